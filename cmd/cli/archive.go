@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/spf13/cobra"
+	"go.szostok.io/version"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
+	"github.com/ondrovic/nexus-mods-scraper/internal/warc"
+)
+
+var (
+	// archiveCmd is a Cobra command used for archiving the pages of a mod.
+	archiveCmd = &cobra.Command{}
+	// archiveOptions holds the command-line flag values of the archive command.
+	archiveOptions = config.Archive{}
+)
+
+// archiveTabs are the tabs of a mod page archived along with its main page.
+var archiveTabs = []string{"files", "posts"}
+
+// init initializes the archive command with usage, description, and argument
+// validation. It registers the archive flags and adds the command to the root command.
+func init() {
+	archiveCmd = &cobra.Command{
+		Use:               "archive <game name> <mod id> --warc [flags]",
+		Short:             "Archive the pages of a mod as a WARC file",
+		Long:              "Save the mod page, files tab, posts tab and gallery images of a mod, as served, into a WARC file replayable with tools such as pywb, to preserve mods before they are taken down",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ac, err := config.LoadArchive(cmd)
+			if err != nil {
+				return err
+			}
+
+			game, modID, err := parseModArgs(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(ac.BaseUrl, ac.CookieDirectory, ac.CookieFile, ac.CookieHeader)
+			if err != nil {
+				return err
+			}
+
+			return archiveMod(context.Background(), cmd.OutOrStdout(), ac, game, modID, client)
+		},
+	}
+
+	config.RegisterArchiveFlags(archiveCmd, &archiveOptions)
+	RootCmd.AddCommand(archiveCmd)
+}
+
+// archiveMod fetches the main page of the mod, its tabs and its gallery images with
+// client, one request per rate limit interval, and saves the exchanges as a WARC file,
+// compressed unless its name ends with .warc. The file is written to a temporary file
+// renamed into place once complete, so that a failed run leaves no partial archive.
+// Gallery images answered with an error are archived as such. Returns an error if
+// --warc isn't given, a page can't be fetched or doesn't answer 200 OK, the mod is
+// hidden as adult content, or the file can't be written.
+func archiveMod(ctx context.Context, w io.Writer, ac config.Archive, game types.GameSlug, modID types.ModID, client *http.Client) error {
+	if !ac.Warc {
+		return fmt.Errorf("nothing to archive: give --warc to save the mod as a WARC file")
+	}
+	if ac.RateLimit < 0 {
+		return fmt.Errorf("--rate-limit must not be negative")
+	}
+	ctx = httpclient.WithLimiter(ctx, httpclient.NewLimiter(ac.RateLimit))
+
+	path := ac.OutputFile
+	if path == "" {
+		path = filepath.Join(ac.OutputDirectory, game.String(), fmt.Sprintf("%d %s.warc.gz", modID, time.Now().UTC().Format("2006-01-02T15-04")))
+	}
+	dir := filepath.Dir(path)
+	if err := utils.EnsureDirExists(dir); err != nil {
+		return err
+	}
+	tempFile, err := fsys.Default.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating archive: %s - %v", path, err)
+	}
+	defer fsys.Default.Remove(tempFile.Name())
+
+	archiveSpinner := spinners.CreateSpinner(fmt.Sprintf("Archiving modID: %d for game: %s", modID, game), "✓", "Mod archived", "✗", "Mod archiving failed")
+	if err := archiveSpinner.Start(); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("failed to start spinner: %w", err)
+	}
+	writer := warc.NewWriter(tempFile, !strings.HasSuffix(path, ".warc"))
+	images, missing, err := writeModArchive(ctx, writer, ac, game, modID, client, filepath.Base(path))
+	if closeErr := tempFile.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("error writing archive: %s - %v", path, closeErr)
+	}
+	if err != nil {
+		archiveSpinner.StopFailMessage(fmt.Sprintf("Error archiving mod: %v", err))
+		archiveSpinner.StopFail()
+		return err
+	}
+	archiveSpinner.Stop()
+
+	// Temp files are created 0600, the archive is meant to be read by other tools
+	if err := fsys.Default.Chmod(tempFile.Name(), 0644); err != nil {
+		return fmt.Errorf("error writing archive: %s - %v", path, err)
+	}
+	if err := fsys.Default.Rename(tempFile.Name(), path); err != nil {
+		return fmt.Errorf("error saving archive: %s - %v", path, err)
+	}
+
+	fmt.Fprintf(w, "Archived the mod page, %d tabs and %d gallery images in %d records to %s\n", len(archiveTabs), images, writer.Records(), path)
+	if missing > 0 {
+		fmt.Fprintf(w, "%d gallery images answered with an error, archived as such\n", missing)
+	}
+	return nil
+}
+
+// writeModArchive writes the warcinfo record of the archive named filename, then the
+// exchanges of the main page of the mod, its tabs and its gallery images, each request
+// waiting on the limiter of ctx. Returns the number of gallery images archived and of
+// those answered with an error.
+func writeModArchive(ctx context.Context, writer *warc.Writer, ac config.Archive, game types.GameSlug, modID types.ModID, client *http.Client, filename string) (int, int, error) {
+	if err := writer.WriteInfo(filename, "nexus-mods-scraper/"+version.Get().Version); err != nil {
+		return 0, 0, err
+	}
+
+	fetch := func(targetURL string) (*http.Response, []byte, error) {
+		if err := httpclient.LimiterFrom(ctx).Wait(ctx); err != nil {
+			return nil, nil, err
+		}
+		u, err := url.Parse(targetURL)
+		if err != nil {
+			return nil, nil, err
+		}
+		req, err := httpclient.NewBrowserRequest(ctx, targetURL, client.Jar.Cookies(u))
+		if err != nil {
+			return nil, nil, err
+		}
+		resp, body, err := writer.Fetch(client, req)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error archiving %s: %w", targetURL, err)
+		}
+		return resp, body, nil
+	}
+
+	// The gallery is read from the main page, which must show the mod
+	modUrl := fmt.Sprintf("%s/%s/mods/%d", ac.BaseUrl, game, modID)
+	resp, body, err := fetch(modUrl)
+	if err != nil {
+		return 0, 0, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, 0, &fetchers.StatusError{StatusCode: resp.StatusCode, Url: modUrl}
+	}
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, err
+	}
+	doc.Url, _ = url.Parse(modUrl)
+	if extractors.IsAdultContent(doc, modID.Int64()) {
+		return 0, 0, fetchers.ErrAdultContent
+	}
+
+	for _, tab := range archiveTabs {
+		tabUrl := fmt.Sprintf("%s?tab=%s", modUrl, tab)
+		resp, _, err := fetch(tabUrl)
+		if err != nil {
+			return 0, 0, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return 0, 0, &fetchers.StatusError{StatusCode: resp.StatusCode, Url: tabUrl}
+		}
+	}
+
+	images, missing := extractors.ExtractGalleryImages(doc), 0
+	for _, imageUrl := range images {
+		resp, _, err := fetch(imageUrl)
+		if err != nil {
+			return 0, 0, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			missing++
+		}
+	}
+	return len(images), missing, nil
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// newArchiveServer serves the pages of mod 3863 of skyrim, whose gallery has an image
+// and a missing one, and records the cookies each request was sent with.
+func newArchiveServer(t *testing.T, cookies *[]string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*cookies = append(*cookies, r.Header.Get("Cookie"))
+		switch {
+		case r.URL.Path == "/skyrim/mods/3863" && r.URL.Query().Get("tab") == "":
+			fmt.Fprintf(w, `<h1>SkyUI</h1><div id="sidebargallery"><ul><li class="thumb" data-src="%[1]s/images/1.png"></li><li class="thumb" data-src="/images/missing.png"></li></ul></div>`, server.URL)
+		case r.URL.Path == "/skyrim/mods/3863":
+			fmt.Fprintf(w, "<h2>%s tab</h2>", r.URL.Query().Get("tab"))
+		case r.URL.Path == "/images/1.png":
+			w.Header().Set("Content-Type", "image/png")
+			io.WriteString(w, "png")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestArchiveMod(t *testing.T) {
+	// Arrange
+	var cookies []string
+	server := newArchiveServer(t, &cookies)
+	client, err := httpclient.NewClient(server.URL, "", "", "nexusmods_session=secret")
+	require.NoError(t, err)
+	output := t.TempDir()
+	ac := config.Archive{BaseUrl: server.URL, OutputDirectory: output, Warc: true}
+	var out bytes.Buffer
+
+	// Act
+	err = archiveMod(context.Background(), &out, ac, "skyrim", 3863, client)
+
+	// Assert
+	require.NoError(t, err)
+	paths, err := filepath.Glob(filepath.Join(output, "skyrim", "3863 *.warc.gz"))
+	require.NoError(t, err)
+	require.Len(t, paths, 1)
+	file, err := os.Open(paths[0])
+	require.NoError(t, err)
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	archive := string(data)
+
+	assert.True(t, strings.HasPrefix(archive, "WARC/1.1\r\nWARC-Type: warcinfo\r\n"))
+	assert.Equal(t, 5, strings.Count(archive, "WARC-Type: response\r\n"))
+	assert.Equal(t, 5, strings.Count(archive, "WARC-Type: request\r\n"))
+	for _, target := range []string{"/skyrim/mods/3863", "/skyrim/mods/3863?tab=files", "/skyrim/mods/3863?tab=posts", "/images/1.png", "/images/missing.png"} {
+		assert.Contains(t, archive, "WARC-Target-URI: "+server.URL+target+"\r\n")
+	}
+	assert.Contains(t, archive, "<h2>posts tab</h2>")
+	assert.Contains(t, archive, "HTTP/1.1 404 Not Found\r\n")
+	assert.NotContains(t, archive, "secret")
+	assert.Contains(t, cookies[0], "nexusmods_session=secret", "the session is sent to the server")
+	assert.Contains(t, out.String(), "Archived the mod page, 2 tabs and 2 gallery images in 11 records to "+paths[0])
+	assert.Contains(t, out.String(), "1 gallery images answered with an error, archived as such")
+}
+
+func TestArchiveMod_Uncompressed(t *testing.T) {
+	// Arrange
+	var cookies []string
+	server := newArchiveServer(t, &cookies)
+	client, err := httpclient.NewClient(server.URL, "", "", "")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "skyui.warc")
+	ac := config.Archive{BaseUrl: server.URL, OutputFile: path, Warc: true}
+
+	// Act
+	err = archiveMod(context.Background(), io.Discard, ac, "skyrim", 3863, client)
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "WARC/1.1\r\n"))
+	assert.Contains(t, string(data), "<h1>SkyUI</h1>")
+}
+
+func TestArchiveMod_Errors(t *testing.T) {
+	var cookies []string
+	server := newArchiveServer(t, &cookies)
+	client, err := httpclient.NewClient(server.URL, "", "", "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		ac    config.Archive
+		modID types.ModID
+		err   string
+	}{
+		{name: "without warc", ac: config.Archive{BaseUrl: server.URL}, modID: 3863, err: "nothing to archive: give --warc to save the mod as a WARC file"},
+		{name: "negative rate limit", ac: config.Archive{BaseUrl: server.URL, RateLimit: -1, Warc: true}, modID: 3863, err: "--rate-limit must not be negative"},
+		{name: "missing mod", ac: config.Archive{BaseUrl: server.URL, Warc: true}, modID: 1, err: "failed to fetch document: " + server.URL + "/skyrim/mods/1 returned 404"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			output := t.TempDir()
+			tt.ac.OutputDirectory = output
+
+			// Act
+			err := archiveMod(context.Background(), io.Discard, tt.ac, "skyrim", tt.modID, client)
+
+			// Assert
+			assert.EqualError(t, err, tt.err)
+			entries, _ := os.ReadDir(filepath.Join(output, "skyrim"))
+			assert.Empty(t, entries, "no partial archive is left")
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/compat"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/report"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// checkCmd is a Cobra command used for checking a mod list against the saved mods.
+	checkCmd = &cobra.Command{}
+	// checkOptions holds the command-line flag values of the check command.
+	checkOptions = config.Check{}
+)
+
+// init initializes the check command with usage, description, and argument validation.
+// It registers the check flags and adds the command to the root command.
+func init() {
+	checkCmd = &cobra.Command{
+		Use:   "check --modlist <file> --game <game name> [flags]",
+		Short: "Check a mod list for missing requirements",
+		Long:  "Match the mods of a Mod Organizer 2 or Vortex export against the saved mods of the game and report the Nexus requirements missing from the list and the installed versions older than the latest saved ones. Scrape the installed mods first to check them",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cc, err := config.LoadCheck(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Saved dates are parsed using the format they were written in
+			types.TimestampFormat = formatters.DateLayout(cc.DateFormat)
+
+			return checkModList(cmd.OutOrStdout(), cmd.ErrOrStderr(), cc)
+		},
+	}
+
+	config.RegisterCheckFlags(checkCmd, &checkOptions)
+	completeGameFlag(checkCmd)
+	RootCmd.AddCommand(checkCmd)
+}
+
+// checkModList reads the mod list, checks it against the mods of the game saved in the
+// output directory and writes the result to w in the configured format. Saved files
+// that can't be loaded are reported to status and left out. Returns an error if the
+// flags are invalid, the mod list can't be read, or no mods of the game are saved.
+func checkModList(w, status io.Writer, cc config.Check) error {
+	format := strings.ToLower(cc.Format)
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format %q, expected text or json", cc.Format)
+	}
+	if cc.ModList == "" {
+		return fmt.Errorf("--modlist is required")
+	}
+	if cc.Game == "" {
+		return fmt.Errorf("--game is required")
+	}
+
+	installed, err := compat.ReadModList(cc.ModList)
+	if err != nil {
+		return err
+	}
+
+	entries, skipped, err := report.Load(cc.OutputDirectory)
+	if err != nil {
+		return err
+	}
+	for _, err := range skipped {
+		fmt.Fprintf(status, "Skipping %v\n", err)
+	}
+	var saved []types.ModInfo
+	for _, entry := range entries {
+		if strings.EqualFold(entry.Game, cc.Game) {
+			saved = append(saved, entry.Mod)
+		}
+	}
+	if len(saved) == 0 {
+		return fmt.Errorf("no saved mods of %s found in %s, scrape the installed mods first", cc.Game, cc.OutputDirectory)
+	}
+
+	result := compat.Check(installed, saved)
+
+	if format == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting results: %v", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	fmt.Fprintf(w, "Checked %d of %d installed mods against the saved %s mods\n", result.Checked, len(installed), cc.Game)
+	if len(result.Missing) > 0 {
+		fmt.Fprintf(w, "\nMissing requirements (%d):\n", len(result.Missing))
+		for _, missing := range result.Missing {
+			fmt.Fprintf(w, "  %s requires %s", missing.Mod, missing.Requirement)
+			if missing.Url != "" {
+				fmt.Fprintf(w, " (%s)", missing.Url)
+			}
+			fmt.Fprintln(w)
+		}
+	}
+	if len(result.Outdated) > 0 {
+		fmt.Fprintf(w, "\nOutdated mods (%d):\n", len(result.Outdated))
+		for _, outdated := range result.Outdated {
+			fmt.Fprintf(w, "  %s %s, latest is %s\n", outdated.Name, outdated.Installed, outdated.Latest)
+		}
+	}
+	if len(result.Unknown) > 0 {
+		fmt.Fprintf(w, "\nNot saved, not checked (%d): %s\n", len(result.Unknown), strings.Join(result.Unknown, ", "))
+	}
+	if len(result.Missing) == 0 && len(result.Outdated) == 0 {
+		fmt.Fprintln(w, "No missing requirements or outdated mods found")
+	}
+	return nil
+}
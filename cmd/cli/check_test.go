@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/compat"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCheckFixtures saves a mod requiring SKSE64 and a mod list holding it to dir,
+// returning the path of the mod list.
+func writeCheckFixtures(t *testing.T, dir string) string {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skyrimspecialedition"), os.ModePerm))
+	saved := `{"Mods":{"ModID":12604,"Name":"SkyUI","LatestVersion":"5.2","Dependencies":[{"Name":"SKSE64","Url":"https://www.nexusmods.com/skyrimspecialedition/mods/30379"}]}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skyrimspecialedition", "skyui 12604.json"), []byte(saved), 0644))
+
+	modList := filepath.Join(dir, "mods.csv")
+	require.NoError(t, os.WriteFile(modList, []byte("Name,Version\nSkyUI,5.1\nLocal Mod,1.0\n"), 0644))
+	return modList
+}
+
+func TestCheckModList_Text(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	modList := writeCheckFixtures(t, dir)
+	var out bytes.Buffer
+
+	// Act
+	err := checkModList(&out, &bytes.Buffer{}, config.Check{Format: "text", Game: "skyrimspecialedition", ModList: modList, OutputDirectory: dir})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Checked 1 of 2 installed mods")
+	assert.Contains(t, out.String(), "SkyUI requires SKSE64 (https://www.nexusmods.com/skyrimspecialedition/mods/30379)")
+	assert.Contains(t, out.String(), "SkyUI 5.1, latest is 5.2")
+	assert.Contains(t, out.String(), "Not saved, not checked (1): Local Mod")
+}
+
+func TestCheckModList_Json(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	modList := writeCheckFixtures(t, dir)
+	var out bytes.Buffer
+
+	// Act
+	err := checkModList(&out, &bytes.Buffer{}, config.Check{Format: "json", Game: "skyrimspecialedition", ModList: modList, OutputDirectory: dir})
+
+	// Assert
+	require.NoError(t, err)
+	var result compat.Result
+	require.NoError(t, json.Unmarshal(out.Bytes(), &result))
+	assert.Len(t, result.Missing, 1)
+	assert.Len(t, result.Outdated, 1)
+}
+
+func TestCheckModList_Errors(t *testing.T) {
+	dir := t.TempDir()
+	modList := writeCheckFixtures(t, dir)
+
+	tests := []struct {
+		name string
+		cc   config.Check
+		want string
+	}{
+		{"format", config.Check{Format: "xml", Game: "skyrimspecialedition", ModList: modList}, `unsupported format "xml", expected text or json`},
+		{"modlist", config.Check{Format: "text", Game: "skyrimspecialedition"}, "--modlist is required"},
+		{"game", config.Check{Format: "text", ModList: modList}, "--game is required"},
+		{"no saved mods", config.Check{Format: "text", Game: "fallout4", ModList: modList, OutputDirectory: dir}, "no saved mods of fallout4 found in " + dir + ", scrape the installed mods first"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkModList(&bytes.Buffer{}, &bytes.Buffer{}, tt.cc)
+
+			assert.EqualError(t, err, tt.want)
+		})
+	}
+}
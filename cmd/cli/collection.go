@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// collectionCmd is a Cobra command used for scraping collections.
+	collectionCmd = &cobra.Command{}
+	// collectionOptions holds the command-line flag values of the scrape-collection command.
+	collectionOptions = config.Collection{}
+	// fetchCollectionFunc is a variable that holds a reference to the function used for
+	// fetching a collection page.
+	fetchCollectionFunc = fetchers.FetchCollection
+)
+
+// init initializes the scrape-collection command with usage, description, and argument
+// validation. It registers the collection flags and adds the command to the root command.
+func init() {
+	collectionCmd = &cobra.Command{
+		Use:               "scrape-collection <game name> <collection slug> [flags]",
+		Short:             "Scrape collection",
+		Long:              "Scrape a collection, listing the mods it contains with their versions, and optionally scrape each mod",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cc, err := config.LoadCollection(cmd)
+			if err != nil {
+				return err
+			}
+			return scrapeCollection(cc, args[0], args[1], fetchCollectionFunc, fetchModInfoFunc, fetchDocumentFunc)
+		},
+	}
+
+	config.RegisterCollectionFlags(collectionCmd, &collectionOptions)
+	RootCmd.AddCommand(collectionCmd)
+}
+
+// scrapeCollection scrapes the collection page, then displays and/or saves the list of
+// mods it contains. When ScrapeMods is set, every mod of the collection is then scraped
+// through the regular scrape pipeline, grouped by the game it belongs to and sharing a
+// single run ID. Returns an
+// error if the collection can't be scraped or any of its mods fail.
+func scrapeCollection(
+	cc config.Collection,
+	game, slug string,
+	fetchCollectionFunc func(ctx context.Context, baseUrl, game, slug string, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Collection, error),
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	if !cc.DisplayResults && !cc.SaveResults {
+		return fmt.Errorf("at least one of --display-results (-r) or --save-results (-s) must be enabled")
+	}
+	game = strings.ToLower(game)
+
+	// HTTP Client Setup
+	client, err := httpclient.NewClient(cc.BaseUrl, cc.CookieDirectory, cc.CookieFile, cc.CookieHeader)
+	if err != nil {
+		return err
+	}
+
+	// Scrape Collection
+	collectionSpinner := spinners.CreateSpinner(fmt.Sprintf("Scraping collection: %s for game: %s", slug, game), "✓", "Collection scraping complete", "✗", "Collection scraping failed")
+	if err := collectionSpinner.Start(); err != nil {
+		return fmt.Errorf("failed to start spinner: %w", err)
+	}
+
+	collection, err := fetchCollectionFunc(context.Background(), cc.CollectionsBaseUrl, game, slug, fetchers.WithClient(client, fetchDocumentFunc))
+	if err != nil {
+		collectionSpinner.StopFailMessage(fmt.Sprintf("Error scraping collection: %v", err))
+		collectionSpinner.StopFail()
+		return err
+	}
+	collectionSpinner.StopMessage(fmt.Sprintf("Found %d mods in collection %s", len(collection.Mods), collection.Name))
+	collectionSpinner.Stop()
+
+	// Display Results
+	if cc.DisplayResults {
+		jsonData, err := json.MarshalIndent(collection, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal collection: %w", err)
+		}
+		if err := formatters.PrintPrettyJson(string(jsonData)); err != nil {
+			return err
+		}
+	}
+
+	// Save Results
+	if cc.SaveResults {
+		outputDirectory := filepath.Join(cc.OutputDirectory, game, "collections")
+		savedPath, err := exporters.SaveModInfoToJson(types.CliFlags{}, collection, outputDirectory, slug, utils.EnsureDirExists)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(formatters.Status(), "Collection saved to %s\n", formatters.PathLink(savedPath))
+	}
+
+	if !cc.ScrapeMods {
+		return nil
+	}
+
+	// Scrape each mod, grouped by game as collections may pull in mods from other games
+	games, modIDs := groupCollectionMods(collection.Mods, game)
+	runID := utils.NewRunID()
+	failedGames := 0
+	for _, modGame := range games {
+		gameSlug, err := types.ParseGameSlug(modGame)
+		if err != nil {
+			fmt.Fprintf(formatters.Status(), "Error scraping %s mods: %v\n", modGame, err)
+			failedGames++
+			continue
+		}
+		sc := types.CliFlags{
+			BaseUrl:         cc.BaseUrl,
+			CookieDirectory: cc.CookieDirectory,
+			CookieFile:      cc.CookieFile,
+			CookieHeader:    cc.CookieHeader,
+			DisplayResults:  cc.DisplayResults,
+			GameName:        gameSlug,
+			IgnoreFile:      cc.IgnoreFile,
+			OutputDirectory: cc.OutputDirectory,
+			PerModTimeout:   cc.PerModTimeout,
+			RunID:           runID,
+			SaveResults:     cc.SaveResults,
+		}
+		if err := scrapeMods(context.Background(), sc, modIDs[modGame], fetchModInfoFunc, fetchDocumentFunc); err != nil {
+			fmt.Fprintf(formatters.Status(), "Error scraping %s mods: %v\n", modGame, err)
+			failedGames++
+		}
+	}
+
+	if failedGames > 0 {
+		return fmt.Errorf("mods of collection %s failed to scrape", slug)
+	}
+	return nil
+}
+
+// groupCollectionMods groups the mod IDs of a collection by game, in the order the games
+// first appear. Mods without a game are attributed to the collection's game.
+func groupCollectionMods(mods []types.CollectionMod, defaultGame string) ([]string, map[string][]int64) {
+	var games []string
+	modIDs := map[string][]int64{}
+
+	for _, mod := range mods {
+		game := strings.ToLower(mod.Game)
+		if game == "" {
+			game = defaultGame
+		}
+		if _, ok := modIDs[game]; !ok {
+			games = append(games, game)
+		}
+		modIDs[game] = append(modIDs[game], mod.ModID)
+	}
+
+	return games, modIDs
+}
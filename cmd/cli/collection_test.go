@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeCollection_ScrapesModsPerGame(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+
+	cc := config.Collection{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		OutputDirectory: tempDir,
+		SaveResults:     true,
+		ScrapeMods:      true,
+	}
+	fetchCollection := func(_ context.Context, baseUrl, game, slug string, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Collection, error) {
+		return types.Collection{Name: "Essentials", Slug: slug, Mods: []types.CollectionMod{
+			{Game: "skyrimspecialedition", ModID: 1},
+			{Game: "skyrimspecialedition", ModID: 2},
+			{Game: "skyrim", ModID: 3},
+		}}, nil
+	}
+	scraped := map[string][]int64{}
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped[game.String()] = append(scraped[game.String()], modId.Int64())
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err := scrapeCollection(cc, "SkyrimSpecialEdition", "abc123", fetchCollection, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]int64{"skyrimspecialedition": {1, 2}, "skyrim": {3}}, scraped)
+	assert.FileExists(t, filepath.Join(tempDir, "skyrimspecialedition", "collections", "abc123.json"))
+}
+
+func TestScrapeCollection_NoResultsFlagSet(t *testing.T) {
+	err := scrapeCollection(config.Collection{}, "game", "slug", nil, nil, nil)
+
+	assert.EqualError(t, err, "at least one of --display-results (-r) or --save-results (-s) must be enabled")
+}
+
+func TestGroupCollectionMods(t *testing.T) {
+	mods := []types.CollectionMod{{Game: "Fallout4", ModID: 1}, {ModID: 2}, {Game: "fallout4", ModID: 3}}
+
+	games, modIDs := groupCollectionMods(mods, "skyrim")
+
+	assert.Equal(t, []string{"fallout4", "skyrim"}, games)
+	assert.Equal(t, map[string][]int64{"fallout4": {1, 3}, "skyrim": {2}}, modIDs)
+}
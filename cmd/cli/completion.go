@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+)
+
+// popularGames are suggested for the game names along with the games of the saved
+// results, so that completion is of use before the first scrape.
+var popularGames = []string{
+	"baldursgate3",
+	"cyberpunk2077",
+	"fallout3",
+	"fallout4",
+	"newvegas",
+	"oblivion",
+	"morrowind",
+	"skyrim",
+	"skyrimspecialedition",
+	"stardewvalley",
+	"starfield",
+	"witcher3",
+}
+
+// completeGames is the completion function of the game name flags: the games whose
+// results are saved in the output directory of the command, or the data storage
+// directory when it has none, along with the popular games, that start with
+// toComplete.
+func completeGames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	dir := storage.GetDataStoragePath()
+	if flag := cmd.Flags().Lookup("output-directory"); flag != nil {
+		dir = flag.Value.String()
+	}
+
+	seen := map[string]bool{}
+	var games []string
+	for _, game := range append(savedGames(dir), popularGames...) {
+		if !seen[game] && strings.HasPrefix(game, strings.ToLower(toComplete)) {
+			seen[game] = true
+			games = append(games, game)
+		}
+	}
+	sort.Strings(games)
+	return games, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeGameArg is the completion function of the commands taking a game name as
+// their first argument, completing it with completeGames and nothing after it, e.g.
+// mod IDs.
+func completeGameArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return completeGames(cmd, args, toComplete)
+}
+
+// savedGames returns the games of the results saved in dir: its subdirectories named
+// as a game that hold JSON files, such as the saved mods or the summary index. Returns
+// none if dir can't be read.
+func savedGames(dir string) []string {
+	entries, err := fsys.Default.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+
+	var games []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if slug, err := types.ParseGameSlug(entry.Name()); err != nil || slug.String() != entry.Name() {
+			continue
+		}
+		if hasJsonFile(filepath.Join(dir, entry.Name())) {
+			games = append(games, entry.Name())
+		}
+	}
+	return games
+}
+
+// hasJsonFile reports whether dir directly holds a .json file.
+func hasJsonFile(dir string) bool {
+	entries, err := fsys.Default.ReadDir(dir)
+	if err != nil {
+		return false
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".json") {
+			return true
+		}
+	}
+	return false
+}
+
+// completeGameFlag registers completeGames as the completion function of the --game
+// flag of cmd. It panics if cmd has no such flag, which is a programming error.
+func completeGameFlag(cmd *cobra.Command) {
+	if err := cmd.RegisterFlagCompletionFunc("game", completeGames); err != nil {
+		panic(err)
+	}
+}
+
+// CompletionRequested reports whether args, the command line arguments without the
+// program name, run the completion command or ask for the completions of the shell,
+// whose output is read by the shell rather than displayed.
+func CompletionRequested(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+	switch args[0] {
+	case "completion", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	return false
+}
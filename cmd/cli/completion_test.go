@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+func TestCompleteGames(t *testing.T) {
+	// Arrange: saved results of two games, one of them popular, and directories that
+	// aren't games
+	defer fsys.Use(fsys.NewMemFS())()
+	output := filepath.Join("data", "output")
+	for _, dir := range []string{"skyrimvr", "skyrim", "Sky Notes", "skyempty"} {
+		require.NoError(t, fsys.Default.MkdirAll(filepath.Join(output, dir), 0755))
+	}
+	require.NoError(t, fsys.Default.WriteFile(filepath.Join(output, "skyrimvr", "skyui 3863.json"), []byte("{}"), 0644))
+	require.NoError(t, fsys.Default.WriteFile(filepath.Join(output, "skyrim", "summary.json"), []byte("{}"), 0644))
+	require.NoError(t, fsys.Default.WriteFile(filepath.Join(output, "Sky Notes", "notes.json"), []byte("{}"), 0644))
+
+	cmd := &cobra.Command{Use: "scrape"}
+	config.RegisterScrapeFlags(cmd, &types.CliFlags{})
+	require.NoError(t, cmd.ParseFlags([]string{"--output-directory", output}))
+
+	// Act
+	games, directive := completeGameArg(cmd, nil, "sky")
+	afterGame, _ := completeGameArg(cmd, []string{"skyrim"}, "")
+
+	// Assert
+	assert.Equal(t, []string{"skyrim", "skyrimspecialedition", "skyrimvr"}, games)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	assert.Empty(t, afterGame)
+}
+
+func TestCompletionRequested(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{args: []string{"completion", "bash"}, expected: true},
+		{args: []string{cobra.ShellCompRequestCmd, "scrape", ""}, expected: true},
+		{args: []string{cobra.ShellCompNoDescRequestCmd, "scrape", ""}, expected: true},
+		{args: []string{"scrape", "skyrim", "3863"}},
+		{},
+	}
+
+	for _, tt := range tests {
+		// Act & Assert
+		assert.Equal(t, tt.expected, CompletionRequested(tt.args), tt.args)
+	}
+}
@@ -0,0 +1,172 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/browserutils/kooky"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// cookiesCmd is the parent Cobra command for managing the session cookies.
+	cookiesCmd = &cobra.Command{}
+	// cookiesRefreshCmd is a Cobra command that re-extracts the session cookies.
+	cookiesRefreshCmd = &cobra.Command{}
+	// cookiesRefreshOptions holds the command-line flag values of the cookies refresh
+	// command.
+	cookiesRefreshOptions = config.CookiesRefresh{}
+	// errLoginRequired is returned when no browser holds a session that authenticates,
+	// which only logging in to the site again fixes.
+	errLoginRequired = errors.New("log in to Nexus Mods in a browser again")
+)
+
+// init initializes the cookies command and its refresh subcommand, registering their
+// flags and adding them to the root command.
+func init() {
+	cookiesCmd = &cobra.Command{
+		Use:   "cookies",
+		Short: "Manage the session cookies",
+		Long:  "Manage the session cookies extracted from the browsers and used by the scraper",
+	}
+
+	cookiesRefreshCmd = &cobra.Command{
+		Use:   "refresh [flags]",
+		Short: "Refresh the session cookies",
+		Long:  "Extract the session cookies from the browsers again, validate them and rewrite the cookie file, once or, with --daemon, every interval. Exits with the authentication exit code when no browser holds a session that authenticates and a manual login is required",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rc, err := config.LoadCookiesRefresh(cmd)
+			if err != nil {
+				return err
+			}
+			if rc.Daemon && rc.Once {
+				return fmt.Errorf("--daemon and --once can't be combined")
+			}
+			if rc.Daemon && rc.Interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			if err := storage.ValidateCookieStore(rc.CookieStore); err != nil {
+				return err
+			}
+
+			refresh := func() error {
+				return refreshCookies(cmd.OutOrStdout(), rc, kooky.FindAllCookieStores)
+			}
+			if !rc.Daemon {
+				return refresh()
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return runCookiesDaemon(ctx, cmd.OutOrStdout(), rc.Interval, refresh)
+		},
+	}
+
+	config.RegisterCookiesRefreshFlags(cookiesRefreshCmd, &cookiesRefreshOptions)
+	cookiesCmd.AddCommand(cookiesRefreshCmd)
+	RootCmd.AddCommand(cookiesCmd)
+}
+
+// refreshCookies extracts the session cookies from the browser stores, picks the ones
+// that authenticate like the extract command does, validates them if that wasn't done
+// while picking them, and rewrites the cookie file with them. The cookie file is left
+// untouched unless the new cookies authenticate. Returns an error wrapping
+// errLoginRequired, classified as an authentication failure, if no store holds the
+// cookies or they don't authenticate, or an error if they can't be validated or saved.
+func refreshCookies(out io.Writer, rc config.CookiesRefresh, storeProvider func() []kooky.CookieStore) error {
+	candidates, err := extractors.CookieCandidates(formatters.CookieDomain(rc.BaseUrl), rc.ValidCookies, storeProvider)
+	if err != nil {
+		return failures.WithKind(fmt.Errorf("no session cookies found in the browsers (%v), %w", err, errLoginRequired), failures.KindAuth)
+	}
+
+	selected := extractors.SelectCookieCandidate(candidates, rc.ValidCookies, func(cookies map[string]string) (bool, error) {
+		return validateCookiesFunc(rc.BaseUrl, cookies)
+	})
+	if !selected.Validated {
+		ok, err := validateCookiesFunc(rc.BaseUrl, selected.Cookies)
+		if err != nil {
+			return fmt.Errorf("error validating the session cookies: %w", err)
+		}
+		if !ok {
+			return failures.WithKind(fmt.Errorf("the session cookies of %s no longer authenticate, %w", describeCandidate(selected), errLoginRequired), failures.KindAuth)
+		}
+		selected.Validated = true
+	}
+
+	location, err := saveCookies(rc.CookieStore, rc.OutputDirectory, rc.OutputFilename, selected.Cookies)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "Refreshed the session cookies from %s in %s, expiring %s\n",
+		describeCandidate(selected), location, formatReportTime(selected.ExpiresAt))
+	return nil
+}
+
+// saveCookies saves the cookies to the store, either the cookie file in dir or the OS
+// keyring entry named after it, and returns where they were saved for display. Returns
+// an error if they can't be saved.
+func saveCookies(store, dir, filename string, cookies map[string]string) (string, error) {
+	path := filepath.Join(dir, filename)
+	if store == storage.CookieStoreKeyring {
+		if err := storage.SaveCookiesToKeyring(path, cookies); err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("the OS keyring (%s)", path), nil
+	}
+
+	if err := exporters.SaveCookiesToJson(dir, filename, cookies, os.OpenFile, utils.EnsureDirExists); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// keyringCookieHeader returns the cookies saved in the OS keyring under the cookie file
+// path as a Cookie header, sorted by name, for the commands to send instead of reading
+// the cookie file. Returns an error if the keyring holds no cookies for the path.
+func keyringCookieHeader(dir, filename string) (string, error) {
+	cookies, err := storage.LoadCookiesFromKeyring(filepath.Join(dir, filename))
+	if err != nil {
+		return "", err
+	}
+	return httpclient.CookieHeader(cookies), nil
+}
+
+// runCookiesDaemon refreshes the session cookies right away and then every interval
+// until ctx is done. A refresh failing for any other reason than a required login,
+// such as the site being unreachable, is logged and retried at the next interval.
+// Returns the error of the refresh requiring a login, or nil once ctx is done.
+func runCookiesDaemon(ctx context.Context, out io.Writer, interval time.Duration, refresh func() error) error {
+	fmt.Fprintf(out, "Refreshing the session cookies every %s\n", interval)
+	for {
+		err := refresh()
+		if errors.Is(err, errLoginRequired) {
+			return err
+		}
+		if err != nil {
+			fmt.Fprintf(out, "Error refreshing the session cookies, retrying in %s: %v\n", interval, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/browserutils/kooky"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+// sessionStoreProvider returns a store provider serving a single browser store that
+// holds the session cookie, or no cookie at all when value is empty.
+func sessionStoreProvider(value string) func() []kooky.CookieStore {
+	store := new(MockCookieStore)
+	cookies := []*kooky.Cookie{}
+	if value != "" {
+		cookies = append(cookies, &kooky.Cookie{Cookie: http.Cookie{Name: "session", Value: value, Domain: "example.com"}, Creation: time.Now()})
+	}
+	store.On("ReadCookies", mock.Anything).Return(cookies, nil)
+	store.On("Close").Return(nil)
+	store.On("Browser").Return("firefox").Maybe()
+	store.On("Profile").Return("").Maybe()
+	store.On("FilePath").Return("").Maybe()
+	return func() []kooky.CookieStore { return []kooky.CookieStore{store} }
+}
+
+func TestRefreshCookies(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		valid    bool
+		validErr error
+		exitCode int
+		saved    string
+	}{
+		{name: "valid session", value: "new", valid: true, saved: `{"session": "new"}`},
+		{name: "expired session", value: "new", exitCode: failures.ExitAuth, saved: `{"session": "old"}`},
+		{name: "no session", exitCode: failures.ExitAuth, saved: `{"session": "old"}`},
+		{name: "site unreachable", value: "new", validErr: errors.New("connection refused"), exitCode: failures.ExitFailure, saved: `{"session": "old"}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+			path := filepath.Join(dir, "session-cookies.json")
+			require.NoError(t, os.WriteFile(path, []byte(`{"session": "old"}`), 0644))
+			original := validateCookiesFunc
+			validateCookiesFunc = func(baseUrl string, cookies map[string]string) (bool, error) {
+				return tt.valid, tt.validErr
+			}
+			defer func() { validateCookiesFunc = original }()
+			rc := config.CookiesRefresh{BaseUrl: "https://example.com", OutputDirectory: dir, OutputFilename: "session-cookies.json", ValidCookies: []string{"session"}}
+			var out bytes.Buffer
+
+			// Act
+			err := refreshCookies(&out, rc, sessionStoreProvider(tt.value))
+
+			// Assert
+			assert.Equal(t, tt.exitCode, failures.ExitCode(err))
+			assert.Equal(t, tt.exitCode == failures.ExitAuth, errors.Is(err, errLoginRequired))
+			data, readErr := os.ReadFile(path)
+			require.NoError(t, readErr)
+			assert.JSONEq(t, tt.saved, string(data))
+			if err == nil {
+				assert.Contains(t, out.String(), "Refreshed the session cookies from firefox, validated")
+			}
+		})
+	}
+}
+
+func TestRefreshCookies_Keyring(t *testing.T) {
+	// Arrange
+	keyring.MockInit()
+	dir := t.TempDir()
+	original := validateCookiesFunc
+	validateCookiesFunc = func(baseUrl string, cookies map[string]string) (bool, error) {
+		return true, nil
+	}
+	defer func() { validateCookiesFunc = original }()
+	rc := config.CookiesRefresh{BaseUrl: "https://example.com", CookieStore: storage.CookieStoreKeyring, OutputDirectory: dir, OutputFilename: "session-cookies.json", ValidCookies: []string{"session"}}
+	var out bytes.Buffer
+
+	// Act
+	err := refreshCookies(&out, rc, sessionStoreProvider("new"))
+	header, headerErr := keyringCookieHeader(dir, "session-cookies.json")
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, headerErr)
+	assert.Equal(t, "session=new", header)
+	assert.NoFileExists(t, filepath.Join(dir, "session-cookies.json"))
+	assert.Contains(t, out.String(), "in the OS keyring")
+}
+
+func TestKeyringCookieHeader(t *testing.T) {
+	// Arrange
+	keyring.MockInit()
+	require.NoError(t, storage.SaveCookiesToKeyring(filepath.Join("data", "session-cookies.json"), map[string]string{"nexusmods_session_refresh": "def", "nexusmods_session": "abc"}))
+
+	// Act
+	header, err := keyringCookieHeader("data", "session-cookies.json")
+	_, missingErr := keyringCookieHeader("other", "session-cookies.json")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "nexusmods_session=abc; nexusmods_session_refresh=def", header)
+	assert.ErrorIs(t, missingErr, storage.ErrNoKeyringCookies)
+}
+
+func TestRunCookiesDaemon(t *testing.T) {
+	t.Run("retries transient failures until stopped", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		refreshes := 0
+		refresh := func() error {
+			refreshes++
+			if refreshes == 3 {
+				cancel()
+			}
+			return errors.New("connection refused")
+		}
+		var out bytes.Buffer
+
+		err := runCookiesDaemon(ctx, &out, time.Millisecond, refresh)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, refreshes)
+		assert.Contains(t, out.String(), "Error refreshing the session cookies, retrying in 1ms: connection refused")
+	})
+
+	t.Run("exits when a login is required", func(t *testing.T) {
+		refreshes := 0
+		refresh := func() error {
+			refreshes++
+			if refreshes == 2 {
+				return failures.WithKind(errLoginRequired, failures.KindAuth)
+			}
+			return nil
+		}
+
+		err := runCookiesDaemon(context.Background(), &bytes.Buffer{}, time.Millisecond, refresh)
+
+		assert.Equal(t, failures.ExitAuth, failures.ExitCode(err))
+		assert.Equal(t, 2, refreshes)
+	})
+}
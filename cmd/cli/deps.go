@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/deps"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// depsCmd is a Cobra command used for building mod dependency graphs.
+	depsCmd = &cobra.Command{}
+	// depsOptions holds the command-line flag values of the deps command.
+	depsOptions = config.Deps{}
+	// fetchModPageFunc is a variable that holds a reference to the function used for
+	// fetching the main page of a mod.
+	fetchModPageFunc = fetchers.FetchModPage
+)
+
+// init initializes the deps command with usage, description, and argument validation.
+// It registers the deps flags and adds the command to the root command.
+func init() {
+	depsCmd = &cobra.Command{
+		Use:               "deps <game name> <mod id> [flags]",
+		Short:             "Build a mod dependency graph",
+		Long:              "Recursively scrape the Nexus requirements of a mod and output the dependency graph as JSON or Graphviz DOT",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dc, err := config.LoadDeps(cmd)
+			if err != nil {
+				return err
+			}
+
+			game, modID, err := parseModArgs(args[0], args[1])
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(dc.BaseUrl, dc.CookieDirectory, dc.CookieFile, dc.CookieHeader)
+			if err != nil {
+				return err
+			}
+
+			return buildDeps(cmd.OutOrStdout(), dc, game, modID, fetchModPageFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterDepsFlags(depsCmd, &depsOptions)
+	RootCmd.AddCommand(depsCmd)
+}
+
+// buildDeps walks the requirements of the mod and writes the resulting dependency graph
+// in the configured format, either to w or to the configured output file. Each mod is
+// scraped within the per-mod timeout. Returns an error if the format is unknown, the
+// root mod can't be scraped or the graph can't be written.
+func buildDeps(
+	w io.Writer,
+	dc config.Deps,
+	game types.GameSlug,
+	modID types.ModID,
+	fetchModPageFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	format := strings.ToLower(dc.Format)
+	if format != "json" && format != "dot" {
+		return fmt.Errorf("unsupported format %q, expected json or dot", dc.Format)
+	}
+
+	fetch := func(_ context.Context, game string, modID int64) (types.ModInfo, error) {
+		slug, id, err := parseMod(game, modID)
+		if err != nil {
+			return types.ModInfo{}, err
+		}
+		ctx, cancel := modContext(context.Background(), dc.PerModTimeout)
+		defer cancel()
+		return fetchModPageFunc(ctx, dc.BaseUrl, slug, id, fetchDocumentFunc)
+	}
+
+	graph, err := deps.Build(context.Background(), game.String(), modID.Int64(), dc.MaxDepth, fetch)
+	if err != nil {
+		return fmt.Errorf("error scraping mod %d: %w", modID, err)
+	}
+
+	output, err := renderGraph(graph, format)
+	if err != nil {
+		return err
+	}
+
+	if dc.OutputFile == "" {
+		_, err := io.WriteString(w, output)
+		return err
+	}
+
+	if err := utils.EnsureDirExists(filepath.Dir(dc.OutputFile)); err != nil {
+		return err
+	}
+	if err := fsys.Default.WriteFile(dc.OutputFile, []byte(output), 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", dc.OutputFile, err)
+	}
+	fmt.Fprintf(w, "Dependency graph with %d mods saved to %s\n", len(graph.Nodes), dc.OutputFile)
+	return nil
+}
+
+// renderGraph formats the dependency graph as indented JSON or Graphviz DOT.
+func renderGraph(graph deps.Graph, format string) (string, error) {
+	if format == "dot" {
+		return deps.ToDOT(graph), nil
+	}
+
+	data, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error formatting graph: %v", err)
+	}
+	return string(data) + "\n", nil
+}
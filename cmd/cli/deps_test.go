@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// mockFetchModPage returns mod 1 requiring mod 2, which has no requirements.
+func mockFetchModPage(_ context.Context, baseUrl string, game types.GameSlug, modId types.ModID, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error) {
+	switch modId {
+	case 1:
+		return types.ModInfo{Name: "Root", Dependencies: []types.Requirement{
+			{Name: "Library", Url: "https://www.nexusmods.com/skyrim/mods/2"},
+		}}, nil
+	case 2:
+		return types.ModInfo{Name: "Library"}, nil
+	}
+	return types.ModInfo{}, errors.New("not found")
+}
+
+func TestBuildDeps_Dot(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	dc := config.Deps{Format: "dot", MaxDepth: 3}
+
+	// Act
+	err := buildDeps(&out, dc, "skyrim", 1, mockFetchModPage, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), `"skyrim/1" -> "skyrim/2";`)
+	assert.Contains(t, out.String(), `"skyrim/2" [label="Library\nskyrim/2"];`)
+}
+
+func TestBuildDeps_JsonToFile(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	path := filepath.Join(t.TempDir(), "graphs", "deps.json")
+	dc := config.Deps{Format: "json", OutputFile: path}
+
+	// Act
+	err := buildDeps(&out, dc, "skyrim", 1, mockFetchModPage, nil)
+
+	// Assert
+	require.NoError(t, err)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `"root": "skyrim/1"`)
+	assert.Contains(t, out.String(), "Dependency graph with 2 mods saved to")
+}
+
+func TestBuildDeps_Errors(t *testing.T) {
+	var out bytes.Buffer
+
+	err := buildDeps(&out, config.Deps{Format: "xml"}, "skyrim", 1, mockFetchModPage, nil)
+	assert.EqualError(t, err, `unsupported format "xml", expected json or dot`)
+
+	err = buildDeps(&out, config.Deps{Format: "json"}, "skyrim", 99, mockFetchModPage, nil)
+	assert.EqualError(t, err, "error scraping mod 99: not found")
+}
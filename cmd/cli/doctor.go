@@ -0,0 +1,143 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// doctorCmd is a Cobra command used for checking the extractors against a live mod.
+	doctorCmd = &cobra.Command{}
+	// doctorOptions holds the command-line flag values of the doctor command.
+	doctorOptions = config.Doctor{}
+)
+
+// doctorCheck is a field of the scraped mod the doctor command expects to be filled in.
+type doctorCheck struct {
+	field  string
+	filled func(mod types.ModInfo) bool
+}
+
+// doctorChecks are the fields checked by the doctor command, in the order they are
+// reported. Fields a mod may legitimately leave out, such as requirements or
+// translations, aren't checked. File fields are filled in when any file has them.
+var doctorChecks = []doctorCheck{
+	{"Name", func(mod types.ModInfo) bool { return mod.Name != "" }},
+	{"Creator", func(mod types.ModInfo) bool { return mod.Creator != "" }},
+	{"Uploader", func(mod types.ModInfo) bool { return mod.Uploader != "" }},
+	{"LastUpdated", func(mod types.ModInfo) bool { return mod.LastUpdated != "" }},
+	{"LastUpdatedAt", func(mod types.ModInfo) bool { return mod.LastUpdatedAt != nil }},
+	{"OriginalUpload", func(mod types.ModInfo) bool { return mod.OriginalUpload != "" }},
+	{"OriginalUploadAt", func(mod types.ModInfo) bool { return mod.OriginalUploadAt != nil }},
+	{"VirusStatus", func(mod types.ModInfo) bool { return mod.VirusStatus != "" }},
+	{"ShortDescription", func(mod types.ModInfo) bool { return mod.ShortDescription != "" }},
+	{"Description", func(mod types.ModInfo) bool { return mod.Description != "" }},
+	{"Tags", func(mod types.ModInfo) bool { return len(mod.Tags) > 0 }},
+	{"Category", func(mod types.ModInfo) bool { return mod.Category != "" }},
+	{"ChangeLogs", func(mod types.ModInfo) bool { return len(mod.ChangeLogs) > 0 }},
+	{"Permissions", func(mod types.ModInfo) bool { return mod.Permissions != nil }},
+	{"Endorsements", func(mod types.ModInfo) bool { return mod.Endorsements != "" }},
+	{"UniqueDLs", func(mod types.ModInfo) bool { return mod.UniqueDLs != "" }},
+	{"TotalDLs", func(mod types.ModInfo) bool { return mod.TotalDLs != "" }},
+	{"TotalViews", func(mod types.ModInfo) bool { return mod.TotalViews != "" }},
+	{"Version", func(mod types.ModInfo) bool { return mod.Version != "" }},
+	{"Files", func(mod types.ModInfo) bool { return len(mod.Files) > 0 }},
+	{"Files.Name", anyFile(func(file types.File) bool { return file.Name != "" })},
+	{"Files.Version", anyFile(func(file types.File) bool { return file.Version != "" })},
+	{"Files.UploadDate", anyFile(func(file types.File) bool { return file.UploadDate != "" })},
+	{"Files.FileSize", anyFile(func(file types.File) bool { return file.FileSize != "" })},
+	{"Files.UniqueDLs", anyFile(func(file types.File) bool { return file.UniqueDLs != "" })},
+	{"Files.TotalDLs", anyFile(func(file types.File) bool { return file.TotalDLs != "" })},
+	{"Files.Description", anyFile(func(file types.File) bool { return file.Description != "" })},
+	{"Files.FileID", anyFile(func(file types.File) bool { return file.FileID != 0 })},
+}
+
+// init initializes the doctor command with usage, description, and argument validation.
+// It registers the doctor flags and adds the command to the root command.
+func init() {
+	doctorCmd = &cobra.Command{
+		Use:   "doctor [flags]",
+		Short: "Check the extractors against a live mod",
+		Long:  "Scrape a known, stable mod page and report every field the extractors returned blank, an early warning that Nexus Mods changed its page structure",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dc, err := config.LoadDoctor(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(dc.BaseUrl, dc.CookieDirectory, dc.CookieFile, dc.CookieHeader)
+			if err != nil {
+				return failures.WithKind(err, failures.KindAuth)
+			}
+
+			return doctor(cmd.OutOrStdout(), dc, fetchModInfoFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterDoctorFlags(doctorCmd, &doctorOptions)
+	completeGameFlag(doctorCmd)
+	RootCmd.AddCommand(doctorCmd)
+}
+
+// doctor scrapes the configured mod within the per-mod timeout and writes whether each
+// checked field was filled in to w. Returns an error if the mod can't be scraped, or a
+// parse failure naming the blank fields if any came back blank.
+func doctor(
+	w io.Writer,
+	dc config.Doctor,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	game, modID, err := parseMod(dc.Game, int64(dc.ModID))
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Checking the extractors against %s mod %d\n", game, modID)
+
+	ctx, cancel := modContext(context.Background(), dc.PerModTimeout)
+	defer cancel()
+	results, err := fetchModInfoFunc(ctx, dc.BaseUrl, game, modID, nil, utils.ConcurrentFetch, fetchDocumentFunc)
+	if err != nil {
+		return fmt.Errorf("error scraping mod %d: %w", dc.ModID, err)
+	}
+
+	var blank []string
+	for _, check := range doctorChecks {
+		if check.filled(results.Mods) {
+			fmt.Fprintf(w, "  ✓ %s\n", check.field)
+			continue
+		}
+		fmt.Fprintf(w, "  ✗ %s came back blank\n", check.field)
+		blank = append(blank, check.field)
+	}
+
+	if len(blank) > 0 {
+		return failures.WithKind(fmt.Errorf("%d of %d fields came back blank (%s), the page structure may have changed: see selector overrides", len(blank), len(doctorChecks), strings.Join(blank, ", ")), failures.KindParse)
+	}
+	fmt.Fprintf(w, "All %d fields were extracted\n", len(doctorChecks))
+	return nil
+}
+
+// anyFile returns a check that is filled in when any file of the mod passes filled.
+func anyFile(filled func(file types.File) bool) func(mod types.ModInfo) bool {
+	return func(mod types.ModInfo) bool {
+		for _, file := range mod.Files {
+			if filled(file) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,98 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// healthyMod returns a mod with every field checked by the doctor command filled in.
+func healthyMod() types.ModInfo {
+	now := types.NewTimestamp(time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC))
+	return types.ModInfo{
+		Category:         "User Interface",
+		ChangeLogs:       []types.ChangeLog{{Version: "5.2", Notes: []string{"Fixes"}}},
+		Creator:          "schlangster",
+		Description:      "A mod",
+		Endorsements:     "100",
+		Files:            []types.File{{Description: "Main", FileID: 1, FileSize: "2MB", Name: "SkyUI", TotalDLs: "10", UniqueDLs: "5", UploadDate: "1 Jan 2024", Version: "5.2"}},
+		LastUpdated:      "1 Jan 2024",
+		LastUpdatedAt:    now,
+		Name:             "SkyUI",
+		OriginalUpload:   "1 Jan 2016",
+		OriginalUploadAt: now,
+		Permissions:      &types.Permissions{Credits: "Everyone"},
+		ShortDescription: "Elegant UI",
+		Tags:             []string{"UI"},
+		TotalDLs:         "10",
+		TotalViews:       "20",
+		UniqueDLs:        "5",
+		Uploader:         "schlangster",
+		Version:          "5.2",
+		VirusStatus:      "Safe to use",
+	}
+}
+
+func TestDoctor(t *testing.T) {
+	tests := []struct {
+		name     string
+		mod      func() types.ModInfo
+		expected string
+	}{
+		{"healthy", healthyMod, ""},
+		{"blank fields", func() types.ModInfo {
+			mod := healthyMod()
+			mod.Tags = nil
+			mod.Files[0].Version = ""
+			return mod
+		}, "2 of 28 fields came back blank (Tags, Files.Version), the page structure may have changed: see selector overrides"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var out bytes.Buffer
+			var requested types.ModID
+			fetch := func(_ context.Context, _ string, game types.GameSlug, modId types.ModID, _ fetchers.ModFilter, _ func(ctx context.Context, tasks ...func(ctx context.Context) error) error, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+				requested = modId
+				return types.Results{Mods: tt.mod()}, nil
+			}
+
+			// Act
+			err := doctor(&out, config.Doctor{Game: "SkyrimSpecialEdition", ModID: 12604}, fetch, nil)
+
+			// Assert
+			assert.Equal(t, types.ModID(12604), requested)
+			assert.Contains(t, out.String(), "Checking the extractors against skyrimspecialedition mod 12604")
+			if tt.expected == "" {
+				require.NoError(t, err)
+				assert.Contains(t, out.String(), "All 28 fields were extracted")
+				return
+			}
+			assert.EqualError(t, err, tt.expected)
+			assert.Equal(t, failures.KindParse, failures.Classify(err))
+			assert.Contains(t, out.String(), "✗ Tags came back blank")
+			assert.Contains(t, out.String(), "✓ Name")
+		})
+	}
+}
+
+func TestDoctor_ScrapeError(t *testing.T) {
+	fetch := func(context.Context, string, types.GameSlug, types.ModID, fetchers.ModFilter, func(ctx context.Context, tasks ...func(ctx context.Context) error) error, func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{}, errors.New("boom")
+	}
+
+	err := doctor(&bytes.Buffer{}, config.Doctor{Game: "skyrim", ModID: 1}, fetch, nil)
+
+	assert.EqualError(t, err, "error scraping mod 1: boom")
+}
@@ -1,31 +1,48 @@
 package cli
 
 import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/browserutils/kooky"
-	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
-	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
-	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/login"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
 
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"golang.org/x/term"
 )
 
 var (
 	// extractCmd is a Cobra command used for extracting information within the application.
 	extractCmd = &cobra.Command{}
-	// outputFilename is a string variable that stores the name of the file to which
-	// output will be saved.
-	outputFilename string
+	// extractOptions holds the command-line flag values of the extract command.
+	extractOptions = config.Extract{}
+	// validateCookiesFunc is a variable that holds a reference to the function used to
+	// check whether a set of cookies authenticates.
+	validateCookiesFunc = fetchers.ValidateCookies
+	// loginFunc is a variable that holds a reference to the function used to sign in
+	// with a headless browser when no browser store holds the cookies.
+	loginFunc = browserLogin
 )
 
+// loginTimeout is the time given to sign in with the headless browser, long enough to
+// look up a two-factor code.
+const loginTimeout = 5 * time.Minute
+
 // init initializes the extract command, setting its usage, description, and argument validation.
-// It binds flags using Viper and adds the extract command to the root command for extracting
-// cookies and saving them to a JSON file.
+// It registers the extract flags and adds the extract command to the root command for
+// extracting cookies and saving them to a JSON file.
 func init() {
 	extractCmd = &cobra.Command{
 		Use:   "extract",
@@ -33,41 +50,156 @@ func init() {
 		Long:  "Extract cookies for https://nexusmods.com to use with the scraper, will save to json file",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			ec, err := config.LoadExtract(cmd)
+			if err != nil {
+				return err
+			}
+			if err := storage.ValidateCookieStore(ec.CookieStore); err != nil {
+				return err
+			}
+
 			// Call the actual ExtractCookies function with the default store provider
-			return ExtractCookies(cmd, args, kooky.FindAllCookieStores)
+			return ExtractCookies(ec, kooky.FindAllCookieStores)
 		},
 	}
 
-	initExtractFlags(extractCmd)
-	viper.BindPFlags(extractCmd.Flags())
+	config.RegisterExtractFlags(extractCmd, &extractOptions)
 	RootCmd.AddCommand(extractCmd)
 }
 
-// initExtractFlags registers the command-line flags for the extract command, including
-// options for the output directory, output filename, and valid cookie names to extract.
-// These flags are bound to the corresponding variables and fields in CliFlags.
-func initExtractFlags(cmd *cobra.Command) {
-	cli.RegisterFlag(cmd, "output-directory", "d", storage.GetDataStoragePath(), "Output directory to save the file in", &options.OutputDirectory)
-	cli.RegisterFlag(cmd, "output-filename", "f", "session-cookies.json", "Filename to save the session cookies to", &outputFilename)
-	cli.RegisterFlag(cmd, "valid-cookie-names", "c", []string{"nexusmods_session", "nexusmods_session_refresh"}, "Names of the cookies to extract", &options.ValidCookies)
-}
-
 // ExtractCookies extracts cookies from the specified domain using the valid cookie names,
-// then saves them as a JSON file in the designated output directory. Returns an error
-// if cookie extraction or saving fails.
-func ExtractCookies(cmd *cobra.Command, args []string, storeProvider func() []kooky.CookieStore) error {
-	domain := formatters.CookieDomain(options.BaseUrl)
-	sessionCookies := options.ValidCookies
+// then saves them as a JSON file in the designated output directory, or in the OS
+// keyring with the keyring cookie store. When several browser stores hold a complete
+// set of cookies, they are validated concurrently and the store that actually
+// authenticates is preferred. When none holds them and ec.Login is set, the cookies are
+// obtained by signing in with a headless browser instead. Returns an error if cookie
+// extraction, the sign in or saving fails.
+func ExtractCookies(ec config.Extract, storeProvider func() []kooky.CookieStore) error {
+	domain := formatters.CookieDomain(ec.BaseUrl)
+	sessionCookies := ec.ValidCookies
 
 	// Use the passed storeProvider instead of the default kooky.FindAllCookieStores
-	extractedCookies, err := extractors.CookieExtractor(domain, sessionCookies, storeProvider)
-	if err != nil {
+	candidates, err := extractors.CookieCandidates(domain, sessionCookies, storeProvider)
+	if err != nil && !ec.Login {
+		fmt.Fprintln(formatters.Status(), "No browser holds the session cookies, run extract --login to sign in with a headless browser instead")
 		return err
 	}
+	if err != nil {
+		cookies, err := loginFunc(ec)
+		if err != nil {
+			return failures.WithKind(fmt.Errorf("error signing in: %w", err), failures.KindAuth)
+		}
+		location, err := saveCookies(ec.CookieStore, ec.OutputDirectory, ec.OutputFilename, cookies)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(formatters.Status(), "Signed in, saved the session cookies in %s\n", location)
+		return nil
+	}
+
+	selected := extractors.SelectCookieCandidate(candidates, sessionCookies, func(cookies map[string]string) (bool, error) {
+		return validateCookiesFunc(ec.BaseUrl, cookies)
+	})
+	if len(candidates) > 1 {
+		fmt.Fprintf(formatters.Status(), "Found cookies in %d browser stores, using %s\n", len(candidates), describeCandidate(selected))
+		for _, line := range freshnessReport(candidates) {
+			fmt.Fprintln(formatters.Status(), line)
+		}
+	}
 
-	if err := exporters.SaveCookiesToJson(options.OutputDirectory, outputFilename, extractedCookies, os.OpenFile, utils.EnsureDirExists); err != nil {
+	if _, err := saveCookies(ec.CookieStore, ec.OutputDirectory, ec.OutputFilename, selected.Cookies); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// freshnessReport returns one line per browser store describing when its cookies were
+// created, when the store was last written and when the cookies expire, flagging the
+// store holding the most recently refreshed session.
+func freshnessReport(candidates []types.CookieCandidate) []string {
+	freshest := -1
+	for i, candidate := range candidates {
+		if refreshed := candidate.RefreshedAt(); !refreshed.IsZero() && (freshest < 0 || refreshed.After(candidates[freshest].RefreshedAt())) {
+			freshest = i
+		}
+	}
+
+	lines := make([]string, 0, len(candidates))
+	for i, candidate := range candidates {
+		name := candidate.Browser
+		if candidate.Profile != "" {
+			name = fmt.Sprintf("%s (%s)", name, candidate.Profile)
+		}
+		line := fmt.Sprintf("  %s: created %s, last written %s, expires %s", name,
+			formatReportTime(candidate.CreatedAt), formatReportTime(candidate.LastWriteAt), formatReportTime(candidate.ExpiresAt))
+		if i == freshest {
+			line += " (most recently refreshed)"
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// formatReportTime formats a time of the freshness report in local time, or "unknown"
+// for the zero time.
+func formatReportTime(t time.Time) string {
+	if t.IsZero() {
+		return "unknown"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
+
+// describeCandidate returns a human readable description of the browser store a
+// cookie candidate was read from, noting whether its cookies were validated.
+func describeCandidate(candidate types.CookieCandidate) string {
+	description := candidate.Browser
+	if candidate.Profile != "" {
+		description = fmt.Sprintf("%s (%s)", description, candidate.Profile)
+	}
+	if candidate.Validated {
+		description += ", validated"
+	}
+	return description
+}
+
+// browserLogin signs in to the site of ec with a headless Chromium, prompting on the
+// terminal for the credentials and the two-factor code if one is asked for, and returns
+// the session cookies it was given. Returns an error if the browser can't be started,
+// the sign in is rejected or doesn't complete within loginTimeout.
+func browserLogin(ec config.Extract) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), loginTimeout)
+	defer cancel()
+
+	page, err := login.NewChromePage(ctx, ec.BrowserPath)
+	if err != nil {
+		return nil, fmt.Errorf("error starting the headless browser, install Chromium or give its path with --browser-path: %w", err)
+	}
+	defer page.Close()
+
+	return login.Login(ctx, page, terminalPrompt(os.Stdin, formatters.Status()), login.Options{
+		CookieNames: ec.ValidCookies,
+		Domain:      formatters.CookieDomain(ec.BaseUrl),
+	})
+}
+
+// terminalPrompt returns a login.Prompt writing the labels to out and reading the
+// answers from in, one per line, the secret ones without echoing them when in is a
+// terminal.
+func terminalPrompt(in *os.File, out io.Writer) login.Prompt {
+	reader := bufio.NewReader(in)
+	return func(label string, secret bool) (string, error) {
+		fmt.Fprintf(out, "%s: ", label)
+		if secret && term.IsTerminal(int(in.Fd())) {
+			value, err := term.ReadPassword(int(in.Fd()))
+			fmt.Fprintln(out)
+			return string(value), err
+		}
+
+		line, err := reader.ReadString('\n')
+		if err != nil && line == "" {
+			return "", err
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	}
+}
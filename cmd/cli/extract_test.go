@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
 	"net/http"
 	"net/url"
@@ -11,8 +12,10 @@ import (
 
 	"github.com/browserutils/kooky"
 	_ "github.com/browserutils/kooky/browser/all"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
-	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -101,6 +104,9 @@ func TestExtractCookies_Success(t *testing.T) {
 	// Mock methods that are called by CookieExtractor
 	mockStore.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{cookie}, nil)
 	mockStore.On("Close").Return(nil)
+	mockStore.On("Browser").Return("MockBrowser").Maybe()
+	mockStore.On("Profile").Return("Default").Maybe()
+	mockStore.On("FilePath").Return("/mock/cookies").Maybe()
 
 	// Create a mock store provider to avoid using live cookie stores
 	mockStoreProvider := func() []kooky.CookieStore {
@@ -119,19 +125,19 @@ func TestExtractCookies_Success(t *testing.T) {
 		return nil // Simulate directory existence or creation
 	}
 
-	// Set the options (these can be set globally or adjusted as necessary)
-	options.BaseUrl = "http://example.com"
-	options.ValidCookies = []string{"session"}
-	options.OutputDirectory = tempDir
-	outputFilename = "session-cookies.json"
+	// Set the extract configuration
+	ec := config.Extract{
+		BaseUrl:         "http://example.com",
+		OutputDirectory: tempDir,
+		OutputFilename:  "session-cookies.json",
+		ValidCookies:    []string{"session"},
+	}
 
 	// Act: Call ExtractCookies using the mockStoreProvider
-	cmd := &cobra.Command{}
-	args := []string{}
-	err := ExtractCookies(cmd, args, mockStoreProvider)
+	err := ExtractCookies(ec, mockStoreProvider)
 
 	// Call SaveCookiesToJson with mocked functions
-	err = exporters.SaveCookiesToJson(options.OutputDirectory, outputFilename, map[string]string{"session": "1234"}, mockOpenFile, mockEnsureDirExists)
+	err = exporters.SaveCookiesToJson(ec.OutputDirectory, ec.OutputFilename, map[string]string{"session": "1234"}, mockOpenFile, mockEnsureDirExists)
 
 	// Assert: Verify no error and that all expectations on the mocks are met
 	assert.NoError(t, err)
@@ -165,19 +171,110 @@ func TestExtractCookies_ErrorInCookieExtractor(t *testing.T) {
 	// Mock ReadCookies and Close (since they are called internally)
 	mockStore.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{}, nil) // Return empty slice instead of nil
 	mockStore.On("Close").Return(nil)                                         // Simulate successful closing
-
-	// Set the options
-	options.BaseUrl = "http://example.com"
-	options.ValidCookies = []string{"session"}
-	options.OutputDirectory = "/tmp"
-	outputFilename = "session-cookies.json"
+	mockStore.On("Browser").Return("MockBrowser").Maybe()
+	mockStore.On("Profile").Return("Default").Maybe()
+	mockStore.On("FilePath").Return("/mock/cookies").Maybe()
+
+	// Set the extract configuration
+	ec := config.Extract{
+		BaseUrl:         "http://example.com",
+		OutputDirectory: "/tmp",
+		OutputFilename:  "session-cookies.json",
+		ValidCookies:    []string{"session"},
+	}
 
 	// Act: Call ExtractCookies using the mockStoreProvider
-	cmd := &cobra.Command{}
-	args := []string{}
-	err := ExtractCookies(cmd, args, mockStoreProvider)
+	err := ExtractCookies(ec, mockStoreProvider)
 
 	// Assert: Verify the error from CookieExtractor is returned
 	assert.Error(t, err)
 	assert.Equal(t, "no matching cookies found", err.Error())
 }
+
+func TestExtractCookies_LoginFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		loginErr error
+		exitCode int
+		saved    bool
+	}{
+		{name: "signed in", saved: true},
+		{name: "sign in rejected", loginErr: errors.New("sign in rejected: Invalid login or password."), exitCode: failures.ExitAuth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange: no browser store holds the cookies
+			original := loginFunc
+			loginFunc = func(ec config.Extract) (map[string]string, error) {
+				if tt.loginErr != nil {
+					return nil, tt.loginErr
+				}
+				return map[string]string{"session": "signed-in"}, nil
+			}
+			defer func() { loginFunc = original }()
+			dir := t.TempDir()
+			ec := config.Extract{BaseUrl: "http://example.com", Login: true, OutputDirectory: dir, OutputFilename: "session-cookies.json", ValidCookies: []string{"session"}}
+
+			// Act
+			err := ExtractCookies(ec, sessionStoreProvider(""))
+
+			// Assert
+			assert.Equal(t, tt.exitCode, failures.ExitCode(err))
+			data, readErr := os.ReadFile(filepath.Join(dir, "session-cookies.json"))
+			if tt.saved {
+				assert.NoError(t, readErr)
+				assert.JSONEq(t, `{"session": "signed-in"}`, string(data))
+			} else {
+				assert.True(t, os.IsNotExist(readErr))
+			}
+		})
+	}
+}
+
+func TestTerminalPrompt(t *testing.T) {
+	// Arrange: answers piped from a file rather than typed on a terminal
+	in, err := os.CreateTemp(t.TempDir(), "answers")
+	if err != nil {
+		t.Fatalf("Failed to create answers file: %v", err)
+	}
+	defer in.Close()
+	_, _ = in.WriteString("me@example.com\r\n pass word \n")
+	_, _ = in.Seek(0, 0)
+	var out bytes.Buffer
+	prompt := terminalPrompt(in, &out)
+
+	// Act
+	username, usernameErr := prompt("Username or email", false)
+	password, passwordErr := prompt("Password", true)
+	_, eofErr := prompt("Two-factor code", false)
+
+	// Assert
+	assert.NoError(t, usernameErr)
+	assert.NoError(t, passwordErr)
+	assert.Equal(t, "me@example.com", username)
+	assert.Equal(t, " pass word ", password)
+	assert.Error(t, eofErr)
+	assert.Equal(t, "Username or email: Password: Two-factor code: ", out.String())
+}
+
+func TestFreshnessReport(t *testing.T) {
+	// Arrange
+	older := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.Local)
+	newer := older.Add(48 * time.Hour)
+	candidates := []types.CookieCandidate{
+		{Browser: "chrome", Profile: "Default", CreatedAt: older, LastWriteAt: older, ExpiresAt: older.AddDate(0, 1, 0)},
+		{Browser: "firefox", CreatedAt: older, LastWriteAt: newer},
+		{Browser: "edge"},
+	}
+
+	// Act
+	lines := freshnessReport(candidates)
+
+	// Assert
+	assert.Equal(t, []string{
+		"  chrome (Default): created 2024-06-01 12:00, last written 2024-06-01 12:00, expires 2024-07-01 12:00",
+		"  firefox: created 2024-06-01 12:00, last written 2024-06-03 12:00, expires unknown (most recently refreshed)",
+		"  edge: created unknown, last written unknown, expires unknown",
+	}, lines)
+}
@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// gameInfoCmd is a Cobra command used for scraping a game's landing page.
+	gameInfoCmd = &cobra.Command{}
+	// gameInfoOptions holds the command-line flag values of the game-info command.
+	gameInfoOptions = config.GameInfo{}
+	// fetchGameInfoFunc is a variable that holds a reference to the function used for
+	// fetching a game's landing page.
+	fetchGameInfoFunc = fetchers.FetchGameInfo
+)
+
+// init initializes the game-info command with usage, description, and argument
+// validation. It registers the game-info flags and adds the command to the root command.
+func init() {
+	gameInfoCmd = &cobra.Command{
+		Use:               "game-info <game name> [flags]",
+		Short:             "Scrape game metadata",
+		Long:              "Scrape a game's landing page (mod, collection and download counts, categories with their mod counts, most endorsed mods this month) and return a JSON output",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			gc, err := config.LoadGameInfo(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(gc.BaseUrl, gc.CookieDirectory, gc.CookieFile, gc.CookieHeader)
+			if err != nil {
+				return err
+			}
+
+			return scrapeGameInfo(gc, args[0], fetchGameInfoFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterGameInfoFlags(gameInfoCmd, &gameInfoOptions)
+	RootCmd.AddCommand(gameInfoCmd)
+}
+
+// scrapeGameInfo scrapes the landing page of the game, displays the GameInfo as JSON and,
+// when requested, saves it as game-info.json in the game's output directory. Returns an
+// error if the page can't be scraped or the results can't be saved.
+func scrapeGameInfo(
+	gc config.GameInfo,
+	game string,
+	fetchGameInfoFunc func(ctx context.Context, baseUrl, game string, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameInfo, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	game = strings.ToLower(game)
+
+	info, err := fetchGameInfoFunc(context.Background(), gc.BaseUrl, game, fetchDocumentFunc)
+	if err != nil {
+		return fmt.Errorf("error scraping game %s: %w", game, err)
+	}
+
+	jsonData, err := json.MarshalIndent(info, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal game information: %w", err)
+	}
+	if err := formatters.PrintPrettyJson(string(jsonData)); err != nil {
+		return err
+	}
+
+	if gc.SaveResults {
+		outputGameDirectory := filepath.Join(gc.OutputDirectory, game)
+		savedPath, err := exporters.SaveModInfoToJson(types.CliFlags{}, info, outputGameDirectory, "game-info", utils.EnsureDirExists)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(formatters.Status(), "Game info saved to %s\n", formatters.PathLink(savedPath))
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeGameInfo_SavesResults(t *testing.T) {
+	// Arrange
+	outputDir := t.TempDir()
+	gc := config.GameInfo{BaseUrl: "https://example.com", OutputDirectory: outputDir, SaveResults: true}
+	fetchGameInfo := func(_ context.Context, baseUrl, game string, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameInfo, error) {
+		return types.GameInfo{Game: game, Name: "Skyrim", ModCount: 42}, nil
+	}
+
+	// Act
+	err := scrapeGameInfo(gc, "Skyrim", fetchGameInfo, nil)
+
+	// Assert
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(outputDir, "skyrim", "game-info.json"))
+	require.NoError(t, err)
+
+	var saved types.GameInfo
+	require.NoError(t, json.Unmarshal(content, &saved))
+	assert.Equal(t, int64(42), saved.ModCount)
+	assert.Equal(t, "skyrim", saved.Game)
+}
+
+func TestScrapeGameInfo_FetchError(t *testing.T) {
+	fetchGameInfo := func(_ context.Context, baseUrl, game string, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameInfo, error) {
+		return types.GameInfo{}, errors.New("boom")
+	}
+
+	err := scrapeGameInfo(config.GameInfo{}, "skyrim", fetchGameInfo, nil)
+
+	assert.EqualError(t, err, "error scraping game skyrim: boom")
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/ignore"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// ignoreCmd is the parent Cobra command for managing the ignore list.
+	ignoreCmd = &cobra.Command{}
+	// ignoreAddCmd is a Cobra command that adds a mod to the ignore list.
+	ignoreAddCmd = &cobra.Command{}
+	// ignoreListCmd is a Cobra command that lists the ignored mods.
+	ignoreListCmd = &cobra.Command{}
+	// ignoreRemoveCmd is a Cobra command that removes a mod from the ignore list.
+	ignoreRemoveCmd = &cobra.Command{}
+	// ignoreOptions holds the command-line flag values of the ignore subcommands.
+	ignoreOptions = config.Ignore{}
+)
+
+// init initializes the ignore command and its add, remove and list subcommands,
+// registering their flags and adding them to the root command.
+func init() {
+	ignoreCmd = &cobra.Command{
+		Use:   "ignore",
+		Short: "Manage the ignore list",
+		Long:  "Manage the list of mods skipped by the scrape, scrape-collection, watch and watch-author commands, e.g. mods known to be broken or irrelevant",
+	}
+
+	ignoreAddCmd = &cobra.Command{
+		Use:               "add <game name> <mod id> [flags]",
+		Short:             "Ignore a mod",
+		Long:              "Add a mod to the ignore list so that it is skipped, with a note in the run summary, from now on",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ic, err := config.LoadIgnore(cmd)
+			if err != nil {
+				return err
+			}
+			return addIgnored(cmd.OutOrStdout(), ic, args[0], args[1], time.Now())
+		},
+	}
+
+	ignoreRemoveCmd = &cobra.Command{
+		Use:               "remove <game name> <mod id> [flags]",
+		Short:             "Stop ignoring a mod",
+		Long:              "Remove a mod from the ignore list so that it is scraped again",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ic, err := config.LoadIgnore(cmd)
+			if err != nil {
+				return err
+			}
+			return removeIgnored(cmd.OutOrStdout(), ic, args[0], args[1])
+		},
+	}
+
+	ignoreListCmd = &cobra.Command{
+		Use:   "list [flags]",
+		Short: "List the ignored mods",
+		Long:  "List the mods of the ignore list, with the reason each is ignored for and when it was added",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ic, err := config.LoadIgnore(cmd)
+			if err != nil {
+				return err
+			}
+			return listIgnored(cmd.OutOrStdout(), ic)
+		},
+	}
+
+	config.RegisterIgnoreFlags(ignoreAddCmd, &ignoreOptions)
+	config.RegisterIgnoreFileFlag(ignoreRemoveCmd, &ignoreOptions.IgnoreFile)
+	config.RegisterIgnoreFileFlag(ignoreListCmd, &ignoreOptions.IgnoreFile)
+	ignoreCmd.AddCommand(ignoreAddCmd)
+	ignoreCmd.AddCommand(ignoreRemoveCmd)
+	ignoreCmd.AddCommand(ignoreListCmd)
+	RootCmd.AddCommand(ignoreCmd)
+}
+
+// addIgnored adds the mod of the game to the ignore list, along with the configured
+// reason and the time it was added. Returns an error if the mod ID is invalid or the
+// list can't be read or saved.
+func addIgnored(w io.Writer, ic config.Ignore, game, modID string, now time.Time) error {
+	slug, id, err := parseModArgs(game, modID)
+	if err != nil {
+		return err
+	}
+
+	list, err := ignore.Load(ic.IgnoreFile)
+	if err != nil {
+		return err
+	}
+
+	list, added, err := list.Add(ignore.Entry{AddedAt: now, Game: slug.String(), ModID: id.Int64(), Reason: ic.Reason})
+	if err != nil {
+		return err
+	}
+	if !added {
+		fmt.Fprintf(w, "Mod %d of %s is already ignored\n", id, slug)
+		return nil
+	}
+
+	if err := ignore.Save(ic.IgnoreFile, list); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Ignoring mod %d of %s\n", id, slug)
+	return nil
+}
+
+// removeIgnored removes the mod of the game from the ignore list. Returns an error if
+// the mod ID is invalid or the list can't be read or saved.
+func removeIgnored(w io.Writer, ic config.Ignore, game, modID string) error {
+	slug, id, err := parseModArgs(game, modID)
+	if err != nil {
+		return err
+	}
+
+	list, err := ignore.Load(ic.IgnoreFile)
+	if err != nil {
+		return err
+	}
+
+	list, removed := list.Remove(slug.String(), id.Int64())
+	if !removed {
+		fmt.Fprintf(w, "Mod %d of %s isn't ignored\n", id, slug)
+		return nil
+	}
+
+	if err := ignore.Save(ic.IgnoreFile, list); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "No longer ignoring mod %d of %s\n", id, slug)
+	return nil
+}
+
+// listIgnored writes the mods of the ignore list to w as a table. Returns an error if
+// the list can't be read.
+func listIgnored(w io.Writer, ic config.Ignore) error {
+	list, err := ignore.Load(ic.IgnoreFile)
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		fmt.Fprintln(w, "No mods are ignored")
+		return nil
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Game\tMod ID\tAdded\tReason")
+	for _, entry := range list {
+		added := "unknown"
+		if !entry.AddedAt.IsZero() {
+			added = entry.AddedAt.Local().Format("2006-01-02")
+		}
+		fmt.Fprintf(table, "%s\t%d\t%s\t%s\n", entry.Game, entry.ModID, added, entry.Reason)
+	}
+	return table.Flush()
+}
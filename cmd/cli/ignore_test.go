@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/ignore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIgnoreAddRemoveList(t *testing.T) {
+	// Arrange
+	ic := config.Ignore{IgnoreFile: filepath.Join(t.TempDir(), ignore.DefaultFilename), Reason: "broken"}
+	added := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.Local)
+	var out bytes.Buffer
+
+	// Act
+	require.NoError(t, addIgnored(&out, ic, "Skyrim", "3863", added))
+	require.NoError(t, addIgnored(&out, ic, "skyrim", "3863", added))
+	require.NoError(t, addIgnored(&out, config.Ignore{IgnoreFile: ic.IgnoreFile}, "fallout4", "1", added))
+	require.NoError(t, removeIgnored(&out, ic, "fallout4", "1"))
+	require.NoError(t, removeIgnored(&out, ic, "fallout4", "1"))
+	var listed bytes.Buffer
+	require.NoError(t, listIgnored(&listed, ic))
+
+	// Assert
+	assert.Equal(t, "Ignoring mod 3863 of skyrim\n"+
+		"Mod 3863 of skyrim is already ignored\n"+
+		"Ignoring mod 1 of fallout4\n"+
+		"No longer ignoring mod 1 of fallout4\n"+
+		"Mod 1 of fallout4 isn't ignored\n", out.String())
+	assert.Equal(t, "Game    Mod ID  Added       Reason\n"+
+		"skyrim  3863    2024-06-01  broken\n", listed.String())
+}
+
+func TestIgnore_InvalidModID(t *testing.T) {
+	ic := config.Ignore{IgnoreFile: filepath.Join(t.TempDir(), ignore.DefaultFilename)}
+
+	addErr := addIgnored(&bytes.Buffer{}, ic, "skyrim", "abc", time.Now())
+	removeErr := removeIgnored(&bytes.Buffer{}, ic, "skyrim", "abc")
+
+	assert.EqualError(t, addErr, `invalid mod id "abc"`)
+	assert.EqualError(t, removeErr, `invalid mod id "abc"`)
+}
+
+func TestListIgnored_Empty(t *testing.T) {
+	var out bytes.Buffer
+
+	err := listIgnored(&out, config.Ignore{IgnoreFile: filepath.Join(t.TempDir(), ignore.DefaultFilename)})
+
+	require.NoError(t, err)
+	assert.Equal(t, "No mods are ignored\n", out.String())
+}
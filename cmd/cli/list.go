@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// listCmd is a Cobra command used for listing the mods updated within a date window.
+	listCmd = &cobra.Command{}
+	// listOptions holds the command-line flag values of the list command.
+	listOptions = config.List{}
+	// fetchUpdatedModsFunc is a variable that holds a reference to the function used for
+	// walking the updated mods listing of a game.
+	fetchUpdatedModsFunc = fetchers.FetchUpdatedMods
+)
+
+// init initializes the list command with usage, description, and argument validation.
+// It registers the list flags and adds the command to the root command.
+func init() {
+	listCmd = &cobra.Command{
+		Use:               "list <game name> [flags]",
+		Short:             "List mods updated within a date window",
+		Long:              "Walk the updated mods listing of a game until the date window is exhausted and output the IDs of the mods updated within it, ready to pass to scrape",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			lc, err := config.LoadList(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(lc.BaseUrl, lc.CookieDirectory, lc.CookieFile, lc.CookieHeader)
+			if err != nil {
+				return failures.WithKind(err, failures.KindAuth)
+			}
+
+			return listMods(cmd.OutOrStdout(), cmd.ErrOrStderr(), lc, args[0], fetchUpdatedModsFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterListFlags(listCmd, &listOptions)
+	RootCmd.AddCommand(listCmd)
+}
+
+// listMods walks the updated mods listing of the game for the configured date window
+// and writes the mods found to w in the configured format, reporting how many were
+// found to status so the output stays pipeable. Returns an error if the format is
+// unknown, a date can't be parsed or the listing can't be fetched.
+func listMods(
+	w, status io.Writer,
+	lc config.List,
+	game string,
+	fetchUpdatedModsFunc func(ctx context.Context, baseUrl, game string, after, before time.Time, maxPages int, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) ([]types.ListedMod, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	format := strings.ToLower(lc.Format)
+	if format != "ids" && format != "json" {
+		return fmt.Errorf("unsupported format %q, expected ids or json", lc.Format)
+	}
+
+	if lc.UpdatedAfter == "" {
+		return fmt.Errorf("--updated-after is required")
+	}
+	after, err := formatters.ParseNexusDate(lc.UpdatedAfter)
+	if err != nil {
+		return fmt.Errorf("invalid --updated-after: %w", err)
+	}
+
+	var before time.Time
+	if lc.UpdatedBefore != "" {
+		if before, err = formatters.ParseNexusDate(lc.UpdatedBefore); err != nil {
+			return fmt.Errorf("invalid --updated-before: %w", err)
+		}
+		if !before.After(after) {
+			return fmt.Errorf("--updated-before must be later than --updated-after")
+		}
+	}
+
+	game = strings.ToLower(game)
+	mods, err := fetchUpdatedModsFunc(context.Background(), lc.BaseUrl, game, after, before, lc.MaxPages, fetchDocumentFunc)
+	if err != nil {
+		return fmt.Errorf("error listing updated %s mods: %w", game, err)
+	}
+	fmt.Fprintf(status, "Found %d %s mods updated within the window\n", len(mods), game)
+
+	if format == "json" {
+		if mods == nil {
+			mods = []types.ListedMod{}
+		}
+		data, err := json.MarshalIndent(mods, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting mods: %v", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	if len(mods) == 0 {
+		return nil
+	}
+	ids := make([]string, len(mods))
+	for i, mod := range mods {
+		ids[i] = strconv.FormatInt(mod.ModID, 10)
+	}
+	_, err = fmt.Fprintln(w, strings.Join(ids, ","))
+	return err
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListMods_Ids(t *testing.T) {
+	// Arrange
+	var out, status bytes.Buffer
+	var gotGame string
+	var gotAfter, gotBefore time.Time
+	fetch := func(_ context.Context, _, game string, after, before time.Time, _ int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) ([]types.ListedMod, error) {
+		gotGame, gotAfter, gotBefore = game, after, before
+		return []types.ListedMod{{Game: game, ModID: 12}, {Game: game, ModID: 7}}, nil
+	}
+	lc := config.List{Format: "ids", UpdatedAfter: "2024-06-01", UpdatedBefore: "2024-07-01"}
+
+	// Act
+	err := listMods(&out, &status, lc, "Skyrim", fetch, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "skyrim", gotGame)
+	assert.Equal(t, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), gotAfter)
+	assert.Equal(t, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC), gotBefore)
+	assert.Equal(t, "12,7\n", out.String())
+	assert.Equal(t, "Found 2 skyrim mods updated within the window\n", status.String())
+}
+
+func TestListMods_Json(t *testing.T) {
+	// Arrange
+	var out, status bytes.Buffer
+	fetch := func(_ context.Context, _, _ string, _, _ time.Time, _ int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) ([]types.ListedMod, error) {
+		return nil, nil
+	}
+
+	// Act
+	err := listMods(&out, &status, config.List{Format: "json", UpdatedAfter: "2024-06-01"}, "skyrim", fetch, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "[]\n", out.String())
+}
+
+func TestListMods_Errors(t *testing.T) {
+	fetch := func(_ context.Context, _, _ string, _, _ time.Time, _ int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) ([]types.ListedMod, error) {
+		return nil, errors.New("boom")
+	}
+
+	tests := []struct {
+		name    string
+		lc      config.List
+		wantErr string
+	}{
+		{name: "unknown format", lc: config.List{Format: "csv", UpdatedAfter: "2024-06-01"}, wantErr: `unsupported format "csv", expected ids or json`},
+		{name: "missing after", lc: config.List{Format: "ids"}, wantErr: "--updated-after is required"},
+		{name: "invalid after", lc: config.List{Format: "ids", UpdatedAfter: "june"}, wantErr: `invalid --updated-after: unrecognised date format: "june"`},
+		{name: "empty window", lc: config.List{Format: "ids", UpdatedAfter: "2024-07-01", UpdatedBefore: "2024-06-01"}, wantErr: "--updated-before must be later than --updated-after"},
+		{name: "fetch error", lc: config.List{Format: "ids", UpdatedAfter: "2024-06-01"}, wantErr: "error listing updated skyrim mods: boom"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out, status bytes.Buffer
+
+			err := listMods(&out, &status, tt.lc, "skyrim", fetch, nil)
+
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/profile"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// profileCmd is the parent Cobra command for managing the profiles.
+	profileCmd = &cobra.Command{}
+	// profileCreateCmd is a Cobra command that creates a profile.
+	profileCreateCmd = &cobra.Command{}
+	// profileDeleteCmd is a Cobra command that deletes a profile.
+	profileDeleteCmd = &cobra.Command{}
+	// profileListCmd is a Cobra command that lists the profiles.
+	profileListCmd = &cobra.Command{}
+	// profileOptions holds the command-line flag values of the profile subcommands.
+	profileOptions = config.Profile{}
+)
+
+// init initializes the profile command and its create, delete and list subcommands,
+// registering their flags and adding them to the root command.
+func init() {
+	profileCmd = &cobra.Command{
+		Use:   "profile",
+		Short: "Manage the profiles",
+		Long:  "Manage the profiles selected with --profile, each with its own cookie file, configuration file and output directory, e.g. a personal and an archival account",
+	}
+
+	profileCreateCmd = &cobra.Command{
+		Use:   "create <name>",
+		Short: "Create a profile",
+		Long:  "Create a profile, then run extract --profile <name> to save its cookies",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return createProfile(cmd.OutOrStdout(), args[0])
+		},
+	}
+
+	profileDeleteCmd = &cobra.Command{
+		Use:   "delete <name> [flags]",
+		Short: "Delete a profile",
+		Long:  "Delete a profile along with its cookies, configuration and the results saved in its default output directory",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pc, err := config.LoadProfile(cmd)
+			if err != nil {
+				return err
+			}
+			return deleteProfile(cmd.OutOrStdout(), pc, args[0])
+		},
+	}
+
+	profileListCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List the profiles",
+		Long:  "List the default profile and the created ones with their directory, marking the active one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return listProfiles(cmd.OutOrStdout())
+		},
+	}
+
+	config.RegisterProfileFlags(profileDeleteCmd, &profileOptions)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+	profileCmd.AddCommand(profileListCmd)
+	RootCmd.AddCommand(profileCmd)
+}
+
+// createProfile creates the named profile. Returns an error if the name is invalid or
+// the profile already exists.
+func createProfile(w io.Writer, name string) error {
+	if err := profile.Create(name); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Created profile %s in %s\n", name, storage.ProfilePath(name))
+	return nil
+}
+
+// deleteProfile deletes the named profile. A profile holding files is only deleted
+// when confirmed with --yes, and the active profile can't be deleted. Returns an error
+// if the profile doesn't exist or can't be deleted.
+func deleteProfile(w io.Writer, pc config.Profile, name string) error {
+	if err := profile.ValidateName(name); err != nil {
+		return err
+	}
+	if name == storage.ActiveProfile() {
+		return fmt.Errorf("profile %s is active, it can't be deleted", name)
+	}
+	if !profile.Exists(name) {
+		return fmt.Errorf("profile %s doesn't exist", name)
+	}
+
+	files, err := profile.Files(name)
+	if err != nil {
+		return err
+	}
+	if files > 0 && !pc.Yes {
+		return fmt.Errorf("profile %s holds %d files, delete it with --yes", name, files)
+	}
+
+	if err := profile.Delete(name); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Deleted profile %s\n", name)
+	return nil
+}
+
+// listProfiles writes the default profile and the created ones, with their directory,
+// marking the active profile with an asterisk. Returns an error if the profiles can't
+// be read.
+func listProfiles(w io.Writer) error {
+	names, err := profile.List()
+	if err != nil {
+		return err
+	}
+
+	active := storage.ActiveProfile()
+	if active == "" {
+		active = profile.Default
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tProfile\tDirectory")
+	for _, name := range append([]string{profile.Default}, names...) {
+		marker := ""
+		if name == active {
+			marker = "*"
+		}
+		dir := storage.ProfilePath(name)
+		if name == profile.Default {
+			dir = storage.DefaultProfilePath()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", marker, name, dir)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfileCreateListDelete(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	var out bytes.Buffer
+
+	// Act
+	require.NoError(t, createProfile(&out, "archival"))
+	require.NoError(t, os.WriteFile(filepath.Join(storage.ProfilePath("archival"), "session-cookies.json"), []byte("{}"), 0644))
+	var listed bytes.Buffer
+	require.NoError(t, listProfiles(&listed))
+	unconfirmedErr := deleteProfile(&out, config.Profile{}, "archival")
+	require.NoError(t, deleteProfile(&out, config.Profile{Yes: true}, "archival"))
+
+	// Assert
+	assert.Equal(t, "Created profile archival in "+storage.ProfilePath("archival")+"\n"+
+		"Deleted profile archival\n", out.String())
+	assert.Contains(t, listed.String(), "*  default   "+storage.DefaultProfilePath())
+	assert.Contains(t, listed.String(), "   archival  "+storage.ProfilePath("archival"))
+	assert.EqualError(t, unconfirmedErr, "profile archival holds 1 files, delete it with --yes")
+	assert.NoDirExists(t, storage.ProfilePath("archival"))
+}
+
+func TestActivateProfile(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	defer storage.SetProfile("")
+	require.NoError(t, createProfile(&bytes.Buffer{}, "archival"))
+
+	cmd := &cobra.Command{Use: "extract"}
+	config.RegisterProfileFlag(cmd)
+	config.RegisterExtractFlags(cmd, &config.Extract{})
+	require.NoError(t, cmd.ParseFlags([]string{"--profile", "archival", "--output-filename", "cookies.json"}))
+
+	// Act
+	err := activateProfile(cmd)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "archival", storage.ActiveProfile())
+	ec, err := config.LoadExtract(cmd)
+	require.NoError(t, err)
+	assert.Equal(t, storage.ProfilePath("archival"), ec.OutputDirectory)
+	assert.Equal(t, "cookies.json", ec.OutputFilename)
+}
+
+func TestActivateProfile_Missing(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+	defer storage.SetProfile("")
+	cmd := &cobra.Command{Use: "extract"}
+	config.RegisterProfileFlag(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--profile", "work"}))
+
+	err := activateProfile(cmd)
+
+	assert.EqualError(t, err, "profile work doesn't exist, create it with profile create work")
+}
@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/store"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// queryCmd is a Cobra command used for searching the saved mods.
+	queryCmd = &cobra.Command{}
+	// queryOptions holds the command-line flag values of the query command.
+	queryOptions = config.Query{}
+)
+
+// init initializes the query command with usage, description, and argument validation.
+// It registers the query flags and adds the command to the root command.
+func init() {
+	queryCmd = &cobra.Command{
+		Use:   "query --search <terms> [flags]",
+		Short: "Search the saved mods",
+		Long:  "Full-text search the names, descriptions and changelogs of the mods saved in the output directory, offline, through a SQLite index kept up to date with the saved files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			qc, err := config.LoadQuery(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Saved dates are parsed using the format they were written in
+			types.TimestampFormat = formatters.DateLayout(qc.DateFormat)
+
+			return queryMods(cmd.OutOrStdout(), cmd.ErrOrStderr(), qc)
+		},
+	}
+
+	config.RegisterQueryFlags(queryCmd, &queryOptions)
+	RootCmd.AddCommand(queryCmd)
+}
+
+// queryMods brings the search index up to date with the saved mods, reporting the
+// changes to status, then writes the mods matching the search to w in the configured
+// format. Returns an error if the search or format is invalid, or the index can't be
+// updated or searched.
+func queryMods(w, status io.Writer, qc config.Query) error {
+	format := strings.ToLower(qc.Format)
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format %q, expected text or json", qc.Format)
+	}
+	if strings.TrimSpace(qc.Search) == "" {
+		return fmt.Errorf("--search is required")
+	}
+
+	index, err := store.OpenSearchIndex(qc.IndexFile)
+	if err != nil {
+		return err
+	}
+	defer index.Close()
+
+	stats, err := index.Sync(qc.OutputDirectory, qc.RebuildIndex)
+	if err != nil {
+		return err
+	}
+	if stats.Added+stats.Updated+stats.Removed > 0 {
+		fmt.Fprintf(status, "Indexed %d new and %d changed saved mods, removed %d\n", stats.Added, stats.Updated, stats.Removed)
+	}
+	if stats.Skipped > 0 {
+		fmt.Fprintf(status, "Skipped %d saved files that couldn't be read\n", stats.Skipped)
+	}
+
+	hits, err := index.Search(qc.Search, qc.Limit)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(hits, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting results: %v", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	if len(hits) == 0 {
+		fmt.Fprintf(w, "No saved mods match %q\n", qc.Search)
+		return nil
+	}
+	for _, hit := range hits {
+		fmt.Fprintf(w, "%s/%d  %s\n", hit.Game, hit.ModID, hit.Name)
+		if snippet := strings.Join(strings.Fields(hit.Snippet), " "); snippet != "" && snippet != hit.Name {
+			fmt.Fprintf(w, "    %s\n", snippet)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/store"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryMods(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skyrim"), os.ModePerm))
+	data, err := json.Marshal(types.Results{Mods: types.ModInfo{ModID: 1234, Name: "Open Cities Skyrim", Description: "Opens the cities."}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skyrim", "open cities skyrim 1234.json"), data, 0644))
+
+	qc := config.Query{
+		Format:          "text",
+		IndexFile:       filepath.Join(t.TempDir(), store.DefaultSearchIndexFilename),
+		Limit:           20,
+		OutputDirectory: dir,
+		Search:          "open cities",
+	}
+	var out, status bytes.Buffer
+
+	// Act
+	err = queryMods(&out, &status, qc)
+	require.NoError(t, err)
+	text := out.String()
+
+	out.Reset()
+	status.Reset()
+	qc.Format = "json"
+	jsonErr := queryMods(&out, &status, qc)
+
+	// Assert
+	assert.Equal(t, "skyrim/1234  Open Cities Skyrim\n    [Open] [Cities] Skyrim\n", text)
+	assert.NoError(t, jsonErr)
+	assert.Empty(t, status.String(), "unchanged files aren't indexed again")
+	var hits []store.SearchHit
+	require.NoError(t, json.Unmarshal(out.Bytes(), &hits))
+	require.Len(t, hits, 1)
+	assert.Equal(t, int64(1234), hits[0].ModID)
+}
+
+func TestQueryMods_NoMatch(t *testing.T) {
+	// Arrange
+	qc := config.Query{
+		Format:          "text",
+		IndexFile:       filepath.Join(t.TempDir(), store.DefaultSearchIndexFilename),
+		OutputDirectory: t.TempDir(),
+		Search:          "dragons",
+	}
+	var out, status bytes.Buffer
+
+	// Act
+	err := queryMods(&out, &status, qc)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "No saved mods match \"dragons\"\n", out.String())
+}
+
+func TestQueryMods_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		qc      config.Query
+		wantErr string
+	}{
+		{name: "format", qc: config.Query{Format: "csv", Search: "x"}, wantErr: `unsupported format "csv", expected text or json`},
+		{name: "search", qc: config.Query{Format: "text"}, wantErr: "--search is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.EqualError(t, queryMods(&bytes.Buffer{}, &bytes.Buffer{}, tt.qc), tt.wantErr)
+		})
+	}
+}
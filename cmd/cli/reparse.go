@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/htmlarchive"
+	"github.com/ondrovic/nexus-mods-scraper/internal/summary"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// reparseCmd is a Cobra command used for re-running the extractors on archived HTML.
+	reparseCmd = &cobra.Command{}
+	// reparseOptions holds the command-line flag values of the reparse command.
+	reparseOptions = config.Reparse{}
+)
+
+// init initializes the reparse command with usage, description, and argument
+// validation. It registers the reparse flags and adds the command to the root command.
+func init() {
+	reparseCmd = &cobra.Command{
+		Use:   "reparse <file or directory>... [flags]",
+		Short: "Re-parse archived HTML",
+		Long:  "Run the extractors again, offline, against the HTML archived with scrape --archive-html and overwrite the saved results. Accepts saved results, archived pages, or directories searched for archived pages",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rc, err := config.LoadReparse(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Parsed dates and empty lists are serialized as requested
+			types.TimestampFormat = formatters.DateLayout(rc.DateFormat)
+			types.KeepEmptyFields = rc.KeepEmptyFields
+
+			paths, err := reparseTargets(args)
+			if err != nil {
+				return err
+			}
+			return reparse(cmd.OutOrStdout(), rc, paths, fetchModInfoFunc)
+		},
+	}
+
+	config.RegisterReparseFlags(reparseCmd, &reparseOptions)
+	RootCmd.AddCommand(reparseCmd)
+}
+
+// reparseTargets resolves the arguments of the reparse command to the saved results to
+// re-parse. Archived pages resolve to the results they belong to, and directories are
+// searched recursively for archived main pages. Results are listed once, in the order
+// found. Returns an error if a directory can't be read.
+func reparseTargets(args []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		if !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+
+	for _, arg := range args {
+		// Missing paths are kept so that they are reported along with the other failures
+		if info, err := fsys.Default.Stat(arg); err == nil && info.IsDir() {
+			found, err := archivedResults(arg)
+			if err != nil {
+				return nil, err
+			}
+			for _, path := range found {
+				add(path)
+			}
+			continue
+		}
+
+		if path, ok := htmlarchive.ResultsPath(arg); ok {
+			add(path)
+			continue
+		}
+		add(arg)
+	}
+	return paths, nil
+}
+
+// archivedResults returns the saved results of every archived main page found in dir
+// and its subdirectories.
+func archivedResults(dir string) ([]string, error) {
+	entries, err := fsys.Default.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %w", dir, err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			found, err := archivedResults(path)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, found...)
+			continue
+		}
+		if strings.HasSuffix(entry.Name(), htmlarchive.PageSuffix) {
+			resultsPath, _ := htmlarchive.ResultsPath(path)
+			paths = append(paths, resultsPath)
+		}
+	}
+	return paths, nil
+}
+
+// reparse re-extracts each of the saved results from the pages archived next to it and
+// overwrites it, skipping summary indexes. No request is made: the game is taken from
+// the directory holding the results, and the time the pages were fetched is kept as the
+// time the mod was last checked. A result that can't be re-parsed is reported and the
+// others are still processed. Returns an error if any of them fail.
+func reparse(
+	w io.Writer,
+	rc config.Reparse,
+	paths []string,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+) error {
+	failed, total := 0, 0
+	for _, path := range paths {
+		// Let a glob over a game directory include the summary index
+		if filepath.Base(path) == summary.Filename {
+			fmt.Fprintf(w, "Skipping %s, it is a summary index\n", path)
+			continue
+		}
+		total++
+
+		savedPath, err := reparseResult(rc, path, fetchModInfoFunc)
+		if err != nil {
+			fmt.Fprintf(w, "Error re-parsing %s: %v\n", path, err)
+			failed++
+			continue
+		}
+		fmt.Fprintf(w, "Re-parsed %s\n", savedPath)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d results failed to re-parse", failed, total)
+	}
+	return nil
+}
+
+// reparseResult re-extracts the results saved at path from their archived pages and
+// overwrites them, returning the path written.
+func reparseResult(
+	rc config.Reparse,
+	path string,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+) (string, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Only the fields identifying the scrape are needed, the rest is re-extracted
+	var saved struct {
+		Meta *types.ScrapeMeta `json:"ScrapeMeta"`
+		Mods struct {
+			LastChecked time.Time `json:"LastChecked"`
+			ModID       int64     `json:"ModID"`
+		} `json:"Mods"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return "", fmt.Errorf("error decoding results: %w", err)
+	}
+	if saved.Mods.ModID == 0 {
+		return "", fmt.Errorf("results have no mod ID")
+	}
+
+	dir := filepath.Dir(path)
+	name := strings.TrimSuffix(filepath.Base(path), ".json")
+	game, modID, err := parseMod(filepath.Base(dir), saved.Mods.ModID)
+	if err != nil {
+		return "", err
+	}
+
+	results, err := fetchModInfoFunc(context.Background(), rc.BaseUrl, game, modID, nil, utils.ConcurrentFetch, htmlarchive.Fetcher(dir, name))
+	if err != nil {
+		return "", err
+	}
+	// The pages are the ones of the original scrape, which the metadata still describes
+	results.Mods.LastChecked = saved.Mods.LastChecked
+	results.Meta = saved.Meta
+
+	if rc.DisplayResults {
+		if err := exporters.DisplayResults(types.CliFlags{}, results, formatters.FormatResultsAsJson); err != nil {
+			return "", err
+		}
+	}
+
+	return exporters.SaveModInfoToJson(types.CliFlags{}, results, dir, name, utils.EnsureDirExists)
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/htmlarchive"
+	"github.com/ondrovic/nexus-mods-scraper/internal/summary"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReparse(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	gameDir := filepath.Join(tempDir, "skyrim")
+	require.NoError(t, os.MkdirAll(gameDir, os.ModePerm))
+	checked := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	meta := &types.ScrapeMeta{Duration: "1.2s", SchemaVersion: 1, ScrapedAt: types.NewTimestamp(checked), ScraperVersion: "v1.0.0", SourceUrl: "https://nexusmods.com/skyrim/mods/42"}
+	data, err := json.Marshal(types.Results{Meta: meta, Mods: types.ModInfo{Name: "Old Name", ModID: 42, LastChecked: checked}})
+	require.NoError(t, err)
+	resultsPath := filepath.Join(gameDir, "old name 42.json")
+	require.NoError(t, os.WriteFile(resultsPath, data, 0644))
+
+	pagePath, filesPath := htmlarchive.Paths(gameDir, "old name 42")
+	for path, html := range map[string]string{
+		pagePath:  `<html><body><div id="pagetitle"><h1>Fixed Name</h1></div></body></html>`,
+		filesPath: `<html><body></body></html>`,
+	} {
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+		require.NoError(t, err)
+		require.NoError(t, htmlarchive.Write(path, doc))
+	}
+
+	var buf bytes.Buffer
+
+	// Act
+	err = reparse(&buf, config.Reparse{BaseUrl: "https://nexusmods.com"}, []string{resultsPath, filepath.Join(gameDir, "missing 1.json"), filepath.Join(gameDir, summary.Filename)}, fetchers.FetchModInfoConcurrent)
+
+	// Assert
+	assert.EqualError(t, err, "1 of 2 results failed to re-parse")
+	assert.Contains(t, buf.String(), "Re-parsed "+resultsPath)
+	assert.Contains(t, buf.String(), "Error re-parsing "+filepath.Join(gameDir, "missing 1.json"))
+	assert.Contains(t, buf.String(), "Skipping "+filepath.Join(gameDir, summary.Filename))
+
+	var results types.Results
+	data, err = os.ReadFile(resultsPath)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, &results))
+	assert.Equal(t, "Fixed Name", results.Mods.Name)
+	assert.Equal(t, int64(42), results.Mods.ModID)
+	assert.True(t, checked.Equal(results.Mods.LastChecked))
+	require.NotNil(t, results.Meta)
+	assert.Equal(t, "v1.0.0", results.Meta.ScraperVersion)
+}
+
+func TestReparseTargets(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	for _, path := range []string{
+		filepath.Join("data", "skyrim", "skyui 3863.json"),
+		filepath.Join("data", "skyrim", "skyui 3863.page.html.gz"),
+		filepath.Join("data", "skyrim", "skyui 3863.files.html.gz"),
+		filepath.Join("data", "skyrim", "unarchived 1.json"),
+		filepath.Join("data", "fallout4", "nested", "mod 2.page.html.gz"),
+	} {
+		require.NoError(t, fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, fsys.Default.WriteFile(path, []byte("{}"), 0644))
+	}
+
+	// Act
+	paths, err := reparseTargets([]string{
+		filepath.Join("data", "skyrim", "skyui 3863.files.html.gz"),
+		"data",
+		filepath.Join("data", "skyrim", "missing 5.json"),
+	})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join("data", "skyrim", "skyui 3863.json"),
+		filepath.Join("data", "fallout4", "nested", "mod 2.json"),
+		filepath.Join("data", "skyrim", "missing 5.json"),
+	}, paths)
+}
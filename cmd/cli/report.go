@@ -0,0 +1,75 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/report"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// reportCmd is a Cobra command used for rendering HTML reports of saved results.
+	reportCmd = &cobra.Command{}
+	// reportOptions holds the command-line flag values of the report command.
+	reportOptions = config.Report{}
+)
+
+// init initializes the report command with usage, description, and argument
+// validation. It registers the report flags and adds the command to the root command.
+func init() {
+	reportCmd = &cobra.Command{
+		Use:   "report <results directory> [flags]",
+		Short: "Render an HTML report",
+		Long:  "Render a static HTML report of the mods saved in a results directory: a sortable index of every mod and a detail page per mod",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rc, err := config.LoadReport(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Saved dates are parsed using the format they were written in
+			types.TimestampFormat = formatters.DateLayout(rc.DateFormat)
+
+			return renderReport(cmd.OutOrStdout(), rc, args[0], time.Now())
+		},
+	}
+
+	config.RegisterReportFlags(reportCmd, &reportOptions)
+	RootCmd.AddCommand(reportCmd)
+}
+
+// renderReport loads the mods saved in the results directory and renders the HTML
+// report, by default into a report directory inside it. Files that can't be loaded are
+// listed and left out of the report. Returns an error if the directory can't be read,
+// holds no mods, or the report can't be rendered.
+func renderReport(w io.Writer, rc config.Report, resultsDir string, now time.Time) error {
+	entries, skipped, err := report.Load(resultsDir)
+	if err != nil {
+		return err
+	}
+	for _, err := range skipped {
+		fmt.Fprintf(w, "Skipping %v\n", err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("no saved mods found in %s", resultsDir)
+	}
+
+	outputDir := rc.OutputDirectory
+	if outputDir == "" {
+		outputDir = filepath.Join(resultsDir, "report")
+	}
+
+	indexPath, err := report.Render(entries, outputDir, rc.TemplateDir, now)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Report of %d mods saved to %s\n", len(entries), formatters.PathLink(indexPath))
+	return nil
+}
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderReport_DefaultOutputDirectory(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skyrim"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skyrim", "one 1.json"), []byte(`{"Mods":{"ModID":1,"Name":"One"}}`), 0644))
+	var out bytes.Buffer
+
+	// Act
+	err := renderReport(&out, config.Report{}, dir, time.Now())
+
+	// Assert
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "report", "index.html"))
+	assert.FileExists(t, filepath.Join(dir, "report", "mods", "skyrim-1.html"))
+	assert.Contains(t, out.String(), "Report of 1 mods saved to")
+}
+
+func TestRenderReport_NoMods(t *testing.T) {
+	dir := t.TempDir()
+
+	err := renderReport(&bytes.Buffer{}, config.Report{}, dir, time.Now())
+
+	assert.EqualError(t, err, "no saved mods found in "+dir)
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/summary"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// retryFailedCmd is a Cobra command used for retrying the failed mods of a batch.
+	retryFailedCmd = &cobra.Command{}
+	// retryFailedOptions holds the command-line flag values of the retry-failed command.
+	retryFailedOptions = config.RetryFailed{}
+)
+
+// init initializes the retry-failed command with usage, description, and argument
+// validation. It registers the retry-failed flags and adds the command to the root
+// command.
+func init() {
+	retryFailedCmd = &cobra.Command{
+		Use:   "retry-failed <summary.json> [flags]",
+		Short: "Retry failed mods",
+		Long:  "Scrape again the mods listed as failed in the summary index of a previous batch, saving them with the other results and updating the summary",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rc, err := config.LoadRetryFailed(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Parsed dates are serialized using the requested format
+			types.TimestampFormat = formatters.DateLayout(rc.DateFormat)
+
+			return retryFailed(rc, args[0], fetchModInfoFunc, fetchDocumentFunc)
+		},
+	}
+
+	config.RegisterRetryFailedFlags(retryFailedCmd, &retryFailedOptions)
+	RootCmd.AddCommand(retryFailedCmd)
+}
+
+// retryFailed scrapes the mods listed as failed in the summary index at summaryPath.
+// They are saved in the game directory holding the summary, which is updated with the
+// outcome: mods that succeed replace their entry and are no longer listed as failed.
+// Returns an error if the summary can't be read or any of the mods fail again.
+func retryFailed(
+	rc config.RetryFailed,
+	summaryPath string,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	if _, err := fsys.Default.Stat(summaryPath); err != nil {
+		return fmt.Errorf("error reading summary: %w", err)
+	}
+	index, err := summary.Load(summaryPath)
+	if err != nil {
+		return err
+	}
+
+	if len(index.Failed) == 0 {
+		fmt.Fprintf(formatters.Status(), "No failed mods to retry in %s\n", summaryPath)
+		return nil
+	}
+
+	modIDs := make([]int64, 0, len(index.Failed))
+	for _, failed := range index.Failed {
+		modIDs = append(modIDs, failed.ModID)
+	}
+	fmt.Fprintf(formatters.Status(), "Retrying %d failed mods of %s\n", len(modIDs), summaryPath)
+
+	// The summary is stored as <output directory>/<game>/summary.json, results are
+	// saved next to it so that it is the one updated
+	absPath, err := filepath.Abs(summaryPath)
+	if err != nil {
+		return err
+	}
+	gameDirectory := filepath.Dir(absPath)
+	game, err := types.ParseGameSlug(filepath.Base(gameDirectory))
+	if err != nil {
+		return err
+	}
+	sc := types.CliFlags{
+		BaseUrl:         rc.BaseUrl,
+		CookieDirectory: rc.CookieDirectory,
+		CookieFile:      rc.CookieFile,
+		CookieHeader:    rc.CookieHeader,
+		DateFormat:      rc.DateFormat,
+		DisplayResults:  rc.DisplayResults,
+		ErrorReport:     rc.ErrorReport,
+		GameName:        game,
+		OutputDirectory: filepath.Dir(gameDirectory),
+		PerModTimeout:   rc.PerModTimeout,
+		RunID:           rc.RunID,
+		SaveResults:     true,
+		SummaryMarkdown: rc.SummaryMarkdown,
+	}
+	return scrapeMods(context.Background(), sc, modIDs, fetchModInfoFunc, fetchDocumentFunc)
+}
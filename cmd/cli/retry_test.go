@@ -0,0 +1,88 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/summary"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryFailed(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	summaryPath := filepath.Join(tempDir, "game", summary.Filename)
+	err = summary.Save(summaryPath, summary.Summary{
+		Game:   "game",
+		Mods:   []summary.Entry{{File: "mocked mod 1.json", ModID: 1, RunID: "run-1"}},
+		Failed: []types.FailedMod{{ModID: 2, Error: "boom"}, {ModID: 3, Error: "boom"}},
+		RunID:  "run-1",
+	})
+	require.NoError(t, err)
+
+	var retried []int64
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		assert.Equal(t, types.GameSlug("game"), game)
+		retried = append(retried, modId.Int64())
+		if modId == 3 {
+			return types.Results{}, errors.New("boom again")
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+	rc := config.RetryFailed{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		RunID:           "run-2",
+	}
+
+	// Act
+	err = retryFailed(rc, summaryPath, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.EqualError(t, err, "1 of 2 mods failed to scrape")
+	assert.Equal(t, []int64{2, 3}, retried)
+	assert.FileExists(t, filepath.Join(tempDir, "game", "mocked mod 2.json"))
+
+	index, err := summary.Load(summaryPath)
+	require.NoError(t, err)
+	assert.Equal(t, "run-2", index.RunID)
+	require.Len(t, index.Mods, 2)
+	assert.Equal(t, int64(2), index.Mods[1].ModID)
+	assert.Equal(t, "run-2", index.Mods[1].RunID)
+	assert.Equal(t, []types.FailedMod{{ModID: 3, Error: "boom again", Kind: "other"}}, index.Failed)
+}
+
+func TestRetryFailed_NothingFailed(t *testing.T) {
+	// Arrange
+	summaryPath := filepath.Join(t.TempDir(), "game", summary.Filename)
+	require.NoError(t, summary.Save(summaryPath, summary.Summary{Game: "game"}))
+
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		t.Fatal("no mod should be scraped")
+		return types.Results{}, nil
+	}
+
+	// Act
+	err := retryFailed(config.RetryFailed{}, summaryPath, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestRetryFailed_MissingSummary(t *testing.T) {
+	err := retryFailed(config.RetryFailed{}, filepath.Join(t.TempDir(), summary.Filename), fetchModInfoFunc, mockFetchDocument)
+
+	assert.ErrorContains(t, err, "error reading summary")
+}
@@ -1,6 +1,25 @@
 package cli
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	nexuserrors "github.com/ondrovic/nexus-mods-scraper/internal/errors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/profile"
+	"github.com/ondrovic/nexus-mods-scraper/internal/progress"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
 	"github.com/spf13/cobra"
 )
 
@@ -8,17 +27,175 @@ import (
 // description and setting up the command's usage for scraping Nexus Mods and returning
 // the information in JSON format.
 var RootCmd = &cobra.Command{
-	Use:   "nexus-mods-scraper",
-	Short: "A CLI tool to scrape https://nexusmods.com mods and return the information in JSON format",
+	Use:               "nexus-mods-scraper",
+	Short:             "A CLI tool to scrape https://nexusmods.com mods and return the information in JSON format",
+	PersistentPreRunE: setUp,
+}
+
+// dryRunAnnotation marks the commands that support the --dry-run flag, printing what
+// they would fetch and write instead of doing it.
+const dryRunAnnotation = "dry-run"
+
+// init registers the persistent flags shared by every command.
+func init() {
+	config.RegisterConfigFlag(RootCmd)
+	config.RegisterDryRunFlag(RootCmd)
+	config.RegisterLocaleFlag(RootCmd)
+	config.RegisterProfileFlag(RootCmd)
+	config.RegisterSelectorsFlag(RootCmd)
+	config.RegisterProgressFlag(RootCmd)
+	config.RegisterQuietFlag(RootCmd)
+	config.RegisterColorFlag(RootCmd)
+	config.RegisterSpinnerFlag(RootCmd)
+	config.RegisterTransportFlags(RootCmd)
+	config.RegisterUsageStatsFlag(RootCmd)
+}
+
+// setUp runs before every command. It activates the selected profile, makes the
+// extractors use the selectors of the selector override file, if any, tunes the
+// connections, requests and reads the pages in the configured language, turns the
+// spinner animation and colors off, the JSON progress events on and keeps stdout for
+// the results in quiet mode as configured. Returns an error if the profile doesn't
+// exist, the override file, the connection tuning or the language is invalid, or
+// --dry-run is given to a command that doesn't support it.
+func setUp(cmd *cobra.Command, args []string) error {
+	if err := activateProfile(cmd); err != nil {
+		return err
+	}
+
+	loaded, err := config.LoadSelectors()
+	if err != nil {
+		return err
+	}
+	selectors.Current = loaded
+
+	options, err := config.LoadTransport(cmd)
+	if err != nil {
+		return err
+	}
+	httpclient.ConfigureTransport(options)
+
+	locale, err := config.LoadLocale(cmd)
+	if err != nil {
+		return err
+	}
+	formatters.Locale = locale
+	httpclient.AcceptLanguage = formatters.AcceptLanguage(locale)
+
+	disabled, err := config.SpinnerDisabled(cmd)
+	if err != nil {
+		return err
+	}
+	spinners.Animated = !disabled
+
+	noColor, err := config.ColorDisabled(cmd)
+	if err != nil {
+		return err
+	}
+	if noColor {
+		// The spinner animation moves the cursor with escape codes as well
+		color.NoColor = true
+		spinners.Animated = false
+	}
+
+	quiet, err := config.QuietEnabled(cmd)
+	if err != nil {
+		return err
+	}
+	// The status lines go to stderr in quiet mode, so that the results can be piped to
+	// e.g. jq
+	formatters.StatusOutput = nil
+	if quiet {
+		formatters.StatusOutput = os.Stderr
+	}
+
+	progressJSON, err := config.ProgressJSONEnabled(cmd)
+	if err != nil {
+		return err
+	}
+	progress.SetOutput(nil)
+	if progressJSON {
+		progress.SetOutput(os.Stderr)
+	}
+
+	dryRun, err := config.DryRunEnabled(cmd)
+	if err != nil {
+		return err
+	}
+	if dryRun && cmd.Annotations[dryRunAnnotation] == "" {
+		return fmt.Errorf("--dry-run is not supported by the %s command", cmd.CommandPath())
+	}
+	return nil
+}
+
+// activateProfile makes the profile selected with --profile or NEXUS_SCRAPER_PROFILE
+// the active one, so that its configuration file is read and the flags defaulting to
+// the data storage directory use its directory instead. Returns an error if the profile
+// name is invalid or the profile hasn't been created.
+func activateProfile(cmd *cobra.Command) error {
+	name := config.ResolveProfile(cmd)
+	if name == profile.Default {
+		name = ""
+	}
+	if name != "" {
+		if err := profile.ValidateName(name); err != nil {
+			return err
+		}
+		if !profile.Exists(name) {
+			return fmt.Errorf("profile %s doesn't exist, create it with profile create %s", name, name)
+		}
+	}
+
+	storage.SetProfile(name)
+	return config.ApplyProfile(cmd, storage.DefaultProfilePath())
 }
 
 // Execute runs the RootCmd command, handling any errors that occur during its execution.
-// Returns an error if the command fails to execute.
+// The usage of the run is then recorded when usage stats are enabled, whether the
+// command succeeds or not. When the command fails with a typed error, the steps to
+// fix it are printed. Returns an error if the command fails to execute.
 func Execute() error {
+	cmd, err := RootCmd.ExecuteC()
+	recordUsage(cmd, filepath.Join(storage.GetDataStoragePath(), usage.DefaultFilename), time.Now())
 
-	if err := RootCmd.Execute(); err != nil {
+	if err != nil {
+		printRemediation(os.Stderr, err)
 		return err
 	}
 
 	return nil
 }
+
+// printRemediation writes the steps to take to fix err to w, when it is a typed error
+// such as adult content being blocked, a missing page or rate limiting. A run where
+// several mods failed is remedied as its first failure.
+func printRemediation(w io.Writer, err error) {
+	var runErr *failures.RunError
+	if errors.As(err, &runErr) && runErr.First != nil {
+		err = runErr.First
+	}
+
+	if hint := nexuserrors.Remediation(err); hint != "" {
+		fmt.Fprintln(w, hint)
+	}
+}
+
+// recordUsage adds the usage counted while the command ran to the usage stats file at
+// path when the command has usage stats enabled. Runs that neither scraped nor fetched
+// anything, such as stats show itself, aren't recorded. Failing to record the usage
+// only prints a warning, it never fails the command.
+func recordUsage(cmd *cobra.Command, path string, now time.Time) {
+	counters := usage.Take()
+	if cmd == nil || counters.IsZero() {
+		return
+	}
+
+	enabled, err := config.UsageStatsEnabled(cmd)
+	if err != nil || !enabled {
+		return
+	}
+
+	if err := usage.Record(path, counters, now); err != nil {
+		fmt.Fprintf(os.Stderr, "Error recording usage stats: %v\n", err)
+	}
+}
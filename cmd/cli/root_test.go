@@ -1,11 +1,23 @@
 package cli
 
 import (
+	"bytes"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestRootCmd_Initialized(t *testing.T) {
@@ -23,6 +35,7 @@ func TestExecute_Success(t *testing.T) {
 	}
 
 	// Replace RootCmd with the mock command for the test
+	defer func(root *cobra.Command) { RootCmd = root }(RootCmd)
 	RootCmd = mockCmd
 
 	// Execute the command and ensure no error is returned
@@ -39,6 +52,7 @@ func TestExecute_Failure(t *testing.T) {
 	}
 
 	// Replace RootCmd with the mock command for the test
+	defer func(root *cobra.Command) { RootCmd = root }(RootCmd)
 	RootCmd = mockCmd
 
 	// Execute the command and ensure the error is returned
@@ -46,3 +60,126 @@ func TestExecute_Failure(t *testing.T) {
 	assert.Error(t, err)
 	assert.Equal(t, "execution failed", err.Error())
 }
+
+func TestRecordUsage(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected []usage.Day
+	}{
+		{"enabled", []string{"--usage-stats"}, []usage.Day{
+			{Date: "2024-06-12", Runs: 1, Counters: usage.Counters{BytesFetched: 2048, ModsScraped: 1, Requests: 1}},
+		}},
+		{"disabled", nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := filepath.Join(t.TempDir(), usage.DefaultFilename)
+			cmd := &cobra.Command{Use: "scrape"}
+			config.RegisterUsageStatsFlag(cmd)
+			require.NoError(t, cmd.ParseFlags(tt.args))
+			// Drop the usage counted by the tests run before
+			usage.Take()
+			usage.AddFetch(2048)
+			usage.AddMods(1, 0)
+
+			// Act
+			recordUsage(cmd, path, time.Date(2024, time.June, 12, 12, 0, 0, 0, time.Local))
+
+			// Assert
+			stats, err := usage.Load(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, stats.Days)
+		})
+	}
+}
+
+func TestPrintRemediation(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"untyped", errors.New("boom"), ""},
+		{"adult content", fmt.Errorf("error scraping mod: %w", fetchers.ErrAdultContent), "To fix it:\n" +
+			"  1. Enable \"Show adult content\" in the content blocking settings of your Nexus Mods account\n" +
+			"  2. Re-run extract-cookies to refresh the session cookies, or check the ones given with --cookie-header\n"},
+		{"partial run", &failures.RunError{Failed: 1, First: &fetchers.StatusError{StatusCode: 429}, Succeeded: 1, Total: 2}, "To fix it:\n" +
+			"  1. Wait a few minutes before running again\n" +
+			"  2. Scrape fewer mods per run, e.g. with a lower --max-mods\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+
+			printRemediation(&out, tt.err)
+
+			assert.Equal(t, tt.expected, out.String())
+		})
+	}
+}
+
+func TestSetUp_Quiet(t *testing.T) {
+	// Arrange
+	defer func() { formatters.StatusOutput = nil }()
+	stdout := os.Stdout
+	root := &cobra.Command{Use: "root", PersistentPreRunE: setUp}
+	config.RegisterLocaleFlag(root)
+	config.RegisterQuietFlag(root)
+	config.RegisterTransportFlags(root)
+	var status io.Writer
+	root.AddCommand(&cobra.Command{Use: "run", RunE: func(*cobra.Command, []string) error {
+		status = formatters.Status()
+		return nil
+	}})
+
+	// Act
+	root.SetArgs([]string{"run", "--quiet"})
+	quietErr := root.Execute()
+	quietStatus := status
+	root.SetArgs([]string{"run", "--quiet=false"})
+	err := root.Execute()
+
+	// Assert
+	require.NoError(t, quietErr)
+	require.NoError(t, err)
+	assert.Equal(t, os.Stderr, quietStatus)
+	assert.Equal(t, os.Stdout, status, "the next run writes the status lines to stdout again")
+	assert.Equal(t, stdout, os.Stdout, "stdout is left alone")
+}
+
+func TestSetUp_DryRun(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		err         string
+	}{
+		{name: "supported", annotations: map[string]string{dryRunAnnotation: "true"}},
+		{name: "unsupported", err: "--dry-run is not supported by the root unsupported command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			root := &cobra.Command{Use: "root", PersistentPreRunE: setUp}
+			config.RegisterDryRunFlag(root)
+			config.RegisterLocaleFlag(root)
+			config.RegisterTransportFlags(root)
+			root.AddCommand(&cobra.Command{Use: tt.name, Annotations: tt.annotations, RunE: func(*cobra.Command, []string) error { return nil }})
+			root.SetArgs([]string{tt.name, "--dry-run"})
+
+			// Act
+			err := root.Execute()
+
+			// Assert
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -1,18 +1,39 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
-	"github.com/savioxavier/termlink"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
+	"go.szostok.io/version"
 
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/emit"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
 	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/htmlarchive"
 	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/ignore"
+	"github.com/ondrovic/nexus-mods-scraper/internal/images"
+	"github.com/ondrovic/nexus-mods-scraper/internal/notifiers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/progress"
+	"github.com/ondrovic/nexus-mods-scraper/internal/share"
+	"github.com/ondrovic/nexus-mods-scraper/internal/store"
+	"github.com/ondrovic/nexus-mods-scraper/internal/summary"
+	"github.com/ondrovic/nexus-mods-scraper/internal/transform"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
-	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
@@ -23,6 +44,9 @@ import (
 )
 
 var (
+	// errModUnchanged is returned by scrapeMod when a mod is skipped because its page
+	// shows the same last update as its saved results.
+	errModUnchanged = errors.New("mod unchanged since it was last saved")
 	// options holds the command-line flag values using the CliFlags struct.
 	options = types.CliFlags{}
 	// scrapeCmd is a Cobra command used for scraping operations in the application.
@@ -33,76 +57,445 @@ var (
 	// fetchDocumentFunc is a variable that holds a reference to the function used for
 	// fetching HTML documents from a given URL.
 	fetchDocumentFunc = fetchers.FetchDocument
+	// checkSessionFunc is a variable that holds a reference to the function used to
+	// check the session the cookies open before scraping.
+	checkSessionFunc = fetchers.CheckSession
 )
 
 // init initializes the scrape command with usage, description, and argument validation.
-// It binds flags using Viper and adds the command to the root command for execution.
+// It registers the scrape flags and adds the command to the root command for execution.
 func init() {
 	scrapeCmd = &cobra.Command{
-		Use:   "scrape <game name> <mod id> [flags]",
-		Short: "Scrape mod",
-		Long:  "Scrape mod for game and returns a JSON output",
-		Args:  cobra.ExactArgs(2),
-		RunE:  run,
+		Use:               "scrape <game name> <mod id|@file[,mod id|@file...] | -> | <mod url[,mod url...]> [flags]",
+		Short:             "Scrape mod",
+		Long:              "Scrape one or more mods (comma separated ids or @file entries expanded from a file of ids, - to read them from stdin, or --ids-file) for game and returns a JSON output, or the mods of comma separated mod page urls, e.g. https://www.nexusmods.com/skyrimspecialedition/mods/3863, the game being read from them, or every Nexus mod of a Wabbajack modlist with --from-wabbajack",
+		Args:              cobra.RangeArgs(0, 2),
+		ValidArgsFunction: completeGameArg,
+		RunE:              run,
+		// The requests and files of a run are planned up front
+		Annotations: map[string]string{dryRunAnnotation: "true"},
 	}
 
-	initScrapeFlags(scrapeCmd)
-	viper.BindPFlags(scrapeCmd.Flags())
+	config.RegisterScrapeFlags(scrapeCmd, &options)
 	RootCmd.AddCommand(scrapeCmd)
 }
 
-// initScrapeFlags registers the command-line flags for the scrape command, including
-// options for the base URL, cookie directory, cookie filename, result display and save
-// options, output directory, and valid cookie names. It binds these flags to the
-// corresponding fields in the CliFlags struct.
-func initScrapeFlags(cmd *cobra.Command) {
-	cli.RegisterFlag(cmd, "base-url", "u", "https://nexusmods.com", "Base url for the mods", &options.BaseUrl)
-	cli.RegisterFlag(cmd, "cookie-directory", "d", storage.GetDataStoragePath(), "Directory your cookie file is stored in", &options.CookieDirectory)
-	cli.RegisterFlag(cmd, "cookie-filename", "f", "session-cookies.json", "Filename where the cookies are stored", &options.CookieFile)
-	cli.RegisterFlag(cmd, "display-results", "r", false, "Do you want to display the results in the terminal?", &options.DisplayResults)
-	cli.RegisterFlag(cmd, "save-results", "s", false, "Do you want to save the results to a JSON file?", &options.SaveResults)
-	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &options.OutputDirectory)
-	cli.RegisterFlag(cmd, "valid-cookie-names", "c", []string{"nexusmods_session", "nexusmods_session_refresh"}, "Names of the cookies to extract", &options.ValidCookies)
-}
-
-// run executes the scrape command, validating that either display or save results
-// options are enabled. It parses the mod ID and game name from the arguments, reads
-// the configuration values from Viper, and then calls the scrapeMod function with
-// the populated CliFlags.
+// run executes the scrape command, validating that at least one of the display, save
+// or emit results options is enabled unless --dry-run is given. It loads the
+// configuration from the flags, environment and configuration file, reads the mod IDs
+// from the arguments, stdin or the IDs file along with the game name, or both from the
+// mod URLs of the argument, drops duplicate IDs and rejects non-positive ones, prints
+// the planned mods and stops there with --dry-run, checks that the session cookies
+// authenticate unless --no-preflight is given, and then calls the scrapeMods function
+// with the populated CliFlags.
 func run(cmd *cobra.Command, args []string) error {
-	if !options.DisplayResults && !options.SaveResults {
-		return fmt.Errorf("at least one of --display-results (-r) or --save-results (-s) must be enabled")
+	scraper, err := config.LoadScrape(cmd)
+	if err != nil {
+		return err
+	}
+	if !scraper.DisplayResults && !scraper.SaveResults && scraper.Emit == "" && !scraper.DryRun {
+		return fmt.Errorf("at least one of --display-results (-r), --save-results (-s) or --emit must be enabled")
+	}
+	if scraper.KeepLast < 0 {
+		return fmt.Errorf("--keep-last must not be negative")
+	}
+	if scraper.KeepLast > 0 && !scraper.Snapshot {
+		return fmt.Errorf("--keep-last requires --snapshot")
+	}
+	if scraper.ArchiveHtml && !scraper.SaveResults {
+		return fmt.Errorf("--archive-html requires --save-results")
+	}
+	if err := exporters.ValidateFormat(scraper.Format); err != nil {
+		return err
+	}
+	if scraper.Format != exporters.FormatJson && !scraper.SaveResults {
+		return fmt.Errorf("--format %s requires --save-results", scraper.Format)
+	}
+	if scraper.MaxMods < 0 {
+		return fmt.Errorf("--max-mods must not be negative")
+	}
+	if err := exporters.ValidatePathTemplate(scraper.PathTemplate); err != nil {
+		return err
+	}
+	if scraper.SkipUnchanged && exporters.PathTemplateUses(scraper.PathTemplate, "version") {
+		// The saved results are looked up before the files tab giving the latest version is read
+		return fmt.Errorf("--skip-unchanged requires a --path-template without {version}, which is only known once the files tab is read")
+	}
+	if err := filenamePolicy(scraper).Validate(); err != nil {
+		return err
+	}
+	if _, err := fetchers.ParseFields(scraper.Fields); err != nil {
+		return err
+	}
+	if _, err := transform.Order(scraper.Transforms); err != nil {
+		return err
+	}
+	if scraper.Backend != fetchers.BackendHtml && scraper.Backend != fetchers.BackendGraphQL {
+		return fmt.Errorf("unsupported backend %q, expected html or graphql", scraper.Backend)
+	}
+	if scraper.Backend == fetchers.BackendGraphQL && scraper.ArchiveHtml {
+		return fmt.Errorf("--archive-html requires --backend html")
+	}
+	if scraper.DownloadImages {
+		if !scraper.SaveResults {
+			return fmt.Errorf("--download-images requires --save-results")
+		}
+		if scraper.Backend == fetchers.BackendGraphQL {
+			return fmt.Errorf("--download-images requires --backend html")
+		}
+		if scraper.ImageConcurrency < 1 {
+			return fmt.Errorf("--image-concurrency must be at least 1")
+		}
 	}
-	modID, err := formatters.StrToInt(args[1])
+	if scraper.RateLimit < 0 {
+		return fmt.Errorf("--rate-limit must not be negative")
+	}
+	if err := storage.ValidateCookieStore(scraper.CookieStore); err != nil {
+		return err
+	}
+	if scraper.Upload != "" {
+		if !scraper.SaveResults {
+			return fmt.Errorf("--upload requires --save-results")
+		}
+		if err := exporters.ValidateUploadDestination(scraper.Upload); err != nil {
+			return err
+		}
+		if scraper.UploadConcurrency < 1 {
+			return fmt.Errorf("--upload-concurrency must be at least 1")
+		}
+	}
+
+	// Parsed dates and empty lists are serialized as requested
+	types.TimestampFormat = formatters.DateLayout(scraper.DateFormat)
+	types.KeepEmptyFields = scraper.KeepEmptyFields
+
+	if scraper.FromWabbajack != "" {
+		return runWabbajack(scraper, args)
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("no game given: pass the game name and mod ids, or --from-wabbajack")
+	}
+	game := args[0]
+	var modIDs []int64
+	if isModUrlArg(args[0]) {
+		if len(args) > 1 || scraper.IdsFile != "" {
+			return fmt.Errorf("give either mod urls or a game and mod ids, not both")
+		}
+		game, modIDs, err = readModUrls(args[0])
+	} else {
+		modIDs, err = readModIDs(cmd.InOrStdin(), scraper.IdsFile, args[1:])
+	}
+	if err != nil {
+		return err
+	}
+	modIDs, duplicates, err := formatters.PlanModIDs(modIDs)
+	if err != nil {
+		return err
+	}
+
+	scraper.GameName, err = types.ParseGameSlug(game)
 	if err != nil {
 		return err
 	}
 
-	scraper := types.CliFlags{
-		BaseUrl:         viper.GetString("base-url"),
-		CookieDirectory: viper.GetString("cookie-directory"),
-		CookieFile:      viper.GetString("cookie-filename"),
-		DisplayResults:  viper.GetBool("display-results"),
-		GameName:        args[0],
-		ModID:           modID,
-		SaveResults:     viper.GetBool("save-results"),
-		OutputDirectory: viper.GetString("output-directory"),
-		ValidCookies:    viper.GetStringSlice("valid-cookie-names"),
+	if scraper.DryRun {
+		printPlan(cmd.OutOrStdout(), scraper, modIDs, duplicates)
+		return nil
+	}
+	if scraper.MaxMods > 0 && len(modIDs) > scraper.MaxMods && !scraper.Yes {
+		return fmt.Errorf("refusing to scrape %d mods, more than the cap of %d mods per run: raise it with --max-mods or confirm with --yes", len(modIDs), scraper.MaxMods)
+	}
+	if duplicates > 0 {
+		fmt.Fprintf(formatters.Status(), "Skipping %d duplicate mod ids\n", duplicates)
+	}
+
+	if !scraper.NoPreflight {
+		if err := preflightCookies(scraper); err != nil {
+			return err
+		}
 	}
 
-	return scrapeMod(scraper, fetchModInfoFunc, fetchDocumentFunc)
+	return scrapeMods(context.Background(), scraper, modIDs, fetchModInfoFunc, fetchDocumentFunc)
 }
 
-// scrapeMod orchestrates the process of scraping mod information, including setting up
-// the HTTP client, scraping mod info, displaying results, and saving results based on
-// the provided command-line flags. It uses spinners to indicate progress throughout the
-// operations and accepts functions for fetching mod info and documents, returning an error
-// if any step fails.
-func scrapeMod(
+// readModIDs returns the mod IDs to scrape: the comma separated IDs of the argument,
+// those read from stdin when the argument is "-", or those of the IDs file. Returns an
+// error if the IDs are given both ways or not at all, or can't be read.
+func readModIDs(stdin io.Reader, idsFile string, args []string) ([]int64, error) {
+	switch {
+	case len(args) > 0 && idsFile != "":
+		return nil, fmt.Errorf("give the mod ids either as an argument or with --ids-file, not both")
+	case len(args) > 0 && args[0] == "-":
+		return formatters.ReadModIDs(stdin)
+	case len(args) > 0:
+		return formatters.StrToInt64Slice(args[0])
+	case idsFile != "":
+		data, err := fsys.Default.ReadFile(idsFile)
+		if err != nil {
+			return nil, fmt.Errorf("error reading ids file: %w", err)
+		}
+		modIDs, err := formatters.ReadModIDs(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", idsFile, err)
+		}
+		return modIDs, nil
+	}
+	return nil, fmt.Errorf("no mod ids given: pass them as an argument, - to read them from stdin, or --ids-file")
+}
+
+// printPlan writes what a run of sc scraping modIDs would do to w, as asked with
+// --dry-run, without making any request: where the session cookies come from and the
+// page checking them, then the URLs fetched for each mod, the file it is saved to and
+// the directory its gallery images are downloaded to, along with the number of
+// duplicate IDs dropped and whether the run would exceed the cap on mods per run.
+func printPlan(w io.Writer, sc types.CliFlags, modIDs []int64, duplicates int) {
+	printSessionPlan(w, sc)
+	printModsPlan(w, sc, modIDs)
+	if duplicates > 0 {
+		fmt.Fprintf(w, "Dropped %d duplicate mod ids\n", duplicates)
+	}
+	if sc.Upload != "" {
+		fmt.Fprintf(w, "Upload the saved files to %s\n", sc.Upload)
+	}
+	printCapPlan(w, sc, len(modIDs))
+}
+
+// printSessionPlan writes where the session cookies of a run of sc come from to w, and
+// the page requested to check them unless the check is skipped.
+func printSessionPlan(w io.Writer, sc types.CliFlags) {
+	fmt.Fprintf(w, "Cookies: %s\n", cookieSource(sc))
+	if sc.NoPreflight {
+		fmt.Fprintln(w, "Session check: skipped with --no-preflight")
+		return
+	}
+	fmt.Fprintf(w, "Session check: GET %s\n", fetchers.SessionCheckUrl(sc.BaseUrl))
+}
+
+// printModsPlan writes the requests a run of sc would make for each of modIDs to w, the
+// files tab being left out when no selected field needs it, and the file each mod
+// would be saved to, the placeholders only known once it is scraped, e.g. {name},
+// being left as is.
+func printModsPlan(w io.Writer, sc types.CliFlags, modIDs []int64) {
+	// The fields were validated along with the other flags
+	fields, _ := fetchers.ParseFields(sc.Fields)
+
+	fmt.Fprintf(w, "Would scrape %d mods of %s:\n", len(modIDs), sc.GameName)
+	for _, modID := range modIDs {
+		fmt.Fprintf(w, "  %d\n", modID)
+		printBackendPlan(w, sc, sc.Backend == fetchers.BackendGraphQL, fields, modID)
+		if sc.ShadowCompare {
+			// The mod is fetched again with the other backend to compare the results
+			printBackendPlan(w, sc, sc.Backend != fetchers.BackendGraphQL, fields, modID)
+		}
+		if sc.SaveResults {
+			fmt.Fprintf(w, "    Save to %s\n", plannedSavePath(sc, modID))
+		}
+		if sc.SaveResults && sc.DownloadImages {
+			path := exporters.PlannedPath(sc.PathTemplate, sc.GameName, modID, filenamePolicy(sc))
+			fmt.Fprintf(w, "    Download the gallery images to %s\n", filepath.Join(sc.OutputDirectory, strings.TrimSuffix(path, ".json")+images.DirSuffix))
+		}
+	}
+}
+
+// printBackendPlan writes the requests fetching the mod numbered modID with the graphql
+// backend, or by its pages, to w.
+func printBackendPlan(w io.Writer, sc types.CliFlags, graphQL bool, fields fetchers.Fields, modID int64) {
+	if graphQL {
+		fmt.Fprintf(w, "    POST %s\n", sc.GraphQLEndpoint)
+		return
+	}
+	modUrl := fmt.Sprintf("%s/%s/mods/%d", sc.BaseUrl, sc.GameName, modID)
+	fmt.Fprintf(w, "    GET %s\n", modUrl)
+	if fields.NeedsFilesTab() {
+		fmt.Fprintf(w, "    GET %s?tab=files\n", modUrl)
+	}
+}
+
+// plannedSavePath returns the path of the file the mod numbered modID would be saved to
+// by a run of sc, as far as it is known before the mod is scraped, like savePath.
+func plannedSavePath(sc types.CliFlags, modID int64) string {
+	path := exporters.PlannedPath(sc.PathTemplate, sc.GameName, modID, filenamePolicy(sc))
+	filename := strings.TrimSuffix(filepath.Base(path), ".json")
+	if sc.Snapshot {
+		filename = filenamePolicy(sc).Snapshot(filename, time.Now())
+	}
+	return filepath.Join(sc.OutputDirectory, filepath.Dir(path), filename+".json")
+}
+
+// printCapPlan writes to w whether a run of sc scraping count mods would be refused for
+// exceeding the cap on mods per run.
+func printCapPlan(w io.Writer, sc types.CliFlags, count int) {
+	if sc.MaxMods > 0 && count > sc.MaxMods && !sc.Yes {
+		fmt.Fprintf(w, "The run would be refused, more than the cap of %d mods per run: raise it with --max-mods or confirm with --yes\n", sc.MaxMods)
+	}
+}
+
+// isModUrlArg reports whether the first argument of the scrape command holds mod page
+// URLs rather than a game name, telling them apart by the /mods/ of their path.
+func isModUrlArg(arg string) bool {
+	return strings.Contains(arg, "/mods/")
+}
+
+// readModUrls returns the game and mod IDs of the comma separated mod page URLs of arg,
+// e.g. "https://www.nexusmods.com/skyrimspecialedition/mods/3863", copied from the
+// browser. Returns an error if an entry isn't a mod URL or the mods are of different
+// games, which are scraped separately.
+func readModUrls(arg string) (string, []int64, error) {
+	var game string
+	var modIDs []int64
+	for _, part := range strings.Split(arg, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		urlGame, id, err := formatters.ParseModUrl(part)
+		if err != nil {
+			return "", nil, err
+		}
+		if game != "" && !strings.EqualFold(urlGame, game) {
+			return "", nil, fmt.Errorf("mod urls of different games given, %s and %s: scrape them separately", game, urlGame)
+		}
+		game = urlGame
+		modIDs = append(modIDs, id)
+	}
+	if len(modIDs) == 0 {
+		return "", nil, fmt.Errorf("no mod urls found in %q", arg)
+	}
+	return game, modIDs, nil
+}
+
+// newScrapeClient returns the HTTP client of a scrape, holding the session cookies of
+// the cookie header, the OS keyring with the keyring cookie store, or the cookie file.
+// Returns an error if the cookies can't be loaded.
+func newScrapeClient(sc types.CliFlags) (*http.Client, error) {
+	// The cookies of the keyring are sent like a --cookie-header
+	cookieHeader := sc.CookieHeader
+	if sc.CookieStore == storage.CookieStoreKeyring && cookieHeader == "" {
+		var err error
+		if cookieHeader, err = keyringCookieHeader(sc.CookieDirectory, sc.CookieFile); err != nil {
+			return nil, err
+		}
+	}
+	return httpclient.NewClient(sc.BaseUrl, sc.CookieDirectory, sc.CookieFile, cookieHeader)
+}
+
+// preflightCookies checks that the session cookies of the scrape are all there and
+// still authenticate before any mod is fetched, printing the user they are signed in
+// as, so that a bad session is reported up front rather than as failed mods. Returns
+// an error classified as an authentication failure if the cookies can't be loaded, are
+// missing or no longer authenticate, or an error if they can't be checked.
+func preflightCookies(sc types.CliFlags) error {
+	source := cookieSource(sc)
+	client, err := newScrapeClient(sc)
+	if err != nil {
+		return failures.WithKind(fmt.Errorf("error loading the session cookies: %w", err), failures.KindAuth)
+	}
+
+	cookies := map[string]string{}
+	if site, err := url.Parse(sc.BaseUrl); err == nil {
+		for _, cookie := range client.Jar.Cookies(site) {
+			cookies[cookie.Name] = cookie.Value
+		}
+	}
+	missing := make([]string, 0)
+	for _, name := range sc.ValidCookies {
+		if cookies[name] == "" {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return failures.WithKind(fmt.Errorf("the session cookies %s are missing from %s, run extract to save them", strings.Join(missing, ", "), source), failures.KindAuth)
+	}
+
+	session, err := checkSessionFunc(sc.BaseUrl, cookies)
+	if err != nil {
+		return fmt.Errorf("error checking the session cookies, skip the check with --no-preflight: %w", err)
+	}
+	if !session.LoggedIn {
+		return failures.WithKind(fmt.Errorf("the session cookies from %s no longer authenticate, they expired or were logged out: run extract or cookies refresh to renew them", source), failures.KindAuth)
+	}
+
+	if session.Username != "" {
+		fmt.Fprintf(formatters.Status(), "Signed in as %s\n", session.Username)
+	} else {
+		fmt.Fprintln(formatters.Status(), "Signed in")
+	}
+	return nil
+}
+
+// cookieSource describes where the session cookies of the scrape are read from, for
+// the error messages.
+func cookieSource(sc types.CliFlags) string {
+	switch {
+	case sc.CookieHeader != "":
+		return "--cookie-header"
+	case sc.CookieStore == storage.CookieStoreKeyring:
+		return "the OS keyring"
+	}
+	return filepath.Join(sc.CookieDirectory, sc.CookieFile)
+}
+
+// scrapeMods scrapes each of the provided mod IDs in turn with an HTTP client of its
+// own, under the configured run ID or a newly generated one. A failing mod does not
+// stop the run: it is recorded in the run summary, which is printed, saved and
+// announced as configured once every mod is done. Returns an error if any of the mods
+// failed.
+func scrapeMods(
+	ctx context.Context,
 	sc types.CliFlags,
-	fetchModInfoFunc func(baseUrl, game string, modId int64, concurrentFetch func(tasks ...func() error) error, fetchDocument func(targetURL string) (*goquery.Document, error)) (types.Results, error),
-	fetchDocumentFunc func(targetURL string) (*goquery.Document, error),
+	modIDs []int64,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
 ) error {
+	return scrapeModsObserving(ctx, sc, modIDs, fetchModInfoFunc, fetchDocumentFunc, nil)
+}
+
+// scrapeModsObserving scrapes the mods as scrapeMods does, passing each mod scraped,
+// whether still available or not, to observe along with its ID when observe isn't nil.
+func scrapeModsObserving(
+	ctx context.Context,
+	sc types.CliFlags,
+	modIDs []int64,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+	observe func(modID int64, mod types.ModInfo),
+) (err error) {
+	// Mod IDs are checked up front, so that a typo doesn't fail the run half way
+	for _, modID := range modIDs {
+		if _, err := types.NewModID(modID); err != nil {
+			return err
+		}
+	}
+
+	// Every run gets an ID to correlate its output, history and notifications
+	if sc.RunID == "" {
+		sc.RunID = utils.NewRunID()
+	}
+	fmt.Fprintf(formatters.Status(), "Run ID: %s\n", sc.RunID)
+
+	var (
+		runSummary = types.ScrapeSummary{RunID: sc.RunID}
+		saved      []summary.Entry
+		scrapeErr  error
+	)
+	if sc.ErrorReport != "" {
+		defer func() {
+			report := failures.NewReport(sc.GameName.String(), runSummary, err)
+			if reportErr := failures.SaveReport(sc.ErrorReport, report); reportErr != nil && err == nil {
+				err = reportErr
+			}
+		}()
+	}
+
+	// Load the ignore list to skip the mods known to be broken or irrelevant
+	ignored := ignore.List{}
+	if sc.IgnoreFile != "" {
+		if ignored, err = ignore.Load(sc.IgnoreFile); err != nil {
+			return err
+		}
+	}
+
 	// Create and start the main spinner for HTTP client setup
 	httpSpinner := spinners.CreateSpinner("Setting up HTTP client", "✓", "HTTP client setup complete", "✗", "HTTP client setup failed")
 	if err := httpSpinner.Start(); err != nil {
@@ -110,70 +503,883 @@ func scrapeMod(
 	}
 
 	// HTTP Client Setup
-	if err := httpclient.InitClient(sc.BaseUrl, sc.CookieDirectory, sc.CookieFile); err != nil {
+	client, err := newScrapeClient(sc)
+	if err != nil {
 		httpSpinner.StopFailMessage(fmt.Sprintf("Error setting up HTTP client: %v", err))
 		httpSpinner.StopFail()
-		return err
+		// The client can only fail to load the cookies
+		return failures.WithKind(err, failures.KindAuth)
 	}
 	httpSpinner.Stop()
+	fetchDocumentFunc = fetchers.WithClient(client, fetchDocumentFunc)
+	htmlFetchModInfo, graphQLFetchModInfo := fetchModInfoFunc, fetchers.GraphQL{Client: client, Endpoint: sc.GraphQLEndpoint}.FetchModInfo
+	if sc.Backend == fetchers.BackendGraphQL {
+		fetchModInfoFunc = graphQLFetchModInfo
+	}
+
+	// Fetch each mod with the other backend too, to compare their results
+	var shadow *fetchers.Shadow
+	if sc.ShadowCompare {
+		shadow = &fetchers.Shadow{Backend: fetchers.BackendGraphQL, FetchModInfo: graphQLFetchModInfo}
+		if sc.Backend == fetchers.BackendGraphQL {
+			shadow = &fetchers.Shadow{Backend: fetchers.BackendHtml, FetchModInfo: htmlFetchModInfo}
+		}
+	}
+
+	// Stream the results to the listener as they are produced
+	var (
+		emitter *emit.Emitter
+		emitErr error
+	)
+	if sc.Emit != "" {
+		emitter, err = emit.Dial(sc.Emit)
+		if err != nil {
+			return failures.WithKind(err, failures.KindNetwork)
+		}
+		defer func() {
+			if emitter != nil {
+				emitter.Close()
+			}
+		}()
+	}
+
+	// Load the last recorded state of each mod to detect updates to notify about, and
+	// requirement changes to warn about when recording history
+	var (
+		previous map[string]history.Entry
+		updates  []notifiers.Update
+	)
+	if sc.NotifyWebhook != "" || sc.RecordHistory {
+		entries, err := store.LoadHistory(sc.StorageDriver, sc.HistoryFile)
+		if err != nil {
+			return err
+		}
+		previous = history.Latest(entries)
+	}
+
+	// Set up the uploader first, so that missing credentials don't fail the run once done
+	var (
+		uploader   exporters.Uploader
+		savedFiles *exporters.SavedFiles
+	)
+	if sc.Upload != "" {
+		uploader, err = exporters.NewUploader(sc.Upload, uploadOptions(sc), &http.Client{Transport: httpclient.Transport()})
+		if err != nil {
+			return err
+		}
+		savedFiles = &exporters.SavedFiles{}
+	}
+
+	var downloader *images.Downloader
+	if sc.DownloadImages && sc.SaveResults {
+		downloader = &images.Downloader{
+			Client:      &http.Client{Timeout: httpclient.Timeout(), Transport: httpclient.Transport()},
+			Concurrency: sc.ImageConcurrency,
+		}
+	}
+
+	// Once ctx is done no further mod is started, the one in flight being given the
+	// drain timeout to finish so that its history and notification aren't lost
+	fetchCtx, stopFetches := drainContext(ctx, sc.DrainTimeout)
+	defer stopFetches()
+	fetchCtx = exporters.WithSavedFiles(fetchCtx, savedFiles)
+	fetchCtx = images.WithDownloader(fetchCtx, downloader)
+	fetchCtx = fetchers.WithShadow(fetchCtx, shadow)
+	// One limiter spans the run, the pages and images of every mod waiting their turn,
+	// unless the caller shares its own between several runs
+	if httpclient.LimiterFrom(fetchCtx) == nil {
+		fetchCtx = httpclient.WithLimiter(fetchCtx, httpclient.NewLimiter(sc.RateLimit))
+	}
+
+	progress.Emit(progress.Event{Event: progress.EventRunStart, Game: sc.GameName.String(), RunID: sc.RunID, Total: len(modIDs)}, time.Time{})
+	started := time.Now()
+	interrupted := 0
+	for i, modID := range modIDs {
+		if ctx.Err() != nil {
+			interrupted = len(modIDs) - i
+			break
+		}
+		if entry, ok := ignored.Find(sc.GameName.String(), modID); ok {
+			fmt.Fprintln(formatters.Status(), ignoredNote(entry))
+			runSummary.Ignored = append(runSummary.Ignored, modID)
+			emitModDone(modID, time.Time{}, progress.OutcomeIgnored, nil)
+			continue
+		}
+
+		sc.ModID = types.ModID(modID)
+		modStarted := time.Now()
+		progress.Emit(progress.Event{Event: progress.EventModStart, ID: modID}, time.Time{})
+		mod, err := scrapeMod(fetchCtx, sc, fetchModInfoFunc, fetchDocumentFunc)
+		if err != nil && fetchCtx.Err() != nil {
+			// Cut short by the shutdown, the mod is left for the next run
+			interrupted = len(modIDs) - i
+			emitModDone(modID, modStarted, progress.OutcomeInterrupted, nil)
+			break
+		}
+		if errors.Is(err, errModUnchanged) {
+			runSummary.Unchanged = append(runSummary.Unchanged, modID)
+			emitModDone(modID, modStarted, progress.OutcomeUnchanged, nil)
+			continue
+		}
+		if errors.Is(err, fetchers.ErrModFiltered) {
+			runSummary.Skipped = append(runSummary.Skipped, modID)
+			emitModDone(modID, modStarted, progress.OutcomeSkipped, nil)
+			continue
+		}
+		if err != nil {
+			if scrapeErr == nil {
+				scrapeErr = err
+			}
+			runSummary.Failed = append(runSummary.Failed, types.FailedMod{ModID: modID, Error: err.Error(), Kind: failures.Classify(err)})
+			emitModDone(modID, modStarted, progress.OutcomeFailed, err)
+			continue
+		}
+		if mod.Status != "" {
+			runSummary.Unavailable = append(runSummary.Unavailable, types.UnavailableMod{ModID: modID, Status: mod.Status})
+			emitModDone(modID, modStarted, progress.OutcomeUnavailable, nil)
+		} else {
+			runSummary.Succeeded = append(runSummary.Succeeded, modID)
+			emitModDone(modID, modStarted, progress.OutcomeSucceeded, nil)
+		}
+		if observe != nil {
+			observe(modID, mod)
+		}
+		if emitter != nil {
+			if emitErr = emitter.Emit(emit.Record{Game: sc.GameName.String(), Mod: mod, RunID: sc.RunID}); emitErr != nil {
+				// The listener is gone, the remaining mods are still scraped and saved
+				fmt.Fprintf(formatters.Status(), "Error streaming results: %v\n", emitErr)
+				emitter.Close()
+				emitter = nil
+			}
+		}
+		// Disappeared mods keep their last entry in the summary index and aren't notified
+		if mod.Status != "" {
+			continue
+		}
+		if sc.SaveResults {
+			saved = append(saved, summary.NewEntry(summaryFile(sc, mod), sc.RunID, mod))
+		}
+
+		if update, ok := detectUpdate(previous, sc.BaseUrl, sc.GameName.String(), mod); ok {
+			if update.RequirementsChanged() {
+				fmt.Fprintln(formatters.Status(), requirementsWarning(update))
+			}
+			updates = append(updates, update)
+		}
+	}
+
+	if interrupted > 0 {
+		fmt.Fprintf(formatters.Status(), "Stopped on shutdown, %d of %d mods left for the next run\n", interrupted, len(modIDs))
+	}
+	progress.Emit(progress.Event{Event: progress.EventRunDone, Failed: len(runSummary.Failed), RunID: sc.RunID, Succeeded: len(runSummary.Succeeded), Total: len(modIDs)}, started)
+
+	usage.AddMods(len(runSummary.Succeeded), len(runSummary.Failed))
+	if len(modIDs) > 1 {
+		printSummary(runSummary)
+	}
+	if sc.ShareSummary {
+		shareSummary(sc, runSummary, cookieValues(client, sc.BaseUrl))
+	}
+
+	// Batches start a summary index, which is then kept current by every later save
+	if sc.SaveResults && (len(modIDs) > 1 || hasSummary(sc)) {
+		if err := updateSummary(sc, saved, runSummary.Failed, savedFiles); err != nil {
+			return err
+		}
+	}
+
+	// The updates of a batch are dispatched by its owner along with those of other runs
+	if batch := notifiers.BatchFrom(ctx); batch != nil {
+		batch.Add(updates...)
+	} else if sc.NotifyWebhook != "" {
+		if err := notifiers.Dispatch(context.Background(), notifiers.NewWebhook(sc.NotifyWebhook), sc.RunID, updates, sc.Digest); err != nil {
+			fmt.Fprintf(formatters.Status(), "Error sending notifications: %v\n", err)
+		}
+	}
+
+	if uploader != nil {
+		if err := uploadSavedFiles(uploader, sc, savedFiles.Paths()); err != nil {
+			return failures.WithKind(err, failures.KindNetwork)
+		}
+	}
+
+	if sc.MetricsTextfile != "" {
+		metrics := formatters.FormatPrometheusMetrics(sc.GameName.String(), runSummary, time.Since(started), time.Now())
+		if err := exporters.SaveMetricsTextfile(sc.MetricsTextfile, metrics, utils.EnsureDirExists); err != nil {
+			return err
+		}
+	}
+
+	if len(runSummary.Failed) > 0 {
+		// A single mod keeps the original behaviour of surfacing its error directly
+		if len(modIDs) == 1 {
+			return scrapeErr
+		}
+		return &failures.RunError{Failed: len(runSummary.Failed), First: scrapeErr, Succeeded: len(runSummary.Succeeded), Total: len(modIDs)}
+	}
+
+	if emitErr != nil {
+		return failures.WithKind(emitErr, failures.KindNetwork)
+	}
+	return nil
+}
+
+// emitModDone writes the progress event of a mod done with the outcome, and the error
+// of a failed mod, when progress events are enabled.
+func emitModDone(modID int64, started time.Time, outcome string, err error) {
+	event := progress.Event{Event: progress.EventModDone, ID: modID, Outcome: outcome}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	progress.Emit(event, started)
+}
+
+// printSummary prints the outcome of a multi-mod scrape run, as formatted by
+// formatSummary.
+func printSummary(summary types.ScrapeSummary) {
+	fmt.Fprint(formatters.Status(), formatSummary(summary))
+}
+
+// formatSummary formats the outcome of a scrape run, listing how many mods were
+// scraped successfully, how many were skipped by filters, as ignored or as unchanged,
+// the status of each mod no longer available, and the reason each failed mod could
+// not be scraped.
+func formatSummary(summary types.ScrapeSummary) string {
+	var formatted strings.Builder
+	total := len(summary.Succeeded) + len(summary.Skipped) + len(summary.Ignored) + len(summary.Unchanged) + len(summary.Unavailable) + len(summary.Failed)
+	fmt.Fprintf(&formatted, "Run %s scraped %d of %d mods successfully\n", summary.RunID, len(summary.Succeeded), total)
+	if len(summary.Skipped) > 0 {
+		fmt.Fprintf(&formatted, "  %d mods skipped by filters: %v\n", len(summary.Skipped), summary.Skipped)
+	}
+	if len(summary.Ignored) > 0 {
+		fmt.Fprintf(&formatted, "  %d mods ignored: %v\n", len(summary.Ignored), summary.Ignored)
+	}
+	if len(summary.Unchanged) > 0 {
+		fmt.Fprintf(&formatted, "  %d mods unchanged since they were last saved: %v\n", len(summary.Unchanged), summary.Unchanged)
+	}
+	for _, unavailable := range summary.Unavailable {
+		fmt.Fprintf(&formatted, "  modID %d is no longer available: %s\n", unavailable.ModID, unavailable.Status)
+	}
+	for _, failed := range summary.Failed {
+		fmt.Fprintf(&formatted, "  modID %d failed: %s\n", failed.ModID, failed.Error)
+	}
+	return formatted.String()
+}
+
+// shareSummary uploads the summary of the run for the game to the configured gist or
+// paste endpoint and prints the URL it can be read at. The secrets, such as the values
+// of the session cookies, and the share token are redacted from it first. Failing to
+// share the summary only prints an error, it never fails the run.
+func shareSummary(sc types.CliFlags, runSummary types.ScrapeSummary, secrets []string) {
+	content := fmt.Sprintf("Game: %s\n", sc.GameName.String()) + formatSummary(runSummary)
+	content = share.Redact(content, append(secrets, sc.ShareToken)...)
+
+	name := fmt.Sprintf("nexus-mods-scraper-summary-%s.txt", sc.RunID)
+	sharedUrl, err := share.New(sc.ShareEndpoint, sc.ShareToken).Upload(context.Background(), name, content)
+	if err != nil {
+		fmt.Fprintf(formatters.Status(), "Error sharing summary: %v\n", err)
+		return
+	}
+	fmt.Fprintf(formatters.Status(), "Summary shared at %s\n", sharedUrl)
+}
+
+// cookieValues returns the values of the cookies the client sends to baseUrl, to be
+// kept out of anything shared.
+func cookieValues(client *http.Client, baseUrl string) []string {
+	parsed, err := url.Parse(baseUrl)
+	if err != nil || client.Jar == nil {
+		return nil
+	}
+
+	var values []string
+	for _, cookie := range client.Jar.Cookies(parsed) {
+		values = append(values, cookie.Value)
+	}
+	return values
+}
+
+// unavailableNote returns the note printed when the mod is found to be no longer
+// available, with its status.
+func unavailableNote(mod types.ModInfo) string {
+	return fmt.Sprintf("modID: %d is no longer available: %s", mod.ModID, mod.Status)
+}
+
+// ignoredNote returns the note printed when the mod of the ignore list entry is
+// skipped, with the reason it is ignored for when there is one.
+func ignoredNote(entry ignore.Entry) string {
+	note := fmt.Sprintf("Skipped modID: %d for game: %s, it is on the ignore list", entry.ModID, entry.Game)
+	if entry.Reason != "" {
+		note += fmt.Sprintf(" (%s)", entry.Reason)
+	}
+	return note
+}
+
+// updateSummary merges the outcome of the run into the summary index of the game's
+// output directory, creating it on the first run, and writes its Markdown rendering
+// alongside when requested. The files written are added to files.
+func updateSummary(sc types.CliFlags, saved []summary.Entry, failed []types.FailedMod, files *exporters.SavedFiles) error {
+	game := sc.GameName.String()
+	outputGameDirectory := filepath.Join(sc.OutputDirectory, game)
+	summaryPath := filepath.Join(outputGameDirectory, summary.Filename)
+
+	index, err := summary.Load(summaryPath)
+	if err != nil {
+		return err
+	}
+	index.Game = game
+	index.Merge(sc.RunID, saved, failed, time.Now())
+
+	if err := summary.Save(summaryPath, index); err != nil {
+		return err
+	}
+	fmt.Fprintf(formatters.Status(), "Summary saved to %s\n", formatters.PathLink(summaryPath))
+	files.Add(summaryPath)
+
+	if sc.SummaryMarkdown {
+		markdownPath := filepath.Join(outputGameDirectory, summary.MarkdownFilename)
+		if err := summary.SaveMarkdown(markdownPath, index); err != nil {
+			return err
+		}
+		files.Add(markdownPath)
+	}
+	return nil
+}
+
+// hasSummary reports whether the output directory of the game already holds a summary
+// index.
+func hasSummary(sc types.CliFlags) bool {
+	_, err := fsys.Default.Stat(filepath.Join(sc.OutputDirectory, sc.GameName.String(), summary.Filename))
+	return err == nil
+}
+
+// scrapeMod orchestrates the process of scraping a single mod, including scraping mod
+// info, displaying results, saving results and recording history based on the
+// provided command-line flags. It uses spinners to indicate progress throughout the
+// operations and accepts functions for fetching mod info and documents, returning the
+// scraped mod, errModUnchanged for a mod skipped with skip-unchanged, or an error if
+// any step fails.
+func scrapeMod(
+	ctx context.Context,
+	sc types.CliFlags,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) (types.ModInfo, error) {
+	fields, err := fetchers.ParseFields(sc.Fields)
+	if err != nil {
+		return types.ModInfo{}, err
+	}
+
+	ctx, cancel := modContext(ctx, sc.PerModTimeout)
+	defer cancel()
+	ctx = fetchers.WithFields(ctx, fields)
 
 	// Create and start the spinner for scraping mod info
 	scrapeSpinner := spinners.CreateSpinner(fmt.Sprintf("Scraping modID: %d for game: %s", sc.ModID, sc.GameName), "✓", "Mod scraping complete", "✗", "Mod scraping failed")
 	if err := scrapeSpinner.Start(); err != nil {
-		return fmt.Errorf("failed to start spinner: %w", err)
+		return types.ModInfo{}, fmt.Errorf("failed to start spinner: %w", err)
+	}
+
+	// Keep the fetched pages to archive them with the results
+	var recorder *htmlarchive.Recorder
+	if sc.ArchiveHtml && sc.SaveResults {
+		recorder = htmlarchive.NewRecorder()
+		fetchDocumentFunc = recorder.Wrap(fetchDocumentFunc)
+	}
+
+	// Keep the gallery of the main page to download its images with the results
+	var gallery *images.Gallery
+	downloader := images.DownloaderFrom(ctx)
+	if downloader != nil && sc.SaveResults {
+		gallery = &images.Gallery{}
+		fetchDocumentFunc = gallery.Wrap(fetchDocumentFunc)
+	}
+
+	// Skip the mods that haven't changed since they were saved, along with their files tab
+	filter, unchanged := allFilters(tagFilter(sc.FilterTags), categoryFilter(sc.FilterCategory)), false
+	if sc.SkipUnchanged {
+		matches := filter
+		filter = func(mod types.ModInfo) bool {
+			if matches != nil && !matches(mod) {
+				return false
+			}
+			unchanged = savedModUnchanged(sc, mod)
+			return !unchanged
+		}
 	}
 
 	// Scrape Mod Info
-	results, err := fetchModInfoFunc(sc.BaseUrl, sc.GameName, sc.ModID, utils.ConcurrentFetch, fetchDocumentFunc)
+	start := time.Now()
+	results, err := fetchModInfoFunc(ctx, sc.BaseUrl, sc.GameName, sc.ModID, filter, utils.ConcurrentFetch, fetchDocumentFunc)
+	if errors.Is(err, fetchers.ErrModFiltered) && unchanged {
+		scrapeSpinner.StopMessage(fmt.Sprintf("Skipped modID: %d, unchanged since it was last saved", sc.ModID))
+		scrapeSpinner.Stop()
+		return types.ModInfo{}, errModUnchanged
+	}
+	if errors.Is(err, fetchers.ErrModFiltered) {
+		scrapeSpinner.StopMessage(fmt.Sprintf("Skipped modID: %d, it does not match the filters", sc.ModID))
+		scrapeSpinner.Stop()
+		return types.ModInfo{}, err
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		err = failures.WithKind(fmt.Errorf("timed out after %s", sc.PerModTimeout), failures.KindNetwork)
+	}
 	if err != nil {
 		scrapeSpinner.StopFailMessage(fmt.Sprintf("Error scraping mod: %v", err))
 		scrapeSpinner.StopFail()
-		return err
+		return types.ModInfo{}, err
+	}
+	if results.Mods.Status != "" {
+		scrapeSpinner.StopMessage(unavailableNote(results.Mods))
 	}
 	scrapeSpinner.Stop()
 
+	// Compare the mod with the one the shadow backend fetches, before it is transformed
+	if shadow := fetchers.ShadowFrom(ctx); shadow != nil && results.Mods.Status == "" {
+		if err := compareShadow(ctx, sc, shadow, results.Mods, fetchDocumentFunc); err != nil {
+			return types.ModInfo{}, err
+		}
+	}
+
+	// Run the transform stages, e.g. file category and changelog language filters
+	pipeline, err := transform.New(sc)
+	if err != nil {
+		return types.ModInfo{}, err
+	}
+	results.Mods = pipeline.Apply(results.Mods)
+
 	// Display Results
 	if sc.DisplayResults {
 		displaySpinner := spinners.CreateSpinner("Displaying results", "✓", "Results displayed", "✗", "Failed to display results")
 		if err := displaySpinner.Start(); err != nil {
-			return fmt.Errorf("failed to start display spinner: %w", err)
+			return types.ModInfo{}, fmt.Errorf("failed to start display spinner: %w", err)
 		}
 		displaySpinner.Stop() // Temporarily stop spinner for clean output
 
 		// Print the results
 		if err := exporters.DisplayResults(sc, results, formatters.FormatResultsAsJson); err != nil {
-			fmt.Println("Error displaying results:", err)
+			fmt.Fprintln(formatters.Status(), "Error displaying results:", err)
 			displaySpinner.StopFail()
-			return err
+			return types.ModInfo{}, err
 		}
 		displaySpinner.Stop() // Restart the spinner after results are displayed
 	}
 
-	// Save Results
+	// Save Results, collecting the files saved to upload them once the run is done
 	if sc.SaveResults {
+		savedFiles := exporters.SavedFilesFrom(ctx)
 		saveSpinner := spinners.CreateSpinner("Saving results", "✓", "Results saved successfully", "✗", "Failed to save results")
 		if err := saveSpinner.Start(); err != nil {
-			return fmt.Errorf("failed to start save spinner: %w", err)
+			return types.ModInfo{}, fmt.Errorf("failed to start save spinner: %w", err)
+		}
+
+		if sc.IncludeMeta {
+			results.Meta = scrapeMeta(sc, results.Mods, start, time.Now())
 		}
 
-		outputGameDirectory := filepath.Join(sc.OutputDirectory, strings.ToLower(sc.GameName))
-		if err := utils.EnsureDirExists(outputGameDirectory); err != nil {
+		outputDirectory, outputFilename := savePath(sc, results.Mods)
+		if err := utils.EnsureDirExists(outputDirectory); err != nil {
 			saveSpinner.StopFailMessage(fmt.Sprintf("Error creating directory: %v", err))
 			saveSpinner.StopFail()
-			return err
+			return types.ModInfo{}, err
 		}
 
-		outputFilename := fmt.Sprintf("%s %d", strings.ToLower(results.Mods.Name), results.Mods.ModID)
-		if item, err := exporters.SaveModInfoToJson(sc, results, outputGameDirectory, outputFilename, utils.EnsureDirExists); err != nil {
+		if item, err := exporters.SaveModInfoToJson(sc, results, outputDirectory, outputFilename, utils.EnsureDirExists); err != nil {
 			saveSpinner.StopFailMessage(fmt.Sprintf("Error saving results: %v", err))
 			saveSpinner.StopFail()
-			return err
+			return types.ModInfo{}, err
 		} else {
 			// saveSpinner.StopMessage(fmt.Sprintf("Saved successfully to %s", item))
-			saveSpinner.StopMessage(fmt.Sprintf("Saved successfully to %s", termlink.ColorLink(item, item, "green")))
+			saveSpinner.StopMessage(fmt.Sprintf("Saved successfully to %s", formatters.PathLink(item)))
+			savedFiles.Add(item)
 		}
 		saveSpinner.Stop()
+
+		// Write the mod manager metadata, named after the mod rather than the snapshot
+		metaPath, err := exporters.SaveModManagerMeta(sc.Format, sc.GameName, results.Mods, outputDirectory, modFilename(sc, results.Mods), utils.EnsureDirExists)
+		if err != nil {
+			return types.ModInfo{}, err
+		}
+		if metaPath != "" {
+			savedFiles.Add(metaPath)
+		}
+
+		// Archive the fetched pages next to the results
+		if recorder != nil {
+			archived, err := recorder.Save(outputDirectory, outputFilename)
+			if err != nil {
+				return types.ModInfo{}, err
+			}
+			savedFiles.Add(archived...)
+		}
+
+		// Download the gallery images, shared by the snapshots of the mod
+		if gallery != nil && results.Mods.Status == "" {
+			downloaded, err := downloadImages(ctx, downloader, images.Dir(outputDirectory, modFilename(sc, results.Mods)), gallery.URLs())
+			savedFiles.Add(downloaded...)
+			if err != nil {
+				return types.ModInfo{}, failures.WithKind(err, failures.KindNetwork)
+			}
+		}
+
+		// Drop the snapshots beyond the retention limit
+		if sc.Snapshot {
+			if _, err := exporters.PruneSnapshots(outputDirectory, modFilename(sc, results.Mods), sc.KeepLast); err != nil {
+				return types.ModInfo{}, err
+			}
+		}
+	}
+
+	// Record History, which has no version to record for a mod that is no longer available
+	if sc.RecordHistory && results.Mods.Status == "" {
+		entry := history.NewEntry(sc.GameName.String(), results.Mods)
+		entry.RunID = sc.RunID
+		if err := store.AppendHistory(sc.StorageDriver, sc.HistoryFile, entry); err != nil {
+			return types.ModInfo{}, fmt.Errorf("error recording history: %w", err)
+		}
+	}
+
+	return results.Mods, nil
+}
+
+// downloadImages downloads the gallery images at urls to dir, with a spinner showing
+// the progress, and returns the paths of the files written. Returns an error if an
+// image fails to download, the others being kept.
+func downloadImages(ctx context.Context, downloader *images.Downloader, dir string, urls []string) ([]string, error) {
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	imagesSpinner := spinners.CreateSpinner(fmt.Sprintf("Downloading %d gallery images", len(urls)), "✓", "Gallery images downloaded", "✗", "Gallery image download failed")
+	if err := imagesSpinner.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start spinner: %w", err)
+	}
+	result, err := downloader.Download(ctx, dir, urls)
+	if err != nil {
+		imagesSpinner.StopFailMessage(fmt.Sprintf("Error downloading the gallery images: %v", err))
+		imagesSpinner.StopFail()
+		return result.Paths, err
+	}
+	imagesSpinner.StopMessage(fmt.Sprintf("Downloaded %d gallery images to %s, %d already saved", result.Downloaded, formatters.PathLink(dir), result.Skipped))
+	imagesSpinner.Stop()
+	return result.Paths, nil
+}
+
+// compareShadow fetches the mod of sc with the shadow backend, with a spinner showing
+// the progress, and writes the fields on which it disagrees with mod. The shadow
+// backend failing is reported without failing the mod. Returns an error only if the
+// spinner can't be started.
+func compareShadow(ctx context.Context, sc types.CliFlags, shadow *fetchers.Shadow, mod types.ModInfo, fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error)) error {
+	compareSpinner := spinners.CreateSpinner(fmt.Sprintf("Comparing with the %s backend", shadow.Backend), "✓", "Backends agree", "✗", "Shadow compare failed")
+	if err := compareSpinner.Start(); err != nil {
+		return fmt.Errorf("failed to start spinner: %w", err)
+	}
+	results, err := shadow.FetchModInfo(ctx, sc.BaseUrl, sc.GameName, sc.ModID, nil, utils.ConcurrentFetch, fetchDocumentFunc)
+	if err != nil {
+		compareSpinner.StopFailMessage(fmt.Sprintf("Error fetching the mod with the %s backend: %v", shadow.Backend, err))
+		compareSpinner.StopFail()
+		return nil
 	}
 
+	divergences := fetchers.Compare(mod, results.Mods)
+	if len(divergences) == 0 {
+		compareSpinner.StopMessage(fmt.Sprintf("The %s backend agrees on every compared field", shadow.Backend))
+		compareSpinner.Stop()
+		return nil
+	}
+	primary := fetchers.BackendHtml
+	if shadow.Backend == fetchers.BackendHtml {
+		primary = fetchers.BackendGraphQL
+	}
+	compareSpinner.StopMessage(fmt.Sprintf("The %s backend disagrees on %d fields, %s against %s:", shadow.Backend, len(divergences), primary, shadow.Backend))
+	compareSpinner.Stop()
+	for _, divergence := range divergences {
+		fmt.Fprintf(formatters.Status(), "  %s\n", divergence)
+	}
 	return nil
 }
+
+// uploadOptions returns the settings of the uploads of a run of sc.
+func uploadOptions(sc types.CliFlags) exporters.UploadOptions {
+	return exporters.UploadOptions{
+		Concurrency: sc.UploadConcurrency,
+		Endpoint:    sc.UploadEndpoint,
+		Password:    sc.UploadPassword,
+		Region:      sc.UploadRegion,
+		User:        sc.UploadUser,
+	}
+}
+
+// uploadSavedFiles uploads the files saved by a run of sc, keyed by their path within
+// the output directory, with a spinner showing the progress. Returns an error if any
+// file fails to upload.
+func uploadSavedFiles(uploader exporters.Uploader, sc types.CliFlags, paths []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+	uploadSpinner := spinners.CreateSpinner(fmt.Sprintf("Uploading %d files to %s", len(paths), sc.Upload), "✓", fmt.Sprintf("Uploaded %d files to %s", len(paths), sc.Upload), "✗", "Upload failed")
+	if err := uploadSpinner.Start(); err != nil {
+		return fmt.Errorf("failed to start spinner: %w", err)
+	}
+	if err := exporters.UploadFiles(context.Background(), uploader, sc.OutputDirectory, paths, sc.UploadConcurrency); err != nil {
+		uploadSpinner.StopFailMessage(fmt.Sprintf("Error uploading the saved files: %v", err))
+		uploadSpinner.StopFail()
+		return err
+	}
+	uploadSpinner.Stop()
+	return nil
+}
+
+// scrapeMeta returns the metadata of the scrape of mod that started at start and ended
+// at end, saved along with the results with --include-meta.
+func scrapeMeta(sc types.CliFlags, mod types.ModInfo, start, end time.Time) *types.ScrapeMeta {
+	sourceUrl := mod.Url
+	if sourceUrl == "" {
+		sourceUrl = fmt.Sprintf("%s/%s/mods/%d", sc.BaseUrl, sc.GameName, mod.ModID)
+	}
+
+	return &types.ScrapeMeta{
+		Duration:       end.Sub(start).Round(time.Millisecond).String(),
+		SchemaVersion:  types.SchemaVersion,
+		ScrapedAt:      types.NewTimestamp(start.UTC()),
+		ScraperVersion: version.Get().Version,
+		SourceUrl:      sourceUrl,
+	}
+}
+
+// detectUpdate compares the scraped mod with its last recorded history entry and returns
+// the update to notify about when it has changed, by a new version or last update, or
+// by adding or removing Nexus requirements. Mods without a recorded entry are not
+// considered updated.
+func detectUpdate(previous map[string]history.Entry, baseUrl, game string, mod types.ModInfo) (notifiers.Update, bool) {
+	last, ok := previous[history.Key(game, mod.ModID)]
+	if !ok {
+		return notifiers.Update{}, false
+	}
+
+	current := history.NewEntry(game, mod)
+	added, removed := history.RequirementsChanged(last, current)
+	if !history.Updated(last, current) && len(added) == 0 && len(removed) == 0 {
+		return notifiers.Update{}, false
+	}
+
+	modUrl := mod.Url
+	if modUrl == "" {
+		modUrl = fmt.Sprintf("%s/%s/mods/%d", baseUrl, game, mod.ModID)
+	}
+
+	return notifiers.Update{
+		AddedRequirements:   added,
+		Game:                game,
+		ModID:               mod.ModID,
+		Name:                mod.Name,
+		PreviousVersion:     last.Version,
+		RemovedRequirements: removed,
+		TotalDLs:            mod.TotalDLs,
+		Url:                 modUrl,
+		Version:             current.Version,
+	}, true
+}
+
+// requirementsWarning returns the line warning that an update added or removed Nexus
+// requirements, e.g. "⚠ SkyUI (skyrimspecialedition/12604): new requirements SKSE64".
+func requirementsWarning(update notifiers.Update) string {
+	var changes []string
+	if len(update.AddedRequirements) > 0 {
+		changes = append(changes, "new requirements "+strings.Join(update.AddedRequirements, ", "))
+	}
+	if len(update.RemovedRequirements) > 0 {
+		changes = append(changes, "dropped requirements "+strings.Join(update.RemovedRequirements, ", "))
+	}
+	return fmt.Sprintf("⚠ %s (%s/%d): %s", update.Name, update.Game, update.ModID, strings.Join(changes, ", "))
+}
+
+// savedModUnchanged reports whether the saved results of the mod, its most recent
+// snapshot in snapshot mode, show the same last update as its page. A mod whose page
+// shows no last update, or without readable saved results, is never unchanged.
+func savedModUnchanged(sc types.CliFlags, mod types.ModInfo) bool {
+	if mod.LastUpdated == "" {
+		return false
+	}
+
+	dir, _ := savePath(sc, mod)
+	path := filepath.Join(dir, modFilename(sc, mod)+".json")
+	if sc.Snapshot {
+		latest, ok := exporters.LatestSnapshot(dir, modFilename(sc, mod))
+		if !ok {
+			return false
+		}
+		path = latest
+	}
+
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	var saved types.Results
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return false
+	}
+	return saved.Mods.LastUpdated == mod.LastUpdated
+}
+
+// modFilename returns the name, without extension, of the file a scraped mod is saved
+// to according to the path template and naming policy, e.g. "skyui 3863".
+func modFilename(sc types.CliFlags, mod types.ModInfo) string {
+	path := exporters.RenderPathTemplate(sc.PathTemplate, sc.GameName, mod, filenamePolicy(sc))
+	return strings.TrimSuffix(filepath.Base(path), ".json")
+}
+
+// savePath returns the directory and the name, without extension, of the file the
+// scraped mod is saved to: the path template rendered within the output directory,
+// with the file name followed in snapshot mode by the time it was scraped, e.g.
+// "skyui 3863 2024-06-01T12-00".
+func savePath(sc types.CliFlags, mod types.ModInfo) (string, string) {
+	path := exporters.RenderPathTemplate(sc.PathTemplate, sc.GameName, mod, filenamePolicy(sc))
+	dir := filepath.Join(sc.OutputDirectory, filepath.Dir(path))
+	if !sc.Snapshot {
+		return dir, modFilename(sc, mod)
+	}
+
+	scrapedAt := mod.LastChecked
+	if scrapedAt.IsZero() {
+		scrapedAt = time.Now()
+	}
+	return dir, filenamePolicy(sc).Snapshot(modFilename(sc, mod), scrapedAt)
+}
+
+// filenamePolicy returns the naming policy of the saved files configured by the flags.
+func filenamePolicy(sc types.CliFlags) exporters.FilenamePolicy {
+	return exporters.FilenamePolicy{Case: sc.FilenameCase, Separator: sc.FilenameSeparator}
+}
+
+// summaryFile returns the path of the file the scraped mod is saved to, relative to the
+// game's output directory holding the summary index, e.g. "skyui 3863.json".
+func summaryFile(sc types.CliFlags, mod types.ModInfo) string {
+	dir, filename := savePath(sc, mod)
+	path := filepath.Join(dir, filename+".json")
+	if rel, err := filepath.Rel(filepath.Join(sc.OutputDirectory, sc.GameName.String()), path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// modContext returns the context used to scrape a single mod, derived from parent.
+// When timeout is greater than zero the context expires after that duration, otherwise
+// it only ends with parent.
+func modContext(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout > 0 {
+		return context.WithTimeout(parent, timeout)
+	}
+	return context.WithCancel(parent)
+}
+
+// parseModArgs returns the game slug and mod ID of a mod given as command arguments.
+// Returns an error if either is invalid.
+func parseModArgs(game, modID string) (types.GameSlug, types.ModID, error) {
+	slug, err := types.ParseGameSlug(game)
+	if err != nil {
+		return "", 0, err
+	}
+	id, err := types.ParseModID(modID)
+	if err != nil {
+		return "", 0, err
+	}
+	return slug, id, nil
+}
+
+// parseMod returns the game slug and mod ID of a mod given by its game and number,
+// e.g. as read from a requirement link or a saved result. Returns an error if either
+// is invalid.
+func parseMod(game string, modID int64) (types.GameSlug, types.ModID, error) {
+	slug, err := types.ParseGameSlug(game)
+	if err != nil {
+		return "", 0, err
+	}
+	id, err := types.NewModID(modID)
+	if err != nil {
+		return "", 0, err
+	}
+	return slug, id, nil
+}
+
+// drainContext returns a context outliving ctx by the drain timeout, so that the work
+// in flight when ctx is done, e.g. on SIGTERM, gets a chance to finish before it is
+// cancelled. A drain timeout of zero cancels it along with ctx.
+func drainContext(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	drainCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	go func() {
+		select {
+		case <-drainCtx.Done():
+			return
+		case <-ctx.Done():
+		}
+
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case <-drainCtx.Done():
+		case <-timer.C:
+			cancel()
+		}
+	}()
+	return drainCtx, cancel
+}
+
+// categoryFilter returns a ModFilter that keeps only mods of the category, compared
+// case-insensitively. It returns nil when no category is given so that no filtering
+// takes place.
+func categoryFilter(category string) fetchers.ModFilter {
+	category = strings.TrimSpace(category)
+	if category == "" {
+		return nil
+	}
+
+	return func(mod types.ModInfo) bool {
+		return strings.EqualFold(mod.Category, category)
+	}
+}
+
+// allFilters returns a ModFilter that keeps only mods kept by every one of the filters,
+// nil filters being ignored. It returns nil when all of them are nil.
+func allFilters(filters ...fetchers.ModFilter) fetchers.ModFilter {
+	var kept []fetchers.ModFilter
+	for _, filter := range filters {
+		if filter != nil {
+			kept = append(kept, filter)
+		}
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+
+	return func(mod types.ModInfo) bool {
+		for _, filter := range kept {
+			if !filter(mod) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// tagFilter returns a ModFilter that keeps only mods tagged with every one of the
+// provided tags, compared case-insensitively. It returns nil when no tags are given
+// so that no filtering takes place.
+func tagFilter(tags []string) fetchers.ModFilter {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	return func(mod types.ModInfo) bool {
+		modTags := make(map[string]bool, len(mod.Tags))
+		for _, tag := range mod.Tags {
+			modTags[strings.ToLower(tag)] = true
+		}
+
+		for _, tag := range tags {
+			if !modTags[strings.ToLower(strings.TrimSpace(tag))] {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -1,17 +1,41 @@
 package cli
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/emit"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/htmlarchive"
+	"github.com/ondrovic/nexus-mods-scraper/internal/ignore"
+	"github.com/ondrovic/nexus-mods-scraper/internal/images"
+	"github.com/ondrovic/nexus-mods-scraper/internal/notifiers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/progress"
+	"github.com/ondrovic/nexus-mods-scraper/internal/summary"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -42,17 +66,17 @@ func (m *Mocker) RoundTrip(req *http.Request) (*http.Response, error) {
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
-var mockFetchDocument = func(_ string) (*goquery.Document, error) {
+var mockFetchDocument = func(_ context.Context, _ string) (*goquery.Document, error) {
 	html := `<html><body>Mocked HTML content</body></html>`
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
 	return doc, nil
 }
 
-var mockFetchModInfoConcurrent = func(baseUrl, game string, modId int64, concurrentFetch func(tasks ...func() error) error, fetchDocument func(targetURL string) (*goquery.Document, error)) (types.Results, error) {
+var mockFetchModInfoConcurrent = func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
 	return types.Results{
 		Mods: types.ModInfo{
 			Name:  "Mocked Mod",
-			ModID: modId,
+			ModID: modId.Int64(),
 		},
 	}, nil
 }
@@ -90,7 +114,7 @@ func TestRun_NoResultsFlagSet(t *testing.T) {
 	err := run(mockCmd, args)
 
 	// Assert the expected error
-	assert.EqualError(t, err, "at least one of --display-results (-r) or --save-results (-s) must be enabled")
+	assert.EqualError(t, err, "at least one of --display-results (-r), --save-results (-s) or --emit must be enabled")
 }
 
 func TestRun_InvalidModID(t *testing.T) {
@@ -100,8 +124,9 @@ func TestRun_InvalidModID(t *testing.T) {
 		RunE: run, // Point to the real `run` function
 	}
 
-	// Initialize the scraper flags (as done in `initScrapeFlags`)
-	initScrapeFlags(mockCmd)
+	// Initialize the scraper flags (as done in `init`)
+	flags := types.CliFlags{}
+	config.RegisterScrapeFlags(mockCmd, &flags)
 
 	// Set the args as if they were passed via command-line
 	args := []string{"game", "toast", "--display-results"}
@@ -117,7 +142,247 @@ func TestRun_InvalidModID(t *testing.T) {
 	assert.EqualError(t, err, "strconv.ParseInt: parsing \"toast\": invalid syntax")
 
 	// Optionally, you can also assert the `DisplayResults` is set to true
-	assert.True(t, viper.GetBool("display-results"))
+	assert.True(t, flags.DisplayResults)
+}
+
+func TestRun_KeepLastRequiresSnapshot(t *testing.T) {
+	// Arrange
+	mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+	config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+	mockCmd.SetArgs([]string{"game", "1234", "--save-results", "--keep-last", "3"})
+
+	// Act
+	err := mockCmd.Execute()
+
+	// Assert
+	assert.EqualError(t, err, "--keep-last requires --snapshot")
+}
+
+func TestRun_MaxModsCap(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"over the cap", []string{"game", "1,2,3", "--display-results", "--max-mods", "2"}, "refusing to scrape 3 mods, more than the cap of 2 mods per run: raise it with --max-mods or confirm with --yes"},
+		{"negative cap", []string{"game", "1", "--display-results", "--max-mods", "-1"}, "--max-mods must not be negative"},
+		{"invalid path template", []string{"game", "1", "--display-results", "--path-template", "{game}/{name}.json"}, `path template "{game}/{name}.json" must include {modid}`},
+		{"skip unchanged with version path", []string{"game", "1", "--save-results", "--skip-unchanged", "--path-template", "{game}/{name} {version} {modid}.json"}, "--skip-unchanged requires a --path-template without {version}, which is only known once the files tab is read"},
+		{"invalid filename case", []string{"game", "1", "--display-results", "--filename-case", "camel"}, `unsupported filename case "camel", expected preserve, lower, kebab or snake`},
+		{"unknown field", []string{"game", "1", "--display-results", "--only", "changelog"}, `unknown field "changelog", expected one of ` + strings.Join(fetchers.FieldNames(), ", ")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+			config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+			mockCmd.SetArgs(tt.args)
+
+			// Act
+			err := mockCmd.Execute()
+
+			// Assert
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestReadModIDs(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, fsys.Default.WriteFile("wishlist.txt", []byte("# wishlist\n12\n34\n"), 0644))
+	require.NoError(t, fsys.Default.WriteFile("empty.txt", []byte(""), 0644))
+
+	tests := []struct {
+		name     string
+		stdin    string
+		idsFile  string
+		args     []string
+		expected []int64
+		err      string
+	}{
+		{name: "argument", args: []string{"1,2"}, expected: []int64{1, 2}},
+		{name: "stdin", stdin: "5\n6\n", args: []string{"-"}, expected: []int64{5, 6}},
+		{name: "ids file", idsFile: "wishlist.txt", expected: []int64{12, 34}},
+		{name: "empty ids file", idsFile: "empty.txt", err: "empty.txt: no mod ids found"},
+		{name: "both", idsFile: "wishlist.txt", args: []string{"1"}, err: "give the mod ids either as an argument or with --ids-file, not both"},
+		{name: "none", err: "no mod ids given: pass them as an argument, - to read them from stdin, or --ids-file"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			modIDs, err := readModIDs(strings.NewReader(tt.stdin), tt.idsFile, tt.args)
+
+			// Assert
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, modIDs)
+		})
+	}
+}
+
+func TestReadModUrls(t *testing.T) {
+	tests := []struct {
+		name     string
+		arg      string
+		game     string
+		expected []int64
+		err      string
+	}{
+		{name: "single url", arg: "https://www.nexusmods.com/skyrimspecialedition/mods/3863", game: "skyrimspecialedition", expected: []int64{3863}},
+		{name: "query and tab", arg: "https://www.nexusmods.com/skyrimspecialedition/mods/3863?tab=files", game: "skyrimspecialedition", expected: []int64{3863}},
+		{name: "several urls", arg: "https://www.nexusmods.com/skyrim/mods/1, nexusmods.com/skyrim/mods/2/", game: "skyrim", expected: []int64{1, 2}},
+		{name: "different games", arg: "https://www.nexusmods.com/skyrim/mods/1,https://www.nexusmods.com/fallout4/mods/2", err: "mod urls of different games given, skyrim and fallout4: scrape them separately"},
+		{name: "not a mod url", arg: "https://www.nexusmods.com/skyrim/collections/abc", err: `not a mod url: "https://www.nexusmods.com/skyrim/collections/abc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			game, modIDs, err := readModUrls(tt.arg)
+
+			// Assert
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.game, game)
+			assert.Equal(t, tt.expected, modIDs)
+		})
+	}
+}
+
+func TestRun_ModUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		expected string
+	}{
+		{"url and mod ids", []string{"https://www.nexusmods.com/skyrim/mods/1", "2", "--display-results"}, "give either mod urls or a game and mod ids, not both"},
+		{"mod ids read from the url", []string{"https://www.nexusmods.com/skyrim/mods/1,https://www.nexusmods.com/skyrim/mods/2", "--display-results", "--max-mods", "1"}, "refusing to scrape 2 mods, more than the cap of 1 mods per run: raise it with --max-mods or confirm with --yes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+			config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+			mockCmd.SetArgs(tt.args)
+
+			// Act
+			err := mockCmd.Execute()
+
+			// Assert
+			assert.EqualError(t, err, tt.expected)
+		})
+	}
+}
+
+func TestRun_DryRun(t *testing.T) {
+	output := t.TempDir()
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+		err      string
+	}{
+		{
+			name: "planned requests and files",
+			args: []string{"skyrim", "3,1,3", "--dry-run", "--save-results", "--output-directory", output, "--cookie-directory", "cookies"},
+			expected: []string{
+				"Cookies: " + filepath.Join("cookies", "session-cookies.json"),
+				"Session check: GET https://nexusmods.com/users/myaccount",
+				"Would scrape 2 mods of skyrim:",
+				"  3",
+				"    GET https://nexusmods.com/skyrim/mods/3",
+				"    GET https://nexusmods.com/skyrim/mods/3?tab=files",
+				"    Save to " + filepath.Join(output, "skyrim", "{name} 3.json"),
+				"  1",
+				"    GET https://nexusmods.com/skyrim/mods/1",
+				"    GET https://nexusmods.com/skyrim/mods/1?tab=files",
+				"    Save to " + filepath.Join(output, "skyrim", "{name} 1.json"),
+				"Dropped 1 duplicate mod ids",
+			},
+		},
+		{
+			name: "graphql backend over the cap",
+			args: []string{"skyrim", "1,2", "--dry-run", "--no-preflight", "--backend", "graphql", "--cookie-header", "a=b", "--max-mods", "1"},
+			expected: []string{
+				"Cookies: --cookie-header",
+				"Session check: skipped with --no-preflight",
+				"Would scrape 2 mods of skyrim:",
+				"  1",
+				"    POST " + fetchers.DefaultGraphQLEndpoint,
+				"  2",
+				"    POST " + fetchers.DefaultGraphQLEndpoint,
+				"The run would be refused, more than the cap of 1 mods per run: raise it with --max-mods or confirm with --yes",
+			},
+		},
+		{
+			name:     "shadow compare",
+			args:     []string{"skyrim", "1", "--dry-run", "--no-preflight", "--cookie-header", "a=b", "--shadow-compare"},
+			expected: []string{"Cookies: --cookie-header", "Session check: skipped with --no-preflight", "Would scrape 1 mods of skyrim:", "  1", "    GET https://nexusmods.com/skyrim/mods/1", "    GET https://nexusmods.com/skyrim/mods/1?tab=files", "    POST " + fetchers.DefaultGraphQLEndpoint},
+		},
+		{
+			name:     "main page only",
+			args:     []string{"skyrim", "1", "--dry-run", "--no-preflight", "--only", "name"},
+			expected: []string{"Cookies: " + filepath.Join(storage.GetDataStoragePath(), "session-cookies.json"), "Session check: skipped with --no-preflight", "Would scrape 1 mods of skyrim:", "  1", "    GET https://nexusmods.com/skyrim/mods/1"},
+		},
+		{
+			name:     "gallery images",
+			args:     []string{"skyrim", "1", "--dry-run", "--no-preflight", "--only", "name", "--save-results", "--output-directory", output, "--cookie-header", "a=b", "--download-images"},
+			expected: []string{"Cookies: --cookie-header", "Session check: skipped with --no-preflight", "Would scrape 1 mods of skyrim:", "  1", "    GET https://nexusmods.com/skyrim/mods/1", "    Save to " + filepath.Join(output, "skyrim", "{name} 1.json"), "    Download the gallery images to " + filepath.Join(output, "skyrim", "{name} 1.images")},
+		},
+		{
+			name:     "upload",
+			args:     []string{"skyrim", "1", "--dry-run", "--no-preflight", "--only", "name", "--save-results", "--output-directory", output, "--cookie-header", "a=b", "--upload", "s3://bucket/prefix"},
+			expected: []string{"Cookies: --cookie-header", "Session check: skipped with --no-preflight", "Would scrape 1 mods of skyrim:", "  1", "    GET https://nexusmods.com/skyrim/mods/1", "    Save to " + filepath.Join(output, "skyrim", "{name} 1.json"), "Upload the saved files to s3://bucket/prefix"},
+		},
+		{name: "non-positive ids", args: []string{"skyrim", "1,0,-4", "--dry-run"}, err: "invalid mod ids 0, -4: mod ids must be positive numbers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var out bytes.Buffer
+			mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+			config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+			config.RegisterDryRunFlag(mockCmd)
+			mockCmd.SetOut(&out)
+			mockCmd.SetArgs(tt.args)
+
+			// Act
+			err := mockCmd.Execute()
+
+			// Assert
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, strings.Join(tt.expected, "\n")+"\n", out.String())
+			assert.NoDirExists(t, filepath.Join(output, "skyrim"))
+		})
+	}
+}
+
+func TestRun_ReadsModIDsFromStdin(t *testing.T) {
+	// Arrange
+	mockCmd := &cobra.Command{Use: "scrape", Args: cobra.RangeArgs(1, 2), RunE: run}
+	config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+	mockCmd.SetIn(strings.NewReader("1\n2\n3\n"))
+	mockCmd.SetArgs([]string{"game", "-", "--display-results", "--max-mods", "2"})
+
+	// Act
+	err := mockCmd.Execute()
+
+	// Assert
+	assert.EqualError(t, err, "refusing to scrape 3 mods, more than the cap of 2 mods per run: raise it with --max-mods or confirm with --yes")
 }
 
 func TestScrapeMod_WithMockedFunctions(t *testing.T) {
@@ -147,8 +412,1175 @@ func TestScrapeMod_WithMockedFunctions(t *testing.T) {
 	}
 
 	// Act
-	err = scrapeMod(sc, mockFetchModInfoConcurrent, mockFetchDocument)
+	_, err = scrapeMod(context.Background(), sc, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+}
+
+func TestScrapeMod_ShadowCompare(t *testing.T) {
+	tests := []struct {
+		name     string
+		shadow   types.ModInfo
+		err      error
+		expected []string
+	}{
+		{
+			name:     "agreeing backends",
+			shadow:   types.ModInfo{Name: "Mocked Mod", Version: "1.0"},
+			expected: []string{"The graphql backend agrees on every compared field"},
+		},
+		{
+			name:     "disagreeing backends",
+			shadow:   types.ModInfo{Name: "Mocked Mod", Version: "1.1"},
+			expected: []string{"The graphql backend disagrees on 1 fields, html against graphql:", `  Version: "1.0" against "1.1"`},
+		},
+		{
+			name:     "failing shadow backend",
+			err:      errors.New("graphql query failed: boom"),
+			expected: []string{"Error fetching the mod with the graphql backend: graphql query failed: boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			var out bytes.Buffer
+			defer func(output io.Writer) { formatters.StatusOutput = output }(formatters.StatusOutput)
+			formatters.StatusOutput = &out
+			fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+				return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", Version: "1.0"}}, nil
+			}
+			shadow := &fetchers.Shadow{
+				Backend: fetchers.BackendGraphQL,
+				FetchModInfo: func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+					return types.Results{Mods: tt.shadow}, tt.err
+				},
+			}
+			ctx := fetchers.WithShadow(context.Background(), shadow)
+			sc := types.CliFlags{BaseUrl: "https://somesite.com", GameName: "game", ModID: 1}
+
+			// Act
+			mod, err := scrapeMod(ctx, sc, fetchModInfo, mockFetchDocument)
+
+			// Assert
+			require.NoError(t, err, "the shadow backend never fails the mod")
+			assert.Equal(t, "1.0", mod.Version)
+			for _, line := range tt.expected {
+				assert.Contains(t, out.String(), line)
+			}
+		})
+	}
+}
+
+func TestScrapeMods_ContinuesAfterFailure(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		OutputDirectory: tempDir,
+	}
+
+	var scraped []int64
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped = append(scraped, modId.Int64())
+		if modId == 2 {
+			return types.Results{}, errors.New("boom")
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err = scrapeMods(context.Background(), sc, []int64{1, 2, 3}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.EqualError(t, err, "1 of 3 mods failed to scrape")
+	assert.Equal(t, []int64{1, 2, 3}, scraped)
+}
+
+func TestScrapeMods_InvalidModID(t *testing.T) {
+	// Arrange
+	var scraped []int64
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped = append(scraped, modId.Int64())
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), types.CliFlags{GameName: "game"}, []int64{1, -2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.EqualError(t, err, "invalid mod id -2: must be a positive number")
+	assert.Empty(t, scraped)
+}
+
+func TestRun_InvalidGame(t *testing.T) {
+	// Arrange
+	mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+	config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+	mockCmd.SetArgs([]string{"../game", "1234", "--display-results"})
+
+	// Act
+	err := mockCmd.Execute()
+
+	// Assert
+	assert.EqualError(t, err, `invalid game "../game": only letters, digits, - and _ are allowed`)
+}
+
+func TestRun_InvalidBackend(t *testing.T) {
+	// Arrange
+	mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+	config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+	mockCmd.SetArgs([]string{"game", "1234", "--display-results", "--backend", "rest"})
+
+	// Act
+	err := mockCmd.Execute()
+
+	// Assert
+	assert.EqualError(t, err, `unsupported backend "rest", expected html or graphql`)
+}
+
+func TestPreflightCookies(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		session  fetchers.Session
+		checkErr error
+		exitCode int
+		err      string
+	}{
+		{name: "signed in", header: "nexusmods_session=abc; nexusmods_session_refresh=def", session: fetchers.Session{LoggedIn: true, Username: "Arthmoor"}},
+		{name: "missing cookie", header: "nexusmods_session=abc", exitCode: failures.ExitAuth, err: "the session cookies nexusmods_session_refresh are missing from --cookie-header, run extract to save them"},
+		{name: "expired", header: "nexusmods_session=abc; nexusmods_session_refresh=def", exitCode: failures.ExitAuth, err: "the session cookies from --cookie-header no longer authenticate, they expired or were logged out: run extract or cookies refresh to renew them"},
+		{name: "site unreachable", header: "nexusmods_session=abc; nexusmods_session_refresh=def", checkErr: errors.New("connection refused"), exitCode: failures.ExitFailure, err: "error checking the session cookies, skip the check with --no-preflight: connection refused"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			original := checkSessionFunc
+			checkSessionFunc = func(baseUrl string, cookies map[string]string) (fetchers.Session, error) {
+				assert.Equal(t, "abc", cookies["nexusmods_session"])
+				return tt.session, tt.checkErr
+			}
+			defer func() { checkSessionFunc = original }()
+			sc := types.CliFlags{BaseUrl: "https://www.nexusmods.com", CookieHeader: tt.header, CookieStore: storage.CookieStoreFile, ValidCookies: []string{"nexusmods_session", "nexusmods_session_refresh"}}
+
+			// Act
+			err := preflightCookies(sc)
+
+			// Assert
+			assert.Equal(t, tt.exitCode, failures.ExitCode(err))
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestScrapeMods_WritesErrorReport(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	reportPath := filepath.Join(tempDir, "errors.json")
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		ErrorReport:     reportPath,
+		GameName:        "game",
+		RunID:           "run-1",
+	}
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if modId == 2 {
+			return types.Results{}, fetchers.ErrAdultContent
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err = scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.Equal(t, failures.ExitPartial, failures.ExitCode(err))
+	data, readErr := os.ReadFile(reportPath)
+	require.NoError(t, readErr)
+	var report failures.Report
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, failures.ExitPartial, report.ExitCode)
+	assert.Equal(t, failures.KindPartial, report.Kind)
+	assert.Equal(t, 1, report.Succeeded)
+	assert.Equal(t, []types.FailedMod{{ModID: 2, Error: fetchers.ErrAdultContent.Error(), Kind: failures.KindAuth}}, report.Failed)
+}
+
+func TestScrapeMods_UpdatesSummary(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		OutputDirectory: tempDir,
+		RunID:           "run-1",
+		SaveResults:     true,
+		SummaryMarkdown: true,
+	}
+
+	failing := int64(2)
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if modId.Int64() == failing {
+			return types.Results{}, errors.New("boom")
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LatestVersion: "1.0"}}, nil
+	}
+
+	// Act
+	firstErr := scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+	failing = 0
+	sc.RunID = "run-2"
+	secondErr := scrapeMods(context.Background(), sc, []int64{2, 3}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.EqualError(t, firstErr, "1 of 2 mods failed to scrape")
+	assert.NoError(t, secondErr)
+
+	index, err := summary.Load(filepath.Join(tempDir, "game", summary.Filename))
+	require.NoError(t, err)
+	assert.Equal(t, "game", index.Game)
+	assert.Equal(t, "run-2", index.RunID)
+	assert.Empty(t, index.Failed)
+	require.Len(t, index.Mods, 3)
+	assert.Equal(t, "mocked mod 1.json", index.Mods[0].File)
+	assert.Equal(t, "run-1", index.Mods[0].RunID)
+	assert.Equal(t, "1.0", index.Mods[0].Version)
+	assert.Equal(t, "run-2", index.Mods[1].RunID)
+	assert.FileExists(t, filepath.Join(tempDir, "game", summary.MarkdownFilename))
+}
+
+func TestScrapeMod_SnapshotKeepsLast(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	gameDir := filepath.Join(tempDir, "game")
+	require.NoError(t, os.MkdirAll(gameDir, os.ModePerm))
+	for _, name := range []string{"mocked mod 1 2024-05-01T12-00.json", "mocked mod 1 2024-05-02T12-00.json"} {
+		require.NoError(t, os.WriteFile(filepath.Join(gameDir, name), []byte("{}"), 0644))
+	}
+
+	checked := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LastChecked: checked}}, nil
+	}
+	sc := types.CliFlags{BaseUrl: "https://somesite.com", GameName: "game", ModID: 1, OutputDirectory: tempDir, SaveResults: true, Snapshot: true, KeepLast: 2}
+
+	// Act
+	_, err := scrapeMod(context.Background(), sc, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	entries, err := os.ReadDir(gameDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{"mocked mod 1 2024-05-02T12-00.json", "mocked mod 1 2024-06-01T12-00.json"}, names)
+}
+
+func TestScrapeMod_WritesModManagerMeta(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	checked := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LastChecked: checked, Version: "1.2"}}, nil
+	}
+	sc := types.CliFlags{BaseUrl: "https://somesite.com", Format: exporters.FormatMO2, GameName: "game", ModID: 1, OutputDirectory: tempDir, SaveResults: true, Snapshot: true}
+
+	// Act
+	_, err := scrapeMod(context.Background(), sc, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tempDir, "game", "mocked mod 1 2024-06-01T12-00.json"))
+	meta, err := os.ReadFile(filepath.Join(tempDir, "game", "mocked mod 1", "meta.ini"))
+	require.NoError(t, err)
+	assert.Contains(t, string(meta), "modid=1\nversion=1.2\n")
+}
+
+func TestScrapeMod_IncludeMeta(t *testing.T) {
+	tests := []struct {
+		name        string
+		includeMeta bool
+	}{
+		{"with --include-meta", true},
+		{"without --include-meta", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			tempDir := t.TempDir()
+			fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+				return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+			}
+			sc := types.CliFlags{BaseUrl: "https://somesite.com", GameName: "game", IncludeMeta: tt.includeMeta, ModID: 1, OutputDirectory: tempDir, SaveResults: true}
+
+			// Act
+			_, err := scrapeMod(context.Background(), sc, fetchModInfo, mockFetchDocument)
+
+			// Assert
+			require.NoError(t, err)
+			data, err := os.ReadFile(filepath.Join(tempDir, "game", "mocked mod 1.json"))
+			require.NoError(t, err)
+			var saved types.Results
+			require.NoError(t, json.Unmarshal(data, &saved))
+			if !tt.includeMeta {
+				assert.Nil(t, saved.Meta)
+				assert.NotContains(t, string(data), "ScrapeMeta")
+				return
+			}
+			require.NotNil(t, saved.Meta)
+			assert.Equal(t, types.SchemaVersion, saved.Meta.SchemaVersion)
+			assert.Equal(t, "https://somesite.com/game/mods/1", saved.Meta.SourceUrl)
+			assert.NotEmpty(t, saved.Meta.ScraperVersion)
+			assert.NotEmpty(t, saved.Meta.Duration)
+			assert.WithinDuration(t, time.Now(), saved.Meta.ScrapedAt.Time, time.Minute)
+		})
+	}
+}
+
+func TestRun_UploadFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"requires save results", []string{"game", "1", "--display-results", "--upload", "s3://bucket"}, "--upload requires --save-results"},
+		{"unsupported destination", []string{"game", "1", "--save-results", "--upload", "sftp://host/path"}, `unsupported upload destination "sftp://host/path": sftp isn't supported, use s3:// or webdav(s)://`},
+		{"concurrency", []string{"game", "1", "--save-results", "--upload", "s3://bucket", "--upload-concurrency", "0"}, "--upload-concurrency must be at least 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+			config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+			mockCmd.SetArgs(tt.args)
+
+			// Act
+			err := mockCmd.Execute()
+
+			// Assert
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRun_DownloadImagesFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{"requires save results", []string{"game", "1", "--display-results", "--download-images"}, "--download-images requires --save-results"},
+		{"requires html backend", []string{"game", "1", "--save-results", "--download-images", "--backend", "graphql"}, "--download-images requires --backend html"},
+		{"concurrency", []string{"game", "1", "--save-results", "--download-images", "--image-concurrency", "0"}, "--image-concurrency must be at least 1"},
+		{"rate limit", []string{"game", "1", "--save-results", "--rate-limit", "-1s"}, "--rate-limit must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+			config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+			mockCmd.SetArgs(tt.args)
+
+			// Act
+			err := mockCmd.Execute()
+
+			// Assert
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestScrapeMods_DownloadImages(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	var (
+		mu       sync.Mutex
+		requests []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.URL.Path)
+		mu.Unlock()
+		fmt.Fprintf(w, "image %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	fetchDocument := func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		html := fmt.Sprintf(`<div id="sidebargallery"><ul><li class="thumb" data-src="%[1]s/1.png"></li><li class="thumb" data-src="%[1]s/2.jpg"></li></ul></div>`, server.URL)
+		return goquery.NewDocumentFromReader(strings.NewReader(html))
+	}
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if _, err := fetchDocument(ctx, fmt.Sprintf("%s/%s/mods/%d", baseUrl, game, modId)); err != nil {
+			return types.Results{}, err
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+	sc := types.CliFlags{
+		BaseUrl:          "https://somesite.com",
+		CookieHeader:     "a=b",
+		DownloadImages:   true,
+		GameName:         "game",
+		ImageConcurrency: 2,
+		OutputDirectory:  tempDir,
+		PathTemplate:     exporters.DefaultPathTemplate,
+		SaveResults:      true,
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1}, fetchModInfo, fetchDocument)
+	require.NoError(t, err)
+	// Scraping the mod again doesn't download its images again
+	err = scrapeMods(context.Background(), sc, []int64{1}, fetchModInfo, fetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"/1.png", "/2.jpg"}, requests)
+	manifest, err := images.LoadManifest(filepath.Join(tempDir, "game", "mocked mod 1.images"))
+	require.NoError(t, err)
+	require.Len(t, manifest.Images, 2)
+	assert.Equal(t, server.URL+"/1.png", manifest.Images[0].Url)
+	data, err := os.ReadFile(filepath.Join(tempDir, "game", "mocked mod 1.images", manifest.Images[1].File))
+	require.NoError(t, err)
+	assert.Equal(t, "image /2.jpg", string(data))
+}
+
+func TestScrapeMods_Upload(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	var (
+		mu      sync.Mutex
+		uploads = map[string]string{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method == http.MethodPut {
+			body, _ := io.ReadAll(r.Body)
+			uploads[r.URL.Path] = string(body)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+	sc := types.CliFlags{
+		BaseUrl:           "https://somesite.com",
+		CookieHeader:      "a=b",
+		Format:            exporters.FormatMO2,
+		GameName:          "game",
+		OutputDirectory:   tempDir,
+		PathTemplate:      exporters.DefaultPathTemplate,
+		SaveResults:       true,
+		Upload:            "webdav://" + strings.TrimPrefix(server.URL, "http://") + "/dav",
+		UploadConcurrency: 2,
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	var keys []string
+	for key := range uploads {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []string{
+		"/dav/game/mocked mod 1.json",
+		"/dav/game/mocked mod 1/meta.ini",
+		"/dav/game/mocked mod 2.json",
+		"/dav/game/mocked mod 2/meta.ini",
+		"/dav/game/summary.json",
+	}, keys)
+	saved, err := os.ReadFile(filepath.Join(tempDir, "game", "mocked mod 1.json"))
+	require.NoError(t, err)
+	assert.Equal(t, string(saved), uploads["/dav/game/mocked mod 1.json"])
+}
+
+func TestRun_FormatRequiresSaveResults(t *testing.T) {
+	// Arrange
+	mockCmd := &cobra.Command{Use: "scrape", RunE: run}
+	config.RegisterScrapeFlags(mockCmd, &types.CliFlags{})
+	mockCmd.SetArgs([]string{"game", "1234", "--display-results", "--format", "vortex"})
+
+	// Act
+	err := mockCmd.Execute()
+
+	// Assert
+	assert.EqualError(t, err, "--format vortex requires --save-results")
+}
+
+func TestScrapeMods_PathTemplate(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LatestVersion: "1.0"}}, nil
+	}
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieHeader:    "session=abc",
+		GameName:        "game",
+		OutputDirectory: tempDir,
+		PathTemplate:    "{game}/{modid}/{version}/{name}.json",
+		SaveResults:     true,
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tempDir, "game", "1", "1.0", "mocked mod.json"))
+	assert.FileExists(t, filepath.Join(tempDir, "game", "2", "1.0", "mocked mod.json"))
+	index, err := summary.Load(filepath.Join(tempDir, "game", summary.Filename))
+	require.NoError(t, err)
+	require.Len(t, index.Mods, 2)
+	assert.Equal(t, "1/1.0/mocked mod.json", index.Mods[0].File)
+}
+
+func TestScrapeMod_PerModTimeout(t *testing.T) {
+	// Arrange
+	sc := types.CliFlags{
+		BaseUrl:       "https://somesite.com",
+		GameName:      "game",
+		ModID:         1234,
+		PerModTimeout: 10 * time.Millisecond,
+	}
+
+	slowFetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		<-ctx.Done()
+		return types.Results{}, ctx.Err()
+	}
+
+	// Act
+	_, err := scrapeMod(context.Background(), sc, slowFetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.EqualError(t, err, "timed out after 10ms")
+}
+
+func TestScrapeMods_SkipsFilteredMods(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		FilterTags:      []string{"Gameplay"},
+		GameName:        "game",
+		OutputDirectory: tempDir,
+	}
+
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		mod := types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), Tags: []string{"Visuals"}}
+		if modId == 1 {
+			mod.Tags = []string{"gameplay"}
+		}
+		if filter != nil && !filter(mod) {
+			return types.Results{Mods: mod}, fetchers.ErrModFiltered
+		}
+		return types.Results{Mods: mod}, nil
+	}
+
+	// Act
+	err = scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
 
 	// Assert
 	assert.NoError(t, err)
 }
+
+func TestScrapeMods_SkipUnchanged(t *testing.T) {
+	// Arrange: mod 1 is saved with its current last update, mod 2 with an older one
+	tempDir := t.TempDir()
+	gameDir := filepath.Join(tempDir, "game")
+	require.NoError(t, os.MkdirAll(gameDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "mocked mod 1.json"), []byte(`{"Mods":{"LastUpdated":"01 June 2024"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "mocked mod 2.json"), []byte(`{"Mods":{"LastUpdated":"01 May 2024"}}`), 0644))
+
+	metricsFile := filepath.Join(tempDir, "nexus.prom")
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieHeader:    "session=abc",
+		GameName:        "game",
+		MetricsTextfile: metricsFile,
+		OutputDirectory: tempDir,
+		SaveResults:     true,
+		SkipUnchanged:   true,
+	}
+
+	var saved []int64
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		mod := types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LastUpdated: "01 June 2024"}
+		if filter != nil && !filter(mod) {
+			return types.Results{Mods: mod}, fetchers.ErrModFiltered
+		}
+		saved = append(saved, modId.Int64())
+		return types.Results{Mods: mod}, nil
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1, 2, 3}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2, 3}, saved)
+	content, err := os.ReadFile(metricsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `nexus_mods_scraper_mods_unchanged{game="game"} 1`)
+	assert.Contains(t, string(content), `nexus_mods_scraper_mods_succeeded{game="game"} 2`)
+}
+
+func TestSavedModUnchanged_Snapshot(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	gameDir := filepath.Join(tempDir, "game")
+	require.NoError(t, os.MkdirAll(gameDir, os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "mocked mod 1 2024-05-01T12-00.json"), []byte(`{"Mods":{"LastUpdated":"01 June 2024"}}`), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(gameDir, "mocked mod 1 2024-06-02T12-00.json"), []byte(`{"Mods":{"LastUpdated":"02 June 2024"}}`), 0644))
+	sc := types.CliFlags{GameName: "game", OutputDirectory: tempDir, Snapshot: true}
+	mod := types.ModInfo{Name: "Mocked Mod", ModID: 1, LastUpdated: "02 June 2024"}
+
+	// Act & Assert: the most recent snapshot is the one compared
+	assert.True(t, savedModUnchanged(sc, mod))
+	mod.LastUpdated = "01 June 2024"
+	assert.False(t, savedModUnchanged(sc, mod))
+	mod.LastUpdated = ""
+	assert.False(t, savedModUnchanged(sc, mod))
+}
+
+func TestScrapeMods_SkipsIgnoredMods(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	ignoreFile := filepath.Join(tempDir, ignore.DefaultFilename)
+	require.NoError(t, ignore.Save(ignoreFile, ignore.List{{Game: "game", ModID: 2, Reason: "broken"}, {Game: "other", ModID: 3}}))
+	metricsFile := filepath.Join(tempDir, "nexus.prom")
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieHeader:    "session=abc",
+		GameName:        "game",
+		IgnoreFile:      ignoreFile,
+		MetricsTextfile: metricsFile,
+	}
+
+	var scraped []int64
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped = append(scraped, modId.Int64())
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1, 2, 3}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 3}, scraped)
+	content, err := os.ReadFile(metricsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `nexus_mods_scraper_mods_skipped{game="game"} 1`)
+	assert.Contains(t, string(content), `nexus_mods_scraper_mods_total{game="game"} 3`)
+}
+
+func TestScrapeMods_FlagsUnavailableMods(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	historyFile := filepath.Join(tempDir, "history.jsonl")
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieHeader:    "session=abc",
+		GameName:        "game",
+		HistoryFile:     historyFile,
+		OutputDirectory: tempDir,
+		RecordHistory:   true,
+		RunID:           "run-1",
+		SaveResults:     true,
+	}
+
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if modId == 2 {
+			return types.Results{Mods: types.ModInfo{Name: "Gone Mod", ModID: modId.Int64(), Status: types.ModStatusHidden}}, nil
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LatestVersion: "1.0"}}, nil
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(filepath.Join(tempDir, "game", "gone mod 2.json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"Status": "hidden"`)
+
+	index, err := summary.Load(filepath.Join(tempDir, "game", summary.Filename))
+	require.NoError(t, err)
+	require.Len(t, index.Mods, 1)
+	assert.Equal(t, int64(1), index.Mods[0].ModID)
+
+	entries, err := history.Load(historyFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(1), entries[0].ModID)
+}
+
+func TestFormatSummary(t *testing.T) {
+	formatted := formatSummary(types.ScrapeSummary{
+		RunID:       "run-1",
+		Succeeded:   []int64{1},
+		Unavailable: []types.UnavailableMod{{ModID: 2, Status: types.ModStatusRemoved}},
+		Failed:      []types.FailedMod{{ModID: 3, Error: "boom"}},
+	})
+
+	assert.Equal(t, "Run run-1 scraped 1 of 3 mods successfully\n"+
+		"  modID 2 is no longer available: removed\n"+
+		"  modID 3 failed: boom\n", formatted)
+}
+
+func TestScrapeMods_ShareSummary(t *testing.T) {
+	// Arrange
+	var shared string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		shared = string(body)
+		io.WriteString(w, "https://paste.example.com/abc123\n")
+	}))
+	defer server.Close()
+	sc := types.CliFlags{
+		BaseUrl:       "https://somesite.com",
+		CookieHeader:  "session=s3cr3t",
+		GameName:      "game",
+		RunID:         "run-1",
+		ShareEndpoint: server.URL,
+		ShareSummary:  true,
+	}
+
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if modId == 2 {
+			return types.Results{}, errors.New("cookie session=s3cr3t refused")
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Equal(t, "Game: game\n"+
+		"Run run-1 scraped 1 of 2 mods successfully\n"+
+		"  modID 2 failed: cookie session=[redacted] refused\n", shared)
+}
+
+func TestIgnoredNote(t *testing.T) {
+	assert.Equal(t, "Skipped modID: 2 for game: skyrim, it is on the ignore list (broken)", ignoredNote(ignore.Entry{Game: "skyrim", ModID: 2, Reason: "broken"}))
+	assert.Equal(t, "Skipped modID: 2 for game: skyrim, it is on the ignore list", ignoredNote(ignore.Entry{Game: "skyrim", ModID: 2}))
+}
+
+func TestTagFilter(t *testing.T) {
+	// No tags means no filter
+	assert.Nil(t, tagFilter(nil))
+
+	filter := tagFilter([]string{"Gameplay", "immersion"})
+	assert.True(t, filter(types.ModInfo{Tags: []string{"gameplay", "Immersion", "Visuals"}}))
+	assert.False(t, filter(types.ModInfo{Tags: []string{"Gameplay"}}))
+}
+
+func TestCategoryFilter(t *testing.T) {
+	// No category means no filter
+	assert.Nil(t, categoryFilter(" "))
+
+	filter := categoryFilter("gameplay")
+	assert.True(t, filter(types.ModInfo{Category: "Gameplay"}))
+	assert.False(t, filter(types.ModInfo{Category: "Visuals"}))
+	assert.False(t, filter(types.ModInfo{}))
+}
+
+func TestAllFilters(t *testing.T) {
+	// No filters means no filter
+	assert.Nil(t, allFilters(nil, nil))
+
+	filter := allFilters(tagFilter([]string{"immersion"}), nil, categoryFilter("Gameplay"))
+	assert.True(t, filter(types.ModInfo{Category: "Gameplay", Tags: []string{"Immersion"}}))
+	assert.False(t, filter(types.ModInfo{Category: "Visuals", Tags: []string{"Immersion"}}))
+	assert.False(t, filter(types.ModInfo{Category: "Gameplay"}))
+}
+
+func TestScrapeMod_RecordsHistory(t *testing.T) {
+	// Arrange
+	historyFile := filepath.Join(t.TempDir(), "history.jsonl")
+	sc := types.CliFlags{
+		BaseUrl:       "https://somesite.com",
+		GameName:      "game",
+		ModID:         1234,
+		RecordHistory: true,
+		HistoryFile:   historyFile,
+		RunID:         "run-1",
+	}
+
+	// Act
+	_, err := scrapeMod(context.Background(), sc, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	entries, err := history.Load(historyFile)
+	assert.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "game", entries[0].Game)
+	assert.Equal(t, int64(1234), entries[0].ModID)
+	assert.Equal(t, "Mocked Mod", entries[0].Name)
+	assert.Equal(t, "run-1", entries[0].RunID)
+}
+
+func TestScrapeMods_WritesMetricsTextfile(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	metricsFile := filepath.Join(tempDir, "metrics", "nexus.prom")
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		GameName:        "game",
+		MetricsTextfile: metricsFile,
+	}
+
+	// Act
+	err = scrapeMods(context.Background(), sc, []int64{1, 2}, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	content, err := os.ReadFile(metricsFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `nexus_mods_scraper_mods_succeeded{game="game"} 2`)
+}
+
+func TestScrapeMods_NotifiesUpdatedModsDigest(t *testing.T) {
+	// Arrange
+	var messages []notifiers.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message notifiers.Message
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		messages = append(messages, message)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	historyFile := filepath.Join(tempDir, "history.jsonl")
+	err = history.Append(historyFile,
+		history.Entry{Game: "game", ModID: 1, Version: "1.0"},
+		history.Entry{Game: "game", ModID: 2, Version: "2.0"},
+	)
+	require.NoError(t, err)
+
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LatestVersion: "2.0"}}, nil
+	}
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		Digest:          true,
+		GameName:        "game",
+		HistoryFile:     historyFile,
+		NotifyWebhook:   server.URL,
+		RunID:           "run-1",
+	}
+
+	// Act
+	err = scrapeMods(context.Background(), sc, []int64{1, 2, 3}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	require.Len(t, messages, 1)
+	assert.Equal(t, "run-1", messages[0].RunID)
+	require.Len(t, messages[0].Updates, 1)
+	assert.Equal(t, int64(1), messages[0].Updates[0].ModID)
+	assert.Equal(t, "1.0", messages[0].Updates[0].PreviousVersion)
+	assert.Equal(t, "https://somesite.com/game/mods/1", messages[0].Updates[0].Url)
+	assert.Contains(t, messages[0].Text, "1 mod updated (game: 1)")
+}
+
+func TestScrapeMod_FiltersChangeLogLanguage(t *testing.T) {
+	// Arrange
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{ModID: modId.Int64(), ChangeLogs: []types.ChangeLog{
+			{Version: "1.1", Notes: []string{"Fixed the crash when opening the map", "Absturz beim Öffnen der Karte behoben"}},
+		}}}, nil
+	}
+	sc := types.CliFlags{BaseUrl: "https://somesite.com", GameName: "game", ModID: 1, AnnotateChangeLogLang: true, ChangeLogLang: "en"}
+
+	// Act
+	mod, err := scrapeMod(context.Background(), sc, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []types.ChangeLog{
+		{Version: "1.1", Notes: []string{"Fixed the crash when opening the map"}, NoteLanguages: []string{"en"}},
+	}, mod.ChangeLogs)
+}
+
+func TestScrapeMod_ArchivesHtml(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if _, err := fetchDocument(ctx, "https://somesite.com/game/mods/1"); err != nil {
+			return types.Results{}, err
+		}
+		if _, err := fetchDocument(ctx, "https://somesite.com/game/mods/1?tab=files"); err != nil {
+			return types.Results{}, err
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+	sc := types.CliFlags{ArchiveHtml: true, BaseUrl: "https://somesite.com", GameName: "game", ModID: 1, OutputDirectory: tempDir, SaveResults: true}
+
+	// Act
+	_, err := scrapeMod(context.Background(), sc, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(tempDir, "game", "mocked mod 1.json"))
+	page, err := htmlarchive.Read(filepath.Join(tempDir, "game", "mocked mod 1"+htmlarchive.PageSuffix))
+	require.NoError(t, err)
+	assert.Equal(t, "Mocked HTML content", page.Find("body").Text())
+	assert.FileExists(t, filepath.Join(tempDir, "game", "mocked mod 1"+htmlarchive.FilesSuffix))
+}
+
+func TestScrapeMods_EmitsResults(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- strings.Split(strings.TrimSpace(string(data)), "\n")
+	}()
+
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		Emit:            "tcp://" + listener.Addr().String(),
+		GameName:        "game",
+		RunID:           "run-1",
+	}
+
+	// Act
+	err = scrapeMods(context.Background(), sc, []int64{1, 2}, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	lines := <-received
+	require.Len(t, lines, 2)
+	var record emit.Record
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &record))
+	assert.Equal(t, "game", record.Game)
+	assert.Equal(t, int64(2), record.Mod.ModID)
+	assert.Equal(t, "run-1", record.RunID)
+}
+
+func TestScrapeMods_EmitUnreachable(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+	sc := types.CliFlags{
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		Emit:            "unix://" + filepath.Join(tempDir, "missing.sock"),
+		GameName:        "game",
+	}
+
+	// Act
+	err = scrapeMods(context.Background(), sc, []int64{1}, mockFetchModInfoConcurrent, mockFetchDocument)
+
+	// Assert
+	assert.Equal(t, failures.ExitNetwork, failures.ExitCode(err))
+}
+
+func TestScrapeMods_FinishesModInFlightOnShutdown(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	historyFile := filepath.Join(tempDir, "history.jsonl")
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieHeader:    "session=abc",
+		DrainTimeout:    time.Minute,
+		GameName:        "game",
+		HistoryFile:     historyFile,
+		OutputDirectory: tempDir,
+		RecordHistory:   true,
+		RunID:           "run-1",
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	var scraped []int64
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		// The shutdown arrives while the first mod is in flight
+		stop()
+		scraped = append(scraped, modId.Int64())
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LatestVersion: "1.0"}}, ctx.Err()
+	}
+
+	// Act
+	err := scrapeMods(ctx, sc, []int64{1, 2, 3}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1}, scraped)
+	entries, err := history.Load(historyFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, int64(1), entries[0].ModID)
+}
+
+func TestScrapeMods_DrainTimeoutCutsModInFlight(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	errorReport := filepath.Join(tempDir, "errors.json")
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieHeader:    "session=abc",
+		DrainTimeout:    10 * time.Millisecond,
+		ErrorReport:     errorReport,
+		GameName:        "game",
+		OutputDirectory: tempDir,
+		RunID:           "run-1",
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		stop()
+		<-ctx.Done()
+		return types.Results{}, ctx.Err()
+	}
+
+	// Act
+	err := scrapeMods(ctx, sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	data, err := os.ReadFile(errorReport)
+	require.NoError(t, err)
+	var report failures.Report
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, failures.ExitOK, report.ExitCode)
+	assert.Empty(t, report.Failed)
+}
+
+func TestDrainContext(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	drainCtx, stopDrain := drainContext(ctx, 20*time.Millisecond)
+	defer stopDrain()
+	immediateCtx, stopImmediate := drainContext(ctx, 0)
+	defer stopImmediate()
+
+	// Act
+	cancel()
+
+	// Assert
+	assert.NoError(t, drainCtx.Err())
+	<-immediateCtx.Done()
+	select {
+	case <-drainCtx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("drain context wasn't cancelled after the drain timeout")
+	}
+}
+
+func TestScrapeMods_ProgressEvents(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	progress.SetOutput(&out)
+	defer progress.SetOutput(nil)
+
+	sc := types.CliFlags{BaseUrl: "https://somesite.com", CookieHeader: "session=abc", GameName: "game", RunID: "run-1"}
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if modId == 2 {
+			return types.Results{}, fmt.Errorf("boom")
+		}
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err := scrapeMods(context.Background(), sc, []int64{1, 2}, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.Error(t, err)
+	var events []progress.Event
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var event progress.Event
+		require.NoError(t, json.Unmarshal([]byte(line), &event))
+		event.ElapsedMs = 0
+		events = append(events, event)
+	}
+	assert.Equal(t, []progress.Event{
+		{Event: progress.EventRunStart, Game: "game", RunID: "run-1", Total: 2},
+		{Event: progress.EventModStart, ID: 1},
+		{Event: progress.EventModDone, ID: 1, Outcome: progress.OutcomeSucceeded},
+		{Event: progress.EventModStart, ID: 2},
+		{Event: progress.EventModDone, ID: 2, Outcome: progress.OutcomeFailed, Error: "boom"},
+		{Event: progress.EventRunDone, Failed: 1, RunID: "run-1", Succeeded: 1, Total: 2},
+	}, events)
+}
+
+func TestDetectUpdate_RequirementChanges(t *testing.T) {
+	// Arrange
+	previous := history.Latest([]history.Entry{history.NewEntry("skyrim", types.ModInfo{ModID: 1, LatestVersion: "1.0", Dependencies: []types.Requirement{{Name: "SkyUI"}}})})
+	mod := types.ModInfo{ModID: 1, Name: "Patch", LatestVersion: "1.0", Dependencies: []types.Requirement{{Name: "SKSE64"}}}
+
+	// Act
+	update, ok := detectUpdate(previous, "https://nexusmods.com", "skyrim", mod)
+
+	// Assert
+	require.True(t, ok)
+	assert.Equal(t, []string{"SKSE64"}, update.AddedRequirements)
+	assert.Equal(t, []string{"SkyUI"}, update.RemovedRequirements)
+	assert.Equal(t, "⚠ Patch (skyrim/1): new requirements SKSE64, dropped requirements SkyUI", requirementsWarning(update))
+}
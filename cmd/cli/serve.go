@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/server"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// serveCmd is a Cobra command used for serving the scraper over HTTP.
+	serveCmd = &cobra.Command{}
+	// serveOptions holds the command-line flag values of the serve command.
+	serveOptions = config.Serve{}
+)
+
+// init initializes the serve command with usage, description, and argument validation.
+// It registers the serve flags and adds the command to the root command.
+func init() {
+	serveCmd = &cobra.Command{
+		Use:   "serve [flags]",
+		Short: "Serve the scraper over HTTP",
+		Long:  "Start an HTTP server scraping mods on demand at /scrape/{game}/{modId} and reporting whether the session cookies work at /cookies/status, with the requests queued and rate limited",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sc, err := config.LoadServe(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(sc.BaseUrl, sc.CookieDirectory, sc.CookieFile, sc.CookieHeader)
+			if err != nil {
+				return failures.WithKind(err, failures.KindAuth)
+			}
+
+			listener, err := net.Listen("tcp", sc.Listen)
+			if err != nil {
+				return fmt.Errorf("error starting server: %w", err)
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			return serve(ctx, cmd.OutOrStdout(), sc, listener, client, fetchModInfoFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterServeFlags(serveCmd, &serveOptions)
+	RootCmd.AddCommand(serveCmd)
+}
+
+// serve answers the HTTP API on listener until ctx is done, then stops it gracefully,
+// giving the requests in flight the drain timeout to be answered. Scrapes and cookie
+// checks go through a single queue, running one at a time with at least the rate limit
+// between them. Returns an error if the queue settings are invalid or the server
+// fails.
+func serve(
+	ctx context.Context,
+	w io.Writer,
+	sc config.Serve,
+	listener net.Listener,
+	client *http.Client,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	if sc.RateLimit < 0 {
+		listener.Close()
+		return fmt.Errorf("--rate-limit must not be negative")
+	}
+	queue, err := server.NewQueue(sc.QueueSize, httpclient.NewLimiter(sc.RateLimit))
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	scrape := func(ctx context.Context, game types.GameSlug, modID types.ModID) (types.Results, error) {
+		if sc.PerModTimeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, sc.PerModTimeout)
+			defer cancel()
+		}
+		return fetchModInfoFunc(ctx, sc.BaseUrl, game, modID, nil, utils.ConcurrentFetch, fetchDocumentFunc)
+	}
+	cookieStatus := func(_ context.Context) (server.CookieStatus, error) {
+		return checkCookies(sc.BaseUrl, client, validateCookiesFunc)
+	}
+
+	httpServer := &http.Server{Handler: server.Handler(queue, scrape, cookieStatus), ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+
+	fmt.Fprintf(w, "Serving on http://%s, at most one request to Nexus Mods every %s\n", listener.Addr(), sc.RateLimit)
+
+	// The queue outlives ctx so that the requests in flight are answered on shutdown
+	queueCtx, stopQueue := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		queue.Run(queueCtx)
+		close(done)
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err = <-serveErr:
+	}
+
+	// The requests in flight are given the drain timeout to be answered, then cut short
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), sc.DrainTimeout)
+	defer shutdownCancel()
+	shutdownErr := httpServer.Shutdown(shutdownCtx)
+	stopQueue()
+	if errors.Is(shutdownErr, context.DeadlineExceeded) {
+		// The scrape in flight is abandoned rather than waited for
+		fmt.Fprintf(w, "Stopped before the requests in flight were answered, after waiting %s\n", sc.DrainTimeout)
+		httpServer.Close()
+	} else {
+		if shutdownErr != nil && err == nil {
+			err = shutdownErr
+		}
+		<-done
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// checkCookies reports the names of the cookies the client sends to baseUrl and
+// whether validateCookies finds that they authenticate. Returns an error if the check
+// can't be made.
+func checkCookies(
+	baseUrl string,
+	client *http.Client,
+	validateCookies func(baseUrl string, cookies map[string]string) (bool, error),
+) (server.CookieStatus, error) {
+	status := server.CookieStatus{Cookies: []string{}}
+	cookies := map[string]string{}
+	if u, err := url.Parse(baseUrl); err == nil && client.Jar != nil {
+		for _, cookie := range client.Jar.Cookies(u) {
+			cookies[cookie.Name] = cookie.Value
+			status.Cookies = append(status.Cookies, cookie.Name)
+		}
+	}
+	sort.Strings(status.Cookies)
+
+	valid, err := validateCookies(baseUrl, cookies)
+	if err != nil {
+		return server.CookieStatus{}, fmt.Errorf("error checking cookies: %w", err)
+	}
+	status.Valid = valid
+	return status, nil
+}
@@ -0,0 +1,127 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	// Arrange
+	sc := config.Serve{BaseUrl: "https://somesite.com", QueueSize: 4}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	client, err := httpclient.NewClient(sc.BaseUrl, "", "", "")
+	require.NoError(t, err)
+
+	mockFetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{ModID: modId.Int64(), Name: "Mod " + game.String()}}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	// Act
+	go func() {
+		done <- serve(ctx, io.Discard, sc, listener, client, mockFetchModInfo, nil)
+	}()
+	resp, err := http.Get("http://" + listener.Addr().String() + "/scrape/skyrim/42")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	cancel()
+
+	// Assert
+	assert.NoError(t, <-done)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Contains(t, string(body), `"ModID":42`)
+	assert.Contains(t, string(body), `"Name":"Mod skyrim"`)
+}
+
+func TestServe_InvalidQueueSize(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	err = serve(context.Background(), io.Discard, config.Serve{}, listener, &http.Client{}, nil, nil)
+
+	assert.EqualError(t, err, "queue size must be at least 1, got 0")
+}
+
+func TestServe_NegativeRateLimit(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	err = serve(context.Background(), io.Discard, config.Serve{QueueSize: 1, RateLimit: -time.Second}, listener, &http.Client{}, nil, nil)
+
+	assert.EqualError(t, err, "--rate-limit must not be negative")
+}
+
+func TestCheckCookies(t *testing.T) {
+	// Arrange
+	client, err := httpclient.NewClient("https://somesite.com", "", "", "session=abc; refresh=def")
+	require.NoError(t, err)
+	var validated map[string]string
+	validate := func(baseUrl string, cookies map[string]string) (bool, error) {
+		validated = cookies
+		return true, nil
+	}
+	failing := func(baseUrl string, cookies map[string]string) (bool, error) {
+		return false, errors.New("connection refused")
+	}
+
+	// Act
+	status, err := checkCookies("https://somesite.com", client, validate)
+	_, failErr := checkCookies("https://somesite.com", client, failing)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"refresh", "session"}, status.Cookies)
+	assert.True(t, status.Valid)
+	assert.Equal(t, map[string]string{"refresh": "def", "session": "abc"}, validated)
+	assert.EqualError(t, failErr, "error checking cookies: connection refused")
+}
+
+func TestServe_DrainTimeout(t *testing.T) {
+	// Arrange
+	sc := config.Serve{BaseUrl: "https://somesite.com", DrainTimeout: 20 * time.Millisecond, QueueSize: 4}
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	client, err := httpclient.NewClient(sc.BaseUrl, "", "", "")
+	require.NoError(t, err)
+
+	started, release := make(chan struct{}), make(chan struct{})
+	defer close(release)
+	slowFetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		close(started)
+		<-release
+		return types.Results{}, nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	var out bytes.Buffer
+
+	// Act
+	go func() {
+		done <- serve(ctx, &out, sc, listener, client, slowFetchModInfo, nil)
+	}()
+	go http.Get("http://" + listener.Addr().String() + "/scrape/skyrim/42")
+	<-started
+	cancel()
+
+	// Assert
+	assert.NoError(t, <-done)
+	assert.Contains(t, out.String(), "Stopped before the requests in flight were answered, after waiting 20ms")
+}
@@ -0,0 +1,296 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/store"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/charts"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+
+	"github.com/spf13/cobra"
+)
+
+// The formats the stats command writes the trend of a mod in.
+const (
+	trendFormatCsv       = "csv"
+	trendFormatSparkline = "sparkline"
+)
+
+var (
+	// statsCmd is the parent Cobra command for the statistics related subcommands.
+	statsCmd = &cobra.Command{}
+	// statsChartCmd is a Cobra command that renders terminal charts from the history journal.
+	statsChartCmd = &cobra.Command{}
+	// statsShowCmd is a Cobra command that shows the usage recorded with --usage-stats.
+	statsShowCmd = &cobra.Command{}
+	// statsOptions holds the command-line flag values for the stats subcommands.
+	statsOptions = struct {
+		Days          int
+		Format        string
+		Game          string
+		HistoryFile   string
+		ModID         int
+		StorageDriver string
+		UsageFile     string
+		Weeks         int
+		Width         int
+	}{}
+)
+
+// init initializes the stats command and its chart and show subcommands, registering
+// their flags and adding them to the root command.
+func init() {
+	statsCmd = &cobra.Command{
+		Use:   "stats [<game name> <mod id>] [flags]",
+		Short: "Statistics from the scrape history and usage",
+		Long:  "Show statistics built from the history journal recorded with scrape --record-history, or the usage recorded with --usage-stats. Given a game and a mod id, chart the downloads and endorsements of the mod across its recordings as sparklines, or export them as CSV",
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) != 0 && len(args) != 2 {
+				return fmt.Errorf("accepts a game name and a mod id, received %d arg(s)", len(args))
+			}
+			return nil
+		},
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return cmd.Help()
+			}
+			game, err := types.ParseGameSlug(args[0])
+			if err != nil {
+				return err
+			}
+			modID, err := types.ParseModID(args[1])
+			if err != nil {
+				return err
+			}
+			return renderModTrend(cmd.OutOrStdout(), game, modID)
+		},
+	}
+
+	statsChartCmd = &cobra.Command{
+		Use:   "chart",
+		Short: "Chart update activity and download growth",
+		Long:  "Render terminal bar charts of mod updates per week and total download growth from the history journal",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return renderStatsCharts(cmd.OutOrStdout(), time.Now())
+		},
+	}
+
+	statsShowCmd = &cobra.Command{
+		Use:   "show",
+		Short: "Show the recorded usage",
+		Long:  "Show the cumulative usage recorded locally with --usage-stats: mods scraped, bytes fetched and cache hit rate, in total and per day",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return renderUsage(cmd.OutOrStdout())
+		},
+	}
+
+	initStatsTrendFlags(statsCmd)
+	initStatsChartFlags(statsChartCmd)
+	initStatsShowFlags(statsShowCmd)
+	statsCmd.AddCommand(statsChartCmd)
+	statsCmd.AddCommand(statsShowCmd)
+	RootCmd.AddCommand(statsCmd)
+}
+
+// initStatsChartFlags registers the command-line flags for the stats chart command,
+// including the history file location and storage driver, game and mod filters, and
+// chart dimensions.
+func initStatsChartFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "history-file", "", filepath.Join(storage.GetDataStoragePath(), history.DefaultFilename), "History journal to read", &statsOptions.HistoryFile)
+	cli.RegisterFlag(cmd, "game", "g", "", "Only include mods for this game", &statsOptions.Game)
+	cli.RegisterFlag(cmd, "mod-id", "m", 0, "Only include this mod id", &statsOptions.ModID)
+	config.RegisterStorageDriverFlag(cmd, &statsOptions.StorageDriver)
+	cli.RegisterFlag(cmd, "weeks", "w", 12, "Number of weeks of update activity to chart", &statsOptions.Weeks)
+	cli.RegisterFlag(cmd, "width", "", 40, "Width of the longest bar in characters", &statsOptions.Width)
+}
+
+// initStatsTrendFlags registers the command-line flags for the stats command charting
+// a mod, including the history file location and storage driver, the output format,
+// and the sparkline width.
+func initStatsTrendFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "format", "", trendFormatSparkline, "Output format: sparkline, or csv to export the recordings", &statsOptions.Format)
+	cli.RegisterFlag(cmd, "history-file", "", filepath.Join(storage.GetDataStoragePath(), history.DefaultFilename), "History journal to read", &statsOptions.HistoryFile)
+	config.RegisterStorageDriverFlag(cmd, &statsOptions.StorageDriver)
+	cli.RegisterFlag(cmd, "width", "", 40, "Number of most recent recordings charted by the sparklines", &statsOptions.Width)
+}
+
+// initStatsShowFlags registers the command-line flags for the stats show command,
+// including the usage stats file location and the number of days listed.
+func initStatsShowFlags(cmd *cobra.Command) {
+	cli.RegisterFlag(cmd, "days", "", 14, "Number of most recent days with activity to list", &statsOptions.Days)
+	cli.RegisterFlag(cmd, "usage-file", "", filepath.Join(storage.GetDataStoragePath(), usage.DefaultFilename), "Usage stats file to read", &statsOptions.UsageFile)
+}
+
+// renderStatsCharts loads the history journal, applies the game and mod filters, and
+// writes the updates per week and download growth charts to w. Returns an error if the
+// journal can't be read or the charts can't be written.
+func renderStatsCharts(w io.Writer, now time.Time) error {
+	entries, err := store.LoadHistory(statsOptions.StorageDriver, statsOptions.HistoryFile)
+	if err != nil {
+		return err
+	}
+
+	entries = history.Filter(entries, statsOptions.Game, int64(statsOptions.ModID))
+	if len(entries) == 0 {
+		return fmt.Errorf("no history recorded in %s, run scrape with --record-history first", statsOptions.HistoryFile)
+	}
+
+	updates := history.UpdatesPerWeek(entries, statsOptions.Weeks, now)
+	if err := charts.BarChart(w, "Updates per week", pointsToBars(updates, "week of 2006-01-02"), statsOptions.Width); err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w); err != nil {
+		return err
+	}
+
+	growth := history.DownloadsGrowth(entries)
+	return charts.BarChart(w, "Total downloads", pointsToBars(growth, "2006-01-02"), statsOptions.Width)
+}
+
+// renderModTrend loads the history journal and writes the downloads and endorsements
+// recorded for the mod to w, as sparklines with the first and last counts, or as CSV
+// with one row per recording. Returns an error if the format is unsupported, the
+// journal can't be read or holds no counts for the mod.
+func renderModTrend(w io.Writer, game types.GameSlug, modID types.ModID) error {
+	if statsOptions.Format != trendFormatSparkline && statsOptions.Format != trendFormatCsv {
+		return fmt.Errorf("unsupported format %q, expected sparkline or csv", statsOptions.Format)
+	}
+
+	entries, err := store.LoadHistory(statsOptions.StorageDriver, statsOptions.HistoryFile)
+	if err != nil {
+		return err
+	}
+
+	samples := history.ModTrend(entries, game.String(), modID.Int64())
+	if len(samples) == 0 {
+		return fmt.Errorf("no downloads or endorsements recorded for %s mod %d in %s, scrape it with --record-history first", game, modID, statsOptions.HistoryFile)
+	}
+
+	if statsOptions.Format == trendFormatCsv {
+		return writeTrendCsv(w, samples)
+	}
+
+	first, last := samples[0], samples[len(samples)-1]
+	fmt.Fprintf(w, "%s mod %d, %d %s from %s to %s\n", game, modID, len(samples), plural(int64(len(samples)), "recording", "recordings"),
+		first.Time.Format("2006-01-02"), last.Time.Format("2006-01-02"))
+
+	downloads := make([]float64, 0, len(samples))
+	endorsements := make([]float64, 0, len(samples))
+	for _, sample := range samples {
+		downloads = append(downloads, float64(sample.Downloads))
+		endorsements = append(endorsements, float64(sample.Endorsements))
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(table, "  Downloads\t%s\t%s\n", charts.Sparkline(downloads, statsOptions.Width), formatGrowth(first.Downloads, last.Downloads))
+	fmt.Fprintf(table, "  Endorsements\t%s\t%s\n", charts.Sparkline(endorsements, statsOptions.Width), formatGrowth(first.Endorsements, last.Endorsements))
+	return table.Flush()
+}
+
+// writeTrendCsv writes the samples to w as CSV, with a header row and one row per
+// recording holding its time in RFC 3339, the downloads and the endorsements.
+func writeTrendCsv(w io.Writer, samples []history.Sample) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"recorded_at", "downloads", "endorsements"}); err != nil {
+		return err
+	}
+	for _, sample := range samples {
+		record := []string{sample.Time.UTC().Format(time.RFC3339), strconv.FormatInt(sample.Downloads, 10), strconv.FormatInt(sample.Endorsements, 10)}
+		if err := writer.Write(record); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// formatGrowth formats the change from one count to another, e.g. "1,000 → 1,250 (+250)".
+func formatGrowth(from, to int64) string {
+	sign := "+"
+	delta := to - from
+	if delta < 0 {
+		sign, delta = "-", -delta
+	}
+	return fmt.Sprintf("%s → %s (%s%s)", formatters.FormatCount(from), formatters.FormatCount(to), sign, formatters.FormatCount(delta))
+}
+
+// pointsToBars converts a history time series into chart bars, labelling each bar
+// with its time formatted using layout.
+func pointsToBars(points []history.Point, layout string) []charts.Bar {
+	bars := make([]charts.Bar, 0, len(points))
+	for _, point := range points {
+		bars = append(bars, charts.Bar{Label: point.Time.Format(layout), Value: float64(point.Value)})
+	}
+	return bars
+}
+
+// renderUsage loads the usage stats file and writes the cumulative usage followed by a
+// table of the most recent days with activity to w. Returns an error if the file can't
+// be read or holds no usage.
+func renderUsage(w io.Writer) error {
+	stats, err := usage.Load(statsOptions.UsageFile)
+	if err != nil {
+		return err
+	}
+	if len(stats.Days) == 0 {
+		return fmt.Errorf("no usage recorded in %s, run commands with --usage-stats first", statsOptions.UsageFile)
+	}
+
+	total := stats.Total()
+	fmt.Fprintf(w, "Usage since %s (%d %s)\n", total.Date, total.Runs, plural(total.Runs, "run", "runs"))
+	fmt.Fprintf(w, "  Mods scraped:   %d (%d failed)\n", total.ModsScraped, total.ModsFailed)
+	fmt.Fprintf(w, "  Requests:       %d\n", total.Requests)
+	fmt.Fprintf(w, "  Bytes fetched:  %s\n", formatBytes(total.BytesFetched))
+	fmt.Fprintf(w, "  Cache hit rate: %.1f%%\n\n", total.CacheHitRate()*100)
+
+	days := stats.Days
+	if statsOptions.Days > 0 && len(days) > statsOptions.Days {
+		days = days[len(days)-statsOptions.Days:]
+	}
+
+	table := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "Date\tRuns\tMods\tFailed\tRequests\tFetched\tCache hits")
+	for _, day := range days {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%d\t%d\t%s\t%.1f%%\n", day.Date, day.Runs, day.ModsScraped, day.ModsFailed, day.Requests, formatBytes(day.BytesFetched), day.CacheHitRate()*100)
+	}
+	return table.Flush()
+}
+
+// formatBytes formats a byte count with a binary unit, e.g. "1.5 MiB".
+func formatBytes(bytes int64) string {
+	const unit = 1024
+	if bytes < unit {
+		return fmt.Sprintf("%d B", bytes)
+	}
+
+	value, exponent := float64(bytes)/unit, 0
+	for value >= unit && exponent < 4 {
+		value /= unit
+		exponent++
+	}
+	return fmt.Sprintf("%.1f %ciB", value, "KMGTP"[exponent])
+}
+
+// plural returns singular when n is 1 and plural otherwise.
+func plural(n int64, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,194 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/store"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderStatsCharts(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, time.June, 12, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), history.DefaultFilename)
+	require.NoError(t, history.Append(path,
+		history.Entry{Game: "skyrim", ModID: 1, TotalDLs: "100", RecordedAt: now, LastUpdatedAt: types.NewTimestamp(now)},
+		history.Entry{Game: "fallout4", ModID: 2, TotalDLs: "900", RecordedAt: now},
+	))
+	statsOptions.HistoryFile = path
+	statsOptions.Game = "skyrim"
+	statsOptions.ModID = 0
+	statsOptions.Weeks = 2
+	statsOptions.Width = 10
+
+	var buf bytes.Buffer
+
+	// Act
+	err := renderStatsCharts(&buf, now)
+
+	// Assert
+	assert.NoError(t, err)
+	expected := "Updates per week\n" +
+		"  week of 2024-06-03 │ 0\n" +
+		"  week of 2024-06-10 │██████████ 1\n" +
+		"\n" +
+		"Total downloads\n" +
+		"  2024-06-12 │██████████ 100\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRenderStatsCharts_NoHistory(t *testing.T) {
+	statsOptions.HistoryFile = filepath.Join(t.TempDir(), "missing.jsonl")
+	statsOptions.Game = ""
+	statsOptions.ModID = 0
+
+	err := renderStatsCharts(&bytes.Buffer{}, time.Now())
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "run scrape with --record-history first")
+}
+
+func TestRenderStatsCharts_SQLite(t *testing.T) {
+	// Arrange
+	now := time.Date(2024, time.June, 12, 12, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), "nexus.db")
+	require.NoError(t, store.AppendHistory(store.SQLiteDriverName, path,
+		history.Entry{Game: "skyrim", ModID: 1, TotalDLs: "100", RecordedAt: now, LastUpdatedAt: types.NewTimestamp(now)},
+	))
+	statsOptions.HistoryFile = path
+	statsOptions.StorageDriver = store.SQLiteDriverName
+	defer func() { statsOptions.StorageDriver = "" }()
+	statsOptions.Game = "skyrim"
+	statsOptions.ModID = 0
+	statsOptions.Weeks = 1
+	statsOptions.Width = 10
+
+	var buf bytes.Buffer
+
+	// Act
+	err := renderStatsCharts(&buf, now)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "  week of 2024-06-10 │██████████ 1\n")
+}
+
+func TestRenderModTrend(t *testing.T) {
+	// Arrange
+	day1 := time.Date(2024, time.June, 1, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, time.June, 2, 10, 0, 0, 0, time.UTC)
+	path := filepath.Join(t.TempDir(), history.DefaultFilename)
+	require.NoError(t, history.Append(path,
+		history.Entry{Game: "skyrim", ModID: 1, TotalDLs: "1,000", Endorsements: "10", RecordedAt: day1},
+		history.Entry{Game: "skyrim", ModID: 1, TotalDLs: "1,250", Endorsements: "12", RecordedAt: day2},
+	))
+	statsOptions.HistoryFile = path
+	statsOptions.StorageDriver = ""
+	statsOptions.Width = 40
+	defer func() { statsOptions.Format = trendFormatSparkline }()
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{trendFormatSparkline, "skyrim mod 1, 2 recordings from 2024-06-01 to 2024-06-02\n" +
+			"  Downloads     ▁█  1,000 → 1,250 (+250)\n" +
+			"  Endorsements  ▁█  10 → 12 (+2)\n"},
+		{trendFormatCsv, "recorded_at,downloads,endorsements\n" +
+			"2024-06-01T10:00:00Z,1000,10\n" +
+			"2024-06-02T10:00:00Z,1250,12\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			statsOptions.Format = tt.format
+			var buf bytes.Buffer
+
+			// Act
+			err := renderModTrend(&buf, "skyrim", 1)
+
+			// Assert
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, buf.String())
+		})
+	}
+}
+
+func TestRenderModTrend_Errors(t *testing.T) {
+	statsOptions.HistoryFile = filepath.Join(t.TempDir(), "missing.jsonl")
+	statsOptions.StorageDriver = ""
+	defer func() { statsOptions.Format = trendFormatSparkline }()
+
+	statsOptions.Format = "png"
+	formatErr := renderModTrend(&bytes.Buffer{}, "skyrim", 1)
+	statsOptions.Format = trendFormatSparkline
+	missingErr := renderModTrend(&bytes.Buffer{}, "skyrim", 1)
+
+	assert.EqualError(t, formatErr, `unsupported format "png", expected sparkline or csv`)
+	assert.ErrorContains(t, missingErr, "scrape it with --record-history first")
+}
+
+func TestRenderUsage(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	path := filepath.Join("data", usage.DefaultFilename)
+	require.NoError(t, usage.Save(path, usage.Stats{Days: []usage.Day{
+		{Date: "2024-06-11", Runs: 1, Counters: usage.Counters{BytesFetched: 512, ModsScraped: 1, Requests: 2}},
+		{Date: "2024-06-12", Runs: 2, Counters: usage.Counters{BytesFetched: 1536, CacheHits: 2, ModsFailed: 1, ModsScraped: 2, Requests: 2}},
+	}}))
+	statsOptions.UsageFile = path
+	statsOptions.Days = 1
+	var buf bytes.Buffer
+
+	// Act
+	err := renderUsage(&buf)
+
+	// Assert
+	require.NoError(t, err)
+	expected := "Usage since 2024-06-11 (3 runs)\n" +
+		"  Mods scraped:   3 (1 failed)\n" +
+		"  Requests:       4\n" +
+		"  Bytes fetched:  2.0 KiB\n" +
+		"  Cache hit rate: 33.3%\n" +
+		"\n" +
+		"Date        Runs  Mods  Failed  Requests  Fetched  Cache hits\n" +
+		"2024-06-12  2     2     1       2         1.5 KiB  50.0%\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestRenderUsage_NoUsage(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+	statsOptions.UsageFile = filepath.Join("data", usage.DefaultFilename)
+
+	err := renderUsage(&bytes.Buffer{})
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "run commands with --usage-stats first")
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		bytes    int64
+		expected string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, formatBytes(tt.bytes))
+		})
+	}
+}
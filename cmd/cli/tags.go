@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// tagsCmd is a Cobra command used for scraping a game's tag taxonomy.
+	tagsCmd = &cobra.Command{}
+	// tagsOptions holds the command-line flag values of the tags command.
+	tagsOptions = config.Tags{}
+	// fetchGameTagsFunc is a variable that holds a reference to the function used for
+	// fetching a game's categories and tags.
+	fetchGameTagsFunc = fetchers.FetchGameTags
+)
+
+// init initializes the tags command with usage, description, and argument validation.
+// It registers the tags flags and adds the command to the root command.
+func init() {
+	tagsCmd = &cobra.Command{
+		Use:               "tags <game name> [flags]",
+		Short:             "Scrape a game's tag taxonomy",
+		Long:              "Scrape the categories and tags of a game, with the number of mods in each where available, and return a JSON output",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			tc, err := config.LoadTags(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(tc.BaseUrl, tc.CookieDirectory, tc.CookieFile, tc.CookieHeader)
+			if err != nil {
+				return err
+			}
+
+			return scrapeTags(tc, args[0], fetchGameTagsFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterTagsFlags(tagsCmd, &tagsOptions)
+	RootCmd.AddCommand(tagsCmd)
+}
+
+// scrapeTags scrapes the taxonomy of the game, displays the GameTags as JSON and, when
+// requested, saves it as tags.json in the game's output directory. Returns an error if
+// the pages can't be scraped or the results can't be saved.
+func scrapeTags(
+	tc config.Tags,
+	game string,
+	fetchGameTagsFunc func(ctx context.Context, baseUrl, game string, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameTags, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	game = strings.ToLower(game)
+
+	tags, err := fetchGameTagsFunc(context.Background(), tc.BaseUrl, game, fetchDocumentFunc)
+	if err != nil {
+		return fmt.Errorf("error scraping tags of game %s: %w", game, err)
+	}
+
+	jsonData, err := json.MarshalIndent(tags, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tags: %w", err)
+	}
+	if err := formatters.PrintPrettyJson(string(jsonData)); err != nil {
+		return err
+	}
+
+	if tc.SaveResults {
+		outputGameDirectory := filepath.Join(tc.OutputDirectory, game)
+		savedPath, err := exporters.SaveModInfoToJson(types.CliFlags{}, tags, outputGameDirectory, "tags", utils.EnsureDirExists)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(formatters.Status(), "Tags saved to %s\n", formatters.PathLink(savedPath))
+	}
+
+	return nil
+}
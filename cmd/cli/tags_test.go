@@ -0,0 +1,48 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeTags_SavesResults(t *testing.T) {
+	// Arrange
+	outputDir := t.TempDir()
+	tc := config.Tags{BaseUrl: "https://example.com", OutputDirectory: outputDir, SaveResults: true}
+	fetchGameTags := func(_ context.Context, baseUrl, game string, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameTags, error) {
+		return types.GameTags{Game: game, Categories: []types.TaxonomyEntry{{Count: 12, Name: "Armour"}}}, nil
+	}
+
+	// Act
+	err := scrapeTags(tc, "Skyrim", fetchGameTags, nil)
+
+	// Assert
+	require.NoError(t, err)
+	content, err := os.ReadFile(filepath.Join(outputDir, "skyrim", "tags.json"))
+	require.NoError(t, err)
+
+	var saved types.GameTags
+	require.NoError(t, json.Unmarshal(content, &saved))
+	assert.Equal(t, "skyrim", saved.Game)
+	assert.Equal(t, []types.TaxonomyEntry{{Count: 12, Name: "Armour"}}, saved.Categories)
+}
+
+func TestScrapeTags_FetchError(t *testing.T) {
+	fetchGameTags := func(_ context.Context, baseUrl, game string, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameTags, error) {
+		return types.GameTags{}, errors.New("boom")
+	}
+
+	err := scrapeTags(config.Tags{}, "skyrim", fetchGameTags, nil)
+
+	assert.EqualError(t, err, "error scraping tags of game skyrim: boom")
+}
@@ -0,0 +1,132 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selfupdate"
+	"github.com/spf13/cobra"
+	"go.szostok.io/version"
+)
+
+var (
+	// updateCmd is a Cobra command used for installing the latest release.
+	updateCmd = &cobra.Command{}
+	// updateOptions holds the command-line flag values of the update command.
+	updateOptions = config.Update{}
+)
+
+// init initializes the update command with usage, description, and argument
+// validation. It registers the update flags and adds the command to the root command.
+func init() {
+	updateCmd = &cobra.Command{
+		Use:   "update [flags]",
+		Short: "Update to the latest release",
+		Long:  "Check GitHub for a newer release, download the archive of the current platform, verify its checksum and replace the running executable with it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			uc, err := config.LoadUpdate(cmd)
+			if err != nil {
+				return err
+			}
+
+			exePath, err := os.Executable()
+			if err != nil {
+				return fmt.Errorf("error locating the running executable: %w", err)
+			}
+			if resolved, err := filepath.EvalSymlinks(exePath); err == nil {
+				exePath = resolved
+			}
+
+			updater := selfupdate.Updater{
+				ApiUrl: uc.ApiUrl,
+				Client: &http.Client{Timeout: httpclient.Timeout(), Transport: httpclient.Transport()},
+				Owner:  RepoOwner,
+				Repo:   RepoName,
+			}
+			return runUpdate(context.Background(), cmd.OutOrStdout(), uc, version.Get().Version, exePath, runtime.GOOS, runtime.GOARCH, updater)
+		},
+	}
+
+	config.RegisterUpdateFlags(updateCmd, &updateOptions)
+	RootCmd.AddCommand(updateCmd)
+}
+
+// runUpdate looks up the latest release and, when it is newer than the current version
+// or --force is given, downloads the archive of the goos/goarch platform, verifies it
+// against the checksums of the release and replaces the executable at exePath with
+// the binary it holds. With --check it only reports whether a newer release is
+// available. Returns an error if the release can't be looked up, has no archive for
+// the platform, fails the checksum or the executable can't be replaced.
+func runUpdate(ctx context.Context, w io.Writer, uc config.Update, current, exePath, goos, goarch string, updater selfupdate.Updater) error {
+	release, err := updater.Latest(ctx)
+	if err != nil {
+		return err
+	}
+
+	newer, err := selfupdate.Newer(current, release.TagName)
+	switch {
+	case errors.Is(err, selfupdate.ErrNotRelease):
+		if !uc.Check && !uc.Force {
+			return fmt.Errorf("%w: use --force to replace it with %s", err, release.TagName)
+		}
+		newer = true
+	case err != nil:
+		return err
+	}
+
+	if uc.Check {
+		if newer {
+			fmt.Fprintf(w, "A newer release is available: %s (running %s)\n%s\n", release.TagName, current, release.HtmlUrl)
+		} else {
+			fmt.Fprintf(w, "Already up to date: %s\n", current)
+		}
+		return nil
+	}
+	if !newer && !uc.Force {
+		fmt.Fprintf(w, "Already up to date: %s\n", current)
+		return nil
+	}
+
+	archiveName := selfupdate.ArchiveName(goos, goarch)
+	archiveAsset, ok := release.Asset(archiveName)
+	if !ok {
+		return fmt.Errorf("release %s has no archive for %s/%s (%s)", release.TagName, goos, goarch, archiveName)
+	}
+	checksumsAsset, ok := release.Asset(selfupdate.ChecksumsName)
+	if !ok {
+		return fmt.Errorf("release %s has no %s to verify the download with", release.TagName, selfupdate.ChecksumsName)
+	}
+
+	fmt.Fprintf(w, "Downloading %s\n", archiveName)
+	archive, err := updater.Download(ctx, archiveAsset)
+	if err != nil {
+		return err
+	}
+	checksums, err := updater.Download(ctx, checksumsAsset)
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.VerifyChecksum(archive, archiveName, checksums); err != nil {
+		return err
+	}
+
+	binary, err := selfupdate.ExtractBinary(archive, archiveName, selfupdate.BinaryName(goos))
+	if err != nil {
+		return err
+	}
+	if err := selfupdate.Replace(exePath, binary, goos); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "Updated from %s to %s\n", current, release.TagName)
+	return nil
+}
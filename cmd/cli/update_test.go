@@ -0,0 +1,153 @@
+package cli
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selfupdate"
+)
+
+// releaseServer serves a v1.3.0 release holding the linux/amd64 archive of binary and
+// its checksum, or checksumsOverride instead when given.
+func releaseServer(t *testing.T, binary []byte, checksumsOverride string) *httptest.Server {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	require.NoError(t, tw.WriteHeader(&tar.Header{Name: "nexus-mods-scraper", Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg}))
+	_, err := tw.Write(binary)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	archive := buf.Bytes()
+
+	archiveName := selfupdate.ArchiveName("linux", "amd64")
+	sum := sha256.Sum256(archive)
+	checksums := hex.EncodeToString(sum[:]) + "  " + archiveName + "\n"
+	if checksumsOverride != "" {
+		checksums = checksumsOverride
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/ondrovic/nexus-mods-scraper/releases/latest":
+			fmt.Fprintf(w, `{"tag_name":"v1.3.0","html_url":"https://github.com/ondrovic/nexus-mods-scraper/releases/tag/v1.3.0","assets":[{"name":%q,"browser_download_url":%q},{"name":"checksums.txt","browser_download_url":%q}]}`,
+				archiveName, server.URL+"/archive", server.URL+"/checksums.txt")
+		case "/archive":
+			w.Write(archive)
+		case "/checksums.txt":
+			w.Write([]byte(checksums))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+func TestRunUpdate(t *testing.T) {
+	tests := []struct {
+		name       string
+		uc         config.Update
+		current    string
+		checksums  string
+		wantErr    string
+		wantOutput string
+		wantBinary string
+	}{
+		{
+			name:       "newer release",
+			current:    "v1.2.0",
+			wantOutput: "Downloading nexus-mods-scraper_Linux_x86_64.tar.gz\nUpdated from v1.2.0 to v1.3.0\n",
+			wantBinary: "new",
+		},
+		{
+			name:       "up to date",
+			current:    "v1.3.0",
+			wantOutput: "Already up to date: v1.3.0\n",
+			wantBinary: "old",
+		},
+		{
+			name:       "check",
+			uc:         config.Update{Check: true},
+			current:    "v1.2.0",
+			wantOutput: "A newer release is available: v1.3.0 (running v1.2.0)\nhttps://github.com/ondrovic/nexus-mods-scraper/releases/tag/v1.3.0\n",
+			wantBinary: "old",
+		},
+		{
+			name:       "development build",
+			current:    "(devel)",
+			wantErr:    `current version "(devel)": not a release version: use --force to replace it with v1.3.0`,
+			wantBinary: "old",
+		},
+		{
+			name:       "force development build",
+			uc:         config.Update{Force: true},
+			current:    "(devel)",
+			wantOutput: "Downloading nexus-mods-scraper_Linux_x86_64.tar.gz\nUpdated from (devel) to v1.3.0\n",
+			wantBinary: "new",
+		},
+		{
+			name:       "checksum mismatch",
+			current:    "v1.2.0",
+			checksums:  "0000  nexus-mods-scraper_Linux_x86_64.tar.gz\n",
+			wantErr:    "checksum mismatch for nexus-mods-scraper_Linux_x86_64.tar.gz",
+			wantBinary: "old",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			mem := fsys.NewMemFS()
+			defer fsys.Use(mem)()
+			require.NoError(t, mem.MkdirAll("/bin", 0755))
+			require.NoError(t, mem.WriteFile("/bin/nexus-mods-scraper", []byte("old"), 0755))
+			server := releaseServer(t, []byte("new"), tt.checksums)
+			defer server.Close()
+			uc := tt.uc
+			uc.ApiUrl = server.URL
+			updater := selfupdate.Updater{ApiUrl: uc.ApiUrl, Client: server.Client(), Owner: RepoOwner, Repo: RepoName}
+			var out bytes.Buffer
+
+			// Act
+			err := runUpdate(context.Background(), &out, uc, tt.current, "/bin/nexus-mods-scraper", "linux", "amd64", updater)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.ErrorContains(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantOutput, out.String())
+			}
+			binary, _ := mem.ReadFile("/bin/nexus-mods-scraper")
+			assert.Equal(t, tt.wantBinary, string(binary))
+		})
+	}
+
+	t.Run("no archive for the platform", func(t *testing.T) {
+		// Arrange
+		server := releaseServer(t, []byte("new"), "")
+		defer server.Close()
+		updater := selfupdate.Updater{ApiUrl: server.URL, Client: server.Client(), Owner: RepoOwner, Repo: RepoName}
+
+		// Act
+		err := runUpdate(context.Background(), &bytes.Buffer{}, config.Update{}, "v1.2.0", "/bin/nexus-mods-scraper", "freebsd", "amd64", updater)
+
+		// Assert
+		assert.EqualError(t, err, "release v1.3.0 has no archive for freebsd/amd64 (nexus-mods-scraper_Freebsd_x86_64.tar.gz)")
+	})
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/spinners"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// userCmd is a Cobra command used for scraping an author's profile.
+	userCmd = &cobra.Command{}
+	// userOptions holds the command-line flag values of the scrape-user command.
+	userOptions = config.User{}
+	// fetchUserModsFunc is a variable that holds a reference to the function used for
+	// fetching the mods of an author.
+	fetchUserModsFunc = fetchers.FetchUserMods
+)
+
+// init initializes the scrape-user command with usage, description, and argument
+// validation. It registers the scrape-user flags and adds the command to the root command.
+func init() {
+	userCmd = &cobra.Command{
+		Use:   "scrape-user <username> [flags]",
+		Short: "Scrape an author's mods",
+		Long:  "Scrape the mods uploaded by an author from the Mods tab of their profile (name, ID, game, downloads, endorsements) and return a JSON report",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			uc, err := config.LoadUser(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(uc.BaseUrl, uc.CookieDirectory, uc.CookieFile, uc.CookieHeader)
+			if err != nil {
+				return err
+			}
+
+			return scrapeUser(uc, args[0], fetchUserModsFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterUserFlags(userCmd, &userOptions)
+	RootCmd.AddCommand(userCmd)
+}
+
+// scrapeUser collects the mods uploaded by the author, displays the report as JSON and,
+// when requested, saves it as <username>.json in the users output directory. Returns an
+// error if the profile can't be scraped or the report can't be saved.
+func scrapeUser(
+	uc config.User,
+	username string,
+	fetchUserModsFunc func(ctx context.Context, baseUrl, username string, maxPages int, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	userSpinner := spinners.CreateSpinner(fmt.Sprintf("Scraping mods of user: %s", username), "✓", "User scraping complete", "✗", "User scraping failed")
+	if err := userSpinner.Start(); err != nil {
+		return fmt.Errorf("failed to start spinner: %w", err)
+	}
+
+	profile, err := fetchUserModsFunc(context.Background(), uc.BaseUrl, username, uc.MaxPages, fetchDocumentFunc)
+	if err != nil {
+		userSpinner.StopFailMessage(fmt.Sprintf("Error scraping user: %v", err))
+		userSpinner.StopFail()
+		return fmt.Errorf("error scraping user %s: %w", username, err)
+	}
+	userSpinner.StopMessage(fmt.Sprintf("Found %d mods uploaded by %s", len(profile.Mods), username))
+	userSpinner.Stop()
+
+	jsonData, err := json.MarshalIndent(profile, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal user profile: %w", err)
+	}
+	if err := formatters.PrintPrettyJson(string(jsonData)); err != nil {
+		return err
+	}
+
+	if uc.SaveResults {
+		outputDirectory := filepath.Join(uc.OutputDirectory, "users")
+		savedPath, err := exporters.SaveModInfoToJson(types.CliFlags{}, profile, outputDirectory, username, utils.EnsureDirExists)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(formatters.Status(), "User report saved to %s\n", formatters.PathLink(savedPath))
+	}
+
+	return nil
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeUser_SavesResults(t *testing.T) {
+	// Arrange
+	outputDir := t.TempDir()
+	uc := config.User{BaseUrl: "https://example.com", MaxPages: 2, OutputDirectory: outputDir, SaveResults: true}
+	var requestedPages int
+	fetchUserMods := func(_ context.Context, baseUrl, username string, maxPages int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error) {
+		requestedPages = maxPages
+		return types.UserProfile{Username: username, Mods: []types.UserMod{{Game: "skyrim", ModID: 1, Name: "One"}}, TotalDownloads: 10}, nil
+	}
+
+	// Act
+	err := scrapeUser(uc, "author", fetchUserMods, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 2, requestedPages)
+	content, err := os.ReadFile(filepath.Join(outputDir, "users", "author.json"))
+	require.NoError(t, err)
+
+	var saved types.UserProfile
+	require.NoError(t, json.Unmarshal(content, &saved))
+	assert.Equal(t, "author", saved.Username)
+	assert.Equal(t, int64(10), saved.TotalDownloads)
+	assert.Len(t, saved.Mods, 1)
+}
+
+func TestScrapeUser_FetchError(t *testing.T) {
+	fetchUserMods := func(_ context.Context, baseUrl, username string, maxPages int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error) {
+		return types.UserProfile{}, errors.New("boom")
+	}
+
+	err := scrapeUser(config.User{}, "author", fetchUserMods, nil)
+
+	assert.EqualError(t, err, "error scraping user author: boom")
+}
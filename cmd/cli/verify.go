@@ -0,0 +1,175 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/archive"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/downloads"
+	"github.com/ondrovic/nexus-mods-scraper/internal/report"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// verifyCmd is a Cobra command used for verifying downloaded archives.
+	verifyCmd = &cobra.Command{}
+	// verifyOptions holds the command-line flag values of the verify command.
+	verifyOptions = config.Verify{}
+	// verifyArchiveCmd is a Cobra command used for verifying saved results.
+	verifyArchiveCmd = &cobra.Command{}
+	// verifyArchiveOptions holds the command-line flag values of the verify-archive command.
+	verifyArchiveOptions = config.VerifyArchive{}
+)
+
+// init initializes the verify and verify-archive commands with usage, description,
+// and argument validation. It registers their flags and adds the commands to the root
+// command.
+func init() {
+	verifyCmd = &cobra.Command{
+		Use:   "verify <download directory> [flags]",
+		Short: "Verify downloaded archives",
+		Long:  "Compare the MD5 checksums of the archives of a download directory with the ones scraped from the files tab of the saved mods, reporting the corrupted downloads. Only some games show checksums",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vc, err := config.LoadVerify(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Saved dates are parsed using the format they were written in
+			types.TimestampFormat = formatters.DateLayout(vc.DateFormat)
+
+			return verifyDownloads(cmd.OutOrStdout(), cmd.ErrOrStderr(), vc, args[0])
+		},
+	}
+
+	config.RegisterVerifyFlags(verifyCmd, &verifyOptions)
+	completeGameFlag(verifyCmd)
+	RootCmd.AddCommand(verifyCmd)
+
+	verifyArchiveCmd = &cobra.Command{
+		Use:   "verify-archive [flags]",
+		Short: "Verify saved results",
+		Long:  "Check that every saved mod file of the output directory parses, is named after the mod it contains, is stored under its game and has sane timestamps, optionally moving misplaced files",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			vc, err := config.LoadVerifyArchive(cmd)
+			if err != nil {
+				return err
+			}
+
+			// Saved dates are parsed using the format they were written in
+			types.TimestampFormat = formatters.DateLayout(vc.DateFormat)
+
+			return verifyArchive(cmd.OutOrStdout(), vc, time.Now())
+		},
+	}
+
+	config.RegisterVerifyArchiveFlags(verifyArchiveCmd, &verifyArchiveOptions)
+	RootCmd.AddCommand(verifyArchiveCmd)
+}
+
+// verifyArchive verifies the output directory and writes every issue found to w. When
+// Repair is set, misnamed and misplaced files are moved to where they belong. Returns
+// an error if the directory can't be read or issues remain unrepaired.
+func verifyArchive(w io.Writer, vc config.VerifyArchive, now time.Time) error {
+	report, err := archive.Verify(vc.OutputDirectory, now)
+	if err != nil {
+		return err
+	}
+
+	remaining := 0
+	for _, issue := range report.Issues {
+		fmt.Fprintf(w, "✗ %s: %s\n", issue.Path, issue.Problem)
+		if !vc.Repair || !issue.Repairable() {
+			remaining++
+			continue
+		}
+
+		if err := archive.Repair(issue); err != nil {
+			fmt.Fprintf(w, "  not repaired: %v\n", err)
+			remaining++
+			continue
+		}
+		fmt.Fprintf(w, "  moved to %s\n", issue.RepairPath)
+	}
+
+	fmt.Fprintf(w, "Checked %d files, found %d issues, %d repaired\n", report.Checked, len(report.Issues), len(report.Issues)-remaining)
+	if remaining > 0 {
+		return fmt.Errorf("%d issues found in %s", remaining, vc.OutputDirectory)
+	}
+	return nil
+}
+
+// verifyDownloads compares the archives of the download directory dir with the
+// checksums of the mods saved in the output directory, of the configured game only
+// when one is given, and writes the result to w in the configured format. Saved files
+// that can't be loaded are reported to status and left out. Returns an error if the
+// format is invalid, no saved file has a checksum, a directory can't be read, or
+// archives don't match their checksum.
+func verifyDownloads(w, status io.Writer, vc config.Verify, dir string) error {
+	format := strings.ToLower(vc.Format)
+	if format != "text" && format != "json" {
+		return fmt.Errorf("unsupported format %q, expected text or json", vc.Format)
+	}
+
+	entries, skipped, err := report.Load(vc.OutputDirectory)
+	if err != nil {
+		return err
+	}
+	for _, err := range skipped {
+		fmt.Fprintf(status, "Skipping %v\n", err)
+	}
+	var saved []report.Entry
+	checksums := 0
+	for _, entry := range entries {
+		if vc.Game != "" && !strings.EqualFold(entry.Game, vc.Game) {
+			continue
+		}
+		saved = append(saved, entry)
+		for _, file := range entry.Mod.Files {
+			if file.MD5 != "" {
+				checksums++
+			}
+		}
+	}
+	if checksums == 0 {
+		return fmt.Errorf("no scraped checksums found in %s, scrape the mods with their files first", vc.OutputDirectory)
+	}
+
+	result, err := downloads.Verify(dir, saved)
+	if err != nil {
+		return err
+	}
+
+	if format == "json" {
+		data, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error formatting results: %v", err)
+		}
+		fmt.Fprintln(w, string(data))
+	} else {
+		for _, archive := range result.Archives {
+			switch archive.Status {
+			case downloads.StatusOK:
+				fmt.Fprintf(w, "✓ %s: %s %s\n", archive.Path, archive.ModName, archive.File)
+			case downloads.StatusMismatch:
+				fmt.Fprintf(w, "✗ %s: checksum %s, expected %s for %s %s\n", archive.Path, archive.MD5, strings.Join(archive.Expected, " or "), archive.ModName, archive.File)
+			default:
+				fmt.Fprintf(w, "? %s: no scraped checksum\n", archive.Path)
+			}
+		}
+		fmt.Fprintf(w, "Checked %d archives: %d ok, %d mismatched, %d unknown\n", len(result.Archives), result.Counts[downloads.StatusOK], result.Counts[downloads.StatusMismatch], result.Counts[downloads.StatusUnknown])
+	}
+
+	if mismatched := result.Counts[downloads.StatusMismatch]; mismatched > 0 {
+		return fmt.Errorf("%d archives of %s don't match their checksum", mismatched, dir)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyArchive_Repair(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skyrim"), os.ModePerm))
+	misnamed := filepath.Join(dir, "skyrim", "old name 1.json")
+	require.NoError(t, os.WriteFile(misnamed, []byte(`{"Mods":{"ModID":1,"Name":"New Name","LastChecked":"2024-06-01T10:00:00Z"}}`), 0644))
+	var out bytes.Buffer
+
+	// Act
+	err := verifyArchive(&out, config.VerifyArchive{OutputDirectory: dir, Repair: true}, time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC))
+
+	// Assert
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(dir, "skyrim", "new name 1.json"))
+	assert.NoFileExists(t, misnamed)
+	assert.Contains(t, out.String(), "Checked 1 files, found 1 issues, 1 repaired")
+}
+
+func TestVerifyArchive_ReportsIssues(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "skyrim"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skyrim", "bad 1.json"), []byte(`not json`), 0644))
+	var out bytes.Buffer
+
+	// Act
+	err := verifyArchive(&out, config.VerifyArchive{OutputDirectory: dir, Repair: true}, time.Now())
+
+	// Assert
+	assert.EqualError(t, err, "1 issues found in "+dir)
+	assert.Contains(t, out.String(), "bad 1.json: doesn't match the results schema")
+}
+
+func TestVerifyDownloads_ReportsMismatch(t *testing.T) {
+	// Arrange
+	saved := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(saved, "skyrimspecialedition"), os.ModePerm))
+	require.NoError(t, os.WriteFile(filepath.Join(saved, "skyrimspecialedition", "skyui 12604.json"), []byte(`{"Mods":{"ModID":12604,"Name":"SkyUI","ModURL":"https://nexusmods.com/skyrimspecialedition/mods/12604","Files":[{"name":"SkyUI","version":"5.2SE","md5":"0123456789abcdef0123456789abcdef"}]}}`), 0644))
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SkyUI_5_2_SE-12604-5-2SE-1573430936.7z"), []byte("truncated"), 0644))
+	var out, status bytes.Buffer
+
+	// Act
+	err := verifyDownloads(&out, &status, config.Verify{Format: "text", OutputDirectory: saved}, dir)
+
+	// Assert
+	assert.EqualError(t, err, "1 archives of "+dir+" don't match their checksum")
+	assert.Contains(t, out.String(), "expected 0123456789abcdef0123456789abcdef for SkyUI SkyUI")
+	assert.Contains(t, out.String(), "Checked 1 archives: 0 ok, 1 mismatched, 0 unknown")
+}
+
+func TestVerifyDownloads_NoChecksums(t *testing.T) {
+	var out, status bytes.Buffer
+
+	err := verifyDownloads(&out, &status, config.Verify{Format: "text", OutputDirectory: t.TempDir()}, t.TempDir())
+
+	assert.ErrorContains(t, err, "no scraped checksums found")
+}
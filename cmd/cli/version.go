@@ -1,7 +1,12 @@
 package cli
 
 import (
-	"go.szostok.io/version/extension"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/spf13/cobra"
+	"go.szostok.io/version"
+	"go.szostok.io/version/printer"
 )
 
 const (
@@ -11,13 +16,44 @@ const (
 	RepoName string = "nexus-mods-scraper"
 )
 
+// versionExtra holds the build metadata printed by the version command besides the
+// version, commit, build date and Go version.
+type versionExtra struct {
+	SchemaVersion int `json:"schemaVersion" yaml:"schemaVersion" pretty:"Schema Version"`
+}
+
 // init initializes the command-line interface by adding the version command
 // to the root command, including an upgrade notice with the repository owner
 // and name.
 func init() {
-	RootCmd.AddCommand(
-		extension.NewVersionCobraCmd(
-			extension.WithUpgradeNotice(RepoOwner, RepoName),
-		),
-	)
+	RootCmd.AddCommand(newVersionCmd(printer.WithUpgradeNotice(RepoOwner, RepoName)))
+}
+
+// newVersionCmd returns the version command, printing the build metadata of the
+// binary along with the extractor schema version, in the --output format.
+func newVersionCmd(options ...printer.ContainerOption) *cobra.Command {
+	verPrinter := printer.New(options...)
+
+	cmd := &cobra.Command{
+		Use:     "version",
+		Short:   "Print the CLI version",
+		Long:    "Print the version, commit, build date and Go version of the binary, and the schema version of the mod info its extractors produce",
+		Example: strings.Join([]string{"version", "version -o=json", "version -o=yaml", "version -o=short"}, "\n"),
+		Aliases: []string{"ver"},
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return verPrinter.PrintInfo(cmd.OutOrStdout(), versionInfo())
+		},
+	}
+
+	verPrinter.RegisterPFlags(cmd.Flags())
+	return cmd
+}
+
+// versionInfo returns the build metadata of the binary along with the extractor
+// schema version.
+func versionInfo() *version.Info {
+	info := version.Get()
+	info.ExtraFields = versionExtra{SchemaVersion: types.SchemaVersion}
+	return info
 }
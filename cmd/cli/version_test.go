@@ -1,44 +1,57 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
 	"testing"
 
-	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
-	"go.szostok.io/version/extension"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 )
 
-func TestInit_VersionCommandAdded(t *testing.T) {
-	// Create a new RootCmd without initializing it
-	rootCmd := &cobra.Command{}
-
-	// Check if version command has been added by default
-	found := false
-	for _, cmd := range rootCmd.Commands() {
-		if cmd.Use == "version" {
-			found = true
-			break
-		}
-	}
-
-	assert.False(t, found, "Version command should not exist before init")
-
-	// Now run the init function indirectly by importing the package
-	// Simulate what happens after `init`
-	RootCmd = rootCmd // Point to our mock rootCmd
-	extensionCmd := extension.NewVersionCobraCmd(
-		extension.WithUpgradeNotice(RepoOwner, RepoName),
-	)
-	RootCmd.AddCommand(extensionCmd)
-
-	// Test if the version command was added to RootCmd
-	found = false
-	for _, cmd := range RootCmd.Commands() {
-		if cmd.Use == "version" {
-			found = true
-			break
-		}
-	}
-
-	assert.True(t, found, "Version command should be added to RootCmd")
+func TestNewVersionCmd(t *testing.T) {
+	// Act
+	cmd := newVersionCmd()
+
+	// Assert
+	assert.Equal(t, "version", cmd.Name())
+	assert.Equal(t, []string{"ver"}, cmd.Aliases)
+	assert.NotNil(t, cmd.Flags().Lookup("output"))
+}
+
+func TestVersionCmd_Json(t *testing.T) {
+	// Arrange
+	cmd := newVersionCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{"--output", "json"})
+
+	// Act
+	err := cmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	var info map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &info))
+	assert.Equal(t, float64(types.SchemaVersion), info["schemaVersion"])
+	assert.NotEmpty(t, info["version"])
+	assert.NotEmpty(t, info["goVersion"])
+}
+
+func TestVersionCmd_Pretty(t *testing.T) {
+	// Arrange
+	cmd := newVersionCmd()
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.SetArgs([]string{})
+
+	// Act
+	err := cmd.Execute()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "Schema Version")
+	assert.Contains(t, out.String(), "Build Date")
 }
@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/wabbajack"
+)
+
+// runWabbajack scrapes every Nexus mod of the Wabbajack modlist given with
+// --from-wabbajack, game by game, and prints the refresh report comparing the versions
+// the modlist pins with the latest ones, also writing it to the refresh report file
+// when one is configured. The session cookies are checked first unless --no-preflight
+// is given. With --dry-run, the requests the run would make are printed instead.
+// Returns an error if mod IDs are given as well, the modlist can't be read
+// or holds too many mods, or the mods of a game failed to scrape.
+func runWabbajack(sc types.CliFlags, args []string) error {
+	if len(args) > 0 || sc.IdsFile != "" {
+		return fmt.Errorf("--from-wabbajack reads the games and mod ids from the modlist, don't give them as well")
+	}
+
+	list, err := wabbajack.Read(sc.FromWabbajack)
+	if err != nil {
+		return err
+	}
+	if len(list.Mods) == 0 {
+		return fmt.Errorf("no Nexus Mods archives found in modlist %s", sc.FromWabbajack)
+	}
+	if sc.DryRun {
+		printWabbajackPlan(formatters.Status(), sc, list)
+		return nil
+	}
+	if sc.MaxMods > 0 && len(list.Mods) > sc.MaxMods && !sc.Yes {
+		return fmt.Errorf("refusing to scrape %d mods, more than the cap of %d mods per run: raise it with --max-mods or confirm with --yes", len(list.Mods), sc.MaxMods)
+	}
+
+	if !sc.NoPreflight {
+		if err := preflightCookies(sc); err != nil {
+			return err
+		}
+	}
+
+	refresh, err := scrapeWabbajack(context.Background(), sc, list, fetchModInfoFunc, fetchDocumentFunc)
+	printRefresh(formatters.Status(), refresh)
+	if sc.RefreshReport != "" {
+		if saveErr := wabbajack.SaveRefresh(sc.RefreshReport, refresh); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}
+	return err
+}
+
+// printWabbajackPlan writes what a run of sc scraping the mods of the modlist would do to
+// w, game by game, as asked with --dry-run, without making any request.
+func printWabbajackPlan(w io.Writer, sc types.CliFlags, list wabbajack.ModList) {
+	printSessionPlan(w, sc)
+	games, modIDs := list.Games()
+	for _, game := range games {
+		slug, err := types.ParseGameSlug(game)
+		if err != nil {
+			fmt.Fprintf(w, "Would skip the %s mods: %v\n", game, err)
+			continue
+		}
+		sc.GameName = slug
+		printModsPlan(w, sc, modIDs[game])
+	}
+	printCapPlan(w, sc, len(list.Mods))
+}
+
+// scrapeWabbajack scrapes the mods of the modlist, grouped by game in the order the
+// games first appear, under a single run ID, and returns the refresh report of the
+// mods scraped. The mods of a game that fails to scrape are reported as not scraped,
+// and the remaining games are still scraped. Returns an error if any game failed.
+func scrapeWabbajack(
+	ctx context.Context,
+	sc types.CliFlags,
+	list wabbajack.ModList,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) (wabbajack.Refresh, error) {
+	if sc.RunID == "" {
+		sc.RunID = utils.NewRunID()
+	}
+
+	scraped := map[string]types.ModInfo{}
+	games, modIDs := list.Games()
+	failedGames := 0
+	for _, game := range games {
+		if ctx.Err() != nil {
+			break
+		}
+		gameSlug, err := types.ParseGameSlug(game)
+		if err != nil {
+			fmt.Fprintf(formatters.Status(), "Error scraping %s mods: %v\n", game, err)
+			failedGames++
+			continue
+		}
+
+		sc.GameName = gameSlug
+		observe := func(modID int64, mod types.ModInfo) {
+			scraped[wabbajack.Key(game, modID)] = mod
+		}
+		if err := scrapeModsObserving(ctx, sc, modIDs[game], fetchModInfoFunc, fetchDocumentFunc, observe); err != nil {
+			fmt.Fprintf(formatters.Status(), "Error scraping %s mods: %v\n", game, err)
+			failedGames++
+		}
+	}
+
+	refresh := wabbajack.NewRefresh(list, scraped)
+	if failedGames > 0 {
+		return refresh, fmt.Errorf("mods of %d of %d games of modlist %s failed to scrape", failedGames, len(games), list.Name)
+	}
+	return refresh, nil
+}
+
+// printRefresh writes the refresh report: the count of mods per status, then the
+// mods that need the maintainer's attention, those outdated, no longer available or
+// not scraped.
+func printRefresh(w io.Writer, refresh wabbajack.Refresh) {
+	name := strings.TrimSpace(refresh.Name + " " + refresh.Version)
+	if name == "" {
+		name = "the modlist"
+	}
+
+	counts := make([]string, 0, len(refresh.Counts))
+	for _, status := range refresh.Statuses() {
+		counts = append(counts, fmt.Sprintf("%d %s", refresh.Counts[status], strings.ReplaceAll(status, "_", " ")))
+	}
+	fmt.Fprintf(w, "Refresh report of %s, %d mods: %s\n", name, len(refresh.Mods), strings.Join(counts, ", "))
+
+	for _, mod := range refresh.Mods {
+		switch mod.Status {
+		case wabbajack.StatusCurrent:
+			continue
+		case wabbajack.StatusOutdated:
+			fmt.Fprintf(w, "  outdated     %s/%d %s: %s -> %s\n", mod.Game, mod.ModID, mod.Name, mod.PinnedVersion, mod.LatestVersion)
+		default:
+			fmt.Fprintf(w, "  %-12s %s/%d %s\n", strings.ReplaceAll(mod.Status, "_", " "), mod.Game, mod.ModID, mod.Name)
+		}
+	}
+}
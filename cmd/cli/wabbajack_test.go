@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/wabbajack"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScrapeWabbajack(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644))
+	sc := types.CliFlags{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		OutputDirectory: tempDir,
+	}
+	list := wabbajack.ModList{Name: "Test List", Mods: []wabbajack.Mod{
+		{Game: "skyrimspecialedition", ModID: 1, Name: "One", Version: "1.0"},
+		{Game: "newvegas", ModID: 2, Name: "Two", Version: "2.0"},
+		{Game: "skyrimspecialedition", ModID: 3, Name: "Three", Version: "3.0"},
+		{Game: "skyrimspecialedition", ModID: 4, Name: "Four", Version: "4.0"},
+	}}
+
+	var scraped []string
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped = append(scraped, wabbajack.Key(game.String(), modId.Int64()))
+		switch modId {
+		case 3:
+			return types.Results{Mods: types.ModInfo{ModID: 3, Name: "Three", Status: types.ModStatusRemoved}}, nil
+		case 4:
+			return types.Results{}, errors.New("boom")
+		}
+		return types.Results{Mods: types.ModInfo{ModID: modId.Int64(), LatestVersion: "2.0"}}, nil
+	}
+
+	// Act
+	refresh, err := scrapeWabbajack(context.Background(), sc, list, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	assert.EqualError(t, err, "mods of 1 of 2 games of modlist Test List failed to scrape")
+	assert.Equal(t, []string{"skyrimspecialedition/1", "skyrimspecialedition/3", "skyrimspecialedition/4", "newvegas/2"}, scraped)
+	assert.Equal(t, map[string]int{wabbajack.StatusCurrent: 1, wabbajack.StatusOutdated: 1, types.ModStatusRemoved: 1, wabbajack.StatusNotScraped: 1}, refresh.Counts)
+	assert.Equal(t, wabbajack.StatusOutdated, refresh.Mods[0].Status)
+	assert.Equal(t, wabbajack.StatusCurrent, refresh.Mods[1].Status)
+}
+
+func TestPrintRefresh(t *testing.T) {
+	// Arrange
+	refresh := wabbajack.NewRefresh(wabbajack.ModList{Name: "Test List", Version: "1.2.0", Mods: []wabbajack.Mod{
+		{Game: "skyrimspecialedition", ModID: 1, Name: "One", Version: "1.0"},
+		{Game: "skyrimspecialedition", ModID: 2, Name: "Two", Version: "2.0"},
+		{Game: "skyrimspecialedition", ModID: 3, Name: "Three"},
+	}}, map[string]types.ModInfo{
+		"skyrimspecialedition/1": {LatestVersion: "1.1"},
+		"skyrimspecialedition/2": {LatestVersion: "2.0"},
+	})
+	var out bytes.Buffer
+
+	// Act
+	printRefresh(&out, refresh)
+
+	// Assert
+	assert.Equal(t, "Refresh report of Test List 1.2.0, 3 mods: 1 current, 1 not scraped, 1 outdated\n"+
+		"  outdated     skyrimspecialedition/1 One: 1.0 -> 1.1\n"+
+		"  not scraped  skyrimspecialedition/3 Three\n", out.String())
+}
+
+func TestRunWabbajack_RejectsModIDs(t *testing.T) {
+	err := runWabbajack(types.CliFlags{FromWabbajack: "list.wabbajack"}, []string{"skyrimspecialedition", "1"})
+
+	assert.EqualError(t, err, "--from-wabbajack reads the games and mod ids from the modlist, don't give them as well")
+}
@@ -0,0 +1,379 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/notifiers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/store"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// watchCmd is a Cobra command used for watching a list of mods.
+	watchCmd = &cobra.Command{}
+	// watchOptions holds the command-line flag values of the watch command.
+	watchOptions = config.Watch{}
+)
+
+// init initializes the watch command with usage, description, and argument validation.
+// It registers the watch flags and adds the command to the root command.
+func init() {
+	watchCmd = &cobra.Command{
+		Use:   "watch [flags]",
+		Short: "Watch a list of mods",
+		Long:  "Scrape the mods of the watch list, recording them in the history journal and notifying about updates, once or, with --daemon, every interval while serving a control API to manage the list. Watch profiles defined in the configuration file are each run with their own watch list and settings, on independent schedules",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wc, err := config.LoadWatch(cmd)
+			if err != nil {
+				return err
+			}
+			if err := validateWatch(wc); err != nil {
+				return err
+			}
+
+			// Watch profiles of the configuration file replace the single watch list
+			profiles, err := config.LoadWatchProfiles(cmd)
+			if err != nil {
+				return err
+			}
+			if profiles, err = selectWatchProfiles(profiles, wc.Profiles); err != nil {
+				return err
+			}
+			for _, profile := range profiles {
+				if err := validateWatch(profile.Watch); err != nil {
+					return fmt.Errorf("watch profile %s: %w", profile.Name, err)
+				}
+			}
+			if len(profiles) > 0 {
+				ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+				defer stop()
+				return runWatchProfiles(ctx, wc, profiles)
+			}
+
+			run := func(ctx context.Context, targets []watch.Target) error {
+				return runWatchPass(ctx, wc, targets, fetchModInfoFunc, fetchDocumentFunc)
+			}
+
+			// Stopping lets the mods in flight finish and flushes their state first
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			if !wc.Daemon {
+				targets, err := loadWatchlist(wc)
+				if err != nil {
+					return err
+				}
+				if len(targets) == 0 {
+					fmt.Fprintf(formatters.Status(), "No mods in the watch list %s\n", wc.WatchlistFile)
+					return nil
+				}
+				return run(ctx, targets)
+			}
+
+			listener, err := net.Listen("tcp", wc.Listen)
+			if err != nil {
+				return fmt.Errorf("error starting control API: %w", err)
+			}
+			return runWatchDaemon(ctx, wc, listener, run)
+		},
+	}
+
+	config.RegisterWatchFlags(watchCmd, &watchOptions)
+	RootCmd.AddCommand(watchCmd)
+}
+
+// runWatchDaemon scrapes the watch list every interval and serves the control API on
+// listener until ctx is done, then stops the API gracefully. Returns an error if the
+// watch list can't be read or the API fails.
+func runWatchDaemon(ctx context.Context, wc config.Watch, listener net.Listener, run watch.RunFunc) error {
+	list, err := store.OpenWatchlist(wc.StorageDriver, wc.WatchlistFile)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+	defer list.Close()
+
+	daemon, err := watch.NewDaemon(list, wc.Interval, run)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	fmt.Fprintf(formatters.Status(), "Watching %d mods every %s, control API on http://%s\n", len(daemon.List()), wc.Interval, listener.Addr())
+	return serveDaemons(ctx, listener, watch.Handler(daemon), daemon)
+}
+
+// runWatchProfiles runs the watch profiles, each scraping its own watch list with its
+// own settings: once, one profile after the other, or with --daemon every interval of
+// each profile, on independent schedules, until ctx is done. A failing or panicking
+// profile doesn't affect the others. Returns an error if profiles share a watch list,
+// a watch list can't be read, the control API fails, or any profile fails in a single
+// run.
+func runWatchProfiles(ctx context.Context, wc config.Watch, profiles []config.WatchProfile) error {
+	watchlists := map[string]string{}
+	for _, profile := range profiles {
+		if other, ok := watchlists[profile.Watch.WatchlistFile]; ok {
+			return fmt.Errorf("watch profiles %s and %s share the watch list %s, give each its own watchlist-file", other, profile.Name, profile.Watch.WatchlistFile)
+		}
+		watchlists[profile.Watch.WatchlistFile] = profile.Name
+	}
+
+	runs := make(map[string]watch.RunFunc, len(profiles))
+	for _, profile := range profiles {
+		pc := profile.Watch
+		runs[profile.Name] = isolatedRun(profile.Name, func(ctx context.Context, targets []watch.Target) error {
+			return runWatchPass(ctx, pc, targets, fetchModInfoFunc, fetchDocumentFunc)
+		})
+	}
+
+	if !wc.Daemon {
+		failed := 0
+		for _, profile := range profiles {
+			if ctx.Err() != nil {
+				break
+			}
+			fmt.Fprintf(formatters.Status(), "Watch profile %s\n", profile.Name)
+			targets, err := loadWatchlist(profile.Watch)
+			if err == nil && len(targets) == 0 {
+				fmt.Fprintf(formatters.Status(), "No mods in the watch list %s\n", profile.Watch.WatchlistFile)
+				continue
+			}
+			if err == nil {
+				err = runs[profile.Name](ctx, targets)
+			}
+			if err != nil {
+				fmt.Fprintf(formatters.Status(), "Error running watch profile %s: %v\n", profile.Name, err)
+				failed++
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d of %d watch profiles failed", failed, len(profiles))
+		}
+		return nil
+	}
+
+	listener, err := net.Listen("tcp", wc.Listen)
+	if err != nil {
+		return fmt.Errorf("error starting control API: %w", err)
+	}
+
+	daemons := make(map[string]*watch.Daemon, len(profiles))
+	all := make([]*watch.Daemon, 0, len(profiles))
+	for _, profile := range profiles {
+		list, err := store.OpenWatchlist(profile.Watch.StorageDriver, profile.Watch.WatchlistFile)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+		defer list.Close()
+
+		daemon, err := watch.NewDaemon(list, profile.Watch.Interval, runs[profile.Name])
+		if err != nil {
+			listener.Close()
+			return fmt.Errorf("watch profile %s: %w", profile.Name, err)
+		}
+		daemons[profile.Name] = daemon
+		all = append(all, daemon)
+		fmt.Fprintf(formatters.Status(), "Watch profile %s: watching %d mods every %s\n", profile.Name, len(daemon.List()), profile.Watch.Interval)
+	}
+
+	fmt.Fprintf(formatters.Status(), "Control API on http://%s/profiles\n", listener.Addr())
+	return serveDaemons(ctx, listener, watch.ProfilesHandler(daemons), all...)
+}
+
+// isolatedRun returns run with a panic turned into the error of the pass, so that a
+// watch profile going wrong doesn't bring down the others.
+func isolatedRun(profile string, run watch.RunFunc) watch.RunFunc {
+	return func(ctx context.Context, targets []watch.Target) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("watch profile %s panicked: %v", profile, r)
+			}
+		}()
+		return run(ctx, targets)
+	}
+}
+
+// serveDaemons runs the daemons, each on its own schedule, and serves their control
+// API with handler on listener until ctx is done or the API fails, then waits for the
+// passes in progress and stops the API gracefully. Returns an error if the API fails.
+func serveDaemons(ctx context.Context, listener net.Listener, handler http.Handler, daemons ...*watch.Daemon) error {
+	server := &http.Server{Handler: handler, ReadHeaderTimeout: 10 * time.Second}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.Serve(listener)
+	}()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	var running sync.WaitGroup
+	for _, daemon := range daemons {
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			daemon.Run(ctx)
+		}()
+	}
+
+	var err error
+	select {
+	case <-ctx.Done():
+	case err = <-serveErr:
+		cancel()
+	}
+	running.Wait()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+	if shutdownErr := server.Shutdown(shutdownCtx); shutdownErr != nil && err == nil {
+		err = shutdownErr
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// validateWatch checks the settings of wc that the passes forward to the scrape of each
+// game, so that a typo fails the command rather than every pass.
+func validateWatch(wc config.Watch) error {
+	if wc.Backend != fetchers.BackendHtml && wc.Backend != fetchers.BackendGraphQL {
+		return fmt.Errorf("unsupported backend %q, expected html or graphql", wc.Backend)
+	}
+	if wc.RateLimit < 0 {
+		return fmt.Errorf("--rate-limit must not be negative")
+	}
+	return storage.ValidateCookieStore(wc.CookieStore)
+}
+
+// selectWatchProfiles returns the profiles named in selected, or all of them when none
+// is. Returns an error naming the first selected profile that isn't defined.
+func selectWatchProfiles(profiles []config.WatchProfile, selected []string) ([]config.WatchProfile, error) {
+	if len(selected) == 0 {
+		return profiles, nil
+	}
+
+	var kept []config.WatchProfile
+	for _, name := range selected {
+		found := false
+		for _, profile := range profiles {
+			if strings.EqualFold(profile.Name, name) {
+				kept, found = append(kept, profile), true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown watch profile %q", name)
+		}
+	}
+	return kept, nil
+}
+
+// loadWatchlist reads the watch list kept by the configured storage driver.
+func loadWatchlist(wc config.Watch) ([]watch.Target, error) {
+	list, err := store.OpenWatchlist(wc.StorageDriver, wc.WatchlistFile)
+	if err != nil {
+		return nil, err
+	}
+	defer list.Close()
+
+	return list.Load()
+}
+
+// runWatchPass scrapes the watched mods once, grouped by game and sharing a single run
+// ID and rate limit. The results are saved and recorded in the history journal, and the
+// updates found in every game are notified about together once the pass is done. Once
+// ctx is done no further mod is started and the pass ends after the mods in flight,
+// bounded by the drain timeout; the mods left are scraped by the next pass. Returns an
+// error if the mods of any game fail to scrape.
+func runWatchPass(
+	ctx context.Context,
+	wc config.Watch,
+	targets []watch.Target,
+	fetchModInfoFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	var games []string
+	modIDs := map[string][]int64{}
+	for _, target := range targets {
+		if _, ok := modIDs[target.Game]; !ok {
+			games = append(games, target.Game)
+		}
+		modIDs[target.Game] = append(modIDs[target.Game], target.ModID)
+	}
+
+	// The games of the pass share one rate limit and one round of notifications
+	runID := utils.NewRunID()
+	batch := &notifiers.Batch{}
+	passCtx := notifiers.WithBatch(httpclient.WithLimiter(ctx, httpclient.NewLimiter(wc.RateLimit)), batch)
+	failedGames := 0
+	for _, game := range games {
+		if ctx.Err() != nil {
+			break
+		}
+		gameSlug, err := types.ParseGameSlug(game)
+		if err != nil {
+			fmt.Fprintf(formatters.Status(), "Error scraping %s mods: %v\n", game, err)
+			failedGames++
+			continue
+		}
+		sc := types.CliFlags{
+			Backend:         wc.Backend,
+			BaseUrl:         wc.BaseUrl,
+			CookieDirectory: wc.CookieDirectory,
+			CookieFile:      wc.CookieFile,
+			CookieHeader:    wc.CookieHeader,
+			CookieStore:     wc.CookieStore,
+			Digest:          wc.Digest,
+			DrainTimeout:    wc.DrainTimeout,
+			GameName:        gameSlug,
+			GraphQLEndpoint: wc.GraphQLEndpoint,
+			HistoryFile:     wc.HistoryFile,
+			IgnoreFile:      wc.IgnoreFile,
+			NotifyWebhook:   wc.NotifyWebhook,
+			OutputDirectory: wc.OutputDirectory,
+			PerModTimeout:   wc.PerModTimeout,
+			RateLimit:       wc.RateLimit,
+			RecordHistory:   true,
+			RunID:           runID,
+			SaveResults:     true,
+			SkipUnchanged:   wc.SkipUnchanged,
+			StorageDriver:   wc.StorageDriver,
+		}
+		if err := scrapeMods(passCtx, sc, modIDs[game], fetchModInfoFunc, fetchDocumentFunc); err != nil {
+			fmt.Fprintf(formatters.Status(), "Error scraping %s mods: %v\n", game, err)
+			failedGames++
+		}
+	}
+
+	if wc.NotifyWebhook != "" {
+		if err := notifiers.Dispatch(context.Background(), notifiers.NewWebhook(wc.NotifyWebhook), runID, batch.Updates(), wc.Digest); err != nil {
+			fmt.Fprintf(formatters.Status(), "Error sending notifications: %v\n", err)
+		}
+	}
+
+	if failedGames > 0 {
+		return fmt.Errorf("mods of %d of %d watched games failed to scrape", failedGames, len(games))
+	}
+	return nil
+}
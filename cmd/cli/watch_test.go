@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/notifiers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunWatchPass(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+
+	wc := config.Watch{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		HistoryFile:     filepath.Join(tempDir, "history.jsonl"),
+		OutputDirectory: tempDir,
+	}
+	scraped := map[string][]int64{}
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		scraped[game.String()] = append(scraped[game.String()], modId.Int64())
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LatestVersion: "1.0"}}, nil
+	}
+	targets := []watch.Target{{Game: "skyrim", ModID: 1}, {Game: "fallout4", ModID: 2}, {Game: "skyrim", ModID: 3}}
+
+	// Act
+	err = runWatchPass(context.Background(), wc, targets, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]int64{"skyrim": {1, 3}, "fallout4": {2}}, scraped)
+	assert.FileExists(t, filepath.Join(tempDir, "skyrim", "mocked mod 3.json"))
+
+	entries, err := history.Load(wc.HistoryFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, entries[0].RunID, entries[2].RunID)
+}
+
+func TestRunWatchPass_DigestSpansGames(t *testing.T) {
+	// Arrange
+	var messages []notifiers.Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var message notifiers.Message
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&message))
+		messages = append(messages, message)
+	}))
+	defer server.Close()
+
+	tempDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tempDir, "session-cookies.json"), []byte("{}"), 0644)
+	require.NoError(t, err)
+	historyFile := filepath.Join(tempDir, "history.jsonl")
+	err = history.Append(historyFile,
+		history.Entry{Game: "skyrim", ModID: 1, Version: "1.0"},
+		history.Entry{Game: "fallout4", ModID: 2, Version: "1.0"},
+	)
+	require.NoError(t, err)
+
+	wc := config.Watch{
+		BaseUrl:         "https://somesite.com",
+		CookieDirectory: tempDir,
+		CookieFile:      "session-cookies.json",
+		Digest:          true,
+		HistoryFile:     historyFile,
+		NotifyWebhook:   server.URL,
+		OutputDirectory: tempDir,
+	}
+	fetchModInfo := func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64(), LatestVersion: "2.0"}}, nil
+	}
+	targets := []watch.Target{{Game: "skyrim", ModID: 1}, {Game: "fallout4", ModID: 2}}
+
+	// Act
+	err = runWatchPass(context.Background(), wc, targets, fetchModInfo, mockFetchDocument)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, messages, 1, "one digest for the whole pass")
+	assert.Len(t, messages[0].Updates, 2)
+	assert.Contains(t, messages[0].Text, "2 mods updated")
+}
+
+func TestValidateWatch(t *testing.T) {
+	tests := []struct {
+		name string
+		wc   config.Watch
+		err  string
+	}{
+		{name: "valid", wc: config.Watch{Backend: fetchers.BackendGraphQL, CookieStore: "keyring", RateLimit: time.Second}},
+		{name: "unknown backend", wc: config.Watch{Backend: "rest"}, err: `unsupported backend "rest", expected html or graphql`},
+		{name: "negative rate limit", wc: config.Watch{Backend: fetchers.BackendHtml, RateLimit: -time.Second}, err: "--rate-limit must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWatch(tt.wc)
+
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
+
+func TestRunWatchDaemon(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	wc := config.Watch{Interval: time.Hour, WatchlistFile: filepath.Join(tempDir, watch.DefaultFilename)}
+	require.NoError(t, watch.Save(wc.WatchlistFile, []watch.Target{{Game: "skyrim", ModID: 1}}))
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	passes := make(chan []watch.Target, 1)
+	run := func(ctx context.Context, targets []watch.Target) error {
+		passes <- targets
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+
+	// Act
+	go func() {
+		done <- runWatchDaemon(ctx, wc, listener, run)
+	}()
+	targets := <-passes
+	resp, err := http.Get("http://" + listener.Addr().String() + "/list")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	cancel()
+
+	// Assert
+	assert.NoError(t, <-done)
+	assert.Equal(t, []watch.Target{{Game: "skyrim", ModID: 1}}, targets)
+	assert.JSONEq(t, `[{"game":"skyrim","modId":1}]`, string(body))
+}
+
+func TestRunWatchProfiles_Once(t *testing.T) {
+	// Arrange
+	tempDir := t.TempDir()
+	profile := func(name string) config.WatchProfile {
+		return config.WatchProfile{Name: name, Watch: config.Watch{
+			BaseUrl:         "https://somesite.com",
+			CookieHeader:    "session=abc",
+			HistoryFile:     filepath.Join(tempDir, name+".jsonl"),
+			OutputDirectory: tempDir,
+			WatchlistFile:   filepath.Join(tempDir, name+".json"),
+		}}
+	}
+	profiles := []config.WatchProfile{profile("fallout"), profile("skyrim"), profile("empty")}
+	require.NoError(t, watch.Save(profiles[0].Watch.WatchlistFile, []watch.Target{{Game: "fallout4", ModID: 1}}))
+	require.NoError(t, watch.Save(profiles[1].Watch.WatchlistFile, []watch.Target{{Game: "skyrim", ModID: 2}}))
+
+	previous := fetchModInfoFunc
+	defer func() { fetchModInfoFunc = previous }()
+	scraped := map[string][]int64{}
+	fetchModInfoFunc = func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter fetchers.ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+		if game == "fallout4" {
+			panic("boom")
+		}
+		scraped[game.String()] = append(scraped[game.String()], modId.Int64())
+		return types.Results{Mods: types.ModInfo{Name: "Mocked Mod", ModID: modId.Int64()}}, nil
+	}
+
+	// Act
+	err := runWatchProfiles(context.Background(), config.Watch{}, profiles)
+
+	// Assert
+	assert.EqualError(t, err, "1 of 3 watch profiles failed")
+	assert.Equal(t, map[string][]int64{"skyrim": {2}}, scraped)
+	assert.FileExists(t, filepath.Join(tempDir, "skyrim.jsonl"))
+}
+
+func TestRunWatchProfiles_SharedWatchlist(t *testing.T) {
+	profiles := []config.WatchProfile{
+		{Name: "a", Watch: config.Watch{WatchlistFile: "watchlist.json"}},
+		{Name: "b", Watch: config.Watch{WatchlistFile: "watchlist.json"}},
+	}
+
+	err := runWatchProfiles(context.Background(), config.Watch{}, profiles)
+
+	assert.EqualError(t, err, "watch profiles a and b share the watch list watchlist.json, give each its own watchlist-file")
+}
+
+func TestSelectWatchProfiles(t *testing.T) {
+	profiles := []config.WatchProfile{{Name: "fallout"}, {Name: "skyrim"}}
+
+	all, allErr := selectWatchProfiles(profiles, nil)
+	selected, selectedErr := selectWatchProfiles(profiles, []string{"Skyrim"})
+	_, unknownErr := selectWatchProfiles(profiles, []string{"oblivion"})
+
+	require.NoError(t, allErr)
+	require.NoError(t, selectedErr)
+	assert.Equal(t, profiles, all)
+	assert.Equal(t, []config.WatchProfile{{Name: "skyrim"}}, selected)
+	assert.EqualError(t, unknownErr, `unknown watch profile "oblivion"`)
+}
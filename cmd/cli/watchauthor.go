@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/ignore"
+	"github.com/ondrovic/nexus-mods-scraper/internal/snapshots"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// watchAuthorCmd is a Cobra command used for tracking the mods of an author.
+	watchAuthorCmd = &cobra.Command{}
+	// watchAuthorOptions holds the command-line flag values of the watch-author command.
+	watchAuthorOptions = config.WatchAuthor{}
+)
+
+// init initializes the watch-author command with usage, description, and argument
+// validation. It registers the watch-author flags and adds the command to the root command.
+func init() {
+	watchAuthorCmd = &cobra.Command{
+		Use:               "watch-author <game name> <author> [flags]",
+		Short:             "Track an author's mods",
+		Long:              "Detect new uploads and version bumps across all of an author's mods for a game since the last run, and record them in the snapshot store",
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: completeGameArg,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			wc, err := config.LoadWatchAuthor(cmd)
+			if err != nil {
+				return err
+			}
+
+			client, err := httpclient.NewClient(wc.BaseUrl, wc.CookieDirectory, wc.CookieFile, wc.CookieHeader)
+			if err != nil {
+				return err
+			}
+
+			return watchAuthor(wc, args[0], args[1], fetchUserModsFunc, fetchModPageFunc, fetchers.WithClient(client, fetchDocumentFunc))
+		},
+	}
+
+	config.RegisterWatchAuthorFlags(watchAuthorCmd, &watchAuthorOptions)
+	RootCmd.AddCommand(watchAuthorCmd)
+}
+
+// watchAuthor lists the author's mods for the game, reads the current version of each
+// from its mod page, and compares them with the last snapshot of the author. New
+// uploads and version bumps are displayed as JSON and the snapshot is replaced along
+// with the changes found. The first run only records a baseline. A mod whose page
+// can't be scraped keeps its previously recorded version; the snapshot is still saved
+// and an error is returned once all mods have been checked. Mods on the ignore list
+// aren't scraped: they keep their recorded version, and new ones aren't recorded.
+func watchAuthor(
+	wc config.WatchAuthor,
+	game, author string,
+	fetchUserModsFunc func(ctx context.Context, baseUrl, username string, maxPages int, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error),
+	fetchModPageFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) error {
+	game = strings.ToLower(game)
+	store := snapshots.NewStore(wc.SnapshotDirectory)
+
+	previous, found, err := store.LoadAuthor(game, author)
+	if err != nil {
+		return err
+	}
+	lastVersions := make(map[int64]string, len(previous.Mods))
+	for _, mod := range previous.Mods {
+		lastVersions[mod.ModID] = mod.Version
+	}
+
+	ignored := ignore.List{}
+	if wc.IgnoreFile != "" {
+		if ignored, err = ignore.Load(wc.IgnoreFile); err != nil {
+			return err
+		}
+	}
+
+	profile, err := fetchUserModsFunc(context.Background(), wc.BaseUrl, author, wc.MaxPages, fetchDocumentFunc)
+	if err != nil {
+		return fmt.Errorf("error scraping user %s: %w", author, err)
+	}
+
+	var (
+		current  []snapshots.AuthorMod
+		failed   int
+		firstErr error
+	)
+	for _, userMod := range profile.Mods {
+		if strings.ToLower(userMod.Game) != game {
+			continue
+		}
+
+		mod := snapshots.AuthorMod{ModID: userMod.ModID, Name: userMod.Name, Url: userMod.Url, Version: lastVersions[userMod.ModID]}
+		if entry, ok := ignored.Find(game, userMod.ModID); ok {
+			fmt.Fprintln(formatters.Status(), ignoredNote(entry))
+			if _, recorded := lastVersions[userMod.ModID]; recorded {
+				current = append(current, mod)
+			}
+			continue
+		}
+		page, err := fetchAuthorModPage(wc, game, userMod.ModID, fetchModPageFunc, fetchDocumentFunc)
+		if err != nil {
+			fmt.Fprintf(formatters.Status(), "Error scraping modID %d: %v\n", userMod.ModID, err)
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+		} else {
+			mod.Version = page.Version
+			if mod.Version == "" {
+				mod.Version = page.LatestVersion
+			}
+		}
+		current = append(current, mod)
+	}
+
+	snapshot := snapshots.AuthorSnapshot{Author: author, CheckedAt: time.Now(), Game: game, Mods: current}
+	if found {
+		snapshot.Changes = snapshots.DiffAuthor(previous.Mods, current)
+	}
+
+	savedPath, err := store.SaveAuthor(snapshot)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !found:
+		fmt.Fprintf(formatters.Status(), "Recorded %d %s mods of %s as the baseline in %s\n", len(current), game, author, savedPath)
+	case len(snapshot.Changes) == 0:
+		fmt.Fprintf(formatters.Status(), "No changes to the %s mods of %s since %s\n", game, author, previous.CheckedAt.Format(time.RFC3339))
+	default:
+		jsonData, err := json.MarshalIndent(snapshot.Changes, "", "    ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal changes: %w", err)
+		}
+		if err := formatters.PrintPrettyJson(string(jsonData)); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return &failures.RunError{Failed: failed, First: firstErr, Succeeded: len(current) - failed, Total: len(current)}
+	}
+	return nil
+}
+
+// fetchAuthorModPage scrapes the main page of one of the author's mods within the
+// per-mod timeout.
+func fetchAuthorModPage(
+	wc config.WatchAuthor,
+	game string,
+	modID int64,
+	fetchModPageFunc func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error),
+	fetchDocumentFunc func(ctx context.Context, targetURL string) (*goquery.Document, error),
+) (types.ModInfo, error) {
+	slug, id, err := parseMod(game, modID)
+	if err != nil {
+		return types.ModInfo{}, err
+	}
+	ctx, cancel := modContext(context.Background(), wc.PerModTimeout)
+	defer cancel()
+	return fetchModPageFunc(ctx, wc.BaseUrl, slug, id, fetchDocumentFunc)
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/config"
+	"github.com/ondrovic/nexus-mods-scraper/internal/ignore"
+	"github.com/ondrovic/nexus-mods-scraper/internal/snapshots"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatchAuthor_RecordsChanges(t *testing.T) {
+	// Arrange
+	wc := config.WatchAuthor{BaseUrl: "https://example.com", SnapshotDirectory: t.TempDir()}
+	userMods := []types.UserMod{
+		{Game: "skyrim", ModID: 1, Name: "One"},
+		{Game: "fallout4", ModID: 9, Name: "Other game"},
+	}
+	versions := map[int64]string{1: "1.0", 2: "2.0"}
+	fetchUserMods := func(_ context.Context, _, username string, _ int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error) {
+		return types.UserProfile{Username: username, Mods: userMods}, nil
+	}
+	fetchModPage := func(_ context.Context, _ string, game types.GameSlug, modId types.ModID, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error) {
+		return types.ModInfo{ModID: modId.Int64(), Version: versions[modId.Int64()]}, nil
+	}
+
+	// Act
+	require.NoError(t, watchAuthor(wc, "Skyrim", "author", fetchUserMods, fetchModPage, nil))
+	userMods = append(userMods, types.UserMod{Game: "skyrim", ModID: 2, Name: "Two"})
+	versions[1] = "1.1"
+	err := watchAuthor(wc, "Skyrim", "author", fetchUserMods, fetchModPage, nil)
+
+	// Assert
+	require.NoError(t, err)
+	snapshot, found, err := snapshots.NewStore(wc.SnapshotDirectory).LoadAuthor("skyrim", "author")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Len(t, snapshot.Mods, 2)
+	assert.Equal(t, []snapshots.AuthorChange{
+		{Kind: snapshots.ChangeUpdated, ModID: 1, Name: "One", PreviousVersion: "1.0", Version: "1.1"},
+		{Kind: snapshots.ChangeNew, ModID: 2, Name: "Two", Version: "2.0"},
+	}, snapshot.Changes)
+}
+
+func TestWatchAuthor_KeepsVersionOfFailedMods(t *testing.T) {
+	// Arrange
+	wc := config.WatchAuthor{SnapshotDirectory: t.TempDir()}
+	store := snapshots.NewStore(wc.SnapshotDirectory)
+	_, err := store.SaveAuthor(snapshots.AuthorSnapshot{Author: "author", Game: "skyrim", Mods: []snapshots.AuthorMod{{ModID: 1, Version: "1.0"}}})
+	require.NoError(t, err)
+
+	fetchUserMods := func(_ context.Context, _, username string, _ int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error) {
+		return types.UserProfile{Mods: []types.UserMod{{Game: "skyrim", ModID: 1}}}, nil
+	}
+	fetchModPage := func(_ context.Context, _ string, _ types.GameSlug, _ types.ModID, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error) {
+		return types.ModInfo{}, errors.New("boom")
+	}
+
+	// Act
+	err = watchAuthor(wc, "skyrim", "author", fetchUserMods, fetchModPage, nil)
+
+	// Assert
+	assert.EqualError(t, err, "1 of 1 mods failed to scrape")
+	snapshot, _, err := store.LoadAuthor("skyrim", "author")
+	require.NoError(t, err)
+	assert.Equal(t, "1.0", snapshot.Mods[0].Version)
+	assert.Empty(t, snapshot.Changes)
+}
+
+func TestWatchAuthor_SkipsIgnoredMods(t *testing.T) {
+	// Arrange: mod 1 is recorded and mod 3 is a new upload, both ignored
+	tempDir := t.TempDir()
+	wc := config.WatchAuthor{IgnoreFile: filepath.Join(tempDir, ignore.DefaultFilename), SnapshotDirectory: tempDir}
+	store := snapshots.NewStore(wc.SnapshotDirectory)
+	_, err := store.SaveAuthor(snapshots.AuthorSnapshot{Author: "author", Game: "skyrim", Mods: []snapshots.AuthorMod{{ModID: 1, Version: "1.0"}, {ModID: 2, Version: "1.0"}}})
+	require.NoError(t, err)
+	require.NoError(t, ignore.Save(wc.IgnoreFile, ignore.List{{Game: "skyrim", ModID: 1}, {Game: "skyrim", ModID: 3}}))
+
+	fetchUserMods := func(_ context.Context, _, username string, _ int, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error) {
+		return types.UserProfile{Mods: []types.UserMod{{Game: "skyrim", ModID: 1}, {Game: "skyrim", ModID: 2}, {Game: "skyrim", ModID: 3}}}, nil
+	}
+	var fetched []int64
+	fetchModPage := func(_ context.Context, _ string, _ types.GameSlug, modId types.ModID, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error) {
+		fetched = append(fetched, modId.Int64())
+		return types.ModInfo{ModID: modId.Int64(), Version: "2.0"}, nil
+	}
+
+	// Act
+	err = watchAuthor(wc, "skyrim", "author", fetchUserMods, fetchModPage, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []int64{2}, fetched)
+	snapshot, _, err := store.LoadAuthor("skyrim", "author")
+	require.NoError(t, err)
+	assert.Equal(t, []snapshots.AuthorMod{{ModID: 1, Version: "1.0"}, {ModID: 2, Version: "2.0"}}, snapshot.Mods)
+	assert.Equal(t, []snapshots.AuthorChange{{Kind: snapshots.ChangeUpdated, ModID: 2, PreviousVersion: "1.0", Version: "2.0"}}, snapshot.Changes)
+}
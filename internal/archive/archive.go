@@ -0,0 +1,281 @@
+package archive
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/snapshots"
+	"github.com/ondrovic/nexus-mods-scraper/internal/summary"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+const (
+	// clockSkew is how far in the future a timestamp may be before it is reported.
+	clockSkew = 24 * time.Hour
+	// gameInfoFilename is the game metadata saved next to the mods by game-info.
+	gameInfoFilename = "game-info.json"
+	// tagsFilename is the game taxonomy saved next to the mods by tags.
+	tagsFilename = "tags.json"
+)
+
+// earliestTimestamp is the earliest plausible date of a mod upload or update.
+var earliestTimestamp = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// skippedDirs are the directories of the output directory that don't hold the saved
+// mods of a game.
+var skippedDirs = map[string]bool{
+	"users":                  true,
+	snapshots.DefaultDirname: true,
+}
+
+// Issue is an inconsistency found in a saved mod file. When the file only needs to be
+// moved or renamed to be consistent, RepairPath holds the path it should have.
+type Issue struct {
+	Path       string `json:"Path"`
+	Problem    string `json:"Problem"`
+	RepairPath string `json:"RepairPath,omitempty"`
+}
+
+// Repairable reports whether the issue can be fixed by moving the file.
+func (i Issue) Repairable() bool {
+	return i.RepairPath != ""
+}
+
+// ModFile is a saved mod file of an output directory, along with the game directory
+// it is stored in.
+type ModFile struct {
+	Game string
+	Path string
+}
+
+// Report is the outcome of verifying an output directory.
+type Report struct {
+	Checked int     `json:"Checked"`
+	Issues  []Issue `json:"Issues,omitempty"`
+}
+
+// Verify checks every saved mod file in dir, laid out as <dir>/<game>/<name> <id>.json,
+// without modifying anything. Each file must decode into the results schema without
+// unknown fields, name a mod ID and name matching its file name, belong to the game
+// directory it is saved in, and carry timestamps that are set and plausible relative to
+// now. The summary index of each game, when there is one, must only list saved files
+// of the mods they hold. Game metadata, collections, user reports and snapshots are not
+// checked. Returns an error only if the directory can't be read.
+func Verify(dir string, now time.Time) (Report, error) {
+	var report Report
+
+	files, err := ModFiles(dir)
+	if err != nil {
+		return report, err
+	}
+
+	games := map[string]bool{}
+	var gameOrder []string
+	for _, file := range files {
+		report.Checked++
+		report.Issues = append(report.Issues, verifyFile(dir, file.Game, file.Path, now)...)
+		if !games[file.Game] {
+			games[file.Game] = true
+			gameOrder = append(gameOrder, file.Game)
+		}
+	}
+
+	for _, game := range gameOrder {
+		report.Issues = append(report.Issues, verifySummary(filepath.Join(dir, game))...)
+	}
+
+	return report, nil
+}
+
+// ModFiles lists the saved mod files of the output directory dir, laid out as
+// <dir>/<game>/<name> <id>.json, sorted by game and file name. Game metadata and tags, summary
+// indexes, collections, user reports and snapshots are left out. Returns an error if the
+// directory can't be read.
+func ModFiles(dir string) ([]ModFile, error) {
+	games, err := fsys.Default.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading output directory: %w", err)
+	}
+
+	var modFiles []ModFile
+	for _, game := range games {
+		if !game.IsDir() || skippedDirs[game.Name()] {
+			continue
+		}
+
+		gameDir := filepath.Join(dir, game.Name())
+		files, err := fsys.Default.ReadDir(gameDir)
+		if err != nil {
+			return nil, fmt.Errorf("error reading output directory: %w", err)
+		}
+
+		for _, file := range files {
+			if file.IsDir() || file.Name() == gameInfoFilename || file.Name() == tagsFilename || file.Name() == summary.Filename || filepath.Ext(file.Name()) != ".json" {
+				continue
+			}
+			modFiles = append(modFiles, ModFile{Game: game.Name(), Path: filepath.Join(gameDir, file.Name())})
+		}
+	}
+
+	return modFiles, nil
+}
+
+// verifyFile returns the issues found in the saved mod file at path.
+func verifyFile(dir, game, path string, now time.Time) []Issue {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return []Issue{{Path: path, Problem: fmt.Sprintf("can't be read: %v", err)}}
+	}
+
+	var results types.Results
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&results); err != nil {
+		return []Issue{{Path: path, Problem: fmt.Sprintf("doesn't match the results schema: %v", err)}}
+	}
+
+	mod := results.Mods
+	var issues []Issue
+	if mod.ModID <= 0 {
+		issues = append(issues, Issue{Path: path, Problem: "ModID is missing"})
+	}
+	if mod.Name == "" {
+		issues = append(issues, Issue{Path: path, Problem: "Name is missing"})
+	}
+	if len(issues) == 0 {
+		if issue, ok := verifyLocation(dir, game, path, mod); !ok {
+			issues = append(issues, issue)
+		}
+	}
+
+	for _, problem := range verifyTimestamps(mod, now) {
+		issues = append(issues, Issue{Path: path, Problem: problem})
+	}
+	return issues
+}
+
+// verifySummary returns the issues found in the summary index of the game directory:
+// entries whose file is missing or holds a different mod. A game without a summary
+// has no issues.
+func verifySummary(gameDir string) []Issue {
+	path := filepath.Join(gameDir, summary.Filename)
+	if _, err := fsys.Default.Stat(path); err != nil {
+		return nil
+	}
+
+	index, err := summary.Load(path)
+	if err != nil {
+		return []Issue{{Path: path, Problem: fmt.Sprintf("can't be read: %v", err)}}
+	}
+
+	var issues []Issue
+	for _, entry := range index.Mods {
+		data, err := fsys.Default.ReadFile(filepath.Join(gameDir, entry.File))
+		if err != nil {
+			issues = append(issues, Issue{Path: path, Problem: fmt.Sprintf("lists missing file %q for mod %d", entry.File, entry.ModID)})
+			continue
+		}
+
+		var results types.Results
+		if err := json.Unmarshal(data, &results); err == nil && results.Mods.ModID != entry.ModID {
+			issues = append(issues, Issue{Path: path, Problem: fmt.Sprintf("lists file %q for mod %d but it holds mod %d", entry.File, entry.ModID, results.Mods.ModID)})
+		}
+	}
+	return issues
+}
+
+// verifyLocation checks that the file is named after the mod name and ID, as the
+// scrape command saves it with the default path template and naming, and is stored in the directory of the game its URL points
+// to. Snapshots keep the timestamp ending their name. It returns a repairable issue
+// when the file belongs elsewhere.
+func verifyLocation(dir, game, path string, mod types.ModInfo) (Issue, bool) {
+	expectedGame := game
+	if urlGame, _, err := formatters.ParseModUrl(mod.Url); err == nil && mod.Url != "" {
+		expectedGame = strings.ToLower(urlGame)
+	}
+
+	slug, err := types.ParseGameSlug(expectedGame)
+	if err != nil {
+		return Issue{Path: path, Problem: err.Error()}, false
+	}
+
+	expectedPath := filepath.Join(dir, exporters.RenderPathTemplate(exporters.DefaultPathTemplate, slug, mod, exporters.FilenamePolicy{}))
+	if _, at, ok := exporters.ParseSnapshotFilename(filepath.Base(path)); ok {
+		expectedName := strings.TrimSuffix(filepath.Base(expectedPath), ".json")
+		expectedPath = filepath.Join(filepath.Dir(expectedPath), exporters.SnapshotFilename(expectedName, at)+".json")
+	}
+	if expectedPath == path {
+		return Issue{}, true
+	}
+
+	problem := fmt.Sprintf("file name doesn't match mod %q (%d)", mod.Name, mod.ModID)
+	if expectedGame != game {
+		problem = fmt.Sprintf("mod %d belongs to game %s, not %s", mod.ModID, expectedGame, game)
+	}
+	return Issue{Path: path, Problem: problem, RepairPath: expectedPath}, false
+}
+
+// verifyTimestamps returns a description of every timestamp of the mod that is missing
+// or implausible: in the future, before 2001, or with the original upload after the
+// last update.
+func verifyTimestamps(mod types.ModInfo, now time.Time) []string {
+	var problems []string
+	latest := now.Add(clockSkew)
+
+	switch {
+	case mod.LastChecked.IsZero():
+		problems = append(problems, "LastChecked is missing")
+	case mod.LastChecked.After(latest):
+		problems = append(problems, "LastChecked is in the future")
+	}
+
+	timestamps := []struct {
+		name  string
+		value *types.Timestamp
+	}{
+		{"LastUpdatedAt", mod.LastUpdatedAt},
+		{"OriginalUploadAt", mod.OriginalUploadAt},
+	}
+	for _, timestamp := range timestamps {
+		if timestamp.value == nil {
+			continue
+		}
+		if timestamp.value.Before(earliestTimestamp) || timestamp.value.After(latest) {
+			problems = append(problems, fmt.Sprintf("%s is out of range: %s", timestamp.name, timestamp.value.Format(time.RFC3339)))
+		}
+	}
+
+	if mod.LastUpdatedAt != nil && mod.OriginalUploadAt != nil && mod.OriginalUploadAt.After(mod.LastUpdatedAt.Time) {
+		problems = append(problems, "OriginalUploadAt is after LastUpdatedAt")
+	}
+
+	return problems
+}
+
+// Repair moves the file of a repairable issue to its expected path. It refuses to
+// overwrite an existing file. Returns an error if the issue isn't repairable or the
+// file can't be moved.
+func Repair(issue Issue) error {
+	if !issue.Repairable() {
+		return fmt.Errorf("%s can't be repaired: %s", issue.Path, issue.Problem)
+	}
+
+	if _, err := fsys.Default.Stat(issue.RepairPath); err == nil {
+		return fmt.Errorf("can't move %s: %s already exists", issue.Path, issue.RepairPath)
+	}
+	if err := fsys.Default.MkdirAll(filepath.Dir(issue.RepairPath), os.ModePerm); err != nil {
+		return err
+	}
+	if err := fsys.Default.Rename(issue.Path, issue.RepairPath); err != nil {
+		return fmt.Errorf("error moving %s: %w", issue.Path, err)
+	}
+	return nil
+}
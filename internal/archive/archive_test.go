@@ -0,0 +1,132 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+// writeFiles creates the files on the Default filesystem, creating their directories.
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		require.NoError(t, fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, fsys.Default.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestVerify(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	dir := "data"
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, "skyrim", "skyui 3863.json"):                  `{"Mods":{"ModID":3863,"Name":"SkyUI","LastChecked":"2024-06-01T10:00:00Z"}}`,
+		filepath.Join(dir, "skyrim", "skyui 3863 2024-06-01T10-00.json"): `{"Mods":{"ModID":3863,"Name":"SkyUI","LastChecked":"2024-06-01T10:00:00Z"}}`,
+		filepath.Join(dir, "skyrim", "five 4 2024-06-01T10-00.json"):     `{"Mods":{"ModID":5,"Name":"Five","LastChecked":"2024-06-01T10:00:00Z"}}`,
+		filepath.Join(dir, "skyrim", "renamed.json"):                     `{"Mods":{"ModID":1,"Name":"One","LastChecked":"2024-06-01T10:00:00Z"}}`,
+		filepath.Join(dir, "skyrim", "two 2.json"):                       `{"Mods":{"ModID":2,"Name":"Two","Url":"https://www.nexusmods.com/fallout4/mods/2","LastChecked":"2024-06-01T10:00:00Z"}}`,
+		filepath.Join(dir, "skyrim", "broken 3.json"):                    `{"Mods":{"ModID":3,"Name":"Broken","Unknown":true}}`,
+		filepath.Join(dir, "skyrim", "dates 4.json"):                     `{"Mods":{"ModID":4,"Name":"Dates","LastUpdatedAt":"1990-01-01T00:00:00Z"}}`,
+		filepath.Join(dir, "skyrim", "game-info.json"):                   `{"Name":"Skyrim"}`,
+		filepath.Join(dir, "skyrim", "tags.json"):                        `{"Game":"skyrim"}`,
+		filepath.Join(dir, "skyrim", "collections", "abc.json"):          `{"Slug":"abc"}`,
+		filepath.Join(dir, "users", "author.json"):                       `{"Username":"author"}`,
+	})
+
+	// Act
+	report, err := Verify(dir, now)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 7, report.Checked)
+	assert.ElementsMatch(t, []Issue{
+		{Path: filepath.Join(dir, "skyrim", "broken 3.json"), Problem: `doesn't match the results schema: json: unknown field "Unknown"`},
+		{Path: filepath.Join(dir, "skyrim", "dates 4.json"), Problem: "LastChecked is missing"},
+		{Path: filepath.Join(dir, "skyrim", "five 4 2024-06-01T10-00.json"), Problem: `file name doesn't match mod "Five" (5)`, RepairPath: filepath.Join(dir, "skyrim", "five 5 2024-06-01T10-00.json")},
+		{Path: filepath.Join(dir, "skyrim", "dates 4.json"), Problem: "LastUpdatedAt is out of range: 1990-01-01T00:00:00Z"},
+		{Path: filepath.Join(dir, "skyrim", "renamed.json"), Problem: `file name doesn't match mod "One" (1)`, RepairPath: filepath.Join(dir, "skyrim", "one 1.json")},
+		{Path: filepath.Join(dir, "skyrim", "two 2.json"), Problem: "mod 2 belongs to game fallout4, not skyrim", RepairPath: filepath.Join(dir, "fallout4", "two 2.json")},
+	}, report.Issues)
+}
+
+func TestVerify_Summary(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	dir := "data"
+	summaryPath := filepath.Join(dir, "skyrim", "summary.json")
+	writeFiles(t, map[string]string{
+		filepath.Join(dir, "skyrim", "one 1.json"): `{"Mods":{"ModID":1,"Name":"One","LastChecked":"2024-06-01T10:00:00Z"}}`,
+		summaryPath: `{"Game":"skyrim","Mods":[{"File":"one 1.json","ModID":1},{"File":"one 1.json","ModID":5},{"File":"gone 2.json","ModID":2}]}`,
+	})
+
+	// Act
+	report, err := Verify(dir, now)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, report.Checked)
+	assert.Equal(t, []Issue{
+		{Path: summaryPath, Problem: `lists file "one 1.json" for mod 5 but it holds mod 1`},
+		{Path: summaryPath, Problem: `lists missing file "gone 2.json" for mod 2`},
+	}, report.Issues)
+}
+
+func TestVerify_MissingDirectory(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+
+	_, err := Verify("missing", now)
+
+	assert.Error(t, err)
+}
+
+func TestVerifyTimestamps_UploadAfterUpdate(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	path := filepath.Join("data", "skyrim", "one 1.json")
+	writeFiles(t, map[string]string{
+		path: `{"Mods":{"ModID":1,"Name":"One","LastChecked":"2030-01-01T00:00:00Z","LastUpdatedAt":"2020-01-01T00:00:00Z","OriginalUploadAt":"2021-01-01T00:00:00Z"}}`,
+	})
+
+	// Act
+	report, err := Verify("data", now)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Issue{
+		{Path: path, Problem: "LastChecked is in the future"},
+		{Path: path, Problem: "OriginalUploadAt is after LastUpdatedAt"},
+	}, report.Issues)
+}
+
+func TestRepair(t *testing.T) {
+	// Arrange
+	memFS := fsys.NewMemFS()
+	defer fsys.Use(memFS)()
+	writeFiles(t, map[string]string{
+		filepath.Join("data", "skyrim", "two 2.json"): "{}",
+		filepath.Join("data", "skyrim", "taken.json"): "{}",
+		filepath.Join("data", "skyrim", "one 1.json"): "{}",
+	})
+
+	// Act
+	moveErr := Repair(Issue{Path: filepath.Join("data", "skyrim", "two 2.json"), RepairPath: filepath.Join("data", "fallout4", "two 2.json")})
+	existsErr := Repair(Issue{Path: filepath.Join("data", "skyrim", "taken.json"), RepairPath: filepath.Join("data", "skyrim", "one 1.json")})
+	unrepairableErr := Repair(Issue{Path: "x.json", Problem: "ModID is missing"})
+
+	// Assert
+	assert.NoError(t, moveErr)
+	assert.ErrorContains(t, existsErr, "already exists")
+	assert.EqualError(t, unrepairableErr, "x.json can't be repaired: ModID is missing")
+	assert.Equal(t, []string{
+		filepath.Join("data", "fallout4", "two 2.json"),
+		filepath.Join("data", "skyrim", "one 1.json"),
+		filepath.Join("data", "skyrim", "taken.json"),
+	}, memFS.Files())
+}
@@ -0,0 +1,360 @@
+package compat
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// pluginExtensions are the extensions of the plugins listed in a plugins.txt, dropped
+// from their names to match them against the mods.
+var pluginExtensions = []string{".esp", ".esm", ".esl"}
+
+// Installed is a mod of a mod list. Version and ModID are only known for exports that
+// list them, e.g. a Vortex CSV export.
+type Installed struct {
+	ModID   int64  `json:"modId,omitempty"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// Missing is a Nexus requirement of an installed mod that the mod list doesn't hold.
+type Missing struct {
+	Mod         string `json:"mod"`
+	ModID       int64  `json:"modId"`
+	Notes       string `json:"notes,omitempty"`
+	Requirement string `json:"requirement"`
+	Url         string `json:"url,omitempty"`
+}
+
+// Outdated is an installed mod whose version is older than the latest one saved.
+type Outdated struct {
+	Installed string `json:"installed"`
+	Latest    string `json:"latest"`
+	ModID     int64  `json:"modId"`
+	Name      string `json:"name"`
+	Url       string `json:"url,omitempty"`
+}
+
+// Result is the outcome of checking a mod list against the saved mods. Unknown lists
+// the installed mods none of the saved mods matches, whose requirements and versions
+// couldn't be checked.
+type Result struct {
+	Checked  int        `json:"checked"`
+	Missing  []Missing  `json:"missing"`
+	Outdated []Outdated `json:"outdated"`
+	Unknown  []string   `json:"unknown"`
+}
+
+// ReadModList reads the mod list at path, see ParseModList. Returns an error if the
+// file can't be read or parsed.
+func ReadModList(path string) ([]Installed, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading mod list: %w", err)
+	}
+	mods, err := ParseModList(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing mod list %s: %w", filepath.Base(path), err)
+	}
+	return mods, nil
+}
+
+// ParseModList parses a mod list exported by a mod manager: a Mod Organizer 2
+// modlist.txt, whose "+" entries are enabled and "-" ones disabled and skipped along
+// with separators, a plugins.txt, whose plugins are named without their extension,
+// or a CSV export, e.g. from Vortex, with a header naming its name, version and mod ID
+// columns. Blank lines and "#" comments are ignored. Returns an error if the list
+// can't be read or a CSV export can't be parsed.
+func ParseModList(r io.Reader) ([]Installed, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	lines := strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if strings.Contains(line, ",") && csvHeader(line) {
+			return parseCsv(data)
+		}
+		break
+	}
+
+	var mods []Installed
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch line[0] {
+		case '-':
+			continue
+		case '+', '*':
+			line = strings.TrimSpace(line[1:])
+		}
+		if strings.HasSuffix(line, "_separator") {
+			continue
+		}
+		for _, ext := range pluginExtensions {
+			if strings.HasSuffix(strings.ToLower(line), ext) {
+				line = line[:len(line)-len(ext)]
+				break
+			}
+		}
+		if line != "" {
+			mods = append(mods, Installed{Name: line})
+		}
+	}
+	return mods, nil
+}
+
+// csvHeader reports whether the line is the header of a CSV export, naming a column
+// after the mod name.
+func csvHeader(line string) bool {
+	for _, column := range strings.Split(line, ",") {
+		if csvColumn(column) == "name" {
+			return true
+		}
+	}
+	return false
+}
+
+// csvColumn returns the field a CSV export column holds: "name", "version" or "modid",
+// or "" for the columns that aren't read.
+func csvColumn(header string) string {
+	switch normalize(header) {
+	case "name", "modname", "displayname":
+		return "name"
+	case "version", "modversion", "installedversion":
+		return "version"
+	case "modid", "nexusid", "id":
+		return "modid"
+	}
+	return ""
+}
+
+// parseCsv parses a CSV export whose first record is its header. Rows without a name
+// are skipped and mod IDs that aren't numbers are ignored.
+func parseCsv(data []byte) ([]Installed, error) {
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	columns := map[string]int{}
+	for i, header := range records[0] {
+		if field := csvColumn(header); field != "" {
+			if _, ok := columns[field]; !ok {
+				columns[field] = i
+			}
+		}
+	}
+	value := func(record []string, field string) string {
+		i, ok := columns[field]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var mods []Installed
+	for _, record := range records[1:] {
+		mod := Installed{Name: value(record, "name"), Version: value(record, "version")}
+		if mod.Name == "" {
+			continue
+		}
+		if id, err := strconv.ParseInt(value(record, "modid"), 10, 64); err == nil {
+			mod.ModID = id
+		}
+		mods = append(mods, mod)
+	}
+	return mods, nil
+}
+
+// Check matches the installed mods against the saved ones, by mod ID when the mod list
+// has it and by name otherwise, ignoring case, spaces and punctuation. For each match
+// it reports the Nexus requirements of the saved mod that no installed mod satisfies,
+// and whether the installed version is older than the latest one saved. Requirements
+// are satisfied by an installed mod of the same ID, taken from their link, or name.
+func Check(installed []Installed, saved []types.ModInfo) Result {
+	result := Result{Missing: []Missing{}, Outdated: []Outdated{}, Unknown: []string{}}
+
+	byID := map[int64]types.ModInfo{}
+	byName := map[string]types.ModInfo{}
+	for _, mod := range saved {
+		byID[mod.ModID] = mod
+		if name := normalize(mod.Name); name != "" {
+			byName[name] = mod
+		}
+	}
+
+	installedIDs := map[int64]bool{}
+	installedNames := map[string]bool{}
+	matches := make([]types.ModInfo, len(installed))
+	matched := make([]bool, len(installed))
+	for i, mod := range installed {
+		installedNames[normalize(mod.Name)] = true
+		if mod.ModID != 0 {
+			matches[i], matched[i] = byID[mod.ModID]
+		}
+		if !matched[i] {
+			matches[i], matched[i] = byName[normalize(mod.Name)]
+		}
+		if matched[i] {
+			installedIDs[matches[i].ModID] = true
+			installedNames[normalize(matches[i].Name)] = true
+		} else if mod.ModID != 0 {
+			installedIDs[mod.ModID] = true
+		}
+	}
+
+	reported := map[string]bool{}
+	for i, mod := range installed {
+		if !matched[i] {
+			result.Unknown = append(result.Unknown, mod.Name)
+			continue
+		}
+		result.Checked++
+		match := matches[i]
+
+		for _, requirement := range match.Dependencies {
+			if _, id, err := formatters.ParseModUrl(requirement.Url); err == nil && installedIDs[id] {
+				continue
+			}
+			if installedNames[normalize(requirement.Name)] {
+				continue
+			}
+			key := fmt.Sprintf("%d/%s", match.ModID, normalize(requirement.Name))
+			if reported[key] {
+				continue
+			}
+			reported[key] = true
+			result.Missing = append(result.Missing, Missing{
+				Mod:         match.Name,
+				ModID:       match.ModID,
+				Notes:       requirement.Notes,
+				Requirement: requirement.Name,
+				Url:         requirement.Url,
+			})
+		}
+
+		latest := match.LatestVersion
+		if latest == "" {
+			latest = match.Version
+		}
+		if mod.Version != "" && latest != "" && CompareVersions(mod.Version, latest) < 0 {
+			result.Outdated = append(result.Outdated, Outdated{
+				Installed: mod.Version,
+				Latest:    latest,
+				ModID:     match.ModID,
+				Name:      match.Name,
+				Url:       match.Url,
+			})
+		}
+	}
+
+	sort.SliceStable(result.Missing, func(i, j int) bool {
+		return strings.ToLower(result.Missing[i].Mod) < strings.ToLower(result.Missing[j].Mod)
+	})
+	sort.SliceStable(result.Outdated, func(i, j int) bool {
+		return strings.ToLower(result.Outdated[i].Name) < strings.ToLower(result.Outdated[j].Name)
+	})
+	return result
+}
+
+// CompareVersions compares two mod versions part by part, e.g. "1.10" is newer than
+// "1.9" and "2.0.1" newer than "2.0", ignoring a leading "v" and case. Numeric parts
+// are compared as numbers and other parts as text. Returns -1 if a is older than b, 1
+// if it is newer, and 0 if they are the same.
+func CompareVersions(a, b string) int {
+	partsA, partsB := versionParts(a), versionParts(b)
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		// A missing part counts as zero, so that "2.0" and "2" are the same version
+		partA, partB := "0", "0"
+		if i < len(partsA) {
+			partA = partsA[i]
+		}
+		if i < len(partsB) {
+			partB = partsB[i]
+		}
+
+		numA, errA := strconv.ParseInt(partA, 10, 64)
+		numB, errB := strconv.ParseInt(partB, 10, 64)
+		switch {
+		case errA == nil && errB == nil:
+			if numA != numB {
+				if numA < numB {
+					return -1
+				}
+				return 1
+			}
+		case partA != partB:
+			if partA < partB {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+// versionParts splits the version into its lowercase parts, at separators and where
+// digits and letters meet, e.g. "v1.2b" becomes 1, 2 and b.
+func versionParts(version string) []string {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+
+	var (
+		parts   []string
+		current []rune
+	)
+	flush := func() {
+		if len(current) > 0 {
+			parts = append(parts, string(current))
+			current = nil
+		}
+	}
+	for _, r := range version {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case len(current) > 0 && unicode.IsDigit(r) != unicode.IsDigit(current[len(current)-1]):
+			flush()
+			current = append(current, r)
+		default:
+			current = append(current, r)
+		}
+	}
+	flush()
+	return parts
+}
+
+// normalize returns the name lowercased with everything but letters and digits
+// removed, so that "SkyUI_5_2_SE" and "SkyUI 5.2 SE" match.
+func normalize(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package compat
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseModList_ModOrganizer(t *testing.T) {
+	// Arrange
+	list := "# This file was automatically generated by Mod Organizer.\r\n" +
+		"+SkyUI\r\n" +
+		"-Disabled Mod\r\n" +
+		"+Interface_separator\r\n" +
+		"\r\n" +
+		"+Unofficial Skyrim Special Edition Patch\r\n"
+
+	// Act
+	mods, err := ParseModList(strings.NewReader(list))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Installed{{Name: "SkyUI"}, {Name: "Unofficial Skyrim Special Edition Patch"}}, mods)
+}
+
+func TestParseModList_Plugins(t *testing.T) {
+	// Arrange
+	list := "# Automatically generated by Vortex\n*Unofficial Skyrim Special Edition Patch.esp\n*SkyUI_SE.ESP\nAlternate Start.esl\n"
+
+	// Act
+	mods, err := ParseModList(strings.NewReader(list))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Installed{
+		{Name: "Unofficial Skyrim Special Edition Patch"},
+		{Name: "SkyUI_SE"},
+		{Name: "Alternate Start"},
+	}, mods)
+}
+
+func TestParseModList_Csv(t *testing.T) {
+	// Arrange
+	list := "\xef\xbb\xbfMod Name,Version,Mod ID,Source\n" +
+		"SkyUI,5.2,12604,nexus\n" +
+		"\"Patch, Unofficial\",4.2.9,,nexus\n" +
+		",1.0,1,nexus\n"
+
+	// Act
+	mods, err := ParseModList(strings.NewReader(list))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Installed{
+		{ModID: 12604, Name: "SkyUI", Version: "5.2"},
+		{Name: "Patch, Unofficial", Version: "4.2.9"},
+	}, mods)
+}
+
+func TestCheck(t *testing.T) {
+	// Arrange
+	saved := []types.ModInfo{
+		{ModID: 12604, Name: "SkyUI", LatestVersion: "5.2SE", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/12604", Dependencies: []types.Requirement{
+			{Name: "SKSE64", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/30379"},
+			{Name: "Address Library for SKSE Plugins", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/32444"},
+		}},
+		{ModID: 266, Name: "Unofficial Skyrim Special Edition Patch", Version: "4.3.2", Dependencies: []types.Requirement{
+			{Name: "SkyUI", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/12604"},
+		}},
+		{ModID: 32444, Name: "Address Library for SKSE Plugins", Version: "11"},
+	}
+	installed := []Installed{
+		{Name: "skyui", Version: "5.1"},
+		{ModID: 266, Name: "USSEP", Version: "4.3.2"},
+		{Name: "Address_Library for SKSE plugins"},
+		{Name: "Some Local Mod"},
+	}
+
+	// Act
+	result := Check(installed, saved)
+
+	// Assert
+	assert.Equal(t, 3, result.Checked)
+	assert.Equal(t, []Missing{{Mod: "SkyUI", ModID: 12604, Requirement: "SKSE64", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/30379"}}, result.Missing)
+	assert.Equal(t, []Outdated{{Installed: "5.1", Latest: "5.2SE", ModID: 12604, Name: "SkyUI", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/12604"}}, result.Outdated)
+	assert.Equal(t, []string{"Some Local Mod"}, result.Unknown)
+}
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1.9", "1.10", -1},
+		{"2.0.1", "2.0", 1},
+		{"2.0", "2", 0},
+		{"v1.2", "1.2", 0},
+		{"1.2a", "1.2b", -1},
+		{"5.2SE", "5.2se", 0},
+		{"4.3.2", "4.2.9", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			// Act
+			got := CompareVersions(tt.a, tt.b)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,468 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	// EnvPrefix is the prefix of the environment variables read by the commands, e.g.
+	// NEXUS_SCRAPER_BASE_URL for the base-url flag.
+	EnvPrefix = "NEXUS_SCRAPER"
+	// DefaultFilename is the name of the configuration file looked up in the data
+	// storage directory when no --config flag is given.
+	DefaultFilename = "config.yaml"
+	// configFlag is the name of the persistent flag holding the configuration file path.
+	configFlag = "config"
+	// http2Flag, idleConnTimeoutFlag, maxIdleConnsFlag and maxIdleConnsPerHostFlag are
+	// the names of the persistent flags tuning the connections.
+	http2Flag               = "http2"
+	idleConnTimeoutFlag     = "idle-conn-timeout"
+	maxIdleConnsFlag        = "max-idle-conns"
+	maxIdleConnsPerHostFlag = "max-idle-conns-per-host"
+	// caBundleFlag and keepAliveFlag are the names of the persistent flags adding
+	// trusted certificate authorities and tuning the keep-alive of the connections.
+	caBundleFlag  = "ca-bundle"
+	keepAliveFlag = "keep-alive"
+	// connectTimeoutFlag and timeoutFlag are the names of the persistent flags bounding
+	// the connections and the requests.
+	connectTimeoutFlag = "connect-timeout"
+	timeoutFlag        = "timeout"
+	// dryRunFlag is the name of the persistent flag printing the planned requests
+	// instead of making them.
+	dryRunFlag = "dry-run"
+	// localeFlag is the name of the persistent flag setting the language of the pages.
+	localeFlag = "locale"
+	// noColorFlag is the name of the persistent flag turning off the ANSI escape codes.
+	noColorFlag = "no-color"
+	// noSpinnerFlag is the name of the persistent flag turning off the spinner
+	// animation.
+	noSpinnerFlag = "no-spinner"
+	// profileFlag is the name of the persistent flag selecting the profile.
+	profileFlag = "profile"
+	// progressJSONFlag is the name of the persistent flag turning on the JSON progress
+	// events.
+	progressJSONFlag = "progress-json"
+	// quietFlag is the name of the persistent flag keeping stdout for the results.
+	quietFlag = "quiet"
+	// selectorsFlag is the name of the persistent flag holding the selector override
+	// file path.
+	selectorsFlag = "selectors"
+	// usageStatsFlag is the name of the persistent flag opting in to the usage stats.
+	usageStatsFlag = "usage-stats"
+)
+
+var (
+	// configFile holds the value of the persistent --config flag.
+	configFile string
+	// dryRun holds the value of the persistent --dry-run flag.
+	dryRun bool
+	// locale holds the value of the persistent --locale flag.
+	locale string
+	// noColor holds the value of the persistent --no-color flag.
+	noColor bool
+	// noSpinner holds the value of the persistent --no-spinner flag.
+	noSpinner bool
+	// profileName holds the value of the persistent --profile flag.
+	profileName string
+	// progressJSON holds the value of the persistent --progress-json flag.
+	progressJSON bool
+	// quiet holds the value of the persistent --quiet flag.
+	quiet bool
+	// selectorsFile holds the value of the persistent --selectors flag.
+	selectorsFile string
+	// caBundle holds the value of the persistent --ca-bundle flag.
+	caBundle string
+	// transportOptions holds the values of the persistent connection tuning flags.
+	transportOptions httpclient.TransportOptions
+	// usageStats holds the value of the persistent --usage-stats flag.
+	usageStats bool
+)
+
+// RegisterConfigFlag registers the persistent --config flag on the root command so
+// every subcommand can point at an alternate configuration file.
+func RegisterConfigFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&configFile, configFlag, "", fmt.Sprintf("Configuration file (default %s)", DefaultConfigPath()))
+}
+
+// RegisterProfileFlag registers the persistent --profile flag on the root command so
+// every command can run with the cookies, configuration and outputs of a profile.
+func RegisterProfileFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&profileName, profileFlag, "", "Profile whose cookies, configuration and output directory to use, e.g. for a second account (see profile list)")
+}
+
+// ResolveProfile returns the profile selected with the --profile flag or the
+// NEXUS_SCRAPER_PROFILE environment variable, or "" for the default profile. It can't
+// be set in the configuration file, since each profile has its own.
+func ResolveProfile(cmd *cobra.Command) string {
+	if flag := cmd.Flags().Lookup(profileFlag); flag != nil && flag.Changed {
+		return flag.Value.String()
+	}
+	return os.Getenv(EnvPrefix + "_PROFILE")
+}
+
+// ApplyProfile points the flags of cmd whose default is a path in the data storage
+// directory of the default profile, e.g. --cookie-directory or --output-directory, at
+// the same path in the data storage directory of the active profile. Flags set on the
+// command line are left alone, and environment variables and the configuration file
+// still override the defaults. It is called once the profile is activated, since the
+// defaults are computed when the flags are registered.
+func ApplyProfile(cmd *cobra.Command, defaultDir string) error {
+	activeDir := storage.GetDataStoragePath()
+	var err error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		if err != nil || flag.Changed || flag.Value.Type() != "string" {
+			return
+		}
+		rel, relErr := filepath.Rel(defaultDir, flag.DefValue)
+		if relErr != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return
+		}
+		if setErr := flag.Value.Set(filepath.Join(activeDir, rel)); setErr != nil {
+			err = fmt.Errorf("error applying profile to --%s: %w", flag.Name, setErr)
+		}
+	})
+	return err
+}
+
+// RegisterSelectorsFlag registers the persistent --selectors flag on the root command so
+// every command can point at an alternate selector override file.
+func RegisterSelectorsFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&selectorsFile, selectorsFlag, "", fmt.Sprintf("Selector override file, to fix extraction after a site change (default %s)", DefaultSelectorsPath()))
+}
+
+// RegisterTransportFlags registers the persistent flags tuning the connections on the
+// root command: HTTP/2, the number of idle connections kept for reuse and how long they
+// are kept, their keep-alive, the certificate authorities trusted besides the system
+// ones, and the timeouts of connecting and of whole requests.
+func RegisterTransportFlags(cmd *cobra.Command) {
+	defaults := httpclient.DefaultTransportOptions()
+	cmd.PersistentFlags().StringVar(&caBundle, caBundleFlag, "", "PEM file of certificate authorities to trust besides the system ones, e.g. of an intercepting proxy")
+	cmd.PersistentFlags().DurationVar(&transportOptions.ConnectTimeout, connectTimeoutFlag, defaults.ConnectTimeout, "Maximum time to open a connection, TLS handshake included (0 leaves it to the system)")
+	cmd.PersistentFlags().BoolVar(&transportOptions.HTTP2, http2Flag, defaults.HTTP2, "Use HTTP/2 when the server supports it (--http2=false forces HTTP/1.1)")
+	cmd.PersistentFlags().DurationVar(&transportOptions.IdleConnTimeout, idleConnTimeoutFlag, defaults.IdleConnTimeout, "How long an idle connection is kept open for reuse (0 keeps it indefinitely)")
+	cmd.PersistentFlags().DurationVar(&transportOptions.KeepAlive, keepAliveFlag, defaults.KeepAlive, "Interval of the keep-alive probes of open connections (0 disables them and opens a connection per request)")
+	cmd.PersistentFlags().IntVar(&transportOptions.MaxIdleConns, maxIdleConnsFlag, defaults.MaxIdleConns, "Number of idle connections kept open for reuse across all hosts (0 means no limit)")
+	cmd.PersistentFlags().IntVar(&transportOptions.MaxIdleConnsPerHost, maxIdleConnsPerHostFlag, defaults.MaxIdleConnsPerHost, "Number of idle connections kept open for reuse per host")
+	cmd.PersistentFlags().DurationVar(&transportOptions.Timeout, timeoutFlag, defaults.Timeout, "Maximum time of a single request, from connecting to reading the page (0 disables it)")
+}
+
+// LoadTransport resolves the connection tuning of the command from the persistent
+// flags, the environment and the configuration file, loading the CA bundle when one is
+// given. Returns an error if an explicitly requested configuration file or the CA
+// bundle can't be read, or a value is negative.
+func LoadTransport(cmd *cobra.Command) (httpclient.TransportOptions, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return httpclient.TransportOptions{}, err
+	}
+
+	options := httpclient.TransportOptions{
+		ConnectTimeout:      v.GetDuration(connectTimeoutFlag),
+		HTTP2:               v.GetBool(http2Flag),
+		IdleConnTimeout:     v.GetDuration(idleConnTimeoutFlag),
+		KeepAlive:           v.GetDuration(keepAliveFlag),
+		MaxIdleConns:        v.GetInt(maxIdleConnsFlag),
+		MaxIdleConnsPerHost: v.GetInt(maxIdleConnsPerHostFlag),
+		Timeout:             v.GetDuration(timeoutFlag),
+	}
+	if options.IdleConnTimeout < 0 || options.MaxIdleConns < 0 || options.MaxIdleConnsPerHost < 0 {
+		return httpclient.TransportOptions{}, fmt.Errorf("--%s, --%s and --%s must not be negative", idleConnTimeoutFlag, maxIdleConnsFlag, maxIdleConnsPerHostFlag)
+	}
+	if options.ConnectTimeout < 0 || options.Timeout < 0 {
+		return httpclient.TransportOptions{}, fmt.Errorf("--%s and --%s must not be negative", connectTimeoutFlag, timeoutFlag)
+	}
+	if options.KeepAlive < 0 {
+		return httpclient.TransportOptions{}, fmt.Errorf("--%s must not be negative", keepAliveFlag)
+	}
+	if path := v.GetString(caBundleFlag); path != "" {
+		if options.RootCAs, err = httpclient.LoadCABundle(path); err != nil {
+			return httpclient.TransportOptions{}, err
+		}
+	}
+	return options, nil
+}
+
+// RegisterSpinnerFlag registers the persistent --no-spinner flag on the root command
+// so every command can print its progress as plain lines instead of animating it.
+func RegisterSpinnerFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&noSpinner, noSpinnerFlag, false, "Print progress steps as plain lines instead of animated spinners, e.g. for CI logs")
+}
+
+// SpinnerDisabled reports whether the command should print its progress without
+// animating it, as set with the --no-spinner flag, the NEXUS_SCRAPER_NO_SPINNER
+// environment variable or a no-spinner key in the configuration file. Returns an
+// error if an explicitly requested configuration file can't be read.
+func SpinnerDisabled(cmd *cobra.Command) (bool, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return false, err
+	}
+	return v.GetBool(noSpinnerFlag), nil
+}
+
+// RegisterDryRunFlag registers the persistent --dry-run flag on the root command so
+// that the commands supporting it can print what they would fetch and write instead.
+func RegisterDryRunFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&dryRun, dryRunFlag, false, "Print the URLs the command would fetch, the files it would write and where its cookies come from, without making any request")
+}
+
+// DryRunEnabled reports whether the command should only print what it would do, as set
+// with the --dry-run flag, the NEXUS_SCRAPER_DRY_RUN environment variable or a dry-run
+// key in the configuration file. Returns an error if an explicitly requested
+// configuration file can't be read.
+func DryRunEnabled(cmd *cobra.Command) (bool, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return false, err
+	}
+	return v.GetBool(dryRunFlag), nil
+}
+
+// RegisterLocaleFlag registers the persistent --locale flag on the root command so
+// every command can read pages rendered in the language of a non-English account.
+func RegisterLocaleFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&locale, localeFlag, formatters.DefaultLocale, "Language the pages are requested and read in, e.g. de or pt-BR, for accounts set to another language than English")
+	cli.RegisterFlagValues(cmd, localeFlag, formatters.Locales()...)
+}
+
+// LoadLocale resolves the language of the pages from the --locale flag, the
+// NEXUS_SCRAPER_LOCALE environment variable or a locale key in the configuration file.
+// Returns an error if an explicitly requested configuration file can't be read or the
+// language isn't supported.
+func LoadLocale(cmd *cobra.Command) (string, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return "", err
+	}
+
+	value := v.GetString(localeFlag)
+	if err := formatters.ValidateLocale(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// RegisterColorFlag registers the persistent --no-color flag on the root command so
+// every command can print its output without ANSI escape codes.
+func RegisterColorFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&noColor, noColorFlag, false, "Print no ANSI escape codes: no colors, terminal links or spinner animations")
+}
+
+// ColorDisabled reports whether the command should print its output without ANSI
+// escape codes, as set with the --no-color flag, the NEXUS_SCRAPER_NO_COLOR environment
+// variable or a no-color key in the configuration file. Returns an error if an
+// explicitly requested configuration file can't be read.
+func ColorDisabled(cmd *cobra.Command) (bool, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return false, err
+	}
+	return v.GetBool(noColorFlag), nil
+}
+
+// RegisterQuietFlag registers the persistent --quiet flag on the root command so every
+// command can keep stdout for its JSON results, e.g. to pipe them to jq.
+func RegisterQuietFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(&quiet, quietFlag, "q", false, "Write only the JSON results to stdout, and every status line, spinner and error to stderr")
+}
+
+// QuietEnabled reports whether the command should write only its results to stdout, as
+// set with the --quiet flag, the NEXUS_SCRAPER_QUIET environment variable or a quiet key
+// in the configuration file. Returns an error if an explicitly requested configuration
+// file can't be read.
+func QuietEnabled(cmd *cobra.Command) (bool, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return false, err
+	}
+	return v.GetBool(quietFlag), nil
+}
+
+// RegisterProgressFlag registers the persistent --progress-json flag on the root
+// command so every command scraping mods can report its progress as JSON lines.
+func RegisterProgressFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&progressJSON, progressJSONFlag, false, "Write progress events as JSON lines on stderr, e.g. for wrapper GUIs and scripts")
+}
+
+// ProgressJSONEnabled reports whether the command should write its progress events as
+// JSON lines, as set with the --progress-json flag, the NEXUS_SCRAPER_PROGRESS_JSON
+// environment variable or a progress-json key in the configuration file. Returns an
+// error if an explicitly requested configuration file can't be read.
+func ProgressJSONEnabled(cmd *cobra.Command) (bool, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return false, err
+	}
+	return v.GetBool(progressJSONFlag), nil
+}
+
+// RegisterUsageStatsFlag registers the persistent --usage-stats flag on the root command
+// so every command can record its usage in the local usage stats file.
+func RegisterUsageStatsFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVar(&usageStats, usageStatsFlag, false, "Record the usage of each run (mods scraped, bytes fetched, cache hits) in a local file, see stats show")
+}
+
+// UsageStatsEnabled reports whether the command should record its usage, as set with
+// the --usage-stats flag, the NEXUS_SCRAPER_USAGE_STATS environment variable or a
+// usage-stats key in the configuration file. Returns an error if an explicitly
+// requested configuration file can't be read.
+func UsageStatsEnabled(cmd *cobra.Command) (bool, error) {
+	v, err := Load(cmd, cmd.Name())
+	if err != nil {
+		return false, err
+	}
+	return v.GetBool(usageStatsFlag), nil
+}
+
+// DefaultConfigPath returns the location of the configuration file used when neither
+// the --config flag nor the NEXUS_SCRAPER_CONFIG environment variable is set.
+func DefaultConfigPath() string {
+	return filepath.Join(storage.GetDataStoragePath(), DefaultFilename)
+}
+
+// DefaultSelectorsPath returns the location of the selector override file used when
+// neither the --selectors flag nor the NEXUS_SCRAPER_SELECTORS environment variable is
+// set.
+func DefaultSelectorsPath() string {
+	return filepath.Join(storage.GetDataStoragePath(), selectors.DefaultFilename)
+}
+
+// LoadSelectors returns the selectors the extractors should use: the defaults, with the
+// keys set in the selector override file replaced. The file is the one given with the
+// --selectors flag or the NEXUS_SCRAPER_SELECTORS environment variable, or else the
+// default one. A missing default file is not an error; a missing explicit one is.
+func LoadSelectors() (selectors.Selectors, error) {
+	path, explicit := selectorsFile, true
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "_SELECTORS")
+	}
+	if path == "" {
+		path, explicit = DefaultSelectorsPath(), false
+	}
+
+	loaded, err := selectors.Load(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return selectors.Default(), nil
+	}
+	return loaded, err
+}
+
+// Load builds a Viper instance for a single command, layering its flags over
+// environment variables, the configuration file and the flag defaults, in that order
+// of precedence. Top level keys in the configuration file apply to every command and
+// keys under the section named after the command override them. Each command gets its
+// own instance so that flags sharing a name across commands don't collide. Returns an
+// error if an explicitly requested configuration file can't be read.
+func Load(cmd *cobra.Command, section string) (*viper.Viper, error) {
+	v := viper.New()
+	v.SetEnvPrefix(EnvPrefix)
+	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
+	v.AutomaticEnv()
+
+	if err := v.BindPFlags(cmd.Flags()); err != nil {
+		return nil, fmt.Errorf("error binding flags: %w", err)
+	}
+
+	settings, err := readConfigFile(resolveConfigPath(), section)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, fmt.Errorf("error merging configuration: %w", err)
+	}
+
+	return v, nil
+}
+
+// resolveConfigPath returns the configuration file to read and whether it was asked for
+// explicitly, through the --config flag or the NEXUS_SCRAPER_CONFIG environment variable.
+func resolveConfigPath() configPath {
+	if configFile != "" {
+		return configPath{path: configFile, explicit: true}
+	}
+	if path := os.Getenv(EnvPrefix + "_CONFIG"); path != "" {
+		return configPath{path: path, explicit: true}
+	}
+	return configPath{path: DefaultConfigPath()}
+}
+
+// configPath is the location of a configuration file and whether the user asked for it.
+type configPath struct {
+	path     string
+	explicit bool
+}
+
+// readConfigFile reads the configuration file and returns the settings that apply to
+// the given section: the top level keys, overridden by the keys of the section itself.
+// A missing default configuration file is not an error; a missing explicit one is.
+func readConfigFile(cfg configPath, section string) (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
+
+	data, err := fsys.Default.ReadFile(cfg.path)
+	if errors.Is(err, os.ErrNotExist) && !cfg.explicit {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", cfg.path, err)
+	}
+
+	file := viper.New()
+	file.SetConfigType(configType(cfg.path))
+	if err := file.ReadConfig(bytes.NewReader(data)); err != nil {
+		return nil, fmt.Errorf("error reading config file %s: %w", cfg.path, err)
+	}
+
+	for key, value := range file.AllSettings() {
+		if _, isSection := value.(map[string]interface{}); !isSection {
+			settings[key] = value
+		}
+	}
+	if sub := file.Sub(section); sub != nil {
+		for key, value := range sub.AllSettings() {
+			settings[key] = value
+		}
+	}
+
+	return settings, nil
+}
+
+// configType returns the format of the configuration file from its extension,
+// defaulting to YAML.
+func configType(path string) string {
+	if ext := strings.TrimPrefix(filepath.Ext(path), "."); ext != "" {
+		return strings.ToLower(ext)
+	}
+	return "yaml"
+}
+
+// stringSlice returns the string slice stored under key. Values coming from the
+// environment or a scalar in the configuration file are split on commas, matching
+// how the same value is passed on the command line.
+func stringSlice(v *viper.Viper, key string) []string {
+	if value, ok := v.Get(key).(string); ok {
+		if strings.TrimSpace(value) == "" {
+			return []string{}
+		}
+		parts := strings.Split(value, ",")
+		for i := range parts {
+			parts[i] = strings.TrimSpace(parts[i])
+		}
+		return parts
+	}
+	return v.GetStringSlice(key)
+}
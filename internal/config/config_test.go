@@ -0,0 +1,337 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withConfigFile points the loader at path for the duration of the test.
+func withConfigFile(t *testing.T, path string) {
+	t.Helper()
+	previous := configFile
+	configFile = path
+	t.Cleanup(func() { configFile = previous })
+}
+
+func TestLoadScrape_Defaults(t *testing.T) {
+	// Arrange
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	cmd := &cobra.Command{}
+	RegisterScrapeFlags(cmd, &types.CliFlags{})
+
+	// Act
+	sc, err := LoadScrape(cmd)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://nexusmods.com", sc.BaseUrl)
+	assert.Equal(t, "session-cookies.json", sc.CookieFile)
+	assert.Equal(t, []string{"nexusmods_session", "nexusmods_session_refresh"}, sc.ValidCookies)
+	assert.Empty(t, sc.FilterTags)
+	assert.Empty(t, sc.CookieHeader)
+	assert.False(t, sc.DisplayResults)
+	assert.Equal(t, 200, sc.MaxMods)
+	assert.False(t, sc.Yes)
+}
+
+func TestLoadScrape_Precedence(t *testing.T) {
+	// Arrange: the file sets shared and per command values, the environment overrides
+	// one of them and a flag overrides another
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+base-url: https://file.example
+per-mod-timeout: 30s
+scrape:
+  display-results: true
+  filter-tags: [armor, weapons]
+  cookie-filename: from-file.json
+extract:
+  output-filename: extract-only.json
+`), 0644))
+	withConfigFile(t, path)
+	t.Setenv("NEXUS_SCRAPER_COOKIE_FILENAME", "from-env.json")
+	t.Setenv("NEXUS_SCRAPER_FILE_CATEGORIES", "main, optional")
+
+	cmd := &cobra.Command{}
+	RegisterScrapeFlags(cmd, &types.CliFlags{})
+	require.NoError(t, cmd.ParseFlags([]string{"--base-url", "https://flag.example"}))
+
+	// Act
+	sc, err := LoadScrape(cmd)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://flag.example", sc.BaseUrl)
+	assert.Equal(t, "from-env.json", sc.CookieFile)
+	assert.Equal(t, 30*time.Second, sc.PerModTimeout)
+	assert.True(t, sc.DisplayResults)
+	assert.Equal(t, []string{"armor", "weapons"}, sc.FilterTags)
+	assert.Equal(t, []string{"main", "optional"}, sc.FileCategories)
+}
+
+func TestLoadExtract_UsesOwnSection(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+output-directory: /shared
+scrape:
+  output-directory: /scrape-only
+extract:
+  output-filename: my-cookies.json
+`), 0644))
+	withConfigFile(t, path)
+	cmd := &cobra.Command{}
+	RegisterExtractFlags(cmd, &Extract{})
+
+	// Act
+	ec, err := LoadExtract(cmd)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "/shared", ec.OutputDirectory)
+	assert.Equal(t, "my-cookies.json", ec.OutputFilename)
+	assert.Equal(t, "https://nexusmods.com", ec.BaseUrl)
+}
+
+func TestLoadWatchProfiles(t *testing.T) {
+	// Arrange: the profiles override the watch section, which a flag overrides in turn
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+watch:
+  interval: 2h
+  digest: true
+  profiles:
+    skyrim:
+      interval: 30m
+      watchlist-file: /data/skyrim.json
+      notify-webhook: https://hooks.example/skyrim
+    fallout:
+      watchlist-file: /data/fallout.json
+`), 0644))
+	withConfigFile(t, path)
+	cmd := &cobra.Command{}
+	RegisterWatchFlags(cmd, &Watch{})
+	require.NoError(t, cmd.ParseFlags([]string{"--per-mod-timeout", "45s"}))
+
+	// Act
+	profiles, err := LoadWatchProfiles(cmd)
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, profiles, 2)
+	assert.Equal(t, "fallout", profiles[0].Name)
+	assert.Equal(t, 2*time.Hour, profiles[0].Watch.Interval)
+	assert.Equal(t, "/data/fallout.json", profiles[0].Watch.WatchlistFile)
+	assert.Empty(t, profiles[0].Watch.NotifyWebhook)
+	assert.True(t, profiles[0].Watch.Digest)
+	assert.Equal(t, "skyrim", profiles[1].Name)
+	assert.Equal(t, 30*time.Minute, profiles[1].Watch.Interval)
+	assert.Equal(t, "https://hooks.example/skyrim", profiles[1].Watch.NotifyWebhook)
+	assert.Equal(t, 45*time.Second, profiles[1].Watch.PerModTimeout)
+}
+
+func TestLoadWatchProfiles_None(t *testing.T) {
+	// Arrange
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	cmd := &cobra.Command{}
+	RegisterWatchFlags(cmd, &Watch{})
+
+	// Act
+	profiles, err := LoadWatchProfiles(cmd)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, profiles)
+}
+
+func TestLoad_MissingExplicitConfigFile(t *testing.T) {
+	// Arrange
+	withConfigFile(t, filepath.Join(t.TempDir(), "missing.yaml"))
+	cmd := &cobra.Command{}
+	RegisterExtractFlags(cmd, &Extract{})
+
+	// Act
+	_, err := LoadExtract(cmd)
+
+	// Assert
+	assert.ErrorContains(t, err, "error reading config file")
+}
+
+func TestLoadSelectors(t *testing.T) {
+	// Arrange: the default file sets the name selector, an explicit file is missing
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv(EnvPrefix+"_SELECTORS", "")
+	require.NoError(t, os.MkdirAll(filepath.Dir(DefaultSelectorsPath()), os.ModePerm))
+	require.NoError(t, os.WriteFile(DefaultSelectorsPath(), []byte("mod-page:\n  name: h2\n"), 0644))
+
+	// Act
+	loaded, err := LoadSelectors()
+	t.Setenv(EnvPrefix+"_SELECTORS", filepath.Join(home, "missing.yaml"))
+	_, missingErr := LoadSelectors()
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "h2", loaded.ModPage.Name)
+	assert.Equal(t, selectors.Default().FilesTab, loaded.FilesTab)
+	assert.ErrorContains(t, missingErr, "error reading selectors file")
+}
+
+func TestLoadSelectors_MissingDefaultFile(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_SELECTORS", "")
+
+	loaded, err := LoadSelectors()
+
+	require.NoError(t, err)
+	assert.Equal(t, selectors.Default(), loaded)
+}
+
+func TestLoadTransport(t *testing.T) {
+	// Arrange
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	t.Setenv(EnvPrefix+"_MAX_IDLE_CONNS_PER_HOST", "32")
+	cmd := &cobra.Command{}
+	RegisterTransportFlags(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--http2=false"}))
+
+	// Act
+	options, err := LoadTransport(cmd)
+
+	// Assert
+	require.NoError(t, err)
+	assert.False(t, options.HTTP2)
+	assert.Equal(t, 32, options.MaxIdleConnsPerHost)
+	assert.Equal(t, 100, options.MaxIdleConns)
+	assert.Equal(t, 90*time.Second, options.IdleConnTimeout)
+	assert.Equal(t, 10*time.Second, options.ConnectTimeout)
+	assert.Equal(t, 60*time.Second, options.Timeout)
+}
+
+func TestLoadTransport_CommandTimeout(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("timeout: 30s\nscrape:\n  timeout: 2m\n"), 0644))
+	withConfigFile(t, path)
+	t.Setenv(EnvPrefix+"_TIMEOUT", "")
+	cmd := &cobra.Command{Use: "scrape"}
+	RegisterTransportFlags(cmd)
+	other := &cobra.Command{Use: "tags"}
+	RegisterTransportFlags(other)
+
+	// Act
+	options, err := LoadTransport(cmd)
+	otherOptions, otherErr := LoadTransport(other)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, otherErr)
+	assert.Equal(t, 2*time.Minute, options.Timeout)
+	assert.Equal(t, 30*time.Second, otherOptions.Timeout)
+}
+
+func TestSpinnerDisabled(t *testing.T) {
+	// Arrange
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	t.Setenv(EnvPrefix+"_NO_SPINNER", "")
+	cmd := &cobra.Command{}
+	RegisterSpinnerFlag(cmd)
+	flagged := &cobra.Command{}
+	RegisterSpinnerFlag(flagged)
+	require.NoError(t, flagged.ParseFlags([]string{"--no-spinner"}))
+
+	// Act
+	defaultDisabled, defaultErr := SpinnerDisabled(cmd)
+	flaggedDisabled, flaggedErr := SpinnerDisabled(flagged)
+
+	// Assert
+	require.NoError(t, defaultErr)
+	require.NoError(t, flaggedErr)
+	assert.False(t, defaultDisabled)
+	assert.True(t, flaggedDisabled)
+}
+
+func TestQuietEnabledAndColorDisabled(t *testing.T) {
+	// Arrange
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	t.Setenv(EnvPrefix+"_QUIET", "")
+	t.Setenv(EnvPrefix+"_NO_COLOR", "true")
+	cmd := &cobra.Command{}
+	RegisterQuietFlag(cmd)
+	RegisterColorFlag(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"-q"}))
+
+	// Act
+	quiet, quietErr := QuietEnabled(cmd)
+	noColor, colorErr := ColorDisabled(cmd)
+
+	// Assert
+	require.NoError(t, quietErr)
+	require.NoError(t, colorErr)
+	assert.True(t, quiet)
+	assert.True(t, noColor)
+}
+
+func TestLoadTransport_Negative(t *testing.T) {
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	cmd := &cobra.Command{}
+	RegisterTransportFlags(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--max-idle-conns=-1"}))
+
+	_, err := LoadTransport(cmd)
+
+	assert.EqualError(t, err, "--idle-conn-timeout, --max-idle-conns and --max-idle-conns-per-host must not be negative")
+}
+
+func TestLoadTransport_MissingCABundle(t *testing.T) {
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	cmd := &cobra.Command{}
+	RegisterTransportFlags(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--ca-bundle", filepath.Join(t.TempDir(), "missing.pem")}))
+
+	_, err := LoadTransport(cmd)
+
+	assert.ErrorContains(t, err, "error reading CA bundle")
+}
+
+func TestLoadLocale(t *testing.T) {
+	// Arrange
+	withConfigFile(t, "")
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv(EnvPrefix+"_CONFIG", "")
+	t.Setenv(EnvPrefix+"_LOCALE", "xx")
+	cmd := &cobra.Command{}
+	RegisterLocaleFlag(cmd)
+
+	// Act
+	_, envErr := LoadLocale(cmd)
+	require.NoError(t, cmd.ParseFlags([]string{"--locale", "de-DE"}))
+	value, err := LoadLocale(cmd)
+
+	// Assert
+	assert.ErrorContains(t, envErr, `unsupported locale "xx"`)
+	require.NoError(t, err)
+	assert.Equal(t, "de-DE", value)
+}
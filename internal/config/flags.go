@@ -0,0 +1,1293 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/ignore"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selfupdate"
+	"github.com/ondrovic/nexus-mods-scraper/internal/server"
+	"github.com/ondrovic/nexus-mods-scraper/internal/share"
+	"github.com/ondrovic/nexus-mods-scraper/internal/snapshots"
+	"github.com/ondrovic/nexus-mods-scraper/internal/store"
+	"github.com/ondrovic/nexus-mods-scraper/internal/transform"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/exporters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/ondrovic/nexus-mods-scraper/internal/watch"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultBaseUrl is the site scraped and the domain cookies are extracted for.
+	defaultBaseUrl = "https://nexusmods.com"
+	// defaultCollectionsBaseUrl is the site collections are hosted on.
+	defaultCollectionsBaseUrl = "https://next.nexusmods.com"
+	// defaultCookieFilename is the file the session cookies are saved to and read from.
+	defaultCookieFilename = "session-cookies.json"
+	// defaultDoctorGame and defaultDoctorModID identify the mod the doctor command
+	// checks the extractors against, SkyUI for Skyrim Special Edition: a long lived mod
+	// filling in every field the extractors read.
+	defaultDoctorGame  = "skyrimspecialedition"
+	defaultDoctorModID = 12604
+	// defaultMaxMods is the number of mods a single scrape run may request without
+	// --max-mods or --yes, guarding against runaway runs.
+	defaultMaxMods = 200
+)
+
+// defaultValidCookies are the session cookies NexusMods needs to authenticate a user.
+var defaultValidCookies = []string{"nexusmods_session", "nexusmods_session_refresh"}
+
+// Archive holds the configuration of the archive command.
+type Archive struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	OutputDirectory string
+	OutputFile      string
+	RateLimit       time.Duration
+	Warc            bool
+}
+
+// Check holds the configuration of the check command.
+type Check struct {
+	DateFormat      string
+	Format          string
+	Game            string
+	ModList         string
+	OutputDirectory string
+}
+
+// Collection holds the configuration of the scrape-collection command. The mod
+// related fields are used when each mod of the collection is scraped as well.
+type Collection struct {
+	BaseUrl            string
+	CollectionsBaseUrl string
+	CookieDirectory    string
+	CookieFile         string
+	CookieHeader       string
+	DisplayResults     bool
+	IgnoreFile         string
+	OutputDirectory    string
+	PerModTimeout      time.Duration
+	SaveResults        bool
+	ScrapeMods         bool
+}
+
+// CookiesRefresh holds the configuration of the cookies refresh command.
+type CookiesRefresh struct {
+	BaseUrl         string
+	CookieStore     string
+	Daemon          bool
+	Interval        time.Duration
+	Once            bool
+	OutputDirectory string
+	OutputFilename  string
+	ValidCookies    []string
+}
+
+// Deps holds the configuration of the deps command.
+type Deps struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	Format          string
+	MaxDepth        int
+	OutputFile      string
+	PerModTimeout   time.Duration
+}
+
+// Doctor holds the configuration of the doctor command.
+type Doctor struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	Game            string
+	ModID           int
+	PerModTimeout   time.Duration
+}
+
+// Extract holds the configuration of the extract command.
+type Extract struct {
+	BaseUrl         string
+	BrowserPath     string
+	CookieStore     string
+	Login           bool
+	OutputDirectory string
+	OutputFilename  string
+	ValidCookies    []string
+}
+
+// GameInfo holds the configuration of the game-info command.
+type GameInfo struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	OutputDirectory string
+	SaveResults     bool
+}
+
+// Ignore holds the configuration of the ignore command.
+type Ignore struct {
+	IgnoreFile string
+	Reason     string
+}
+
+// List holds the configuration of the list command.
+type List struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	Format          string
+	MaxPages        int
+	UpdatedAfter    string
+	UpdatedBefore   string
+}
+
+// Profile holds the configuration of the profile subcommands.
+type Profile struct {
+	Yes bool
+}
+
+// Query holds the configuration of the query command.
+type Query struct {
+	DateFormat      string
+	Format          string
+	IndexFile       string
+	Limit           int
+	OutputDirectory string
+	RebuildIndex    bool
+	Search          string
+}
+
+// Reparse holds the configuration of the reparse command.
+type Reparse struct {
+	BaseUrl         string
+	DateFormat      string
+	DisplayResults  bool
+	KeepEmptyFields bool
+}
+
+// Report holds the configuration of the report command.
+type Report struct {
+	DateFormat      string
+	OutputDirectory string
+	TemplateDir     string
+}
+
+// RetryFailed holds the configuration of the retry-failed command.
+type RetryFailed struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	DateFormat      string
+	DisplayResults  bool
+	ErrorReport     string
+	PerModTimeout   time.Duration
+	RunID           string
+	SummaryMarkdown bool
+}
+
+// Serve holds the configuration of the serve command.
+type Serve struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	DrainTimeout    time.Duration
+	Listen          string
+	PerModTimeout   time.Duration
+	QueueSize       int
+	RateLimit       time.Duration
+}
+
+// Tags holds the configuration of the tags command.
+type Tags struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	OutputDirectory string
+	SaveResults     bool
+}
+
+// Update holds the configuration of the update command.
+type Update struct {
+	ApiUrl string
+	Check  bool
+	Force  bool
+}
+
+// User holds the configuration of the scrape-user command.
+type User struct {
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	MaxPages        int
+	OutputDirectory string
+	SaveResults     bool
+}
+
+// Verify holds the configuration of the verify command.
+type Verify struct {
+	DateFormat      string
+	Format          string
+	Game            string
+	OutputDirectory string
+}
+
+// VerifyArchive holds the configuration of the verify-archive command.
+type VerifyArchive struct {
+	DateFormat      string
+	OutputDirectory string
+	Repair          bool
+}
+
+// Watch holds the configuration of the watch command.
+type Watch struct {
+	Backend         string
+	BaseUrl         string
+	CookieDirectory string
+	CookieFile      string
+	CookieHeader    string
+	CookieStore     string
+	Daemon          bool
+	Digest          bool
+	DrainTimeout    time.Duration
+	GraphQLEndpoint string
+	HistoryFile     string
+	IgnoreFile      string
+	Interval        time.Duration
+	Listen          string
+	NotifyWebhook   string
+	OutputDirectory string
+	PerModTimeout   time.Duration
+	Profiles        []string
+	RateLimit       time.Duration
+	SkipUnchanged   bool
+	StorageDriver   string
+	WatchlistFile   string
+}
+
+// WatchProfile is a watch job defined under watch.profiles in the configuration file,
+// run on its own schedule with its own watch list, notifier and settings.
+type WatchProfile struct {
+	Name  string
+	Watch Watch
+}
+
+// WatchAuthor holds the configuration of the watch-author command.
+type WatchAuthor struct {
+	BaseUrl           string
+	CookieDirectory   string
+	CookieFile        string
+	CookieHeader      string
+	IgnoreFile        string
+	MaxPages          int
+	PerModTimeout     time.Duration
+	SnapshotDirectory string
+}
+
+// RegisterScrapeFlags registers the command-line flags for the scrape command,
+// including options for changelog language detection, HTML archiving, gallery image
+// downloads, the fetch backend and GraphQL endpoint, the base URL, cookie location and
+// store, the cookie preflight check, date format, result display, save and streaming
+// options, error report, field selection, file categories, file naming policy, category
+// and tag filters, the format of the saved results, Wabbajack modlist and its refresh
+// report, history recording, ignore list, mod IDs file, scrape metadata, empty list
+// output, update notifications, metrics textfile, the cap on mods per run and its
+// override, output directory and path template, per-mod timeout, redaction of personal
+// details, run ID, summary sharing, skipping of unchanged mods, snapshot mode and
+// retention, storage driver, summary Markdown output, the order of the transform
+// stages, uploads to remote storage, and valid cookie names. The flags are bound to the
+// corresponding fields of target.
+func RegisterScrapeFlags(cmd *cobra.Command, target *types.CliFlags) {
+	cli.RegisterFlag(cmd, "annotate-changelog-lang", "", false, "Do you want to record the detected language of each changelog note?", &target.AnnotateChangeLogLang)
+	cli.RegisterFlag(cmd, "archive-html", "", false, "Do you want to also save the fetched mod page and files tab HTML, gzipped, next to the JSON results (see reparse)?", &target.ArchiveHtml)
+	registerBackendFlags(cmd, &target.Backend, &target.GraphQLEndpoint)
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	cli.RegisterFlag(cmd, "changelog-lang", "", "", "Only keep changelog notes detected as written in this language, e.g. en (notes of unknown language are kept)", &target.ChangeLogLang)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	registerCookieStoreFlag(cmd, &target.CookieStore)
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format for parsed dates in the JSON output: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "digest", "", false, "Send a single summarized notification for all updated mods instead of one per mod", &target.Digest)
+	cli.RegisterFlag(cmd, "display-results", "r", false, "Do you want to display the results in the terminal?", &target.DisplayResults)
+	cli.RegisterFlag(cmd, "download-images", "", false, "Do you want to also download the gallery images of each saved mod, with an images.json manifest, next to the JSON results?", &target.DownloadImages)
+	cli.RegisterFlag(cmd, "emit", "", "", "Stream each scraped mod as a JSON line to this listener, e.g. tcp://127.0.0.1:9000 or unix:///run/nexus.sock", &target.Emit)
+	cli.RegisterFlag(cmd, "error-report", "", "", "Write the outcome of the run and the error of each failed mod as JSON to this file, e.g. errors.json", &target.ErrorReport)
+	cli.RegisterFlag(cmd, "fields", "", []string{}, "Only scrape these fields of each mod, e.g. name,files,changelogs; the files tab is skipped unless files or latestversion is listed (all fields when empty)", &target.Fields)
+	cli.RegisterFlag(cmd, "file-categories", "", []string{}, "Only keep files from these Files tab sections, e.g. main,optional (also old, miscellaneous)", &target.FileCategories)
+	cli.RegisterFlag(cmd, "filename-case", "", exporters.CaseLower, "Casing of the names in saved file names: preserve, lower, kebab or snake", &target.FilenameCase)
+	cli.RegisterFlagValues(cmd, "filename-case", exporters.CasePreserve, exporters.CaseLower, exporters.CaseKebab, exporters.CaseSnake)
+	cli.RegisterFlag(cmd, "filename-separator", "", "", "Separator replacing the spaces of saved file names: a space, -, _ or . (defaults to - for kebab and _ for snake casing, a space otherwise)", &target.FilenameSeparator)
+	cli.RegisterFlag(cmd, "filter-category", "", "", "Only keep mods of this category, e.g. Gameplay, other mods are skipped", &target.FilterCategory)
+	cli.RegisterFlag(cmd, "filter-tags", "", []string{}, "Only keep mods tagged with all of these tags, other mods are skipped", &target.FilterTags)
+	cli.RegisterFlag(cmd, "format", "", exporters.FormatJson, "Format of the saved results: json, or mo2 or vortex to also write the mod manager metadata of each mod next to them", &target.Format)
+	cli.RegisterFlagValues(cmd, "format", exporters.FormatJson, exporters.FormatMO2, exporters.FormatVortex)
+	cli.RegisterFlag(cmd, "from-wabbajack", "", "", "Scrape every Nexus mod of this Wabbajack modlist, a .wabbajack file or its extracted modlist JSON, instead of the game and mod ids given", &target.FromWabbajack)
+	cli.RegisterFlag(cmd, "history-file", "", filepath.Join(storage.GetDataStoragePath(), history.DefaultFilename), "History journal to record scraped mods in", &target.HistoryFile)
+	RegisterIgnoreFileFlag(cmd, &target.IgnoreFile)
+	cli.RegisterFlag(cmd, "ids-file", "", "", "Read the mod ids to scrape from this file, one per line (# starts a comment), instead of the argument", &target.IdsFile)
+	cli.RegisterFlag(cmd, "image-concurrency", "", 4, "Number of gallery images downloaded at a time with --download-images", &target.ImageConcurrency)
+	cli.RegisterFlag(cmd, "include-meta", "", false, "Do you want the saved results to record the scraper and schema version, scrape time, source URL and duration in a ScrapeMeta block?", &target.IncludeMeta)
+	cli.RegisterFlag(cmd, "keep-empty-fields", "", false, "Do you want empty lists, such as a mod without tags, written as [] rather than left out of the JSON?", &target.KeepEmptyFields)
+	cli.RegisterFlag(cmd, "keep-last", "", 0, "Number of snapshots to keep per mod when saving with --snapshot, older ones are removed (0 keeps them all)", &target.KeepLast)
+	cli.RegisterFlag(cmd, "max-mods", "", defaultMaxMods, "Maximum number of mods a run may scrape, larger runs are refused unless --yes is given (0 disables the cap)", &target.MaxMods)
+	cli.RegisterFlag(cmd, "metrics-textfile", "", "", "Write Prometheus textfile metrics for the run to this file, e.g. /var/lib/node_exporter/nexus.prom", &target.MetricsTextfile)
+	cli.RegisterFlag(cmd, "no-preflight", "", false, "Do you want to skip checking that the session cookies authenticate before scraping?", &target.NoPreflight)
+	cli.RegisterFlag(cmd, "notify-webhook", "", "", "Post a notification to this webhook URL for mods updated since they were last recorded in the history journal", &target.NotifyWebhook)
+	cli.RegisterFlag(cmd, "only", "", []string{}, "Same as --fields, e.g. --only changelogs", &target.Fields)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "path-template", "", exporters.DefaultPathTemplate, "Path of each saved mod within the output directory, with the placeholders {game}, {modid}, {name}, {version} and {creator}", &target.PathTemplate)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping a single mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+	cli.RegisterFlag(cmd, "rate-limit", "", 500*time.Millisecond, "Minimum time between two requests of the run, pages and gallery images alike, e.g. 1s (0 disables rate limiting)", &target.RateLimit)
+	cli.RegisterFlag(cmd, "record-history", "", false, "Do you want to record each scraped mod in the history journal (used by stats)?", &target.RecordHistory)
+	cli.RegisterFlag(cmd, "redact-personal", "", false, "Do you want to strip the creator and uploader usernames and profile links from the results, e.g. to publish them?", &target.RedactPersonal)
+	cli.RegisterFlag(cmd, "refresh-report", "", "", "Write the refresh report of the --from-wabbajack modlist as JSON to this file, e.g. refresh.json", &target.RefreshReport)
+	cli.RegisterFlag(cmd, "run-id", "", "", "Identifier of the run, included in the output, history journal and notifications (generated when empty)", &target.RunID)
+	cli.RegisterFlag(cmd, "save-results", "s", false, "Do you want to save the results to a JSON file?", &target.SaveResults)
+	cli.RegisterFlag(cmd, "shadow-compare", "", false, "Do you want to also fetch each mod with the other backend (graphql for html and the other way round) and report the fields on which they disagree?", &target.ShadowCompare)
+	cli.RegisterFlag(cmd, "share-endpoint", "", share.DefaultEndpoint, "Gist API or paste service URL the run summary is shared to with --share-summary", &target.ShareEndpoint)
+	cli.RegisterFlag(cmd, "share-summary", "", false, "Do you want to upload the run summary, without secrets, and print its URL?", &target.ShareSummary)
+	cli.RegisterFlag(cmd, "share-token", "", "", "GitHub token allowed to create gists, used to share the run summary", &target.ShareToken)
+	registerSkipUnchangedFlag(cmd, &target.SkipUnchanged)
+	cli.RegisterFlag(cmd, "snapshot", "", false, "Do you want to save each scrape as a new timestamped snapshot instead of overwriting the previous results?", &target.Snapshot)
+	RegisterStorageDriverFlag(cmd, &target.StorageDriver)
+	cli.RegisterFlag(cmd, "summary-markdown", "", false, "Do you want to also write the saved results summary as summary.md?", &target.SummaryMarkdown)
+	cli.RegisterFlag(cmd, "transforms", "", []string{}, fmt.Sprintf("Order to run the transform stages in, stages left out running after them in their default order (%s)", strings.Join(transform.Names(), ", ")), &target.Transforms)
+	cli.RegisterFlag(cmd, "upload", "", "", "Upload the files saved by the run to remote storage, e.g. s3://bucket/prefix or webdavs://host/path", &target.Upload)
+	cli.RegisterFlag(cmd, "upload-concurrency", "", 4, "Number of files, and parts of large files on S3, uploaded at a time", &target.UploadConcurrency)
+	cli.RegisterFlag(cmd, "upload-endpoint", "", "", "S3 endpoint of an S3 compatible storage, e.g. https://minio.example.com (defaults to AWS)", &target.UploadEndpoint)
+	cli.RegisterFlag(cmd, "upload-password", "", "", "Secret access key (S3, defaults to AWS_SECRET_ACCESS_KEY) or password (WebDAV) of the uploads", &target.UploadPassword)
+	cli.RegisterFlag(cmd, "upload-region", "", "", "S3 region of the bucket (defaults to AWS_REGION or us-east-1)", &target.UploadRegion)
+	cli.RegisterFlag(cmd, "upload-user", "", "", "Access key ID (S3, defaults to AWS_ACCESS_KEY_ID) or user name (WebDAV) of the uploads", &target.UploadUser)
+	registerValidCookiesFlag(cmd, &target.ValidCookies)
+	cli.RegisterFlag(cmd, "yes", "y", false, "Scrape more mods than the --max-mods cap allows", &target.Yes)
+}
+
+// RegisterArchiveFlags registers the command-line flags for the archive command,
+// including options for the base URL, cookie location, output directory and file, the
+// rate limit of the requests, and the WARC output. The flags are bound to the
+// corresponding fields of target.
+func RegisterArchiveFlags(cmd *cobra.Command, target *Archive) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory the archive is saved to, within the directory of the game", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "output-file", "", "", "Save the archive to this file instead, e.g. skyui.warc.gz (a .warc file is written uncompressed)", &target.OutputFile)
+	cli.RegisterFlag(cmd, "rate-limit", "", 500*time.Millisecond, "Minimum time between two requests, e.g. 1s (0 disables rate limiting)", &target.RateLimit)
+	cli.RegisterFlag(cmd, "warc", "", false, "Do you want to save the mod page, its files and posts tabs and gallery images as a WARC file, replayable with tools such as pywb?", &target.Warc)
+}
+
+// RegisterCheckFlags registers the command-line flags for the check command, including
+// options for the date format of the saved files, the output format, the game and mod
+// list checked, and the directory of the saved mods. The flags are bound to the
+// corresponding fields of target.
+func RegisterCheckFlags(cmd *cobra.Command, target *Check) {
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format the parsed dates of the saved files were written in: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "format", "", "text", "Output format of the check: text or json", &target.Format)
+	cli.RegisterFlagValues(cmd, "format", "text", "json")
+	cli.RegisterFlag(cmd, "game", "g", "", "Game the mod list is for, e.g. skyrimspecialedition", &target.Game)
+	cli.RegisterFlag(cmd, "modlist", "m", "", "Mod list to check: a Mod Organizer 2 modlist.txt, a plugins.txt or a CSV export, e.g. from Vortex", &target.ModList)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Directory of the saved mods to check the mod list against", &target.OutputDirectory)
+}
+
+// RegisterCollectionFlags registers the command-line flags for the scrape-collection
+// command, including options for the mod and collection base URLs, cookie location,
+// result display and save options, ignore list, output directory, per-mod timeout, and
+// whether to scrape each mod. The flags are bound to the corresponding fields of
+// target.
+func RegisterCollectionFlags(cmd *cobra.Command, target *Collection) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	cli.RegisterFlag(cmd, "collections-base-url", "", defaultCollectionsBaseUrl, "Base url for the collections", &target.CollectionsBaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "display-results", "r", false, "Do you want to display the results in the terminal?", &target.DisplayResults)
+	RegisterIgnoreFileFlag(cmd, &target.IgnoreFile)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping a single mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+	cli.RegisterFlag(cmd, "save-results", "s", false, "Do you want to save the results to a JSON file?", &target.SaveResults)
+	cli.RegisterFlag(cmd, "scrape-mods", "", false, "Do you want to scrape each mod in the collection as well?", &target.ScrapeMods)
+}
+
+// RegisterCookiesRefreshFlags registers the command-line flags for the cookies refresh
+// command, including options for the base URL, cookie store, daemon or single pass
+// mode, the refresh interval, output directory, output filename, and valid cookie names
+// to extract. The flags are bound to the corresponding fields of target.
+func RegisterCookiesRefreshFlags(cmd *cobra.Command, target *CookiesRefresh) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieStoreFlag(cmd, &target.CookieStore)
+	cli.RegisterFlag(cmd, "daemon", "", false, "Do you want to keep running, refreshing the session cookies every interval?", &target.Daemon)
+	cli.RegisterFlag(cmd, "interval", "", 24*time.Hour, "Time between two refreshes in daemon mode, e.g. 12h", &target.Interval)
+	cli.RegisterFlag(cmd, "once", "", false, "Refresh the session cookies a single time and exit, e.g. from a systemd timer (the default without --daemon)", &target.Once)
+	cli.RegisterFlag(cmd, "output-directory", "d", storage.GetDataStoragePath(), "Output directory to save the file in", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "output-filename", "f", defaultCookieFilename, "Filename to save the session cookies to", &target.OutputFilename)
+	registerValidCookiesFlag(cmd, &target.ValidCookies)
+}
+
+// RegisterDepsFlags registers the command-line flags for the deps command, including
+// options for the base URL, cookie location, output format and file, maximum depth,
+// and per-mod timeout. The flags are bound to the corresponding fields of target.
+func RegisterDepsFlags(cmd *cobra.Command, target *Deps) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "format", "", "json", "Output format of the dependency graph: json or dot", &target.Format)
+	cli.RegisterFlagValues(cmd, "format", "json", "dot")
+	cli.RegisterFlag(cmd, "max-depth", "", 3, "How many levels of requirements to follow (0 follows them all)", &target.MaxDepth)
+	cli.RegisterFlag(cmd, "output-file", "o", "", "Write the graph to this file instead of the terminal", &target.OutputFile)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping a single mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+}
+
+// RegisterDoctorFlags registers the command-line flags for the doctor command, including
+// options for the base URL, cookie location, the mod checked and the per-mod timeout.
+// The flags are bound to the corresponding fields of target.
+func RegisterDoctorFlags(cmd *cobra.Command, target *Doctor) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "game", "g", defaultDoctorGame, "Game of the mod to check the extractors against", &target.Game)
+	cli.RegisterFlag(cmd, "mod-id", "m", defaultDoctorModID, "Mod to check the extractors against, it should fill in every field", &target.ModID)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping the mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+}
+
+// RegisterGameInfoFlags registers the command-line flags for the game-info command,
+// including options for the base URL, cookie location, and saving the results. The
+// flags are bound to the corresponding fields of target.
+func RegisterGameInfoFlags(cmd *cobra.Command, target *GameInfo) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "save-results", "s", false, "Do you want to save the results to a JSON file?", &target.SaveResults)
+}
+
+// RegisterIgnoreFlags registers the command-line flags for the ignore command,
+// including options for the ignore list file and the reason a mod is ignored for. The
+// flags are bound to the corresponding fields of target.
+func RegisterIgnoreFlags(cmd *cobra.Command, target *Ignore) {
+	RegisterIgnoreFileFlag(cmd, &target.IgnoreFile)
+	cli.RegisterFlag(cmd, "reason", "", "", "Why the mod is ignored, e.g. broken or abandoned, noted when it is skipped", &target.Reason)
+}
+
+// RegisterListFlags registers the command-line flags for the list command, including
+// options for the base URL, cookie location, the date window and the output format. The
+// flags are bound to the corresponding fields of target.
+func RegisterListFlags(cmd *cobra.Command, target *List) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "format", "", "ids", "Output format of the listed mods: ids (comma-separated, ready for scrape) or json", &target.Format)
+	cli.RegisterFlagValues(cmd, "format", "ids", "json")
+	cli.RegisterFlag(cmd, "max-pages", "", 0, "Maximum number of listing pages to read (0 reads until the window is exhausted)", &target.MaxPages)
+	cli.RegisterFlag(cmd, "updated-after", "", "", "List mods updated on or after this date, e.g. 2024-06-01 (required)", &target.UpdatedAfter)
+	cli.RegisterFlag(cmd, "updated-before", "", "", "List mods updated before this date, e.g. 2024-07-01 (empty lists up to now)", &target.UpdatedBefore)
+}
+
+// RegisterProfileFlags registers the command-line flags for the profile delete command,
+// confirming the deletion of a profile holding files. The flags are bound to the
+// corresponding fields of target.
+func RegisterProfileFlags(cmd *cobra.Command, target *Profile) {
+	cli.RegisterFlag(cmd, "yes", "y", false, "Delete the profile even though it holds files, e.g. its cookies or saved results", &target.Yes)
+}
+
+// RegisterQueryFlags registers the command-line flags for the query command, including
+// options for the date format of the saved files, the output format, the search index
+// file and its rebuilding, the maximum number of results, the output directory
+// searched, and the search terms. The flags are bound to the corresponding fields of
+// target.
+func RegisterQueryFlags(cmd *cobra.Command, target *Query) {
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format the parsed dates of the saved files were written in: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "format", "", "text", "Output format of the matching mods: text or json", &target.Format)
+	cli.RegisterFlagValues(cmd, "format", "text", "json")
+	cli.RegisterFlag(cmd, "index-file", "", filepath.Join(storage.GetDataStoragePath(), store.DefaultSearchIndexFilename), "SQLite database the full-text search index is kept in", &target.IndexFile)
+	cli.RegisterFlag(cmd, "limit", "", 20, "Maximum number of matching mods to list (0 lists them all)", &target.Limit)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Directory of the saved mods to search", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "rebuild-index", "", false, "Do you want to rebuild the search index from scratch instead of only indexing changed files?", &target.RebuildIndex)
+	cli.RegisterFlag(cmd, "search", "", "", "Full-text search the names, descriptions and changelogs of the saved mods, e.g. \"open cities\"", &target.Search)
+}
+
+// RegisterReparseFlags registers the command-line flags for the reparse command,
+// including options for the base URL the pages were fetched from, the date format,
+// result display, and empty list output. The flags are bound to the corresponding
+// fields of target.
+func RegisterReparseFlags(cmd *cobra.Command, target *Reparse) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format for parsed dates in the JSON output: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "display-results", "r", false, "Do you want to display the results in the terminal?", &target.DisplayResults)
+	cli.RegisterFlag(cmd, "keep-empty-fields", "", false, "Do you want empty lists, such as a mod without tags, written as [] rather than left out of the JSON?", &target.KeepEmptyFields)
+}
+
+// RegisterReportFlags registers the command-line flags for the report command,
+// including options for the date format of the saved files, the report output
+// directory, and the directory of custom templates. The flags are bound to the
+// corresponding fields of target.
+func RegisterReportFlags(cmd *cobra.Command, target *Report) {
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format the parsed dates of the saved files were written in: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "output-directory", "o", "", "Directory to write the report to (defaults to a report directory inside the results directory)", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "template-dir", "", "", "Directory of templates (index.html.tmpl, mod.html.tmpl) replacing the built-in layouts", &target.TemplateDir)
+}
+
+// RegisterRetryFailedFlags registers the command-line flags for the retry-failed
+// command, including options for the base URL, cookie location, date format, result
+// display, error report, per-mod timeout, run ID, and summary Markdown output. The
+// flags are bound to the corresponding fields of target.
+func RegisterRetryFailedFlags(cmd *cobra.Command, target *RetryFailed) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format for parsed dates in the JSON output: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "display-results", "r", false, "Do you want to display the results in the terminal?", &target.DisplayResults)
+	cli.RegisterFlag(cmd, "error-report", "", "", "Write the outcome of the run and the error of each failed mod as JSON to this file, e.g. errors.json", &target.ErrorReport)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping a single mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+	cli.RegisterFlag(cmd, "run-id", "", "", "Identifier of the run, included in the output and summary (generated when empty)", &target.RunID)
+	cli.RegisterFlag(cmd, "summary-markdown", "", false, "Do you want to also write the saved results summary as summary.md?", &target.SummaryMarkdown)
+}
+
+// RegisterServeFlags registers the command-line flags for the serve command, including
+// options for the base URL, cookie location, shutdown drain timeout, listen address,
+// per-mod timeout, job queue size, and rate limit. The flags are bound to the
+// corresponding fields of target.
+func RegisterServeFlags(cmd *cobra.Command, target *Serve) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	registerDrainTimeoutFlag(cmd, &target.DrainTimeout)
+	cli.RegisterFlag(cmd, "listen", "", server.DefaultListen, "Address the server listens on", &target.Listen)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping a single mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+	cli.RegisterFlag(cmd, "queue-size", "", 16, "Maximum number of requests waiting for their turn, further requests are refused with 429 Too Many Requests", &target.QueueSize)
+	cli.RegisterFlag(cmd, "rate-limit", "", 2*time.Second, "Minimum time between two requests to Nexus Mods, e.g. 5s (0 disables rate limiting)", &target.RateLimit)
+}
+
+// RegisterTagsFlags registers the command-line flags for the tags command, including
+// options for the base URL, cookie location, and saving the results. The flags are
+// bound to the corresponding fields of target.
+func RegisterTagsFlags(cmd *cobra.Command, target *Tags) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "save-results", "s", false, "Do you want to save the results to a JSON file?", &target.SaveResults)
+}
+
+// RegisterUpdateFlags registers the command-line flags for the update command,
+// including options for the GitHub API URL, only checking for a newer release, and
+// forcing the install. The flags are bound to the corresponding fields of target.
+func RegisterUpdateFlags(cmd *cobra.Command, target *Update) {
+	cli.RegisterFlag(cmd, "api-url", "", selfupdate.DefaultApiUrl, "GitHub API URL the latest release is looked up in", &target.ApiUrl)
+	cli.RegisterFlag(cmd, "check", "", false, "Only report whether a newer release is available, without installing it", &target.Check)
+	cli.RegisterFlag(cmd, "force", "", false, "Install the latest release even if it isn't newer or the running version is a development build", &target.Force)
+}
+
+// RegisterUserFlags registers the command-line flags for the scrape-user command,
+// including options for the base URL, cookie location, maximum number of profile
+// pages, and saving the results. The flags are bound to the corresponding fields of
+// target.
+func RegisterUserFlags(cmd *cobra.Command, target *User) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	cli.RegisterFlag(cmd, "max-pages", "", 0, "Maximum number of profile pages to read (0 reads them all)", &target.MaxPages)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "save-results", "s", false, "Do you want to save the results to a JSON file?", &target.SaveResults)
+}
+
+// RegisterWatchFlags registers the command-line flags for the watch command, including
+// options for the backend, base URL, cookie location and store, daemon mode with its
+// interval and control address, update notifications, shutdown drain timeout, history
+// journal, ignore list, output directory, per-mod timeout, profile selection, rate
+// limit, skipping of unchanged mods, storage driver, and watch list file. The flags are bound to the corresponding fields
+// of target.
+func RegisterWatchFlags(cmd *cobra.Command, target *Watch) {
+	registerBackendFlags(cmd, &target.Backend, &target.GraphQLEndpoint)
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	registerCookieStoreFlag(cmd, &target.CookieStore)
+	cli.RegisterFlag(cmd, "daemon", "", false, "Do you want to keep running, scraping the watch list every interval and serving the control API?", &target.Daemon)
+	cli.RegisterFlag(cmd, "digest", "", false, "Send a single summarized notification for all updated mods instead of one per mod", &target.Digest)
+	registerDrainTimeoutFlag(cmd, &target.DrainTimeout)
+	cli.RegisterFlag(cmd, "history-file", "", filepath.Join(storage.GetDataStoragePath(), history.DefaultFilename), "History journal to record scraped mods in", &target.HistoryFile)
+	RegisterIgnoreFileFlag(cmd, &target.IgnoreFile)
+	cli.RegisterFlag(cmd, "interval", "", time.Hour, "Time between two scrapes of the watch list in daemon mode, e.g. 30m", &target.Interval)
+	cli.RegisterFlag(cmd, "listen", "", watch.DefaultListen, "Address the control API listens on in daemon mode", &target.Listen)
+	cli.RegisterFlag(cmd, "notify-webhook", "", "", "Post a notification to this webhook URL for mods updated since they were last recorded in the history journal", &target.NotifyWebhook)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to save files", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping a single mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+	cli.RegisterFlag(cmd, "profile", "", []string{}, "Only run these watch profiles of the configuration file (all of them when empty)", &target.Profiles)
+	cli.RegisterFlag(cmd, "rate-limit", "", 500*time.Millisecond, "Minimum time between two requests of a pass over the watch list, e.g. 1s (0 disables rate limiting)", &target.RateLimit)
+	registerSkipUnchangedFlag(cmd, &target.SkipUnchanged)
+	RegisterStorageDriverFlag(cmd, &target.StorageDriver)
+	cli.RegisterFlag(cmd, "watchlist-file", "", filepath.Join(storage.GetDataStoragePath(), watch.DefaultFilename), "File the list of watched mods is stored in", &target.WatchlistFile)
+}
+
+// RegisterWatchAuthorFlags registers the command-line flags for the watch-author
+// command, including options for the base URL, cookie location, ignore list, maximum
+// number of profile pages, per-mod timeout, and snapshot directory. The flags are bound
+// to the corresponding fields of target.
+func RegisterWatchAuthorFlags(cmd *cobra.Command, target *WatchAuthor) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	registerCookieFlags(cmd, &target.CookieDirectory, &target.CookieFile, &target.CookieHeader)
+	RegisterIgnoreFileFlag(cmd, &target.IgnoreFile)
+	cli.RegisterFlag(cmd, "max-pages", "", 0, "Maximum number of profile pages to read (0 reads them all)", &target.MaxPages)
+	cli.RegisterFlag(cmd, "per-mod-timeout", "", time.Duration(0), "Maximum time to spend scraping a single mod, e.g. 60s (0 disables the timeout)", &target.PerModTimeout)
+	cli.RegisterFlag(cmd, "snapshot-directory", "", filepath.Join(storage.GetDataStoragePath(), snapshots.DefaultDirname), "Directory of the snapshot store the author's mods are compared with and recorded in", &target.SnapshotDirectory)
+}
+
+// RegisterVerifyFlags registers the command-line flags for the verify command,
+// including options for the date format of the saved files, the output format, the
+// game whose saved checksums are compared and the output directory holding them. The
+// flags are bound to the corresponding fields of target.
+func RegisterVerifyFlags(cmd *cobra.Command, target *Verify) {
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format the parsed dates of the saved files were written in: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "format", "", "text", "Output format of the verification: text or json", &target.Format)
+	cli.RegisterFlagValues(cmd, "format", "text", "json")
+	cli.RegisterFlag(cmd, "game", "g", "", "Game whose saved checksums the archives are compared with, e.g. skyrimspecialedition (all games when empty)", &target.Game)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Directory of the saved mods holding the scraped checksums", &target.OutputDirectory)
+}
+
+// RegisterVerifyArchiveFlags registers the command-line flags for the verify-archive
+// command, including options for the date format of the saved files, the output
+// directory to verify, and whether to repair misplaced files. The flags are bound to
+// the corresponding fields of target.
+func RegisterVerifyArchiveFlags(cmd *cobra.Command, target *VerifyArchive) {
+	cli.RegisterFlag(cmd, "date-format", "", "rfc3339", "Format the parsed dates of the saved files were written in: rfc3339, date, datetime, unix or a Go time layout", &target.DateFormat)
+	cli.RegisterFlagValues(cmd, "date-format", formatters.DateFormats...)
+	cli.RegisterFlag(cmd, "output-directory", "o", storage.GetDataStoragePath(), "Output directory to verify", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "repair", "", false, "Do you want to move misnamed or misplaced files to where they belong?", &target.Repair)
+}
+
+// RegisterExtractFlags registers the command-line flags for the extract command,
+// including options for the base URL, the headless browser login fallback and its
+// browser, cookie store, output directory, output filename, and valid cookie names to
+// extract. The flags are bound to the corresponding fields of target.
+func RegisterExtractFlags(cmd *cobra.Command, target *Extract) {
+	registerBaseUrlFlag(cmd, &target.BaseUrl)
+	cli.RegisterFlag(cmd, "browser-path", "", "", "Chromium or Chrome executable signing in with --login (looked up on the PATH when empty)", &target.BrowserPath)
+	registerCookieStoreFlag(cmd, &target.CookieStore)
+	cli.RegisterFlag(cmd, "login", "", false, "Do you want to sign in with a headless browser, prompting for your credentials, when no browser holds the cookies?", &target.Login)
+	cli.RegisterFlag(cmd, "output-directory", "d", storage.GetDataStoragePath(), "Output directory to save the file in", &target.OutputDirectory)
+	cli.RegisterFlag(cmd, "output-filename", "f", defaultCookieFilename, "Filename to save the session cookies to", &target.OutputFilename)
+	registerValidCookiesFlag(cmd, &target.ValidCookies)
+}
+
+// LoadScrape resolves the scrape command configuration from its flags, the environment
+// and the configuration file. The game name is taken from the command arguments and
+// is left for the caller to fill in.
+func LoadScrape(cmd *cobra.Command) (types.CliFlags, error) {
+	v, err := Load(cmd, "scrape")
+	if err != nil {
+		return types.CliFlags{}, err
+	}
+
+	return types.CliFlags{
+		AnnotateChangeLogLang: v.GetBool("annotate-changelog-lang"),
+		ArchiveHtml:           v.GetBool("archive-html"),
+		Backend:               v.GetString("backend"),
+		BaseUrl:               v.GetString("base-url"),
+		ChangeLogLang:         v.GetString("changelog-lang"),
+		CookieDirectory:       v.GetString("cookie-directory"),
+		CookieFile:            v.GetString("cookie-filename"),
+		CookieHeader:          v.GetString("cookie-header"),
+		CookieStore:           v.GetString("cookie-store"),
+		DateFormat:            v.GetString("date-format"),
+		Digest:                v.GetBool("digest"),
+		DisplayResults:        v.GetBool("display-results"),
+		DownloadImages:        v.GetBool("download-images"),
+		DryRun:                v.GetBool(dryRunFlag),
+		Emit:                  v.GetString("emit"),
+		ErrorReport:           v.GetString("error-report"),
+		Fields:                append(stringSlice(v, "fields"), stringSlice(v, "only")...),
+		FileCategories:        stringSlice(v, "file-categories"),
+		FilenameCase:          v.GetString("filename-case"),
+		FilenameSeparator:     v.GetString("filename-separator"),
+		FilterCategory:        v.GetString("filter-category"),
+		FilterTags:            stringSlice(v, "filter-tags"),
+		Format:                v.GetString("format"),
+		FromWabbajack:         v.GetString("from-wabbajack"),
+		GraphQLEndpoint:       v.GetString("graphql-endpoint"),
+		HistoryFile:           v.GetString("history-file"),
+		IdsFile:               v.GetString("ids-file"),
+		IgnoreFile:            v.GetString("ignore-file"),
+		ImageConcurrency:      v.GetInt("image-concurrency"),
+		IncludeMeta:           v.GetBool("include-meta"),
+		KeepEmptyFields:       v.GetBool("keep-empty-fields"),
+		KeepLast:              v.GetInt("keep-last"),
+		MaxMods:               v.GetInt("max-mods"),
+		MetricsTextfile:       v.GetString("metrics-textfile"),
+		NoPreflight:           v.GetBool("no-preflight"),
+		NotifyWebhook:         v.GetString("notify-webhook"),
+		OutputDirectory:       v.GetString("output-directory"),
+		PathTemplate:          v.GetString("path-template"),
+		PerModTimeout:         v.GetDuration("per-mod-timeout"),
+		RateLimit:             v.GetDuration("rate-limit"),
+		RecordHistory:         v.GetBool("record-history"),
+		RedactPersonal:        v.GetBool("redact-personal"),
+		RefreshReport:         v.GetString("refresh-report"),
+		RunID:                 v.GetString("run-id"),
+		SaveResults:           v.GetBool("save-results"),
+		ShadowCompare:         v.GetBool("shadow-compare"),
+		ShareEndpoint:         v.GetString("share-endpoint"),
+		ShareSummary:          v.GetBool("share-summary"),
+		ShareToken:            v.GetString("share-token"),
+		SkipUnchanged:         v.GetBool("skip-unchanged"),
+		Snapshot:              v.GetBool("snapshot"),
+		StorageDriver:         v.GetString("storage-driver"),
+		SummaryMarkdown:       v.GetBool("summary-markdown"),
+		Transforms:            stringSlice(v, "transforms"),
+		Upload:                v.GetString("upload"),
+		UploadConcurrency:     v.GetInt("upload-concurrency"),
+		UploadEndpoint:        v.GetString("upload-endpoint"),
+		UploadPassword:        v.GetString("upload-password"),
+		UploadRegion:          v.GetString("upload-region"),
+		UploadUser:            v.GetString("upload-user"),
+		ValidCookies:          stringSlice(v, "valid-cookie-names"),
+		Yes:                   v.GetBool("yes"),
+	}, nil
+}
+
+// LoadArchive resolves the archive command configuration from its flags, the
+// environment and the configuration file.
+func LoadArchive(cmd *cobra.Command) (Archive, error) {
+	v, err := Load(cmd, "archive")
+	if err != nil {
+		return Archive{}, err
+	}
+
+	return Archive{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		OutputDirectory: v.GetString("output-directory"),
+		OutputFile:      v.GetString("output-file"),
+		RateLimit:       v.GetDuration("rate-limit"),
+		Warc:            v.GetBool("warc"),
+	}, nil
+}
+
+// LoadCheck resolves the check command configuration from its flags, the environment
+// and the configuration file.
+func LoadCheck(cmd *cobra.Command) (Check, error) {
+	v, err := Load(cmd, "check")
+	if err != nil {
+		return Check{}, err
+	}
+
+	return Check{
+		DateFormat:      v.GetString("date-format"),
+		Format:          v.GetString("format"),
+		Game:            v.GetString("game"),
+		ModList:         v.GetString("modlist"),
+		OutputDirectory: v.GetString("output-directory"),
+	}, nil
+}
+
+// LoadCollection resolves the scrape-collection command configuration from its flags,
+// the environment and the configuration file.
+func LoadCollection(cmd *cobra.Command) (Collection, error) {
+	v, err := Load(cmd, "scrape-collection")
+	if err != nil {
+		return Collection{}, err
+	}
+
+	return Collection{
+		BaseUrl:            v.GetString("base-url"),
+		CollectionsBaseUrl: v.GetString("collections-base-url"),
+		CookieDirectory:    v.GetString("cookie-directory"),
+		CookieFile:         v.GetString("cookie-filename"),
+		CookieHeader:       v.GetString("cookie-header"),
+		DisplayResults:     v.GetBool("display-results"),
+		IgnoreFile:         v.GetString("ignore-file"),
+		OutputDirectory:    v.GetString("output-directory"),
+		PerModTimeout:      v.GetDuration("per-mod-timeout"),
+		SaveResults:        v.GetBool("save-results"),
+		ScrapeMods:         v.GetBool("scrape-mods"),
+	}, nil
+}
+
+// LoadCookiesRefresh resolves the cookies refresh command configuration from its flags,
+// the environment and the configuration file.
+func LoadCookiesRefresh(cmd *cobra.Command) (CookiesRefresh, error) {
+	v, err := Load(cmd, "cookies")
+	if err != nil {
+		return CookiesRefresh{}, err
+	}
+
+	return CookiesRefresh{
+		BaseUrl:         v.GetString("base-url"),
+		CookieStore:     v.GetString("cookie-store"),
+		Daemon:          v.GetBool("daemon"),
+		Interval:        v.GetDuration("interval"),
+		Once:            v.GetBool("once"),
+		OutputDirectory: v.GetString("output-directory"),
+		OutputFilename:  v.GetString("output-filename"),
+		ValidCookies:    stringSlice(v, "valid-cookie-names"),
+	}, nil
+}
+
+// LoadDeps resolves the deps command configuration from its flags, the environment
+// and the configuration file.
+func LoadDeps(cmd *cobra.Command) (Deps, error) {
+	v, err := Load(cmd, "deps")
+	if err != nil {
+		return Deps{}, err
+	}
+
+	return Deps{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		Format:          v.GetString("format"),
+		MaxDepth:        v.GetInt("max-depth"),
+		OutputFile:      v.GetString("output-file"),
+		PerModTimeout:   v.GetDuration("per-mod-timeout"),
+	}, nil
+}
+
+// LoadDoctor resolves the doctor command configuration from its flags, the environment
+// and the configuration file.
+func LoadDoctor(cmd *cobra.Command) (Doctor, error) {
+	v, err := Load(cmd, "doctor")
+	if err != nil {
+		return Doctor{}, err
+	}
+
+	return Doctor{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		Game:            v.GetString("game"),
+		ModID:           v.GetInt("mod-id"),
+		PerModTimeout:   v.GetDuration("per-mod-timeout"),
+	}, nil
+}
+
+// LoadGameInfo resolves the game-info command configuration from its flags, the
+// environment and the configuration file.
+func LoadGameInfo(cmd *cobra.Command) (GameInfo, error) {
+	v, err := Load(cmd, "game-info")
+	if err != nil {
+		return GameInfo{}, err
+	}
+
+	return GameInfo{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		OutputDirectory: v.GetString("output-directory"),
+		SaveResults:     v.GetBool("save-results"),
+	}, nil
+}
+
+// LoadIgnore resolves the ignore command configuration from its flags, the environment
+// and the configuration file.
+func LoadIgnore(cmd *cobra.Command) (Ignore, error) {
+	v, err := Load(cmd, "ignore")
+	if err != nil {
+		return Ignore{}, err
+	}
+
+	return Ignore{
+		IgnoreFile: v.GetString("ignore-file"),
+		Reason:     v.GetString("reason"),
+	}, nil
+}
+
+// LoadList resolves the list command configuration from its flags, the environment and
+// the configuration file.
+func LoadList(cmd *cobra.Command) (List, error) {
+	v, err := Load(cmd, "list")
+	if err != nil {
+		return List{}, err
+	}
+
+	return List{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		Format:          v.GetString("format"),
+		MaxPages:        v.GetInt("max-pages"),
+		UpdatedAfter:    v.GetString("updated-after"),
+		UpdatedBefore:   v.GetString("updated-before"),
+	}, nil
+}
+
+// LoadProfile resolves the profile subcommands configuration from their flags, the
+// environment and the configuration file.
+func LoadProfile(cmd *cobra.Command) (Profile, error) {
+	v, err := Load(cmd, "profile")
+	if err != nil {
+		return Profile{}, err
+	}
+
+	return Profile{
+		Yes: v.GetBool("yes"),
+	}, nil
+}
+
+// LoadQuery resolves the query command configuration from its flags, the environment
+// and the configuration file.
+func LoadQuery(cmd *cobra.Command) (Query, error) {
+	v, err := Load(cmd, "query")
+	if err != nil {
+		return Query{}, err
+	}
+
+	return Query{
+		DateFormat:      v.GetString("date-format"),
+		Format:          v.GetString("format"),
+		IndexFile:       v.GetString("index-file"),
+		Limit:           v.GetInt("limit"),
+		OutputDirectory: v.GetString("output-directory"),
+		RebuildIndex:    v.GetBool("rebuild-index"),
+		Search:          v.GetString("search"),
+	}, nil
+}
+
+// LoadReparse resolves the reparse command configuration from its flags, the
+// environment and the configuration file.
+func LoadReparse(cmd *cobra.Command) (Reparse, error) {
+	v, err := Load(cmd, "reparse")
+	if err != nil {
+		return Reparse{}, err
+	}
+
+	return Reparse{
+		BaseUrl:         v.GetString("base-url"),
+		DateFormat:      v.GetString("date-format"),
+		DisplayResults:  v.GetBool("display-results"),
+		KeepEmptyFields: v.GetBool("keep-empty-fields"),
+	}, nil
+}
+
+// LoadReport resolves the report command configuration from its flags, the
+// environment and the configuration file.
+func LoadReport(cmd *cobra.Command) (Report, error) {
+	v, err := Load(cmd, "report")
+	if err != nil {
+		return Report{}, err
+	}
+
+	return Report{
+		DateFormat:      v.GetString("date-format"),
+		OutputDirectory: v.GetString("output-directory"),
+		TemplateDir:     v.GetString("template-dir"),
+	}, nil
+}
+
+// LoadRetryFailed resolves the retry-failed command configuration from its flags, the
+// environment and the configuration file.
+func LoadRetryFailed(cmd *cobra.Command) (RetryFailed, error) {
+	v, err := Load(cmd, "retry-failed")
+	if err != nil {
+		return RetryFailed{}, err
+	}
+
+	return RetryFailed{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		DateFormat:      v.GetString("date-format"),
+		DisplayResults:  v.GetBool("display-results"),
+		ErrorReport:     v.GetString("error-report"),
+		PerModTimeout:   v.GetDuration("per-mod-timeout"),
+		RunID:           v.GetString("run-id"),
+		SummaryMarkdown: v.GetBool("summary-markdown"),
+	}, nil
+}
+
+// LoadServe resolves the serve command configuration from its flags, the environment
+// and the configuration file.
+func LoadServe(cmd *cobra.Command) (Serve, error) {
+	v, err := Load(cmd, "serve")
+	if err != nil {
+		return Serve{}, err
+	}
+
+	return Serve{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		DrainTimeout:    v.GetDuration("drain-timeout"),
+		Listen:          v.GetString("listen"),
+		PerModTimeout:   v.GetDuration("per-mod-timeout"),
+		QueueSize:       v.GetInt("queue-size"),
+		RateLimit:       v.GetDuration("rate-limit"),
+	}, nil
+}
+
+// LoadTags resolves the tags command configuration from its flags, the environment and
+// the configuration file.
+func LoadTags(cmd *cobra.Command) (Tags, error) {
+	v, err := Load(cmd, "tags")
+	if err != nil {
+		return Tags{}, err
+	}
+
+	return Tags{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		OutputDirectory: v.GetString("output-directory"),
+		SaveResults:     v.GetBool("save-results"),
+	}, nil
+}
+
+// LoadUpdate resolves the update command configuration from its flags, the environment
+// and the configuration file.
+func LoadUpdate(cmd *cobra.Command) (Update, error) {
+	v, err := Load(cmd, "update")
+	if err != nil {
+		return Update{}, err
+	}
+
+	return Update{
+		ApiUrl: v.GetString("api-url"),
+		Check:  v.GetBool("check"),
+		Force:  v.GetBool("force"),
+	}, nil
+}
+
+// LoadUser resolves the scrape-user command configuration from its flags, the
+// environment and the configuration file.
+func LoadUser(cmd *cobra.Command) (User, error) {
+	v, err := Load(cmd, "scrape-user")
+	if err != nil {
+		return User{}, err
+	}
+
+	return User{
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		MaxPages:        v.GetInt("max-pages"),
+		OutputDirectory: v.GetString("output-directory"),
+		SaveResults:     v.GetBool("save-results"),
+	}, nil
+}
+
+// LoadWatch resolves the watch command configuration from its flags, the environment
+// and the configuration file.
+func LoadWatch(cmd *cobra.Command) (Watch, error) {
+	v, err := Load(cmd, "watch")
+	if err != nil {
+		return Watch{}, err
+	}
+
+	return watchFrom(v), nil
+}
+
+// LoadWatchProfiles resolves the watch profiles defined under watch.profiles in the
+// configuration file, sorted by name. Each profile is the watch command configuration
+// with the keys of the profile layered over the rest of the configuration file; flags
+// and environment variables still win, applying to every profile. Returns no profile
+// when none is defined, and an error if a profile isn't a map of settings or the
+// configuration can't be read.
+func LoadWatchProfiles(cmd *cobra.Command) ([]WatchProfile, error) {
+	v, err := Load(cmd, "watch")
+	if err != nil {
+		return nil, err
+	}
+
+	defined := v.GetStringMap("profiles")
+	names := make([]string, 0, len(defined))
+	for name := range defined {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	profiles := make([]WatchProfile, 0, len(names))
+	for _, name := range names {
+		settings, ok := defined[name].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("watch profile %s must be a map of settings", name)
+		}
+
+		pv, err := Load(cmd, "watch")
+		if err != nil {
+			return nil, err
+		}
+		if err := pv.MergeConfigMap(settings); err != nil {
+			return nil, fmt.Errorf("error merging watch profile %s: %w", name, err)
+		}
+		profiles = append(profiles, WatchProfile{Name: name, Watch: watchFrom(pv)})
+	}
+	return profiles, nil
+}
+
+// watchFrom returns the watch command configuration held by v.
+func watchFrom(v *viper.Viper) Watch {
+	return Watch{
+		Backend:         v.GetString("backend"),
+		BaseUrl:         v.GetString("base-url"),
+		CookieDirectory: v.GetString("cookie-directory"),
+		CookieFile:      v.GetString("cookie-filename"),
+		CookieHeader:    v.GetString("cookie-header"),
+		CookieStore:     v.GetString("cookie-store"),
+		Daemon:          v.GetBool("daemon"),
+		Digest:          v.GetBool("digest"),
+		DrainTimeout:    v.GetDuration("drain-timeout"),
+		GraphQLEndpoint: v.GetString("graphql-endpoint"),
+		HistoryFile:     v.GetString("history-file"),
+		IgnoreFile:      v.GetString("ignore-file"),
+		Interval:        v.GetDuration("interval"),
+		Listen:          v.GetString("listen"),
+		NotifyWebhook:   v.GetString("notify-webhook"),
+		OutputDirectory: v.GetString("output-directory"),
+		PerModTimeout:   v.GetDuration("per-mod-timeout"),
+		Profiles:        stringSlice(v, "profile"),
+		RateLimit:       v.GetDuration("rate-limit"),
+		SkipUnchanged:   v.GetBool("skip-unchanged"),
+		StorageDriver:   v.GetString("storage-driver"),
+		WatchlistFile:   v.GetString("watchlist-file"),
+	}
+}
+
+// LoadWatchAuthor resolves the watch-author command configuration from its flags, the
+// environment and the configuration file.
+func LoadWatchAuthor(cmd *cobra.Command) (WatchAuthor, error) {
+	v, err := Load(cmd, "watch-author")
+	if err != nil {
+		return WatchAuthor{}, err
+	}
+
+	return WatchAuthor{
+		BaseUrl:           v.GetString("base-url"),
+		CookieDirectory:   v.GetString("cookie-directory"),
+		CookieFile:        v.GetString("cookie-filename"),
+		CookieHeader:      v.GetString("cookie-header"),
+		IgnoreFile:        v.GetString("ignore-file"),
+		MaxPages:          v.GetInt("max-pages"),
+		PerModTimeout:     v.GetDuration("per-mod-timeout"),
+		SnapshotDirectory: v.GetString("snapshot-directory"),
+	}, nil
+}
+
+// LoadVerify resolves the verify command configuration from its flags, the environment
+// and the configuration file.
+func LoadVerify(cmd *cobra.Command) (Verify, error) {
+	v, err := Load(cmd, "verify")
+	if err != nil {
+		return Verify{}, err
+	}
+
+	return Verify{
+		DateFormat:      v.GetString("date-format"),
+		Format:          v.GetString("format"),
+		Game:            v.GetString("game"),
+		OutputDirectory: v.GetString("output-directory"),
+	}, nil
+}
+
+// LoadVerifyArchive resolves the verify-archive command configuration from its flags,
+// the environment and the configuration file.
+func LoadVerifyArchive(cmd *cobra.Command) (VerifyArchive, error) {
+	v, err := Load(cmd, "verify-archive")
+	if err != nil {
+		return VerifyArchive{}, err
+	}
+
+	return VerifyArchive{
+		DateFormat:      v.GetString("date-format"),
+		OutputDirectory: v.GetString("output-directory"),
+		Repair:          v.GetBool("repair"),
+	}, nil
+}
+
+// LoadExtract resolves the extract command configuration from its flags, the
+// environment and the configuration file.
+func LoadExtract(cmd *cobra.Command) (Extract, error) {
+	v, err := Load(cmd, "extract")
+	if err != nil {
+		return Extract{}, err
+	}
+
+	return Extract{
+		BaseUrl:         v.GetString("base-url"),
+		BrowserPath:     v.GetString("browser-path"),
+		CookieStore:     v.GetString("cookie-store"),
+		Login:           v.GetBool("login"),
+		OutputDirectory: v.GetString("output-directory"),
+		OutputFilename:  v.GetString("output-filename"),
+		ValidCookies:    stringSlice(v, "valid-cookie-names"),
+	}, nil
+}
+
+// registerBaseUrlFlag registers the base-url flag shared by the commands.
+func registerBaseUrlFlag(cmd *cobra.Command, target *string) {
+	cli.RegisterFlag(cmd, "base-url", "u", defaultBaseUrl, "Base url for the mods", target)
+}
+
+// registerCookieFlags registers the cookie-directory, cookie-filename and cookie-header
+// flags used by the commands that read the saved session cookies.
+func registerCookieFlags(cmd *cobra.Command, directory, filename, header *string) {
+	cli.RegisterFlag(cmd, "cookie-directory", "d", storage.GetDataStoragePath(), "Directory your cookie file is stored in", directory)
+	cli.RegisterFlag(cmd, "cookie-filename", "f", defaultCookieFilename, "Filename where the cookies are stored", filename)
+	cli.RegisterFlag(cmd, "cookie-header", "", "", "Cookies to use instead of the cookie file, as a Cookie header, e.g. \"nexusmods_session=...; nexusmods_session_refresh=...\"", header)
+}
+
+// registerBackendFlags registers the backend flag selecting how mods are fetched and the
+// graphql-endpoint flag, shared by the scrape and watch commands.
+func registerBackendFlags(cmd *cobra.Command, backend, endpoint *string) {
+	cli.RegisterFlag(cmd, "backend", "", fetchers.BackendHtml, "How mods are fetched: html parses their pages, graphql queries the API of the new Nexus Mods frontend with the session cookies", backend)
+	cli.RegisterFlagValues(cmd, "backend", fetchers.BackendHtml, fetchers.BackendGraphQL)
+	cli.RegisterFlag(cmd, "graphql-endpoint", "", fetchers.DefaultGraphQLEndpoint, "GraphQL API queried with --backend graphql", endpoint)
+}
+
+// registerCookieStoreFlag registers the cookie-store flag selecting whether the session
+// cookies are kept in the cookie file or the OS keyring.
+func registerCookieStoreFlag(cmd *cobra.Command, target *string) {
+	cli.RegisterFlag(cmd, "cookie-store", "", storage.CookieStoreFile, "Where the session cookies are kept: file, or keyring for the OS keyring (the cookie directory and filename then only name the entry)", target)
+	cli.RegisterFlagValues(cmd, "cookie-store", storage.CookieStoreFile, storage.CookieStoreKeyring)
+}
+
+// RegisterStorageDriverFlag registers the storage-driver flag selecting how the history
+// journal and the watch list are persisted. It is exported for the stats command.
+func RegisterStorageDriverFlag(cmd *cobra.Command, target *string) {
+	cli.RegisterFlag(cmd, "storage-driver", "", store.DefaultDriver, "Storage driver for the history journal and watch list: json or sqlite (the history and watch list file flags then name the SQLite database)", target)
+	cli.RegisterFlagValues(cmd, "storage-driver", store.JSONDriverName, store.SQLiteDriverName)
+}
+
+// RegisterIgnoreFileFlag registers the ignore-file flag shared by the commands skipping
+// the ignored mods and those managing the ignore list.
+func RegisterIgnoreFileFlag(cmd *cobra.Command, target *string) {
+	cli.RegisterFlag(cmd, "ignore-file", "", filepath.Join(storage.GetDataStoragePath(), ignore.DefaultFilename), "Ignore list of the mods to skip, managed with the ignore command", target)
+}
+
+// registerDrainTimeoutFlag registers the drain-timeout flag shared by the serve and
+// watch commands.
+func registerDrainTimeoutFlag(cmd *cobra.Command, target *time.Duration) {
+	cli.RegisterFlag(cmd, "drain-timeout", "", 5*time.Second, "Maximum time to let the work in flight finish when stopped, e.g. 20s (0 cancels it right away)", target)
+}
+
+// registerSkipUnchangedFlag registers the skip-unchanged flag shared by the scrape
+// and watch commands.
+func registerSkipUnchangedFlag(cmd *cobra.Command, target *bool) {
+	cli.RegisterFlag(cmd, "skip-unchanged", "", false, "Do you want to skip the mods whose page shows the same last update as their saved results, without fetching their files tab or saving them again?", target)
+}
+
+// registerValidCookiesFlag registers the valid-cookie-names flag shared by the scrape
+// and extract commands.
+func registerValidCookiesFlag(cmd *cobra.Command, target *[]string) {
+	cli.RegisterFlag(cmd, "valid-cookie-names", "c", append([]string{}, defaultValidCookies...), "Names of the cookies to extract", target)
+}
@@ -0,0 +1,144 @@
+package deps
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// FetchFunc retrieves the details of a single mod, including its Nexus requirements.
+type FetchFunc func(ctx context.Context, game string, modID int64) (types.ModInfo, error)
+
+// Node is a mod in the dependency graph. Depth is the shortest number of requirement
+// hops from the root mod. Truncated nodes sit at the maximum depth and their own
+// requirements were not followed; nodes that failed to scrape carry the error.
+type Node struct {
+	Depth     int    `json:"depth"`
+	Error     string `json:"error,omitempty"`
+	Game      string `json:"game"`
+	ID        string `json:"id"`
+	ModID     int64  `json:"modId"`
+	Name      string `json:"name,omitempty"`
+	Truncated bool   `json:"truncated,omitempty"`
+	Url       string `json:"url,omitempty"`
+}
+
+// Edge is a requirement of one mod on another, identified by node IDs. Cycle marks an
+// edge that closes a requirement loop back to one of the mod's own prerequisites.
+type Edge struct {
+	Cycle bool   `json:"cycle,omitempty"`
+	From  string `json:"from"`
+	Notes string `json:"notes,omitempty"`
+	To    string `json:"to"`
+}
+
+// Graph is the dependency graph of a root mod, with nodes in breadth-first order.
+type Graph struct {
+	Cycles int    `json:"cycles"`
+	Edges  []Edge `json:"edges"`
+	Nodes  []Node `json:"nodes"`
+	Root   string `json:"root"`
+}
+
+// NodeID returns the identifier of a mod in the graph, e.g. "skyrimspecialedition/12604".
+func NodeID(game string, modID int64) string {
+	return fmt.Sprintf("%s/%d", game, modID)
+}
+
+// Build scrapes the root mod and follows its Nexus requirements breadth-first, up to
+// maxDepth hops away (0 or less means no limit). Each mod is scraped at most once, so
+// requirement loops terminate; the edges closing them are marked as cycles. A failure
+// to scrape a required mod is recorded on its node and does not stop the walk, but a
+// failure to scrape the root mod, or a cancelled context, returns an error.
+func Build(ctx context.Context, game string, modID int64, maxDepth int, fetch FetchFunc) (Graph, error) {
+	graph := Graph{Root: NodeID(game, modID), Edges: []Edge{}, Nodes: []Node{}}
+	index := map[string]int{}
+
+	add := func(node Node) {
+		index[node.ID] = len(graph.Nodes)
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	add(Node{Game: game, ID: graph.Root, ModID: modID})
+
+	// The node slice doubles as the breadth-first queue
+	for i := 0; i < len(graph.Nodes); i++ {
+		if err := ctx.Err(); err != nil {
+			return graph, err
+		}
+
+		node := &graph.Nodes[i]
+		if maxDepth > 0 && node.Depth >= maxDepth {
+			node.Truncated = true
+			continue
+		}
+
+		mod, err := fetch(ctx, node.Game, node.ModID)
+		if err != nil {
+			if i == 0 || ctx.Err() != nil {
+				return graph, err
+			}
+			node.Error = err.Error()
+			continue
+		}
+		node.Name = mod.Name
+		if mod.Url != "" {
+			node.Url = mod.Url
+		}
+
+		// Appending requirements may move the slice, so don't hold on to node past here
+		from, depth := node.ID, node.Depth+1
+		for _, requirement := range mod.Dependencies {
+			reqGame, reqID, err := formatters.ParseModUrl(requirement.Url)
+			if err != nil {
+				// Not a mod page, e.g. an external link listed as a Nexus requirement
+				continue
+			}
+
+			id := NodeID(reqGame, reqID)
+			graph.Edges = append(graph.Edges, Edge{From: from, Notes: requirement.Notes, To: id})
+			if _, seen := index[id]; !seen {
+				add(Node{Depth: depth, Game: reqGame, ID: id, ModID: reqID, Name: requirement.Name, Url: requirement.Url})
+			}
+		}
+	}
+
+	graph.Cycles = markCycles(&graph)
+	return graph, nil
+}
+
+// markCycles walks the graph depth-first from the root and marks every edge that leads
+// back to a mod still on the current path. Returns the number of cycle edges.
+func markCycles(graph *Graph) int {
+	outgoing := map[string][]int{}
+	for i, edge := range graph.Edges {
+		outgoing[edge.From] = append(outgoing[edge.From], i)
+	}
+
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+	state := map[string]int{}
+	cycles := 0
+
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = onPath
+		for _, i := range outgoing[id] {
+			switch state[graph.Edges[i].To] {
+			case onPath:
+				graph.Edges[i].Cycle = true
+				cycles++
+			case unvisited:
+				visit(graph.Edges[i].To)
+			}
+		}
+		state[id] = done
+	}
+	visit(graph.Root)
+
+	return cycles
+}
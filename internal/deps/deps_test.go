@@ -0,0 +1,120 @@
+package deps
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMods builds a FetchFunc over a map of mod ID to required mod IDs, all in one game,
+// recording how often each mod was fetched.
+func fakeMods(requires map[int64][]int64, fetched map[int64]int) FetchFunc {
+	return func(ctx context.Context, game string, modID int64) (types.ModInfo, error) {
+		fetched[modID]++
+		required, ok := requires[modID]
+		if !ok {
+			return types.ModInfo{}, errors.New("not found")
+		}
+
+		mod := types.ModInfo{Name: fmt.Sprintf("Mod %d", modID)}
+		for _, id := range required {
+			mod.Dependencies = append(mod.Dependencies, types.Requirement{
+				Name: fmt.Sprintf("Mod %d", id),
+				Url:  fmt.Sprintf("https://www.nexusmods.com/%s/mods/%d", game, id),
+			})
+		}
+		return mod, nil
+	}
+}
+
+func TestBuild_DetectsCycles(t *testing.T) {
+	// Arrange: 1 -> 2 -> 3 -> 1, and 1 -> 3
+	fetched := map[int64]int{}
+	fetch := fakeMods(map[int64][]int64{1: {2, 3}, 2: {3}, 3: {1}}, fetched)
+
+	// Act
+	graph, err := Build(context.Background(), "skyrim", 1, 0, fetch)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "skyrim/1", graph.Root)
+	assert.Len(t, graph.Nodes, 3)
+	assert.Len(t, graph.Edges, 4)
+	assert.Equal(t, 1, graph.Cycles)
+	assert.Equal(t, map[int64]int{1: 1, 2: 1, 3: 1}, fetched, "each mod is scraped once")
+
+	for _, edge := range graph.Edges {
+		assert.Equal(t, edge.From == "skyrim/3" && edge.To == "skyrim/1", edge.Cycle, "%s -> %s", edge.From, edge.To)
+	}
+}
+
+func TestBuild_MaxDepth(t *testing.T) {
+	// Arrange: 1 -> 2 -> 3 -> 4
+	fetched := map[int64]int{}
+	fetch := fakeMods(map[int64][]int64{1: {2}, 2: {3}, 3: {4}, 4: {}}, fetched)
+
+	// Act
+	graph, err := Build(context.Background(), "skyrim", 1, 2, fetch)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, graph.Nodes, 3)
+	assert.Equal(t, 2, graph.Nodes[2].Depth)
+	assert.True(t, graph.Nodes[2].Truncated)
+	assert.Equal(t, "Mod 3", graph.Nodes[2].Name, "truncated nodes keep the requirement name")
+	assert.Zero(t, fetched[3])
+}
+
+func TestBuild_RequirementFailureIsRecorded(t *testing.T) {
+	// Arrange: 2 can't be scraped
+	fetch := fakeMods(map[int64][]int64{1: {2}}, map[int64]int{})
+
+	// Act
+	graph, err := Build(context.Background(), "skyrim", 1, 0, fetch)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "not found", graph.Nodes[1].Error)
+}
+
+func TestBuild_RootFailure(t *testing.T) {
+	fetch := fakeMods(map[int64][]int64{}, map[int64]int{})
+
+	_, err := Build(context.Background(), "skyrim", 1, 0, fetch)
+
+	assert.EqualError(t, err, "not found")
+}
+
+func TestToDOT(t *testing.T) {
+	// Arrange
+	graph := Graph{
+		Root: "skyrim/1",
+		Nodes: []Node{
+			{ID: "skyrim/1", Name: `The "Best" Mod`},
+			{ID: "skyrim/2", Truncated: true},
+		},
+		Edges: []Edge{
+			{From: "skyrim/1", To: "skyrim/2", Notes: "Required"},
+			{From: "skyrim/2", To: "skyrim/1", Cycle: true},
+		},
+	}
+
+	// Act
+	dot := ToDOT(graph)
+
+	// Assert
+	assert.Equal(t, `digraph dependencies {
+  rankdir=LR;
+  node [shape=box];
+  "skyrim/1" [label="The \"Best\" Mod\nskyrim/1", style=bold];
+  "skyrim/2" [label="skyrim/2", style=dashed];
+  "skyrim/1" -> "skyrim/2" [label="Required"];
+  "skyrim/2" -> "skyrim/1" [color=red];
+}
+`, dot)
+}
@@ -0,0 +1,56 @@
+package deps
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ToDOT renders the graph in Graphviz DOT format, with requirements pointing from a mod
+// to the mods it needs. The root mod is drawn bold, truncated and failed mods are
+// dashed, and edges closing a requirement cycle are drawn in red.
+func ToDOT(graph Graph) string {
+	var b strings.Builder
+
+	b.WriteString("digraph dependencies {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+
+	for _, node := range graph.Nodes {
+		label := dotEscape(node.ID)
+		if node.Name != "" {
+			label = fmt.Sprintf("%s\\n%s", dotEscape(node.Name), label)
+		}
+		attrs := []string{fmt.Sprintf("label=\"%s\"", label)}
+		if node.ID == graph.Root {
+			attrs = append(attrs, "style=bold")
+		}
+		if node.Truncated || node.Error != "" {
+			attrs = append(attrs, "style=dashed")
+		}
+		fmt.Fprintf(&b, "  \"%s\" [%s];\n", dotEscape(node.ID), strings.Join(attrs, ", "))
+	}
+
+	for _, edge := range graph.Edges {
+		var attrs []string
+		if edge.Notes != "" {
+			attrs = append(attrs, fmt.Sprintf("label=\"%s\"", dotEscape(edge.Notes)))
+		}
+		if edge.Cycle {
+			attrs = append(attrs, "color=red")
+		}
+
+		fmt.Fprintf(&b, "  \"%s\" -> \"%s\"", dotEscape(edge.From), dotEscape(edge.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&b, " [%s]", strings.Join(attrs, ", "))
+		}
+		b.WriteString(";\n")
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotEscape escapes a string for use inside a double quoted DOT identifier.
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
@@ -0,0 +1,179 @@
+package downloads
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/report"
+)
+
+// The statuses of the downloaded archives checked.
+const (
+	// StatusMismatch is an archive named after a scraped file whose checksum differs,
+	// e.g. a corrupted or truncated download.
+	StatusMismatch = "mismatch"
+	// StatusOK is an archive whose checksum is the one of a scraped file.
+	StatusOK = "ok"
+	// StatusUnknown is an archive no scraped checksum could be found for.
+	StatusUnknown = "unknown"
+)
+
+// archiveNamePattern matches the name Nexus Mods gives the archives it serves, e.g.
+// "SkyUI_5_2_SE-12604-5-2SE-1573430936.7z": the file name, the mod ID, the version
+// with its dots turned into dashes and the upload timestamp.
+var archiveNamePattern = regexp.MustCompile(`^(.*?)-(\d+)-(.+)-(\d{9,11})\.[^.]+$`)
+
+// Archive is a downloaded archive checked against the scraped checksums.
+type Archive struct {
+	Expected []string `json:"expected,omitempty"`
+	File     string   `json:"file,omitempty"`
+	Game     string   `json:"game,omitempty"`
+	MD5      string   `json:"md5"`
+	ModID    int64    `json:"modId,omitempty"`
+	ModName  string   `json:"modName,omitempty"`
+	Path     string   `json:"path"`
+	Status   string   `json:"status"`
+}
+
+// Result is the outcome of checking a download directory, the archives in the order
+// of their paths.
+type Result struct {
+	Archives []Archive      `json:"archives"`
+	Counts   map[string]int `json:"counts"`
+}
+
+// scrapedFile is a scraped file that has a checksum, along with the mod it belongs to.
+type scrapedFile struct {
+	game    string
+	modID   int64
+	modName string
+	name    string
+	md5     string
+	version string
+}
+
+// Verify computes the MD5 checksum of every file under dir, except the .meta files
+// mod managers write next to the archives, and compares it with the checksums of the
+// files of the saved mods. An archive is ok when a scraped file has its checksum. It
+// is a mismatch when its Nexus Mods name gives the mod ID and version of scraped files
+// that have a different checksum, and unknown otherwise. Returns an error if the
+// directory or one of its files can't be read.
+func Verify(dir string, entries []report.Entry) (Result, error) {
+	byMD5 := map[string]scrapedFile{}
+	byMod := map[int64][]scrapedFile{}
+	for _, entry := range entries {
+		for _, file := range entry.Mod.Files {
+			if file.MD5 == "" {
+				continue
+			}
+			scraped := scrapedFile{game: entry.Game, modID: entry.Mod.ModID, modName: entry.Mod.Name, name: file.Name, md5: strings.ToLower(file.MD5), version: file.Version}
+			byMD5[scraped.md5] = scraped
+			byMod[scraped.modID] = append(byMod[scraped.modID], scraped)
+		}
+	}
+
+	paths, err := listFiles(dir)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{Archives: []Archive{}, Counts: map[string]int{}}
+	for _, path := range paths {
+		checksum, err := HashFile(path)
+		if err != nil {
+			return result, err
+		}
+
+		archive := Archive{MD5: checksum, Path: path, Status: StatusUnknown}
+		if scraped, ok := byMD5[checksum]; ok {
+			archive.File, archive.Game, archive.ModID, archive.ModName = scraped.name, scraped.game, scraped.modID, scraped.modName
+			archive.Status = StatusOK
+		} else if modID, version, ok := ParseArchiveName(filepath.Base(path)); ok {
+			for _, scraped := range byMod[modID] {
+				if dashedVersion(scraped.version) != version {
+					continue
+				}
+				archive.File, archive.Game, archive.ModID, archive.ModName = scraped.name, scraped.game, scraped.modID, scraped.modName
+				archive.Expected = append(archive.Expected, scraped.md5)
+				archive.Status = StatusMismatch
+			}
+		}
+		result.Counts[archive.Status]++
+		result.Archives = append(result.Archives, archive)
+	}
+	return result, nil
+}
+
+// ParseArchiveName returns the mod ID and the version, with its dots turned into
+// dashes, of an archive named the way Nexus Mods serves it. ok is false for other
+// names.
+func ParseArchiveName(name string) (modID int64, version string, ok bool) {
+	match := archiveNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return 0, "", false
+	}
+	modID, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return 0, "", false
+	}
+	return modID, strings.ToLower(match[3]), true
+}
+
+// HashFile returns the MD5 checksum of the file at path as lowercase hexadecimal,
+// reading it in chunks so large archives aren't held in memory.
+func HashFile(path string) (string, error) {
+	file, err := fsys.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	defer file.Close()
+
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("error reading %s: %w", path, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// dashedVersion returns the version as Nexus Mods writes it in archive names, e.g.
+// "5-2se" for "5.2SE".
+func dashedVersion(version string) string {
+	version = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(version)), "v")
+	return strings.NewReplacer(".", "-", " ", "-").Replace(version)
+}
+
+// listFiles returns the paths of the files under dir, subdirectories included, sorted,
+// leaving out the .meta files mod managers keep next to the archives.
+func listFiles(dir string) ([]string, error) {
+	entries, err := fsys.Default.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading download directory: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if entry.IsDir() {
+			nested, err := listFiles(path)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, nested...)
+			continue
+		}
+		if strings.EqualFold(filepath.Ext(entry.Name()), ".meta") {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
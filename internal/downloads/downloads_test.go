@@ -0,0 +1,74 @@
+package downloads
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/report"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArchiveName(t *testing.T) {
+	modID, version, ok := ParseArchiveName("SkyUI_5_2_SE-12604-5-2SE-1573430936.7z")
+
+	assert.True(t, ok)
+	assert.Equal(t, int64(12604), modID)
+	assert.Equal(t, "5-2se", version)
+
+	_, _, ok = ParseArchiveName("SkyUI.7z")
+	assert.False(t, ok)
+}
+
+func TestVerify(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	files := map[string]string{
+		"SkyUI_5_2_SE-12604-5-2SE-1573430936.7z":     "skyui",
+		"nested/Patch-12604-1-0-1600000000.zip":      "truncated",
+		"nested/Patch-12604-1-0-1600000000.zip.meta": "[General]",
+		"Unrelated-999-1-0-1600000000.zip":           "unrelated",
+		"renamed.7z":                                 "skyui",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	skyuiMD5, err := HashFile(filepath.Join(dir, "renamed.7z"))
+	require.NoError(t, err)
+	entries := []report.Entry{{Game: "skyrimspecialedition", Mod: types.ModInfo{ModID: 12604, Name: "SkyUI", Files: []types.File{
+		{Name: "SkyUI", Version: "5.2SE", MD5: skyuiMD5},
+		{Name: "Patch", Version: "1.0", MD5: "0123456789abcdef0123456789abcdef"},
+		{Name: "No checksum", Version: "2.0"},
+	}}}}
+
+	// Act
+	result, err := Verify(dir, entries)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{StatusOK: 2, StatusMismatch: 1, StatusUnknown: 1}, result.Counts)
+	require.Len(t, result.Archives, 4)
+	assert.Equal(t, StatusOK, result.Archives[0].Status)
+	assert.Equal(t, StatusUnknown, result.Archives[1].Status)
+	assert.Equal(t, Archive{
+		Expected: []string{"0123456789abcdef0123456789abcdef"},
+		File:     "Patch",
+		Game:     "skyrimspecialedition",
+		MD5:      result.Archives[2].MD5,
+		ModID:    12604,
+		ModName:  "SkyUI",
+		Path:     filepath.Join(dir, "nested", "Patch-12604-1-0-1600000000.zip"),
+		Status:   StatusMismatch,
+	}, result.Archives[2])
+	assert.Equal(t, StatusOK, result.Archives[3].Status)
+}
+
+func TestVerify_MissingDirectory(t *testing.T) {
+	_, err := Verify(filepath.Join(t.TempDir(), "missing"), nil)
+
+	assert.ErrorContains(t, err, "error reading download directory")
+}
@@ -0,0 +1,91 @@
+package emit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// dialTimeout bounds the time spent connecting to the listener.
+const dialTimeout = 10 * time.Second
+
+// Record is a single line of the stream: a scraped mod along with the game it belongs
+// to and the ID of the run that scraped it.
+type Record struct {
+	Game  string        `json:"Game"`
+	Mod   types.ModInfo `json:"Mod"`
+	RunID string        `json:"RunID,omitempty"`
+}
+
+// Emitter streams records as newline delimited JSON (NDJSON) over a connection to a
+// listener. An Emitter is safe for concurrent use.
+type Emitter struct {
+	mu      sync.Mutex
+	conn    net.Conn
+	encoder *json.Encoder
+}
+
+// Dial connects to the listener at target, given as tcp://host:port or
+// unix:///path/to/socket. Returns an error if the target is invalid or the listener
+// can't be reached.
+func Dial(target string) (*Emitter, error) {
+	network, address, err := parseTarget(target)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout(network, address, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to %s: %w", target, err)
+	}
+	return &Emitter{conn: conn, encoder: json.NewEncoder(conn)}, nil
+}
+
+// Emit writes the record as a single JSON line.
+func (e *Emitter) Emit(record Record) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.encoder.Encode(record); err != nil {
+		return fmt.Errorf("error emitting mod %d: %w", record.Mod.ModID, err)
+	}
+	return nil
+}
+
+// Close closes the connection, letting the listener know the stream is over.
+func (e *Emitter) Close() error {
+	return e.conn.Close()
+}
+
+// parseTarget splits an emit target into the network and address to dial.
+func parseTarget(target string) (string, string, error) {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid emit target %q: %w", target, err)
+	}
+
+	switch parsed.Scheme {
+	case "tcp":
+		if parsed.Host == "" {
+			return "", "", fmt.Errorf("invalid emit target %q, expected tcp://host:port", target)
+		}
+		return "tcp", parsed.Host, nil
+	case "unix":
+		path := parsed.Path
+		if path == "" {
+			// unix://relative.sock puts the path in the host part
+			path = parsed.Host
+		}
+		if path == "" {
+			return "", "", fmt.Errorf("invalid emit target %q, expected unix:///path/to/socket", target)
+		}
+		return "unix", path, nil
+	default:
+		return "", "", fmt.Errorf("unsupported emit target %q, expected tcp://host:port or unix:///path/to/socket", target)
+	}
+}
@@ -0,0 +1,104 @@
+package emit
+
+import (
+	"bufio"
+	"encoding/json"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTarget(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  string
+		network string
+		address string
+		wantErr string
+	}{
+		{name: "tcp", target: "tcp://127.0.0.1:9000", network: "tcp", address: "127.0.0.1:9000"},
+		{name: "unix", target: "unix:///run/nexus.sock", network: "unix", address: "/run/nexus.sock"},
+		{name: "unix relative", target: "unix://nexus.sock", network: "unix", address: "nexus.sock"},
+		{name: "tcp without host", target: "tcp://", wantErr: `invalid emit target "tcp://", expected tcp://host:port`},
+		{name: "unsupported scheme", target: "http://localhost", wantErr: `unsupported emit target "http://localhost", expected tcp://host:port or unix:///path/to/socket`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			network, address, err := parseTarget(tt.target)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.network, network)
+			assert.Equal(t, tt.address, address)
+		})
+	}
+}
+
+func TestEmitter(t *testing.T) {
+	for _, network := range []string{"tcp", "unix"} {
+		t.Run(network, func(t *testing.T) {
+			// Arrange
+			address := "127.0.0.1:0"
+			if network == "unix" {
+				address = filepath.Join(t.TempDir(), "emit.sock")
+			}
+			listener, err := net.Listen(network, address)
+			require.NoError(t, err)
+			defer listener.Close()
+
+			received := make(chan []Record, 1)
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					received <- nil
+					return
+				}
+				defer conn.Close()
+
+				var records []Record
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					var record Record
+					if json.Unmarshal(scanner.Bytes(), &record) == nil {
+						records = append(records, record)
+					}
+				}
+				received <- records
+			}()
+
+			emitter, err := Dial(network + "://" + listener.Addr().String())
+			require.NoError(t, err)
+
+			// Act
+			firstErr := emitter.Emit(Record{Game: "skyrim", Mod: types.ModInfo{ModID: 1, Name: "One"}, RunID: "run-1"})
+			secondErr := emitter.Emit(Record{Game: "skyrim", Mod: types.ModInfo{ModID: 2, Name: "Two"}, RunID: "run-1"})
+			closeErr := emitter.Close()
+
+			// Assert
+			assert.NoError(t, firstErr)
+			assert.NoError(t, secondErr)
+			assert.NoError(t, closeErr)
+			records := <-received
+			require.Len(t, records, 2)
+			assert.Equal(t, int64(1), records[0].Mod.ModID)
+			assert.Equal(t, "Two", records[1].Mod.Name)
+			assert.Equal(t, "run-1", records[1].RunID)
+		})
+	}
+}
+
+func TestDial_Unreachable(t *testing.T) {
+	_, err := Dial("unix://" + filepath.Join(t.TempDir(), "missing.sock"))
+
+	assert.ErrorContains(t, err, "error connecting to unix://")
+}
@@ -0,0 +1,56 @@
+package errors
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrAdultContentBlocked is returned when a mod page is hidden as adult content,
+	// which happens when the session cookies are missing, expired, or belong to an
+	// account that doesn't show adult content.
+	ErrAdultContentBlocked = errors.New("adult content detected, cookies not working")
+	// ErrNotFound is returned when a page doesn't exist, e.g. a mistyped game name or a
+	// mod that was removed.
+	ErrNotFound = errors.New("page not found")
+	// ErrRateLimited is returned when Nexus Mods refuses requests because too many were
+	// sent.
+	ErrRateLimited = errors.New("rate limited by Nexus Mods")
+)
+
+// remediations holds the steps to take to fix each typed error, in the order they are
+// checked.
+var remediations = []struct {
+	err   error
+	steps []string
+}{
+	{ErrAdultContentBlocked, []string{
+		`Enable "Show adult content" in the content blocking settings of your Nexus Mods account`,
+		"Re-run extract-cookies to refresh the session cookies, or check the ones given with --cookie-header",
+	}},
+	{ErrNotFound, []string{
+		"Check the game name as it appears in Nexus Mods URLs, e.g. skyrimspecialedition",
+		"Check the mod ID, the mod may also have been removed or hidden by its author",
+	}},
+	{ErrRateLimited, []string{
+		"Wait a few minutes before running again",
+		"Scrape fewer mods per run, e.g. with a lower --max-mods",
+	}},
+}
+
+// Remediation returns the steps to take to fix err, one per line, when it is one of
+// the typed errors of this package or wraps one. Returns an empty string otherwise.
+func Remediation(err error) string {
+	for _, remediation := range remediations {
+		if !errors.Is(err, remediation.err) {
+			continue
+		}
+
+		hint := "To fix it:"
+		for i, step := range remediation.steps {
+			hint += fmt.Sprintf("\n  %d. %s", i+1, step)
+		}
+		return hint
+	}
+	return ""
+}
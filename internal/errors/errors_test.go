@@ -0,0 +1,31 @@
+package errors
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRemediation(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{"nil", nil, ""},
+		{"untyped", fmt.Errorf("boom"), ""},
+		{"wrapped not found", fmt.Errorf("error scraping mod: %w", ErrNotFound), "To fix it:\n" +
+			"  1. Check the game name as it appears in Nexus Mods URLs, e.g. skyrimspecialedition\n" +
+			"  2. Check the mod ID, the mod may also have been removed or hidden by its author"},
+		{"rate limited", ErrRateLimited, "To fix it:\n" +
+			"  1. Wait a few minutes before running again\n" +
+			"  2. Scrape fewer mods per run, e.g. with a lower --max-mods"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Remediation(tt.err))
+		})
+	}
+}
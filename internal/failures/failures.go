@@ -0,0 +1,201 @@
+package failures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+)
+
+// Kinds of failure an error is classified as.
+const (
+	// KindAuth is a failure to authenticate, e.g. missing or expired cookies.
+	KindAuth = "auth"
+	// KindNetwork is a failure to reach Nexus Mods, including timeouts and error statuses.
+	KindNetwork = "network"
+	// KindParse is a failure to parse an argument, a page or a saved file.
+	KindParse = "parse"
+	// KindPartial is a run over several mods where some of them failed.
+	KindPartial = "partial"
+	// KindOther is any other failure.
+	KindOther = "other"
+)
+
+// Exit codes of the command line tool.
+const (
+	// ExitOK is returned when the command succeeds.
+	ExitOK = 0
+	// ExitFailure is returned for failures that aren't of a more specific kind.
+	ExitFailure = 1
+	// ExitAuth is returned when authentication fails.
+	ExitAuth = 2
+	// ExitNetwork is returned when Nexus Mods can't be reached.
+	ExitNetwork = 3
+	// ExitParse is returned when an argument, page or file can't be parsed.
+	ExitParse = 4
+	// ExitPartial is returned when some of the mods of a run failed and others succeeded.
+	ExitPartial = 5
+)
+
+// kindError marks an error as being of a given kind of failure, keeping its message.
+type kindError struct {
+	err  error
+	kind string
+}
+
+func (e *kindError) Error() string { return e.err.Error() }
+
+func (e *kindError) Unwrap() error { return e.err }
+
+// WithKind marks err as a failure of the given kind, overriding the kind it would be
+// classified as otherwise. The message of err is unchanged. Returns nil if err is nil.
+func WithKind(err error, kind string) error {
+	if err == nil {
+		return nil
+	}
+	return &kindError{err: err, kind: kind}
+}
+
+// RunError is the error of a run over several mods where some of them failed. When
+// none of the mods succeeded it unwraps to the first failure, so the run is classified
+// like it.
+type RunError struct {
+	Failed    int
+	First     error
+	Succeeded int
+	Total     int
+}
+
+func (e *RunError) Error() string {
+	return fmt.Sprintf("%d of %d mods failed to scrape", e.Failed, e.Total)
+}
+
+// Unwrap returns the first failure when no mod succeeded, and nil otherwise.
+func (e *RunError) Unwrap() error {
+	if e.Succeeded > 0 {
+		return nil
+	}
+	return e.First
+}
+
+// Classify returns the kind of failure err is: a run where some mods succeeded is a
+// partial failure, an error marked with WithKind is of the kind it was given, adult
+// content served to a visitor whose cookies don't work or a 401 or 403 status are
+// authentication failures, other error statuses, timeouts and connection errors are
+// network failures, and malformed JSON, numbers or dates are parse failures. Returns
+// an empty string if err is nil.
+func Classify(err error) string {
+	if err == nil {
+		return ""
+	}
+
+	var (
+		runErr    *RunError
+		kindErr   *kindError
+		statusErr *fetchers.StatusError
+		netErr    net.Error
+		urlErr    *url.Error
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+		numErr    *strconv.NumError
+		timeErr   *time.ParseError
+	)
+	switch {
+	case errors.As(err, &runErr) && runErr.Succeeded > 0:
+		return KindPartial
+	case errors.As(err, &kindErr):
+		return kindErr.kind
+	case errors.Is(err, fetchers.ErrAdultContent):
+		return KindAuth
+	case errors.As(err, &statusErr):
+		if statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusForbidden {
+			return KindAuth
+		}
+		return KindNetwork
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr), errors.As(err, &urlErr):
+		return KindNetwork
+	case errors.As(err, &syntaxErr), errors.As(err, &typeErr), errors.As(err, &numErr), errors.As(err, &timeErr):
+		return KindParse
+	}
+	return KindOther
+}
+
+// ExitCode returns the exit code of the command line tool for err: ExitOK when it is
+// nil, and otherwise the exit code of the kind of failure it is.
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	switch Classify(err) {
+	case KindPartial:
+		return ExitPartial
+	case KindAuth:
+		return ExitAuth
+	case KindNetwork:
+		return ExitNetwork
+	case KindParse:
+		return ExitParse
+	}
+	return ExitFailure
+}
+
+// Report is the machine readable summary of the errors of a run, written for
+// automation to branch on. Error holds the error that ended the run, if any, with its
+// kind, and Failed the mods that failed with the kind of each failure.
+type Report struct {
+	Error     string            `json:"Error,omitempty"`
+	ExitCode  int               `json:"ExitCode"`
+	Failed    []types.FailedMod `json:"Failed"`
+	Game      string            `json:"Game,omitempty"`
+	Kind      string            `json:"Kind,omitempty"`
+	RunID     string            `json:"RunID,omitempty"`
+	Succeeded int               `json:"Succeeded"`
+}
+
+// NewReport builds the report of a run from its summary and the error it ended with.
+func NewReport(game string, summary types.ScrapeSummary, err error) Report {
+	report := Report{
+		ExitCode:  ExitCode(err),
+		Failed:    summary.Failed,
+		Game:      game,
+		RunID:     summary.RunID,
+		Succeeded: len(summary.Succeeded),
+	}
+	if report.Failed == nil {
+		report.Failed = []types.FailedMod{}
+	}
+	if err != nil {
+		report.Error = err.Error()
+		report.Kind = Classify(err)
+	}
+	return report
+}
+
+// SaveReport writes the report to path as indented JSON, creating its directory if
+// needed.
+func SaveReport(path string, report Report) error {
+	if err := utils.EnsureDirExists(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting error report: %w", err)
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package failures
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassify(t *testing.T) {
+	_, numErr := strconv.ParseInt("toast", 10, 64)
+	tests := []struct {
+		name string
+		err  error
+		kind string
+	}{
+		{"nil", nil, ""},
+		{"marked", WithKind(errors.New("bad cookies"), KindAuth), KindAuth},
+		{"adult content", fmt.Errorf("error scraping mod: %w", fetchers.ErrAdultContent), KindAuth},
+		{"forbidden", &fetchers.StatusError{StatusCode: 403, Url: "https://example.com"}, KindAuth},
+		{"server error", &fetchers.StatusError{StatusCode: 502, Url: "https://example.com"}, KindNetwork},
+		{"deadline", context.DeadlineExceeded, KindNetwork},
+		{"json", json.Unmarshal([]byte("not json"), &struct{}{}), KindParse},
+		{"number", numErr, KindParse},
+		{"other", errors.New("boom"), KindOther},
+		{"partial", &RunError{Failed: 1, First: context.DeadlineExceeded, Succeeded: 1, Total: 2}, KindPartial},
+		{"all failed", &RunError{Failed: 2, First: context.DeadlineExceeded, Total: 2}, KindNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.kind, Classify(tt.err))
+		})
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+	}{
+		{"success", nil, ExitOK},
+		{"auth", fetchers.ErrAdultContent, ExitAuth},
+		{"network", context.DeadlineExceeded, ExitNetwork},
+		{"parse", &json.SyntaxError{}, ExitParse},
+		{"other", errors.New("boom"), ExitFailure},
+		{"partial", &RunError{Failed: 1, First: fetchers.ErrAdultContent, Succeeded: 2, Total: 3}, ExitPartial},
+		{"all failed", &RunError{Failed: 2, First: fetchers.ErrAdultContent, Total: 2}, ExitAuth},
+		{"wrapped", fmt.Errorf("error executing command: %w", context.DeadlineExceeded), ExitNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.code, ExitCode(tt.err))
+		})
+	}
+}
+
+func TestWithKind_KeepsMessage(t *testing.T) {
+	err := WithKind(errors.New("timed out after 10s"), KindNetwork)
+
+	assert.EqualError(t, err, "timed out after 10s")
+	assert.Nil(t, WithKind(nil, KindNetwork))
+}
+
+func TestSaveReport(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	path := filepath.Join("reports", "errors.json")
+	summary := types.ScrapeSummary{
+		RunID:     "run-1",
+		Succeeded: []int64{1},
+		Failed:    []types.FailedMod{{ModID: 2, Error: "timed out after 10s", Kind: KindNetwork}},
+	}
+	runErr := &RunError{Failed: 1, First: errors.New("timed out after 10s"), Succeeded: 1, Total: 2}
+
+	// Act
+	err := SaveReport(path, NewReport("skyrim", summary, runErr))
+
+	// Assert
+	require.NoError(t, err)
+	data, err := fsys.Default.ReadFile(path)
+	require.NoError(t, err)
+	var report Report
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, Report{
+		Error:     "1 of 2 mods failed to scrape",
+		ExitCode:  ExitPartial,
+		Failed:    summary.Failed,
+		Game:      "skyrim",
+		Kind:      KindPartial,
+		RunID:     "run-1",
+		Succeeded: 1,
+	}, report)
+}
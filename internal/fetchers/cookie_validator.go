@@ -0,0 +1,131 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+)
+
+// cookieValidationPath is the page requested to check whether cookies authenticate.
+// It is only reachable when logged in, anonymous requests are redirected to the login page.
+const cookieValidationPath = "/users/myaccount"
+
+const (
+	// loginLinkSelector matches the log in links of the header shown to logged out visitors.
+	loginLinkSelector = `a#login, a[href*="/login"], a[href*="users.nexusmods.com/auth"]`
+	// profileMenuSelector matches the profile menu of the header shown to logged in users,
+	// or the log out link it contains.
+	profileMenuSelector = `#user-profile-menu, #mem-profile, [data-e2eid="user-header-dropdown"], a[href*="logout"]`
+	// usernameSelector matches the username shown in the profile menu.
+	usernameSelector = `#user-profile-menu .username, #mem-profile .username, [data-e2eid="user-header-dropdown"] [data-e2eid="user-name"]`
+	// profileLinkSelector matches the links of the profile menu to the user's profile,
+	// whose path ends with the username when the menu doesn't show it.
+	profileLinkSelector = `#user-profile-menu a[href*="/profile/"], #mem-profile a[href*="/profile/"], [data-e2eid="user-header-dropdown"] a[href*="/profile/"]`
+)
+
+// Session describes the session a set of cookies opens: whether it is logged in and,
+// when the page shows it, the username it is logged in as.
+type Session struct {
+	LoggedIn bool
+	Username string
+}
+
+// ValidateCookies checks whether the provided cookies authenticate against the site at
+// baseUrl by requesting a page that requires a logged in session, without following
+// redirects. It returns true when the page is served directly (200 OK) with the header
+// of a logged in user, and false when the site redirects, refuses the request, or serves
+// a "soft logged out" page showing log in links or no profile menu. An error is returned
+// if the request fails or the page can't be parsed.
+func ValidateCookies(baseUrl string, cookies map[string]string) (bool, error) {
+	session, err := CheckSession(baseUrl, cookies)
+	return session.LoggedIn, err
+}
+
+// CheckSession checks the session the provided cookies open on the site at baseUrl like
+// ValidateCookies does, also reading the username it is logged in as from the profile
+// menu, left empty when the menu doesn't show it. An error is returned if the request
+// fails or the page can't be parsed.
+func CheckSession(baseUrl string, cookies map[string]string) (Session, error) {
+	client := &http.Client{
+		Timeout:   httpclient.Timeout(),
+		Transport: httpclient.Transport(),
+		// Don't follow the redirect to the login page, it is the signal that cookies are invalid
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	// Sort the cookies by name so that the Cookie header is the same on every run
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	jar := make([]*http.Cookie, 0, len(names))
+	for _, name := range names {
+		jar = append(jar, &http.Cookie{Name: name, Value: cookies[name]})
+	}
+
+	req, err := httpclient.NewBrowserRequest(context.Background(), SessionCheckUrl(baseUrl), jar)
+	if err != nil {
+		return Session{}, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return Session{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Session{}, nil
+	}
+
+	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	if err != nil {
+		return Session{}, fmt.Errorf("error parsing %s: %w", req.URL, err)
+	}
+	if !isLoggedInPage(doc) {
+		return Session{}, nil
+	}
+	return Session{LoggedIn: true, Username: sessionUsername(doc)}, nil
+}
+
+// SessionCheckUrl returns the URL of the page CheckSession requests on the site at
+// baseUrl to check the session of a set of cookies.
+func SessionCheckUrl(baseUrl string) string {
+	return strings.TrimRight(baseUrl, "/") + cookieValidationPath
+}
+
+// sessionUsername returns the username shown in the profile menu of the page, or the
+// last segment of the profile link of the menu, or "" when the menu shows neither.
+func sessionUsername(doc *goquery.Document) string {
+	if name := strings.TrimSpace(doc.Find(usernameSelector).First().Text()); name != "" {
+		return name
+	}
+	href, _ := doc.Find(profileLinkSelector).First().Attr("href")
+	if _, name, ok := strings.Cut(href, "/profile/"); ok {
+		name, _, _ = strings.Cut(name, "/")
+		name, _, _ = strings.Cut(name, "?")
+		if unescaped, err := url.PathUnescape(name); err == nil {
+			return unescaped
+		}
+	}
+	return ""
+}
+
+// isLoggedInPage reports whether the page was served to a logged in user: it has a
+// profile menu and no log in link. The site sometimes answers 200 with the logged out
+// header even though cookies were sent, which the status alone doesn't tell.
+func isLoggedInPage(doc *goquery.Document) bool {
+	if doc.Find(loginLinkSelector).Length() > 0 {
+		return false
+	}
+	return doc.Find(profileMenuSelector).Length() > 0
+}
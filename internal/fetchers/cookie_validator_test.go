@@ -0,0 +1,89 @@
+package fetchers
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateCookies(t *testing.T) {
+	// Arrange: the server only serves the account page to the "good" session, and a
+	// logged out page with a 200 status to the "soft" session
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, cookieValidationPath, r.URL.Path)
+		cookie, err := r.Cookie("nexusmods_session")
+		switch {
+		case err == nil && cookie.Value == "good":
+			fmt.Fprint(w, `<header><div id="user-profile-menu"><a href="/users/myaccount">Account</a><a href="/Core/Libs/Common/Widgets/Logout">Log out</a></div></header>`)
+		case err == nil && cookie.Value == "soft":
+			fmt.Fprint(w, `<header><a id="login" href="https://users.nexusmods.com/auth/continue">Log in</a></header>`)
+		case err == nil && cookie.Value == "bare":
+			fmt.Fprint(w, `<header><nav>Mods</nav></header>`)
+		default:
+			http.Redirect(w, r, "/login", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		cookies  map[string]string
+		expected bool
+	}{
+		{"Authenticated", map[string]string{"nexusmods_session": "good"}, true},
+		{"Redirected to login", map[string]string{"nexusmods_session": "bad"}, false},
+		{"Soft logged out with login link", map[string]string{"nexusmods_session": "soft"}, false},
+		{"Soft logged out without profile menu", map[string]string{"nexusmods_session": "bare"}, false},
+		{"No cookies", map[string]string{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			isValid, err := ValidateCookies(server.URL, tt.cookies)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, isValid)
+		})
+	}
+}
+
+func TestCheckSession_Username(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     string
+		expected string
+	}{
+		{"Username in the menu", `<div id="user-profile-menu"><span class="username">Arthmoor</span><a href="/logout">Log out</a></div>`, "Arthmoor"},
+		{"Username from the profile link", `<div data-e2eid="user-header-dropdown"><a href="/profile/Some%20One/about">Profile</a></div>`, "Some One"},
+		{"No username", `<div id="user-profile-menu"><a href="/logout">Log out</a></div>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, tt.page)
+			}))
+			defer server.Close()
+
+			// Act
+			session, err := CheckSession(server.URL, map[string]string{"nexusmods_session": "good"})
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Equal(t, Session{LoggedIn: true, Username: tt.expected}, session)
+		})
+	}
+}
+
+func TestValidateCookies_RequestError(t *testing.T) {
+	isValid, err := ValidateCookies("://invalid-url", map[string]string{})
+
+	assert.Error(t, err)
+	assert.False(t, isValid)
+}
@@ -1,24 +1,78 @@
 package fetchers
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
 	"net/url"
-	"strings"
 	"time"
 
+	nexuserrors "github.com/ondrovic/nexus-mods-scraper/internal/errors"
 	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
 
 	"github.com/PuerkitoBio/goquery"
 )
 
+var (
+	// ErrModFiltered is returned by FetchModInfoConcurrent when the mod was fetched but
+	// rejected by the provided filter, meaning the caller should skip it.
+	ErrModFiltered = errors.New("mod skipped by filters")
+	// ErrAdultContent is returned when a mod page is hidden as adult content, which
+	// happens when the cookies don't authenticate the visitor. It is the same error as
+	// ErrAdultContentBlocked of the internal errors package.
+	ErrAdultContent = nexuserrors.ErrAdultContentBlocked
+	// errModUnavailable stops the fetch of a mod that is no longer available, whose
+	// status is then returned in place of an error.
+	errModUnavailable = errors.New("mod no longer available")
+)
+
+// StatusError is returned by FetchDocument when a page answers with a status other
+// than 200 OK.
+type StatusError struct {
+	StatusCode int
+	Url        string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("failed to fetch document: %s returned %d", e.Url, e.StatusCode)
+}
+
+// Unwrap returns the typed error matching the status, ErrNotFound for 404 Not Found
+// and ErrRateLimited for 429 Too Many Requests, so that callers can tell them apart
+// with errors.Is. Returns nil for other statuses.
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return nexuserrors.ErrNotFound
+	case http.StatusTooManyRequests:
+		return nexuserrors.ErrRateLimited
+	}
+	return nil
+}
+
+// ModFilter decides whether a mod is still wanted once its main page has been
+// extracted. Returning false skips the mod.
+type ModFilter func(mod types.ModInfo) bool
+
 // FetchModInfoConcurrent retrieves mod information and file details concurrently
 // for a specified mod ID and game. It validates URLs and uses provided functions
-// for concurrent fetching of mod info and file info extraction. The results are populated
-// in the Results struct, and an error is returned if any fetching or extraction step fails.
-func FetchModInfoConcurrent(baseUrl, game string, modId int64, concurrentFetch func(tasks ...func() error) error, fetchDocument func(targetURL string) (*goquery.Document, error)) (types.Results, error) {
+// for concurrent fetching of mod info and file info extraction. The context bounds
+// both requests, so cancelling it or hitting its deadline aborts the fetch, and the
+// first request to fail cancels the other, its error being returned as is. When a
+// filter is provided and rejects the mod after its main page is extracted, the
+// in-flight files tab request is cancelled and ErrModFiltered is returned along with
+// the partially populated results. When ctx carries a selection of fields (see
+// WithFields), only those fields are returned and the files tab isn't requested unless
+// a selected field is read from it. A mod whose page is missing (404 Not Found) or
+// shows it as hidden or removed isn't an error: it is returned with its Status set,
+// along with whatever its page still shows. The results are populated in the Results
+// struct, and an error is returned if any fetching or extraction step fails.
+func FetchModInfoConcurrent(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
 	modUrl := fmt.Sprintf("%s/%s/mods/%d", baseUrl, game, modId)
 
 	// Validate the initial URL
@@ -26,26 +80,49 @@ func FetchModInfoConcurrent(baseUrl, game string, modId int64, concurrentFetch f
 		return types.Results{}, err
 	}
 
-	var results types.Results
+	var (
+		results types.Results
+		files   []types.File
+	)
 
-	// Function to handle mod info fetch
-	err := concurrentFetch(
-		func() error {
-			doc, err := fetchDocument(modUrl)
+	// Fetch the main page, along with the files tab unless no selected field needs it.
+	// Each task gets a context cancelled as soon as the other fails, so a filtered mod
+	// or a failed request aborts the sibling request.
+	fields := fieldsFromContext(ctx)
+	tasks := []func(ctx context.Context) error{
+		func(ctx context.Context) error {
+			doc, err := fetchDocument(ctx, modUrl)
+			if errors.Is(err, nexuserrors.ErrNotFound) {
+				results.Mods = types.ModInfo{LastChecked: time.Now(), ModID: modId.Int64(), Status: types.ModStatusNotFound, Url: modUrl}
+				return errModUnavailable
+			}
 			if err != nil {
 				return err
 			}
 
-			if extractors.IsAdultContent(doc, modId) {
-				return fmt.Errorf("adult content detected, cookies not working")
+			if extractors.IsAdultContent(doc, modId.Int64()) {
+				return ErrAdultContent
 			}
 
 			results.Mods = extractors.ExtractModInfo(doc)
-			results.Mods.ModID = modId
+			results.Mods.ModID = modId.Int64()
 			results.Mods.LastChecked = time.Now()
+
+			// Hidden and removed mods are reported with their status, unfiltered
+			if status := extractors.ModStatus(doc, modId.Int64()); status != "" {
+				results.Mods.Status = status
+				if results.Mods.Url == "" {
+					results.Mods.Url = modUrl
+				}
+				return errModUnavailable
+			}
+
+			if filter != nil && !filter(results.Mods) {
+				return ErrModFiltered
+			}
 			return nil
 		},
-		func() error {
+		func(ctx context.Context) error {
 			filesTabURL := fmt.Sprintf("%s?tab=files", modUrl)
 
 			// Validate files tab URL
@@ -53,64 +130,328 @@ func FetchModInfoConcurrent(baseUrl, game string, modId int64, concurrentFetch f
 				return err
 			}
 
-			filesDoc, err := fetchDocument(filesTabURL)
+			filesDoc, err := fetchDocument(ctx, filesTabURL)
+			if errors.Is(err, nexuserrors.ErrNotFound) {
+				// The main page tells whether the mod itself is gone
+				return nil
+			}
 			if err != nil {
 				return err
 			}
 
-			results.Mods.Files = extractors.ExtractFileInfo(filesDoc)
-			if len(results.Mods.Files) > 0 {
-				results.Mods.LatestVersion = results.Mods.Files[0].Version
-			}
-
+			files = extractors.ExtractFileInfo(filesDoc, modUrl)
 			return nil
 		},
-	)
+	}
+	if !fields.NeedsFilesTab() {
+		tasks = tasks[:1]
+	}
+	err := concurrentFetch(ctx, tasks...)
+
+	if errors.Is(err, ErrModFiltered) {
+		return results, ErrModFiltered
+	}
+
+	if errors.Is(err, errModUnavailable) {
+		return types.Results{Mods: fields.Apply(results.Mods)}, nil
+	}
 
 	if err != nil {
 		return types.Results{}, err
 	}
 
+	// Merge the files tab once both fetches are done so neither task overwrites the other
+	results.Mods.Files = files
+	if len(files) > 0 {
+		results.Mods.LatestVersion = files[0].Version
+	}
+
+	// Fall back to a version mentioned in the name or description for mods without file versions
+	if results.Mods.LatestVersion == "" {
+		results.Mods.LatestVersionGuess, results.Mods.LatestVersionGuessConfidence = extractors.GuessVersion(results.Mods)
+	}
+
+	results.Mods = fields.Apply(results.Mods)
 	return results, nil
 }
 
+// FetchModPage retrieves and extracts only the main page of a mod, skipping the files
+// tab. It is used where only the mod details and requirements are needed, such as when
+// walking dependencies. Returns an error if the page can't be fetched or is hidden as
+// adult content.
+func FetchModPage(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.ModInfo, error) {
+	modUrl := fmt.Sprintf("%s/%s/mods/%d", baseUrl, game, modId)
+
+	// Validate the URL
+	if _, err := url.Parse(modUrl); err != nil {
+		return types.ModInfo{}, err
+	}
+
+	doc, err := fetchDocument(ctx, modUrl)
+	if err != nil {
+		return types.ModInfo{}, err
+	}
+
+	if extractors.IsAdultContent(doc, modId.Int64()) {
+		return types.ModInfo{}, ErrAdultContent
+	}
+
+	mod := extractors.ExtractModInfo(doc)
+	mod.ModID = modId.Int64()
+	mod.Url = modUrl
+	mod.LastChecked = time.Now()
+	return mod, nil
+}
+
+// FetchCollection retrieves and extracts a collection page, including the mods it
+// contains, from the collections site at baseUrl (e.g. https://next.nexusmods.com).
+// Returns an error if the page can't be fetched or lists no mods.
+func FetchCollection(ctx context.Context, baseUrl, game, slug string, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Collection, error) {
+	collectionUrl := fmt.Sprintf("%s/%s/collections/%s", baseUrl, game, url.PathEscape(slug))
+
+	// Validate the URL
+	if _, err := url.Parse(collectionUrl); err != nil {
+		return types.Collection{}, err
+	}
+
+	doc, err := fetchDocument(ctx, collectionUrl)
+	if err != nil {
+		return types.Collection{}, err
+	}
+
+	collection := extractors.ExtractCollectionInfo(doc)
+	if len(collection.Mods) == 0 {
+		return types.Collection{}, fmt.Errorf("no mods found in collection %s", slug)
+	}
+
+	collection.Game = game
+	collection.Slug = slug
+	collection.Url = collectionUrl
+	collection.LastChecked = time.Now()
+	return collection, nil
+}
+
+// FetchUserMods retrieves the mods uploaded by an author by paginating through the
+// "Mods" tab of their profile, stopping after the last page, a page listing no new
+// mods, or maxPages pages when maxPages is above zero. Mods are listed once each in
+// the order they appear and their downloads and endorsements are totalled. Returns an
+// error if a page can't be fetched or the profile lists no mods.
+func FetchUserMods(ctx context.Context, baseUrl, username string, maxPages int, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.UserProfile, error) {
+	profileUrl := fmt.Sprintf("%s/profile/%s/mods", baseUrl, url.PathEscape(username))
+
+	// Validate the URL
+	if _, err := url.Parse(profileUrl); err != nil {
+		return types.UserProfile{}, err
+	}
+
+	profile := types.UserProfile{Username: username, Url: profileUrl}
+	seen := map[string]bool{}
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		doc, err := fetchDocument(ctx, fmt.Sprintf("%s?page=%d", profileUrl, page))
+		if err != nil {
+			return types.UserProfile{}, fmt.Errorf("error fetching page %d: %w", page, err)
+		}
+
+		added := 0
+		for _, mod := range extractors.ExtractUserMods(doc) {
+			key := fmt.Sprintf("%s/%d", mod.Game, mod.ModID)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			added++
+
+			profile.Mods = append(profile.Mods, mod)
+			profile.TotalDownloads += mod.Downloads
+			profile.TotalEndorsements += mod.Endorsements
+		}
+
+		if added == 0 || !extractors.HasNextPage(doc) {
+			break
+		}
+	}
+
+	if len(profile.Mods) == 0 {
+		return types.UserProfile{}, fmt.Errorf("no mods found for user %s", username)
+	}
+
+	profile.LastChecked = time.Now()
+	return profile, nil
+}
+
+// FetchUpdatedMods retrieves the mods of a game last updated within a date window by
+// paginating through its updated mods listing, which lists the most recently updated
+// mods first. Mods updated at or after before are skipped and the walk stops at the
+// first mod updated before after, so a zero before leaves the window open-ended. It
+// also stops after the last page, a page listing no new mods, or maxPages pages when
+// maxPages is above zero. Mods without an update time are skipped. Returns an error if
+// a page can't be fetched.
+func FetchUpdatedMods(ctx context.Context, baseUrl, game string, after, before time.Time, maxPages int, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) ([]types.ListedMod, error) {
+	listingUrl := fmt.Sprintf("%s/%s/mods/updated", baseUrl, url.PathEscape(game))
+
+	// Validate the URL
+	if _, err := url.Parse(listingUrl); err != nil {
+		return nil, err
+	}
+
+	var mods []types.ListedMod
+	seen := map[string]bool{}
+	for page := 1; maxPages <= 0 || page <= maxPages; page++ {
+		doc, err := fetchDocument(ctx, fmt.Sprintf("%s?page=%d", listingUrl, page))
+		if err != nil {
+			return nil, fmt.Errorf("error fetching page %d: %w", page, err)
+		}
+
+		added := 0
+		for _, mod := range extractors.ExtractUpdatedMods(doc) {
+			key := fmt.Sprintf("%s/%d", mod.Game, mod.ModID)
+			if seen[key] || mod.UpdatedAt == nil {
+				continue
+			}
+			seen[key] = true
+			added++
+
+			if mod.UpdatedAt.Before(after) {
+				return mods, nil
+			}
+			if !before.IsZero() && !mod.UpdatedAt.Before(before) {
+				continue
+			}
+			mods = append(mods, mod)
+		}
+
+		if added == 0 || !extractors.HasNextPage(doc) {
+			break
+		}
+	}
+
+	return mods, nil
+}
+
+// FetchGameInfo retrieves and extracts the landing page of a game, such as its mod,
+// collection and download counts and most endorsed mods. The categories are read from
+// the game's category listing when the landing page doesn't list them. Returns an error
+// if a page can't be fetched.
+func FetchGameInfo(ctx context.Context, baseUrl, game string, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameInfo, error) {
+	gameUrl := fmt.Sprintf("%s/%s", baseUrl, game)
+
+	// Validate the URL
+	if _, err := url.Parse(gameUrl); err != nil {
+		return types.GameInfo{}, err
+	}
+
+	doc, err := fetchDocument(ctx, gameUrl)
+	if err != nil {
+		return types.GameInfo{}, err
+	}
+
+	info := extractors.ExtractGameInfo(doc)
+	if len(info.Categories) == 0 {
+		categoriesDoc, err := fetchDocument(ctx, gameUrl+"/mods/categories")
+		if err != nil {
+			return types.GameInfo{}, fmt.Errorf("error fetching categories: %w", err)
+		}
+		info.Categories = extractors.ExtractCategories(categoriesDoc)
+	}
+	info.Game = game
+	info.Url = gameUrl
+	info.LastChecked = time.Now()
+	return info, nil
+}
+
+// FetchGameTags retrieves the taxonomy of a game: its categories from the category
+// listing and its tags from the tag filter of its mod search. Returns an error if a page
+// can't be fetched or neither lists anything, e.g. because the game doesn't exist.
+func FetchGameTags(ctx context.Context, baseUrl, game string, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.GameTags, error) {
+	modsUrl := fmt.Sprintf("%s/%s/mods", baseUrl, game)
+	categoriesUrl := modsUrl + "/categories"
+
+	// Validate the URL
+	if _, err := url.Parse(categoriesUrl); err != nil {
+		return types.GameTags{}, err
+	}
+
+	categoriesDoc, err := fetchDocument(ctx, categoriesUrl)
+	if err != nil {
+		return types.GameTags{}, fmt.Errorf("error fetching categories: %w", err)
+	}
+	tagsDoc, err := fetchDocument(ctx, modsUrl)
+	if err != nil {
+		return types.GameTags{}, fmt.Errorf("error fetching tags: %w", err)
+	}
+
+	tags := types.GameTags{
+		Categories: extractors.ExtractCategories(categoriesDoc),
+		Game:       game,
+		Tags:       extractors.ExtractTagFilter(tagsDoc),
+		Url:        categoriesUrl,
+	}
+	if len(tags.Categories) == 0 && len(tags.Tags) == 0 {
+		return types.GameTags{}, fmt.Errorf("no categories or tags found for game %s", game)
+	}
+
+	tags.LastChecked = time.Now()
+	return tags, nil
+}
+
+// WithClient returns a fetch function calling fetchDocument with client scoped to the
+// context of each request, so that every request of a run goes through the client and
+// cookie jar of that run rather than the global client.
+func WithClient(client httpclient.HTTPClient, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	return func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		return fetchDocument(httpclient.WithClient(ctx, client), targetURL)
+	}
+}
+
 // FetchDocument sends an HTTP GET request to the target URL, manually attaches cookies
-// from the HTTP client's cookie jar, and returns the response as a parsed goquery document.
-// It ensures a successful 200 OK status before parsing and returns an error if the request
-// or document parsing fails.
-func FetchDocument(targetURL string) (*goquery.Document, error) {
-	// Create a new HTTP GET request
-	req, err := http.NewRequest("GET", targetURL, nil)
+// from the cookie jar of the HTTP client scoped to the context, or of the global client
+// if there is none, and returns the response as a parsed goquery document.
+// The request is bound to the provided context so it can be cancelled or timed out.
+// It ensures a successful 200 OK status before parsing, returning a StatusError
+// otherwise, and returns an error if the request or document parsing fails. The request
+// waits on the Limiter carried by the context, if any. Each request and the bytes read
+// are counted in the usage stats.
+func FetchDocument(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	u, err := url.Parse(targetURL)
 	if err != nil {
 		return nil, err
 	}
 
-	// Manually retrieve cookies for the domain
-	u, _ := url.Parse(targetURL)
-	cookies := httpclient.Client.(*http.Client).Jar.Cookies(u)
+	// Manually retrieve cookies for the domain, from the jar of the client if it has one
+	client := httpclient.FromContext(ctx)
+	var cookies []*http.Cookie
+	if c, ok := client.(*http.Client); ok && c.Jar != nil {
+		cookies = c.Jar.Cookies(u)
+	}
 
-	// Build the Cookie header string manually from the cookies
-	var cookieHeader []string
-	for _, cookie := range cookies {
-		cookieHeader = append(cookieHeader, fmt.Sprintf("%s=%s", cookie.Name, cookie.Value))
+	req, err := httpclient.NewBrowserRequest(ctx, targetURL, cookies)
+	if err != nil {
+		return nil, err
 	}
-	req.Header.Set("Cookie", strings.Join(cookieHeader, "; "))
 
-	// Use the global httpclient.Client to make the request
-	resp, err := httpclient.Client.Do(req)
+	// Wait for the turn of the request within the rate limit of the run, if any, then
+	// use the run's client to make it
+	if err := httpclient.LimiterFrom(ctx).Wait(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
 
 	defer resp.Body.Close()
 
+	// Count the request and the bytes read in the usage stats
+	body := &countingReader{reader: resp.Body}
+	defer func() { usage.AddFetch(body.count) }()
+
 	// Ensure we received a 200 OK response
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to fetch document: %s returned %d", targetURL, resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode, Url: targetURL}
 	}
 
 	// Parse the response body into a goquery document
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	doc, err := goquery.NewDocumentFromReader(body)
 	if err != nil {
 		return nil, err
 	}
@@ -118,3 +459,15 @@ func FetchDocument(targetURL string) (*goquery.Document, error) {
 	// Return the goquery document
 	return doc, nil
 }
+
+// countingReader counts the bytes read through it.
+type countingReader struct {
+	count  int64
+	reader io.Reader
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.count += int64(n)
+	return n, err
+}
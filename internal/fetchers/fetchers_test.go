@@ -1,15 +1,27 @@
 package fetchers
 
 import (
+	"context"
+	"errors"
+	"fmt"
+
 	"github.com/PuerkitoBio/goquery"
+	nexuserrors "github.com/ondrovic/nexus-mods-scraper/internal/errors"
 	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"io"
 	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 type Mocker struct {
@@ -35,16 +47,16 @@ func (m *Mocker) RoundTrip(req *http.Request) (*http.Response, error) {
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
-var mockFetchDocument = func(_ string) (*goquery.Document, error) {
+var mockFetchDocument = func(_ context.Context, _ string) (*goquery.Document, error) {
 	html := `<html><body>Mocked HTML content</body></html>`
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
 	return doc, nil
 }
 
-var mockConcurrentFetch = func(tasks ...func() error) error {
+var mockConcurrentFetch = func(ctx context.Context, tasks ...func(ctx context.Context) error) error {
 	// Mock behavior: run all tasks sequentially without concurrency for simplicity in testing
 	for _, task := range tasks {
-		if err := task(); err != nil {
+		if err := task(ctx); err != nil {
 			return err
 		}
 	}
@@ -73,7 +85,7 @@ func TestFetchModInfoConcurrent_Success(t *testing.T) {
 	mockClient.On("Do", mock.Anything).Return(mockResponse, nil)
 
 	// Act
-	results, err := FetchModInfoConcurrent("https://example.com", "game", 12345, mockConcurrentFetch, mockFetchDocument)
+	results, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, nil, mockConcurrentFetch, mockFetchDocument)
 
 	// Assert
 	assert.NoError(t, err)
@@ -81,6 +93,366 @@ func TestFetchModInfoConcurrent_Success(t *testing.T) {
 
 }
 
+func TestFetchModInfoConcurrent_GuessesMissingVersion(t *testing.T) {
+	// Arrange
+	fetchDocument := func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		return goquery.NewDocumentFromReader(strings.NewReader(`<div id="pagetitle"><h1>MyMod v2.3</h1></div>`))
+	}
+
+	// Act
+	results, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, nil, mockConcurrentFetch, fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, results.Mods.LatestVersion)
+	assert.Equal(t, "2.3", results.Mods.LatestVersionGuess)
+	assert.Equal(t, "high", results.Mods.LatestVersionGuessConfidence)
+}
+
+func TestFetchModInfoConcurrent_FilteredCancelsFilesFetch(t *testing.T) {
+	// Arrange
+	filesCancelled := make(chan bool, 1)
+	fetchDocument := func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		if strings.HasSuffix(targetURL, "?tab=files") {
+			// Block until the filter cancels the request
+			<-ctx.Done()
+			filesCancelled <- true
+			return nil, ctx.Err()
+		}
+		return goquery.NewDocumentFromReader(strings.NewReader(`<div id="pagetitle"><h1>Filtered Mod</h1></div>`))
+	}
+	rejectAll := func(mod types.ModInfo) bool { return false }
+
+	// Act
+	results, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, rejectAll, utils.ConcurrentFetch, fetchDocument)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrModFiltered)
+	assert.Equal(t, "Filtered Mod", results.Mods.Name)
+	assert.True(t, <-filesCancelled, "files tab request should be cancelled")
+}
+
+func TestFetchModInfoConcurrent_FailedFetchCancelsOther(t *testing.T) {
+	// Arrange
+	fetchDocument := func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		if strings.HasSuffix(targetURL, "?tab=files") {
+			return nil, &StatusError{StatusCode: http.StatusBadGateway, Url: targetURL}
+		}
+		// Block until the failed files tab request cancels the main page
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	// Act
+	results, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, nil, utils.ConcurrentFetch, fetchDocument)
+
+	// Assert
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusBadGateway, statusErr.StatusCode)
+	assert.Empty(t, results.Mods.Name)
+}
+
+func TestFetchModInfoConcurrent_UnavailableMods(t *testing.T) {
+	tests := []struct {
+		name     string
+		page     func(targetURL string) (*goquery.Document, error)
+		expected types.ModInfo
+	}{
+		{
+			name: "not found",
+			page: func(targetURL string) (*goquery.Document, error) {
+				return nil, &StatusError{StatusCode: http.StatusNotFound, Url: targetURL}
+			},
+			expected: types.ModInfo{ModID: 12345, Status: types.ModStatusNotFound, Url: "https://example.com/game/mods/12345"},
+		},
+		{
+			name: "hidden",
+			page: func(targetURL string) (*goquery.Document, error) {
+				return goquery.NewDocumentFromReader(strings.NewReader(`<div id="pagetitle"><h1>Gone Mod</h1></div><h3 id="12345-title">Hidden mod</h3>`))
+			},
+			expected: types.ModInfo{ModID: 12345, Name: "Gone Mod", Status: types.ModStatusHidden, Url: "https://example.com/game/mods/12345"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetchDocument := func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+				return tt.page(targetURL)
+			}
+			rejectAll := func(mod types.ModInfo) bool { return false }
+
+			results, err := FetchModInfoConcurrent(context.Background(), "https://example.com", "game", 12345, rejectAll, utils.ConcurrentFetch, fetchDocument)
+
+			require.NoError(t, err)
+			assert.False(t, results.Mods.LastChecked.IsZero())
+			assert.Equal(t, tt.expected.ModID, results.Mods.ModID)
+			assert.Equal(t, tt.expected.Name, results.Mods.Name)
+			assert.Equal(t, tt.expected.Status, results.Mods.Status)
+			assert.Equal(t, tt.expected.Url, results.Mods.Url)
+			assert.Empty(t, results.Mods.Files)
+		})
+	}
+}
+
+func TestStatusError_Unwrap(t *testing.T) {
+	assert.ErrorIs(t, &StatusError{StatusCode: http.StatusNotFound}, nexuserrors.ErrNotFound)
+	assert.ErrorIs(t, &StatusError{StatusCode: http.StatusTooManyRequests}, nexuserrors.ErrRateLimited)
+	assert.NoError(t, (&StatusError{StatusCode: http.StatusBadGateway}).Unwrap())
+}
+
+func TestFetchModInfoConcurrent_FieldsSkipFilesTab(t *testing.T) {
+	// Arrange
+	var (
+		mu        sync.Mutex
+		requested []string
+	)
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		mu.Lock()
+		requested = append(requested, targetURL)
+		mu.Unlock()
+		return goquery.NewDocumentFromReader(strings.NewReader(`
+			<div id="pagetitle"><h1>Mod Name</h1></div>
+			<div class="mod_description_container">A description</div>`))
+	}
+	changeLogsOnly, err := ParseFields([]string{"changelogs"})
+	require.NoError(t, err)
+	withFiles, err := ParseFields([]string{"files"})
+	require.NoError(t, err)
+
+	// Act
+	results, err := FetchModInfoConcurrent(WithFields(context.Background(), changeLogsOnly), "https://example.com", "game", 12345, nil, utils.ConcurrentFetch, fetchDocument)
+	skipped := requested
+	requested = nil
+	_, filesErr := FetchModInfoConcurrent(WithFields(context.Background(), withFiles), "https://example.com", "game", 12345, nil, utils.ConcurrentFetch, fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/game/mods/12345"}, skipped)
+	assert.Equal(t, "Mod Name", results.Mods.Name)
+	assert.Equal(t, int64(12345), results.Mods.ModID)
+	assert.Empty(t, results.Mods.Description, "unselected fields are cleared")
+	assert.NotNil(t, results.Mods.ChangeLogs)
+	assert.NoError(t, filesErr)
+	assert.Len(t, requested, 2)
+}
+
+func TestFetchModPage_OnlyFetchesMainPage(t *testing.T) {
+	// Arrange
+	var requested []string
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		requested = append(requested, targetURL)
+		return goquery.NewDocumentFromReader(strings.NewReader(`<div id="pagetitle"><h1>Main Page</h1></div>`))
+	}
+
+	// Act
+	mod, err := FetchModPage(context.Background(), "https://example.com", "game", 12345, fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/game/mods/12345"}, requested)
+	assert.Equal(t, "Main Page", mod.Name)
+	assert.Equal(t, int64(12345), mod.ModID)
+	assert.Equal(t, "https://example.com/game/mods/12345", mod.Url)
+}
+
+func TestFetchCollection(t *testing.T) {
+	// Arrange
+	var requested string
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		requested = targetURL
+		return goquery.NewDocumentFromReader(strings.NewReader(`<h1>Essentials</h1><a href="https://www.nexusmods.com/skyrim/mods/1">One</a>`))
+	}
+
+	// Act
+	collection, err := FetchCollection(context.Background(), "https://next.example.com", "skyrim", "abc123", fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "https://next.example.com/skyrim/collections/abc123", requested)
+	assert.Equal(t, "abc123", collection.Slug)
+	assert.Len(t, collection.Mods, 1)
+}
+
+func TestFetchCollection_NoMods(t *testing.T) {
+	_, err := FetchCollection(context.Background(), "https://next.example.com", "skyrim", "empty", mockFetchDocument)
+
+	assert.EqualError(t, err, "no mods found in collection empty")
+}
+
+func TestFetchUserMods_Paginates(t *testing.T) {
+	// Arrange
+	pages := map[string]string{
+		"https://example.com/profile/some%20author/mods?page=1": `
+			<li class="mod-tile"><p class="tile-name"><a href="https://www.nexusmods.com/skyrim/mods/1">One</a></p><span class="downloadcount">100</span><span class="endorsecount">5</span></li>
+			<div class="pagination"><a class="next" href="?page=2">Next</a></div>`,
+		"https://example.com/profile/some%20author/mods?page=2": `
+			<li class="mod-tile"><p class="tile-name"><a href="https://www.nexusmods.com/skyrim/mods/1">One</a></p></li>
+			<li class="mod-tile"><p class="tile-name"><a href="https://www.nexusmods.com/fallout4/mods/2">Two</a></p><span class="downloadcount">50</span><span class="endorsecount">1</span></li>`,
+	}
+	var requested []string
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		requested = append(requested, targetURL)
+		return goquery.NewDocumentFromReader(strings.NewReader(pages[targetURL]))
+	}
+
+	// Act
+	profile, err := FetchUserMods(context.Background(), "https://example.com", "some author", 0, fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, requested, 2)
+	assert.Equal(t, "https://example.com/profile/some%20author/mods", profile.Url)
+	assert.Len(t, profile.Mods, 2)
+	assert.Equal(t, int64(150), profile.TotalDownloads)
+	assert.Equal(t, int64(6), profile.TotalEndorsements)
+}
+
+func TestFetchUserMods_MaxPages(t *testing.T) {
+	// Arrange
+	var requested int
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		requested++
+		return goquery.NewDocumentFromReader(strings.NewReader(fmt.Sprintf(
+			`<li class="mod-tile"><p class="tile-name"><a href="https://www.nexusmods.com/skyrim/mods/%d">Mod</a></p></li><a rel="next">Next</a>`, requested)))
+	}
+
+	// Act
+	profile, err := FetchUserMods(context.Background(), "https://example.com", "author", 3, fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, requested)
+	assert.Len(t, profile.Mods, 3)
+}
+
+func TestFetchUserMods_NoMods(t *testing.T) {
+	_, err := FetchUserMods(context.Background(), "https://example.com", "nobody", 0, mockFetchDocument)
+
+	assert.EqualError(t, err, "no mods found for user nobody")
+}
+
+func TestFetchUpdatedMods_Window(t *testing.T) {
+	// Arrange
+	tile := func(id int, date string) string {
+		return fmt.Sprintf(`<li class="mod-tile"><p class="tile-name"><a href="https://www.nexusmods.com/skyrim/mods/%d">Mod</a></p><time datetime="%s"></time></li>`, id, date)
+	}
+	pages := map[string]string{
+		"https://example.com/skyrim/mods/updated?page=1": tile(1, "2024-07-02") + tile(2, "2024-06-30") + `<a rel="next">Next</a>`,
+		"https://example.com/skyrim/mods/updated?page=2": tile(2, "2024-06-30") + tile(3, "2024-06-01") + tile(4, "2024-05-31") + tile(5, "2024-06-15") + `<a rel="next">Next</a>`,
+	}
+	var requested []string
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		requested = append(requested, targetURL)
+		return goquery.NewDocumentFromReader(strings.NewReader(pages[targetURL]))
+	}
+	after := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// Act
+	mods, err := FetchUpdatedMods(context.Background(), "https://example.com", "skyrim", after, before, 0, fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Len(t, requested, 2)
+	if assert.Len(t, mods, 2) {
+		assert.Equal(t, int64(2), mods[0].ModID)
+		assert.Equal(t, int64(3), mods[1].ModID)
+	}
+}
+
+func TestFetchUpdatedMods_MaxPages(t *testing.T) {
+	// Arrange
+	var requested int
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		requested++
+		return goquery.NewDocumentFromReader(strings.NewReader(fmt.Sprintf(
+			`<li class="mod-tile"><p class="tile-name"><a href="https://www.nexusmods.com/skyrim/mods/%d">Mod</a></p><time datetime="2024-06-15"></time></li><a rel="next">Next</a>`, requested)))
+	}
+
+	// Act
+	mods, err := FetchUpdatedMods(context.Background(), "https://example.com", "skyrim", time.Time{}, time.Time{}, 2, fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, requested)
+	assert.Len(t, mods, 2)
+}
+
+func TestFetchUpdatedMods_FetchError(t *testing.T) {
+	fetchDocument := func(_ context.Context, _ string) (*goquery.Document, error) {
+		return nil, errors.New("boom")
+	}
+
+	_, err := FetchUpdatedMods(context.Background(), "https://example.com", "skyrim", time.Time{}, time.Time{}, 0, fetchDocument)
+
+	assert.EqualError(t, err, "error fetching page 1: boom")
+}
+
+func TestFetchGameInfo(t *testing.T) {
+	// Arrange
+	var requested []string
+	pages := map[string]string{
+		"https://example.com/fallout4":                 `<div id="pagetitle"><h1>Fallout 4</h1></div>`,
+		"https://example.com/fallout4/mods/categories": `<ul class="categories"><li><a href="/fallout4/mods/categories/2">Armour (1,234)</a></li></ul>`,
+	}
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		requested = append(requested, targetURL)
+		return goquery.NewDocumentFromReader(strings.NewReader(pages[targetURL]))
+	}
+
+	// Act
+	info, err := FetchGameInfo(context.Background(), "https://example.com", "fallout4", fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://example.com/fallout4", "https://example.com/fallout4/mods/categories"}, requested)
+	assert.Equal(t, "Fallout 4", info.Name)
+	assert.Equal(t, "fallout4", info.Game)
+	assert.Equal(t, "https://example.com/fallout4", info.Url)
+	assert.Equal(t, []types.TaxonomyEntry{{Count: 1234, Name: "Armour", Url: "/fallout4/mods/categories/2"}}, info.Categories)
+}
+
+func TestFetchGameInfo_CategoriesError(t *testing.T) {
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		if strings.HasSuffix(targetURL, "/categories") {
+			return nil, errors.New("boom")
+		}
+		return goquery.NewDocumentFromReader(strings.NewReader(`<div id="pagetitle"><h1>Fallout 4</h1></div>`))
+	}
+
+	_, err := FetchGameInfo(context.Background(), "https://example.com", "fallout4", fetchDocument)
+
+	assert.EqualError(t, err, "error fetching categories: boom")
+}
+
+func TestFetchGameTags(t *testing.T) {
+	// Arrange
+	pages := map[string]string{
+		"https://example.com/skyrim/mods/categories": `<ul class="categories"><li><a href="/skyrim/mods/categories/2">Armour (1,234)</a></li></ul>`,
+		"https://example.com/skyrim/mods":            `<ul class="tags"><li><a href="?tag=lore">Lore</a></li></ul>`,
+	}
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		return goquery.NewDocumentFromReader(strings.NewReader(pages[targetURL]))
+	}
+
+	// Act
+	tags, err := FetchGameTags(context.Background(), "https://example.com", "skyrim", fetchDocument)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "skyrim", tags.Game)
+	assert.Equal(t, "https://example.com/skyrim/mods/categories", tags.Url)
+	assert.Equal(t, []types.TaxonomyEntry{{Count: 1234, Name: "Armour", Url: "/skyrim/mods/categories/2"}}, tags.Categories)
+	assert.Equal(t, []types.TaxonomyEntry{{Name: "Lore", Url: "?tag=lore"}}, tags.Tags)
+}
+
+func TestFetchGameTags_NothingFound(t *testing.T) {
+	_, err := FetchGameTags(context.Background(), "https://example.com", "nogame", mockFetchDocument)
+
+	assert.EqualError(t, err, "no categories or tags found for game nogame")
+}
+
 func TestFetchDocument_Success(t *testing.T) {
 	// Arrange
 	targetURL := "https://example.com"
@@ -109,7 +481,7 @@ func TestFetchDocument_Success(t *testing.T) {
 	mockTransport.On("RoundTrip", mock.Anything).Return(mockResponse, nil)
 
 	// Act
-	doc, err := FetchDocument(targetURL)
+	doc, err := FetchDocument(context.Background(), targetURL)
 
 	// Assert
 	assert.NoError(t, err) // Ensure no error occurred
@@ -126,13 +498,92 @@ func TestFetchDocument_Success(t *testing.T) {
 
 func TestFetchDocument_RequestError(t *testing.T) {
 	// Arrange
+	jar, _ := cookiejar.New(nil)
+	ctx := httpclient.WithClient(context.Background(), &http.Client{Jar: jar})
 	targetURL := "://invalid-url"
 
 	// Act
-	doc, err := FetchDocument(targetURL)
+	doc, err := FetchDocument(ctx, targetURL)
 
 	// Assert
 	assert.Nil(t, doc)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "missing protocol scheme")
 }
+
+func TestFetchDocument_ContextCanceled(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("request should not reach the server")
+	}))
+	defer server.Close()
+
+	jar, _ := cookiejar.New(nil)
+	httpclient.Client = &http.Client{Jar: jar}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	doc, err := FetchDocument(ctx, server.URL)
+
+	// Assert
+	assert.Nil(t, doc)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestFetchDocument_RateLimited(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html></html>")
+	}))
+	defer server.Close()
+	jar, _ := cookiejar.New(nil)
+	ctx := httpclient.WithClient(context.Background(), &http.Client{Jar: jar})
+	ctx = httpclient.WithLimiter(ctx, httpclient.NewLimiter(20*time.Millisecond))
+	start := time.Now()
+
+	// Act
+	for i := 0; i < 3; i++ {
+		_, err := FetchDocument(ctx, server.URL)
+		require.NoError(t, err)
+	}
+
+	// Assert
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestWithClient_ScopesCookiesToTheRun(t *testing.T) {
+	// Arrange: the global client and two runs each hold a different session
+	var received []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = append(received, r.Header.Get("Cookie"))
+		fmt.Fprint(w, "<html></html>")
+	}))
+	defer server.Close()
+
+	newClient := func(session string) *http.Client {
+		client, err := httpclient.NewClient(server.URL, "", "", "nexusmods_session="+session)
+		assert.NoError(t, err)
+		return client
+	}
+	httpclient.Client = newClient("global")
+	first := WithClient(newClient("first"), FetchDocument)
+	second := WithClient(newClient("second"), FetchDocument)
+
+	// Act
+	_, firstErr := first(context.Background(), server.URL)
+	_, secondErr := second(context.Background(), server.URL)
+	_, globalErr := FetchDocument(context.Background(), server.URL)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.NoError(t, globalErr)
+	assert.Len(t, received, 3)
+	for i, session := range []string{"first", "second", "global"} {
+		for _, cookie := range strings.Split(received[i], "; ") {
+			assert.Equal(t, "nexusmods_session="+session, cookie)
+		}
+	}
+}
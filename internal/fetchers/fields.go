@@ -0,0 +1,121 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// identityFields are the fields of a mod kept whatever fields are selected, so that
+// scraped mods can always be told apart, saved under their usual name and flagged
+// when no longer available.
+var identityFields = map[string]bool{
+	"lastchecked": true,
+	"modid":       true,
+	"name":        true,
+	"status":      true,
+	"url":         true,
+}
+
+// filesTabFields are the fields of a mod read from its files tab. The files tab is
+// only requested when one of them is selected.
+var filesTabFields = map[string]bool{
+	"files":         true,
+	"latestversion": true,
+}
+
+// fieldsKey is the context key the selected fields are stored under.
+type fieldsKey struct{}
+
+// Fields is a selection of the fields of a mod to scrape, by their lowercased JSON
+// names, e.g. "changelogs" or "files". An empty selection scrapes every field.
+type Fields map[string]bool
+
+// ParseFields returns the selection of the named fields, matched case-insensitively
+// against the JSON names of ModInfo, e.g. "ChangeLogs" or "changelogs". Returns an
+// error naming the first unknown field.
+func ParseFields(names []string) (Fields, error) {
+	known := modFieldIndexes()
+	fields := Fields{}
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		if _, ok := known[name]; !ok {
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", name, strings.Join(FieldNames(), ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// FieldNames returns the sorted, lowercased JSON names of the fields of a mod that
+// can be selected.
+func FieldNames() []string {
+	names := make([]string, 0, len(modFieldIndexes()))
+	for name := range modFieldIndexes() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NeedsFilesTab reports whether any selected field is read from the files tab.
+func (f Fields) NeedsFilesTab() bool {
+	if len(f) == 0 {
+		return true
+	}
+	for name := range f {
+		if filesTabFields[name] {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns mod with the fields that aren't selected cleared, keeping the fields
+// identifying the mod. An empty selection returns mod unchanged.
+func (f Fields) Apply(mod types.ModInfo) types.ModInfo {
+	if len(f) == 0 {
+		return mod
+	}
+
+	source := reflect.ValueOf(mod)
+	var selected types.ModInfo
+	target := reflect.ValueOf(&selected).Elem()
+	for name, index := range modFieldIndexes() {
+		if f[name] || identityFields[name] {
+			target.Field(index).Set(source.Field(index))
+		}
+	}
+	return selected
+}
+
+// WithFields returns a copy of ctx carrying the fields to scrape, which
+// FetchModInfoConcurrent only fetches and returns.
+func WithFields(ctx context.Context, fields Fields) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, fields)
+}
+
+// fieldsFromContext returns the fields carried by ctx, or an empty selection.
+func fieldsFromContext(ctx context.Context) Fields {
+	fields, _ := ctx.Value(fieldsKey{}).(Fields)
+	return fields
+}
+
+// modFieldIndexes returns the index of each field of ModInfo by its lowercased JSON
+// name.
+func modFieldIndexes() map[string]int {
+	modType := reflect.TypeOf(types.ModInfo{})
+	indexes := make(map[string]int, modType.NumField())
+	for i := 0; i < modType.NumField(); i++ {
+		name, _, _ := strings.Cut(modType.Field(i).Tag.Get("json"), ",")
+		indexes[strings.ToLower(name)] = i
+	}
+	return indexes
+}
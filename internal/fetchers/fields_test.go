@@ -0,0 +1,51 @@
+package fetchers
+
+import (
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFields(t *testing.T) {
+	// Act
+	fields, err := ParseFields([]string{"Name", " changelogs ", ""})
+	_, unknownErr := ParseFields([]string{"changelog"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, Fields{"name": true, "changelogs": true}, fields)
+	assert.ErrorContains(t, unknownErr, `unknown field "changelog", expected one of `)
+	assert.ErrorContains(t, unknownErr, "changelogs")
+}
+
+func TestFields_NeedsFilesTab(t *testing.T) {
+	assert.True(t, Fields{}.NeedsFilesTab())
+	assert.True(t, Fields{"files": true}.NeedsFilesTab())
+	assert.True(t, Fields{"latestversion": true, "name": true}.NeedsFilesTab())
+	assert.False(t, Fields{"changelogs": true}.NeedsFilesTab())
+}
+
+func TestFields_Apply(t *testing.T) {
+	// Arrange
+	mod := types.ModInfo{
+		ChangeLogs:  []types.ChangeLog{{Version: "1.0"}},
+		Description: "A description",
+		ModID:       1,
+		Name:        "Mod",
+		Url:         "https://example.com/game/mods/1",
+	}
+
+	// Act
+	selected := Fields{"changelogs": true}.Apply(mod)
+	unchanged := Fields{}.Apply(mod)
+
+	// Assert
+	assert.Equal(t, types.ModInfo{
+		ChangeLogs: []types.ChangeLog{{Version: "1.0"}},
+		ModID:      1,
+		Name:       "Mod",
+		Url:        "https://example.com/game/mods/1",
+	}, selected)
+	assert.Equal(t, mod, unchanged)
+}
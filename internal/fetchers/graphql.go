@@ -0,0 +1,318 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// DefaultGraphQLEndpoint is the GraphQL API backing the redesigned Nexus Mods frontend.
+const DefaultGraphQLEndpoint = "https://api-router.nexusmods.com/graphql"
+
+// The backends mods can be fetched with: by parsing their pages with
+// FetchModInfoConcurrent, or from the GraphQL API with GraphQL.
+const (
+	BackendGraphQL = "graphql"
+	BackendHtml    = "html"
+)
+
+const (
+	// modQuery looks up a mod by game domain and ID, along with the numeric ID of its
+	// game that modFilesQuery needs.
+	modQuery = `query Mod($ids: [CompositeDomainWithIdInput!]!) {
+  legacyModsByDomain(ids: $ids) {
+    nodes {
+      author createdAt description downloads endorsements modId name status summary updatedAt version
+      game { id }
+      modCategory { name }
+      uploader { name }
+    }
+  }
+}`
+	// modFilesQuery lists the files of a mod, newest first.
+	modFilesQuery = `query ModFiles($modId: ID!, $gameId: ID!) {
+  modFiles(modId: $modId, gameId: $gameId) {
+    category date description fileId name sizeInBytes uniqueDownloads totalDownloads version
+  }
+}`
+)
+
+// GraphQL fetches mods from the GraphQL API of the redesigned Nexus Mods frontend
+// rather than by parsing their pages, which is faster and doesn't break when the
+// markup changes. Requests are sent with the session cookies the client holds for the
+// mod's site.
+type GraphQL struct {
+	Client   httpclient.HTTPClient
+	Endpoint string
+}
+
+// graphQLMod is a mod as returned by modQuery.
+type graphQLMod struct {
+	Author       string `json:"author"`
+	CreatedAt    string `json:"createdAt"`
+	Description  string `json:"description"`
+	Downloads    int64  `json:"downloads"`
+	Endorsements int64  `json:"endorsements"`
+	Game         struct {
+		ID json.Number `json:"id"`
+	} `json:"game"`
+	ModCategory struct {
+		Name string `json:"name"`
+	} `json:"modCategory"`
+	ModID     int64  `json:"modId"`
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	Summary   string `json:"summary"`
+	UpdatedAt string `json:"updatedAt"`
+	Uploader  struct {
+		Name string `json:"name"`
+	} `json:"uploader"`
+	Version string `json:"version"`
+}
+
+// graphQLFile is a file as returned by modFilesQuery.
+type graphQLFile struct {
+	Category        string      `json:"category"`
+	Date            json.Number `json:"date"`
+	Description     string      `json:"description"`
+	FileID          int64       `json:"fileId"`
+	Name            string      `json:"name"`
+	SizeInBytes     json.Number `json:"sizeInBytes"`
+	TotalDownloads  int64       `json:"totalDownloads"`
+	UniqueDownloads int64       `json:"uniqueDownloads"`
+	Version         string      `json:"version"`
+}
+
+// FetchModInfo retrieves the mod and, unless no selected field needs them (see
+// WithFields), its files from the GraphQL API. It has the signature of
+// FetchModInfoConcurrent so that it can stand in for it: baseUrl is only used to build
+// the mod URL and find the session cookies, and the pages fetchers are ignored. A mod
+// the API doesn't know is returned with its Status set to not found, and one it reports
+// as hidden or removed with that status, as FetchModInfoConcurrent does. When a filter
+// is provided and rejects the mod, its files aren't requested and ErrModFiltered is
+// returned along with the mod. Returns an error if a request fails or the API answers
+// with errors.
+func (g GraphQL) FetchModInfo(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter ModFilter, _ func(ctx context.Context, tasks ...func(ctx context.Context) error) error, _ func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error) {
+	modUrl := fmt.Sprintf("%s/%s/mods/%d", baseUrl, game, modId)
+	fields := fieldsFromContext(ctx)
+
+	var modData struct {
+		LegacyModsByDomain struct {
+			Nodes []graphQLMod `json:"nodes"`
+		} `json:"legacyModsByDomain"`
+	}
+	variables := map[string]any{"ids": []map[string]any{{"gameDomain": game.String(), "modId": modId.Int64()}}}
+	if err := g.query(ctx, baseUrl, modQuery, variables, &modData); err != nil {
+		return types.Results{}, err
+	}
+
+	if len(modData.LegacyModsByDomain.Nodes) == 0 {
+		mod := types.ModInfo{LastChecked: time.Now(), ModID: modId.Int64(), Status: types.ModStatusNotFound, Url: modUrl}
+		return types.Results{Mods: fields.Apply(mod)}, nil
+	}
+	node := modData.LegacyModsByDomain.Nodes[0]
+	mod := node.modInfo()
+	mod.ModID = modId.Int64()
+	mod.LastChecked = time.Now()
+
+	if status := graphQLStatus(node.Status); status != "" {
+		mod.Status = status
+		mod.Url = modUrl
+		return types.Results{Mods: fields.Apply(mod)}, nil
+	}
+
+	if filter != nil && !filter(mod) {
+		return types.Results{Mods: mod}, ErrModFiltered
+	}
+
+	if fields.NeedsFilesTab() {
+		var filesData struct {
+			ModFiles []graphQLFile `json:"modFiles"`
+		}
+		variables := map[string]any{"modId": strconv.FormatInt(modId.Int64(), 10), "gameId": node.Game.ID.String()}
+		if err := g.query(ctx, baseUrl, modFilesQuery, variables, &filesData); err != nil {
+			return types.Results{}, err
+		}
+
+		mod.Files = make([]types.File, 0, len(filesData.ModFiles))
+		for _, file := range filesData.ModFiles {
+			mod.Files = append(mod.Files, file.file(modUrl))
+		}
+		if len(mod.Files) > 0 {
+			mod.LatestVersion = mod.Files[0].Version
+		}
+	}
+
+	// Fall back to a version mentioned in the name or description for mods without file versions
+	if mod.LatestVersion == "" {
+		mod.LatestVersionGuess, mod.LatestVersionGuessConfidence = extractors.GuessVersion(mod)
+	}
+
+	return types.Results{Mods: fields.Apply(mod)}, nil
+}
+
+// query posts the GraphQL query with its variables to the endpoint, with the browser
+// headers and the session cookies the client holds for baseUrl, and decodes the data
+// of the answer into data. Returns a StatusError for a status other than 200 OK, or an
+// error holding the messages of the errors the API answered with. The request waits on
+// the Limiter carried by ctx, if any, and it and the bytes read are counted in the
+// usage stats.
+func (g GraphQL) query(ctx context.Context, baseUrl, query string, variables map[string]any, data any) error {
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		return err
+	}
+
+	// The session cookies are scoped to the mod's site, the client only sending those
+	// it also holds for the endpoint on its own
+	var cookies []*http.Cookie
+	if client, ok := g.Client.(*http.Client); ok && client.Jar != nil {
+		site, siteErr := url.Parse(baseUrl)
+		endpoint, endpointErr := url.Parse(g.Endpoint)
+		if siteErr == nil && endpointErr == nil {
+			sent := map[string]bool{}
+			for _, cookie := range client.Jar.Cookies(endpoint) {
+				sent[cookie.Name] = true
+			}
+			for _, cookie := range client.Jar.Cookies(site) {
+				if !sent[cookie.Name] {
+					cookies = append(cookies, cookie)
+				}
+			}
+		}
+	}
+
+	req, err := httpclient.NewBrowserJSONRequest(ctx, g.Endpoint, body, cookies)
+	if err != nil {
+		return err
+	}
+
+	if err := httpclient.LimiterFrom(ctx).Wait(ctx); err != nil {
+		return err
+	}
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	counted := &countingReader{reader: resp.Body}
+	defer func() { usage.AddFetch(counted.count) }()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode, Url: g.Endpoint}
+	}
+
+	var answer struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(io.LimitReader(counted, 32<<20)).Decode(&answer); err != nil {
+		return fmt.Errorf("error decoding graphql answer: %w", err)
+	}
+	if len(answer.Errors) > 0 {
+		messages := make([]string, 0, len(answer.Errors))
+		for _, e := range answer.Errors {
+			messages = append(messages, e.Message)
+		}
+		return fmt.Errorf("graphql query failed: %s", strings.Join(messages, "; "))
+	}
+	if len(answer.Data) == 0 {
+		return errors.New("graphql answer has no data")
+	}
+	return json.Unmarshal(answer.Data, data)
+}
+
+// modInfo maps the mod to the fields of ModInfo it has a counterpart for. Counts are
+// formatted like the statistics of the mod page, e.g. 12,345.
+func (m graphQLMod) modInfo() types.ModInfo {
+	mod := types.ModInfo{
+		Category:         m.ModCategory.Name,
+		Creator:          m.Author,
+		Description:      formatters.CleanAndFormatText(m.Description),
+		Endorsements:     formatters.FormatCount(m.Endorsements),
+		Name:             m.Name,
+		ShortDescription: m.Summary,
+		TotalDLs:         formatters.FormatCount(m.Downloads),
+		Uploader:         m.Uploader.Name,
+		Version:          m.Version,
+	}
+	if updated, err := time.Parse(time.RFC3339, m.UpdatedAt); err == nil {
+		mod.LastUpdated = updated.Format("02 Jan 2006, 3:04PM")
+		mod.LastUpdatedAt = types.NewTimestamp(updated)
+	}
+	if created, err := time.Parse(time.RFC3339, m.CreatedAt); err == nil {
+		mod.OriginalUpload = created.Format("02 Jan 2006, 3:04PM")
+		mod.OriginalUploadAt = types.NewTimestamp(created)
+	}
+	return mod
+}
+
+// file maps the file to a File, with its download page link built from modUrl and its
+// category shortened like the Files tab sections, e.g. "old" for OLD_VERSION.
+func (f graphQLFile) file(modUrl string) types.File {
+	file := types.File{
+		Category:      graphQLFileCategory(f.Category),
+		Description:   f.Description,
+		FileID:        f.FileID,
+		Name:          f.Name,
+		TotalDLCount:  f.TotalDownloads,
+		TotalDLs:      formatters.FormatCount(f.TotalDownloads),
+		UniqueDLCount: f.UniqueDownloads,
+		UniqueDLs:     formatters.FormatCount(f.UniqueDownloads),
+		Version:       f.Version,
+	}
+	if size, err := f.SizeInBytes.Int64(); err == nil {
+		file.FileSizeBytes = size
+		file.FileSize = formatters.FormatSize(size)
+	}
+	if seconds, err := f.Date.Int64(); err == nil && seconds > 0 {
+		file.UploadDate = time.Unix(seconds, 0).UTC().Format("02 Jan 2006")
+	}
+	if file.FileID != 0 {
+		file.DownloadUrl = fmt.Sprintf("%s?tab=files&file_id=%d", modUrl, file.FileID)
+	}
+	return file
+}
+
+// graphQLStatus returns the ModStatus matching the status the API reports a mod with,
+// or "" for a published mod.
+func graphQLStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "hidden", "under_moderation":
+		return types.ModStatusHidden
+	case "removed", "deleted", "wastebinned":
+		return types.ModStatusRemoved
+	}
+	return ""
+}
+
+// graphQLFileCategory returns the Files tab section of a file category of the API,
+// e.g. "main" for MAIN and "old" for OLD_VERSION.
+func graphQLFileCategory(category string) string {
+	category = strings.ToLower(category)
+	switch category {
+	case "old_version", "archived":
+		return "old"
+	case "misc":
+		return "miscellaneous"
+	}
+	return category
+}
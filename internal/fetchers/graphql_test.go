@@ -0,0 +1,207 @@
+package fetchers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// graphQLServer answers the mod query with mod and the files query with files,
+// recording the operations and Cookie headers it received.
+func graphQLServer(t *testing.T, mod, files string) (*httptest.Server, *[]string, *[]string) {
+	var operations, cookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Query     string         `json:"query"`
+			Variables map[string]any `json:"variables"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		cookies = append(cookies, r.Header.Get("Cookie"))
+
+		switch {
+		case strings.HasPrefix(request.Query, "query Mod("):
+			operations = append(operations, "Mod")
+			io.WriteString(w, mod)
+		case strings.HasPrefix(request.Query, "query ModFiles("):
+			operations = append(operations, "ModFiles")
+			assert.Equal(t, map[string]any{"modId": "3863", "gameId": "1704"}, request.Variables)
+			io.WriteString(w, files)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &operations, &cookies
+}
+
+const graphQLModAnswer = `{"data": {"legacyModsByDomain": {"nodes": [{
+	"author": "schlangster", "createdAt": "2016-11-01T10:00:00Z", "description": "SkyUI makes the interface easier to use.",
+	"downloads": 1234567, "endorsements": 45678, "modId": 3863, "name": "SkyUI", "status": "published",
+	"summary": "Elegant, PC-friendly interface mod", "updatedAt": "2024-06-01T12:00:00Z", "version": "5.2SE",
+	"game": {"id": 1704}, "modCategory": {"name": "User Interface"}, "uploader": {"name": "EssArrBee"}
+}]}}}`
+
+func TestGraphQL_FetchModInfo(t *testing.T) {
+	// Arrange
+	server, operations, cookies := graphQLServer(t, graphQLModAnswer, `{"data": {"modFiles": [{
+		"category": "MAIN", "date": 1717243200, "description": "Main file", "fileId": 1001, "name": "SkyUI",
+		"sizeInBytes": 2097152, "uniqueDownloads": 1000, "totalDownloads": 1500, "version": "5.2SE"
+	}, {
+		"category": "OLD_VERSION", "date": 1600000000, "fileId": 900, "name": "SkyUI", "sizeInBytes": 1024, "version": "5.1"
+	}]}}`)
+	jar, _ := cookiejar.New(nil)
+	site, _ := url.Parse("https://www.nexusmods.com")
+	jar.SetCookies(site, []*http.Cookie{{Name: "nexusmods_session", Value: "abc"}})
+	backend := GraphQL{Client: &http.Client{Jar: jar}, Endpoint: server.URL}
+
+	// Act
+	results, err := backend.FetchModInfo(context.Background(), "https://www.nexusmods.com", "skyrimspecialedition", 3863, nil, nil, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Mod", "ModFiles"}, *operations)
+	assert.Equal(t, []string{"nexusmods_session=abc", "nexusmods_session=abc"}, *cookies)
+	mod := results.Mods
+	assert.Equal(t, int64(3863), mod.ModID)
+	assert.Equal(t, "SkyUI", mod.Name)
+	assert.Equal(t, "schlangster", mod.Creator)
+	assert.Equal(t, "EssArrBee", mod.Uploader)
+	assert.Equal(t, "User Interface", mod.Category)
+	assert.Equal(t, "Elegant, PC-friendly interface mod", mod.ShortDescription)
+	assert.Equal(t, "45,678", mod.Endorsements)
+	assert.Equal(t, "1,234,567", mod.TotalDLs)
+	require.NotNil(t, mod.LastUpdatedAt)
+	assert.Equal(t, 2024, mod.LastUpdatedAt.Year())
+	assert.Equal(t, "5.2SE", mod.LatestVersion)
+	assert.False(t, mod.LastChecked.IsZero())
+	assert.Equal(t, []types.File{
+		{
+			Category:      "main",
+			Description:   "Main file",
+			DownloadUrl:   "https://www.nexusmods.com/skyrimspecialedition/mods/3863?tab=files&file_id=1001",
+			FileID:        1001,
+			FileSize:      "2MB",
+			FileSizeBytes: 2097152,
+			Name:          "SkyUI",
+			TotalDLCount:  1500,
+			TotalDLs:      "1,500",
+			UniqueDLCount: 1000,
+			UniqueDLs:     "1,000",
+			UploadDate:    "01 Jun 2024",
+			Version:       "5.2SE",
+		},
+		{
+			Category:      "old",
+			DownloadUrl:   "https://www.nexusmods.com/skyrimspecialedition/mods/3863?tab=files&file_id=900",
+			FileID:        900,
+			FileSize:      "1KB",
+			FileSizeBytes: 1024,
+			Name:          "SkyUI",
+			TotalDLs:      "0",
+			UniqueDLs:     "0",
+			UploadDate:    "13 Sep 2020",
+			Version:       "5.1",
+		},
+	}, mod.Files)
+}
+
+func TestGraphQL_FetchModInfo_Unavailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected string
+	}{
+		{name: "unknown mod", answer: `{"data": {"legacyModsByDomain": {"nodes": []}}}`, expected: types.ModStatusNotFound},
+		{name: "hidden mod", answer: `{"data": {"legacyModsByDomain": {"nodes": [{"name": "SkyUI", "status": "hidden", "game": {"id": 1704}}]}}}`, expected: types.ModStatusHidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, operations, _ := graphQLServer(t, tt.answer, "")
+
+			results, err := GraphQL{Client: http.DefaultClient, Endpoint: server.URL}.FetchModInfo(context.Background(), "https://www.nexusmods.com", "skyrim", 3863, nil, nil, nil)
+
+			require.NoError(t, err)
+			assert.Equal(t, []string{"Mod"}, *operations)
+			assert.Equal(t, tt.expected, results.Mods.Status)
+			assert.Equal(t, "https://www.nexusmods.com/skyrim/mods/3863", results.Mods.Url)
+		})
+	}
+}
+
+func TestGraphQL_FetchModInfo_BrowserHeaders(t *testing.T) {
+	// Arrange
+	defer func(previous string) { httpclient.AcceptLanguage = previous }(httpclient.AcceptLanguage)
+	httpclient.AcceptLanguage = "de-DE,de;q=0.9,en;q=0.8"
+	var userAgent, acceptLanguage string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userAgent, acceptLanguage = r.Header.Get("User-Agent"), r.Header.Get("Accept-Language")
+		io.WriteString(w, `{"data": {"legacyModsByDomain": {"nodes": []}}}`)
+	}))
+	defer server.Close()
+
+	// Act
+	_, err := GraphQL{Client: http.DefaultClient, Endpoint: server.URL}.FetchModInfo(context.Background(), "https://www.nexusmods.com", "skyrim", 3863, nil, nil, nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Contains(t, userAgent, "Mozilla/5.0")
+	assert.Equal(t, "de-DE,de;q=0.9,en;q=0.8", acceptLanguage)
+}
+
+func TestGraphQL_FetchModInfo_FilteredSkipsFiles(t *testing.T) {
+	// Arrange
+	server, operations, _ := graphQLServer(t, graphQLModAnswer, "")
+
+	// Act
+	results, err := GraphQL{Client: http.DefaultClient, Endpoint: server.URL}.FetchModInfo(context.Background(), "https://www.nexusmods.com", "skyrimspecialedition", 3863, func(mod types.ModInfo) bool { return false }, nil, nil)
+
+	// Assert
+	assert.ErrorIs(t, err, ErrModFiltered)
+	assert.Equal(t, "SkyUI", results.Mods.Name)
+	assert.Equal(t, []string{"Mod"}, *operations)
+}
+
+func TestGraphQL_FetchModInfo_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		err     string
+	}{
+		{
+			name: "graphql errors",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, `{"errors": [{"message": "unknown field"}, {"message": "bad id"}]}`)
+			},
+			err: "graphql query failed: unknown field; bad id",
+		},
+		{
+			name: "error status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			err: "returned 429",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			_, err := GraphQL{Client: http.DefaultClient, Endpoint: server.URL}.FetchModInfo(context.Background(), "https://www.nexusmods.com", "skyrim", 3863, nil, nil, nil)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package fetchers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// maxDivergenceValue is the number of runes of a value shown when describing a
+// divergence.
+const maxDivergenceValue = 60
+
+// Shadow is the backend a run fetches each mod with a second time, so that the results
+// of its backend can be compared against it with Compare.
+type Shadow struct {
+	Backend      string
+	FetchModInfo func(ctx context.Context, baseUrl string, game types.GameSlug, modId types.ModID, filter ModFilter, concurrentFetch func(ctx context.Context, tasks ...func(ctx context.Context) error) error, fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) (types.Results, error)
+}
+
+// shadowKey is the context key of the Shadow of a run.
+type shadowKey struct{}
+
+// WithShadow returns a copy of ctx carrying shadow, which the mods scraped with it are
+// fetched with again.
+func WithShadow(ctx context.Context, shadow *Shadow) context.Context {
+	return context.WithValue(ctx, shadowKey{}, shadow)
+}
+
+// ShadowFrom returns the Shadow carried by ctx, or nil when there is none.
+func ShadowFrom(ctx context.Context) *Shadow {
+	shadow, _ := ctx.Value(shadowKey{}).(*Shadow)
+	return shadow
+}
+
+// Divergence is a field of a mod on which two backends disagree, with the value each
+// of them fetched, "" standing for a field left empty.
+type Divergence struct {
+	Field   string
+	Primary string
+	Shadow  string
+}
+
+// Compare returns the fields on which the mod fetched by the primary backend and the
+// one fetched by the shadow backend disagree, in the order of ModInfo. Only the fields
+// both backends fill are compared: the statistics, dates and texts of the GraphQL API
+// and, when both mods list their files, the files matched by their ID. File sizes are
+// left out, the pages only showing them rounded.
+func Compare(primary, shadow types.ModInfo) []Divergence {
+	var divergences []Divergence
+	compare := func(field, primary, shadow string) {
+		if primary != shadow {
+			divergences = append(divergences, Divergence{Field: field, Primary: primary, Shadow: shadow})
+		}
+	}
+
+	compare("Category", primary.Category, shadow.Category)
+	compare("Creator", primary.Creator, shadow.Creator)
+	compare("Description", primary.Description, shadow.Description)
+	compare("Endorsements", primary.Endorsements, shadow.Endorsements)
+	if primary.Files != nil && shadow.Files != nil {
+		shadowFiles := make(map[int64]types.File, len(shadow.Files))
+		for _, file := range shadow.Files {
+			shadowFiles[file.FileID] = file
+		}
+		for _, file := range primary.Files {
+			field := "Files[" + strconv.FormatInt(file.FileID, 10) + "]"
+			other, ok := shadowFiles[file.FileID]
+			if !ok {
+				compare(field, file.Name, "")
+				continue
+			}
+			delete(shadowFiles, file.FileID)
+			compare(field+".Category", file.Category, other.Category)
+			compare(field+".Name", file.Name, other.Name)
+			compare(field+".Version", file.Version, other.Version)
+		}
+		for _, file := range shadow.Files {
+			if _, ok := shadowFiles[file.FileID]; ok {
+				compare("Files["+strconv.FormatInt(file.FileID, 10)+"]", "", file.Name)
+			}
+		}
+	}
+	compare("LastUpdated", primary.LastUpdated, shadow.LastUpdated)
+	compare("LatestVersion", primary.LatestVersion, shadow.LatestVersion)
+	compare("Name", primary.Name, shadow.Name)
+	compare("OriginalUpload", primary.OriginalUpload, shadow.OriginalUpload)
+	compare("ShortDescription", primary.ShortDescription, shadow.ShortDescription)
+	compare("Status", primary.Status, shadow.Status)
+	compare("TotalDLs", primary.TotalDLs, shadow.TotalDLs)
+	compare("Uploader", primary.Uploader, shadow.Uploader)
+	compare("Version", primary.Version, shadow.Version)
+	return divergences
+}
+
+// String describes the divergence, e.g. `Name: "SkyUI" against "SkyUI 5"`, values
+// longer than maxDivergenceValue runes, such as descriptions, being cut short.
+func (d Divergence) String() string {
+	return fmt.Sprintf("%s: %q against %q", d.Field, shorten(d.Primary), shorten(d.Shadow))
+}
+
+// shorten returns value cut to maxDivergenceValue runes, ending with an ellipsis when
+// it was cut.
+func shorten(value string) string {
+	runes := []rune(value)
+	if len(runes) <= maxDivergenceValue {
+		return value
+	}
+	return string(runes[:maxDivergenceValue]) + "…"
+}
@@ -0,0 +1,76 @@
+package fetchers
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+func TestCompare(t *testing.T) {
+	// Arrange
+	primary := types.ModInfo{
+		Creator:      "schlangster",
+		Endorsements: "12,345",
+		Files: []types.File{
+			{FileID: 1, Name: "SkyUI", Version: "5.2", FileSizeBytes: 1 << 20},
+			{FileID: 2, Name: "SkyUI SE", Version: "5.2"},
+		},
+		LastChecked: time.Now(),
+		Name:        "SkyUI",
+		Tags:        []string{"UI"},
+	}
+	shadow := types.ModInfo{
+		Creator:      "schlangster",
+		Endorsements: "12,346",
+		Files: []types.File{
+			{FileID: 1, Name: "SkyUI", Version: "5.2.1", FileSizeBytes: 1<<20 + 1},
+			{FileID: 3, Name: "SkyUI patch"},
+		},
+		Name: "SkyUI",
+	}
+
+	// Act
+	divergences := Compare(primary, shadow)
+
+	// Assert
+	assert.Equal(t, []Divergence{
+		{Field: "Endorsements", Primary: "12,345", Shadow: "12,346"},
+		{Field: "Files[1].Version", Primary: "5.2", Shadow: "5.2.1"},
+		{Field: "Files[2]", Primary: "SkyUI SE"},
+		{Field: "Files[3]", Shadow: "SkyUI patch"},
+	}, divergences)
+}
+
+func TestCompare_WithoutFiles(t *testing.T) {
+	// Arrange
+	primary := types.ModInfo{Name: "SkyUI", Files: []types.File{{FileID: 1, Name: "SkyUI"}}}
+	shadow := types.ModInfo{Name: "SkyUI"}
+
+	// Act
+	divergences := Compare(primary, shadow)
+
+	// Assert
+	assert.Empty(t, divergences, "the files are only compared when both backends list them")
+}
+
+func TestDivergence_String(t *testing.T) {
+	tests := []struct {
+		name       string
+		divergence Divergence
+		expected   string
+	}{
+		{"short", Divergence{Field: "Name", Primary: "SkyUI", Shadow: "SkyUI 5"}, `Name: "SkyUI" against "SkyUI 5"`},
+		{"empty", Divergence{Field: "Files[3]", Shadow: "SkyUI patch"}, `Files[3]: "" against "SkyUI patch"`},
+		{"long", Divergence{Field: "Description", Primary: strings.Repeat("a", 61), Shadow: "b"}, `Description: "` + strings.Repeat("a", 60) + `…" against "b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.divergence.String())
+		})
+	}
+}
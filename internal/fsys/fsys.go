@@ -0,0 +1,110 @@
+package fsys
+
+import (
+	"io"
+	"os"
+)
+
+// File is the subset of *os.File used by the application, so that files handed out
+// by an in-memory filesystem can stand in for real ones.
+type File interface {
+	io.Reader
+	io.Writer
+	io.Closer
+	Name() string
+}
+
+// FS is an interface over the filesystem operations used when reading and writing the
+// scraper's files (results, cookies, history, metrics and configuration). It allows
+// the real filesystem to be swapped for an in-memory one in tests, or for alternate
+// backends.
+type FS interface {
+	Chmod(name string, mode os.FileMode) error
+	CreateTemp(dir, pattern string) (File, error)
+	MkdirAll(path string, perm os.FileMode) error
+	OpenFile(name string, flag int, perm os.FileMode) (File, error)
+	ReadDir(name string) ([]os.DirEntry, error)
+	ReadFile(name string) ([]byte, error)
+	Remove(name string) error
+	Rename(oldpath, newpath string) error
+	Stat(name string) (os.FileInfo, error)
+	WriteFile(name string, data []byte, perm os.FileMode) error
+}
+
+// Default is the filesystem used by the application. It is the real filesystem unless
+// replaced, e.g. by a MemFS in tests.
+var Default FS = OS{}
+
+// Use replaces Default with fs and returns a function that restores the previous
+// filesystem, so tests can write `defer fsys.Use(fsys.NewMemFS())()`.
+func Use(fs FS) func() {
+	previous := Default
+	Default = fs
+	return func() { Default = previous }
+}
+
+// Open opens the named file for reading on the Default filesystem.
+func Open(name string) (File, error) {
+	return Default.OpenFile(name, os.O_RDONLY, 0)
+}
+
+// OS is an FS backed by the real filesystem through the os package.
+type OS struct{}
+
+// Chmod changes the mode of the named file.
+func (OS) Chmod(name string, mode os.FileMode) error {
+	return os.Chmod(name, mode)
+}
+
+// CreateTemp creates a new temporary file in dir, see os.CreateTemp.
+func (OS) CreateTemp(dir, pattern string) (File, error) {
+	file, err := os.CreateTemp(dir, pattern)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// MkdirAll creates a directory along with any necessary parents.
+func (OS) MkdirAll(path string, perm os.FileMode) error {
+	return os.MkdirAll(path, perm)
+}
+
+// OpenFile opens the named file with the specified flag and permissions.
+func (OS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	file, err := os.OpenFile(name, flag, perm)
+	if err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// ReadDir returns the entries of the named directory, sorted by file name.
+func (OS) ReadDir(name string) ([]os.DirEntry, error) {
+	return os.ReadDir(name)
+}
+
+// ReadFile reads the named file and returns its contents.
+func (OS) ReadFile(name string) ([]byte, error) {
+	return os.ReadFile(name)
+}
+
+// Remove removes the named file or empty directory.
+func (OS) Remove(name string) error {
+	return os.Remove(name)
+}
+
+// Rename renames (moves) oldpath to newpath.
+func (OS) Rename(oldpath, newpath string) error {
+	return os.Rename(oldpath, newpath)
+}
+
+// Stat returns the FileInfo describing the named file.
+func (OS) Stat(name string) (os.FileInfo, error) {
+	return os.Stat(name)
+}
+
+// WriteFile writes data to the named file, creating it if necessary.
+func (OS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
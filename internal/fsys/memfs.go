@@ -0,0 +1,361 @@
+package fsys
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemFS is an in-memory FS intended for tests. Besides holding files in memory it can
+// be told to fail a given operation on a given path, which replaces simulating errors
+// through file permissions on the real filesystem.
+type MemFS struct {
+	mu       sync.Mutex
+	dirs     map[string]bool
+	failures map[string]error
+	files    map[string]*memEntry
+	tempSeq  int
+}
+
+// memEntry is the content and mode of a file held by a MemFS.
+type memEntry struct {
+	data    []byte
+	mode    os.FileMode
+	modTime time.Time
+}
+
+// NewMemFS returns an empty in-memory filesystem.
+func NewMemFS() *MemFS {
+	return &MemFS{
+		dirs:     map[string]bool{},
+		failures: map[string]error{},
+		files:    map[string]*memEntry{},
+	}
+}
+
+// FailOn makes the operation op (the FS method name, e.g. "Rename") fail with err when
+// it is called for path. For Rename the path is the old path.
+func (m *MemFS) FailOn(op, path string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.failures[op+":"+filepath.Clean(path)] = err
+}
+
+// Files returns the paths of all the files held, sorted.
+func (m *MemFS) Files() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// Chmod changes the mode of the named file.
+func (m *MemFS) Chmod(name string, mode os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.failure("Chmod", name); err != nil {
+		return err
+	}
+	entry, ok := m.files[name]
+	if !ok {
+		return pathError("chmod", name, fs.ErrNotExist)
+	}
+	entry.mode = mode
+	return nil
+}
+
+// CreateTemp creates a new empty file in dir named after pattern, with the last "*"
+// replaced by a unique sequence number.
+func (m *MemFS) CreateTemp(dir, pattern string) (File, error) {
+	m.mu.Lock()
+	m.tempSeq++
+	seq := fmt.Sprint(m.tempSeq)
+	m.mu.Unlock()
+
+	name := pattern + seq
+	if i := strings.LastIndex(pattern, "*"); i >= 0 {
+		name = pattern[:i] + seq + pattern[i+1:]
+	}
+	return m.OpenFile(filepath.Join(dir, name), os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+}
+
+// MkdirAll creates a directory along with any necessary parents.
+func (m *MemFS) MkdirAll(path string, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if err := m.failure("MkdirAll", path); err != nil {
+		return err
+	}
+	for dir := path; !isRoot(dir); dir = filepath.Dir(dir) {
+		if _, isFile := m.files[dir]; isFile {
+			return pathError("mkdir", dir, fs.ErrExist)
+		}
+		m.dirs[dir] = true
+	}
+	return nil
+}
+
+// OpenFile opens the named file. O_CREATE, O_EXCL, O_TRUNC and O_APPEND behave as they
+// do for os.OpenFile; written content becomes visible when the file is closed.
+func (m *MemFS) OpenFile(name string, flag int, perm os.FileMode) (File, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.failure("OpenFile", name); err != nil {
+		return nil, err
+	}
+
+	entry, exists := m.files[name]
+	switch {
+	case exists && flag&os.O_CREATE != 0 && flag&os.O_EXCL != 0:
+		return nil, pathError("open", name, fs.ErrExist)
+	case !exists && flag&os.O_CREATE == 0:
+		return nil, pathError("open", name, fs.ErrNotExist)
+	case !exists && !m.dirExists(filepath.Dir(name)):
+		return nil, pathError("open", name, fs.ErrNotExist)
+	case !exists:
+		entry = &memEntry{mode: perm, modTime: time.Now()}
+		m.files[name] = entry
+	}
+
+	file := &memFile{fs: m, name: name, writable: flag&(os.O_WRONLY|os.O_RDWR) != 0}
+	if flag&os.O_TRUNC == 0 {
+		file.buf.Write(entry.data)
+	}
+	if flag&os.O_APPEND == 0 && file.writable {
+		// Writes without O_APPEND start at the beginning, like a freshly opened file
+		file.buf.Truncate(0)
+		file.overwrite = append([]byte(nil), entry.data...)
+	}
+	return file, nil
+}
+
+// ReadDir returns the files and directories directly inside the named directory,
+// sorted by file name.
+func (m *MemFS) ReadDir(name string) ([]os.DirEntry, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.failure("ReadDir", name); err != nil {
+		return nil, err
+	}
+	if !m.dirExists(name) {
+		return nil, pathError("open", name, fs.ErrNotExist)
+	}
+
+	var entries []os.DirEntry
+	for path, entry := range m.files {
+		if filepath.Dir(path) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: filepath.Base(path), size: int64(len(entry.data)), mode: entry.mode, modTime: entry.modTime}))
+		}
+	}
+	for dir := range m.dirs {
+		if dir != name && filepath.Dir(dir) == name {
+			entries = append(entries, fs.FileInfoToDirEntry(memFileInfo{name: filepath.Base(dir), mode: fs.ModeDir | 0755}))
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+	return entries, nil
+}
+
+// ReadFile reads the named file and returns its contents.
+func (m *MemFS) ReadFile(name string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.failure("ReadFile", name); err != nil {
+		return nil, err
+	}
+	entry, ok := m.files[name]
+	if !ok {
+		return nil, pathError("open", name, fs.ErrNotExist)
+	}
+	return append([]byte(nil), entry.data...), nil
+}
+
+// Remove removes the named file or directory.
+func (m *MemFS) Remove(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.failure("Remove", name); err != nil {
+		return err
+	}
+	if _, ok := m.files[name]; ok {
+		delete(m.files, name)
+		return nil
+	}
+	if m.dirs[name] {
+		delete(m.dirs, name)
+		return nil
+	}
+	return pathError("remove", name, fs.ErrNotExist)
+}
+
+// Rename renames (moves) oldpath to newpath, replacing any existing file at newpath.
+func (m *MemFS) Rename(oldpath, newpath string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	oldpath, newpath = filepath.Clean(oldpath), filepath.Clean(newpath)
+	if err := m.failure("Rename", oldpath); err != nil {
+		return err
+	}
+	entry, ok := m.files[oldpath]
+	if !ok {
+		return &os.LinkError{Op: "rename", Old: oldpath, New: newpath, Err: fs.ErrNotExist}
+	}
+	delete(m.files, oldpath)
+	m.files[newpath] = entry
+	return nil
+}
+
+// Stat returns the FileInfo describing the named file or directory.
+func (m *MemFS) Stat(name string) (os.FileInfo, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.failure("Stat", name); err != nil {
+		return nil, err
+	}
+	if entry, ok := m.files[name]; ok {
+		return memFileInfo{name: filepath.Base(name), size: int64(len(entry.data)), mode: entry.mode, modTime: entry.modTime}, nil
+	}
+	if m.dirExists(name) {
+		return memFileInfo{name: filepath.Base(name), mode: fs.ModeDir | 0755}, nil
+	}
+	return nil, pathError("stat", name, fs.ErrNotExist)
+}
+
+// WriteFile writes data to the named file, creating it if necessary.
+func (m *MemFS) WriteFile(name string, data []byte, perm os.FileMode) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	name = filepath.Clean(name)
+	if err := m.failure("WriteFile", name); err != nil {
+		return err
+	}
+	if !m.dirExists(filepath.Dir(name)) {
+		return pathError("open", name, fs.ErrNotExist)
+	}
+	m.files[name] = &memEntry{data: append([]byte(nil), data...), mode: perm, modTime: time.Now()}
+	return nil
+}
+
+// failure returns the error registered with FailOn for op and path, if any. The
+// caller must hold the lock.
+func (m *MemFS) failure(op, path string) error {
+	return m.failures[op+":"+path]
+}
+
+// dirExists reports whether path is a known directory. The root and the current
+// directory always exist. The caller must hold the lock.
+func (m *MemFS) dirExists(path string) bool {
+	return isRoot(path) || m.dirs[path]
+}
+
+// isRoot reports whether path is the root of an absolute or relative path.
+func isRoot(path string) bool {
+	return path == "." || path == filepath.Dir(path)
+}
+
+// pathError builds the *fs.PathError the os package would return.
+func pathError(op, path string, err error) error {
+	return &fs.PathError{Op: op, Path: path, Err: err}
+}
+
+// memFile is a File handed out by a MemFS. Reads see the content at the time the file
+// was opened and writes are committed to the MemFS when the file is closed.
+type memFile struct {
+	buf       bytes.Buffer
+	closed    bool
+	fs        *MemFS
+	name      string
+	overwrite []byte
+	writable  bool
+}
+
+// Name returns the name of the file as passed to OpenFile or CreateTemp.
+func (f *memFile) Name() string {
+	return f.name
+}
+
+// Read reads from the file content.
+func (f *memFile) Read(p []byte) (int, error) {
+	if f.closed {
+		return 0, pathError("read", f.name, fs.ErrClosed)
+	}
+	return f.buf.Read(p)
+}
+
+// Write writes to the file content.
+func (f *memFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, pathError("write", f.name, fs.ErrClosed)
+	}
+	if !f.writable {
+		return 0, pathError("write", f.name, fs.ErrPermission)
+	}
+	return f.buf.Write(p)
+}
+
+// Close commits written content to the MemFS.
+func (f *memFile) Close() error {
+	if f.closed {
+		return pathError("close", f.name, fs.ErrClosed)
+	}
+	f.closed = true
+	if !f.writable {
+		return nil
+	}
+
+	data := f.buf.Bytes()
+	if len(f.overwrite) > len(data) {
+		// Without O_TRUNC, bytes past the written content are left in place
+		data = append(data, f.overwrite[len(data):]...)
+	}
+
+	f.fs.mu.Lock()
+	defer f.fs.mu.Unlock()
+	if entry, ok := f.fs.files[f.name]; ok {
+		entry.data = append([]byte(nil), data...)
+		entry.modTime = time.Now()
+	}
+	return nil
+}
+
+// memFileInfo is the os.FileInfo returned by MemFS.Stat.
+type memFileInfo struct {
+	modTime time.Time
+	mode    os.FileMode
+	name    string
+	size    int64
+}
+
+func (i memFileInfo) Name() string       { return i.name }
+func (i memFileInfo) Size() int64        { return i.size }
+func (i memFileInfo) Mode() os.FileMode  { return i.mode }
+func (i memFileInfo) ModTime() time.Time { return i.modTime }
+func (i memFileInfo) IsDir() bool        { return i.mode.IsDir() }
+func (i memFileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,114 @@
+package fsys
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemFS_WriteAndRead(t *testing.T) {
+	// Arrange
+	m := NewMemFS()
+	dir := filepath.Join("data", "skyrim")
+
+	// Act
+	require.NoError(t, m.MkdirAll(dir, os.ModePerm))
+	require.NoError(t, m.WriteFile(filepath.Join(dir, "1.json"), []byte("{}"), 0644))
+	data, err := m.ReadFile(filepath.Join(dir, "1.json"))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "{}", string(data))
+
+	info, err := m.Stat(dir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+}
+
+func TestMemFS_WriteFileWithoutDirectory(t *testing.T) {
+	m := NewMemFS()
+
+	err := m.WriteFile(filepath.Join("missing", "file.json"), []byte("{}"), 0644)
+
+	assert.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestMemFS_OpenFileAppend(t *testing.T) {
+	// Arrange
+	m := NewMemFS()
+	require.NoError(t, m.WriteFile("journal.jsonl", []byte("one\n"), 0644))
+
+	// Act
+	file, err := m.OpenFile("journal.jsonl", os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(t, err)
+	_, err = file.Write([]byte("two\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+
+	// Assert
+	defer Use(m)()
+	reader, err := Open("journal.jsonl")
+	require.NoError(t, err)
+	data, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "one\ntwo\n", string(data))
+}
+
+func TestMemFS_CreateTempAndRename(t *testing.T) {
+	// Arrange
+	m := NewMemFS()
+	require.NoError(t, m.MkdirAll("metrics", os.ModePerm))
+
+	// Act
+	file, err := m.CreateTemp("metrics", "nexus.prom.*.tmp")
+	require.NoError(t, err)
+	_, err = file.Write([]byte("metric 1\n"))
+	require.NoError(t, err)
+	require.NoError(t, file.Close())
+	require.NoError(t, m.Rename(file.Name(), filepath.Join("metrics", "nexus.prom")))
+
+	// Assert
+	assert.Equal(t, filepath.Join("metrics", "nexus.prom.1.tmp"), file.Name())
+	assert.Equal(t, []string{filepath.Join("metrics", "nexus.prom")}, m.Files())
+}
+
+func TestMemFS_FailOn(t *testing.T) {
+	// Arrange
+	m := NewMemFS()
+	boom := errors.New("disk full")
+	m.FailOn("WriteFile", "out.json", boom)
+
+	// Act
+	err := m.WriteFile("out.json", []byte("{}"), 0644)
+
+	// Assert
+	assert.Equal(t, boom, err)
+	assert.Empty(t, m.Files())
+}
+
+func TestMemFS_ReadDir(t *testing.T) {
+	// Arrange
+	m := NewMemFS()
+	require.NoError(t, m.MkdirAll(filepath.Join("data", "skyrim", "collections"), os.ModePerm))
+	require.NoError(t, m.WriteFile(filepath.Join("data", "skyrim", "b.json"), []byte("{}"), 0644))
+	require.NoError(t, m.WriteFile(filepath.Join("data", "skyrim", "a.json"), []byte("{}"), 0644))
+
+	// Act
+	entries, err := m.ReadDir(filepath.Join("data", "skyrim"))
+	_, missingErr := m.ReadDir("missing")
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, "a.json", entries[0].Name())
+	assert.Equal(t, "b.json", entries[1].Name())
+	assert.Equal(t, "collections", entries[2].Name())
+	assert.True(t, entries[2].IsDir())
+	assert.ErrorIs(t, missingErr, fs.ErrNotExist)
+}
@@ -0,0 +1,202 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// DefaultFilename is the name of the history journal stored in the data directory.
+const DefaultFilename = "history.jsonl"
+
+// Entry is a single observation of a mod recorded in the history journal, capturing
+// the values that are useful to track over time such as the version, last update
+// and popularity statistics, along with the ID of the run that recorded it. The names
+// of the mod's Nexus requirements are always written, empty when it has none, so that
+// entries recorded before they were tracked, which have none, can be told apart.
+type Entry struct {
+	Endorsements  string           `json:"Endorsements,omitempty"`
+	Game          string           `json:"Game"`
+	LastUpdatedAt *types.Timestamp `json:"LastUpdatedAt,omitempty"`
+	ModID         int64            `json:"ModID"`
+	Name          string           `json:"Name,omitempty"`
+	RecordedAt    time.Time        `json:"RecordedAt"`
+	Requirements  []string         `json:"Requirements"`
+	RunID         string           `json:"RunID,omitempty"`
+	TotalDLs      string           `json:"TotalDLs,omitempty"`
+	UniqueDLs     string           `json:"UniqueDLs,omitempty"`
+	Version       string           `json:"Version,omitempty"`
+}
+
+// NewEntry builds a history entry for the provided game and mod, using the time the
+// mod was last checked as the recording time.
+func NewEntry(game string, mod types.ModInfo) Entry {
+	version := mod.Version
+	if version == "" {
+		version = mod.LatestVersion
+	}
+
+	recordedAt := mod.LastChecked
+	if recordedAt.IsZero() {
+		recordedAt = time.Now()
+	}
+
+	requirements := make([]string, 0, len(mod.Dependencies))
+	for _, requirement := range mod.Dependencies {
+		requirements = append(requirements, requirement.Name)
+	}
+	sort.Strings(requirements)
+
+	return Entry{
+		Endorsements:  mod.Endorsements,
+		Game:          game,
+		LastUpdatedAt: mod.LastUpdatedAt,
+		ModID:         mod.ModID,
+		Name:          mod.Name,
+		RecordedAt:    recordedAt,
+		Requirements:  requirements,
+		TotalDLs:      mod.TotalDLs,
+		UniqueDLs:     mod.UniqueDLs,
+		Version:       version,
+	}
+}
+
+// Append writes the provided entries to the end of the journal at path, one JSON
+// document per line, creating the file and its directory if needed.
+func Append(path string, entries ...Entry) error {
+	if err := fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	file, err := fsys.Default.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("error opening history file: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			file.Close()
+			return fmt.Errorf("error writing history entry: %w", err)
+		}
+	}
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error writing history file: %w", err)
+	}
+	return nil
+}
+
+// Load reads every entry from the journal at path in the order they were recorded.
+// A missing journal is not an error and yields no entries.
+func Load(path string) ([]Entry, error) {
+	file, err := fsys.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening history file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("error decoding history line %d: %w", line, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Filter returns the entries matching the provided game and mod ID. An empty game or
+// a zero mod ID matches every entry for that field.
+func Filter(entries []Entry, game string, modID int64) []Entry {
+	var filtered []Entry
+	for _, entry := range entries {
+		if game != "" && entry.Game != game {
+			continue
+		}
+		if modID != 0 && entry.ModID != modID {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+	return filtered
+}
+
+// Latest returns the most recently recorded entry of every mod in entries, keyed by
+// Key(game, modID).
+func Latest(entries []Entry) map[string]Entry {
+	latest := make(map[string]Entry)
+	for _, entry := range entries {
+		key := Key(entry.Game, entry.ModID)
+		if previous, ok := latest[key]; !ok || !entry.RecordedAt.Before(previous.RecordedAt) {
+			latest[key] = entry
+		}
+	}
+	return latest
+}
+
+// Key identifies a mod across games in the journal, e.g. "skyrim/1234".
+func Key(game string, modID int64) string {
+	return fmt.Sprintf("%s/%d", game, modID)
+}
+
+// Updated reports whether the mod recorded in current has changed since previous,
+// either by a new version or a new last updated date.
+func Updated(previous, current Entry) bool {
+	if current.Version != "" && previous.Version != "" && current.Version != previous.Version {
+		return true
+	}
+	if current.LastUpdatedAt == nil || previous.LastUpdatedAt == nil {
+		return false
+	}
+	return !current.LastUpdatedAt.Equal(previous.LastUpdatedAt.Time)
+}
+
+// RequirementsChanged returns the Nexus requirements the mod recorded in current added
+// and removed since previous, compared by name regardless of case. Nothing is reported
+// when previous was recorded before requirements were tracked.
+func RequirementsChanged(previous, current Entry) (added, removed []string) {
+	if previous.Requirements == nil || current.Requirements == nil {
+		return nil, nil
+	}
+	return missing(current.Requirements, previous.Requirements), missing(previous.Requirements, current.Requirements)
+}
+
+// missing returns the names of names that aren't in others, regardless of case.
+func missing(names, others []string) []string {
+	known := make(map[string]bool, len(others))
+	for _, name := range others {
+		known[strings.ToLower(name)] = true
+	}
+
+	var result []string
+	for _, name := range names {
+		if !known[strings.ToLower(name)] {
+			result = append(result, name)
+		}
+	}
+	return result
+}
@@ -0,0 +1,134 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEntry(t *testing.T) {
+	// Arrange
+	checked := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	mod := types.ModInfo{
+		ModID:         123,
+		Name:          "Test Mod",
+		LatestVersion: "1.0",
+		LastChecked:   checked,
+		TotalDLs:      "1,000",
+		Endorsements:  "10",
+	}
+
+	// Act
+	entry := NewEntry("skyrim", mod)
+
+	// Assert
+	assert.Equal(t, "skyrim", entry.Game)
+	assert.Equal(t, int64(123), entry.ModID)
+	assert.Equal(t, "1.0", entry.Version)
+	assert.Equal(t, checked, entry.RecordedAt)
+	assert.Equal(t, "1,000", entry.TotalDLs)
+	assert.Equal(t, "10", entry.Endorsements)
+}
+
+func TestAppendAndLoad(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "nested", DefaultFilename)
+	first := Entry{Game: "skyrim", ModID: 1, RecordedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	second := Entry{Game: "fallout4", ModID: 2, RecordedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)}
+
+	// Act
+	require.NoError(t, Append(path, first))
+	require.NoError(t, Append(path, second))
+	entries, err := Load(path)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Entry{first, second}, entries)
+}
+
+func TestLoad_MissingFile(t *testing.T) {
+	entries, err := Load(filepath.Join(t.TempDir(), "missing.jsonl"))
+
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestLoad_InvalidLine(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), DefaultFilename)
+	require.NoError(t, os.WriteFile(path, []byte("{\"Game\":\"skyrim\"}\nnot json\n"), 0644))
+
+	// Act
+	_, err := Load(path)
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error decoding history line 2")
+}
+
+func TestFilter(t *testing.T) {
+	entries := []Entry{
+		{Game: "skyrim", ModID: 1},
+		{Game: "skyrim", ModID: 2},
+		{Game: "fallout4", ModID: 1},
+	}
+
+	assert.Len(t, Filter(entries, "", 0), 3)
+	assert.Len(t, Filter(entries, "skyrim", 0), 2)
+	assert.Len(t, Filter(entries, "", 1), 2)
+	assert.Equal(t, []Entry{{Game: "fallout4", ModID: 1}}, Filter(entries, "fallout4", 1))
+}
+
+func TestLatest(t *testing.T) {
+	// Arrange
+	first := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Game: "skyrim", ModID: 1, Version: "1.0", RecordedAt: first},
+		{Game: "skyrim", ModID: 1, Version: "1.1", RecordedAt: first.Add(time.Hour)},
+		{Game: "fallout4", ModID: 1, Version: "2.0", RecordedAt: first},
+	}
+
+	// Act
+	latest := Latest(entries)
+
+	// Assert
+	require.Len(t, latest, 2)
+	assert.Equal(t, "1.1", latest[Key("skyrim", 1)].Version)
+	assert.Equal(t, "2.0", latest[Key("fallout4", 1)].Version)
+}
+
+func TestUpdated(t *testing.T) {
+	// Arrange
+	updatedAt := time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC)
+	previous := Entry{Version: "1.0", LastUpdatedAt: &types.Timestamp{Time: updatedAt}}
+
+	// Act & Assert
+	assert.False(t, Updated(previous, previous))
+	assert.True(t, Updated(previous, Entry{Version: "1.1"}))
+	assert.False(t, Updated(previous, Entry{Version: ""}))
+	assert.True(t, Updated(previous, Entry{Version: "1.0", LastUpdatedAt: &types.Timestamp{Time: updatedAt.Add(time.Hour)}}))
+}
+
+func TestRequirementsChanged(t *testing.T) {
+	previous := NewEntry("skyrim", types.ModInfo{Dependencies: []types.Requirement{{Name: "SkyUI"}, {Name: "Address Library"}}})
+	current := NewEntry("skyrim", types.ModInfo{Dependencies: []types.Requirement{{Name: "address library"}, {Name: "SKSE64"}}})
+
+	added, removed := RequirementsChanged(previous, current)
+
+	assert.Equal(t, []string{"SKSE64"}, added)
+	assert.Equal(t, []string{"SkyUI"}, removed)
+}
+
+func TestRequirementsChanged_NotTracked(t *testing.T) {
+	current := NewEntry("skyrim", types.ModInfo{Dependencies: []types.Requirement{{Name: "SKSE64"}}})
+
+	added, removed := RequirementsChanged(Entry{Game: "skyrim"}, current)
+
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
@@ -0,0 +1,141 @@
+package history
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// Point is a single value in a time series derived from the history journal.
+type Point struct {
+	Time  time.Time
+	Value int64
+}
+
+// UpdatesPerWeek counts the distinct mod updates recorded in the journal for each of
+// the given number of weeks, ending with the week containing now. An update is a
+// unique LastUpdatedAt value per mod, so re-scraping an unchanged mod is not counted
+// twice. Weeks start on Monday (UTC).
+func UpdatesPerWeek(entries []Entry, weeks int, now time.Time) []Point {
+	if weeks <= 0 {
+		return nil
+	}
+
+	points := make([]Point, weeks)
+	current := weekStart(now)
+	for i := range points {
+		points[i].Time = current.AddDate(0, 0, -7*(weeks-1-i))
+	}
+
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		if entry.LastUpdatedAt == nil {
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%d/%d", entry.Game, entry.ModID, entry.LastUpdatedAt.Unix())
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		start := weekStart(entry.LastUpdatedAt.Time)
+		for i := range points {
+			if points[i].Time.Equal(start) {
+				points[i].Value++
+				break
+			}
+		}
+	}
+
+	return points
+}
+
+// DownloadsGrowth returns the combined total downloads of every mod in the journal
+// at the end of each day on which something was recorded. Each mod contributes its
+// most recently recorded total, so the series shows how downloads grew over time.
+func DownloadsGrowth(entries []Entry) []Point {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RecordedAt.Before(sorted[j].RecordedAt)
+	})
+
+	var points []Point
+	latest := make(map[string]int64)
+	for i, entry := range sorted {
+		if count, err := formatters.ParseCount(entry.TotalDLs); err == nil {
+			latest[fmt.Sprintf("%s/%d", entry.Game, entry.ModID)] = count
+		}
+
+		day := dayStart(entry.RecordedAt)
+		if i+1 < len(sorted) && dayStart(sorted[i+1].RecordedAt).Equal(day) {
+			continue
+		}
+
+		var total int64
+		for _, count := range latest {
+			total += count
+		}
+		points = append(points, Point{Time: day, Value: total})
+	}
+
+	return points
+}
+
+// Sample is the popularity of a mod as recorded in the journal at a time.
+type Sample struct {
+	Downloads    int64
+	Endorsements int64
+	Time         time.Time
+}
+
+// ModTrend returns the total downloads and endorsements recorded for the mod, one
+// sample per recording in chronological order, so that its growth can be charted. A
+// count missing from a recording, or that can't be parsed, keeps its previous value,
+// and recordings holding neither count are left out.
+func ModTrend(entries []Entry, game string, modID int64) []Sample {
+	sorted := Filter(entries, game, modID)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].RecordedAt.Before(sorted[j].RecordedAt)
+	})
+
+	var (
+		samples  []Sample
+		previous Sample
+	)
+	for _, entry := range sorted {
+		sample := Sample{Downloads: previous.Downloads, Endorsements: previous.Endorsements, Time: entry.RecordedAt}
+		downloads, downloadsErr := formatters.ParseCount(entry.TotalDLs)
+		if downloadsErr == nil {
+			sample.Downloads = downloads
+		}
+		endorsements, endorsementsErr := formatters.ParseCount(entry.Endorsements)
+		if endorsementsErr == nil {
+			sample.Endorsements = endorsements
+		}
+		if downloadsErr != nil && endorsementsErr != nil {
+			continue
+		}
+
+		samples = append(samples, sample)
+		previous = sample
+	}
+
+	return samples
+}
+
+// weekStart returns midnight UTC on the Monday of the week containing t.
+func weekStart(t time.Time) time.Time {
+	day := dayStart(t)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// dayStart returns midnight UTC on the day containing t.
+func dayStart(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,79 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdatesPerWeek(t *testing.T) {
+	// Arrange: Wednesday 12 June 2024
+	now := time.Date(2024, time.June, 12, 15, 0, 0, 0, time.UTC)
+	thisWeek := types.NewTimestamp(time.Date(2024, time.June, 10, 9, 0, 0, 0, time.UTC))
+	lastWeek := types.NewTimestamp(time.Date(2024, time.June, 5, 9, 0, 0, 0, time.UTC))
+	entries := []Entry{
+		{Game: "skyrim", ModID: 1, LastUpdatedAt: thisWeek},
+		{Game: "skyrim", ModID: 1, LastUpdatedAt: thisWeek}, // re-scraped, not a new update
+		{Game: "skyrim", ModID: 2, LastUpdatedAt: thisWeek},
+		{Game: "skyrim", ModID: 3, LastUpdatedAt: lastWeek},
+		{Game: "skyrim", ModID: 4},
+	}
+
+	// Act
+	points := UpdatesPerWeek(entries, 3, now)
+
+	// Assert
+	assert.Equal(t, []Point{
+		{Time: time.Date(2024, time.May, 27, 0, 0, 0, 0, time.UTC), Value: 0},
+		{Time: time.Date(2024, time.June, 3, 0, 0, 0, 0, time.UTC), Value: 1},
+		{Time: time.Date(2024, time.June, 10, 0, 0, 0, 0, time.UTC), Value: 2},
+	}, points)
+}
+
+func TestUpdatesPerWeek_NoWeeks(t *testing.T) {
+	assert.Nil(t, UpdatesPerWeek([]Entry{{}}, 0, time.Now()))
+}
+
+func TestDownloadsGrowth(t *testing.T) {
+	// Arrange
+	day1 := time.Date(2024, time.June, 1, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, time.June, 2, 10, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Game: "skyrim", ModID: 2, TotalDLs: "50", RecordedAt: day1.Add(time.Hour)},
+		{Game: "skyrim", ModID: 1, TotalDLs: "1,000", RecordedAt: day1},
+		{Game: "skyrim", ModID: 1, TotalDLs: "1,200", RecordedAt: day2},
+	}
+
+	// Act
+	points := DownloadsGrowth(entries)
+
+	// Assert
+	assert.Equal(t, []Point{
+		{Time: time.Date(2024, time.June, 1, 0, 0, 0, 0, time.UTC), Value: 1050},
+		{Time: time.Date(2024, time.June, 2, 0, 0, 0, 0, time.UTC), Value: 1250},
+	}, points)
+}
+
+func TestModTrend(t *testing.T) {
+	// Arrange
+	day1 := time.Date(2024, time.June, 1, 10, 0, 0, 0, time.UTC)
+	day2 := time.Date(2024, time.June, 2, 10, 0, 0, 0, time.UTC)
+	day3 := time.Date(2024, time.June, 3, 10, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Game: "skyrim", ModID: 1, TotalDLs: "1,200", RecordedAt: day3},
+		{Game: "skyrim", ModID: 1, TotalDLs: "1,000", Endorsements: "10", RecordedAt: day1},
+		{Game: "skyrim", ModID: 2, TotalDLs: "50", Endorsements: "1", RecordedAt: day1},
+		{Game: "skyrim", ModID: 1, RecordedAt: day2}, // recorded without counts
+	}
+
+	// Act
+	samples := ModTrend(entries, "skyrim", 1)
+
+	// Assert
+	assert.Equal(t, []Sample{
+		{Downloads: 1000, Endorsements: 10, Time: day1},
+		{Downloads: 1200, Endorsements: 10, Time: day3},
+	}, samples)
+}
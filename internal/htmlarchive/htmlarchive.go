@@ -0,0 +1,172 @@
+package htmlarchive
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/usage"
+)
+
+const (
+	// FilesSuffix ends the name of the archived files tab of a mod, following the name
+	// of its JSON result, e.g. "skyui 3863.files.html.gz".
+	FilesSuffix = ".files.html.gz"
+	// PageSuffix ends the name of the archived main page of a mod, following the name
+	// of its JSON result, e.g. "skyui 3863.page.html.gz".
+	PageSuffix = ".page.html.gz"
+)
+
+// Paths returns the paths the main page and files tab of the mod saved as
+// <dir>/<name>.json are archived to.
+func Paths(dir, name string) (page, files string) {
+	return filepath.Join(dir, name+PageSuffix), filepath.Join(dir, name+FilesSuffix)
+}
+
+// ResultsPath returns the path of the JSON result an archived main page or files tab
+// belongs to, reporting false when path isn't an archived page.
+func ResultsPath(path string) (string, bool) {
+	for _, suffix := range []string{PageSuffix, FilesSuffix} {
+		if strings.HasSuffix(path, suffix) {
+			return strings.TrimSuffix(path, suffix) + ".json", true
+		}
+	}
+	return "", false
+}
+
+// Recorder keeps the documents fetched through it so that they can be archived once
+// the mod is saved. A Recorder is safe for concurrent use, as the main page and files
+// tab are fetched concurrently.
+type Recorder struct {
+	mu    sync.Mutex
+	files *goquery.Document
+	page  *goquery.Document
+}
+
+// NewRecorder returns an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Wrap returns a fetch function calling fetchDocument and recording the documents it
+// returns, telling the files tab from the main page by its URL.
+func (r *Recorder) Wrap(fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	return func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		doc, err := fetchDocument(ctx, targetURL)
+		if err != nil {
+			return doc, err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if isFilesTab(targetURL) {
+			r.files = doc
+		} else {
+			r.page = doc
+		}
+		return doc, nil
+	}
+}
+
+// Save archives the recorded main page and files tab next to the JSON result saved as
+// <dir>/<name>.json. Returns the paths written, or an error if a page can't be saved.
+func (r *Recorder) Save(dir, name string) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	pagePath, filesPath := Paths(dir, name)
+	var saved []string
+	for _, archive := range []struct {
+		doc  *goquery.Document
+		path string
+	}{{r.page, pagePath}, {r.files, filesPath}} {
+		if archive.doc == nil {
+			continue
+		}
+		if err := Write(archive.path, archive.doc); err != nil {
+			return saved, err
+		}
+		saved = append(saved, archive.path)
+	}
+	return saved, nil
+}
+
+// Write saves the HTML of the document gzipped to path.
+func Write(path string, doc *goquery.Document) error {
+	html, err := doc.Html()
+	if err != nil {
+		return fmt.Errorf("error rendering %s: %w", path, err)
+	}
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+	if _, err := io.WriteString(writer, html); err != nil {
+		return fmt.Errorf("error compressing %s: %w", path, err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("error compressing %s: %w", path, err)
+	}
+
+	if err := fsys.Default.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
+
+// Read parses the gzipped HTML archived at path.
+func Read(path string) (*goquery.Document, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading archived page: %w", err)
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("error decompressing %s: %w", path, err)
+	}
+	defer reader.Close()
+
+	doc, err := goquery.NewDocumentFromReader(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// Fetcher returns a fetch function serving the archived pages of the mod saved as
+// <dir>/<name>.json instead of requesting them, the files tab for files tab URLs and
+// the main page for any other URL. Each page served counts as a cache hit in the usage
+// stats.
+func Fetcher(dir, name string) func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	pagePath, filesPath := Paths(dir, name)
+	return func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		path := pagePath
+		if isFilesTab(targetURL) {
+			path = filesPath
+		}
+
+		doc, err := Read(path)
+		if err != nil {
+			return nil, err
+		}
+		usage.AddCacheHit()
+		return doc, nil
+	}
+}
+
+// isFilesTab reports whether the URL points at the files tab of a mod page.
+func isFilesTab(targetURL string) bool {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return false
+	}
+	return parsed.Query().Get("tab") == "files"
+}
@@ -0,0 +1,79 @@
+package htmlarchive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecorder_SaveAndFetcher(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, fsys.Default.MkdirAll("out/skyrim", 0755))
+	fetchDocument := func(_ context.Context, targetURL string) (*goquery.Document, error) {
+		return goquery.NewDocumentFromReader(strings.NewReader("<html><body><p>" + targetURL + "</p></body></html>"))
+	}
+	recorder := NewRecorder()
+	fetch := recorder.Wrap(fetchDocument)
+	_, pageErr := fetch(context.Background(), "https://nexusmods.com/skyrim/mods/1")
+	_, filesErr := fetch(context.Background(), "https://nexusmods.com/skyrim/mods/1?tab=files")
+
+	// Act
+	saved, err := recorder.Save("out/skyrim", "mod 1")
+	archived := Fetcher("out/skyrim", "mod 1")
+	page, readPageErr := archived(context.Background(), "https://nexusmods.com/skyrim/mods/1")
+	files, readFilesErr := archived(context.Background(), "https://nexusmods.com/skyrim/mods/1?tab=files")
+
+	// Assert
+	require.NoError(t, pageErr)
+	require.NoError(t, filesErr)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"out/skyrim/mod 1.page.html.gz", "out/skyrim/mod 1.files.html.gz"}, saved)
+	assert.NoError(t, readPageErr)
+	assert.Equal(t, "https://nexusmods.com/skyrim/mods/1", page.Find("p").Text())
+	assert.NoError(t, readFilesErr)
+	assert.Equal(t, "https://nexusmods.com/skyrim/mods/1?tab=files", files.Find("p").Text())
+}
+
+func TestRecorder_SaveNothingFetched(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+
+	saved, err := NewRecorder().Save("out", "mod 1")
+
+	assert.NoError(t, err)
+	assert.Empty(t, saved)
+}
+
+func TestRead_Missing(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+
+	_, err := Read("missing.page.html.gz")
+
+	assert.ErrorContains(t, err, "error reading archived page")
+}
+
+func TestResultsPath(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected string
+		ok       bool
+	}{
+		{"skyrim/skyui 3863.page.html.gz", "skyrim/skyui 3863.json", true},
+		{"skyrim/skyui 3863.files.html.gz", "skyrim/skyui 3863.json", true},
+		{"skyrim/skyui 3863.json", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			path, ok := ResultsPath(tt.path)
+
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, path)
+		})
+	}
+}
@@ -1,13 +1,24 @@
 package httpclient
 
 import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net"
 	"net/http"
 	"net/http/cookiejar"
 	"net/url"
-	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
 )
 
 // HTTPClient is an interface that defines a single method, Do, for executing an
@@ -18,42 +29,359 @@ type HTTPClient interface {
 }
 
 // Client is a variable of type HTTPClient, representing the HTTP client that
-// will be used to send HTTP requests. It can be set to any implementation of
-// the HTTPClient interface.
+// will be used to send HTTP requests when no client is scoped to the request's
+// context. It can be set to any implementation of the HTTPClient interface.
 var Client HTTPClient
 
-// InitClient initializes the HTTP client with a new CookieJar for managing cookies.
-// It also loads cookies from the specified file and sets them for the given domain.
-// Returns an error if the CookieJar creation or setting cookies fails.
-func InitClient(domain, dir, filename string) error {
+// clientKey is the context key of the HTTP client scoped to a run.
+type clientKey struct{}
+
+// limiterKey is the context key of the Limiter shared by the requests of a run.
+type limiterKey struct{}
+
+// TransportOptions holds the tuning of the connections to Nexus Mods.
+type TransportOptions struct {
+	// ConnectTimeout bounds opening a connection, its TLS handshake included. Zero
+	// leaves it to the operating system.
+	ConnectTimeout time.Duration
+	// HTTP2 enables HTTP/2 when the server supports it; disabled, HTTP/1.1 is used.
+	HTTP2 bool
+	// IdleConnTimeout is how long an idle connection is kept open for reuse.
+	IdleConnTimeout time.Duration
+	// KeepAlive is the interval of the TCP keep-alive probes of open connections. Zero
+	// disables both the probes and the reuse of connections, opening one per request,
+	// for proxies or networks that drop idle connections.
+	KeepAlive time.Duration
+	// MaxIdleConns is the number of idle connections kept open across all hosts.
+	MaxIdleConns int
+	// MaxIdleConnsPerHost is the number of idle connections kept open per host.
+	MaxIdleConnsPerHost int
+	// RootCAs are the certificate authorities trusted for TLS connections, e.g. the
+	// system ones along with the one of an intercepting proxy. Nil trusts the system
+	// ones only.
+	RootCAs *x509.CertPool
+	// Timeout bounds a whole request, from connecting to reading the body of the
+	// response. Zero means no timeout.
+	Timeout time.Duration
+}
+
+// DefaultTransportOptions returns the connection tuning used unless configured
+// otherwise: HTTP/2 enabled, enough idle connections per host for the concurrent
+// requests of a batch scrape to be reused instead of reopened, and timeouts generous
+// enough for the largest mod pages while not letting a stalled request hang a run.
+func DefaultTransportOptions() TransportOptions {
+	return TransportOptions{
+		ConnectTimeout:      10 * time.Second,
+		HTTP2:               true,
+		IdleConnTimeout:     90 * time.Second,
+		KeepAlive:           30 * time.Second,
+		MaxIdleConns:        100,
+		MaxIdleConnsPerHost: 10,
+		Timeout:             60 * time.Second,
+	}
+}
+
+var (
+	// transport is shared by every client built by NewClient, so that connections are
+	// reused across the requests of a run and across runs, whatever their cookies.
+	transport = NewTransport(DefaultTransportOptions())
+	// timeout bounds the requests of the clients built by NewClient.
+	timeout = DefaultTransportOptions().Timeout
+)
+
+// NewTransport returns an HTTP transport tuned with options, using the proxy settings
+// of the environment like the default transport.
+func NewTransport(options TransportOptions) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	dialer := &net.Dialer{Timeout: options.ConnectTimeout, KeepAlive: options.KeepAlive}
+	if options.KeepAlive == 0 {
+		// A negative interval turns the probes off, zero would use the system default
+		dialer.KeepAlive = -1
+		t.DisableKeepAlives = true
+	}
+	t.DialContext = dialer.DialContext
+	t.TLSHandshakeTimeout = options.ConnectTimeout
+	if options.RootCAs != nil {
+		t.TLSClientConfig = &tls.Config{RootCAs: options.RootCAs}
+	}
+	t.IdleConnTimeout = options.IdleConnTimeout
+	t.MaxIdleConns = options.MaxIdleConns
+	t.MaxIdleConnsPerHost = options.MaxIdleConnsPerHost
+	t.ForceAttemptHTTP2 = options.HTTP2
+	if !options.HTTP2 {
+		// A non-nil empty map disables the automatic HTTP/2 upgrade
+		t.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+		if t.TLSClientConfig != nil {
+			t.TLSClientConfig.NextProtos = nil
+		}
+	}
+	return t
+}
+
+// ConfigureTransport replaces the transport shared by the clients built afterwards with
+// one tuned with options, closing the idle connections of the previous one, and bounds
+// their requests with the timeout of options.
+func ConfigureTransport(options TransportOptions) {
+	previous := transport
+	transport = NewTransport(options)
+	timeout = options.Timeout
+	previous.CloseIdleConnections()
+}
+
+// LoadCABundle returns the certificate authorities of the system along with the ones
+// of the PEM file at path, e.g. the certificate of an intercepting proxy. Returns an
+// error if the file can't be read or holds no certificate.
+func LoadCABundle(path string) (*x509.CertPool, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading CA bundle: %w", err)
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil || pool == nil {
+		pool = x509.NewCertPool()
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificate found in CA bundle %s", path)
+	}
+	return pool, nil
+}
+
+// Transport returns the transport shared by the clients, for clients that need their
+// own settings, such as not following redirects, to still use the configured tuning.
+func Transport() http.RoundTripper {
+	return transport
+}
+
+// Timeout returns the configured timeout of a whole request, zero meaning none.
+func Timeout() time.Duration {
+	return timeout
+}
+
+// NewClient returns an HTTP client using the shared transport and the configured
+// request timeout, with its own CookieJar, holding the cookies loaded from the
+// specified file for the given domain, unless a Cookie header is provided, in which
+// case the cookies are parsed from it and the file isn't read. Without a header or a
+// file name, the client starts without cookies. The global Client is left untouched,
+// so that runs using different cookies, e.g. different accounts, can't mix up their
+// sessions. Returns an error if the CookieJar creation or setting cookies fails.
+func NewClient(domain, dir, filename, cookieHeader string) (*http.Client, error) {
 	// Create a new CookieJar
 	jar, err := cookiejar.New(nil)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	// Initialize the HTTP client with the cookie jar, sharing the connections of the
+	// other clients
+	client := &http.Client{
+		Jar:       jar, // Set the CookieJar to manage cookies automatically
+		Timeout:   timeout,
+		Transport: transport,
 	}
 
-	// Initialize the HTTP client with the cookie jar
-	Client = &http.Client{
-		Jar: jar, // Set the CookieJar to manage cookies automatically
+	// Cookies given as a header bypass the cookie file entirely
+	switch {
+	case cookieHeader != "":
+		err = setCookiesFromHeader(client, domain, cookieHeader)
+	case filename != "":
+		err = setCookiesFromFile(client, domain, dir, filename)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	// Call the helper function to set the cookies
-	if err := setCookiesFromFile(domain, dir, filename); err != nil {
+	return client, nil
+}
+
+// InitClient initializes the global HTTP client with a new client built by NewClient.
+// Returns an error if the CookieJar creation or setting cookies fails, in which case the
+// global client is left unchanged.
+func InitClient(domain, dir, filename, cookieHeader string) error {
+	client, err := NewClient(domain, dir, filename, cookieHeader)
+	if err != nil {
 		return err
 	}
 
+	Client = client
 	return nil
 }
 
+// WithClient returns a copy of ctx carrying client, which FromContext returns instead
+// of the global Client.
+func WithClient(ctx context.Context, client HTTPClient) context.Context {
+	return context.WithValue(ctx, clientKey{}, client)
+}
+
+// FromContext returns the HTTP client scoped to ctx with WithClient, or the global
+// Client if there is none.
+func FromContext(ctx context.Context) HTTPClient {
+	if client, ok := ctx.Value(clientKey{}).(HTTPClient); ok {
+		return client
+	}
+	return Client
+}
+
+// Limiter spaces out requests, starting at most one per interval however many
+// goroutines share it, e.g. the page fetches and image downloads of a whole run. It is
+// safe for concurrent use, and a nil Limiter or a zero interval doesn't limit.
+type Limiter struct {
+	interval time.Duration
+	mu       sync.Mutex
+	next     time.Time
+}
+
+// NewLimiter returns a Limiter starting at most one request per interval.
+func NewLimiter(interval time.Duration) *Limiter {
+	return &Limiter{interval: interval}
+}
+
+// Interval returns the minimum time between the start of two requests, zero for a nil
+// Limiter.
+func (l *Limiter) Interval() time.Duration {
+	if l == nil {
+		return 0
+	}
+	return l.interval
+}
+
+// WithLimiter returns a copy of ctx carrying limiter, which the requests made with ctx
+// wait on, so that the requests of a whole run share one rate limit.
+func WithLimiter(ctx context.Context, limiter *Limiter) context.Context {
+	return context.WithValue(ctx, limiterKey{}, limiter)
+}
+
+// LimiterFrom returns the Limiter carried by ctx, or nil, which doesn't limit, if there
+// is none.
+func LimiterFrom(ctx context.Context) *Limiter {
+	limiter, _ := ctx.Value(limiterKey{}).(*Limiter)
+	return limiter
+}
+
+// Wait blocks until the turn of the caller comes, each call taking the next free slot
+// so that callers are let through in the order they called. Returns the error of ctx
+// if it is done first, the slot being lost.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if l == nil || l.interval <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(l.interval)
+	l.mu.Unlock()
+
+	wait := time.Until(start)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// browserHeaders are sent with every request built by NewBrowserRequest, so that pages
+// are served as they would be to a browser.
+var browserHeaders = map[string]string{
+	"Accept":     "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+	"User-Agent": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0 Safari/537.36",
+}
+
+// AcceptLanguage is the Accept-Language header sent with every request built by
+// NewBrowserRequest, asking for the pages in the language set with --locale.
+var AcceptLanguage = "en-US,en;q=0.9"
+
+// NewBrowserRequest returns a GET request for targetURL bound to ctx, carrying the
+// browser headers and a Cookie header built from cookies. Every page request goes
+// through it so that a change to the headers applies to all of them. Returns an error
+// if targetURL is invalid.
+func NewBrowserRequest(ctx context.Context, targetURL string, cookies []*http.Cookie) (*http.Request, error) {
+	return newBrowserRequest(ctx, http.MethodGet, targetURL, nil, cookies)
+}
+
+// NewBrowserJSONRequest returns a POST request for targetURL bound to ctx, sending body
+// as JSON and asking for JSON back, with the same browser headers and Cookie header as
+// NewBrowserRequest. Returns an error if targetURL is invalid.
+func NewBrowserJSONRequest(ctx context.Context, targetURL string, body []byte, cookies []*http.Cookie) (*http.Request, error) {
+	req, err := newBrowserRequest(ctx, http.MethodPost, targetURL, bytes.NewReader(body), cookies)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	return req, nil
+}
+
+// newBrowserRequest returns a request with the given method for targetURL bound to ctx,
+// carrying body, the browser headers and a Cookie header built from cookies. Returns
+// an error if targetURL is invalid.
+func newBrowserRequest(ctx context.Context, method, targetURL string, body io.Reader, cookies []*http.Cookie) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, targetURL, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for name, value := range browserHeaders {
+		req.Header.Set(name, value)
+	}
+	req.Header.Set("Accept-Language", AcceptLanguage)
+
+	// Build the Cookie header string manually from the cookies
+	cookieHeader := make([]string, 0, len(cookies))
+	for _, cookie := range cookies {
+		cookieHeader = append(cookieHeader, fmt.Sprintf("%s=%s", cookie.Name, cookie.Value))
+	}
+	req.Header.Set("Cookie", strings.Join(cookieHeader, "; "))
+
+	return req, nil
+}
+
+// CookieHeader formats cookies as the value of a Cookie header, sorted by name.
+func CookieHeader(cookies map[string]string) string {
+	names := make([]string, 0, len(cookies))
+	for name := range cookies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = name + "=" + cookies[name]
+	}
+	return strings.Join(pairs, "; ")
+}
+
+// setCookiesFromHeader parses cookies from the value of a Cookie header, e.g.
+// "nexusmods_session=abc; nexusmods_session_refresh=def", and sets them for the
+// specified domain in the client's CookieJar. Returns an error if the header holds no
+// valid cookie or the domain is invalid.
+func setCookiesFromHeader(client *http.Client, domain, header string) error {
+	cookies, err := http.ParseCookie(header)
+	if err != nil {
+		return fmt.Errorf("error parsing cookie header: %w", err)
+	}
+
+	return setCookies(client, domain, cookies)
+}
+
 // setCookiesFromFile reads cookies from a JSON file, creates HTTP cookie objects,
 // and sets them for the specified domain in the client's CookieJar. Returns an error
 // if the file cannot be opened, the JSON cannot be decoded, or the domain is invalid.
-func setCookiesFromFile(domain, dir, filename string) error {
+func setCookiesFromFile(client *http.Client, domain, dir, filename string) error {
 	// Combine dir and filename
 	cookieFilePath := filepath.Join(dir, filename)
 
 	// Open the JSON file
-	file, err := os.Open(cookieFilePath)
+	file, err := fsys.Open(cookieFilePath)
 	if err != nil {
 		return fmt.Errorf("error opening cookie file: %w", err)
 	}
@@ -74,8 +402,13 @@ func setCookiesFromFile(domain, dir, filename string) error {
 		})
 	}
 
-	// Set cookies for the domain
-	if jar, ok := Client.(*http.Client).Jar.(*cookiejar.Jar); ok {
+	return setCookies(client, domain, cookies)
+}
+
+// setCookies sets the cookies for the specified domain in the client's CookieJar.
+// Returns an error if the domain is invalid.
+func setCookies(client *http.Client, domain string, cookies []*http.Cookie) error {
+	if jar, ok := client.Jar.(*cookiejar.Jar); ok {
 		u, err := url.Parse(domain)
 		if err != nil {
 			return fmt.Errorf("error parsing domain: %w", err)
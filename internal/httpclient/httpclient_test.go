@@ -1,15 +1,21 @@
 package httpclient
 
 import (
+	"context"
 	"encoding/json"
+	"encoding/pem"
+	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mocker is a mock implementation of the HTTPClient interface for testing.
@@ -51,7 +57,7 @@ func TestInitClient_Success(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Act
-	err = InitClient(domain, dir, filepath.Base(file.Name()))
+	err = InitClient(domain, dir, filepath.Base(file.Name()), "")
 
 	// Assert
 	assert.NoError(t, err)
@@ -103,7 +109,7 @@ func TestSetCookiesFromFile_Success(t *testing.T) {
 	mockJar.On("Cookies", u).Return(mockCookies)
 
 	// Act
-	err = InitClient(domain, dir, filename)
+	err = InitClient(domain, dir, filename, "")
 	assert.NoError(t, err)
 
 	// Assert
@@ -121,7 +127,7 @@ func TestSetCookiesFromFile_FileError(t *testing.T) {
 	filename := "nonexistent.json"
 
 	// Act
-	err := InitClient(domain, dir, filename)
+	err := InitClient(domain, dir, filename, "")
 
 	// Assert
 	assert.Error(t, err)
@@ -144,9 +150,285 @@ func TestSetCookiesFromFile_JSONError(t *testing.T) {
 	assert.NoError(t, err)
 
 	// Act
-	err = InitClient(domain, dir, filename)
+	err = InitClient(domain, dir, filename, "")
 
 	// Assert
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "error decoding JSON")
 }
+
+func TestSetCookiesFromHeader_Success(t *testing.T) {
+	// Arrange
+	domain := "https://example.com"
+	dir := t.TempDir() // No cookie file exists, the header bypasses it
+
+	// Act
+	err := InitClient(domain, dir, "missing.json", "nexusmods_session=abc; nexusmods_session_refresh=def")
+
+	// Assert
+	assert.NoError(t, err)
+	u, _ := url.Parse(domain)
+	cookies := Client.(*http.Client).Jar.Cookies(u)
+	assert.Len(t, cookies, 2)
+	assert.Equal(t, "nexusmods_session", cookies[0].Name)
+	assert.Equal(t, "abc", cookies[0].Value)
+	assert.Equal(t, "nexusmods_session_refresh", cookies[1].Name)
+	assert.Equal(t, "def", cookies[1].Value)
+}
+
+func TestSetCookiesFromHeader_InvalidHeader(t *testing.T) {
+	// Act
+	err := InitClient("https://example.com", t.TempDir(), "missing.json", "not a cookie")
+
+	// Assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "error parsing cookie header")
+}
+
+func TestCookieHeader(t *testing.T) {
+	// Act
+	header := CookieHeader(map[string]string{"nexusmods_session_refresh": "def", "nexusmods_session": "abc"})
+
+	// Assert
+	assert.Equal(t, "nexusmods_session=abc; nexusmods_session_refresh=def", header)
+	assert.Empty(t, CookieHeader(nil))
+}
+
+func TestNewClient_SeparateJars(t *testing.T) {
+	// Arrange
+	domain := "https://example.com"
+	u, _ := url.Parse(domain)
+	global := &http.Client{}
+	Client = global
+
+	// Act
+	first, err := NewClient(domain, t.TempDir(), "missing.json", "nexusmods_session=first")
+	assert.NoError(t, err)
+	second, err := NewClient(domain, t.TempDir(), "missing.json", "nexusmods_session=second")
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Same(t, global, Client)
+	assert.Equal(t, "first", first.Jar.Cookies(u)[0].Value)
+	assert.Equal(t, "second", second.Jar.Cookies(u)[0].Value)
+}
+
+func TestNewClient_WithoutCookies(t *testing.T) {
+	// Act
+	client, err := NewClient("https://example.com", "", "", "")
+
+	// Assert
+	assert.NoError(t, err)
+	u, _ := url.Parse("https://example.com")
+	assert.Empty(t, client.Jar.Cookies(u))
+}
+
+func TestFromContext(t *testing.T) {
+	// Arrange
+	global := &http.Client{}
+	scoped := &http.Client{}
+	Client = global
+
+	// Act & Assert
+	assert.Same(t, global, FromContext(context.Background()))
+	assert.Same(t, scoped, FromContext(WithClient(context.Background(), scoped)))
+}
+
+func TestLimiter_Wait(t *testing.T) {
+	// Arrange
+	limiter := NewLimiter(20 * time.Millisecond)
+	start := time.Now()
+
+	// Act
+	for i := 0; i < 3; i++ {
+		require.NoError(t, limiter.Wait(context.Background()))
+	}
+
+	// Assert
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestLimiter_WaitCancelled(t *testing.T) {
+	// Arrange
+	limiter := NewLimiter(time.Hour)
+	require.NoError(t, limiter.Wait(context.Background()))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Act
+	err := limiter.Wait(ctx)
+
+	// Assert
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestLimiter_Unlimited(t *testing.T) {
+	// Arrange
+	var limiter *Limiter
+
+	// Act & Assert
+	assert.NoError(t, limiter.Wait(context.Background()))
+	assert.NoError(t, NewLimiter(0).Wait(context.Background()))
+}
+
+func TestNewTransport(t *testing.T) {
+	tests := []struct {
+		name    string
+		options TransportOptions
+	}{
+		{"http2", DefaultTransportOptions()},
+		{"http1", TransportOptions{IdleConnTimeout: time.Minute, KeepAlive: time.Minute, MaxIdleConns: 5, MaxIdleConnsPerHost: 3}},
+		{"no keep-alive", TransportOptions{HTTP2: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			transport := NewTransport(tt.options)
+
+			// Assert
+			assert.Equal(t, tt.options.IdleConnTimeout, transport.IdleConnTimeout)
+			assert.Equal(t, tt.options.MaxIdleConns, transport.MaxIdleConns)
+			assert.Equal(t, tt.options.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+			assert.Equal(t, tt.options.HTTP2, transport.ForceAttemptHTTP2)
+			assert.Equal(t, tt.options.ConnectTimeout, transport.TLSHandshakeTimeout)
+			assert.Equal(t, tt.options.KeepAlive == 0, transport.DisableKeepAlives)
+			// HTTP/2 is disabled by a non-nil, empty TLSNextProto
+			assert.Equal(t, !tt.options.HTTP2, transport.TLSNextProto != nil && len(transport.TLSNextProto) == 0)
+		})
+	}
+}
+
+func TestConfigureTransport_SharedByClients(t *testing.T) {
+	// Arrange
+	defer ConfigureTransport(DefaultTransportOptions())
+	options := TransportOptions{MaxIdleConnsPerHost: 32}
+
+	// Act
+	ConfigureTransport(options)
+	first, err := NewClient("https://example.com", "", "", "nexusmods_session=first")
+	assert.NoError(t, err)
+	second, err := NewClient("https://example.com", "", "", "nexusmods_session=second")
+	assert.NoError(t, err)
+
+	// Assert
+	assert.Same(t, first.Transport, second.Transport)
+	assert.Equal(t, 32, first.Transport.(*http.Transport).MaxIdleConnsPerHost)
+}
+
+func TestConfigureTransport_Timeout(t *testing.T) {
+	// Arrange
+	defer ConfigureTransport(DefaultTransportOptions())
+	stalled := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer stalled.Close()
+
+	// Act
+	ConfigureTransport(TransportOptions{Timeout: 50 * time.Millisecond})
+	client, err := NewClient(stalled.URL, "", "", "")
+	require.NoError(t, err)
+	_, err = client.Get(stalled.URL)
+
+	// Assert
+	assert.Equal(t, 50*time.Millisecond, Timeout())
+	assert.Equal(t, 50*time.Millisecond, client.Timeout)
+	assert.ErrorContains(t, err, "Client.Timeout exceeded")
+}
+
+func TestNewBrowserRequest(t *testing.T) {
+	// Arrange
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "run")
+	cookies := []*http.Cookie{{Name: "session", Value: "abc"}, {Name: "refresh", Value: "def"}}
+
+	// Act
+	req, err := NewBrowserRequest(ctx, "https://example.com/page", cookies)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, http.MethodGet, req.Method)
+	assert.Equal(t, "run", req.Context().Value(ctxKey{}))
+	assert.Equal(t, "session=abc; refresh=def", req.Header.Get("Cookie"))
+	for name, value := range browserHeaders {
+		assert.Equal(t, value, req.Header.Get(name), name)
+	}
+	assert.Equal(t, "en-US,en;q=0.9", req.Header.Get("Accept-Language"))
+}
+
+func TestNewBrowserJSONRequest(t *testing.T) {
+	// Arrange
+	cookies := []*http.Cookie{{Name: "session", Value: "abc"}}
+
+	// Act
+	req, err := NewBrowserJSONRequest(context.Background(), "https://example.com/graphql", []byte(`{"query":"{}"}`), cookies)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, req.Method)
+	body, err := io.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, `{"query":"{}"}`, string(body))
+	assert.Equal(t, "application/json", req.Header.Get("Accept"))
+	assert.Equal(t, "application/json", req.Header.Get("Content-Type"))
+	assert.Equal(t, browserHeaders["User-Agent"], req.Header.Get("User-Agent"))
+	assert.Equal(t, AcceptLanguage, req.Header.Get("Accept-Language"))
+	assert.Equal(t, "session=abc", req.Header.Get("Cookie"))
+}
+
+func TestNewBrowserRequest_AcceptLanguage(t *testing.T) {
+	defer func(previous string) { AcceptLanguage = previous }(AcceptLanguage)
+	AcceptLanguage = "de-DE,de;q=0.9,en;q=0.8"
+
+	req, err := NewBrowserRequest(context.Background(), "https://example.com/page", nil)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "de-DE,de;q=0.9,en;q=0.8", req.Header.Get("Accept-Language"))
+}
+
+func TestNewBrowserRequest_InvalidURL(t *testing.T) {
+	_, err := NewBrowserRequest(context.Background(), "://invalid-url", nil)
+
+	assert.Error(t, err)
+}
+
+func TestLoadCABundle(t *testing.T) {
+	// Arrange
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "proxy.pem")
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(path, certificate, 0644))
+
+	// Act
+	pool, err := LoadCABundle(path)
+	require.NoError(t, err)
+	options := DefaultTransportOptions()
+	options.RootCAs = pool
+	resp, err := (&http.Client{Transport: NewTransport(options)}).Get(server.URL)
+
+	// Assert
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestLoadCABundle_NoCertificate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "empty.pem")
+	require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0644))
+
+	_, err := LoadCABundle(path)
+
+	assert.EqualError(t, err, "no PEM certificate found in CA bundle "+path)
+}
+
+func TestLimiterFrom(t *testing.T) {
+	// Arrange
+	limiter := NewLimiter(time.Second)
+
+	// Act & Assert
+	assert.Nil(t, LimiterFrom(context.Background()))
+	assert.Same(t, limiter, LimiterFrom(WithLimiter(context.Background(), limiter)))
+	assert.Equal(t, time.Second, limiter.Interval())
+	assert.Zero(t, LimiterFrom(context.Background()).Interval())
+}
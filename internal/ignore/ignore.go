@@ -0,0 +1,114 @@
+package ignore
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+// DefaultFilename is the name of the ignore list file in the data directory.
+const DefaultFilename = "ignore.json"
+
+// Entry is a mod skipped by the batch, author, collection and watch modes, with the
+// reason it was ignored for, if any.
+type Entry struct {
+	AddedAt time.Time `json:"addedAt"`
+	Game    string    `json:"game"`
+	ModID   int64     `json:"modId"`
+	Reason  string    `json:"reason,omitempty"`
+}
+
+// List is the ignore list, sorted by game and mod ID.
+type List []Entry
+
+// Load reads the ignore list at path. A missing file is not an error and yields an
+// empty list.
+func Load(path string) (List, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if os.IsNotExist(err) {
+		return List{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading ignore list: %w", err)
+	}
+
+	var list List
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, fmt.Errorf("error decoding ignore list %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// Save writes the ignore list to path as indented JSON, creating its directory if
+// needed.
+func Save(path string, list List) error {
+	if err := fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if list == nil {
+		list = List{}
+	}
+
+	data, err := json.MarshalIndent(list, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting ignore list: %w", err)
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
+
+// Find returns the entry ignoring the mod of the game, matched ignoring case. Reports
+// false when the mod isn't ignored.
+func (l List) Find(game string, modID int64) (Entry, bool) {
+	for _, entry := range l {
+		if entry.ModID == modID && strings.EqualFold(entry.Game, game) {
+			return entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Add returns the list with the entry added, its game lowercased. Reports false, and
+// returns the list unchanged, when the mod was already ignored. Returns an error if
+// the game or mod ID is missing.
+func (l List) Add(entry Entry) (List, bool, error) {
+	entry.Game = strings.ToLower(strings.TrimSpace(entry.Game))
+	if entry.Game == "" {
+		return l, false, fmt.Errorf("game is required")
+	}
+	if entry.ModID <= 0 {
+		return l, false, fmt.Errorf("mod id must be a positive number")
+	}
+	if _, ok := l.Find(entry.Game, entry.ModID); ok {
+		return l, false, nil
+	}
+
+	list := append(append(List{}, l...), entry)
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Game != list[j].Game {
+			return list[i].Game < list[j].Game
+		}
+		return list[i].ModID < list[j].ModID
+	})
+	return list, true, nil
+}
+
+// Remove returns the list without the mod of the game. Reports false when the mod
+// wasn't ignored.
+func (l List) Remove(game string, modID int64) (List, bool) {
+	list := make(List, 0, len(l))
+	for _, entry := range l {
+		if entry.ModID != modID || !strings.EqualFold(entry.Game, game) {
+			list = append(list, entry)
+		}
+	}
+	return list, len(list) != len(l)
+}
@@ -0,0 +1,67 @@
+package ignore
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestList_AddRemove(t *testing.T) {
+	// Arrange
+	added := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	list := List{}
+
+	// Act
+	list, ok, err := list.Add(Entry{AddedAt: added, Game: "Skyrim", ModID: 3863, Reason: "broken"})
+	require.NoError(t, err)
+	list, again, _ := list.Add(Entry{Game: "skyrim", ModID: 3863})
+	list, _, _ = list.Add(Entry{Game: "fallout4", ModID: 1})
+	list, _, _ = list.Add(Entry{Game: "fallout4", ModID: 2})
+	list, removed := list.Remove("Fallout4", 2)
+	list, removedAgain := list.Remove("fallout4", 2)
+
+	// Assert
+	assert.True(t, ok)
+	assert.False(t, again)
+	assert.True(t, removed)
+	assert.False(t, removedAgain)
+	assert.Equal(t, List{
+		{Game: "fallout4", ModID: 1},
+		{AddedAt: added, Game: "skyrim", ModID: 3863, Reason: "broken"},
+	}, list)
+	entry, found := list.Find("SKYRIM", 3863)
+	assert.True(t, found)
+	assert.Equal(t, "broken", entry.Reason)
+	_, found = list.Find("skyrim", 1)
+	assert.False(t, found)
+}
+
+func TestList_AddInvalid(t *testing.T) {
+	_, _, gameErr := List{}.Add(Entry{ModID: 1})
+	_, _, modErr := List{}.Add(Entry{Game: "skyrim"})
+
+	assert.EqualError(t, gameErr, "game is required")
+	assert.EqualError(t, modErr, "mod id must be a positive number")
+}
+
+func TestLoadSave(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	path := filepath.Join("data", DefaultFilename)
+
+	// Act
+	missing, missingErr := Load(path)
+	saveErr := Save(path, List{{Game: "skyrim", ModID: 3863}})
+	loaded, loadErr := Load(path)
+
+	// Assert
+	require.NoError(t, missingErr)
+	require.NoError(t, saveErr)
+	require.NoError(t, loadErr)
+	assert.Empty(t, missing)
+	assert.Equal(t, List{{Game: "skyrim", ModID: 3863}}, loaded)
+}
@@ -0,0 +1,307 @@
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+)
+
+const (
+	// DirSuffix ends the name of the directory the gallery images of a mod are saved to,
+	// following the name of its JSON result, e.g. "skyui 3863.images".
+	DirSuffix = ".images"
+	// ManifestFilename is the name of the manifest written in the images directory.
+	ManifestFilename = "images.json"
+	// maxImageSize bounds the size of a downloaded image, well above the size of gallery
+	// images, so that a misbehaving server can't exhaust the memory.
+	maxImageSize = 64 << 20
+)
+
+// Dir returns the directory the gallery images of the mod saved as <dir>/<name>.json
+// are downloaded to.
+func Dir(dir, name string) string {
+	return filepath.Join(dir, name+DirSuffix)
+}
+
+// Image is a downloaded gallery image, saved in the images directory under a name
+// made of its SHA-256 checksum, so that an image is stored once however many URLs
+// serve it.
+type Image struct {
+	File   string `json:"File"`
+	SHA256 string `json:"SHA256"`
+	Size   int64  `json:"Size"`
+	Url    string `json:"Url"`
+}
+
+// Manifest lists the gallery images of a mod, in gallery order, with the file each is
+// saved to. It is written as images.json in the images directory, and read back by
+// the next download to skip the images already saved.
+type Manifest struct {
+	Images    []Image   `json:"Images"`
+	UpdatedAt time.Time `json:"UpdatedAt"`
+}
+
+// Result is the outcome of downloading the gallery of a mod.
+type Result struct {
+	// Downloaded is the number of images fetched.
+	Downloaded int
+	// Manifest is the manifest written.
+	Manifest Manifest
+	// Paths are the files written, new images and manifest, e.g. to upload them.
+	Paths []string
+	// Skipped is the number of images already saved with the checksum the manifest
+	// records, which weren't fetched again.
+	Skipped int
+}
+
+// Downloader fetches gallery images, up to Concurrency at a time, each request waiting
+// for its turn with the Limiter carried by the context of the download, if any, so that
+// the images are rate limited together with the pages of the run.
+type Downloader struct {
+	Client      httpclient.HTTPClient
+	Concurrency int
+}
+
+// downloaderKey is the context key of the Downloader of a run.
+type downloaderKey struct{}
+
+// WithDownloader returns a copy of ctx carrying downloader, shared by the mods scraped
+// with it.
+func WithDownloader(ctx context.Context, downloader *Downloader) context.Context {
+	return context.WithValue(ctx, downloaderKey{}, downloader)
+}
+
+// DownloaderFrom returns the Downloader carried by ctx, or nil when there is none.
+func DownloaderFrom(ctx context.Context) *Downloader {
+	downloader, _ := ctx.Value(downloaderKey{}).(*Downloader)
+	return downloader
+}
+
+// Download saves the images at urls to dir and writes their manifest there. An image
+// the previous manifest lists is skipped when its file still has the recorded
+// checksum, and a fetched image whose checksum is already saved isn't written again.
+// Every image is attempted and the manifest lists those saved, so that a failed image
+// is fetched again by the next download. Returns the errors of the images that failed,
+// joined, or an error if the manifest can't be read or written.
+func (d *Downloader) Download(ctx context.Context, dir string, urls []string) (Result, error) {
+	if len(urls) == 0 {
+		return Result{}, nil
+	}
+	previous, err := LoadManifest(dir)
+	if err != nil {
+		return Result{}, err
+	}
+	known := make(map[string]Image, len(previous.Images))
+	for _, image := range previous.Images {
+		known[image.Url] = image
+	}
+	if err := fsys.Default.MkdirAll(dir, os.ModePerm); err != nil {
+		return Result{}, err
+	}
+
+	var (
+		mu     sync.Mutex
+		result Result
+		errs   []error
+		saved  = make([]*Image, len(urls))
+	)
+	group := errgroup.Group{}
+	group.SetLimit(max(d.Concurrency, 1))
+	for i, imageUrl := range urls {
+		group.Go(func() error {
+			if image, ok := known[imageUrl]; ok && hasChecksum(filepath.Join(dir, image.File), image.SHA256) {
+				mu.Lock()
+				saved[i] = &image
+				result.Skipped++
+				mu.Unlock()
+				return nil
+			}
+
+			image, written, err := d.fetch(ctx, dir, imageUrl)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("error downloading %s: %w", imageUrl, err))
+				return nil
+			}
+			saved[i] = &image
+			result.Downloaded++
+			// Two URLs serving the same image may both have written it
+			if filePath := filepath.Join(dir, image.File); written && !slices.Contains(result.Paths, filePath) {
+				result.Paths = append(result.Paths, filePath)
+			}
+			return nil
+		})
+	}
+	group.Wait()
+
+	result.Manifest = Manifest{Images: make([]Image, 0, len(urls)), UpdatedAt: time.Now().UTC()}
+	for _, image := range saved {
+		if image != nil {
+			result.Manifest.Images = append(result.Manifest.Images, *image)
+		}
+	}
+	manifestPath := filepath.Join(dir, ManifestFilename)
+	data, err := json.MarshalIndent(result.Manifest, "", "  ")
+	if err != nil {
+		return result, err
+	}
+	if err := fsys.Default.WriteFile(manifestPath, data, 0644); err != nil {
+		return result, fmt.Errorf("error saving file: %s - %v", manifestPath, err)
+	}
+	result.Paths = append(result.Paths, manifestPath)
+	return result, errors.Join(errs...)
+}
+
+// fetch downloads the image at imageUrl to dir, named after its checksum, reporting
+// whether it was written, which it isn't when a file of that name is already saved.
+func (d *Downloader) fetch(ctx context.Context, dir, imageUrl string) (Image, bool, error) {
+	if err := httpclient.LimiterFrom(ctx).Wait(ctx); err != nil {
+		return Image{}, false, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, imageUrl, nil)
+	if err != nil {
+		return Image{}, false, err
+	}
+	resp, err := d.Client.Do(req)
+	if err != nil {
+		return Image{}, false, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return Image{}, false, fmt.Errorf("server returned status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageSize+1))
+	if err != nil {
+		return Image{}, false, err
+	}
+	if len(data) > maxImageSize {
+		return Image{}, false, fmt.Errorf("image larger than %d MB", maxImageSize>>20)
+	}
+
+	sum := sha256.Sum256(data)
+	checksum := hex.EncodeToString(sum[:])
+	image := Image{File: checksum[:16] + extension(imageUrl, resp.Header.Get("Content-Type")), SHA256: checksum, Size: int64(len(data)), Url: imageUrl}
+	filePath := filepath.Join(dir, image.File)
+	if hasChecksum(filePath, checksum) {
+		return image, false, nil
+	}
+	if err := fsys.Default.WriteFile(filePath, data, 0644); err != nil {
+		return Image{}, false, fmt.Errorf("error saving file: %s - %v", filePath, err)
+	}
+	return image, true, nil
+}
+
+// Gallery keeps the gallery image URLs of the mod page fetched through it, so that
+// they can be downloaded once the mod is saved. A Gallery is safe for concurrent use,
+// as the main page and files tab are fetched concurrently.
+type Gallery struct {
+	mu   sync.Mutex
+	urls []string
+}
+
+// Wrap returns a fetch function calling fetchDocument and extracting the gallery of
+// the main page it returns, the tabs of the mod, such as the files tab, being ignored.
+func (g *Gallery) Wrap(fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)) func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+	return func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		doc, err := fetchDocument(ctx, targetURL)
+		if err != nil || isTab(targetURL) {
+			return doc, err
+		}
+
+		urls := extractors.ExtractGalleryImages(doc)
+		g.mu.Lock()
+		defer g.mu.Unlock()
+		g.urls = urls
+		return doc, nil
+	}
+}
+
+// URLs returns the gallery image URLs of the fetched main page.
+func (g *Gallery) URLs() []string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.urls
+}
+
+// isTab reports whether targetURL is a tab of a mod page rather than its main page.
+func isTab(targetURL string) bool {
+	parsed, err := url.Parse(targetURL)
+	if err != nil {
+		return false
+	}
+	return parsed.Query().Get("tab") != ""
+}
+
+// LoadManifest reads the manifest of the images directory dir. A missing manifest is
+// not an error and yields an empty one.
+func LoadManifest(dir string) (Manifest, error) {
+	manifestPath := filepath.Join(dir, ManifestFilename)
+	data, err := fsys.Default.ReadFile(manifestPath)
+	if os.IsNotExist(err) {
+		return Manifest{}, nil
+	}
+	if err != nil {
+		return Manifest{}, fmt.Errorf("error reading %s: %w", manifestPath, err)
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return Manifest{}, fmt.Errorf("error decoding %s: %w", manifestPath, err)
+	}
+	return manifest, nil
+}
+
+// hasChecksum reports whether the file at path exists with the SHA-256 checksum.
+func hasChecksum(path, checksum string) bool {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]) == checksum
+}
+
+// extension returns the extension of the image file, taken from the path of its URL,
+// or from its content type when the path has none, e.g. ".png".
+func extension(imageUrl, contentType string) string {
+	if parsed, err := url.Parse(imageUrl); err == nil {
+		if ext := strings.ToLower(path.Ext(parsed.Path)); ext != "" && len(ext) <= 5 {
+			return ext
+		}
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	// The extensions of the mime package for these depend on the system
+	case "image/jpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	return ""
+}
@@ -0,0 +1,246 @@
+package images
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+)
+
+// imageServer serves the images of a gallery, counting the requests for each path.
+type imageServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	requests map[string]int
+}
+
+func newImageServer(t *testing.T, images map[string]string) *imageServer {
+	s := &imageServer{requests: map[string]int{}}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		s.requests[r.URL.Path]++
+		s.mu.Unlock()
+		body, ok := images[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *imageServer) count(path string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.requests[path]
+}
+
+// checksumName returns the name an image with content is saved under.
+func checksumName(content, ext string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:16] + ext
+}
+
+func TestDir(t *testing.T) {
+	assert.Equal(t, filepath.Join("out", "skyrim", "skyui 3863.images"), Dir(filepath.Join("out", "skyrim"), "skyui 3863"))
+}
+
+func TestDownload(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	server := newImageServer(t, map[string]string{"/1.png": "first", "/2.JPG": "second", "/copy": "first"})
+	downloader := &Downloader{Client: server.Client(), Concurrency: 2}
+	dir := filepath.Join("out", "skyui 3863.images")
+	urls := []string{server.URL + "/1.png", server.URL + "/2.JPG", server.URL + "/copy"}
+
+	// Act
+	result, err := downloader.Download(context.Background(), dir, urls)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Downloaded)
+	assert.Equal(t, 0, result.Skipped)
+	assert.Equal(t, []Image{
+		{File: checksumName("first", ".png"), SHA256: result.Manifest.Images[0].SHA256, Size: 5, Url: urls[0]},
+		{File: checksumName("second", ".jpg"), SHA256: result.Manifest.Images[1].SHA256, Size: 6, Url: urls[1]},
+		{File: checksumName("first", ".png"), SHA256: result.Manifest.Images[0].SHA256, Size: 5, Url: urls[2]},
+	}, result.Manifest.Images)
+	assert.ElementsMatch(t, []string{filepath.Join(dir, checksumName("first", ".png")), filepath.Join(dir, checksumName("second", ".jpg")), filepath.Join(dir, ManifestFilename)}, result.Paths)
+	data, err := fsys.Default.ReadFile(filepath.Join(dir, checksumName("second", ".jpg")))
+	require.NoError(t, err)
+	assert.Equal(t, "second", string(data))
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Equal(t, result.Manifest.Images, manifest.Images)
+}
+
+func TestDownload_SkipsSavedImages(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	server := newImageServer(t, map[string]string{"/1.png": "first", "/2.png": "second"})
+	downloader := &Downloader{Client: server.Client(), Concurrency: 2}
+	dir := "skyui 3863.images"
+	_, err := downloader.Download(context.Background(), dir, []string{server.URL + "/1.png", server.URL + "/2.png"})
+	require.NoError(t, err)
+	// A damaged image is fetched again
+	require.NoError(t, fsys.Default.WriteFile(filepath.Join(dir, checksumName("second", ".png")), []byte("truncated"), 0644))
+
+	// Act
+	result, err := downloader.Download(context.Background(), dir, []string{server.URL + "/1.png", server.URL + "/2.png"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Downloaded)
+	assert.Equal(t, 1, result.Skipped)
+	assert.Equal(t, 1, server.count("/1.png"))
+	assert.Equal(t, 2, server.count("/2.png"))
+	assert.Equal(t, []string{filepath.Join(dir, checksumName("second", ".png")), filepath.Join(dir, ManifestFilename)}, result.Paths)
+	assert.Len(t, result.Manifest.Images, 2)
+}
+
+func TestDownload_FailedImage(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	server := newImageServer(t, map[string]string{"/1.png": "first"})
+	downloader := &Downloader{Client: server.Client(), Concurrency: 2}
+	dir := "skyui 3863.images"
+
+	// Act
+	result, err := downloader.Download(context.Background(), dir, []string{server.URL + "/missing.png", server.URL + "/1.png"})
+
+	// Assert
+	assert.EqualError(t, err, "error downloading "+server.URL+"/missing.png: server returned status 404")
+	assert.Equal(t, 1, result.Downloaded)
+	require.Len(t, result.Manifest.Images, 1)
+	assert.Equal(t, server.URL+"/1.png", result.Manifest.Images[0].Url)
+	manifest, err := LoadManifest(dir)
+	require.NoError(t, err)
+	assert.Len(t, manifest.Images, 1)
+}
+
+func TestDownload_RateLimited(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	server := newImageServer(t, map[string]string{"/1.png": "first", "/2.png": "second", "/3.png": "third"})
+	downloader := &Downloader{Client: server.Client(), Concurrency: 3}
+	ctx := httpclient.WithLimiter(context.Background(), httpclient.NewLimiter(20*time.Millisecond))
+	start := time.Now()
+
+	// Act
+	_, err := downloader.Download(ctx, "images", []string{server.URL + "/1.png", server.URL + "/2.png", server.URL + "/3.png"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestDownload_TooLarge(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(make([]byte, maxImageSize+1))
+	}))
+	defer server.Close()
+	downloader := &Downloader{Client: server.Client()}
+
+	// Act
+	result, err := downloader.Download(context.Background(), "images", []string{server.URL + "/huge.png"})
+
+	// Assert
+	assert.EqualError(t, err, "error downloading "+server.URL+"/huge.png: image larger than 64 MB")
+	assert.Zero(t, result.Downloaded)
+}
+
+func TestDownload_NoImages(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	downloader := &Downloader{Client: http.DefaultClient}
+
+	// Act
+	result, err := downloader.Download(context.Background(), "images", nil)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, Result{}, result)
+	_, err = fsys.Default.Stat("images")
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestLoadManifest_Invalid(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, fsys.Default.MkdirAll("images", 0755))
+	require.NoError(t, fsys.Default.WriteFile(filepath.Join("images", ManifestFilename), []byte("{"), 0644))
+
+	// Act
+	_, err := LoadManifest("images")
+
+	// Assert
+	assert.ErrorContains(t, err, "error decoding "+filepath.Join("images", ManifestFilename))
+}
+
+func TestExtension(t *testing.T) {
+	tests := []struct {
+		url, contentType, expected string
+	}{
+		{"https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-1.PNG", "", ".png"},
+		{"https://example.com/image?id=4", "image/jpeg; charset=binary", ".jpg"},
+		{"https://example.com/image", "image/webp", ".webp"},
+		{"https://example.com/image", "application/octet-stream", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			assert.Equal(t, tt.expected, extension(tt.url, tt.contentType))
+		})
+	}
+}
+
+func TestGallery_Wrap(t *testing.T) {
+	// Arrange
+	pages := map[string]string{
+		"https://www.nexusmods.com/skyrim/mods/3863":           `<div id="sidebargallery"><ul><li class="thumb" data-src="https://staticdelivery.nexusmods.com/3863-1.png"></li></ul></div>`,
+		"https://www.nexusmods.com/skyrim/mods/3863?tab=files": `<div id="sidebargallery"><ul><li class="thumb" data-src="https://staticdelivery.nexusmods.com/other.png"></li></ul></div>`,
+	}
+	fetchDocument := func(ctx context.Context, targetURL string) (*goquery.Document, error) {
+		return goquery.NewDocumentFromReader(strings.NewReader(pages[targetURL]))
+	}
+	gallery := &Gallery{}
+	fetch := gallery.Wrap(fetchDocument)
+
+	// Act
+	_, err := fetch(context.Background(), "https://www.nexusmods.com/skyrim/mods/3863")
+	require.NoError(t, err)
+	_, err = fetch(context.Background(), "https://www.nexusmods.com/skyrim/mods/3863?tab=files")
+	require.NoError(t, err)
+
+	// Assert
+	assert.Equal(t, []string{"https://staticdelivery.nexusmods.com/3863-1.png"}, gallery.URLs())
+}
+
+func TestDownloaderFrom(t *testing.T) {
+	// Arrange
+	downloader := &Downloader{}
+
+	// Act & Assert
+	assert.Nil(t, DownloaderFrom(context.Background()))
+	assert.Same(t, downloader, DownloaderFrom(WithDownloader(context.Background(), downloader)))
+}
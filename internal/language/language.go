@@ -0,0 +1,176 @@
+package language
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// Undetermined is the ISO 639 code used for text whose language can't be detected,
+// such as version numbers or very short notes.
+const Undetermined = "und"
+
+// scripts maps the non-Latin scripts to the language they most likely indicate.
+var scripts = []struct {
+	code  string
+	table *unicode.RangeTable
+}{
+	{"ja", unicode.Hiragana},
+	{"ja", unicode.Katakana},
+	{"ko", unicode.Hangul},
+	{"zh", unicode.Han},
+	{"ru", unicode.Cyrillic},
+	{"el", unicode.Greek},
+	{"ar", unicode.Arabic},
+	{"he", unicode.Hebrew},
+	{"th", unicode.Thai},
+}
+
+// stopwords are common short words, along with words typical of changelogs, that
+// tell apart the languages written in the Latin script.
+var stopwords = map[string][]string{
+	"de": {"der", "die", "das", "und", "ist", "nicht", "mit", "für", "auf", "wurde", "wurden", "jetzt", "ein", "eine", "den", "dem", "von", "zu", "behoben", "hinzugefügt", "entfernt", "sich", "auch", "bei", "neue"},
+	"en": {"the", "and", "to", "of", "is", "in", "for", "with", "now", "fixed", "added", "removed", "updated", "this", "that", "it", "on", "when", "was", "not", "be", "are", "from", "should", "new", "some"},
+	"es": {"el", "los", "las", "y", "es", "una", "para", "con", "por", "que", "del", "ahora", "añadido", "corregido", "eliminado", "se", "al", "nuevo", "nueva"},
+	"fr": {"le", "la", "les", "et", "est", "des", "une", "pour", "avec", "dans", "du", "pas", "ajouté", "corrigé", "supprimé", "sur", "qui", "au", "nouveau", "nouvelle"},
+	"it": {"il", "lo", "gli", "è", "di", "un", "per", "che", "non", "della", "aggiunto", "corretto", "rimosso", "ora", "nuovo", "nuova", "sono"},
+	"nl": {"het", "en", "een", "van", "voor", "met", "niet", "toegevoegd", "opgelost", "verwijderd", "nu", "op", "nieuwe", "ook"},
+	"pl": {"i", "w", "z", "na", "nie", "jest", "się", "oraz", "dodano", "naprawiono", "usunięto", "że", "teraz", "nowy", "nowe"},
+	"pt": {"o", "os", "um", "uma", "com", "não", "do", "da", "agora", "adicionado", "corrigido", "removido", "foi", "são", "novo", "nova"},
+}
+
+// stopwordLanguages maps each stopword to the languages it belongs to.
+var stopwordLanguages = func() map[string][]string {
+	index := map[string][]string{}
+	for code, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], code)
+		}
+	}
+	return index
+}()
+
+// Detect returns the ISO 639-1 code of the language the text is most likely written
+// in, or Undetermined. Text mostly written in a non-Latin script is attributed to the
+// language of that script; Latin text is attributed to the language whose common words
+// it uses the most, among English, German, French, Spanish, Portuguese, Italian, Dutch
+// and Polish. Text using none of them, or as many of two languages, is undetermined.
+func Detect(text string) string {
+	if code := detectScript(text); code != "" {
+		return code
+	}
+	return detectLatin(text)
+}
+
+// detectScript returns the language of the non-Latin script most of the letters of the
+// text are written in, or an empty string.
+func detectScript(text string) string {
+	letters := 0
+	counts := map[string]int{}
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range scripts {
+			if unicode.Is(script.table, r) {
+				counts[script.code]++
+				break
+			}
+		}
+	}
+
+	best, bestCount := "", 0
+	for _, script := range scripts {
+		if count := counts[script.code]; count > bestCount {
+			best, bestCount = script.code, count
+		}
+	}
+	// Japanese mixes kana with Han characters, so any kana outweighs Chinese
+	if best == "zh" && counts["ja"] > 0 {
+		best = "ja"
+	}
+	if bestCount*2 <= letters {
+		return ""
+	}
+	if best == "ru" && strings.ContainsAny(strings.ToLower(text), "іїєґ") {
+		return "uk"
+	}
+	return best
+}
+
+// detectLatin scores the words of the text against the stopwords of each language and
+// returns the language with the highest score, or Undetermined when there is no single
+// best match.
+func detectLatin(text string) string {
+	scores := map[string]int{}
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		for _, code := range stopwordLanguages[word] {
+			scores[code]++
+		}
+	}
+
+	best, bestScore, tied := Undetermined, 0, false
+	for code, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = code, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if tied {
+		return Undetermined
+	}
+	return best
+}
+
+// FilterChangeLogs returns the changelogs keeping only the notes detected as written in
+// lang, along with notes whose language can't be detected, such as bare version numbers.
+// Versions left without notes are kept so the version history stays complete.
+func FilterChangeLogs(changeLogs []types.ChangeLog, lang string) []types.ChangeLog {
+	lang = strings.ToLower(lang)
+	filtered := make([]types.ChangeLog, 0, len(changeLogs))
+	for _, changeLog := range changeLogs {
+		kept := types.ChangeLog{Version: changeLog.Version}
+		for i, note := range changeLog.Notes {
+			code := noteLanguage(changeLog, i)
+			if code != lang && code != Undetermined {
+				continue
+			}
+			kept.Notes = append(kept.Notes, note)
+			if changeLog.NoteLanguages != nil {
+				kept.NoteLanguages = append(kept.NoteLanguages, code)
+			}
+		}
+		filtered = append(filtered, kept)
+	}
+	return filtered
+}
+
+// AnnotateChangeLogs returns the changelogs with the detected language of each note
+// recorded in NoteLanguages, in the same order as the notes.
+func AnnotateChangeLogs(changeLogs []types.ChangeLog) []types.ChangeLog {
+	annotated := make([]types.ChangeLog, 0, len(changeLogs))
+	for _, changeLog := range changeLogs {
+		changeLog.NoteLanguages = make([]string, len(changeLog.Notes))
+		for i, note := range changeLog.Notes {
+			changeLog.NoteLanguages[i] = Detect(note)
+		}
+		annotated = append(annotated, changeLog)
+	}
+	return annotated
+}
+
+// noteLanguage returns the language of the i-th note of the changelog, reusing its
+// annotation when there is one.
+func noteLanguage(changeLog types.ChangeLog, i int) string {
+	if i < len(changeLog.NoteLanguages) {
+		return changeLog.NoteLanguages[i]
+	}
+	return Detect(changeLog.Notes[i])
+}
@@ -0,0 +1,64 @@
+package language
+
+import (
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Fixed a crash when the player opens the map", "en"},
+		{"Absturz beim Öffnen der Karte behoben und die Texturen wurden verbessert", "de"},
+		{"Correction du plantage lors de l'ouverture de la carte et ajouté des textures", "fr"},
+		{"Corregido el error al abrir el mapa y añadido soporte para la nueva versión", "es"},
+		{"Исправлен вылет при открытии карты", "ru"},
+		{"Виправлено збій під час відкриття карти", "uk"},
+		{"マップを開くとクラッシュする問題を修正", "ja"},
+		{"修复打开地图时崩溃的问题", "zh"},
+		{"지도를 열 때 충돌 문제 수정", "ko"},
+		{"v1.2.3", Undetermined},
+		{"", Undetermined},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			assert.Equal(t, tt.want, Detect(tt.text))
+		})
+	}
+}
+
+func TestFilterChangeLogs(t *testing.T) {
+	// Arrange
+	changeLogs := []types.ChangeLog{
+		{Version: "1.1", Notes: []string{"Fixed the map crash", "Absturz der Karte behoben", "1.1"}},
+		{Version: "1.0", Notes: []string{"Исправлен вылет"}},
+	}
+
+	// Act
+	filtered := FilterChangeLogs(changeLogs, "EN")
+
+	// Assert
+	assert.Equal(t, []types.ChangeLog{
+		{Version: "1.1", Notes: []string{"Fixed the map crash", "1.1"}},
+		{Version: "1.0"},
+	}, filtered)
+}
+
+func TestAnnotateChangeLogs(t *testing.T) {
+	// Arrange
+	changeLogs := []types.ChangeLog{{Version: "1.1", Notes: []string{"Added new armor to the game", "Neue Rüstung hinzugefügt", "1.1"}}}
+
+	// Act
+	annotated := AnnotateChangeLogs(changeLogs)
+	filtered := FilterChangeLogs(annotated, "de")
+
+	// Assert
+	assert.Equal(t, []string{"en", "de", Undetermined}, annotated[0].NoteLanguages)
+	assert.Nil(t, changeLogs[0].NoteLanguages)
+	assert.Equal(t, []types.ChangeLog{{Version: "1.1", Notes: []string{"Neue Rüstung hinzugefügt", "1.1"}, NoteLanguages: []string{"de", Undetermined}}}, filtered)
+}
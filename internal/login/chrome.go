@@ -0,0 +1,91 @@
+package login
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+// chromePage is a Page driving a headless Chromium through the DevTools protocol.
+type chromePage struct {
+	ctx    context.Context
+	cancel func()
+}
+
+// NewChromePage starts a headless Chromium, found on the PATH or at its usual install
+// locations, or at execPath when given, and returns a Page driving a tab of it. The
+// browser is closed by Close or once ctx is done. Returns an error if it can't start.
+func NewChromePage(ctx context.Context, execPath string) (Page, error) {
+	opts := chromedp.DefaultExecAllocatorOptions[:]
+	if execPath != "" {
+		opts = append(opts, chromedp.ExecPath(execPath))
+	}
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(ctx, opts...)
+	tabCtx, cancelTab := chromedp.NewContext(allocCtx)
+
+	// Running no action starts the browser, reporting a missing Chromium right away
+	if err := chromedp.Run(tabCtx); err != nil {
+		cancelTab()
+		cancelAlloc()
+		return nil, err
+	}
+	return &chromePage{ctx: tabCtx, cancel: func() { cancelTab(); cancelAlloc() }}, nil
+}
+
+// Navigate loads the URL in the tab.
+func (p *chromePage) Navigate(url string) error {
+	return chromedp.Run(p.ctx, chromedp.Navigate(url))
+}
+
+// Fill types the value in the field matching the selector, waiting for it to show.
+func (p *chromePage) Fill(selector, value string) error {
+	return chromedp.Run(p.ctx, chromedp.SendKeys(selector, value, chromedp.ByQuery))
+}
+
+// Click clicks the element matching the selector, waiting for it to show.
+func (p *chromePage) Click(selector string) error {
+	return chromedp.Run(p.ctx, chromedp.Click(selector, chromedp.ByQuery))
+}
+
+// Text returns the text of the first element matching the selector and whether there
+// is one, without waiting.
+func (p *chromePage) Text(selector string) (string, bool, error) {
+	quoted, err := json.Marshal(selector)
+	if err != nil {
+		return "", false, err
+	}
+	var result struct {
+		Found bool   `json:"found"`
+		Text  string `json:"text"`
+	}
+	script := `(() => { const e = document.querySelector(` + string(quoted) + `); return e ? {found: true, text: e.innerText} : {found: false, text: ""}; })()`
+	if err := chromedp.Run(p.ctx, chromedp.Evaluate(script, &result)); err != nil {
+		return "", false, err
+	}
+	return result.Text, result.Found, nil
+}
+
+// Cookies returns the cookies the browser holds, for every site.
+func (p *chromePage) Cookies() ([]*http.Cookie, error) {
+	var cookies []*http.Cookie
+	err := chromedp.Run(p.ctx, chromedp.ActionFunc(func(ctx context.Context) error {
+		browserCookies, err := storage.GetCookies().Do(ctx)
+		if err != nil {
+			return err
+		}
+		for _, cookie := range browserCookies {
+			cookies = append(cookies, &http.Cookie{Name: cookie.Name, Value: cookie.Value, Domain: cookie.Domain, Path: cookie.Path})
+		}
+		return nil
+	}))
+	return cookies, err
+}
+
+// Close closes the tab and the browser behind it.
+func (p *chromePage) Close() error {
+	p.cancel()
+	return nil
+}
@@ -0,0 +1,186 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultUrl is the sign in page of Nexus Mods.
+const DefaultUrl = "https://users.nexusmods.com/auth/sign_in"
+
+// DefaultForm holds the selectors of the Nexus Mods sign in form.
+var DefaultForm = Form{
+	Code:       `input[name="user[otp_attempt]"]`,
+	CodeSubmit: `form:has(input[name="user[otp_attempt]"]) [type="submit"]`,
+	Error:      `.alert-danger, .flash-error, #error_explanation`,
+	Password:   `input[name="user[password]"]`,
+	Submit:     `form:has(input[name="user[password]"]) [type="submit"]`,
+	Username:   `input[name="user[login]"]`,
+}
+
+// ErrRejected is returned when the site rejects the credentials or the two-factor code.
+var ErrRejected = errors.New("sign in rejected")
+
+// Form holds the CSS selectors of the sign in form: the username and password fields
+// and their submit button, the two-factor code field and its submit button shown for
+// accounts with two-factor authentication, and the message shown when the sign in is
+// rejected.
+type Form struct {
+	Code       string
+	CodeSubmit string
+	Error      string
+	Password   string
+	Submit     string
+	Username   string
+}
+
+// Page is a browser tab the sign in form is filled in, e.g. a headless Chromium
+// driven by NewChromePage.
+type Page interface {
+	// Navigate loads the URL in the tab.
+	Navigate(url string) error
+	// Fill types the value in the field matching the selector, waiting for it to show.
+	Fill(selector, value string) error
+	// Click clicks the element matching the selector, waiting for it to show.
+	Click(selector string) error
+	// Text returns the text of the first element matching the selector and whether
+	// there is one, without waiting.
+	Text(selector string) (string, bool, error)
+	// Cookies returns the cookies the browser holds.
+	Cookies() ([]*http.Cookie, error)
+	// Close closes the tab and the browser behind it.
+	Close() error
+}
+
+// Prompt asks the user for the value labelled label, with the typed characters hidden
+// when secret is true.
+type Prompt func(label string, secret bool) (string, error)
+
+// Options configures a sign in.
+type Options struct {
+	// CookieNames are the session cookies the sign in has to yield.
+	CookieNames []string
+	// Domain is the site the session cookies are set for, e.g. nexusmods.com.
+	Domain string
+	// Form holds the selectors of the sign in form, DefaultForm when empty.
+	Form Form
+	// PollInterval is the time between two checks of the outcome of the sign in,
+	// 500ms when zero.
+	PollInterval time.Duration
+	// Url is the sign in page, DefaultUrl when empty.
+	Url string
+}
+
+// Login signs in on the page with the username and password prompted for, then with
+// the two-factor code if the site asks for one, and returns the session cookies the
+// browser was given. Returns an error wrapping ErrRejected if the site rejects the
+// credentials or the code, or an error if the page can't be driven or ctx is done
+// before the session cookies are set.
+func Login(ctx context.Context, page Page, prompt Prompt, opts Options) (map[string]string, error) {
+	opts = opts.withDefaults()
+
+	username, err := prompt("Username or email", false)
+	if err != nil {
+		return nil, err
+	}
+	password, err := prompt("Password", true)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := page.Navigate(opts.Url); err != nil {
+		return nil, fmt.Errorf("error loading the sign in page: %w", err)
+	}
+	if err := page.Fill(opts.Form.Username, username); err != nil {
+		return nil, fmt.Errorf("error filling in the username: %w", err)
+	}
+	if err := page.Fill(opts.Form.Password, password); err != nil {
+		return nil, fmt.Errorf("error filling in the password: %w", err)
+	}
+	if err := page.Click(opts.Form.Submit); err != nil {
+		return nil, fmt.Errorf("error submitting the sign in form: %w", err)
+	}
+
+	codeSent := false
+	for {
+		cookies, err := page.Cookies()
+		if err != nil {
+			return nil, fmt.Errorf("error reading the browser cookies: %w", err)
+		}
+		if session := sessionCookies(cookies, opts.Domain, opts.CookieNames); session != nil {
+			return session, nil
+		}
+
+		if message, ok, err := page.Text(opts.Form.Error); err != nil {
+			return nil, err
+		} else if ok && strings.TrimSpace(message) != "" {
+			return nil, fmt.Errorf("%w: %s", ErrRejected, strings.TrimSpace(message))
+		}
+
+		if !codeSent {
+			if _, ok, err := page.Text(opts.Form.Code); err != nil {
+				return nil, err
+			} else if ok {
+				code, err := prompt("Two-factor code", false)
+				if err != nil {
+					return nil, err
+				}
+				if err := page.Fill(opts.Form.Code, code); err != nil {
+					return nil, fmt.Errorf("error filling in the two-factor code: %w", err)
+				}
+				if err := page.Click(opts.Form.CodeSubmit); err != nil {
+					return nil, fmt.Errorf("error submitting the two-factor code: %w", err)
+				}
+				codeSent = true
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("no session cookies set after signing in: %w", ctx.Err())
+		case <-time.After(opts.PollInterval):
+		}
+	}
+}
+
+// withDefaults returns the options with the defaults filled in for the empty ones.
+func (o Options) withDefaults() Options {
+	if o.Form == (Form{}) {
+		o.Form = DefaultForm
+	}
+	if o.PollInterval <= 0 {
+		o.PollInterval = 500 * time.Millisecond
+	}
+	if o.Url == "" {
+		o.Url = DefaultUrl
+	}
+	return o
+}
+
+// sessionCookies returns the named cookies set for the domain or its subdomains, or nil
+// unless every one of them is set.
+func sessionCookies(cookies []*http.Cookie, domain string, names []string) map[string]string {
+	domain = strings.TrimPrefix(strings.ToLower(domain), ".")
+	found := map[string]string{}
+	for _, cookie := range cookies {
+		host := strings.TrimPrefix(strings.ToLower(cookie.Domain), ".")
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		found[cookie.Name] = cookie.Value
+	}
+
+	session := make(map[string]string, len(names))
+	for _, name := range names {
+		value, ok := found[name]
+		if !ok || value == "" {
+			return nil
+		}
+		session[name] = value
+	}
+	return session
+}
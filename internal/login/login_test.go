@@ -0,0 +1,149 @@
+package login
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakePage is a Page playing a sign in form: the session cookies are set once the
+// expected password, and the expected code when one is set, are submitted.
+type fakePage struct {
+	code      string
+	filled    map[string]string
+	navigated string
+	password  string
+	rejected  bool
+	submitted bool
+}
+
+func (p *fakePage) Navigate(url string) error {
+	p.navigated = url
+	return nil
+}
+
+func (p *fakePage) Fill(selector, value string) error {
+	if p.filled == nil {
+		p.filled = map[string]string{}
+	}
+	p.filled[selector] = value
+	return nil
+}
+
+func (p *fakePage) Click(selector string) error {
+	switch selector {
+	case DefaultForm.Submit:
+		p.rejected = p.filled[DefaultForm.Password] != p.password
+		p.submitted = !p.rejected && p.code == ""
+	case DefaultForm.CodeSubmit:
+		p.rejected = p.filled[DefaultForm.Code] != p.code
+		p.submitted = !p.rejected
+	}
+	return nil
+}
+
+func (p *fakePage) Text(selector string) (string, bool, error) {
+	switch selector {
+	case DefaultForm.Error:
+		if p.rejected {
+			return "Invalid login or password.", true, nil
+		}
+	case DefaultForm.Code:
+		if p.code != "" && !p.submitted && !p.rejected {
+			return "", true, nil
+		}
+	}
+	return "", false, nil
+}
+
+func (p *fakePage) Cookies() ([]*http.Cookie, error) {
+	cookies := []*http.Cookie{{Name: "tracking", Value: "x", Domain: ".example.org"}}
+	if p.submitted {
+		cookies = append(cookies,
+			&http.Cookie{Name: "nexusmods_session", Value: "abc", Domain: ".nexusmods.com"},
+			&http.Cookie{Name: "nexusmods_session_refresh", Value: "def", Domain: "users.nexusmods.com"})
+	}
+	return cookies, nil
+}
+
+func (p *fakePage) Close() error {
+	return nil
+}
+
+// answers returns a Prompt answering each label from the map, recording the labels
+// asked for.
+func answers(values map[string]string, asked *[]string) Prompt {
+	return func(label string, secret bool) (string, error) {
+		*asked = append(*asked, label)
+		return values[label], nil
+	}
+}
+
+func TestLogin(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		password  string
+		wantAsked []string
+		wantErr   error
+	}{
+		{name: "password", password: "hunter2", wantAsked: []string{"Username or email", "Password"}},
+		{name: "two-factor code", password: "hunter2", code: "123456", wantAsked: []string{"Username or email", "Password", "Two-factor code"}},
+		{name: "wrong password", password: "secret", wantAsked: []string{"Username or email", "Password"}, wantErr: ErrRejected},
+		{name: "wrong code", password: "hunter2", code: "654321", wantAsked: []string{"Username or email", "Password", "Two-factor code"}, wantErr: ErrRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			page := &fakePage{password: tt.password, code: tt.code}
+			var asked []string
+			prompt := answers(map[string]string{"Username or email": "me", "Password": "hunter2", "Two-factor code": "123456"}, &asked)
+			opts := Options{CookieNames: []string{"nexusmods_session", "nexusmods_session_refresh"}, Domain: "nexusmods.com", PollInterval: time.Millisecond}
+
+			// Act
+			cookies, err := Login(context.Background(), page, prompt, opts)
+
+			// Assert
+			assert.Equal(t, tt.wantAsked, asked)
+			assert.Equal(t, DefaultUrl, page.navigated)
+			assert.Equal(t, "me", page.filled[DefaultForm.Username])
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Contains(t, err.Error(), "Invalid login or password.")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, map[string]string{"nexusmods_session": "abc", "nexusmods_session_refresh": "def"}, cookies)
+		})
+	}
+}
+
+func TestLogin_PromptError(t *testing.T) {
+	prompt := func(label string, secret bool) (string, error) {
+		return "", errors.New("EOF")
+	}
+
+	_, err := Login(context.Background(), &fakePage{}, prompt, Options{})
+
+	assert.EqualError(t, err, "EOF")
+}
+
+func TestLogin_Timeout(t *testing.T) {
+	// Arrange: the submitted form never sets the session cookies
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	var asked []string
+	prompt := answers(map[string]string{"Password": "hunter2"}, &asked)
+
+	// Act
+	_, err := Login(ctx, &fakePage{password: "hunter2"}, prompt, Options{CookieNames: []string{"missing"}, Domain: "nexusmods.com", PollInterval: time.Millisecond})
+
+	// Assert
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,290 @@
+package notifiers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// digestTopChanges is the number of updates listed individually in a digest, picked
+// by download count.
+const digestTopChanges = 5
+
+// Update describes a mod found to have changed since it was last recorded, along with
+// the Nexus requirements it added or removed, which can break load orders silently.
+type Update struct {
+	AddedRequirements   []string `json:"addedRequirements,omitempty"`
+	Game                string   `json:"game"`
+	ModID               int64    `json:"modId"`
+	Name                string   `json:"name,omitempty"`
+	PreviousVersion     string   `json:"previousVersion,omitempty"`
+	RemovedRequirements []string `json:"removedRequirements,omitempty"`
+	TotalDLs            string   `json:"totalDownloads,omitempty"`
+	Url                 string   `json:"url,omitempty"`
+	Version             string   `json:"version,omitempty"`
+}
+
+// RequirementsChanged reports whether the mod added or removed Nexus requirements.
+func (u Update) RequirementsChanged() bool {
+	return len(u.AddedRequirements) > 0 || len(u.RemovedRequirements) > 0
+}
+
+// Message is the JSON payload posted to a webhook. The summary is sent as both "text"
+// and "content" so that Slack and Discord style webhooks display it, and the updates
+// it covers are included for consumers that process the payload, along with the ID of
+// the run that found them.
+type Message struct {
+	Content string   `json:"content"`
+	RunID   string   `json:"runId,omitempty"`
+	Text    string   `json:"text"`
+	Updates []Update `json:"updates"`
+}
+
+// Notifier delivers a message, e.g. by posting it to a webhook.
+type Notifier interface {
+	Notify(ctx context.Context, message Message) error
+}
+
+// Webhook is a Notifier that posts messages as JSON to a URL.
+type Webhook struct {
+	Client *http.Client
+	Url    string
+}
+
+// NewWebhook returns a Webhook posting to url with a 10 second timeout.
+func NewWebhook(url string) Webhook {
+	return Webhook{Client: &http.Client{Timeout: 10 * time.Second}, Url: url}
+}
+
+// Notify posts the message to the webhook URL. Returns an error if the request fails
+// or the webhook doesn't answer with a 2xx status.
+func (w Webhook) Notify(ctx context.Context, message Message) error {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("error formatting notification: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.Url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error sending notification: webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Dispatch sends the updates found by the run through the notifier, either one message
+// per updated mod or, when digest is set, a single summarized message. Nothing is sent
+// when there are no updates. Returns the first error encountered; remaining messages
+// are still sent.
+func Dispatch(ctx context.Context, notifier Notifier, runID string, updates []Update, digest bool) error {
+	if len(updates) == 0 {
+		return nil
+	}
+
+	if digest {
+		message := FormatDigest(updates)
+		message.RunID = runID
+		return notifier.Notify(ctx, message)
+	}
+
+	var firstErr error
+	for _, update := range updates {
+		message := FormatUpdate(update)
+		message.RunID = runID
+		if err := notifier.Notify(ctx, message); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// Batch collects the updates found by several runs, e.g. the games of a watch pass, so
+// that they are dispatched together rather than by each run.
+type Batch struct {
+	mu      sync.Mutex
+	updates []Update
+}
+
+// batchKey is the context key of the Batch of the runs.
+type batchKey struct{}
+
+// WithBatch returns a copy of ctx carrying batch, which the runs made with ctx add their
+// updates to instead of dispatching them.
+func WithBatch(ctx context.Context, batch *Batch) context.Context {
+	return context.WithValue(ctx, batchKey{}, batch)
+}
+
+// BatchFrom returns the Batch carried by ctx, or nil when there is none.
+func BatchFrom(ctx context.Context) *Batch {
+	batch, _ := ctx.Value(batchKey{}).(*Batch)
+	return batch
+}
+
+// Add appends updates to the batch.
+func (b *Batch) Add(updates ...Update) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.updates = append(b.updates, updates...)
+}
+
+// Updates returns the updates added to the batch, in the order they were added.
+func (b *Batch) Updates() []Update {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Update(nil), b.updates...)
+}
+
+// FormatUpdate builds the message announcing a single updated mod.
+func FormatUpdate(update Update) Message {
+	return newMessage(describeUpdate(update), []Update{update})
+}
+
+// FormatDigest builds a single message summarizing all the updates, with the number of
+// updated mods per game and the most downloaded updated mods listed individually. Every
+// mod whose requirements changed is listed as well, whatever its downloads.
+func FormatDigest(updates []Update) Message {
+	perGame := map[string]int{}
+	for _, update := range updates {
+		perGame[update.Game]++
+	}
+	games := make([]string, 0, len(perGame))
+	for game := range perGame {
+		games = append(games, game)
+	}
+	sort.Strings(games)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d %s updated", len(updates), plural(len(updates), "mod", "mods"))
+	counts := make([]string, 0, len(games))
+	for _, game := range games {
+		counts = append(counts, fmt.Sprintf("%s: %d", game, perGame[game]))
+	}
+	fmt.Fprintf(&b, " (%s)", strings.Join(counts, ", "))
+
+	top := append([]Update(nil), updates...)
+	sort.SliceStable(top, func(i, j int) bool {
+		return downloads(top[i]) > downloads(top[j])
+	})
+	if len(top) > digestTopChanges {
+		top = top[:digestTopChanges]
+	}
+
+	b.WriteString("\nTop changes:")
+	for _, update := range top {
+		fmt.Fprintf(&b, "\n- %s", describeUpdate(update))
+	}
+	if remaining := len(updates) - len(top); remaining > 0 {
+		fmt.Fprintf(&b, "\n…and %d more", remaining)
+	}
+
+	var changed []Update
+	for _, update := range updates {
+		if update.RequirementsChanged() {
+			changed = append(changed, update)
+		}
+	}
+	if len(changed) > 0 {
+		b.WriteString("\nRequirement changes:")
+		for _, update := range changed {
+			fmt.Fprintf(&b, "\n- %s", describeRequirements(update))
+		}
+	}
+
+	return newMessage(b.String(), updates)
+}
+
+// describeUpdate returns a one line description of an update, e.g.
+// "SkyUI (skyrim/3863) updated 5.1 → 5.2", followed by its requirement changes.
+func describeUpdate(update Update) string {
+	if update.RequirementsChanged() && update.PreviousVersion == update.Version {
+		return describeRequirements(update)
+	}
+
+	description := fmt.Sprintf("%s updated", modLabel(update))
+	switch {
+	case update.PreviousVersion != "" && update.Version != "":
+		description += fmt.Sprintf(" %s → %s", update.PreviousVersion, update.Version)
+	case update.Version != "":
+		description += fmt.Sprintf(" to %s", update.Version)
+	}
+	if update.RequirementsChanged() {
+		description += ", " + requirementChanges(update)
+	}
+	if update.Url != "" {
+		description += " " + update.Url
+	}
+	return description
+}
+
+// describeRequirements returns a one line description of the requirement changes of
+// an update, e.g. "SkyUI (skyrim/3863) now requires SKSE64".
+func describeRequirements(update Update) string {
+	description := fmt.Sprintf("%s %s", modLabel(update), requirementChanges(update))
+	if update.Url != "" {
+		description += " " + update.Url
+	}
+	return description
+}
+
+// requirementChanges lists the requirements an update added and removed, e.g.
+// "now requires SKSE64 and no longer requires SkyUI".
+func requirementChanges(update Update) string {
+	var changes []string
+	if len(update.AddedRequirements) > 0 {
+		changes = append(changes, "now requires "+strings.Join(update.AddedRequirements, ", "))
+	}
+	if len(update.RemovedRequirements) > 0 {
+		changes = append(changes, "no longer requires "+strings.Join(update.RemovedRequirements, ", "))
+	}
+	return strings.Join(changes, " and ")
+}
+
+// modLabel returns the name of the updated mod with its game and ID, e.g.
+// "SkyUI (skyrim/3863)".
+func modLabel(update Update) string {
+	name := update.Name
+	if name == "" {
+		name = fmt.Sprintf("Mod %d", update.ModID)
+	}
+	return fmt.Sprintf("%s (%s/%d)", name, update.Game, update.ModID)
+}
+
+// newMessage builds a Message carrying the summary in both text fields.
+func newMessage(summary string, updates []Update) Message {
+	return Message{Content: summary, Text: summary, Updates: updates}
+}
+
+// downloads returns the total downloads of an update as a number, or zero if unknown.
+func downloads(update Update) int64 {
+	count, err := formatters.ParseCount(update.TotalDLs)
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// plural returns singular when n is 1 and plural otherwise.
+func plural(n int, singular, plural string) string {
+	if n == 1 {
+		return singular
+	}
+	return plural
+}
@@ -0,0 +1,191 @@
+package notifiers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingNotifier records the messages it is asked to send.
+type recordingNotifier struct {
+	err      error
+	messages []Message
+}
+
+func (r *recordingNotifier) Notify(_ context.Context, message Message) error {
+	r.messages = append(r.messages, message)
+	return r.err
+}
+
+func TestWebhookNotify(t *testing.T) {
+	// Arrange
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+	update := Update{Game: "skyrim", ModID: 3863, Name: "SkyUI", PreviousVersion: "5.1", Version: "5.2"}
+
+	// Act
+	err := NewWebhook(server.URL).Notify(context.Background(), FormatUpdate(update))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "SkyUI (skyrim/3863) updated 5.1 → 5.2", received.Text)
+	assert.Equal(t, received.Text, received.Content)
+	assert.Equal(t, []Update{update}, received.Updates)
+}
+
+func TestWebhookNotify_ErrorStatus(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	// Act
+	err := NewWebhook(server.URL).Notify(context.Background(), Message{})
+
+	// Assert
+	assert.EqualError(t, err, "error sending notification: webhook returned status 400")
+}
+
+func TestDispatch(t *testing.T) {
+	// Arrange
+	updates := []Update{
+		{Game: "skyrim", ModID: 1, Version: "1.1"},
+		{Game: "skyrim", ModID: 2, Version: "2.1"},
+	}
+
+	tests := []struct {
+		name         string
+		digest       bool
+		wantMessages int
+	}{
+		{name: "one message per update", digest: false, wantMessages: 2},
+		{name: "digest", digest: true, wantMessages: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &recordingNotifier{}
+
+			// Act
+			err := Dispatch(context.Background(), notifier, "run-1", updates, tt.digest)
+
+			// Assert
+			assert.NoError(t, err)
+			assert.Len(t, notifier.messages, tt.wantMessages)
+			for _, message := range notifier.messages {
+				assert.Equal(t, "run-1", message.RunID)
+			}
+		})
+	}
+}
+
+func TestDispatch_NoUpdates(t *testing.T) {
+	// Arrange
+	notifier := &recordingNotifier{}
+
+	// Act
+	err := Dispatch(context.Background(), notifier, "run-1", nil, true)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, notifier.messages)
+}
+
+func TestDispatch_ContinuesAfterError(t *testing.T) {
+	// Arrange
+	notifier := &recordingNotifier{err: errors.New("boom")}
+	updates := []Update{{Game: "skyrim", ModID: 1}, {Game: "skyrim", ModID: 2}}
+
+	// Act
+	err := Dispatch(context.Background(), notifier, "run-1", updates, false)
+
+	// Assert
+	assert.EqualError(t, err, "boom")
+	assert.Len(t, notifier.messages, 2)
+}
+
+func TestFormatDigest(t *testing.T) {
+	// Arrange
+	var updates []Update
+	for i := 1; i <= 6; i++ {
+		updates = append(updates, Update{
+			Game:     "skyrim",
+			ModID:    int64(i),
+			Name:     fmt.Sprintf("Mod %d", i),
+			TotalDLs: fmt.Sprintf("%d,000", i),
+			Version:  "2.0",
+		})
+	}
+	updates = append(updates, Update{Game: "fallout4", ModID: 7, Name: "Other", TotalDLs: "10"})
+
+	// Act
+	message := FormatDigest(updates)
+
+	// Assert
+	expected := "7 mods updated (fallout4: 1, skyrim: 6)\n" +
+		"Top changes:\n" +
+		"- Mod 6 (skyrim/6) updated to 2.0\n" +
+		"- Mod 5 (skyrim/5) updated to 2.0\n" +
+		"- Mod 4 (skyrim/4) updated to 2.0\n" +
+		"- Mod 3 (skyrim/3) updated to 2.0\n" +
+		"- Mod 2 (skyrim/2) updated to 2.0\n" +
+		"…and 2 more"
+	assert.Equal(t, expected, message.Text)
+	assert.Len(t, message.Updates, 7)
+}
+
+func TestFormatUpdate_RequirementChanges(t *testing.T) {
+	updated := Update{Game: "skyrim", ModID: 3863, Name: "SkyUI", PreviousVersion: "5.1", Version: "5.2", AddedRequirements: []string{"SKSE64"}}
+	unchanged := Update{Game: "skyrim", ModID: 3863, Name: "SkyUI", PreviousVersion: "5.2", Version: "5.2", RemovedRequirements: []string{"Address Library"}}
+
+	assert.Equal(t, "SkyUI (skyrim/3863) updated 5.1 → 5.2, now requires SKSE64", FormatUpdate(updated).Text)
+	assert.Equal(t, "SkyUI (skyrim/3863) no longer requires Address Library", FormatUpdate(unchanged).Text)
+}
+
+func TestFormatDigest_RequirementChanges(t *testing.T) {
+	// Arrange
+	updates := []Update{
+		{Game: "skyrim", ModID: 1, Name: "Popular", TotalDLs: "1,000", Version: "2.0"},
+		{Game: "skyrim", ModID: 2, Name: "Patch", TotalDLs: "10", Version: "1.0", PreviousVersion: "1.0", AddedRequirements: []string{"SKSE64", "SkyUI"}},
+	}
+
+	// Act
+	message := FormatDigest(updates)
+
+	// Assert
+	expected := "2 mods updated (skyrim: 2)\n" +
+		"Top changes:\n" +
+		"- Popular (skyrim/1) updated to 2.0\n" +
+		"- Patch (skyrim/2) now requires SKSE64, SkyUI\n" +
+		"Requirement changes:\n" +
+		"- Patch (skyrim/2) now requires SKSE64, SkyUI"
+	assert.Equal(t, expected, message.Text)
+}
+
+func TestBatch(t *testing.T) {
+	// Arrange
+	batch := &Batch{}
+	ctx := WithBatch(context.Background(), batch)
+
+	// Act
+	BatchFrom(ctx).Add(Update{Game: "skyrim", ModID: 1})
+	BatchFrom(ctx).Add(Update{Game: "fallout4", ModID: 2})
+
+	// Assert
+	assert.Nil(t, BatchFrom(context.Background()))
+	assert.Equal(t, []Update{{Game: "skyrim", ModID: 1}, {Game: "fallout4", ModID: 2}}, batch.Updates())
+}
@@ -0,0 +1,145 @@
+package profile
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+)
+
+// Default is the name the default profile, whose files are kept in the data storage
+// directory itself, is listed under. It can't be created or deleted.
+const Default = "default"
+
+// namePattern is the form of a profile name, which names its directory.
+var namePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// ErrNotFound is returned for a profile that hasn't been created.
+var ErrNotFound = errors.New("profile not found")
+
+// ValidateName returns an error if name can't name a profile: it must be lowercase
+// letters, digits, - and _, starting with a letter or digit, and not be Default.
+func ValidateName(name string) error {
+	if name == Default {
+		return fmt.Errorf("%s is the name of the default profile", Default)
+	}
+	if !namePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name %q: only lowercase letters, digits, - and _ are allowed", name)
+	}
+	return nil
+}
+
+// List returns the names of the created profiles, sorted, not including the default
+// one. A missing profiles directory means no profile was created.
+func List() ([]string, error) {
+	entries, err := fsys.Default.ReadDir(storage.ProfilesPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return []string{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading profiles: %w", err)
+	}
+
+	names := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && namePattern.MatchString(entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Exists reports whether the named profile was created. The default profile always
+// exists.
+func Exists(name string) bool {
+	if name == "" || name == Default {
+		return true
+	}
+	info, err := fsys.Default.Stat(storage.ProfilePath(name))
+	return err == nil && info.IsDir()
+}
+
+// Create creates the directory of the named profile. Returns an error if the name is
+// invalid, the profile already exists or its directory can't be created.
+func Create(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if Exists(name) {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+	if err := fsys.Default.MkdirAll(storage.ProfilePath(name), 0755); err != nil {
+		return fmt.Errorf("error creating profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// Files returns the number of files the named profile holds, e.g. its cookies,
+// configuration and saved results.
+func Files(name string) (int, error) {
+	return countFiles(storage.ProfilePath(name))
+}
+
+// Delete removes the named profile along with every file it holds. Returns ErrNotFound
+// if it doesn't exist, or an error if the name is invalid or a file can't be removed.
+func Delete(name string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if !Exists(name) {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if err := removeTree(storage.ProfilePath(name)); err != nil {
+		return fmt.Errorf("error deleting profile %s: %w", name, err)
+	}
+	return nil
+}
+
+// countFiles returns the number of files in the directory tree at path.
+func countFiles(path string) (int, error) {
+	entries, err := fsys.Default.ReadDir(path)
+	if err != nil {
+		return 0, err
+	}
+	count := 0
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			count++
+			continue
+		}
+		n, err := countFiles(filepath.Join(path, entry.Name()))
+		if err != nil {
+			return 0, err
+		}
+		count += n
+	}
+	return count, nil
+}
+
+// removeTree removes the directory at path along with everything it holds, depth
+// first since the filesystem only removes empty directories.
+func removeTree(path string) error {
+	entries, err := fsys.Default.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		child := filepath.Join(path, entry.Name())
+		if entry.IsDir() {
+			if err := removeTree(child); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := fsys.Default.Remove(child); err != nil {
+			return err
+		}
+	}
+	return fsys.Default.Remove(path)
+}
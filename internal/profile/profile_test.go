@@ -0,0 +1,76 @@
+package profile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateListDelete(t *testing.T) {
+	// Arrange
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	// Act
+	require.NoError(t, Create("personal"))
+	require.NoError(t, Create("archival"))
+	createErr := Create("archival")
+	require.NoError(t, os.WriteFile(filepath.Join(storage.ProfilePath("archival"), "session-cookies.json"), []byte("{}"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(storage.ProfilePath("archival"), "skyrim"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(storage.ProfilePath("archival"), "skyrim", "skyui 3863.json"), []byte("{}"), 0644))
+	listed, listErr := List()
+	files, filesErr := Files("archival")
+	deleteErr := Delete("archival")
+	remaining, _ := List()
+
+	// Assert
+	assert.EqualError(t, createErr, "profile archival already exists")
+	require.NoError(t, listErr)
+	assert.Equal(t, []string{"archival", "personal"}, listed)
+	require.NoError(t, filesErr)
+	assert.Equal(t, 2, files)
+	require.NoError(t, deleteErr)
+	assert.Equal(t, []string{"personal"}, remaining)
+	assert.NoDirExists(t, storage.ProfilePath("archival"))
+	assert.ErrorIs(t, Delete("archival"), ErrNotFound)
+}
+
+func TestList_NoProfiles(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("USERPROFILE", os.Getenv("HOME"))
+
+	names, err := List()
+
+	require.NoError(t, err)
+	assert.Empty(t, names)
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name string
+		err  string
+	}{
+		{name: "archival"},
+		{name: "work_2"},
+		{name: "default", err: "default is the name of the default profile"},
+		{name: "../data", err: `invalid profile name "../data": only lowercase letters, digits, - and _ are allowed`},
+		{name: "Work", err: `invalid profile name "Work": only lowercase letters, digits, - and _ are allowed`},
+		{name: "", err: `invalid profile name "": only lowercase letters, digits, - and _ are allowed`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateName(tt.name)
+
+			if tt.err == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package progress
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+)
+
+// Events written as the scrape of a batch of mods goes on.
+const (
+	// EventModDone is written once a mod is done with, whatever the outcome.
+	EventModDone = "mod_done"
+	// EventModStart is written when a mod starts being scraped.
+	EventModStart = "mod_start"
+	// EventRunDone is written when every mod of the run is done with.
+	EventRunDone = "run_done"
+	// EventRunStart is written when a run starts, with the number of mods requested.
+	EventRunStart = "run_start"
+)
+
+// Outcomes of a mod, given in the mod_done events.
+const (
+	OutcomeFailed      = "failed"
+	OutcomeIgnored     = "ignored"
+	OutcomeInterrupted = "interrupted"
+	OutcomeSkipped     = "skipped"
+	OutcomeSucceeded   = "succeeded"
+	OutcomeUnavailable = "unavailable"
+	OutcomeUnchanged   = "unchanged"
+)
+
+// Event is a progress event, written as a single JSON line. Fields that don't apply
+// to the event are left out.
+type Event struct {
+	Event     string `json:"event"`
+	ID        int64  `json:"id,omitempty"`
+	ElapsedMs int64  `json:"elapsed_ms"`
+	Error     string `json:"error,omitempty"`
+	Failed    int    `json:"failed,omitempty"`
+	Game      string `json:"game,omitempty"`
+	Outcome   string `json:"outcome,omitempty"`
+	RunID     string `json:"run_id,omitempty"`
+	Succeeded int    `json:"succeeded,omitempty"`
+	Total     int    `json:"total,omitempty"`
+}
+
+var (
+	// mu serializes the writes so that concurrent events don't interleave.
+	mu sync.Mutex
+	// output receives the events, none are written when it is nil.
+	output io.Writer
+)
+
+// SetOutput makes the events be written to w, e.g. stderr as set with the
+// --progress-json flag. A nil w turns progress events off, which is the default.
+func SetOutput(w io.Writer) {
+	mu.Lock()
+	defer mu.Unlock()
+	output = w
+}
+
+// Enabled reports whether progress events are written.
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return output != nil
+}
+
+// Emit writes the event as a JSON line, with the time elapsed since started, when
+// progress events are enabled. A zero started leaves the elapsed time at zero. Failing
+// to write the event is ignored, progress being informational.
+func Emit(event Event, started time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	if output == nil {
+		return
+	}
+
+	if !started.IsZero() {
+		event.ElapsedMs = time.Since(started).Milliseconds()
+	}
+	data, err := json.Marshal(event)
+	if err != nil {
+		return
+	}
+	output.Write(append(data, '\n'))
+}
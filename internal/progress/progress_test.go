@@ -0,0 +1,35 @@
+package progress
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmit(t *testing.T) {
+	// Arrange
+	var out bytes.Buffer
+	SetOutput(&out)
+	defer SetOutput(nil)
+
+	// Act
+	Emit(Event{Event: EventRunStart, RunID: "run-1", Total: 2}, time.Time{})
+	Emit(Event{Event: EventModDone, ID: 3863, Outcome: OutcomeSucceeded}, time.Now().Add(-time.Second))
+
+	// Assert
+	assert.True(t, Enabled())
+	lines := bytes.Split(bytes.TrimSpace(out.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{"event":"run_start","elapsed_ms":0,"run_id":"run-1","total":2}`, string(lines[0]))
+	assert.Regexp(t, `^\{"event":"mod_done","id":3863,"elapsed_ms":1\d{3},"outcome":"succeeded"\}$`, string(lines[1]))
+}
+
+func TestEmit_Disabled(t *testing.T) {
+	SetOutput(nil)
+
+	Emit(Event{Event: EventRunDone}, time.Time{})
+
+	assert.False(t, Enabled())
+}
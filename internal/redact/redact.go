@@ -0,0 +1,156 @@
+package redact
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// Placeholder replaces the usernames found in the text of a redacted mod.
+const Placeholder = "[redacted]"
+
+// profilePaths are the paths Nexus Mods serves member profiles under.
+var profilePaths = []string{"/users/", "/profile/"}
+
+// Mod returns the mod stripped of the personal details of the people behind it, for
+// results meant to be published: the creator and uploader are removed, along with any
+// other mention of their usernames in the description, changelogs, permissions and
+// file descriptions, and the links to member profiles are dropped from the
+// requirements. Usernames are only replaced as whole words, e.g. "Thanks to Arthmoor"
+// becomes "Thanks to [redacted]" while "Arthmoorish" is left alone.
+func Mod(mod types.ModInfo) types.ModInfo {
+	names := usernames(mod.Creator, mod.Uploader)
+	mod.Creator = ""
+	mod.Uploader = ""
+
+	mod.Description = names.replace(mod.Description)
+	mod.ShortDescription = names.replace(mod.ShortDescription)
+
+	changeLogs := make([]types.ChangeLog, 0, len(mod.ChangeLogs))
+	for _, changeLog := range mod.ChangeLogs {
+		notes := make([]string, 0, len(changeLog.Notes))
+		for _, note := range changeLog.Notes {
+			notes = append(notes, names.replace(note))
+		}
+		changeLog.Notes = notes
+		changeLogs = append(changeLogs, changeLog)
+	}
+	if mod.ChangeLogs != nil {
+		mod.ChangeLogs = changeLogs
+	}
+
+	if mod.Permissions != nil {
+		permissions := *mod.Permissions
+		permissions.AuthorNotes = names.replace(permissions.AuthorNotes)
+		permissions.Credits = names.replace(permissions.Credits)
+		permissions.DonationPoints = names.replace(permissions.DonationPoints)
+		rules := make([]types.Permission, 0, len(permissions.Rules))
+		for _, rule := range permissions.Rules {
+			rule.Description = names.replace(rule.Description)
+			rules = append(rules, rule)
+		}
+		if permissions.Rules != nil {
+			permissions.Rules = rules
+		}
+		mod.Permissions = &permissions
+	}
+
+	if mod.Files != nil {
+		files := make([]types.File, 0, len(mod.Files))
+		for _, file := range mod.Files {
+			file.Description = names.replace(file.Description)
+			files = append(files, file)
+		}
+		mod.Files = files
+	}
+
+	mod.Dependencies = requirements(mod.Dependencies, names)
+	mod.OffSiteRequirements = requirements(mod.OffSiteRequirements, names)
+	mod.DLCRequirements = requirements(mod.DLCRequirements, names)
+	mod.ModsUsing = requirements(mod.ModsUsing, names)
+	return mod
+}
+
+// IsProfileUrl reports whether the link points to a member profile of Nexus Mods,
+// either absolute or relative to the site.
+func IsProfileUrl(link string) bool {
+	u, err := url.Parse(strings.TrimSpace(link))
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	if host != "" && host != "nexusmods.com" && !strings.HasSuffix(host, ".nexusmods.com") {
+		return false
+	}
+	for _, path := range profilePaths {
+		if strings.HasPrefix(strings.ToLower(u.Path), path) {
+			return true
+		}
+	}
+	return false
+}
+
+// requirements returns the requirements with the usernames replaced in their notes,
+// the profile links dropped from their notes links, and the link of those pointing to
+// a profile removed.
+func requirements(list []types.Requirement, names usernamePattern) []types.Requirement {
+	if list == nil {
+		return nil
+	}
+	redacted := make([]types.Requirement, 0, len(list))
+	for _, requirement := range list {
+		requirement.Notes = names.replace(requirement.Notes)
+		if IsProfileUrl(requirement.Url) {
+			requirement.Url = ""
+		}
+		if requirement.NotesLinks != nil {
+			links := make([]types.Link, 0, len(requirement.NotesLinks))
+			for _, link := range requirement.NotesLinks {
+				if !IsProfileUrl(link.Url) {
+					link.Text = names.replace(link.Text)
+					links = append(links, link)
+				}
+			}
+			requirement.NotesLinks = links
+		}
+		redacted = append(redacted, requirement)
+	}
+	return redacted
+}
+
+// usernamePattern matches the usernames to redact as whole words, or nothing when
+// there are none.
+type usernamePattern struct {
+	re *regexp.Regexp
+}
+
+// usernames returns the pattern matching the non-empty names given.
+func usernames(names ...string) usernamePattern {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			quoted = append(quoted, regexp.QuoteMeta(name))
+		}
+	}
+	if len(quoted) == 0 {
+		return usernamePattern{}
+	}
+	// Go regexps have no lookarounds, so the surrounding characters are captured and
+	// written back
+	return usernamePattern{re: regexp.MustCompile(`(^|[^\pL\pN_])(?:` + strings.Join(quoted, "|") + `)([^\pL\pN_]|$)`)}
+}
+
+// replace returns the text with each username replaced by the Placeholder.
+func (p usernamePattern) replace(text string) string {
+	if p.re == nil || text == "" {
+		return text
+	}
+	// The character between two adjacent names is consumed by the first match, so a
+	// second pass replaces the names it left behind
+	for i := 0; i < 2; i++ {
+		text = p.re.ReplaceAllString(text, "${1}"+Placeholder+"${2}")
+	}
+	return text
+}
@@ -0,0 +1,74 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMod(t *testing.T) {
+	// Arrange
+	mod := types.ModInfo{
+		ChangeLogs:  []types.ChangeLog{{Version: "1.1", Notes: []string{"Patch by EssArrBee", "Fixed the EssArrBeeFix plugin"}}},
+		Creator:     "schlangster",
+		Description: "SkyUI by schlangster, ported by EssArrBee.",
+		Files:       []types.File{{Name: "SkyUI", Description: "Ask schlangster/EssArrBee for help"}},
+		Dependencies: []types.Requirement{{
+			Name:  "SKSE64",
+			Notes: "Thanks schlangster",
+			NotesLinks: []types.Link{
+				{Text: "profile", Url: "https://www.nexusmods.com/users/123"},
+				{Text: "SKSE64 2.0", Url: "https://skse.silverlock.org"},
+			},
+			Url: "https://www.nexusmods.com/skyrimspecialedition/mods/30379",
+		}},
+		OffSiteRequirements: []types.Requirement{{Name: "Author page", Url: "/profile/schlangster"}},
+		ModID:               3863,
+		Name:                "SkyUI",
+		Permissions:         &types.Permissions{Credits: "schlangster and the SKSE team"},
+		Uploader:            "EssArrBee",
+	}
+
+	// Act
+	redacted := Mod(mod)
+
+	// Assert
+	assert.Empty(t, redacted.Creator)
+	assert.Empty(t, redacted.Uploader)
+	assert.Equal(t, "SkyUI by [redacted], ported by [redacted].", redacted.Description)
+	assert.Equal(t, []string{"Patch by [redacted]", "Fixed the EssArrBeeFix plugin"}, redacted.ChangeLogs[0].Notes)
+	assert.Equal(t, "Ask [redacted]/[redacted] for help", redacted.Files[0].Description)
+	assert.Equal(t, "Thanks [redacted]", redacted.Dependencies[0].Notes)
+	assert.Equal(t, []types.Link{{Text: "SKSE64 2.0", Url: "https://skse.silverlock.org"}}, redacted.Dependencies[0].NotesLinks)
+	assert.Equal(t, "https://www.nexusmods.com/skyrimspecialedition/mods/30379", redacted.Dependencies[0].Url)
+	assert.Empty(t, redacted.OffSiteRequirements[0].Url)
+	assert.Equal(t, "[redacted] and the SKSE team", redacted.Permissions.Credits)
+	assert.Equal(t, "SkyUI", redacted.Name)
+	assert.Equal(t, int64(3863), redacted.ModID)
+
+	// The original mod is left untouched
+	assert.Equal(t, "schlangster", mod.Creator)
+	assert.Equal(t, "schlangster and the SKSE team", mod.Permissions.Credits)
+	assert.Len(t, mod.Dependencies[0].NotesLinks, 2)
+}
+
+func TestIsProfileUrl(t *testing.T) {
+	tests := []struct {
+		link     string
+		expected bool
+	}{
+		{"https://www.nexusmods.com/users/123", true},
+		{"https://next.nexusmods.com/profile/schlangster/mods", true},
+		{"/users/123", true},
+		{"https://www.nexusmods.com/skyrim/mods/3863", false},
+		{"https://example.com/users/123", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.link, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsProfileUrl(tt.link))
+		})
+	}
+}
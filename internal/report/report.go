@@ -0,0 +1,195 @@
+package report
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/archive"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+const (
+	// IndexTemplate is the name of the template rendering the index page.
+	IndexTemplate = "index.html.tmpl"
+	// ModTemplate is the name of the template rendering a mod's detail page.
+	ModTemplate = "mod.html.tmpl"
+	// modsDirname is the directory of the report holding the detail pages.
+	modsDirname = "mods"
+)
+
+// defaultTemplates holds the built-in layouts used when no template directory is
+// given or it doesn't override a template.
+//
+//go:embed templates/*.tmpl
+var defaultTemplates embed.FS
+
+// Entry is a saved mod listed in the report, with the values the templates sort and
+// display by.
+type Entry struct {
+	Downloads    int64
+	Endorsements int64
+	Game         string
+	Mod          types.ModInfo
+	Page         string
+	Version      string
+}
+
+// Index is the data the index template is executed with.
+type Index struct {
+	GeneratedAt time.Time
+	Mods        []Entry
+}
+
+// ModPage is the data the mod template is executed with.
+type ModPage struct {
+	Entry
+	GeneratedAt time.Time
+}
+
+// Load reads the saved mods of the output directory dir, sorted by game and name. When
+// a mod was saved several times as snapshots only its most recently checked file is
+// kept. Files that can't be read or decoded are skipped and returned as errors
+// alongside the entries. Returns an error only if the directory can't be read.
+func Load(dir string) ([]Entry, []error, error) {
+	files, err := archive.ModFiles(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		entries []Entry
+		latest  = map[string]int{}
+		skipped []error
+	)
+	for _, file := range files {
+		data, err := fsys.Default.ReadFile(file.Path)
+		if err != nil {
+			skipped = append(skipped, fmt.Errorf("error reading %s: %w", file.Path, err))
+			continue
+		}
+
+		var results types.Results
+		if err := json.Unmarshal(data, &results); err != nil {
+			skipped = append(skipped, fmt.Errorf("error decoding %s: %w", file.Path, err))
+			continue
+		}
+
+		key := fmt.Sprintf("%s/%d", file.Game, results.Mods.ModID)
+		if i, ok := latest[key]; ok {
+			if results.Mods.LastChecked.After(entries[i].Mod.LastChecked) {
+				entries[i] = newEntry(file.Game, results.Mods)
+			}
+			continue
+		}
+		latest[key] = len(entries)
+		entries = append(entries, newEntry(file.Game, results.Mods))
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Game != entries[j].Game {
+			return entries[i].Game < entries[j].Game
+		}
+		return strings.ToLower(entries[i].Mod.Name) < strings.ToLower(entries[j].Mod.Name)
+	})
+	return entries, skipped, nil
+}
+
+// newEntry builds the report entry of a mod saved under game.
+func newEntry(game string, mod types.ModInfo) Entry {
+	version := mod.LatestVersion
+	if version == "" {
+		version = mod.Version
+	}
+
+	entry := Entry{
+		Game:    game,
+		Mod:     mod,
+		Page:    fmt.Sprintf("%s/%s-%d.html", modsDirname, game, mod.ModID),
+		Version: version,
+	}
+	if downloads, err := formatters.ParseCount(mod.TotalDLs); err == nil {
+		entry.Downloads = downloads
+	}
+	if endorsements, err := formatters.ParseCount(mod.Endorsements); err == nil {
+		entry.Endorsements = endorsements
+	}
+	return entry
+}
+
+// Render writes the report of the entries to outputDir: an index.html page listing
+// every mod and a detail page per mod under mods/. Templates found in templateDir
+// replace the built-in ones of the same name. Returns the path of the index page, or
+// an error if a template can't be parsed or executed or a page can't be written.
+func Render(entries []Entry, outputDir, templateDir string, generatedAt time.Time) (string, error) {
+	index, err := loadTemplate(templateDir, IndexTemplate)
+	if err != nil {
+		return "", err
+	}
+	modPage, err := loadTemplate(templateDir, ModTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	if err := fsys.Default.MkdirAll(filepath.Join(outputDir, modsDirname), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	for _, entry := range entries {
+		if err := writePage(modPage, filepath.Join(outputDir, filepath.FromSlash(entry.Page)), ModPage{Entry: entry, GeneratedAt: generatedAt}); err != nil {
+			return "", err
+		}
+	}
+
+	indexPath := filepath.Join(outputDir, "index.html")
+	if err := writePage(index, indexPath, Index{GeneratedAt: generatedAt, Mods: entries}); err != nil {
+		return "", err
+	}
+	return indexPath, nil
+}
+
+// loadTemplate parses the named template from templateDir when it holds one, and from
+// the built-in templates otherwise.
+func loadTemplate(templateDir, name string) (*template.Template, error) {
+	content, err := defaultTemplates.ReadFile("templates/" + name)
+	if err != nil {
+		return nil, err
+	}
+
+	if templateDir != "" {
+		custom, err := fsys.Default.ReadFile(filepath.Join(templateDir, name))
+		switch {
+		case err == nil:
+			content = custom
+		case !os.IsNotExist(err):
+			return nil, fmt.Errorf("error reading template %s: %w", name, err)
+		}
+	}
+
+	tmpl, err := template.New(name).Parse(string(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %s: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+// writePage executes the template with data and writes the result to path.
+func writePage(tmpl *template.Template, path string, data interface{}) error {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return fmt.Errorf("error rendering %s: %w", path, err)
+	}
+	if err := fsys.Default.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
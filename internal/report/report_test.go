@@ -0,0 +1,126 @@
+package report
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var generatedAt = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+// writeFiles creates the files on the Default filesystem, creating their directories.
+func writeFiles(t *testing.T, files map[string]string) {
+	t.Helper()
+	for path, content := range files {
+		require.NoError(t, fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm))
+		require.NoError(t, fsys.Default.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func TestLoad(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	writeFiles(t, map[string]string{
+		filepath.Join("data", "skyrim", "skyui 3863.json"):      `{"Mods":{"ModID":3863,"Name":"SkyUI","TotalDLs":"1.2M","Endorsements":"5,000","LatestVersion":"5.2"}}`,
+		filepath.Join("data", "skyrim", "apocalypse 1090.json"): `{"Mods":{"ModID":1090,"Name":"Apocalypse","Version":"9.45"}}`,
+		filepath.Join("data", "skyrim", "broken 1.json"):        `not json`,
+	})
+
+	// Act
+	entries, skipped, err := Load("data")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, skipped, 1)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "Apocalypse", entries[0].Mod.Name)
+	assert.Equal(t, "9.45", entries[0].Version)
+	assert.Equal(t, "mods/skyrim-1090.html", entries[0].Page)
+	assert.Equal(t, int64(1200000), entries[1].Downloads)
+	assert.Equal(t, int64(5000), entries[1].Endorsements)
+	assert.Equal(t, "5.2", entries[1].Version)
+}
+
+func TestLoad_KeepsLatestSnapshot(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	writeFiles(t, map[string]string{
+		filepath.Join("data", "skyrim", "skyui 3863 2024-06-01T12-00.json"): `{"Mods":{"ModID":3863,"Name":"SkyUI","LatestVersion":"5.2","LastChecked":"2024-06-01T12:00:00Z"}}`,
+		filepath.Join("data", "skyrim", "skyui 3863 2024-07-01T12-00.json"): `{"Mods":{"ModID":3863,"Name":"SkyUI","LatestVersion":"5.3","LastChecked":"2024-07-01T12:00:00Z"}}`,
+		filepath.Join("data", "skyrim", "skyui 3863 2024-05-01T12-00.json"): `{"Mods":{"ModID":3863,"Name":"SkyUI","LatestVersion":"5.1","LastChecked":"2024-05-01T12:00:00Z"}}`,
+	})
+
+	// Act
+	entries, skipped, err := Load("data")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Empty(t, skipped)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "5.3", entries[0].Version)
+}
+
+func TestRender(t *testing.T) {
+	// Arrange
+	memFS := fsys.NewMemFS()
+	defer fsys.Use(memFS)()
+	entries := []Entry{newEntry("skyrim", types.ModInfo{ModID: 3863, Name: "SkyUI <beta>", TotalDLs: "1.2M"})}
+
+	// Act
+	indexPath, err := Render(entries, "report", "", generatedAt)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("report", "index.html"), indexPath)
+	index, err := memFS.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(index), `<a href="mods/skyrim-3863.html">SkyUI &lt;beta&gt;</a>`)
+	assert.Contains(t, string(index), `data-sort="1200000"`)
+
+	page, err := memFS.ReadFile(filepath.Join("report", "mods", "skyrim-3863.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<h1>SkyUI &lt;beta&gt;</h1>")
+}
+
+func TestRender_CustomTemplate(t *testing.T) {
+	// Arrange
+	memFS := fsys.NewMemFS()
+	defer fsys.Use(memFS)()
+	writeFiles(t, map[string]string{
+		filepath.Join("templates", IndexTemplate): `{{range .Mods}}{{.Game}}/{{.Mod.ModID}};{{end}}`,
+	})
+	entries := []Entry{newEntry("skyrim", types.ModInfo{ModID: 1, Name: "One"})}
+
+	// Act
+	indexPath, err := Render(entries, "report", "templates", generatedAt)
+
+	// Assert: the index is overridden while the detail page keeps the built-in layout
+	require.NoError(t, err)
+	index, err := memFS.ReadFile(indexPath)
+	require.NoError(t, err)
+	assert.Equal(t, "skyrim/1;", string(index))
+
+	page, err := memFS.ReadFile(filepath.Join("report", "mods", "skyrim-1.html"))
+	require.NoError(t, err)
+	assert.Contains(t, string(page), "<h1>One</h1>")
+}
+
+func TestRender_InvalidTemplate(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	writeFiles(t, map[string]string{
+		filepath.Join("templates", ModTemplate): `{{.Broken`,
+	})
+
+	// Act
+	_, err := Render(nil, "report", "templates", generatedAt)
+
+	// Assert
+	assert.ErrorContains(t, err, "error parsing template mod.html.tmpl")
+}
@@ -0,0 +1,276 @@
+package selectors
+
+import (
+	"bytes"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/andybalholm/cascadia"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultFilename is the name of the selector override file looked up in the data
+// storage directory when no --selectors flag is given.
+const DefaultFilename = "selectors.yaml"
+
+// defaultSelectors holds the built-in selectors, used for every key an override file
+// doesn't set.
+//
+//go:embed selectors.yaml
+var defaultSelectors []byte
+
+// Selectors holds the CSS selectors the extractors read mod pages with, so that a
+// Nexus Mods redesign can be worked around by overriding them in a file.
+type Selectors struct {
+	FilesTab FilesTab `yaml:"files-tab"`
+	ModPage  ModPage  `yaml:"mod-page"`
+	Next     Next     `yaml:"next"`
+}
+
+// ModPage holds the selectors of the main page of a mod.
+type ModPage struct {
+	Blocks           Blocks       `yaml:"blocks"`
+	Breadcrumb       string       `yaml:"breadcrumb"`
+	ChangeLog        ChangeLog    `yaml:"changelog"`
+	Creator          string       `yaml:"creator"`
+	Description      string       `yaml:"description"`
+	Gallery          string       `yaml:"gallery"`
+	LastUpdated      string       `yaml:"last-updated"`
+	Name             string       `yaml:"name"`
+	OriginalUpload   string       `yaml:"original-upload"`
+	Requirements     Requirements `yaml:"requirements"`
+	ShortDescription string       `yaml:"short-description"`
+	Stats            Stats        `yaml:"stats"`
+	Tags             string       `yaml:"tags"`
+	Translations     Translations `yaml:"translations"`
+	Uploader         string       `yaml:"uploader"`
+	VirusStatus      string       `yaml:"virus-status"`
+}
+
+// Blocks holds the selectors of the titled blocks of the description tab, such as the
+// requirement and translation tables, and their titles in each language the pages can
+// be read in, keyed by language, e.g. "de", as the blocks have nothing else telling
+// them apart.
+type Blocks struct {
+	Block  string                 `yaml:"block"`
+	Title  string                 `yaml:"title"`
+	Titles map[string]BlockTitles `yaml:"titles"`
+}
+
+// BlockTitles holds the titles of the blocks of the description tab in a language. The
+// translations block is matched on the start of its title.
+type BlockTitles struct {
+	DLCRequirements     string `yaml:"dlc-requirements"`
+	ModsRequiring       string `yaml:"mods-requiring"`
+	NexusRequirements   string `yaml:"nexus-requirements"`
+	OffSiteRequirements string `yaml:"off-site-requirements"`
+	Translations        string `yaml:"translations"`
+}
+
+// ChangeLog holds the selectors of the changelog entries, with the version and notes
+// looked up within each entry.
+type ChangeLog struct {
+	Item    string `yaml:"item"`
+	Notes   string `yaml:"notes"`
+	Version string `yaml:"version"`
+}
+
+// Requirements holds the selectors of the rows of a requirement table, with the name
+// and notes cells looked up within each row.
+type Requirements struct {
+	Name  string `yaml:"name"`
+	Notes string `yaml:"notes"`
+	Rows  string `yaml:"rows"`
+}
+
+// Stats holds the selectors of the statistics under the page title, with the title
+// and value looked up within each statistic. The statistics of a mod are told apart by
+// the selectors their items match, whatever the language of the page.
+type Stats struct {
+	Endorsements string `yaml:"endorsements"`
+	Item         string `yaml:"item"`
+	Title        string `yaml:"title"`
+	TotalDLs     string `yaml:"total-dls"`
+	TotalViews   string `yaml:"total-views"`
+	UniqueDLs    string `yaml:"unique-dls"`
+	Value        string `yaml:"value"`
+	Version      string `yaml:"version"`
+}
+
+// Translations holds the selector of the rows of the translations table.
+type Translations struct {
+	Rows string `yaml:"rows"`
+}
+
+// FilesTab holds the selectors of the files tab of a mod, with the file details looked
+// up within each file header.
+type FilesTab struct {
+	Description string `yaml:"description"`
+	File        string `yaml:"file"`
+	FileSize    string `yaml:"file-size"`
+	MD5         string `yaml:"md5"`
+	Name        string `yaml:"name"`
+	TotalDLs    string `yaml:"total-dls"`
+	UniqueDLs   string `yaml:"unique-dls"`
+	UploadDate  string `yaml:"upload-date"`
+	Version     string `yaml:"version"`
+}
+
+// Next holds the selectors of the redesigned next.nexusmods.com frontend, whose
+// elements are tagged with data-e2eid attributes. Detect matches the elements telling
+// its pages apart from the classic ones.
+type Next struct {
+	Detect   string       `yaml:"detect"`
+	FilesTab NextFilesTab `yaml:"files-tab"`
+	ModPage  NextModPage  `yaml:"mod-page"`
+}
+
+// NextModPage holds the selectors of the main page of a mod on the redesigned frontend.
+type NextModPage struct {
+	Breadcrumb       string           `yaml:"breadcrumb"`
+	ChangeLog        ChangeLog        `yaml:"changelog"`
+	Creator          string           `yaml:"creator"`
+	Description      string           `yaml:"description"`
+	LastUpdated      string           `yaml:"last-updated"`
+	Name             string           `yaml:"name"`
+	OriginalUpload   string           `yaml:"original-upload"`
+	Requirements     NextRequirements `yaml:"requirements"`
+	ShortDescription string           `yaml:"short-description"`
+	Stats            NextStats        `yaml:"stats"`
+	Tags             string           `yaml:"tags"`
+	Uploader         string           `yaml:"uploader"`
+	VirusStatus      string           `yaml:"virus-status"`
+}
+
+// NextRequirements holds the selectors of the requirement sections of the redesigned
+// frontend, with the requirements looked up within each section and their name and
+// notes within each requirement.
+type NextRequirements struct {
+	DLC           string `yaml:"dlc"`
+	Item          string `yaml:"item"`
+	ModsRequiring string `yaml:"mods-requiring"`
+	Name          string `yaml:"name"`
+	Nexus         string `yaml:"nexus"`
+	Notes         string `yaml:"notes"`
+	OffSite       string `yaml:"off-site"`
+}
+
+// NextStats holds the selectors of the statistics of the redesigned frontend, with the
+// value looked up within each statistic, the statistic itself being read when it holds
+// none.
+type NextStats struct {
+	Endorsements string `yaml:"endorsements"`
+	TotalDLs     string `yaml:"total-dls"`
+	TotalViews   string `yaml:"total-views"`
+	UniqueDLs    string `yaml:"unique-dls"`
+	Value        string `yaml:"value"`
+	Version      string `yaml:"version"`
+}
+
+// NextFilesTab holds the selectors of the files tab of the redesigned frontend, with the
+// file details looked up within each file tile and its category read from the
+// data-category attribute of the enclosing section.
+type NextFilesTab struct {
+	Description string `yaml:"description"`
+	File        string `yaml:"file"`
+	FileSize    string `yaml:"file-size"`
+	MD5         string `yaml:"md5"`
+	Name        string `yaml:"name"`
+	Section     string `yaml:"section"`
+	TotalDLs    string `yaml:"total-dls"`
+	UniqueDLs   string `yaml:"unique-dls"`
+	UploadDate  string `yaml:"upload-date"`
+	Version     string `yaml:"version"`
+}
+
+// Current is the set of selectors used by the extractors. It holds the defaults
+// unless replaced, e.g. with the selectors loaded from an override file.
+var Current = Default()
+
+// Use replaces Current with selectors and returns a function that restores the
+// previous ones, so tests can write `defer selectors.Use(s)()`.
+func Use(selectors Selectors) func() {
+	previous := Current
+	Current = selectors
+	return func() { Current = previous }
+}
+
+// Default returns the built-in selectors.
+func Default() Selectors {
+	var selectors Selectors
+	if err := decode(defaultSelectors, &selectors); err != nil {
+		panic(fmt.Sprintf("invalid default selectors: %v", err))
+	}
+	return selectors
+}
+
+// Load reads the selector override file at path and returns the default selectors
+// with the keys it sets replaced. Returns an error if the file can't be read, holds
+// unknown keys, or sets an empty or invalid selector.
+func Load(path string) (Selectors, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return Selectors{}, fmt.Errorf("error reading selectors file %s: %w", path, err)
+	}
+
+	selectors := Default()
+	if err := decode(data, &selectors); err != nil {
+		return Selectors{}, fmt.Errorf("error reading selectors file %s: %w", path, err)
+	}
+	if err := selectors.Validate(); err != nil {
+		return Selectors{}, fmt.Errorf("invalid selectors file %s: %w", path, err)
+	}
+	return selectors, nil
+}
+
+// Validate checks that every selector is set and is a valid CSS selector, returning an
+// error naming the first key that isn't.
+func (s Selectors) Validate() error {
+	return validate(reflect.ValueOf(s), "")
+}
+
+// decode unmarshals YAML data over selectors, rejecting unknown keys so that a typo in
+// an override doesn't go unnoticed. An empty document leaves selectors unchanged.
+func decode(data []byte, selectors *Selectors) error {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(selectors); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
+
+// validate walks the fields of value, named after their YAML keys joined with dots
+// under prefix, and checks each selector.
+func validate(value reflect.Value, prefix string) error {
+	for i := 0; i < value.NumField(); i++ {
+		key := value.Type().Field(i).Tag.Get("yaml")
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		field := value.Field(i)
+		if field.Kind() == reflect.Map {
+			// Block titles are text rather than selectors
+			continue
+		}
+		if field.Kind() == reflect.Struct {
+			if err := validate(field, key); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if field.String() == "" {
+			return fmt.Errorf("selector %s is empty", key)
+		}
+		if _, err := cascadia.Compile(field.String()); err != nil {
+			return fmt.Errorf("selector %s is invalid: %w", key, err)
+		}
+	}
+	return nil
+}
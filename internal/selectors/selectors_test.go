@@ -0,0 +1,116 @@
+package selectors
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefault(t *testing.T) {
+	selectors := Default()
+
+	assert.NoError(t, selectors.Validate())
+	assert.Equal(t, "#pagetitle > h1", selectors.ModPage.Name)
+	assert.Equal(t, ".file-expander-header", selectors.FilesTab.File)
+}
+
+func TestDefault_BlockTitlesForEveryLocale(t *testing.T) {
+	titles := Default().ModPage.Blocks.Titles
+
+	for _, lang := range formatters.Locales() {
+		t.Run(lang, func(t *testing.T) {
+			value := reflect.ValueOf(titles[lang])
+			for i := 0; i < value.NumField(); i++ {
+				assert.NotEmpty(t, value.Field(i).String(), "title %s", value.Type().Field(i).Tag.Get("yaml"))
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, fsys.Default.WriteFile("selectors.yaml", []byte(`
+mod-page:
+  name: "#pagetitle h2"
+  stats:
+    value: ".stat-value"
+next:
+  mod-page:
+    name: "[data-e2eid='mod-name']"
+`), 0644))
+
+	// Act
+	selectors, err := Load("selectors.yaml")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "#pagetitle h2", selectors.ModPage.Name)
+	assert.Equal(t, ".stat-value", selectors.ModPage.Stats.Value)
+	assert.Equal(t, Default().ModPage.Stats.Title, selectors.ModPage.Stats.Title)
+	assert.Equal(t, Default().FilesTab, selectors.FilesTab)
+	assert.Equal(t, "[data-e2eid='mod-name']", selectors.Next.ModPage.Name)
+	assert.Equal(t, Default().Next.FilesTab, selectors.Next.FilesTab)
+}
+
+func TestLoad_BlockTitles(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, fsys.Default.WriteFile("selectors.yaml", []byte(`
+mod-page:
+  blocks:
+    titles:
+      de:
+        nexus-requirements: "Nexus-Voraussetzungen"
+`), 0644))
+
+	// Act
+	selectors, err := Load("selectors.yaml")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, BlockTitles{NexusRequirements: "Nexus-Voraussetzungen"}, selectors.ModPage.Blocks.Titles["de"])
+	assert.Equal(t, "Nexus requirements", selectors.ModPage.Blocks.Titles["en"].NexusRequirements, "the English titles are kept")
+	assert.Equal(t, "Übersetzungen", Default().ModPage.Blocks.Titles["de"].Translations, "the defaults are left alone")
+}
+
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"unknown key", "mod-page:\n  title: h1\n", "field title not found"},
+		{"empty selector", "files-tab:\n  version: \"\"\n", "selector files-tab.version is empty"},
+		{"invalid selector", "mod-page:\n  changelog:\n    item: \"div[\"\n", "selector mod-page.changelog.item is invalid"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			defer fsys.Use(fsys.NewMemFS())()
+			require.NoError(t, fsys.Default.WriteFile("selectors.yaml", []byte(tt.content), 0644))
+
+			// Act
+			_, err := Load("selectors.yaml")
+
+			// Assert
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestUse(t *testing.T) {
+	overridden := Default()
+	overridden.ModPage.Name = "h2"
+
+	restore := Use(overridden)
+	assert.Equal(t, "h2", Current.ModPage.Name)
+
+	restore()
+	assert.Equal(t, Default().ModPage.Name, Current.ModPage.Name)
+}
@@ -0,0 +1,290 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+const (
+	// DefaultApiUrl is the GitHub API the releases are looked up in.
+	DefaultApiUrl = "https://api.github.com"
+	// ChecksumsName is the asset of a release listing the SHA-256 checksum of each of
+	// its archives.
+	ChecksumsName = "checksums.txt"
+	// projectName is the name the release archives and binaries start with.
+	projectName = "nexus-mods-scraper"
+	// maxDownloadSize bounds the size of a downloaded asset, well above the size of the
+	// release archives, so that a misbehaving server can't exhaust the memory.
+	maxDownloadSize = 256 << 20
+)
+
+// ErrNotRelease is returned by Newer when the running version isn't a release, e.g. a
+// development build, which can't be compared with the latest release.
+var ErrNotRelease = errors.New("not a release version")
+
+// Release is a published release of the repository.
+type Release struct {
+	Assets  []Asset `json:"assets"`
+	HtmlUrl string  `json:"html_url"`
+	TagName string  `json:"tag_name"`
+}
+
+// Asset is a file attached to a release, such as the archive of a platform.
+type Asset struct {
+	Name string `json:"name"`
+	Url  string `json:"browser_download_url"`
+}
+
+// Asset returns the asset of the release called name, and whether there is one.
+func (r Release) Asset(name string) (Asset, bool) {
+	for _, asset := range r.Assets {
+		if asset.Name == name {
+			return asset, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Updater looks up the releases of a GitHub repository and downloads their assets.
+type Updater struct {
+	ApiUrl string
+	Client *http.Client
+	Owner  string
+	Repo   string
+}
+
+// Latest returns the latest release of the repository. Returns an error if the request
+// fails or GitHub doesn't answer with a release.
+func (u Updater) Latest(ctx context.Context) (Release, error) {
+	releaseUrl := fmt.Sprintf("%s/repos/%s/%s/releases/latest", strings.TrimRight(u.ApiUrl, "/"), u.Owner, u.Repo)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, releaseUrl, nil)
+	if err != nil {
+		return Release{}, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return Release{}, fmt.Errorf("error looking up the latest release: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Release{}, fmt.Errorf("error looking up the latest release: GitHub returned status %d", resp.StatusCode)
+	}
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil || release.TagName == "" {
+		return Release{}, fmt.Errorf("error looking up the latest release: GitHub didn't return a release")
+	}
+	return release, nil
+}
+
+// Download returns the content of the asset. Returns an error if the request fails,
+// the server doesn't answer 200 OK or the asset is larger than expected of a release.
+func (u Updater) Download(ctx context.Context, asset Asset) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.Url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", asset.Name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error downloading %s: server returned status %d", asset.Name, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error downloading %s: %w", asset.Name, err)
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("error downloading %s: larger than %d MB", asset.Name, maxDownloadSize>>20)
+	}
+	return data, nil
+}
+
+// Newer reports whether the latest release is newer than the current version, both
+// given as semantic versions with or without a leading v, e.g. "v1.4.0". Returns
+// ErrNotRelease if the current version isn't one, e.g. "(devel)" for a development
+// build, or an error if the latest one isn't.
+func Newer(current, latest string) (bool, error) {
+	currentVersion, err := semver.NewVersion(current)
+	if err != nil {
+		return false, fmt.Errorf("current version %q: %w", current, ErrNotRelease)
+	}
+	latestVersion, err := semver.NewVersion(latest)
+	if err != nil {
+		return false, fmt.Errorf("invalid release version %q", latest)
+	}
+	return latestVersion.GreaterThan(currentVersion), nil
+}
+
+// ArchiveName returns the name of the release archive of the platform, as named by the
+// release build, e.g. "nexus-mods-scraper_Linux_x86_64.tar.gz" for linux/amd64 or
+// "nexus-mods-scraper_Windows_x86_64.zip" for windows/amd64.
+func ArchiveName(goos, goarch string) string {
+	osName := strings.ToUpper(goos[:1]) + goos[1:]
+	if goos == "darwin" {
+		osName = "MacOs"
+	}
+	if goarch == "amd64" {
+		goarch = "x86_64"
+	}
+	extension := ".tar.gz"
+	if goos == "windows" {
+		extension = ".zip"
+	}
+	return fmt.Sprintf("%s_%s_%s%s", projectName, osName, goarch, extension)
+}
+
+// BinaryName returns the name of the executable in the release archive of the
+// operating system.
+func BinaryName(goos string) string {
+	if goos == "windows" {
+		return projectName + ".exe"
+	}
+	return projectName
+}
+
+// VerifyChecksum checks that the SHA-256 checksum of archive is the one listed for name
+// in checksums, a checksums file of the "<sha256>  <name>" lines of a release. Returns
+// an error if name isn't listed or the checksums differ, e.g. for a corrupted download.
+func VerifyChecksum(archive []byte, name string, checksums []byte) error {
+	sum := sha256.Sum256(archive)
+	actual := hex.EncodeToString(sum[:])
+
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != name {
+			continue
+		}
+		if !strings.EqualFold(fields[0], actual) {
+			return fmt.Errorf("checksum mismatch for %s: expected %s, got %s", name, fields[0], actual)
+		}
+		return nil
+	}
+	return fmt.Errorf("no checksum listed for %s in %s", name, ChecksumsName)
+}
+
+// ExtractBinary returns the content of the file called binary in archive, a .zip or
+// .tar.gz file as told by the name of the archive. Returns an error if the archive
+// can't be read or doesn't hold the binary.
+func ExtractBinary(archive []byte, archiveName, binary string) ([]byte, error) {
+	if strings.HasSuffix(archiveName, ".zip") {
+		return extractZip(archive, archiveName, binary)
+	}
+	return extractTarGz(archive, archiveName, binary)
+}
+
+// extractZip returns the content of the file called binary in the zip archive.
+func extractZip(archive []byte, archiveName, binary string) ([]byte, error) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", archiveName, err)
+	}
+	for _, file := range reader.File {
+		if path.Base(file.Name) != binary || file.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := file.Open()
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", archiveName, err)
+		}
+		defer rc.Close()
+		return readBinary(rc, archiveName)
+	}
+	return nil, fmt.Errorf("%s holds no %s", archiveName, binary)
+}
+
+// extractTarGz returns the content of the file called binary in the gzipped tar archive.
+func extractTarGz(archive []byte, archiveName, binary string) ([]byte, error) {
+	gz, err := gzip.NewReader(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", archiveName, err)
+	}
+	defer gz.Close()
+
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("%s holds no %s", archiveName, binary)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %w", archiveName, err)
+		}
+		if header.Typeflag == tar.TypeReg && path.Base(header.Name) == binary {
+			return readBinary(reader, archiveName)
+		}
+	}
+}
+
+// readBinary reads the binary extracted from the archive, bounded like downloads.
+func readBinary(r io.Reader, archiveName string) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxDownloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", archiveName, err)
+	}
+	if len(data) > maxDownloadSize {
+		return nil, fmt.Errorf("error reading %s: binary larger than %d MB", archiveName, maxDownloadSize>>20)
+	}
+	return data, nil
+}
+
+// Replace replaces the executable at path with binary. The new executable is written
+// next to it first and then renamed over it, so that an interrupted update leaves the
+// previous one in place. Windows doesn't allow replacing a running executable, so it is
+// moved aside as path.old first, to be removed by the next update. Returns an error if
+// the executable can't be written or replaced, e.g. without write access to its
+// directory.
+func Replace(exePath string, binary []byte, goos string) error {
+	newPath := exePath + ".new"
+	if err := fsys.Default.WriteFile(newPath, binary, 0755); err != nil {
+		return fmt.Errorf("error writing the new executable: %w", err)
+	}
+	// WriteFile keeps the mode of an existing file
+	if err := fsys.Default.Chmod(newPath, 0755); err != nil {
+		fsys.Default.Remove(newPath)
+		return fmt.Errorf("error writing the new executable: %w", err)
+	}
+
+	oldPath := exePath + ".old"
+	if goos == "windows" {
+		fsys.Default.Remove(oldPath)
+		if err := fsys.Default.Rename(exePath, oldPath); err != nil {
+			fsys.Default.Remove(newPath)
+			return fmt.Errorf("error replacing the executable: %w", err)
+		}
+	}
+	if err := fsys.Default.Rename(newPath, exePath); err != nil {
+		fsys.Default.Remove(newPath)
+		if goos == "windows" {
+			// Put the previous executable back rather than leave none
+			fsys.Default.Rename(oldPath, exePath)
+		}
+		return fmt.Errorf("error replacing the executable: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+// tarGz returns a gzipped tar archive holding the files.
+func tarGz(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for name, data := range files {
+		require.NoError(t, tw.WriteHeader(&tar.Header{Name: name, Mode: 0755, Size: int64(len(data)), Typeflag: tar.TypeReg}))
+		_, err := tw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, tw.Close())
+	require.NoError(t, gz.Close())
+	return buf.Bytes()
+}
+
+// zipArchive returns a zip archive holding the files.
+func zipArchive(t *testing.T, files map[string][]byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, data := range files {
+		fw, err := zw.Create(name)
+		require.NoError(t, err)
+		_, err = fw.Write(data)
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// checksum returns the hex SHA-256 checksum of data.
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLatest(t *testing.T) {
+	t.Run("release", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/repos/owner/repo/releases/latest", r.URL.Path)
+			w.Write([]byte(`{"tag_name":"v1.2.0","html_url":"https://example.com/v1.2.0","assets":[{"name":"checksums.txt","browser_download_url":"https://example.com/checksums.txt"}]}`))
+		}))
+		defer server.Close()
+		updater := Updater{ApiUrl: server.URL + "/", Client: server.Client(), Owner: "owner", Repo: "repo"}
+
+		// Act
+		release, err := updater.Latest(context.Background())
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "v1.2.0", release.TagName)
+		asset, ok := release.Asset(ChecksumsName)
+		assert.True(t, ok)
+		assert.Equal(t, "https://example.com/checksums.txt", asset.Url)
+		_, ok = release.Asset("missing")
+		assert.False(t, ok)
+	})
+
+	t.Run("status", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer server.Close()
+		updater := Updater{ApiUrl: server.URL, Client: server.Client(), Owner: "owner", Repo: "repo"}
+
+		// Act
+		_, err := updater.Latest(context.Background())
+
+		// Assert
+		assert.EqualError(t, err, "error looking up the latest release: GitHub returned status 404")
+	})
+
+	t.Run("not a release", func(t *testing.T) {
+		// Arrange
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"message":"rate limited"}`))
+		}))
+		defer server.Close()
+		updater := Updater{ApiUrl: server.URL, Client: server.Client(), Owner: "owner", Repo: "repo"}
+
+		// Act
+		_, err := updater.Latest(context.Background())
+
+		// Assert
+		assert.EqualError(t, err, "error looking up the latest release: GitHub didn't return a release")
+	})
+}
+
+func TestDownload(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+	updater := Updater{Client: server.Client()}
+
+	// Act
+	data, err := updater.Download(context.Background(), Asset{Name: "archive", Url: server.URL + "/archive"})
+	_, missingErr := updater.Download(context.Background(), Asset{Name: "missing", Url: server.URL + "/missing"})
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(data))
+	assert.EqualError(t, missingErr, "error downloading missing: server returned status 404")
+}
+
+func TestNewer(t *testing.T) {
+	tests := []struct {
+		name    string
+		current string
+		latest  string
+		want    bool
+		wantErr string
+	}{
+		{"newer", "v1.2.0", "v1.3.0", true, ""},
+		{"same", "v1.3.0", "v1.3.0", false, ""},
+		{"older", "1.4.0", "v1.3.0", false, ""},
+		{"prerelease", "v1.3.0-rc.1", "v1.3.0", true, ""},
+		{"development build", "(devel)", "v1.3.0", false, `current version "(devel)": not a release version`},
+		{"invalid release", "v1.3.0", "nightly", false, `invalid release version "nightly"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got, err := Newer(tt.current, tt.latest)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+
+	t.Run("development build is ErrNotRelease", func(t *testing.T) {
+		// Act
+		_, err := Newer("(devel)", "v1.3.0")
+
+		// Assert
+		assert.True(t, errors.Is(err, ErrNotRelease))
+	})
+}
+
+func TestArchiveName(t *testing.T) {
+	tests := []struct {
+		goos   string
+		goarch string
+		want   string
+	}{
+		{"linux", "amd64", "nexus-mods-scraper_Linux_x86_64.tar.gz"},
+		{"linux", "arm64", "nexus-mods-scraper_Linux_arm64.tar.gz"},
+		{"darwin", "arm64", "nexus-mods-scraper_MacOs_arm64.tar.gz"},
+		{"windows", "amd64", "nexus-mods-scraper_Windows_x86_64.zip"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.goos+"/"+tt.goarch, func(t *testing.T) {
+			// Act & Assert
+			assert.Equal(t, tt.want, ArchiveName(tt.goos, tt.goarch))
+		})
+	}
+}
+
+func TestBinaryName(t *testing.T) {
+	// Act & Assert
+	assert.Equal(t, "nexus-mods-scraper", BinaryName("linux"))
+	assert.Equal(t, "nexus-mods-scraper.exe", BinaryName("windows"))
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	// Arrange
+	archive := []byte("archive")
+	checksums := []byte(checksum([]byte("other")) + "  other.tar.gz\n" + checksum(archive) + "  archive.tar.gz\n")
+
+	// Act
+	okErr := VerifyChecksum(archive, "archive.tar.gz", checksums)
+	mismatchErr := VerifyChecksum([]byte("corrupted"), "archive.tar.gz", checksums)
+	missingErr := VerifyChecksum(archive, "missing.tar.gz", checksums)
+
+	// Assert
+	assert.NoError(t, okErr)
+	assert.ErrorContains(t, mismatchErr, "checksum mismatch for archive.tar.gz")
+	assert.EqualError(t, missingErr, "no checksum listed for missing.tar.gz in checksums.txt")
+}
+
+func TestExtractBinary(t *testing.T) {
+	files := map[string][]byte{"README.md": []byte("readme"), "nexus-mods-scraper": []byte("binary")}
+
+	t.Run("tar.gz", func(t *testing.T) {
+		// Act
+		binary, err := ExtractBinary(tarGz(t, files), "archive.tar.gz", "nexus-mods-scraper")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "binary", string(binary))
+	})
+
+	t.Run("zip", func(t *testing.T) {
+		// Act
+		binary, err := ExtractBinary(zipArchive(t, files), "archive.zip", "nexus-mods-scraper")
+
+		// Assert
+		require.NoError(t, err)
+		assert.Equal(t, "binary", string(binary))
+	})
+
+	t.Run("missing binary", func(t *testing.T) {
+		// Act
+		_, err := ExtractBinary(tarGz(t, files), "archive.tar.gz", "nexus-mods-scraper.exe")
+
+		// Assert
+		assert.EqualError(t, err, "archive.tar.gz holds no nexus-mods-scraper.exe")
+	})
+
+	t.Run("invalid archive", func(t *testing.T) {
+		// Act
+		_, err := ExtractBinary([]byte("not an archive"), "archive.zip", "nexus-mods-scraper")
+
+		// Assert
+		assert.ErrorContains(t, err, "error reading archive.zip")
+	})
+}
+
+func TestReplace(t *testing.T) {
+	t.Run("unix", func(t *testing.T) {
+		// Arrange
+		mem := fsys.NewMemFS()
+		defer fsys.Use(mem)()
+		require.NoError(t, mem.MkdirAll("/bin", 0755))
+		require.NoError(t, mem.WriteFile("/bin/nexus-mods-scraper", []byte("old"), 0755))
+
+		// Act
+		err := Replace("/bin/nexus-mods-scraper", []byte("new"), "linux")
+
+		// Assert
+		require.NoError(t, err)
+		data, _ := mem.ReadFile("/bin/nexus-mods-scraper")
+		assert.Equal(t, "new", string(data))
+		assert.Equal(t, []string{"/bin/nexus-mods-scraper"}, mem.Files())
+	})
+
+	t.Run("windows moves the running executable aside", func(t *testing.T) {
+		// Arrange
+		mem := fsys.NewMemFS()
+		defer fsys.Use(mem)()
+		require.NoError(t, mem.MkdirAll("/bin", 0755))
+		require.NoError(t, mem.WriteFile("/bin/nexus-mods-scraper.exe", []byte("old"), 0755))
+
+		// Act
+		err := Replace("/bin/nexus-mods-scraper.exe", []byte("new"), "windows")
+
+		// Assert
+		require.NoError(t, err)
+		data, _ := mem.ReadFile("/bin/nexus-mods-scraper.exe")
+		assert.Equal(t, "new", string(data))
+		old, _ := mem.ReadFile("/bin/nexus-mods-scraper.exe.old")
+		assert.Equal(t, "old", string(old))
+	})
+
+	t.Run("failed rename keeps the executable", func(t *testing.T) {
+		// Arrange
+		mem := fsys.NewMemFS()
+		defer fsys.Use(mem)()
+		require.NoError(t, mem.MkdirAll("/bin", 0755))
+		require.NoError(t, mem.WriteFile("/bin/nexus-mods-scraper.exe", []byte("old"), 0755))
+		mem.FailOn("Rename", "/bin/nexus-mods-scraper.exe.new", errors.New("access denied"))
+
+		// Act
+		err := Replace("/bin/nexus-mods-scraper.exe", []byte("new"), "windows")
+
+		// Assert
+		assert.EqualError(t, err, "error replacing the executable: access denied")
+		data, _ := mem.ReadFile("/bin/nexus-mods-scraper.exe")
+		assert.Equal(t, "old", string(data))
+		assert.Equal(t, []string{"/bin/nexus-mods-scraper.exe"}, mem.Files())
+	})
+}
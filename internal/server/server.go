@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// DefaultListen is the address the server listens on, only reachable locally.
+const DefaultListen = "127.0.0.1:8766"
+
+// ErrQueueFull is returned by Queue.Do when the queue can't take another job.
+var ErrQueueFull = errors.New("the job queue is full")
+
+// ScrapeFunc scrapes the mod of the game with the given ID.
+type ScrapeFunc func(ctx context.Context, game types.GameSlug, modID types.ModID) (types.Results, error)
+
+// CookieStatusFunc checks whether the session cookies of the server authenticate.
+type CookieStatusFunc func(ctx context.Context) (CookieStatus, error)
+
+// CookieStatus describes the session cookies of the server: the names of the cookies
+// sent with each request, never their values, and whether they authenticate.
+type CookieStatus struct {
+	Cookies []string `json:"cookies"`
+	Valid   bool     `json:"valid"`
+}
+
+// Queue runs jobs one at a time, in the order they were submitted, each job waiting on
+// the limiter so that requests to Nexus Mods are rate limited however often the server
+// is called.
+type Queue struct {
+	jobs    chan job
+	limiter *httpclient.Limiter
+}
+
+// job is a unit of work waiting in the queue, along with the context of the request
+// that submitted it and the channel its result is delivered on.
+type job struct {
+	ctx  context.Context
+	done chan jobResult
+	run  func(ctx context.Context) (interface{}, error)
+}
+
+// jobResult is the outcome of a job.
+type jobResult struct {
+	err   error
+	value interface{}
+}
+
+// NewQueue returns a queue holding up to size waiting jobs and starting each job once
+// limiter lets it through, a nil limiter starting them as soon as the previous one is
+// done. Returns an error if size is lower than 1.
+func NewQueue(size int, limiter *httpclient.Limiter) (*Queue, error) {
+	if size < 1 {
+		return nil, fmt.Errorf("queue size must be at least 1, got %d", size)
+	}
+	return &Queue{jobs: make(chan job, size), limiter: limiter}, nil
+}
+
+// Pending returns the number of jobs waiting in the queue.
+func (q *Queue) Pending() int {
+	return len(q.jobs)
+}
+
+// Do submits run to the queue and waits for its result. Returns ErrQueueFull if the
+// queue is full, or the error of ctx if it is done before the job finished. A job
+// whose request has gone away by the time its turn comes is skipped.
+func (q *Queue) Do(ctx context.Context, run func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+	j := job{ctx: ctx, done: make(chan jobResult, 1), run: run}
+	select {
+	case q.jobs <- j:
+	default:
+		return nil, ErrQueueFull
+	}
+
+	select {
+	case result := <-j.done:
+		return result.value, result.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Run processes the jobs of the queue until ctx is done.
+func (q *Queue) Run(ctx context.Context) {
+	for {
+		var j job
+		select {
+		case <-ctx.Done():
+			return
+		case j = <-q.jobs:
+		}
+
+		// The wait ends as soon as either the queue or the request of the job is done
+		waitCtx, cancel := context.WithCancel(j.ctx)
+		stop := context.AfterFunc(ctx, cancel)
+		_ = q.limiter.Wait(waitCtx)
+		stop()
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+		if err := j.ctx.Err(); err != nil {
+			j.done <- jobResult{err: err}
+			continue
+		}
+
+		value, err := j.run(j.ctx)
+		j.done <- jobResult{err: err, value: value}
+	}
+}
+
+// Handler returns the HTTP API of the server:
+//
+//	GET /scrape/{game}/{modId}  scrape the mod, answered like a saved result
+//	GET /cookies/status         whether the session cookies authenticate
+//
+// Both go through the queue, so they are answered once the jobs ahead of them are
+// done. Responses are JSON. An invalid mod ID is answered with 400 Bad Request, a full
+// queue with 429 Too Many Requests, a request timing out with 504 Gateway Timeout and
+// a failure to scrape with 502 Bad Gateway, along with the kind of failure.
+func Handler(q *Queue, scrape ScrapeFunc, cookieStatus CookieStatusFunc) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /scrape/{game}/{modId}", func(w http.ResponseWriter, r *http.Request) {
+		game, err := types.ParseGameSlug(r.PathValue("game"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+		modID, err := types.ParseModID(r.PathValue("modId"))
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+			return
+		}
+
+		result, err := q.Do(r.Context(), func(ctx context.Context) (interface{}, error) {
+			return scrape(ctx, game, modID)
+		})
+		writeResult(w, q, result, err)
+	})
+
+	mux.HandleFunc("GET /cookies/status", func(w http.ResponseWriter, r *http.Request) {
+		result, err := q.Do(r.Context(), func(ctx context.Context) (interface{}, error) {
+			return cookieStatus(ctx)
+		})
+		writeResult(w, q, result, err)
+	})
+
+	return mux
+}
+
+// writeResult answers the request with the result of its job, or with the status
+// matching the error the job failed with.
+func writeResult(w http.ResponseWriter, q *Queue, result interface{}, err error) {
+	switch {
+	case err == nil:
+		writeJSON(w, http.StatusOK, result)
+	case errors.Is(err, ErrQueueFull):
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter(q)))
+		writeJSON(w, http.StatusTooManyRequests, map[string]string{"error": err.Error()})
+	case errors.Is(err, context.DeadlineExceeded):
+		writeJSON(w, http.StatusGatewayTimeout, map[string]string{"error": err.Error()})
+	default:
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": err.Error(), "kind": failures.Classify(err)})
+	}
+}
+
+// retryAfter returns the number of seconds until the queue is expected to have room
+// again: the time it takes to start the jobs waiting in it, and at least a second.
+func retryAfter(q *Queue) int {
+	seconds := int(math.Ceil(q.limiter.Interval().Seconds() * float64(q.Pending())))
+	return max(seconds, 1)
+}
+
+// writeJSON answers the request with the status and value encoded as JSON.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,142 @@
+package server
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewQueue_Invalid(t *testing.T) {
+	_, err := NewQueue(0, httpclient.NewLimiter(time.Second))
+
+	assert.EqualError(t, err, "queue size must be at least 1, got 0")
+}
+
+func TestQueue_RateLimit(t *testing.T) {
+	// Arrange
+	queue, err := NewQueue(4, httpclient.NewLimiter(50*time.Millisecond))
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx)
+
+	var started []time.Time
+	run := func(ctx context.Context) (interface{}, error) {
+		started = append(started, time.Now())
+		return len(started), nil
+	}
+
+	// Act
+	first, firstErr := queue.Do(context.Background(), run)
+	second, secondErr := queue.Do(context.Background(), run)
+
+	// Assert
+	assert.NoError(t, firstErr)
+	assert.NoError(t, secondErr)
+	assert.Equal(t, 1, first)
+	assert.Equal(t, 2, second)
+	assert.GreaterOrEqual(t, started[1].Sub(started[0]), 50*time.Millisecond)
+}
+
+func TestQueue_Full(t *testing.T) {
+	// Arrange: the queue isn't running, so the first job keeps waiting
+	queue, err := NewQueue(1, nil)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	waiting := make(chan error, 1)
+	go func() {
+		_, err := queue.Do(ctx, func(ctx context.Context) (interface{}, error) { return nil, nil })
+		waiting <- err
+	}()
+	require.Eventually(t, func() bool { return queue.Pending() == 1 }, time.Second, time.Millisecond)
+
+	// Act
+	_, err = queue.Do(context.Background(), func(ctx context.Context) (interface{}, error) { return nil, nil })
+	cancel()
+
+	// Assert
+	assert.ErrorIs(t, err, ErrQueueFull)
+	assert.ErrorIs(t, <-waiting, context.Canceled)
+}
+
+func TestHandler(t *testing.T) {
+	// Arrange
+	queue, err := NewQueue(4, nil)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go queue.Run(ctx)
+
+	scrape := func(ctx context.Context, game types.GameSlug, modID types.ModID) (types.Results, error) {
+		switch modID {
+		case 1:
+			return types.Results{Mods: types.ModInfo{ModID: modID.Int64(), Name: game.String() + " mod"}}, nil
+		case 2:
+			return types.Results{}, fetchers.ErrAdultContent
+		default:
+			return types.Results{}, context.DeadlineExceeded
+		}
+	}
+	cookieStatus := func(ctx context.Context) (CookieStatus, error) {
+		return CookieStatus{Cookies: []string{"nexusmods_session"}, Valid: true}, nil
+	}
+	server := httptest.NewServer(Handler(queue, scrape, cookieStatus))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	// Act
+	scrapeStatus, scrapeBody := get("/scrape/Skyrim/1")
+	invalidStatus, invalidBody := get("/scrape/skyrim/abc")
+	invalidGameStatus, invalidGameBody := get("/scrape/sky.rim/1")
+	adultStatus, adultBody := get("/scrape/skyrim/2")
+	timeoutStatus, _ := get("/scrape/skyrim/3")
+	cookieCode, cookieBody := get("/cookies/status")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, scrapeStatus)
+	assert.Contains(t, scrapeBody, `"ModID":1`)
+	assert.Contains(t, scrapeBody, `"Name":"skyrim mod"`)
+	assert.Equal(t, http.StatusBadRequest, invalidStatus)
+	assert.JSONEq(t, `{"error":"invalid mod id \"abc\""}`, invalidBody)
+	assert.Equal(t, http.StatusBadRequest, invalidGameStatus)
+	assert.JSONEq(t, `{"error":"invalid game \"sky.rim\": only letters, digits, - and _ are allowed"}`, invalidGameBody)
+	assert.Equal(t, http.StatusBadGateway, adultStatus)
+	assert.JSONEq(t, `{"error":"`+fetchers.ErrAdultContent.Error()+`","kind":"auth"}`, adultBody)
+	assert.Equal(t, http.StatusGatewayTimeout, timeoutStatus)
+	assert.Equal(t, http.StatusOK, cookieCode)
+	assert.JSONEq(t, `{"cookies":["nexusmods_session"],"valid":true}`, cookieBody)
+}
+
+func TestWriteResult_QueueFull(t *testing.T) {
+	// Arrange
+	queue, err := NewQueue(2, httpclient.NewLimiter(1500*time.Millisecond))
+	require.NoError(t, err)
+	queue.jobs <- job{}
+	queue.jobs <- job{}
+	recorder := httptest.NewRecorder()
+
+	// Act
+	writeResult(recorder, queue, nil, ErrQueueFull)
+
+	// Assert
+	assert.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	assert.Equal(t, "3", recorder.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"error":"the job queue is full"}`, recorder.Body.String())
+}
@@ -0,0 +1,136 @@
+package share
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultEndpoint is the GitHub API endpoint creating gists, where summaries are
+// shared by default.
+const DefaultEndpoint = "https://api.github.com/gists"
+
+// redacted replaces the secrets removed from shared content.
+const redacted = "[redacted]"
+
+// Uploader publishes a text document and returns the URL it can be read at.
+type Uploader interface {
+	Upload(ctx context.Context, name, content string) (string, error)
+}
+
+// Gist is an Uploader creating secret GitHub gists, authenticated with a token
+// allowed to create gists.
+type Gist struct {
+	Client *http.Client
+	Token  string
+	Url    string
+}
+
+// Paste is an Uploader posting the content as plain text to a paste service that
+// answers with the URL of the paste, either in its body or in a Location header.
+type Paste struct {
+	Client *http.Client
+	Url    string
+}
+
+// New returns the Uploader for endpoint with a 10 second timeout: a Gist when the
+// endpoint creates gists, i.e. its path ends with /gists, and a Paste otherwise.
+func New(endpoint, token string) Uploader {
+	client := &http.Client{Timeout: 10 * time.Second}
+	if parsed, err := url.Parse(endpoint); err == nil && strings.HasSuffix(strings.TrimSuffix(parsed.Path, "/"), "/gists") {
+		return Gist{Client: client, Token: token, Url: endpoint}
+	}
+	return Paste{Client: client, Url: endpoint}
+}
+
+// Upload creates a secret gist holding the content as a file called name. Returns the
+// URL of the gist, or an error if the request fails or GitHub doesn't create it.
+func (g Gist) Upload(ctx context.Context, name, content string) (string, error) {
+	body, err := json.Marshal(map[string]any{
+		"description": name,
+		"files":       map[string]map[string]string{name: {"content": content}},
+		"public":      false,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error formatting gist: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.Url, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Content-Type", "application/json")
+	if g.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+g.Token)
+	}
+
+	resp, err := g.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error sharing summary: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		return "", fmt.Errorf("error sharing summary: gist endpoint returned status %d", resp.StatusCode)
+	}
+
+	var created struct {
+		HtmlUrl string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil || created.HtmlUrl == "" {
+		return "", fmt.Errorf("error sharing summary: gist endpoint didn't return the gist URL")
+	}
+	return created.HtmlUrl, nil
+}
+
+// Upload posts the content to the paste service. The name isn't sent, plain text
+// paste services having no use for it. Returns the URL of the paste, or an error if
+// the request fails or the service doesn't answer with a 2xx status and a URL.
+func (p Paste) Upload(ctx context.Context, name, content string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.Url, strings.NewReader(content))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+
+	resp, err := p.Client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("error sharing summary: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("error sharing summary: paste endpoint returned status %d", resp.StatusCode)
+	}
+
+	if location := resp.Header.Get("Location"); location != "" {
+		return location, nil
+	}
+	answer, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		return "", fmt.Errorf("error sharing summary: %w", err)
+	}
+	pasteUrl := strings.TrimSpace(string(answer))
+	if !strings.HasPrefix(pasteUrl, "http://") && !strings.HasPrefix(pasteUrl, "https://") {
+		return "", fmt.Errorf("error sharing summary: paste endpoint didn't return the paste URL")
+	}
+	return pasteUrl, nil
+}
+
+// Redact returns content with every occurrence of the secrets replaced, so that e.g.
+// session cookies quoted in error messages aren't shared. Empty secrets are ignored.
+func Redact(content string, secrets ...string) string {
+	for _, secret := range secrets {
+		if secret != "" {
+			content = strings.ReplaceAll(content, secret, redacted)
+		}
+	}
+	return content
+}
@@ -0,0 +1,120 @@
+package share
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	assert.IsType(t, Gist{}, New(DefaultEndpoint, "token"))
+	assert.IsType(t, Gist{}, New("https://github.example.com/api/v3/gists/", ""))
+	assert.IsType(t, Paste{}, New("https://paste.example.com", ""))
+}
+
+func TestGistUpload(t *testing.T) {
+	// Arrange
+	var received struct {
+		Description string                       `json:"description"`
+		Files       map[string]map[string]string `json:"files"`
+		Public      bool                         `json:"public"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url": "https://gist.example.com/1"}`)
+	}))
+	defer server.Close()
+
+	// Act
+	sharedUrl, err := Gist{Client: server.Client(), Token: "token", Url: server.URL}.Upload(context.Background(), "summary.txt", "Run scraped 1 of 1 mods")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "https://gist.example.com/1", sharedUrl)
+	assert.Equal(t, "summary.txt", received.Description)
+	assert.Equal(t, map[string]map[string]string{"summary.txt": {"content": "Run scraped 1 of 1 mods"}}, received.Files)
+	assert.False(t, received.Public)
+}
+
+func TestGistUpload_ErrorStatus(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer server.Close()
+
+	// Act
+	_, err := Gist{Client: server.Client(), Url: server.URL}.Upload(context.Background(), "summary.txt", "")
+
+	// Assert
+	assert.EqualError(t, err, "error sharing summary: gist endpoint returned status 401")
+}
+
+func TestPasteUpload(t *testing.T) {
+	tests := []struct {
+		name     string
+		handler  http.HandlerFunc
+		expected string
+		err      string
+	}{
+		{
+			name: "url in body",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				assert.Equal(t, "summary", string(body))
+				io.WriteString(w, "https://paste.example.com/abc\n")
+			},
+			expected: "https://paste.example.com/abc",
+		},
+		{
+			name: "url in location",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Location", "https://paste.example.com/def")
+				w.WriteHeader(http.StatusCreated)
+			},
+			expected: "https://paste.example.com/def",
+		},
+		{
+			name: "no url",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "ok")
+			},
+			err: "error sharing summary: paste endpoint didn't return the paste URL",
+		},
+		{
+			name: "error status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+			},
+			err: "error sharing summary: paste endpoint returned status 502",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			sharedUrl, err := Paste{Client: server.Client(), Url: server.URL}.Upload(context.Background(), "summary.txt", "summary")
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, sharedUrl)
+		})
+	}
+}
+
+func TestRedact(t *testing.T) {
+	assert.Equal(t, "cookie [redacted] and [redacted]", Redact("cookie abc and token", "abc", "", "token"))
+}
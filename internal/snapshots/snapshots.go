@@ -0,0 +1,131 @@
+package snapshots
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+// DefaultDirname is the name of the snapshot store directory in the data directory.
+const DefaultDirname = "snapshots"
+
+// Change kinds reported when comparing author snapshots.
+const (
+	// ChangeNew marks a mod uploaded since the previous snapshot.
+	ChangeNew = "new"
+	// ChangeUpdated marks a mod whose version changed since the previous snapshot.
+	ChangeUpdated = "updated"
+)
+
+// AuthorMod is the state of one of an author's mods at the time of a snapshot.
+type AuthorMod struct {
+	ModID   int64  `json:"ModID"`
+	Name    string `json:"Name,omitempty"`
+	Url     string `json:"Url,omitempty"`
+	Version string `json:"Version,omitempty"`
+}
+
+// AuthorChange describes a new upload or a version bump found by comparing two
+// snapshots of an author's mods.
+type AuthorChange struct {
+	Kind            string `json:"Kind"`
+	ModID           int64  `json:"ModID"`
+	Name            string `json:"Name,omitempty"`
+	PreviousVersion string `json:"PreviousVersion,omitempty"`
+	Url             string `json:"Url,omitempty"`
+	Version         string `json:"Version,omitempty"`
+}
+
+// AuthorSnapshot records the mods an author has uploaded for a game, along with the
+// changes found when the snapshot was taken.
+type AuthorSnapshot struct {
+	Author    string         `json:"Author"`
+	Changes   []AuthorChange `json:"Changes,omitempty"`
+	CheckedAt time.Time      `json:"CheckedAt"`
+	Game      string         `json:"Game"`
+	Mods      []AuthorMod    `json:"Mods"`
+}
+
+// Store keeps snapshots as JSON documents under a root directory.
+type Store struct {
+	Dir string
+}
+
+// NewStore returns a Store rooted at dir.
+func NewStore(dir string) Store {
+	return Store{Dir: dir}
+}
+
+// LoadAuthor returns the last snapshot of the author's mods for the game. The boolean
+// is false when no snapshot has been saved yet.
+func (s Store) LoadAuthor(game, author string) (AuthorSnapshot, bool, error) {
+	data, err := fsys.Default.ReadFile(s.authorPath(game, author))
+	if os.IsNotExist(err) {
+		return AuthorSnapshot{}, false, nil
+	}
+	if err != nil {
+		return AuthorSnapshot{}, false, fmt.Errorf("error reading snapshot: %w", err)
+	}
+
+	var snapshot AuthorSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return AuthorSnapshot{}, false, fmt.Errorf("error decoding snapshot: %w", err)
+	}
+	return snapshot, true, nil
+}
+
+// SaveAuthor writes the snapshot, replacing the previous snapshot of the same author
+// and game, and returns the path it was saved to.
+func (s Store) SaveAuthor(snapshot AuthorSnapshot) (string, error) {
+	path := s.authorPath(snapshot.Game, snapshot.Author)
+	if err := fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return "", err
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("error formatting snapshot: %w", err)
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("error saving snapshot: %s - %v", path, err)
+	}
+	return path, nil
+}
+
+// authorPath returns where the snapshot of the author's mods for the game is stored,
+// e.g. <dir>/authors/skyrim/some-author.json.
+func (s Store) authorPath(game, author string) string {
+	return filepath.Join(s.Dir, "authors", strings.ToLower(game), fileSafe(author)+".json")
+}
+
+// fileSafe replaces the characters that can't appear in a file name.
+func fileSafe(name string) string {
+	return strings.NewReplacer("/", "_", `\`, "_", ":", "_").Replace(strings.ToLower(name))
+}
+
+// DiffAuthor compares the current mods of an author with the previous snapshot and
+// returns the mods that are new and the mods whose version changed, in the order of
+// current. Mods missing a version in either snapshot are not reported as updated.
+func DiffAuthor(previous, current []AuthorMod) []AuthorChange {
+	known := make(map[int64]AuthorMod, len(previous))
+	for _, mod := range previous {
+		known[mod.ModID] = mod
+	}
+
+	var changes []AuthorChange
+	for _, mod := range current {
+		last, ok := known[mod.ModID]
+		switch {
+		case !ok:
+			changes = append(changes, AuthorChange{Kind: ChangeNew, ModID: mod.ModID, Name: mod.Name, Url: mod.Url, Version: mod.Version})
+		case last.Version != "" && mod.Version != "" && last.Version != mod.Version:
+			changes = append(changes, AuthorChange{Kind: ChangeUpdated, ModID: mod.ModID, Name: mod.Name, PreviousVersion: last.Version, Url: mod.Url, Version: mod.Version})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,63 @@
+package snapshots
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_SaveAndLoadAuthor(t *testing.T) {
+	// Arrange
+	store := NewStore(t.TempDir())
+	snapshot := AuthorSnapshot{
+		Author:    "Some/Author",
+		CheckedAt: time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC),
+		Game:      "skyrim",
+		Mods:      []AuthorMod{{ModID: 1, Name: "One", Version: "1.0"}},
+	}
+
+	// Act
+	path, err := store.SaveAuthor(snapshot)
+	require.NoError(t, err)
+	loaded, found, err := store.LoadAuthor("Skyrim", "Some/Author")
+
+	// Assert
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, filepath.Join(store.Dir, "authors", "skyrim", "some_author.json"), path)
+	assert.Equal(t, snapshot, loaded)
+}
+
+func TestStore_LoadAuthor_Missing(t *testing.T) {
+	_, found, err := NewStore(t.TempDir()).LoadAuthor("skyrim", "nobody")
+
+	assert.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestDiffAuthor(t *testing.T) {
+	// Arrange
+	previous := []AuthorMod{
+		{ModID: 1, Version: "1.0"},
+		{ModID: 2, Version: "2.0"},
+		{ModID: 3},
+	}
+	current := []AuthorMod{
+		{ModID: 1, Name: "One", Version: "1.1"},
+		{ModID: 2, Version: "2.0"},
+		{ModID: 3, Version: "3.0"},
+		{ModID: 4, Name: "Four", Version: "0.1"},
+	}
+
+	// Act
+	changes := DiffAuthor(previous, current)
+
+	// Assert
+	assert.Equal(t, []AuthorChange{
+		{Kind: ChangeUpdated, ModID: 1, Name: "One", PreviousVersion: "1.0", Version: "1.1"},
+		{Kind: ChangeNew, ModID: 4, Name: "Four", Version: "0.1"},
+	}, changes)
+}
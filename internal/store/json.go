@@ -0,0 +1,57 @@
+package store
+
+import (
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/watch"
+)
+
+// init registers the JSON driver.
+func init() {
+	Register(JSONDriverName, JSONDriver{})
+}
+
+// JSONDriver keeps the history journal in a JSON Lines file and the watch list in a
+// JSON file, at the path given as location.
+type JSONDriver struct{}
+
+// OpenHistory returns the history journal kept in the JSON Lines file at path. The
+// file is created on the first append.
+func (JSONDriver) OpenHistory(path string) (History, error) {
+	return jsonHistory{path: path}, nil
+}
+
+// OpenWatchlist returns the watch list kept in the JSON file at path. The file is
+// created on the first save.
+func (JSONDriver) OpenWatchlist(path string) (Watchlist, error) {
+	return jsonWatchlist{FileStore: watch.FileStore{Path: path}}, nil
+}
+
+// jsonHistory is a History backed by a JSON Lines file.
+type jsonHistory struct {
+	path string
+}
+
+// Append writes the entries to the end of the file.
+func (h jsonHistory) Append(entries ...history.Entry) error {
+	return history.Append(h.path, entries...)
+}
+
+// Close is a no-op, the file is only open while it is read or written.
+func (h jsonHistory) Close() error {
+	return nil
+}
+
+// Load reads every entry of the file in the order they were recorded.
+func (h jsonHistory) Load() ([]history.Entry, error) {
+	return history.Load(h.path)
+}
+
+// jsonWatchlist is a Watchlist backed by a JSON file.
+type jsonWatchlist struct {
+	watch.FileStore
+}
+
+// Close is a no-op, the file is only open while it is read or written.
+func (w jsonWatchlist) Close() error {
+	return nil
+}
@@ -0,0 +1,296 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/archive"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// DefaultSearchIndexFilename is the name of the full-text search index in the data
+// directory.
+const DefaultSearchIndexFilename = "search.db"
+
+// searchSchema creates the tables of the search index: the saved files indexed, with
+// the modification time and size they were indexed at, and an FTS5 table holding the
+// name, description and changelogs of the mod of each file under the same rowid.
+const searchSchema = `
+CREATE TABLE IF NOT EXISTS search_files (
+	id       INTEGER PRIMARY KEY AUTOINCREMENT,
+	path     TEXT    NOT NULL UNIQUE,
+	modified INTEGER NOT NULL,
+	size     INTEGER NOT NULL
+);
+CREATE VIRTUAL TABLE IF NOT EXISTS mod_search USING fts5(
+	game UNINDEXED,
+	mod_id UNINDEXED,
+	path UNINDEXED,
+	name,
+	description,
+	changelogs,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+`
+
+// searchWeights ranks matches in the name above those in the description and
+// changelogs, in the column order of mod_search.
+const searchWeights = "0, 0, 0, 10.0, 2.0, 1.0"
+
+// SearchHit is a mod matching a search, with an excerpt of the matching text where
+// the matched terms are surrounded by brackets.
+type SearchHit struct {
+	Game    string `json:"Game"`
+	ModID   int64  `json:"ModID"`
+	Name    string `json:"Name"`
+	Path    string `json:"Path"`
+	Snippet string `json:"Snippet,omitempty"`
+}
+
+// SyncStats counts the saved files added to, updated in and removed from the search
+// index by a sync.
+type SyncStats struct {
+	Added   int
+	Removed int
+	Skipped int
+	Updated int
+}
+
+// SearchIndex is a full-text index over the names, descriptions and changelogs of the
+// saved mods, kept in a SQLite database.
+type SearchIndex struct {
+	db *sql.DB
+}
+
+// OpenSearchIndex opens the search index kept in the SQLite database at path,
+// creating it if needed. It may share the database of the SQLite driver.
+func OpenSearchIndex(path string) (*SearchIndex, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(searchSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error preparing search index %s: %w", path, err)
+	}
+	return &SearchIndex{db: db}, nil
+}
+
+// Close closes the database.
+func (s *SearchIndex) Close() error {
+	return s.db.Close()
+}
+
+// Sync brings the index up to date with the saved mod files of the output directory
+// dir: new files and files modified since they were indexed are read and indexed,
+// and files that are gone are removed. Unchanged files aren't read, so that syncing
+// an indexed archive is quick. Files that can't be read or decoded are skipped. With
+// rebuild, the index is emptied first. Returns an error if the directory can't be
+// read or the index can't be written.
+func (s *SearchIndex) Sync(dir string, rebuild bool) (SyncStats, error) {
+	var stats SyncStats
+
+	files, err := archive.ModFiles(dir)
+	if err != nil {
+		return stats, err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return stats, fmt.Errorf("error updating search index: %w", err)
+	}
+	defer tx.Rollback()
+
+	if rebuild {
+		if _, err := tx.Exec("DELETE FROM mod_search; DELETE FROM search_files"); err != nil {
+			return stats, fmt.Errorf("error updating search index: %w", err)
+		}
+	}
+
+	indexed, err := indexedFiles(tx)
+	if err != nil {
+		return stats, err
+	}
+
+	for _, file := range files {
+		info, err := fsys.Default.Stat(file.Path)
+		if err != nil {
+			stats.Skipped++
+			continue
+		}
+
+		previous, ok := indexed[file.Path]
+		delete(indexed, file.Path)
+		if ok && previous.modified == info.ModTime().UnixNano() && previous.size == info.Size() {
+			continue
+		}
+
+		mod, err := readSavedMod(file.Path)
+		if err != nil {
+			stats.Skipped++
+			continue
+		}
+
+		if ok {
+			if err := removeIndexedFile(tx, previous.id); err != nil {
+				return stats, err
+			}
+			stats.Updated++
+		} else {
+			stats.Added++
+		}
+
+		result, err := tx.Exec("INSERT INTO search_files (path, modified, size) VALUES (?, ?, ?)", file.Path, info.ModTime().UnixNano(), info.Size())
+		if err != nil {
+			return stats, fmt.Errorf("error updating search index: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return stats, fmt.Errorf("error updating search index: %w", err)
+		}
+		_, err = tx.Exec(
+			"INSERT INTO mod_search (rowid, game, mod_id, path, name, description, changelogs) VALUES (?, ?, ?, ?, ?, ?, ?)",
+			id, file.Game, mod.ModID, file.Path, mod.Name, mod.Description, changeLogText(mod.ChangeLogs),
+		)
+		if err != nil {
+			return stats, fmt.Errorf("error updating search index: %w", err)
+		}
+	}
+
+	for _, gone := range indexed {
+		if err := removeIndexedFile(tx, gone.id); err != nil {
+			return stats, err
+		}
+		stats.Removed++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return stats, fmt.Errorf("error updating search index: %w", err)
+	}
+	return stats, nil
+}
+
+// Search returns up to limit mods matching every term of query, best matches first,
+// 0 meaning no limit. Terms match words starting with them, ignoring case and
+// diacritics, so "open cit" finds "Open Cities". Returns an error if the query holds
+// no term or the index can't be read.
+func (s *SearchIndex) Search(query string, limit int) ([]SearchHit, error) {
+	match := matchExpression(query)
+	if match == "" {
+		return nil, fmt.Errorf("search query %q holds no term", query)
+	}
+	if limit <= 0 {
+		limit = -1
+	}
+
+	rows, err := s.db.Query(
+		`SELECT game, mod_id, path, name, snippet(mod_search, -1, '[', ']', '…', 12)
+		FROM mod_search WHERE mod_search MATCH ?
+		ORDER BY bm25(mod_search, `+searchWeights+`), name LIMIT ?`,
+		match, limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error searching index: %w", err)
+	}
+	defer rows.Close()
+
+	hits := []SearchHit{}
+	for rows.Next() {
+		var hit SearchHit
+		if err := rows.Scan(&hit.Game, &hit.ModID, &hit.Path, &hit.Name, &hit.Snippet); err != nil {
+			return nil, fmt.Errorf("error searching index: %w", err)
+		}
+		hits = append(hits, hit)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error searching index: %w", err)
+	}
+	return hits, nil
+}
+
+// indexedFile is a saved file as recorded in the search index.
+type indexedFile struct {
+	id       int64
+	modified int64
+	size     int64
+}
+
+// indexedFiles returns the files recorded in the search index by path.
+func indexedFiles(tx *sql.Tx) (map[string]indexedFile, error) {
+	rows, err := tx.Query("SELECT id, path, modified, size FROM search_files")
+	if err != nil {
+		return nil, fmt.Errorf("error reading search index: %w", err)
+	}
+	defer rows.Close()
+
+	files := map[string]indexedFile{}
+	for rows.Next() {
+		var (
+			file indexedFile
+			path string
+		)
+		if err := rows.Scan(&file.id, &path, &file.modified, &file.size); err != nil {
+			return nil, fmt.Errorf("error reading search index: %w", err)
+		}
+		files[path] = file
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading search index: %w", err)
+	}
+	return files, nil
+}
+
+// removeIndexedFile removes the file with the given id and its mod from the index.
+func removeIndexedFile(tx *sql.Tx, id int64) error {
+	if _, err := tx.Exec("DELETE FROM mod_search WHERE rowid = ?", id); err != nil {
+		return fmt.Errorf("error updating search index: %w", err)
+	}
+	if _, err := tx.Exec("DELETE FROM search_files WHERE id = ?", id); err != nil {
+		return fmt.Errorf("error updating search index: %w", err)
+	}
+	return nil
+}
+
+// readSavedMod reads the mod of the saved results file at path.
+func readSavedMod(path string) (types.ModInfo, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return types.ModInfo{}, err
+	}
+
+	var results types.Results
+	if err := json.Unmarshal(data, &results); err != nil {
+		return types.ModInfo{}, err
+	}
+	return results.Mods, nil
+}
+
+// changeLogText flattens the changelogs into text, one version per line followed by
+// its notes.
+func changeLogText(changeLogs []types.ChangeLog) string {
+	var text strings.Builder
+	for _, changeLog := range changeLogs {
+		text.WriteString(changeLog.Version)
+		for _, note := range changeLog.Notes {
+			text.WriteString("\n" + note)
+		}
+		text.WriteString("\n")
+	}
+	return text.String()
+}
+
+// matchExpression turns a plain search query into an FTS5 match expression requiring
+// every whitespace separated term as a word prefix. Terms are quoted so that
+// punctuation, e.g. "skse64-plugin", is searched for rather than read as FTS5 syntax.
+func matchExpression(query string) string {
+	terms := strings.Fields(query)
+	for i, term := range terms {
+		terms[i] = `"` + strings.ReplaceAll(term, `"`, `""`) + `"*`
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,127 @@
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeSavedMod saves mod as a results file of game in dir, modified at modified.
+func writeSavedMod(t *testing.T, dir, game, filename string, mod types.ModInfo, modified time.Time) string {
+	t.Helper()
+	path := filepath.Join(dir, game, filename)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+	data, err := json.Marshal(types.Results{Mods: mod})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, data, 0644))
+	require.NoError(t, os.Chtimes(path, modified, modified))
+	return path
+}
+
+func TestSearchIndex(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	modified := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	writeSavedMod(t, dir, "skyrimspecialedition", "open cities skyrim 1234.json", types.ModInfo{
+		ModID:       1234,
+		Name:        "Open Cities Skyrim",
+		Description: "Removes the city worldspaces.",
+	}, modified)
+	writeSavedMod(t, dir, "skyrimspecialedition", "jk's skyrim 5678.json", types.ModInfo{
+		ModID:       5678,
+		Name:        "JK's Skyrim",
+		Description: "Overhauls the cities. Compatible with Open Cities.",
+		ChangeLogs:  []types.ChangeLog{{Version: "1.1", Notes: []string{"Fixed navmesh"}}},
+	}, modified)
+	writeSavedMod(t, dir, "skyrimspecialedition", "broken 1.json", types.ModInfo{}, modified)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "skyrimspecialedition", "broken 1.json"), []byte("{"), 0644))
+
+	index, err := OpenSearchIndex(filepath.Join(t.TempDir(), DefaultSearchIndexFilename))
+	require.NoError(t, err)
+	defer index.Close()
+
+	// Act
+	stats, err := index.Sync(dir, false)
+	require.NoError(t, err)
+	resync, resyncErr := index.Sync(dir, false)
+	cities, citiesErr := index.Search("open cit", 0)
+	navmesh, navmeshErr := index.Search("NAVMESH", 0)
+	limited, limitedErr := index.Search("cities", 1)
+	none, noneErr := index.Search("dragons", 0)
+	_, emptyErr := index.Search("  ", 0)
+	_, punctuationErr := index.Search("cities -", 0)
+
+	// Assert
+	assert.Equal(t, SyncStats{Added: 2, Skipped: 1}, stats)
+	assert.NoError(t, resyncErr)
+	assert.Equal(t, SyncStats{Skipped: 1}, resync)
+	assert.NoError(t, citiesErr)
+	require.Len(t, cities, 2)
+	assert.Equal(t, int64(1234), cities[0].ModID, "matches in the name rank first")
+	assert.Equal(t, "skyrimspecialedition", cities[0].Game)
+	assert.Equal(t, filepath.Join(dir, "skyrimspecialedition", "open cities skyrim 1234.json"), cities[0].Path)
+	assert.Equal(t, "[Open] [Cities] Skyrim", cities[0].Snippet)
+	assert.NoError(t, navmeshErr)
+	require.Len(t, navmesh, 1)
+	assert.Equal(t, "JK's Skyrim", navmesh[0].Name)
+	assert.Contains(t, navmesh[0].Snippet, "[navmesh]")
+	assert.NoError(t, limitedErr)
+	assert.Len(t, limited, 1)
+	assert.NoError(t, noneErr)
+	assert.NotNil(t, none)
+	assert.Empty(t, none)
+	assert.EqualError(t, emptyErr, `search query "  " holds no term`)
+	assert.NoError(t, punctuationErr)
+}
+
+func TestSearchIndex_SyncChanges(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	modified := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	kept := writeSavedMod(t, dir, "skyrim", "kept 1.json", types.ModInfo{ModID: 1, Name: "Kept Mod"}, modified)
+	changed := writeSavedMod(t, dir, "skyrim", "changed 2.json", types.ModInfo{ModID: 2, Name: "Old Name"}, modified)
+	removed := writeSavedMod(t, dir, "skyrim", "removed 3.json", types.ModInfo{ModID: 3, Name: "Removed Mod"}, modified)
+
+	index, err := OpenSearchIndex(filepath.Join(t.TempDir(), DefaultSearchIndexFilename))
+	require.NoError(t, err)
+	defer index.Close()
+	_, err = index.Sync(dir, false)
+	require.NoError(t, err)
+
+	writeSavedMod(t, dir, "skyrim", "changed 2.json", types.ModInfo{ModID: 2, Name: "New Name"}, modified.Add(time.Hour))
+	require.NoError(t, os.Remove(removed))
+
+	// Act
+	stats, err := index.Sync(dir, false)
+	require.NoError(t, err)
+	oldName, _ := index.Search("old", 0)
+	newName, _ := index.Search("new", 0)
+	gone, _ := index.Search("removed", 0)
+	rebuilt, rebuildErr := index.Sync(dir, true)
+	all, _ := index.Search("mod name", 0)
+
+	// Assert
+	assert.Equal(t, SyncStats{Removed: 1, Updated: 1}, stats)
+	assert.Empty(t, oldName)
+	require.Len(t, newName, 1)
+	assert.Equal(t, changed, newName[0].Path)
+	assert.Empty(t, gone)
+	assert.NoError(t, rebuildErr)
+	assert.Equal(t, SyncStats{Added: 2}, rebuilt)
+	assert.Empty(t, all)
+	kept1, _ := index.Search("kept", 0)
+	require.Len(t, kept1, 1)
+	assert.Equal(t, kept, kept1[0].Path)
+}
+
+func TestMatchExpression(t *testing.T) {
+	assert.Equal(t, `"open"* "cities"*`, matchExpression("  open   cities "))
+	assert.Equal(t, `"skse64-plugin"* "say"* """hi"""*`, matchExpression(`skse64-plugin say "hi"`))
+	assert.Equal(t, "", matchExpression(" "))
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/watch"
+
+	// Registers the pure Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// sqliteSchema creates the tables of both stores, so that the journal and the watch
+// list can share a database. Each history entry is kept whole as JSON next to the
+// columns it is looked up by.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS history (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	game        TEXT    NOT NULL,
+	mod_id      INTEGER NOT NULL,
+	recorded_at TEXT    NOT NULL,
+	run_id      TEXT    NOT NULL DEFAULT '',
+	entry       TEXT    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_mod ON history (game, mod_id);
+CREATE TABLE IF NOT EXISTS watchlist (
+	game   TEXT    NOT NULL,
+	mod_id INTEGER NOT NULL,
+	PRIMARY KEY (game, mod_id)
+);
+`
+
+// init registers the SQLite driver.
+func init() {
+	Register(SQLiteDriverName, SQLiteDriver{})
+}
+
+// SQLiteDriver keeps the history journal and the watch list in the SQLite database at
+// the path given as location, which may be the same for both.
+type SQLiteDriver struct{}
+
+// OpenHistory returns the history journal kept in the SQLite database at path,
+// creating the database if needed.
+func (SQLiteDriver) OpenHistory(path string) (History, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	return sqliteHistory{db: db}, nil
+}
+
+// OpenWatchlist returns the watch list kept in the SQLite database at path, creating
+// the database if needed.
+func (SQLiteDriver) OpenWatchlist(path string) (Watchlist, error) {
+	db, err := openSQLite(path)
+	if err != nil {
+		return nil, err
+	}
+	return sqliteWatchlist{db: db}, nil
+}
+
+// openSQLite opens the database at path, creating it and its directory if needed, and
+// makes sure its tables exist. The database lives on disk rather than in fsys.Default
+// as SQLite does its own file handling.
+func openSQLite(path string) (*sql.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("error opening database %s: %w", path, err)
+	}
+	if _, err := db.Exec(sqliteSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error preparing database %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// sqliteHistory is a History backed by a SQLite database.
+type sqliteHistory struct {
+	db *sql.DB
+}
+
+// Append inserts the entries in a single transaction.
+func (h sqliteHistory) Append(entries ...history.Entry) error {
+	tx, err := h.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error writing history entry: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, entry := range entries {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("error writing history entry: %w", err)
+		}
+
+		_, err = tx.Exec(
+			"INSERT INTO history (game, mod_id, recorded_at, run_id, entry) VALUES (?, ?, ?, ?, ?)",
+			entry.Game, entry.ModID, entry.RecordedAt.UTC().Format(time.RFC3339Nano), entry.RunID, string(data),
+		)
+		if err != nil {
+			return fmt.Errorf("error writing history entry: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error writing history entry: %w", err)
+	}
+	return nil
+}
+
+// Close closes the database.
+func (h sqliteHistory) Close() error {
+	return h.db.Close()
+}
+
+// Load reads every entry in the order they were recorded.
+func (h sqliteHistory) Load() ([]history.Entry, error) {
+	rows, err := h.db.Query("SELECT id, entry FROM history ORDER BY id")
+	if err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []history.Entry
+	for rows.Next() {
+		var (
+			id   int64
+			data string
+		)
+		if err := rows.Scan(&id, &data); err != nil {
+			return nil, fmt.Errorf("error reading history: %w", err)
+		}
+
+		var entry history.Entry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			return nil, fmt.Errorf("error decoding history row %d: %w", id, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading history: %w", err)
+	}
+	return entries, nil
+}
+
+// sqliteWatchlist is a Watchlist backed by a SQLite database.
+type sqliteWatchlist struct {
+	db *sql.DB
+}
+
+// Close closes the database.
+func (w sqliteWatchlist) Close() error {
+	return w.db.Close()
+}
+
+// Load reads the watch list, sorted by game and mod ID.
+func (w sqliteWatchlist) Load() ([]watch.Target, error) {
+	rows, err := w.db.Query("SELECT game, mod_id FROM watchlist ORDER BY game, mod_id")
+	if err != nil {
+		return nil, fmt.Errorf("error reading watch list: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []watch.Target
+	for rows.Next() {
+		var target watch.Target
+		if err := rows.Scan(&target.Game, &target.ModID); err != nil {
+			return nil, fmt.Errorf("error reading watch list: %w", err)
+		}
+		targets = append(targets, target)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading watch list: %w", err)
+	}
+	return targets, nil
+}
+
+// Save replaces the watch list in a single transaction.
+func (w sqliteWatchlist) Save(targets []watch.Target) error {
+	tx, err := w.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error saving watch list: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM watchlist"); err != nil {
+		return fmt.Errorf("error saving watch list: %w", err)
+	}
+	for _, target := range targets {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO watchlist (game, mod_id) VALUES (?, ?)", target.Game, target.ModID); err != nil {
+			return fmt.Errorf("error saving watch list: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error saving watch list: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/watch"
+)
+
+const (
+	// DefaultDriver is the name of the driver used when none is configured.
+	DefaultDriver = JSONDriverName
+	// JSONDriverName is the name of the driver keeping the data in flat JSON files.
+	JSONDriverName = "json"
+	// SQLiteDriverName is the name of the driver keeping the data in SQLite databases.
+	SQLiteDriverName = "sqlite"
+)
+
+// History persists the history journal.
+type History interface {
+	Append(entries ...history.Entry) error
+	Close() error
+	Load() ([]history.Entry, error)
+}
+
+// Watchlist persists the list of mods tracked by the watch command.
+type Watchlist interface {
+	watch.Store
+	Close() error
+}
+
+// Driver opens the stores of a storage backend. The location passed to each store is
+// backend specific, e.g. a file path for the JSON and SQLite drivers or a connection
+// string for a database server.
+type Driver interface {
+	OpenHistory(location string) (History, error)
+	OpenWatchlist(location string) (Watchlist, error)
+}
+
+var (
+	// driversMu guards drivers.
+	driversMu sync.RWMutex
+	// drivers holds the registered drivers by name.
+	drivers = map[string]Driver{}
+)
+
+// Register makes a driver available under the provided name. It panics if the driver
+// is nil or a driver is already registered under that name.
+func Register(name string, driver Driver) {
+	driversMu.Lock()
+	defer driversMu.Unlock()
+
+	if driver == nil {
+		panic("store: Register driver is nil")
+	}
+	if _, ok := drivers[name]; ok {
+		panic("store: Register called twice for driver " + name)
+	}
+	drivers[name] = driver
+}
+
+// Drivers returns the sorted names of the registered drivers.
+func Drivers() []string {
+	driversMu.RLock()
+	defer driversMu.RUnlock()
+
+	names := make([]string, 0, len(drivers))
+	for name := range drivers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the driver registered under name, ignoring case. An empty name selects
+// the default driver. Returns an error if no such driver is registered.
+func Get(name string) (Driver, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		name = DefaultDriver
+	}
+
+	driversMu.RLock()
+	driver, ok := drivers[name]
+	driversMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown storage driver %q, expected one of %s", name, strings.Join(Drivers(), ", "))
+	}
+	return driver, nil
+}
+
+// LoadHistory reads every entry of the history journal kept by the named driver at
+// location.
+func LoadHistory(driverName, location string) ([]history.Entry, error) {
+	journal, err := OpenHistory(driverName, location)
+	if err != nil {
+		return nil, err
+	}
+	defer journal.Close()
+
+	return journal.Load()
+}
+
+// AppendHistory records the entries in the history journal kept by the named driver at
+// location.
+func AppendHistory(driverName, location string, entries ...history.Entry) error {
+	journal, err := OpenHistory(driverName, location)
+	if err != nil {
+		return err
+	}
+
+	if err := journal.Append(entries...); err != nil {
+		journal.Close()
+		return err
+	}
+	return journal.Close()
+}
+
+// OpenHistory opens the history journal kept by the named driver at location.
+func OpenHistory(driverName, location string) (History, error) {
+	driver, err := Get(driverName)
+	if err != nil {
+		return nil, err
+	}
+	return driver.OpenHistory(location)
+}
+
+// OpenWatchlist opens the watch list kept by the named driver at location.
+func OpenWatchlist(driverName, location string) (Watchlist, error) {
+	driver, err := Get(driverName)
+	if err != nil {
+		return nil, err
+	}
+	return driver.OpenWatchlist(location)
+}
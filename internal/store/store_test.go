@@ -0,0 +1,118 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/history"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name    string
+		driver  string
+		want    Driver
+		wantErr string
+	}{
+		{name: "default", driver: "", want: JSONDriver{}},
+		{name: "json", driver: "json", want: JSONDriver{}},
+		{name: "sqlite ignoring case", driver: " SQLite ", want: SQLiteDriver{}},
+		{name: "unknown", driver: "postgres", wantErr: `unknown storage driver "postgres", expected one of json, sqlite`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			driver, err := Get(tt.driver)
+
+			// Assert
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, driver)
+		})
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	assert.Panics(t, func() { Register(JSONDriverName, JSONDriver{}) })
+}
+
+func TestHistory(t *testing.T) {
+	updated := types.Timestamp{Time: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)}
+	first := history.Entry{Game: "skyrim", ModID: 1, RecordedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), RunID: "run-1", Version: "1.0"}
+	second := history.Entry{Game: "fallout4", LastUpdatedAt: &updated, ModID: 2, Name: "Mod", RecordedAt: time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), TotalDLs: "1,000"}
+
+	for _, driver := range []string{JSONDriverName, SQLiteDriverName} {
+		t.Run(driver, func(t *testing.T) {
+			// Arrange
+			location := filepath.Join(t.TempDir(), "nested", "history")
+
+			// Act
+			empty, emptyErr := LoadHistory(driver, location)
+			require.NoError(t, AppendHistory(driver, location, first))
+			require.NoError(t, AppendHistory(driver, location, second))
+			entries, err := LoadHistory(driver, location)
+
+			// Assert
+			assert.NoError(t, emptyErr)
+			assert.Empty(t, empty)
+			assert.NoError(t, err)
+			assert.Equal(t, []history.Entry{first, second}, entries)
+		})
+	}
+}
+
+func TestWatchlist(t *testing.T) {
+	targets := []watch.Target{{Game: "fallout4", ModID: 5}, {Game: "skyrim", ModID: 1}}
+
+	for _, driver := range []string{JSONDriverName, SQLiteDriverName} {
+		t.Run(driver, func(t *testing.T) {
+			// Arrange
+			location := filepath.Join(t.TempDir(), "watchlist")
+			list, err := OpenWatchlist(driver, location)
+			require.NoError(t, err)
+			defer list.Close()
+
+			// Act
+			empty, emptyErr := list.Load()
+			require.NoError(t, list.Save(append(targets, watch.Target{Game: "skyrim", ModID: 9})))
+			require.NoError(t, list.Save(targets))
+			loaded, err := list.Load()
+
+			// Assert
+			assert.NoError(t, emptyErr)
+			assert.Empty(t, empty)
+			assert.NoError(t, err)
+			assert.Equal(t, targets, loaded)
+		})
+	}
+}
+
+func TestSQLite_SharedDatabase(t *testing.T) {
+	// Arrange
+	location := filepath.Join(t.TempDir(), "nexus.db")
+	list, err := OpenWatchlist(SQLiteDriverName, location)
+	require.NoError(t, err)
+	defer list.Close()
+	entry := history.Entry{Game: "skyrim", ModID: 1, RecordedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+
+	// Act
+	require.NoError(t, list.Save([]watch.Target{{Game: "skyrim", ModID: 1}}))
+	require.NoError(t, AppendHistory(SQLiteDriverName, location, entry))
+	entries, err := LoadHistory(SQLiteDriverName, location)
+	targets, listErr := list.Load()
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []history.Entry{entry}, entries)
+	assert.NoError(t, listErr)
+	assert.Equal(t, []watch.Target{{Game: "skyrim", ModID: 1}}, targets)
+}
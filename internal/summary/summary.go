@@ -0,0 +1,168 @@
+package summary
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+const (
+	// Filename is the name of the summary written in a game's output directory.
+	Filename = "summary.json"
+	// MarkdownFilename is the name of the optional Markdown rendering of the summary.
+	MarkdownFilename = "summary.md"
+)
+
+// Entry describes a saved mod file listed in the summary.
+type Entry struct {
+	File      string    `json:"File"`
+	ModID     int64     `json:"ModID"`
+	Name      string    `json:"Name,omitempty"`
+	RunID     string    `json:"RunID,omitempty"`
+	ScrapedAt time.Time `json:"ScrapedAt"`
+	Version   string    `json:"Version,omitempty"`
+}
+
+// Summary is the index of the mods saved in a game's output directory, updated after
+// every run that saves results. Failed lists the mods whose last scrape failed.
+type Summary struct {
+	Failed    []types.FailedMod `json:"Failed,omitempty"`
+	Game      string            `json:"Game"`
+	Mods      []Entry           `json:"Mods"`
+	RunID     string            `json:"RunID,omitempty"`
+	UpdatedAt time.Time         `json:"UpdatedAt"`
+}
+
+// NewEntry builds the summary entry of a mod saved to file by the run.
+func NewEntry(file, runID string, mod types.ModInfo) Entry {
+	version := mod.LatestVersion
+	if version == "" {
+		version = mod.Version
+	}
+
+	scrapedAt := mod.LastChecked
+	if scrapedAt.IsZero() {
+		scrapedAt = time.Now()
+	}
+
+	return Entry{File: file, ModID: mod.ModID, Name: mod.Name, RunID: runID, ScrapedAt: scrapedAt, Version: version}
+}
+
+// Load reads the summary at path. A missing summary is not an error and yields an
+// empty one.
+func Load(path string) (Summary, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Summary{}, nil
+	}
+	if err != nil {
+		return Summary{}, fmt.Errorf("error reading summary: %w", err)
+	}
+
+	var summary Summary
+	if err := json.Unmarshal(data, &summary); err != nil {
+		return Summary{}, fmt.Errorf("error decoding summary %s: %w", path, err)
+	}
+	return summary, nil
+}
+
+// Merge records the outcome of a run in the summary. Saved mods replace the entries of
+// the same mod; mods that failed are listed as failed and keep any previous entry, and
+// mods that succeeded are no longer listed as failed. Mods and failures are kept sorted
+// by mod ID.
+func (s *Summary) Merge(runID string, saved []Entry, failed []types.FailedMod, now time.Time) {
+	entries := make(map[int64]Entry, len(s.Mods)+len(saved))
+	for _, entry := range s.Mods {
+		entries[entry.ModID] = entry
+	}
+	failures := make(map[int64]types.FailedMod, len(s.Failed)+len(failed))
+	for _, failure := range s.Failed {
+		failures[failure.ModID] = failure
+	}
+
+	for _, entry := range saved {
+		entries[entry.ModID] = entry
+		delete(failures, entry.ModID)
+	}
+	for _, failure := range failed {
+		failures[failure.ModID] = failure
+	}
+
+	s.Mods = make([]Entry, 0, len(entries))
+	for _, entry := range entries {
+		s.Mods = append(s.Mods, entry)
+	}
+	sort.Slice(s.Mods, func(i, j int) bool { return s.Mods[i].ModID < s.Mods[j].ModID })
+
+	s.Failed = make([]types.FailedMod, 0, len(failures))
+	for _, failure := range failures {
+		s.Failed = append(s.Failed, failure)
+	}
+	sort.Slice(s.Failed, func(i, j int) bool { return s.Failed[i].ModID < s.Failed[j].ModID })
+
+	s.RunID = runID
+	s.UpdatedAt = now
+}
+
+// Save writes the summary to path as indented JSON, creating its directory if needed.
+func Save(path string, summary Summary) error {
+	if err := fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(summary, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting summary: %w", err)
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
+
+// SaveMarkdown writes the summary to path as a Markdown table, followed by the list of
+// failed mods when there are any.
+func SaveMarkdown(path string, summary Summary) error {
+	if err := fsys.Default.WriteFile(path, []byte(Markdown(summary)), 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
+
+// Markdown renders the summary as a Markdown document.
+func Markdown(summary Summary) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s saved mods\n\n", summary.Game)
+	fmt.Fprintf(&b, "Updated %s", summary.UpdatedAt.Format(time.RFC3339))
+	if summary.RunID != "" {
+		fmt.Fprintf(&b, " by run %s", summary.RunID)
+	}
+	b.WriteString("\n\n")
+
+	b.WriteString("| Mod ID | Name | Version | Scraped | File |\n")
+	b.WriteString("| --- | --- | --- | --- | --- |\n")
+	for _, entry := range summary.Mods {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | [%s](<%s>) |\n",
+			entry.ModID, markdownEscape(entry.Name), markdownEscape(entry.Version), entry.ScrapedAt.Format(time.RFC3339), markdownEscape(entry.File), entry.File)
+	}
+
+	if len(summary.Failed) > 0 {
+		b.WriteString("\n## Failed\n\n")
+		for _, failure := range summary.Failed {
+			fmt.Fprintf(&b, "- %d: %s\n", failure.ModID, markdownEscape(failure.Error))
+		}
+	}
+	return b.String()
+}
+
+// markdownEscape escapes the characters that would break a Markdown table cell.
+func markdownEscape(s string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+}
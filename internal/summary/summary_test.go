@@ -0,0 +1,94 @@
+package summary
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var now = time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+
+func TestNewEntry(t *testing.T) {
+	// Arrange
+	checked := now.Add(-time.Hour)
+	mod := types.ModInfo{ModID: 1, Name: "One", Version: "1.0", LastChecked: checked}
+
+	// Act
+	entry := NewEntry("one 1.json", "run-1", mod)
+
+	// Assert
+	assert.Equal(t, Entry{File: "one 1.json", ModID: 1, Name: "One", RunID: "run-1", ScrapedAt: checked, Version: "1.0"}, entry)
+}
+
+func TestMerge(t *testing.T) {
+	// Arrange
+	s := Summary{
+		Mods:   []Entry{{File: "two 2.json", ModID: 2, RunID: "run-1"}, {File: "one 1.json", ModID: 1, RunID: "run-1"}},
+		Failed: []types.FailedMod{{ModID: 3, Error: "boom"}},
+	}
+	saved := []Entry{{File: "three 3.json", ModID: 3, RunID: "run-2"}, {File: "one 1.json", ModID: 1, RunID: "run-2"}}
+	failed := []types.FailedMod{{ModID: 2, Error: "timed out"}}
+
+	// Act
+	s.Merge("run-2", saved, failed, now)
+
+	// Assert
+	assert.Equal(t, []Entry{
+		{File: "one 1.json", ModID: 1, RunID: "run-2"},
+		{File: "two 2.json", ModID: 2, RunID: "run-1"},
+		{File: "three 3.json", ModID: 3, RunID: "run-2"},
+	}, s.Mods)
+	assert.Equal(t, []types.FailedMod{{ModID: 2, Error: "timed out"}}, s.Failed)
+	assert.Equal(t, "run-2", s.RunID)
+	assert.Equal(t, now, s.UpdatedAt)
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	path := filepath.Join("data", "skyrim", Filename)
+	s := Summary{Game: "skyrim", Mods: []Entry{{File: "one 1.json", ModID: 1, ScrapedAt: now}}, UpdatedAt: now}
+
+	// Act
+	err := Save(path, s)
+	loaded, loadErr := Load(path)
+
+	// Assert
+	require.NoError(t, err)
+	require.NoError(t, loadErr)
+	assert.Equal(t, s, loaded)
+}
+
+func TestLoad_Missing(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+
+	s, err := Load(filepath.Join("data", Filename))
+
+	assert.NoError(t, err)
+	assert.Empty(t, s.Mods)
+}
+
+func TestMarkdown(t *testing.T) {
+	// Arrange
+	s := Summary{
+		Game:      "skyrim",
+		Mods:      []Entry{{File: "a|b 1.json", ModID: 1, Name: "A|B", ScrapedAt: now, Version: "1.0"}},
+		Failed:    []types.FailedMod{{ModID: 2, Error: "boom"}},
+		RunID:     "run-1",
+		UpdatedAt: now,
+	}
+
+	// Act
+	markdown := Markdown(s)
+
+	// Assert
+	assert.Contains(t, markdown, "# skyrim saved mods")
+	assert.Contains(t, markdown, "Updated 2024-06-01T12:00:00Z by run run-1")
+	assert.Contains(t, markdown, `| 1 | A\|B | 1.0 | 2024-06-01T12:00:00Z | [a\|b 1.json](<a|b 1.json>) |`)
+	assert.Contains(t, markdown, "## Failed\n\n- 2: boom\n")
+}
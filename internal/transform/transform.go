@@ -0,0 +1,185 @@
+package transform
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/language"
+	"github.com/ondrovic/nexus-mods-scraper/internal/redact"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/extractors"
+)
+
+// Names of the built-in stages, in the order they run by default.
+const (
+	// StageFileCategories keeps only the files of the sections given with
+	// --file-categories.
+	StageFileCategories = "file-categories"
+	// StageAnnotateChangeLogLang records the detected language of each changelog note,
+	// with --annotate-changelog-lang.
+	StageAnnotateChangeLogLang = "annotate-changelog-lang"
+	// StageChangeLogLang keeps only the changelog notes written in the language given
+	// with --changelog-lang.
+	StageChangeLogLang = "changelog-lang"
+	// StageRedactPersonal strips the usernames and profile links, with --redact-personal.
+	StageRedactPersonal = "redact-personal"
+)
+
+// Stage transforms a mod once it is extracted, before it is displayed, saved or
+// emitted, e.g. to normalize, redact or enrich it. Apply must not modify the slices or
+// pointers of the mod it is given in place, but replace them.
+type Stage interface {
+	Apply(mod types.ModInfo) types.ModInfo
+}
+
+// StageFunc adapts a function to a Stage.
+type StageFunc func(mod types.ModInfo) types.ModInfo
+
+// Apply calls f with the mod.
+func (f StageFunc) Apply(mod types.ModInfo) types.ModInfo {
+	return f(mod)
+}
+
+// Builder returns the stage configured by the command-line flags, or nil when the
+// flags don't enable it.
+type Builder func(sc types.CliFlags) Stage
+
+var (
+	// registryMu guards registry and defaultOrder.
+	registryMu sync.RWMutex
+	// registry maps the name of each stage to its builder.
+	registry = map[string]Builder{}
+	// defaultOrder lists the stages in the order they were registered, which is the
+	// order they run in unless --transforms says otherwise.
+	defaultOrder []string
+)
+
+func init() {
+	Register(StageFileCategories, func(sc types.CliFlags) Stage {
+		if len(sc.FileCategories) == 0 {
+			return nil
+		}
+		return StageFunc(func(mod types.ModInfo) types.ModInfo {
+			mod.Files = extractors.FilterFilesByCategory(mod.Files, sc.FileCategories)
+			return mod
+		})
+	})
+	Register(StageAnnotateChangeLogLang, func(sc types.CliFlags) Stage {
+		if !sc.AnnotateChangeLogLang {
+			return nil
+		}
+		return StageFunc(func(mod types.ModInfo) types.ModInfo {
+			mod.ChangeLogs = language.AnnotateChangeLogs(mod.ChangeLogs)
+			return mod
+		})
+	})
+	Register(StageChangeLogLang, func(sc types.CliFlags) Stage {
+		if sc.ChangeLogLang == "" {
+			return nil
+		}
+		return StageFunc(func(mod types.ModInfo) types.ModInfo {
+			mod.ChangeLogs = language.FilterChangeLogs(mod.ChangeLogs, sc.ChangeLogLang)
+			return mod
+		})
+	})
+	Register(StageRedactPersonal, func(sc types.CliFlags) Stage {
+		if !sc.RedactPersonal {
+			return nil
+		}
+		return StageFunc(redact.Mod)
+	})
+}
+
+// Register adds a stage under name, running after the stages registered before it
+// unless --transforms orders them otherwise. It panics if the name is empty or already
+// registered, like registering a flag twice does.
+func Register(name string, build Builder) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" {
+		panic("transform: stage registered without a name")
+	}
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("transform: stage %s registered twice", name))
+	}
+	registry[name] = build
+	defaultOrder = append(defaultOrder, name)
+}
+
+// Names returns the names of the registered stages, sorted.
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return sortedNames()
+}
+
+// Order returns the names of every registered stage in the order they run: the ones
+// listed in order first, then the others in their default order. Names are matched
+// ignoring case. Returns an error if order lists an unknown stage or one twice.
+func Order(order []string) ([]string, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	resolved := make([]string, 0, len(defaultOrder))
+	listed := map[string]bool{}
+	for _, name := range order {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, ok := registry[name]; !ok {
+			return nil, fmt.Errorf("unknown transform stage %q, expected one of %s", name, strings.Join(sortedNames(), ", "))
+		}
+		if listed[name] {
+			return nil, fmt.Errorf("transform stage %s is listed twice", name)
+		}
+		listed[name] = true
+		resolved = append(resolved, name)
+	}
+	for _, name := range defaultOrder {
+		if !listed[name] {
+			resolved = append(resolved, name)
+		}
+	}
+	return resolved, nil
+}
+
+// sortedNames returns the names of the registered stages, sorted. The caller holds
+// registryMu.
+func sortedNames() []string {
+	names := append([]string(nil), defaultOrder...)
+	sort.Strings(names)
+	return names
+}
+
+// Pipeline is the sequence of stages applied to each scraped mod.
+type Pipeline []Stage
+
+// New builds the pipeline of the stages the flags enable, in the order given by
+// sc.Transforms (see Order). Returns an error if the order is invalid.
+func New(sc types.CliFlags) (Pipeline, error) {
+	order, err := Order(sc.Transforms)
+	if err != nil {
+		return nil, err
+	}
+
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	pipeline := make(Pipeline, 0, len(order))
+	for _, name := range order {
+		if stage := registry[name](sc); stage != nil {
+			pipeline = append(pipeline, stage)
+		}
+	}
+	return pipeline, nil
+}
+
+// Apply runs the mod through every stage of the pipeline in turn.
+func (p Pipeline) Apply(mod types.ModInfo) types.ModInfo {
+	for _, stage := range p {
+		mod = stage.Apply(mod)
+	}
+	return mod
+}
@@ -0,0 +1,95 @@
+package transform
+
+import (
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrder(t *testing.T) {
+	tests := []struct {
+		name     string
+		order    []string
+		expected []string
+		err      string
+	}{
+		{
+			name:     "default order",
+			expected: []string{StageFileCategories, StageAnnotateChangeLogLang, StageChangeLogLang, StageRedactPersonal},
+		},
+		{
+			name:     "listed stages first",
+			order:    []string{"Redact-Personal", "changelog-lang"},
+			expected: []string{StageRedactPersonal, StageChangeLogLang, StageFileCategories, StageAnnotateChangeLogLang},
+		},
+		{
+			name:  "unknown stage",
+			order: []string{"uppercase"},
+			err:   `unknown transform stage "uppercase", expected one of annotate-changelog-lang, changelog-lang, file-categories, redact-personal`,
+		},
+		{
+			name:  "stage listed twice",
+			order: []string{"changelog-lang", "changelog-lang"},
+			err:   "transform stage changelog-lang is listed twice",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			order, err := Order(tt.order)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, order)
+		})
+	}
+}
+
+func TestNew_AppliesEnabledStagesInOrder(t *testing.T) {
+	// Arrange
+	mod := types.ModInfo{
+		ChangeLogs: []types.ChangeLog{{Version: "1.0", Notes: []string{"Fixed the menu", "Menü wurde behoben und ist jetzt schneller"}}},
+		Creator:    "schlangster",
+		Files:      []types.File{{Name: "Main", Category: "main"}, {Name: "Old", Category: "old"}},
+	}
+	sc := types.CliFlags{ChangeLogLang: "en", FileCategories: []string{"main"}}
+
+	// Act
+	pipeline, err := New(sc)
+	require.NoError(t, err)
+	transformed := pipeline.Apply(mod)
+
+	// Assert
+	assert.Len(t, pipeline, 2)
+	assert.Equal(t, []types.File{{Name: "Main", Category: "main"}}, transformed.Files)
+	assert.Equal(t, []string{"Fixed the menu"}, transformed.ChangeLogs[0].Notes)
+	assert.Equal(t, "schlangster", transformed.Creator)
+}
+
+func TestPipeline_Apply(t *testing.T) {
+	// Arrange
+	appendTag := func(tag string) Stage {
+		return StageFunc(func(mod types.ModInfo) types.ModInfo {
+			mod.Tags = append(append([]string(nil), mod.Tags...), tag)
+			return mod
+		})
+	}
+	mod := types.ModInfo{Tags: []string{"ui"}}
+
+	// Act
+	transformed := Pipeline{appendTag("first"), appendTag("second")}.Apply(mod)
+
+	// Assert
+	assert.Equal(t, []string{"ui", "first", "second"}, transformed.Tags)
+	assert.Equal(t, []string{"ui"}, mod.Tags)
+}
+
+func TestRegister_Panics(t *testing.T) {
+	assert.Panics(t, func() { Register(StageRedactPersonal, func(types.CliFlags) Stage { return nil }) })
+	assert.Panics(t, func() { Register("", func(types.CliFlags) Stage { return nil }) })
+}
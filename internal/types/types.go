@@ -1,23 +1,85 @@
 package types
 
 import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
 	"time"
 )
 
 // cli related.
-// CliFlags defines the structure for command-line flags, including options such as
-// the base URL, cookie directory, cookie file, display and save result flags, game name,
-// mod ID, output directory, and valid cookies for the operation.
+// CliFlags defines the structure for command-line flags of the scrape commands,
+// including options such as the base URL, cookies, display and save result flags, game
+// name, mod ID, output directory, and how the mods are fetched, filtered, saved and
+// reported.
 type CliFlags struct {
-	BaseUrl         string
-	CookieDirectory string
-	CookieFile      string
-	DisplayResults  bool
-	GameName        string
-	ModID           int64
-	OutputDirectory string
-	SaveResults     bool
-	ValidCookies    []string
+	AnnotateChangeLogLang bool
+	ArchiveHtml           bool
+	Backend               string
+	BaseUrl               string
+	ChangeLogLang         string
+	CookieDirectory       string
+	CookieFile            string
+	CookieHeader          string
+	CookieStore           string
+	DateFormat            string
+	Digest                bool
+	DisplayResults        bool
+	DownloadImages        bool
+	DrainTimeout          time.Duration
+	DryRun                bool
+	Emit                  string
+	ErrorReport           string
+	Fields                []string
+	FileCategories        []string
+	FilenameCase          string
+	FilenameSeparator     string
+	FilterCategory        string
+	FilterTags            []string
+	Format                string
+	FromWabbajack         string
+	GameName              GameSlug
+	GraphQLEndpoint       string
+	HistoryFile           string
+	IdsFile               string
+	IgnoreFile            string
+	ImageConcurrency      int
+	IncludeMeta           bool
+	KeepEmptyFields       bool
+	KeepLast              int
+	MaxMods               int
+	MetricsTextfile       string
+	ModID                 ModID
+	NoPreflight           bool
+	NotifyWebhook         string
+	OutputDirectory       string
+	PathTemplate          string
+	PerModTimeout         time.Duration
+	RateLimit             time.Duration
+	RecordHistory         bool
+	RedactPersonal        bool
+	RefreshReport         string
+	RunID                 string
+	SaveResults           bool
+	ShadowCompare         bool
+	ShareEndpoint         string
+	ShareSummary          bool
+	ShareToken            string
+	SkipUnchanged         bool
+	Snapshot              bool
+	StorageDriver         string
+	SummaryMarkdown       bool
+	Transforms            []string
+	Upload                string
+	UploadConcurrency     int
+	UploadEndpoint        string
+	UploadPassword        string
+	UploadRegion          string
+	UploadUser            string
+	ValidCookies          []string
+	Yes                   bool
 }
 
 // NewScraper initializes and returns a new instance of CliFlags with default values.
@@ -25,51 +87,371 @@ func NewScraper() *CliFlags {
 	return &CliFlags{}
 }
 
+// CookieCandidate holds the valid cookies found in a single browser cookie store,
+// along with the browser, profile and file they were read from, how fresh they are,
+// and whether they were confirmed to authenticate against Nexus Mods. CreatedAt is the
+// most recent creation time of the cookies, ExpiresAt the earliest expiry, and
+// LastWriteAt the last time the browser wrote or used them, or wrote the store when its
+// database doesn't tell; each is zero when unknown.
+type CookieCandidate struct {
+	Browser     string
+	Cookies     map[string]string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	FilePath    string
+	LastWriteAt time.Time
+	Profile     string
+	Validated   bool
+}
+
+// RefreshedAt returns the most recent sign of the session being refreshed: the later
+// of the cookie creation time and the last write of the store.
+func (c CookieCandidate) RefreshedAt() time.Time {
+	if c.LastWriteAt.After(c.CreatedAt) {
+		return c.LastWriteAt
+	}
+	return c.CreatedAt
+}
+
+// HasAll reports whether the candidate holds every one of the provided cookie names.
+func (c CookieCandidate) HasAll(names []string) bool {
+	for _, name := range names {
+		if _, ok := c.Cookies[name]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 // end cli related.
 
+// scrape run related.
+
+// ScrapeSummary records the outcome of a scrape run covering one or more mods,
+// identified by its run ID, listing the mod IDs that succeeded, the mods skipped by
+// filters, as ignored or as unchanged since they were last saved, the mods no longer
+// available along with their status, and the mods that failed along with the reason.
+type ScrapeSummary struct {
+	Failed      []FailedMod      `json:"Failed,omitempty"`
+	Ignored     []int64          `json:"Ignored,omitempty"`
+	RunID       string           `json:"RunID,omitempty"`
+	Skipped     []int64          `json:"Skipped,omitempty"`
+	Succeeded   []int64          `json:"Succeeded,omitempty"`
+	Unavailable []UnavailableMod `json:"Unavailable,omitempty"`
+	Unchanged   []int64          `json:"Unchanged,omitempty"`
+}
+
+// FailedMod represents a mod that could not be scraped during a run, including
+// the mod ID, the error that caused the failure and the kind of failure it is.
+type FailedMod struct {
+	Error string `json:"Error,omitempty"`
+	Kind  string `json:"Kind,omitempty"`
+	ModID int64  `json:"ModID,omitempty"`
+}
+
+// UnavailableMod represents a mod found to be no longer available during a run, with
+// its status, one of the ModStatus constants.
+type UnavailableMod struct {
+	ModID  int64  `json:"ModID,omitempty"`
+	Status string `json:"Status,omitempty"`
+}
+
+// end scrape run related.
+
+// identifiers related.
+// GameSlug is the name of a game as it appears in the Nexus Mods URLs, e.g.
+// "skyrimspecialedition". It is always lowercase.
+type GameSlug string
+
+// ModID is the number identifying a mod within its game on Nexus Mods.
+type ModID int64
+
+// ParseGameSlug returns the GameSlug of the game name, trimmed and lowercased. Returns
+// an error if the name is empty or has characters other than letters, digits, "-" and
+// "_", as such a name can't be part of a Nexus Mods URL or an output path.
+func ParseGameSlug(name string) (GameSlug, error) {
+	slug := strings.ToLower(strings.TrimSpace(name))
+	if slug == "" {
+		return "", fmt.Errorf("game is required")
+	}
+	for _, r := range slug {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return "", fmt.Errorf("invalid game %q: only letters, digits, - and _ are allowed", name)
+		}
+	}
+	return GameSlug(slug), nil
+}
+
+// String returns the game slug as a string.
+func (g GameSlug) String() string {
+	return string(g)
+}
+
+// NewModID returns the ModID of id. Returns an error if id isn't positive, Nexus Mods
+// numbering mods from 1.
+func NewModID(id int64) (ModID, error) {
+	if id <= 0 {
+		return 0, fmt.Errorf("invalid mod id %d: must be a positive number", id)
+	}
+	return ModID(id), nil
+}
+
+// ParseModID returns the ModID of the decimal number s, surrounding whitespace
+// ignored. Returns an error if s isn't a positive number.
+func ParseModID(s string) (ModID, error) {
+	id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid mod id %q", s)
+	}
+	return ModID(id), nil
+}
+
+// Int64 returns the mod ID as an int64, e.g. to store it in the ModID field of the
+// scraped data.
+func (id ModID) Int64() int64 {
+	return int64(id)
+}
+
+// String returns the mod ID in decimal.
+func (id ModID) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// end identifiers related.
+
 // nexus mods related.
 
+// Collection represents a Nexus Mods collection, a curated list of mods installed
+// together, including its name, author, revision, and the mods it contains. Fields
+// are JSON-tagged for proper formatting and may be omitted if empty.
+type Collection struct {
+	Author      string          `json:"Author,omitempty"`
+	Game        string          `json:"Game,omitempty"`
+	LastChecked time.Time       `json:"LastChecked,omitempty"`
+	Mods        []CollectionMod `json:"Mods,omitempty"`
+	Name        string          `json:"Name,omitempty"`
+	Revision    string          `json:"Revision,omitempty"`
+	Slug        string          `json:"Slug,omitempty"`
+	Url         string          `json:"Url,omitempty"`
+}
+
+// CollectionMod represents a mod contained in a collection, including the game it
+// belongs to, its ID, name, the version pinned by the collection, and whether it is
+// optional.
+type CollectionMod struct {
+	Game     string `json:"Game,omitempty"`
+	ModID    int64  `json:"ModID,omitempty"`
+	Name     string `json:"Name,omitempty"`
+	Optional bool   `json:"Optional,omitempty"`
+	Url      string `json:"Url,omitempty"`
+	Version  string `json:"Version,omitempty"`
+}
+
+// GameInfo represents the metadata shown on a game's Nexus Mods landing page, such as
+// the number of mods, collections and downloads, the categories with the number of mods
+// in each, and the most endorsed mods of the month. Fields are JSON-tagged for proper
+// formatting and may be omitted if empty.
+type GameInfo struct {
+	Categories            []TaxonomyEntry `json:"Categories,omitempty"`
+	CollectionCount       int64           `json:"CollectionCount,omitempty"`
+	DownloadCount         int64           `json:"DownloadCount,omitempty"`
+	Game                  string          `json:"Game,omitempty"`
+	LastChecked           time.Time       `json:"LastChecked,omitempty"`
+	ModCount              int64           `json:"ModCount,omitempty"`
+	MostEndorsedThisMonth []GameMod       `json:"MostEndorsedThisMonth,omitempty"`
+	Name                  string          `json:"Name,omitempty"`
+	Url                   string          `json:"Url,omitempty"`
+}
+
+// GameTags represents the taxonomy of a game on Nexus Mods: the categories mods are
+// filed under and the tags they can be filtered by, along with the number of mods in
+// each where the site shows it.
+type GameTags struct {
+	Categories  []TaxonomyEntry `json:"Categories,omitempty"`
+	Game        string          `json:"Game,omitempty"`
+	LastChecked time.Time       `json:"LastChecked,omitempty"`
+	Tags        []TaxonomyEntry `json:"Tags,omitempty"`
+	Url         string          `json:"Url,omitempty"`
+}
+
+// TaxonomyEntry represents a category or tag of a game's taxonomy, including its name,
+// the number of mods it holds when shown, and its link.
+type TaxonomyEntry struct {
+	Count int64  `json:"Count,omitempty"`
+	Name  string `json:"Name,omitempty"`
+	Url   string `json:"Url,omitempty"`
+}
+
+// GameMod represents a mod listed on a game's landing page, including its ID, name,
+// link, and endorsement count.
+type GameMod struct {
+	Endorsements int64  `json:"Endorsements,omitempty"`
+	ModID        int64  `json:"ModID,omitempty"`
+	Name         string `json:"Name,omitempty"`
+	Url          string `json:"Url,omitempty"`
+}
+
+// UserProfile represents the mods uploaded by a Nexus Mods author, as listed on the
+// "Mods" tab of their profile, along with the combined downloads and endorsements of
+// those mods. Fields are JSON-tagged for proper formatting and may be omitted if empty.
+type UserProfile struct {
+	LastChecked       time.Time `json:"LastChecked,omitempty"`
+	Mods              []UserMod `json:"Mods,omitempty"`
+	TotalDownloads    int64     `json:"TotalDownloads,omitempty"`
+	TotalEndorsements int64     `json:"TotalEndorsements,omitempty"`
+	Url               string    `json:"Url,omitempty"`
+	Username          string    `json:"Username,omitempty"`
+}
+
+// ListedMod represents a mod listed on a game's updated mods listing, including the
+// game it belongs to, its ID, name, link, and when it was last updated.
+type ListedMod struct {
+	Game      string     `json:"Game,omitempty"`
+	ModID     int64      `json:"ModID,omitempty"`
+	Name      string     `json:"Name,omitempty"`
+	UpdatedAt *Timestamp `json:"UpdatedAt,omitempty"`
+	Url       string     `json:"Url,omitempty"`
+}
+
+// UserMod represents a mod listed on an author's profile, including the game it
+// belongs to, its ID, name, link, and download and endorsement counts.
+type UserMod struct {
+	Downloads    int64  `json:"Downloads,omitempty"`
+	Endorsements int64  `json:"Endorsements,omitempty"`
+	Game         string `json:"Game,omitempty"`
+	ModID        int64  `json:"ModID,omitempty"`
+	Name         string `json:"Name,omitempty"`
+	Url          string `json:"Url,omitempty"`
+}
+
 // Results defines the structure for storing the scraping results, which includes
-// a ModInfo object under the key "Mods" in the JSON output.
+// a ModInfo object under the key "Mods" in the JSON output, and the metadata of the
+// scrape that produced it under "ScrapeMeta" when it is recorded.
 type Results struct {
-	Mods ModInfo `json:"Mods"`
+	Meta *ScrapeMeta `json:"ScrapeMeta,omitempty"`
+	Mods ModInfo     `json:"Mods"`
+}
+
+// ScrapeMeta describes the scrape that produced saved results: the version of the
+// scraper and of the ModInfo schema, when the mod was scraped and from which URL, and
+// how long the scrape took, so that archived results remain interpretable.
+type ScrapeMeta struct {
+	Duration       string     `json:"Duration"`
+	SchemaVersion  int        `json:"SchemaVersion"`
+	ScrapedAt      *Timestamp `json:"ScrapedAt"`
+	ScraperVersion string     `json:"ScraperVersion"`
+	SourceUrl      string     `json:"SourceUrl"`
 }
 
-// ModInfo represents detailed information about a mod, including its changelogs,
-// creator, dependencies, description, files, timestamps, versioning, tags, uploader,
-// URL, and virus status. Fields are JSON-tagged for proper formatting and may be omitted
-// if empty.
+// SchemaVersion is the version of the ModInfo schema produced by the extractors. It is
+// bumped whenever the fields of ModInfo, or of the types it holds, change, so that saved
+// files can record which schema produced them.
+const SchemaVersion = 1
+
+// ModInfo represents detailed information about a mod, including its category,
+// changelogs, creator, requirements, description, files, timestamps, versioning,
+// permissions and credits, popularity statistics, tags, translations, uploader, URL,
+// and virus status. When no file sets a version, a version guessed from the name or
+// description is given in LatestVersionGuess along with its confidence (high, medium or
+// low). A mod that is no longer available has its Status set to one of the ModStatus
+// constants. Fields are JSON-tagged for proper formatting and may be omitted if empty.
 type ModInfo struct {
-	ChangeLogs       []ChangeLog   `json:"ChangeLogs,omitempty"`
-	Creator          string        `json:"Creator,omitempty"`
-	Dependencies     []Requirement `json:"Dependencies,omitempty"`
-	Description      string        `json:"Description,omitempty"`
-	Files            []File        `json:"Files,omitempty"`
-	LastChecked      time.Time     `json:"LastChecked,omitempty"`
-	LastUpdated      string        `json:"LastUpdated,omitempty"`
-	LatestVersion    string        `json:"LatestVersion,omitempty"`
-	ModID            int64         `json:"ModID,omitempty"`
-	ModsUsing        []Requirement `json:"ModsUsing,omitempty"`
-	Name             string        `json:"Name,omitempty"`
-	OriginalUpload   string        `json:"OriginalUpload,omitempty"`
-	ShortDescription string        `json:"ShortDescription,omitempty"`
-	Tags             []string      `json:"Tags,omitempty"`
-	Uploader         string        `json:"Uploader,omitempty"`
-	Url              string        `json:"Url,omitempty"`
-	VirusStatus      string        `json:"VirusStatus,omitempty"`
+	Category                     string        `json:"Category,omitempty"`
+	ChangeLogs                   []ChangeLog   `json:"ChangeLogs,omitempty"`
+	Creator                      string        `json:"Creator,omitempty"`
+	DLCRequirements              []Requirement `json:"DLCRequirements,omitempty"`
+	Dependencies                 []Requirement `json:"Dependencies,omitempty"`
+	Description                  string        `json:"Description,omitempty"`
+	Endorsements                 string        `json:"Endorsements,omitempty"`
+	Files                        []File        `json:"Files,omitempty"`
+	LastChecked                  time.Time     `json:"LastChecked,omitempty"`
+	LastUpdated                  string        `json:"LastUpdated,omitempty"`
+	LastUpdatedAt                *Timestamp    `json:"LastUpdatedAt,omitempty"`
+	LatestVersion                string        `json:"LatestVersion,omitempty"`
+	LatestVersionGuess           string        `json:"LatestVersionGuess,omitempty"`
+	LatestVersionGuessConfidence string        `json:"LatestVersionGuessConfidence,omitempty"`
+	ModID                        int64         `json:"ModID,omitempty"`
+	ModsUsing                    []Requirement `json:"ModsUsing,omitempty"`
+	Name                         string        `json:"Name,omitempty"`
+	OffSiteRequirements          []Requirement `json:"OffSiteRequirements,omitempty"`
+	OriginalUpload               string        `json:"OriginalUpload,omitempty"`
+	OriginalUploadAt             *Timestamp    `json:"OriginalUploadAt,omitempty"`
+	Permissions                  *Permissions  `json:"Permissions,omitempty"`
+	ShortDescription             string        `json:"ShortDescription,omitempty"`
+	Status                       string        `json:"Status,omitempty"`
+	Tags                         []string      `json:"Tags,omitempty"`
+	TotalDLs                     string        `json:"TotalDLs,omitempty"`
+	TotalViews                   string        `json:"TotalViews,omitempty"`
+	Translations                 []Translation `json:"Translations,omitempty"`
+	UniqueDLs                    string        `json:"UniqueDLs,omitempty"`
+	Uploader                     string        `json:"Uploader,omitempty"`
+	Url                          string        `json:"Url,omitempty"`
+	Version                      string        `json:"Version,omitempty"`
+	VirusStatus                  string        `json:"VirusStatus,omitempty"`
 }
 
+// Statuses of a mod that is no longer available, recorded in ModInfo.Status. An
+// available mod has no status.
+const (
+	// ModStatusHidden is a mod hidden by its author or by the moderators.
+	ModStatusHidden = "hidden"
+	// ModStatusNotFound is a mod whose page doesn't exist.
+	ModStatusNotFound = "not_found"
+	// ModStatusRemoved is a mod removed by its author.
+	ModStatusRemoved = "removed"
+)
+
 // ChangeLog represents a mod's changelog, including the version and a list of notes.
+// When requested, the detected language of each note is listed in NoteLanguages, in
+// the same order as the notes.
 type ChangeLog struct {
-	Notes   []string `json:"Notes,omitempty"`
-	Version string   `json:"Version,omitempty"`
+	NoteLanguages []string `json:"NoteLanguages,omitempty"`
+	Notes         []string `json:"Notes,omitempty"`
+	Version       string   `json:"Version,omitempty"`
+}
+
+// Permissions represents the "Permissions and credits" section of a mod page: the
+// permission rules set by the author, along with the author notes, credits and
+// donation points text.
+type Permissions struct {
+	AuthorNotes    string       `json:"AuthorNotes,omitempty"`
+	Credits        string       `json:"Credits,omitempty"`
+	DonationPoints string       `json:"DonationPoints,omitempty"`
+	Rules          []Permission `json:"Rules,omitempty"`
 }
 
-// Requirement represents a mod requirement, including the name of the required mod
-// and any additional notes.
+// Permission represents a single permission rule, such as "Upload permission", with
+// its status (yes, no or maybe) and the description given by the author.
+type Permission struct {
+	Description string `json:"Description,omitempty"`
+	Status      string `json:"Status,omitempty"`
+	Title       string `json:"Title,omitempty"`
+}
+
+// Translation represents a localized version of a mod published as a separate mod,
+// including its language, name, link and mod ID.
+type Translation struct {
+	Language string `json:"Language,omitempty"`
+	ModID    int64  `json:"ModID,omitempty"`
+	Name     string `json:"Name,omitempty"`
+	Url      string `json:"Url,omitempty"`
+}
+
+// Requirement represents a mod requirement, including the name of the required mod,
+// DLC or off-site resource, any additional notes, and the link to it when there is one.
+// The notes are flattened to text, with the links they contain, such as to a specific
+// version of the requirement, listed in NotesLinks.
 type Requirement struct {
-	Name  string `json:"Name,omitempty"`
-	Notes string `json:"Notes,omitempty"`
+	Name       string `json:"Name,omitempty"`
+	Notes      string `json:"Notes,omitempty"`
+	NotesLinks []Link `json:"NotesLinks,omitempty"`
+	Url        string `json:"Url,omitempty"`
+}
+
+// Link represents a link found in scraped text, with its text and URL.
+type Link struct {
+	Text string `json:"Text,omitempty"`
+	Url  string `json:"Url,omitempty"`
 }
 
 // Tag represents a tag associated with a mod, containing a single tag string.
@@ -77,16 +459,135 @@ type Tag struct {
 	Tag string `json:"Tag,omitempty"`
 }
 
-// File represents details about a mod file, including its description, file size,
-// name, download statistics, upload date, and version.
+// File represents details about a mod file, including its description, download link,
+// file ID, file size, name, download statistics, upload date, and version. The size and
+// download statistics are kept as displayed and, when they can be parsed, as numbers in
+// FileSizeBytes, TotalDLCount and UniqueDLCount for sorting and aggregating.
 type File struct {
-	Description string `json:"description"`
-	FileSize    string `json:"fileSize"`
-	Name        string `json:"name"`
-	TotalDLs    string `json:"totalDownloads"`
-	UniqueDLs   string `json:"uniqueDownloads"`
-	UploadDate  string `json:"uploadDate"`
-	Version     string `json:"version"`
+	Category      string `json:"category,omitempty"`
+	Description   string `json:"description"`
+	DownloadUrl   string `json:"downloadUrl,omitempty"`
+	FileID        int64  `json:"fileId,omitempty"`
+	FileSize      string `json:"fileSize"`
+	FileSizeBytes int64  `json:"fileSizeBytes,omitempty"`
+	MD5           string `json:"md5,omitempty"`
+	Name          string `json:"name"`
+	TotalDLCount  int64  `json:"totalDownloadCount,omitempty"`
+	TotalDLs      string `json:"totalDownloads"`
+	UniqueDLCount int64  `json:"uniqueDownloadCount,omitempty"`
+	UniqueDLs     string `json:"uniqueDownloads"`
+	UploadDate    string `json:"uploadDate"`
+	Version       string `json:"version"`
+}
+
+// TimestampFormat controls how Timestamp values are serialized to JSON. It holds a Go
+// time layout, or "unix" to serialize as seconds since the epoch. Defaults to RFC3339.
+var TimestampFormat = time.RFC3339
+
+// Timestamp wraps a time.Time parsed from a Nexus Mods page so that its JSON
+// representation follows TimestampFormat.
+type Timestamp struct {
+	time.Time
+}
+
+// NewTimestamp returns a pointer to a Timestamp wrapping the provided time.
+func NewTimestamp(t time.Time) *Timestamp {
+	return &Timestamp{Time: t}
+}
+
+// MarshalJSON serializes the timestamp using TimestampFormat, writing a number for
+// the "unix" format and a quoted string otherwise.
+func (t Timestamp) MarshalJSON() ([]byte, error) {
+	if TimestampFormat == "unix" {
+		return []byte(strconv.FormatInt(t.Unix(), 10)), nil
+	}
+	return json.Marshal(t.Format(TimestampFormat))
+}
+
+// UnmarshalJSON reads a timestamp written by MarshalJSON. It accepts seconds since
+// the epoch, or a string in TimestampFormat or RFC3339.
+func (t *Timestamp) UnmarshalJSON(data []byte) error {
+	if seconds, err := strconv.ParseInt(string(data), 10, 64); err == nil {
+		t.Time = time.Unix(seconds, 0).UTC()
+		return nil
+	}
+
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := time.Parse(TimestampFormat, value)
+	if err != nil {
+		if parsed, err = time.Parse(time.RFC3339, value); err != nil {
+			return err
+		}
+	}
+	t.Time = parsed
+	return nil
+}
+
+// KeepEmptyFields controls how the lists of a mod are serialized to JSON. When false,
+// empty lists are left out of the output; when true, they are written as [] so that
+// consumers always find every list field.
+var KeepEmptyFields = false
+
+// MarshalJSON serializes the mod, writing its empty lists as [] when KeepEmptyFields
+// is set.
+func (m ModInfo) MarshalJSON() ([]byte, error) {
+	type modInfo ModInfo
+	return marshalKeepingEmptyLists(modInfo(m))
+}
+
+// MarshalJSON serializes the changelog, writing its empty lists as [] when
+// KeepEmptyFields is set.
+func (c ChangeLog) MarshalJSON() ([]byte, error) {
+	type changeLog ChangeLog
+	return marshalKeepingEmptyLists(changeLog(c))
+}
+
+// MarshalJSON serializes the requirement, writing an empty list of note links as []
+// when KeepEmptyFields is set.
+func (r Requirement) MarshalJSON() ([]byte, error) {
+	type requirement Requirement
+	return marshalKeepingEmptyLists(requirement(r))
+}
+
+// MarshalJSON serializes the permissions, writing an empty list of rules as [] when
+// KeepEmptyFields is set.
+func (p Permissions) MarshalJSON() ([]byte, error) {
+	type permissions Permissions
+	return marshalKeepingEmptyLists(permissions(p))
+}
+
+// marshalKeepingEmptyLists serializes the struct value as usual and, when
+// KeepEmptyFields is set, adds back the slice fields omitempty left out as []. The
+// fields of the types it is used for are declared in alphabetical order, so the keys
+// keep their order when the object is rebuilt.
+func marshalKeepingEmptyLists(value any) ([]byte, error) {
+	data, err := json.Marshal(value)
+	if err != nil || !KeepEmptyFields {
+		return data, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	structType := reflect.TypeOf(value)
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if field.Type.Kind() != reflect.Slice || name == "" || name == "-" {
+			continue
+		}
+		if _, ok := fields[name]; !ok {
+			fields[name] = json.RawMessage("[]")
+		}
+	}
+
+	return json.Marshal(fields)
 }
 
 // end nexus mods related.
@@ -1,7 +1,13 @@
 package types
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -19,8 +25,8 @@ func TestNewScraper(t *testing.T) {
 	assert.Equal(t, "", scraper.CookieDirectory)
 	assert.Equal(t, "", scraper.CookieFile)
 	assert.False(t, scraper.DisplayResults)
-	assert.Equal(t, "", scraper.GameName)
-	assert.Equal(t, int64(0), scraper.ModID)
+	assert.Equal(t, GameSlug(""), scraper.GameName)
+	assert.Equal(t, ModID(0), scraper.ModID)
 	assert.Equal(t, "", scraper.OutputDirectory)
 	assert.False(t, scraper.SaveResults)
 	assert.Empty(t, scraper.ValidCookies)
@@ -127,3 +133,249 @@ func TestFileStruct(t *testing.T) {
 	}`
 	assert.JSONEq(t, expectedJSON, string(data))
 }
+
+func TestTimestampJSON(t *testing.T) {
+	ts := NewTimestamp(time.Date(2024, time.October, 13, 10, 44, 0, 0, time.UTC))
+	defer func(format string) { TimestampFormat = format }(TimestampFormat)
+
+	tests := []struct {
+		format   string
+		expected string
+	}{
+		{time.RFC3339, `"2024-10-13T10:44:00Z"`},
+		{time.DateOnly, `"2024-10-13"`},
+		{"unix", `1728816240`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			TimestampFormat = tt.format
+
+			// Act
+			data, err := json.Marshal(ts)
+			assert.NoError(t, err)
+
+			var decoded Timestamp
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+
+			// Assert
+			assert.Equal(t, tt.expected, string(data))
+			assert.Equal(t, ts.Format(tt.format), decoded.Format(tt.format))
+		})
+	}
+}
+
+func TestModInfoJSON_KeepEmptyFields(t *testing.T) {
+	defer func(keep bool) { KeepEmptyFields = keep }(KeepEmptyFields)
+	mod := ModInfo{
+		ChangeLogs:  []ChangeLog{{Notes: []string{"Fixed a bug"}, Version: "1.1"}},
+		Name:        "Test Mod",
+		Permissions: &Permissions{Credits: "Everyone"},
+		Tags:        []string{},
+	}
+
+	tests := []struct {
+		keep     bool
+		expected string
+	}{
+		{false, `{
+			"ChangeLogs": [{"Notes": ["Fixed a bug"], "Version": "1.1"}],
+			"LastChecked": "0001-01-01T00:00:00Z",
+			"Name": "Test Mod",
+			"Permissions": {"Credits": "Everyone"}
+		}`},
+		{true, `{
+			"ChangeLogs": [{"NoteLanguages": [], "Notes": ["Fixed a bug"], "Version": "1.1"}],
+			"DLCRequirements": [],
+			"Dependencies": [],
+			"Files": [],
+			"LastChecked": "0001-01-01T00:00:00Z",
+			"ModsUsing": [],
+			"Name": "Test Mod",
+			"OffSiteRequirements": [],
+			"Permissions": {"Credits": "Everyone", "Rules": []},
+			"Tags": [],
+			"Translations": []
+		}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(strconv.FormatBool(tt.keep), func(t *testing.T) {
+			KeepEmptyFields = tt.keep
+
+			// Act
+			data, err := json.Marshal(Results{Mods: mod})
+			assert.NoError(t, err)
+
+			var decoded Results
+			assert.NoError(t, json.Unmarshal(data, &decoded))
+
+			// Assert
+			assert.JSONEq(t, `{"Mods": `+tt.expected+`}`, string(data))
+			assert.Equal(t, mod.Name, decoded.Mods.Name)
+		})
+	}
+}
+
+func TestRequirementJSON(t *testing.T) {
+	defer func(keep bool) { KeepEmptyFields = keep }(KeepEmptyFields)
+	requirement := Requirement{
+		Name:       "SkyUI",
+		Notes:      "Use version 5.1",
+		NotesLinks: []Link{{Text: "version 5.1", Url: "https://example.com/skyui?file_id=1"}},
+	}
+
+	// Act
+	data, err := json.Marshal(requirement)
+	KeepEmptyFields = true
+	empty, emptyErr := json.Marshal(Requirement{Name: "SKSE64"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"Name": "SkyUI",
+		"Notes": "Use version 5.1",
+		"NotesLinks": [{"Text": "version 5.1", "Url": "https://example.com/skyui?file_id=1"}]
+	}`, string(data))
+	assert.NoError(t, emptyErr)
+	assert.JSONEq(t, `{"Name": "SKSE64", "NotesLinks": []}`, string(empty))
+}
+
+func TestModInfoJSON_KeepEmptyFieldsOrder(t *testing.T) {
+	defer func(keep bool) { KeepEmptyFields = keep }(KeepEmptyFields)
+	KeepEmptyFields = true
+
+	// Act
+	data, err := json.Marshal(ModInfo{Name: "Test Mod", Version: "1.0"})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Less(t, strings.Index(string(data), `"ChangeLogs"`), strings.Index(string(data), `"Name"`))
+	assert.Less(t, strings.Index(string(data), `"Name"`), strings.Index(string(data), `"Version"`))
+}
+
+func TestCookieCandidateHasAll(t *testing.T) {
+	candidate := CookieCandidate{Cookies: map[string]string{"session": "1", "refresh": "2"}}
+
+	assert.True(t, candidate.HasAll([]string{"session", "refresh"}))
+	assert.True(t, candidate.HasAll(nil))
+	assert.False(t, candidate.HasAll([]string{"session", "missing"}))
+}
+
+func TestParseGameSlug(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected GameSlug
+		err      string
+	}{
+		{name: "lowercased and trimmed", input: " SkyrimSpecialEdition ", expected: "skyrimspecialedition"},
+		{name: "dashes and underscores", input: "mount-and_blade2", expected: "mount-and_blade2"},
+		{name: "empty", input: "  ", err: "game is required"},
+		{name: "path separator", input: "../skyrim", err: `invalid game "../skyrim": only letters, digits, - and _ are allowed`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slug, err := ParseGameSlug(tt.input)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, slug)
+			assert.Equal(t, string(tt.expected), slug.String())
+		})
+	}
+}
+
+func TestParseModID(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected ModID
+		err      string
+	}{
+		{input: "3863", expected: 3863},
+		{input: " 42 ", expected: 42},
+		{input: "0", err: `invalid mod id "0"`},
+		{input: "-1", err: `invalid mod id "-1"`},
+		{input: "abc", err: `invalid mod id "abc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			id, err := ParseModID(tt.input)
+
+			if tt.err != "" {
+				assert.EqualError(t, err, tt.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, id)
+		})
+	}
+}
+
+func TestNewModID(t *testing.T) {
+	id, err := NewModID(3863)
+	_, zeroErr := NewModID(0)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3863), id.Int64())
+	assert.Equal(t, "3863", id.String())
+	assert.EqualError(t, zeroErr, "invalid mod id 0: must be a positive number")
+}
+
+// schemaFields returns a "path type" line for every JSON field of t, following the
+// structs, pointers, slices and maps it holds.
+func schemaFields(t reflect.Type, prefix string, seen map[reflect.Type]bool) []string {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || seen[t] || t == reflect.TypeOf(time.Time{}) {
+		return nil
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		path := prefix + "." + name
+		fields = append(fields, path+" "+field.Type.String())
+		fields = append(fields, schemaFields(field.Type, path, seen)...)
+	}
+	return fields
+}
+
+func TestSchemaVersion(t *testing.T) {
+	// A change of this checksum means the fields of ModInfo changed: bump SchemaVersion
+	// and update the checksum and version below
+	const (
+		wantChecksum = "959690a65d319920195980861f038d6ddde209ccaccdb2ec7726515bc8f1069b"
+		wantVersion  = 1
+	)
+
+	// Arrange
+	fields := schemaFields(reflect.TypeOf(ModInfo{}), "ModInfo", map[reflect.Type]bool{})
+	sort.Strings(fields)
+	sum := sha256.Sum256([]byte(strings.Join(fields, "\n")))
+
+	// Act
+	checksum := hex.EncodeToString(sum[:])
+
+	// Assert
+	assert.Equal(t, wantChecksum, checksum, "the fields of ModInfo changed, bump SchemaVersion")
+	assert.Equal(t, wantVersion, SchemaVersion)
+}
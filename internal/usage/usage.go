@@ -0,0 +1,177 @@
+package usage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+const (
+	// DateLayout is the layout of the day each usage bucket covers, in local time.
+	DateLayout = "2006-01-02"
+	// DefaultFilename is the name of the usage stats file stored in the data directory.
+	DefaultFilename = "usage.json"
+)
+
+// Counters holds the usage tallied over a run or a day. Cache hits are pages served
+// from local archives, such as those re-parsed by the reparse command, rather than
+// requested from the site.
+type Counters struct {
+	BytesFetched int64 `json:"BytesFetched"`
+	CacheHits    int64 `json:"CacheHits"`
+	ModsFailed   int64 `json:"ModsFailed"`
+	ModsScraped  int64 `json:"ModsScraped"`
+	Requests     int64 `json:"Requests"`
+}
+
+// Day is the usage recorded over a single day, along with the number of runs.
+type Day struct {
+	Counters
+	Date string `json:"Date"`
+	Runs int64  `json:"Runs"`
+}
+
+// Stats is the content of the usage stats file: the usage of every day with activity,
+// oldest first.
+type Stats struct {
+	Days []Day `json:"Days"`
+}
+
+// current holds the usage of the running command, fed by the fetchers and the scrape
+// pipeline and taken once the command is done.
+var current struct {
+	bytesFetched atomic.Int64
+	cacheHits    atomic.Int64
+	modsFailed   atomic.Int64
+	modsScraped  atomic.Int64
+	requests     atomic.Int64
+}
+
+// AddFetch counts a request to the site and the size of the body it returned.
+func AddFetch(bytes int64) {
+	current.requests.Add(1)
+	current.bytesFetched.Add(bytes)
+}
+
+// AddCacheHit counts a page served from a local archive instead of the site.
+func AddCacheHit() {
+	current.cacheHits.Add(1)
+}
+
+// AddMods counts the mods scraped successfully and the mods that failed.
+func AddMods(scraped, failed int) {
+	current.modsScraped.Add(int64(scraped))
+	current.modsFailed.Add(int64(failed))
+}
+
+// Take returns the usage counted since the last call and resets the counters.
+func Take() Counters {
+	return Counters{
+		BytesFetched: current.bytesFetched.Swap(0),
+		CacheHits:    current.cacheHits.Swap(0),
+		ModsFailed:   current.modsFailed.Swap(0),
+		ModsScraped:  current.modsScraped.Swap(0),
+		Requests:     current.requests.Swap(0),
+	}
+}
+
+// IsZero reports whether nothing was counted.
+func (c Counters) IsZero() bool {
+	return c == Counters{}
+}
+
+// CacheHitRate returns the share of pages served from local archives out of every
+// page read, between 0 and 1, or 0 when no page was read.
+func (c Counters) CacheHitRate() float64 {
+	pages := c.CacheHits + c.Requests
+	if pages == 0 {
+		return 0
+	}
+	return float64(c.CacheHits) / float64(pages)
+}
+
+// add returns the sum of both counters.
+func (c Counters) add(other Counters) Counters {
+	return Counters{
+		BytesFetched: c.BytesFetched + other.BytesFetched,
+		CacheHits:    c.CacheHits + other.CacheHits,
+		ModsFailed:   c.ModsFailed + other.ModsFailed,
+		ModsScraped:  c.ModsScraped + other.ModsScraped,
+		Requests:     c.Requests + other.Requests,
+	}
+}
+
+// Total returns the usage summed over every day. Its date is the first day recorded.
+func (s Stats) Total() Day {
+	var total Day
+	for _, day := range s.Days {
+		total.Counters = total.Counters.add(day.Counters)
+		total.Runs += day.Runs
+	}
+	if len(s.Days) > 0 {
+		total.Date = s.Days[0].Date
+	}
+	return total
+}
+
+// Load reads the usage stats file at path. A missing file is not an error and yields
+// empty stats.
+func Load(path string) (Stats, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Stats{}, nil
+	}
+	if err != nil {
+		return Stats{}, fmt.Errorf("error reading usage stats: %w", err)
+	}
+
+	var stats Stats
+	if err := json.Unmarshal(data, &stats); err != nil {
+		return Stats{}, fmt.Errorf("error decoding usage stats %s: %w", path, err)
+	}
+	return stats, nil
+}
+
+// Save writes the usage stats to path as indented JSON, creating its directory if
+// needed.
+func Save(path string, stats Stats) error {
+	if err := fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting usage stats: %w", err)
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
+
+// Record adds a run with the provided usage to the day of now in the usage stats file
+// at path, creating the file if needed.
+func Record(path string, counters Counters, now time.Time) error {
+	stats, err := Load(path)
+	if err != nil {
+		return err
+	}
+
+	date := now.Local().Format(DateLayout)
+	index := sort.Search(len(stats.Days), func(i int) bool { return stats.Days[i].Date >= date })
+	if index == len(stats.Days) || stats.Days[index].Date != date {
+		stats.Days = append(stats.Days, Day{})
+		copy(stats.Days[index+1:], stats.Days[index:])
+		stats.Days[index] = Day{Date: date}
+	}
+	stats.Days[index].Counters = stats.Days[index].Counters.add(counters)
+	stats.Days[index].Runs++
+
+	return Save(path, stats)
+}
@@ -0,0 +1,62 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTake(t *testing.T) {
+	// Arrange
+	Take()
+	AddFetch(100)
+	AddFetch(50)
+	AddCacheHit()
+	AddMods(2, 1)
+
+	// Act
+	counters := Take()
+
+	// Assert
+	assert.Equal(t, Counters{BytesFetched: 150, CacheHits: 1, ModsFailed: 1, ModsScraped: 2, Requests: 2}, counters)
+	assert.True(t, Take().IsZero())
+}
+
+func TestCounters_CacheHitRate(t *testing.T) {
+	assert.Equal(t, 0.25, Counters{CacheHits: 1, Requests: 3}.CacheHitRate())
+	assert.Equal(t, 0.0, Counters{}.CacheHitRate())
+}
+
+func TestRecord(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	path := "data/" + DefaultFilename
+	first := time.Date(2024, time.June, 2, 12, 0, 0, 0, time.Local)
+	earlier := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.Local)
+
+	// Act
+	require.NoError(t, Record(path, Counters{ModsScraped: 2, Requests: 4, BytesFetched: 1000}, first))
+	require.NoError(t, Record(path, Counters{ModsScraped: 1, Requests: 2}, first))
+	require.NoError(t, Record(path, Counters{CacheHits: 3}, earlier))
+	stats, err := Load(path)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []Day{
+		{Counters: Counters{CacheHits: 3}, Date: "2024-06-01", Runs: 1},
+		{Counters: Counters{BytesFetched: 1000, ModsScraped: 3, Requests: 6}, Date: "2024-06-02", Runs: 2},
+	}, stats.Days)
+	assert.Equal(t, Day{Counters: Counters{BytesFetched: 1000, CacheHits: 3, ModsScraped: 3, Requests: 6}, Date: "2024-06-01", Runs: 3}, stats.Total())
+}
+
+func TestLoad_Missing(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+
+	stats, err := Load("missing.json")
+
+	assert.NoError(t, err)
+	assert.Empty(t, stats.Days)
+}
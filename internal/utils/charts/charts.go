@@ -0,0 +1,91 @@
+package charts
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Bar is a single labelled value rendered by BarChart.
+type Bar struct {
+	Label string
+	Value float64
+}
+
+// BarChart renders a horizontal bar chart to w, with a title line followed by one
+// row per bar. Bars are scaled so that the largest value spans width characters,
+// and each row ends with the formatted value.
+func BarChart(w io.Writer, title string, bars []Bar, width int) error {
+	if width <= 0 {
+		width = 40
+	}
+
+	if _, err := fmt.Fprintln(w, title); err != nil {
+		return err
+	}
+
+	if len(bars) == 0 {
+		_, err := fmt.Fprintln(w, "  (no data)")
+		return err
+	}
+
+	labelWidth, maxValue := 0, 0.0
+	for _, bar := range bars {
+		labelWidth = max(labelWidth, len(bar.Label))
+		maxValue = math.Max(maxValue, bar.Value)
+	}
+
+	for _, bar := range bars {
+		length := 0
+		if maxValue > 0 && bar.Value > 0 {
+			length = max(1, int(math.Round(bar.Value/maxValue*float64(width))))
+		}
+
+		if _, err := fmt.Fprintf(w, "  %-*s │%s %s\n", labelWidth, bar.Label, strings.Repeat("█", length), formatValue(bar.Value)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sparkBlocks are the block characters of a sparkline, from the lowest to the highest
+// value.
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline renders the values as a single line of block characters, scaled between
+// the lowest and the highest value, keeping only the last width values when there are
+// more. Values that are all equal render as the lowest block.
+func Sparkline(values []float64, width int) string {
+	if width > 0 && len(values) > width {
+		values = values[len(values)-width:]
+	}
+	if len(values) == 0 {
+		return ""
+	}
+
+	low, high := values[0], values[0]
+	for _, value := range values {
+		low = math.Min(low, value)
+		high = math.Max(high, value)
+	}
+
+	var line strings.Builder
+	for _, value := range values {
+		level := 0
+		if high > low {
+			level = int(math.Round((value - low) / (high - low) * float64(len(sparkBlocks)-1)))
+		}
+		line.WriteRune(sparkBlocks[level])
+	}
+	return line.String()
+}
+
+// formatValue renders a bar value without a fractional part when it is a whole number.
+func formatValue(value float64) string {
+	if value == math.Trunc(value) {
+		return fmt.Sprintf("%.0f", value)
+	}
+	return fmt.Sprintf("%.2f", value)
+}
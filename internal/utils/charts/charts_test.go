@@ -0,0 +1,69 @@
+package charts
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarChart(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	bars := []Bar{
+		{Label: "week 1", Value: 4},
+		{Label: "week 2", Value: 2},
+		{Label: "wk 3", Value: 0},
+	}
+
+	// Act
+	err := BarChart(&buf, "Updates", bars, 4)
+
+	// Assert
+	assert.NoError(t, err)
+	expected := "Updates\n" +
+		"  week 1 │████ 4\n" +
+		"  week 2 │██ 2\n" +
+		"  wk 3   │ 0\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+func TestBarChart_NoData(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := BarChart(&buf, "Updates", nil, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Updates\n  (no data)\n", buf.String())
+}
+
+func TestBarChart_FractionalValues(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := BarChart(&buf, "Ratio", []Bar{{Label: "a", Value: 0.5}}, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Ratio\n  a │██ 0.50\n", buf.String())
+}
+
+func TestSparkline(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		width    int
+		expected string
+	}{
+		{"rising", []float64{0, 1, 2, 3, 4, 5, 6, 7}, 0, "▁▂▃▄▅▆▇█"},
+		{"last values kept", []float64{100, 0, 7}, 2, "▁█"},
+		{"flat", []float64{5, 5, 5}, 10, "▁▁▁"},
+		{"empty", nil, 10, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sparkline(tt.values, tt.width); got != tt.expected {
+				t.Errorf("Sparkline(%v, %d) = %q, expected %q", tt.values, tt.width, got, tt.expected)
+			}
+		})
+	}
+}
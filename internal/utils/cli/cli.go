@@ -2,13 +2,14 @@ package cli
 
 import (
 	"reflect"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 // RegisterFlag registers a command-line flag for a Cobra command based on the provided
 // name, shorthand, value, usage description, and target variable. It supports bool,
-// string, float64, int, and string slice types, ensuring the target is a pointer.
+// string, float64, int, duration, and string slice types, ensuring the target is a pointer.
 // If the value type is unsupported, the function panics.
 func RegisterFlag(cmd *cobra.Command, name, shorthand string, value interface{}, usage string, target interface{}) {
 	targetValue := reflect.ValueOf(target)
@@ -31,7 +32,7 @@ func RegisterFlag(cmd *cobra.Command, name, shorthand string, value interface{},
 		}
 	case string:
 		usage += "\n"
-	case float64, int, []string:
+	case float64, int, time.Duration, []string:
 		usage += "\n"
 	default:
 		panic("unsupported flag type")
@@ -47,6 +48,12 @@ func RegisterFlag(cmd *cobra.Command, name, shorthand string, value interface{},
 		cmd.Flags().Float64VarP(target.(*float64), name, shorthand, value.(float64), usage)
 	case reflect.Int:
 		cmd.Flags().IntVarP(target.(*int), name, shorthand, value.(int), usage)
+	case reflect.Int64:
+		if targetValue.Elem().Type() == reflect.TypeOf(time.Duration(0)) {
+			cmd.Flags().DurationVarP(target.(*time.Duration), name, shorthand, value.(time.Duration), usage)
+		} else {
+			panic("unsupported flag type")
+		}
 	case reflect.Slice:
 		if targetValue.Elem().Type().Elem().Kind() == reflect.String {
 			cmd.Flags().StringSliceVarP(target.(*[]string), name, shorthand, value.([]string), usage)
@@ -57,3 +64,13 @@ func RegisterFlag(cmd *cobra.Command, name, shorthand string, value interface{},
 		panic("unsupported flag type")
 	}
 }
+
+// RegisterFlagValues registers the values suggested by shell completion for the named
+// flag of a Cobra command, e.g. the formats a --format flag accepts, files not being
+// suggested. The function panics if the command has no such flag or its completion is
+// already registered.
+func RegisterFlagValues(cmd *cobra.Command, name string, values ...string) {
+	if err := cmd.RegisterFlagCompletionFunc(name, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp)); err != nil {
+		panic(err)
+	}
+}
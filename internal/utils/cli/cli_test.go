@@ -2,6 +2,7 @@ package cli
 
 import (
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -92,6 +93,23 @@ func TestRegisterFlag_StringSliceFlag(t *testing.T) {
 	assert.Equal(t, "[file1,file2]", flag.DefValue)
 }
 
+func TestRegisterFlag_DurationFlag(t *testing.T) {
+	// Arrange
+	var durationTarget time.Duration
+	cmd := &cobra.Command{}
+
+	// Act
+	RegisterFlag(cmd, "timeout", "t", 30*time.Second, "Request timeout", &durationTarget)
+
+	// Assert
+	flag := cmd.Flags().Lookup("timeout")
+	require.NotNil(t, flag)
+	assert.Equal(t, "timeout", flag.Name)
+	assert.Equal(t, "t", flag.Shorthand)
+	assert.Equal(t, "Request timeout\n", flag.Usage)
+	assert.Equal(t, "30s", flag.DefValue)
+}
+
 func TestRegisterFlag_PanicOnNonPointerTarget(t *testing.T) {
 	// Arrange
 	var stringTarget string
@@ -113,3 +131,21 @@ func TestRegisterFlag_PanicOnUnsupportedType(t *testing.T) {
 		RegisterFlag(cmd, "config", "c", map[string]string{}, "Unsupported type", &unsupportedTarget)
 	})
 }
+
+func TestRegisterFlagValues(t *testing.T) {
+	// Arrange
+	var format string
+	cmd := &cobra.Command{}
+	RegisterFlag(cmd, "format", "", "json", "Output format", &format)
+
+	// Act
+	RegisterFlagValues(cmd, "format", "json", "dot")
+
+	// Assert
+	complete, ok := cmd.GetFlagCompletionFunc("format")
+	require.True(t, ok)
+	values, directive := complete(cmd, nil, "")
+	assert.Equal(t, []string{"json", "dot"}, values)
+	assert.Equal(t, cobra.ShellCompDirectiveNoFileComp, directive)
+	assert.Panics(t, func() { RegisterFlagValues(cmd, "unknown", "a") })
+}
@@ -5,12 +5,20 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
 
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
-	"github.com/savioxavier/termlink"
 )
 
+// SnapshotLayout is the time layout of the timestamp ending snapshot file names. It
+// avoids colons so the names are valid on every platform.
+const SnapshotLayout = "2006-01-02T15-04"
+
 // DisplayResults formats and displays the scraped mod results. It takes command-line flags,
 // the results to be displayed, and a formatting function to convert mod information into
 // a JSON string. Returns an error if formatting fails.
@@ -54,7 +62,7 @@ func SaveCookiesToJson(dir string, filename string, data interface{}, openFileFu
 	if err != nil {
 		return err
 	}
-	fmt.Printf("Extracted cookies saved to %s\n", termlink.ColorLink(fullPath, fullPath, "green"))
+	fmt.Fprintf(formatters.Status(), "Extracted cookies saved to %s\n", formatters.PathLink(fullPath))
 	return nil
 }
 
@@ -78,10 +86,153 @@ func SaveModInfoToJson(sc types.CliFlags, data interface{}, dir, filename string
 	}
 
 	// Write the JSON data to the file
-	err = os.WriteFile(fullPath, jsonData, 0644)
+	err = fsys.Default.WriteFile(fullPath, jsonData, 0644)
 	if err != nil {
 		return "", fmt.Errorf("error saving file: %s - %v", fullPath, err)
 	}
 
 	return fullPath, nil
 }
+
+// SaveMetricsTextfile writes Prometheus formatted metrics to the file at path. The
+// metrics are written to a temporary file in the same directory and then renamed into
+// place, so the node_exporter textfile collector never reads a partially written file.
+// Returns an error if the directory can't be created or the file can't be written.
+func SaveMetricsTextfile(path, metrics string, ensureDirExistsFunc func(string) error) error {
+	dir := filepath.Dir(path)
+	if err := ensureDirExistsFunc(dir); err != nil {
+		return err
+	}
+
+	tempFile, err := fsys.Default.CreateTemp(dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("error creating metrics file: %s - %v", path, err)
+	}
+	defer fsys.Default.Remove(tempFile.Name())
+
+	if _, err := tempFile.Write([]byte(metrics)); err != nil {
+		tempFile.Close()
+		return fmt.Errorf("error writing metrics file: %s - %v", path, err)
+	}
+
+	if err := tempFile.Close(); err != nil {
+		return fmt.Errorf("error writing metrics file: %s - %v", path, err)
+	}
+
+	// Temp files are created 0600, node_exporter needs to be able to read the result
+	if err := fsys.Default.Chmod(tempFile.Name(), 0644); err != nil {
+		return fmt.Errorf("error writing metrics file: %s - %v", path, err)
+	}
+
+	if err := fsys.Default.Rename(tempFile.Name(), path); err != nil {
+		return fmt.Errorf("error saving metrics file: %s - %v", path, err)
+	}
+
+	return nil
+}
+
+// SnapshotFilename returns the name, without extension, of the snapshot of the file
+// named base taken at the given time, e.g. "skyui 3863 2024-06-01T12-00".
+func SnapshotFilename(base string, at time.Time) string {
+	return FilenamePolicy{}.Snapshot(base, at)
+}
+
+// ParseSnapshotFilename splits a snapshot file name, with or without its .json
+// extension, into the name of the file it is a snapshot of and the time it was taken.
+// The timestamp may be joined to the name with any separator a FilenamePolicy accepts.
+// Reports false when the name doesn't end with a snapshot timestamp.
+func ParseSnapshotFilename(filename string) (string, time.Time, bool) {
+	name := strings.TrimSuffix(filename, ".json")
+	separator := len(name) - len(SnapshotLayout) - 1
+	if separator < 1 || !slices.Contains(filenameSeparators, name[separator:separator+1]) {
+		return "", time.Time{}, false
+	}
+
+	at, err := time.Parse(SnapshotLayout, name[separator+1:])
+	if err != nil {
+		return "", time.Time{}, false
+	}
+	return name[:separator], at, true
+}
+
+// PruneSnapshots removes the oldest JSON snapshots of the file named base in dir, so
+// that only the keep most recent remain, along with the companion files sharing the
+// name of each removed snapshot such as its archived HTML. Files that aren't snapshots
+// of base are left untouched, and nothing is removed when keep is not positive. Returns
+// the paths of the removed files, or an error if the directory can't be read or a file
+// can't be removed.
+func PruneSnapshots(dir, base string, keep int) ([]string, error) {
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	files, err := fsys.Default.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading snapshots: %w", err)
+	}
+
+	snapshots := snapshotsOf(files, dir, base)
+	if len(snapshots) <= keep {
+		return nil, nil
+	}
+
+	var removed []string
+	for _, old := range snapshots[keep:] {
+		if err := fsys.Default.Remove(old.path); err != nil {
+			return removed, fmt.Errorf("error removing snapshot: %s - %v", old.path, err)
+		}
+		removed = append(removed, old.path)
+
+		// Companion files, such as the archived HTML, share the snapshot name
+		prefix := strings.TrimSuffix(filepath.Base(old.path), ".json") + "."
+		for _, file := range files {
+			if file.IsDir() || !strings.HasPrefix(file.Name(), prefix) || file.Name() == filepath.Base(old.path) {
+				continue
+			}
+			companion := filepath.Join(dir, file.Name())
+			if err := fsys.Default.Remove(companion); err != nil {
+				return removed, fmt.Errorf("error removing snapshot: %s - %v", companion, err)
+			}
+			removed = append(removed, companion)
+		}
+	}
+	return removed, nil
+}
+
+// LatestSnapshot returns the path of the most recent JSON snapshot of the file named
+// base in dir. Reports false when there is none or the directory can't be read.
+func LatestSnapshot(dir, base string) (string, bool) {
+	files, err := fsys.Default.ReadDir(dir)
+	if err != nil {
+		return "", false
+	}
+
+	snapshots := snapshotsOf(files, dir, base)
+	if len(snapshots) == 0 {
+		return "", false
+	}
+	return snapshots[0].path, true
+}
+
+// snapshot is a JSON snapshot of a saved file, with the time it was taken.
+type snapshot struct {
+	at   time.Time
+	path string
+}
+
+// snapshotsOf returns the JSON snapshots of the file named base among the files of
+// dir, most recent first.
+func snapshotsOf(files []os.DirEntry, dir, base string) []snapshot {
+	var snapshots []snapshot
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		if snapshotBase, at, ok := ParseSnapshotFilename(file.Name()); ok && snapshotBase == base {
+			snapshots = append(snapshots, snapshot{at: at, path: filepath.Join(dir, file.Name())})
+		}
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].at.After(snapshots[j].at) })
+	return snapshots
+}
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -219,3 +220,259 @@ func TestSaveModInfoToJson_EnsureDirExistsError(t *testing.T) {
 	assert.Contains(t, err.Error(), "directory error")
 	mockUtils.AssertCalled(t, "EnsureDirExists", dir)
 }
+
+func TestSaveMetricsTextfile_Success(t *testing.T) {
+	// Arrange
+	dir := t.TempDir()
+	path := filepath.Join(dir, "nexus.prom")
+	metrics := "nexus_mods_scraper_mods_total{game=\"skyrim\"} 1\n"
+	mockUtils := new(Mocker)
+	mockUtils.On("EnsureDirExists", dir).Return(nil)
+
+	// Act
+	err := SaveMetricsTextfile(path, metrics, mockUtils.EnsureDirExists)
+
+	// Assert
+	assert.NoError(t, err)
+	content, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, metrics, string(content))
+
+	// Only the final file should remain
+	entries, err := os.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestSaveMetricsTextfile_EnsureDirExistsError(t *testing.T) {
+	// Arrange
+	mockUtils := new(Mocker)
+	mockUtils.On("EnsureDirExists", "metrics").Return(fmt.Errorf("directory error"))
+
+	// Act
+	err := SaveMetricsTextfile(filepath.Join("metrics", "nexus.prom"), "", mockUtils.EnsureDirExists)
+
+	// Assert
+	assert.EqualError(t, err, "directory error")
+}
+
+func TestSaveMetricsTextfile_RenameErrorLeavesNoTempFile(t *testing.T) {
+	// Arrange
+	memFS := fsys.NewMemFS()
+	defer fsys.Use(memFS)()
+	path := filepath.Join("metrics", "nexus.prom")
+	memFS.FailOn("Rename", filepath.Join("metrics", "nexus.prom.1.tmp"), fmt.Errorf("rename failed"))
+
+	// Act
+	err := SaveMetricsTextfile(path, "metric 1\n", func(dir string) error {
+		return memFS.MkdirAll(dir, os.ModePerm)
+	})
+
+	// Assert
+	assert.EqualError(t, err, "error saving metrics file: metrics/nexus.prom - rename failed")
+	assert.Empty(t, memFS.Files())
+}
+
+func TestSaveModInfoToJson_WriteError(t *testing.T) {
+	// Arrange
+	memFS := fsys.NewMemFS()
+	defer fsys.Use(memFS)()
+	memFS.FailOn("WriteFile", filepath.Join("out", "1.json"), fmt.Errorf("disk full"))
+
+	// Act
+	_, err := SaveModInfoToJson(types.CliFlags{}, types.ModInfo{}, "out", "1", func(dir string) error {
+		return memFS.MkdirAll(dir, os.ModePerm)
+	})
+
+	// Assert
+	assert.EqualError(t, err, "error saving file: out/1.json - disk full")
+}
+
+func TestSnapshotFilename(t *testing.T) {
+	// Arrange
+	at := time.Date(2024, time.June, 1, 14, 30, 45, 0, time.FixedZone("CEST", 2*60*60))
+
+	// Act
+	filename := SnapshotFilename("skyui 3863", at)
+
+	// Assert
+	assert.Equal(t, "skyui 3863 2024-06-01T12-30", filename)
+}
+
+func TestFilenamePolicy_Validate(t *testing.T) {
+	assert.NoError(t, FilenamePolicy{}.Validate())
+	assert.NoError(t, FilenamePolicy{Case: "Kebab", Separator: "."}.Validate())
+	assert.EqualError(t, FilenamePolicy{Case: "camel"}.Validate(), `unsupported filename case "camel", expected preserve, lower, kebab or snake`)
+	assert.EqualError(t, FilenamePolicy{Separator: "/"}.Validate(), `unsupported filename separator "/", expected a space, -, _ or .`)
+}
+
+func TestFilenamePolicy_Snapshot(t *testing.T) {
+	// Arrange
+	at := time.Date(2024, time.June, 1, 12, 30, 0, 0, time.UTC)
+
+	// Act
+	filename := FilenamePolicy{Case: CaseKebab}.Snapshot("skyui-3863", at)
+	base, parsedAt, ok := ParseSnapshotFilename(filename + ".json")
+
+	// Assert
+	assert.Equal(t, "skyui-3863-2024-06-01T12-30", filename)
+	assert.True(t, ok)
+	assert.Equal(t, "skyui-3863", base)
+	assert.Equal(t, at, parsedAt)
+}
+
+func TestParseSnapshotFilename(t *testing.T) {
+	// Act
+	base, at, ok := ParseSnapshotFilename("skyui 3863 2024-06-01T12-30.json")
+	_, _, plainOk := ParseSnapshotFilename("skyui 3863.json")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, "skyui 3863", base)
+	assert.Equal(t, time.Date(2024, time.June, 1, 12, 30, 0, 0, time.UTC), at)
+	assert.False(t, plainOk)
+}
+
+func TestPruneSnapshots(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	dir := filepath.Join("data", "skyrim")
+	for _, name := range []string{
+		"skyui 3863 2024-06-01T12-00.json",
+		"skyui 3863 2024-05-01T12-00.json",
+		"skyui 3863 2024-05-01T12-00.page.html.gz",
+		"skyui 3863 2024-07-01T12-00.json",
+		"skyui 3863 2024-07-01T12-00.page.html.gz",
+		"skyui 3863.json",
+		"other 1 2024-01-01T12-00.json",
+	} {
+		assert.NoError(t, fsys.Default.MkdirAll(dir, os.ModePerm))
+		assert.NoError(t, fsys.Default.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644))
+	}
+
+	// Act
+	removed, err := PruneSnapshots(dir, "skyui 3863", 2)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(dir, "skyui 3863 2024-05-01T12-00.json"),
+		filepath.Join(dir, "skyui 3863 2024-05-01T12-00.page.html.gz"),
+	}, removed)
+	files, err := fsys.Default.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Len(t, files, 5)
+}
+
+func TestPruneSnapshots_KeepAll(t *testing.T) {
+	// Act
+	removed, err := PruneSnapshots("missing", "skyui 3863", 0)
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Empty(t, removed)
+}
+
+func TestLatestSnapshot(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	dir := filepath.Join("data", "skyrim")
+	assert.NoError(t, fsys.Default.MkdirAll(dir, os.ModePerm))
+	for _, name := range []string{"skyui 3863 2024-06-01T12-00.json", "skyui 3863 2024-07-01T12-00.json", "skyui 3863.json", "other 1 2024-08-01T12-00.json"} {
+		assert.NoError(t, fsys.Default.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644))
+	}
+
+	// Act
+	latest, ok := LatestSnapshot(dir, "skyui 3863")
+	_, missingOk := LatestSnapshot(dir, "missing 1")
+
+	// Assert
+	assert.True(t, ok)
+	assert.Equal(t, filepath.Join(dir, "skyui 3863 2024-07-01T12-00.json"), latest)
+	assert.False(t, missingOk)
+}
+
+func TestValidatePathTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		wantErr  string
+	}{
+		{template: DefaultPathTemplate},
+		{template: "{game}/{modid}/{version}/{name}.json"},
+		{template: "{game}/{modid}.txt", wantErr: `path template "{game}/{modid}.txt" must end in .json`},
+		{template: "/archive/{modid}.json", wantErr: `path template "/archive/{modid}.json" must be relative to the output directory`},
+		{template: "../{modid}.json", wantErr: `path template "../{modid}.json" must stay within the output directory`},
+		{template: "{game}/{author} {modid}.json", wantErr: `path template "{game}/{author} {modid}.json" has unknown placeholder {author}, expected {creator}, {game}, {modid}, {name} or {version}`},
+		{template: "{game}/{name}.json", wantErr: `path template "{game}/{name}.json" must include {modid}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			err := ValidatePathTemplate(tt.template)
+
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenderPathTemplate(t *testing.T) {
+	// Arrange
+	mod := types.ModInfo{ModID: 3863, Name: "SkyUI/Extended", Version: "5.1", LatestVersion: "5.2"}
+
+	tests := []struct {
+		name     string
+		template string
+		policy   FilenamePolicy
+		want     string
+	}{
+		{name: "default layout", template: "", want: filepath.Join("skyrim", "skyui-extended 3863.json")},
+		{name: "preserve case", template: "", policy: FilenamePolicy{Case: CasePreserve}, want: filepath.Join("skyrim", "SkyUI-Extended 3863.json")},
+		{name: "kebab case", template: "{game}/{name} {modid}.json", policy: FilenamePolicy{Case: CaseKebab}, want: filepath.Join("skyrim", "skyui-extended-3863.json")},
+		{name: "snake case", template: "{game}/{name} v{version} {modid}.json", policy: FilenamePolicy{Case: CaseSnake}, want: filepath.Join("skyrim", "skyui_extended_v5.2_3863.json")},
+		{name: "separator", template: "", policy: FilenamePolicy{Separator: "_"}, want: filepath.Join("skyrim", "skyui-extended_3863.json")},
+		{name: "nested", template: "{game}/{modid}/{version}/{name}.json", want: filepath.Join("skyrim", "3863", "5.2", "skyui-extended.json")},
+		{name: "empty value", template: "{creator}/{modid}.json", want: filepath.Join("unknown", "3863.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := RenderPathTemplate(tt.template, "skyrim", mod, tt.policy)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPathTemplateUses(t *testing.T) {
+	assert.True(t, PathTemplateUses("{game}/{name} {version} {modid}.json", "version"))
+	assert.False(t, PathTemplateUses("{game}/{name} {modid}.json", "version"))
+	assert.True(t, PathTemplateUses("", "name"), "the default template is used when empty")
+}
+
+func TestPlannedPath(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		policy   FilenamePolicy
+		want     string
+	}{
+		{name: "default layout", template: "", want: filepath.Join("skyrim", "{name} 3863.json")},
+		{name: "kebab case", template: "{game}/{creator}/{name} {modid}.json", policy: FilenamePolicy{Case: CaseKebab}, want: filepath.Join("skyrim", "{creator}", "{name}-3863.json")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			got := PlannedPath(tt.template, "skyrim", 3863, tt.policy)
+
+			// Assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
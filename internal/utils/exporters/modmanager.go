@@ -0,0 +1,130 @@
+package exporters
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// The formats the scraped mods are saved in. Every format saves the JSON results, the
+// mod manager ones also write the metadata of the mod next to them.
+const (
+	// FormatJson only saves the JSON results. It is the default.
+	FormatJson = "json"
+	// FormatMO2 also writes the meta.ini Mod Organizer 2 keeps in each mod folder.
+	FormatMO2 = "mo2"
+	// FormatVortex also writes the mod attributes Vortex tracks, as vortex.json.
+	FormatVortex = "vortex"
+)
+
+// ValidateFormat checks that the format is json, mo2 or vortex.
+func ValidateFormat(format string) error {
+	switch format {
+	case FormatJson, FormatMO2, FormatVortex:
+		return nil
+	}
+	return fmt.Errorf("unsupported format %q, expected json, mo2 or vortex", format)
+}
+
+// MO2Meta returns the meta.ini Mod Organizer 2 reads from a mod folder to know where
+// the mod comes from, holding its game, ID, version, Nexus page and, as Mod Organizer
+// numbers categories on its own, its category and name as comments.
+func MO2Meta(game types.GameSlug, mod types.ModInfo) string {
+	version := modVersion(mod)
+
+	var b strings.Builder
+	b.WriteString("[General]\n")
+	fmt.Fprintf(&b, "gameName=%s\n", iniValue(game.String()))
+	fmt.Fprintf(&b, "modid=%d\n", mod.ModID)
+	fmt.Fprintf(&b, "version=%s\n", iniValue(version))
+	fmt.Fprintf(&b, "newestVersion=%s\n", iniValue(version))
+	b.WriteString("repository=Nexus\n")
+	if mod.Url != "" {
+		fmt.Fprintf(&b, "url=%s\n", iniValue(mod.Url))
+	}
+	comments := mod.Name
+	if mod.Category != "" {
+		comments = fmt.Sprintf("%s (%s)", mod.Name, mod.Category)
+	}
+	fmt.Fprintf(&b, "comments=%s\n", iniValue(comments))
+	return b.String()
+}
+
+// VortexMeta returns the attributes Vortex tracks for a mod installed from Nexus Mods,
+// under an "attributes" object, as indented JSON.
+func VortexMeta(game types.GameSlug, mod types.ModInfo) ([]byte, error) {
+	attributes := map[string]any{
+		"author":           mod.Creator,
+		"category":         mod.Category,
+		"customFileName":   mod.Name,
+		"downloadGame":     game.String(),
+		"homepage":         mod.Url,
+		"logicalFileName":  mod.Name,
+		"modId":            mod.ModID,
+		"modName":          mod.Name,
+		"newestVersion":    modVersion(mod),
+		"shortDescription": mod.ShortDescription,
+		"source":           "nexus",
+		"version":          modVersion(mod),
+	}
+	return json.MarshalIndent(map[string]any{"attributes": attributes}, "", "  ")
+}
+
+// SaveModManagerMeta writes the mod manager metadata of the mod in the format to a
+// folder named after filename in dir, ready to be copied into the mod folder: meta.ini
+// for mo2 and vortex.json for vortex. Nothing is written for json. Returns the path
+// written, or an error if it can't be written.
+func SaveModManagerMeta(format string, game types.GameSlug, mod types.ModInfo, dir, filename string, ensureDirExistsFunc func(string) error) (string, error) {
+	var (
+		name string
+		data []byte
+	)
+	switch format {
+	case FormatMO2:
+		name, data = "meta.ini", []byte(MO2Meta(game, mod))
+	case FormatVortex:
+		var err error
+		name = "vortex.json"
+		if data, err = VortexMeta(game, mod); err != nil {
+			return "", fmt.Errorf("error formatting data: %s - %v", filepath.Join(dir, filename, name), err)
+		}
+	default:
+		return "", nil
+	}
+
+	// The metadata sits in its own folder, out of the way of the saved results
+	dir = filepath.Join(dir, filename)
+	path := filepath.Join(dir, name)
+	if err := ensureDirExistsFunc(dir); err != nil {
+		return "", err
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return path, nil
+}
+
+// modVersion returns the latest version of the mod's files, or the version its page
+// shows when the files weren't scraped.
+func modVersion(mod types.ModInfo) string {
+	if mod.LatestVersion != "" {
+		return mod.LatestVersion
+	}
+	return mod.Version
+}
+
+// iniValue returns the value as written in an INI file read by Qt, the way Mod
+// Organizer 2 writes it: quoted when it holds characters that would otherwise end or
+// split it, with quotes and backslashes escaped and line breaks flattened.
+func iniValue(value string) string {
+	value = strings.Join(strings.Fields(value), " ")
+	if !strings.ContainsAny(value, `,;="\`) {
+		return value
+	}
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	return `"` + value + `"`
+}
@@ -0,0 +1,106 @@
+package exporters
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// modManagerMod is the mod the mod manager metadata tests write.
+var modManagerMod = types.ModInfo{
+	Category:      "User Interface",
+	Creator:       "schlangster",
+	LatestVersion: "5.2SE",
+	ModID:         12604,
+	Name:          "SkyUI",
+	Url:           "https://www.nexusmods.com/skyrimspecialedition/mods/12604",
+	Version:       "5.1",
+}
+
+func TestValidateFormat(t *testing.T) {
+	for _, format := range []string{FormatJson, FormatMO2, FormatVortex} {
+		assert.NoError(t, ValidateFormat(format))
+	}
+	assert.EqualError(t, ValidateFormat("csv"), `unsupported format "csv", expected json, mo2 or vortex`)
+}
+
+func TestMO2Meta(t *testing.T) {
+	// Act
+	meta := MO2Meta("skyrimspecialedition", modManagerMod)
+
+	// Assert
+	assert.Equal(t, "[General]\n"+
+		"gameName=skyrimspecialedition\n"+
+		"modid=12604\n"+
+		"version=5.2SE\n"+
+		"newestVersion=5.2SE\n"+
+		"repository=Nexus\n"+
+		"url=https://www.nexusmods.com/skyrimspecialedition/mods/12604\n"+
+		"comments=SkyUI (User Interface)\n", meta)
+}
+
+func TestMO2Meta_QuotesValues(t *testing.T) {
+	// Arrange
+	mod := types.ModInfo{ModID: 1, Name: "Patch, \"Unofficial\"\nEdition"}
+
+	// Act
+	meta := MO2Meta("skyrimspecialedition", mod)
+
+	// Assert
+	assert.Contains(t, meta, "comments=\"Patch, \\\"Unofficial\\\" Edition\"\n")
+}
+
+func TestVortexMeta(t *testing.T) {
+	// Act
+	data, err := VortexMeta("skyrimspecialedition", modManagerMod)
+
+	// Assert
+	require.NoError(t, err)
+	var meta struct {
+		Attributes map[string]any `json:"attributes"`
+	}
+	require.NoError(t, json.Unmarshal(data, &meta))
+	assert.Equal(t, float64(12604), meta.Attributes["modId"])
+	assert.Equal(t, "SkyUI", meta.Attributes["modName"])
+	assert.Equal(t, "5.2SE", meta.Attributes["version"])
+	assert.Equal(t, "User Interface", meta.Attributes["category"])
+	assert.Equal(t, "skyrimspecialedition", meta.Attributes["downloadGame"])
+	assert.Equal(t, "nexus", meta.Attributes["source"])
+}
+
+func TestSaveModManagerMeta(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatMO2, filepath.Join("skyui 12604", "meta.ini")},
+		{FormatVortex, filepath.Join("skyui 12604", "vortex.json")},
+		{FormatJson, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			// Arrange
+			dir := t.TempDir()
+
+			// Act
+			path, err := SaveModManagerMeta(tt.format, "skyrimspecialedition", modManagerMod, dir, "skyui 12604", func(dir string) error {
+				return os.MkdirAll(dir, os.ModePerm)
+			})
+
+			// Assert
+			require.NoError(t, err)
+			if tt.want == "" {
+				assert.Empty(t, path)
+				return
+			}
+			assert.Equal(t, filepath.Join(dir, tt.want), path)
+			assert.FileExists(t, path)
+		})
+	}
+}
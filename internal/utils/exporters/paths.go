@@ -0,0 +1,226 @@
+package exporters
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// DefaultPathTemplate lays saved mods out as <game>/<name> <modid>.json under the
+// output directory.
+const DefaultPathTemplate = "{game}/{name} {modid}.json"
+
+// pathPlaceholder matches a placeholder of a path template, e.g. {modid}.
+var pathPlaceholder = regexp.MustCompile(`\{([^{}]*)\}`)
+
+// unsafePathChars matches the characters replaced in placeholder values, so that a
+// value, such as a mod name with a slash, can't add directories to the path.
+var unsafePathChars = regexp.MustCompile(`[/\\\x00-\x1f]`)
+
+// pathFields returns the value of each placeholder of a path template for a mod.
+var pathFields = map[string]func(game types.GameSlug, mod types.ModInfo) string{
+	"creator": func(_ types.GameSlug, mod types.ModInfo) string { return mod.Creator },
+	"game":    func(game types.GameSlug, _ types.ModInfo) string { return game.String() },
+	"modid":   func(_ types.GameSlug, mod types.ModInfo) string { return strconv.FormatInt(mod.ModID, 10) },
+	"name":    func(_ types.GameSlug, mod types.ModInfo) string { return mod.Name },
+	"version": func(_ types.GameSlug, mod types.ModInfo) string { return modVersion(mod) },
+}
+
+// The filename casings supported by FilenamePolicy.
+const (
+	// CaseKebab lowercases names and joins their words with dashes, e.g. "skyui-3863".
+	CaseKebab = "kebab"
+	// CaseLower lowercases names, e.g. "skyui 3863". It is the default.
+	CaseLower = "lower"
+	// CasePreserve keeps names as shown on Nexus Mods, e.g. "SkyUI 3863".
+	CasePreserve = "preserve"
+	// CaseSnake lowercases names and joins their words with underscores, e.g.
+	// "skyui_3863".
+	CaseSnake = "snake"
+)
+
+// filenameSeparators are the separators FilenamePolicy accepts. They are single
+// characters so that snapshot names can be split from their timestamp.
+var filenameSeparators = []string{" ", "-", "_", "."}
+
+// wordBreaks matches the runs of characters kebab and snake casing join words across.
+var wordBreaks = regexp.MustCompile(`[^\pL\pN.]+`)
+
+// whitespace matches the runs of whitespace replaced with the separator of a
+// FilenamePolicy.
+var whitespace = regexp.MustCompile(`\s+`)
+
+// FilenamePolicy controls how the names of saved files are cased and which separator
+// replaces the spaces in them. The zero value keeps the default naming: lowercased
+// names separated with spaces, e.g. "skyui 3863.json".
+type FilenamePolicy struct {
+	// Case is one of CaseKebab, CaseLower, CasePreserve or CaseSnake; empty means
+	// CaseLower.
+	Case string
+	// Separator replaces the spaces of file names; empty means a dash for kebab
+	// casing, an underscore for snake casing and a space otherwise.
+	Separator string
+}
+
+// Validate checks that the casing is known and the separator is a space, dash,
+// underscore or dot, returning an error naming the invalid setting.
+func (p FilenamePolicy) Validate() error {
+	switch strings.ToLower(p.Case) {
+	case "", CaseKebab, CaseLower, CasePreserve, CaseSnake:
+	default:
+		return fmt.Errorf("unsupported filename case %q, expected preserve, lower, kebab or snake", p.Case)
+	}
+
+	if p.Separator != "" && !slices.Contains(filenameSeparators, p.Separator) {
+		return fmt.Errorf("unsupported filename separator %q, expected a space, -, _ or .", p.Separator)
+	}
+	return nil
+}
+
+// separator returns the separator replacing the spaces of file names.
+func (p FilenamePolicy) separator() string {
+	switch {
+	case p.Separator != "":
+		return p.Separator
+	case strings.EqualFold(p.Case, CaseKebab):
+		return "-"
+	case strings.EqualFold(p.Case, CaseSnake):
+		return "_"
+	}
+	return " "
+}
+
+// apply cases value and replaces its spaces with the separator. Kebab and snake
+// casing also replace the punctuation between words, keeping dots for versions.
+func (p FilenamePolicy) apply(value string) string {
+	switch strings.ToLower(p.Case) {
+	case CasePreserve:
+	case CaseKebab, CaseSnake:
+		value = strings.Trim(wordBreaks.ReplaceAllString(strings.ToLower(value), " "), " ")
+	default:
+		value = strings.ToLower(value)
+	}
+	return whitespace.ReplaceAllString(value, p.separator())
+}
+
+// Snapshot returns the name, without extension, of the snapshot of the file named
+// base taken at the given time, joined with the separator of the policy, e.g.
+// "skyui-3863-2024-06-01T12-00".
+func (p FilenamePolicy) Snapshot(base string, at time.Time) string {
+	return base + p.separator() + at.UTC().Format(SnapshotLayout)
+}
+
+// ValidatePathTemplate checks that template is a relative, slash separated path ending
+// in .json that stays within the output directory, only uses known placeholders, and
+// includes {modid} so that mods don't overwrite each other. Returns an error
+// describing the first problem found.
+func ValidatePathTemplate(template string) error {
+	if !strings.HasSuffix(template, ".json") {
+		return fmt.Errorf("path template %q must end in .json", template)
+	}
+	if path.IsAbs(template) || filepath.IsAbs(template) {
+		return fmt.Errorf("path template %q must be relative to the output directory", template)
+	}
+	for _, element := range strings.Split(template, "/") {
+		if element == ".." {
+			return fmt.Errorf("path template %q must stay within the output directory", template)
+		}
+	}
+
+	hasModID := false
+	for _, match := range pathPlaceholder.FindAllStringSubmatch(template, -1) {
+		if _, ok := pathFields[match[1]]; !ok {
+			return fmt.Errorf("path template %q has unknown placeholder %s, expected {creator}, {game}, {modid}, {name} or {version}", template, match[0])
+		}
+		hasModID = hasModID || match[1] == "modid"
+	}
+	if !hasModID {
+		return fmt.Errorf("path template %q must include {modid}", template)
+	}
+	return nil
+}
+
+// PathTemplateUses reports whether template, or the default one when empty, has the
+// named placeholder, e.g. "version" for {version}.
+func PathTemplateUses(template, name string) bool {
+	if template == "" {
+		template = DefaultPathTemplate
+	}
+	for _, match := range pathPlaceholder.FindAllStringSubmatch(template, -1) {
+		if match[1] == name {
+			return true
+		}
+	}
+	return false
+}
+
+// RenderPathTemplate returns the path, relative to the output directory, the mod is
+// saved to according to template and policy, which are expected to be valid. An empty
+// template renders the default one. Placeholder values have unsafe characters replaced
+// with dashes, and empty values are rendered as "unknown". Apart from the game, which
+// is always lowercase, the values are cased by the policy, and the spaces of both the
+// values and the template are replaced with its separator.
+func RenderPathTemplate(template string, game types.GameSlug, mod types.ModInfo, policy FilenamePolicy) string {
+	return renderPath(template, policy, func(name string) string {
+		return renderPlaceholder(name, game, mod, policy)
+	})
+}
+
+// PlannedPath returns the path, relative to the output directory, the mod of game
+// numbered modID would be saved to according to template and policy, as far as it is
+// known before the mod is scraped: the {game} and {modid} placeholders are rendered
+// like RenderPathTemplate does, the others, e.g. {name}, being left as is.
+func PlannedPath(template string, game types.GameSlug, modID int64, policy FilenamePolicy) string {
+	mod := types.ModInfo{ModID: modID}
+	return renderPath(template, policy, func(name string) string {
+		if name != "game" && name != "modid" {
+			return "{" + name + "}"
+		}
+		return renderPlaceholder(name, game, mod, policy)
+	})
+}
+
+// renderPath returns template, or the default one when empty, with its placeholders
+// replaced with the values returned by placeholder for their names and the spaces
+// around them with the separator of policy.
+func renderPath(template string, policy FilenamePolicy, placeholder func(name string) string) string {
+	if template == "" {
+		template = DefaultPathTemplate
+	}
+
+	var rendered strings.Builder
+	last := 0
+	for _, match := range pathPlaceholder.FindAllStringSubmatchIndex(template, -1) {
+		rendered.WriteString(whitespace.ReplaceAllString(template[last:match[0]], policy.separator()))
+		rendered.WriteString(placeholder(template[match[2]:match[3]]))
+		last = match[1]
+	}
+	rendered.WriteString(whitespace.ReplaceAllString(template[last:], policy.separator()))
+
+	return filepath.FromSlash(rendered.String())
+}
+
+// renderPlaceholder returns the value of the named placeholder for the mod, or the
+// placeholder itself when it is unknown.
+func renderPlaceholder(name string, game types.GameSlug, mod types.ModInfo, policy FilenamePolicy) string {
+	field, ok := pathFields[name]
+	if !ok {
+		return "{" + name + "}"
+	}
+
+	value := strings.Trim(unsafePathChars.ReplaceAllString(field(game, mod), "-"), " .")
+	if name != "game" && name != "modid" {
+		value = strings.Trim(policy.apply(value), " "+policy.separator())
+	}
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
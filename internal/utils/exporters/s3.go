@@ -0,0 +1,333 @@
+package exporters
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+// S3Uploader uploads files to an S3 bucket, or any storage speaking the S3 API such as
+// MinIO, addressing it path-style as Endpoint/Bucket/Prefix/key and signing the
+// requests with AWS Signature Version 4. Files larger than PartSize are sent as a
+// multipart upload, up to Concurrency parts at a time.
+type S3Uploader struct {
+	AccessKey    string
+	Bucket       string
+	Client       *http.Client
+	Concurrency  int
+	Endpoint     string
+	PartSize     int64
+	Prefix       string
+	Region       string
+	SecretKey    string
+	SessionToken string
+
+	// now returns the time the requests are signed at, time.Now unless replaced in tests
+	now func() time.Time
+}
+
+// s3Error is the error document S3 answers failed requests with.
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// completedPart is a part of a multipart upload listed when completing it.
+type completedPart struct {
+	ETag       string `xml:"ETag"`
+	PartNumber int    `xml:"PartNumber"`
+}
+
+// Upload uploads the file at path as key below the prefix of the bucket, in a single
+// request or as a multipart upload when it is larger than the part size. Returns an
+// error if the file can't be read or S3 rejects a request.
+func (u *S3Uploader) Upload(ctx context.Context, key, path string) error {
+	info, err := fsys.Default.Stat(path)
+	if err != nil {
+		return err
+	}
+	file, err := fsys.Default.OpenFile(path, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	objectUrl := u.objectUrl(key)
+	if info.Size() <= u.PartSize {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		_, err = u.do(ctx, http.MethodPut, objectUrl, nil, data)
+		return err
+	}
+	return u.uploadMultipart(ctx, objectUrl, file)
+}
+
+// uploadMultipart uploads the content of file to objectUrl as a multipart upload, the
+// parts being read one after the other and uploaded concurrently, so that no more than
+// Concurrency parts are held in memory. The upload is aborted if a part fails.
+func (u *S3Uploader) uploadMultipart(ctx context.Context, objectUrl string, file io.Reader) error {
+	resp, err := u.do(ctx, http.MethodPost, objectUrl, url.Values{"uploads": {""}}, nil)
+	if err != nil {
+		return err
+	}
+	var initiated struct {
+		UploadId string `xml:"UploadId"`
+	}
+	if err := xml.Unmarshal(resp, &initiated); err != nil || initiated.UploadId == "" {
+		return fmt.Errorf("S3 didn't start the multipart upload")
+	}
+	uploadId := initiated.UploadId
+
+	var (
+		mu    sync.Mutex
+		parts []completedPart
+	)
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(max(u.Concurrency, 1))
+	for number := 1; ; number++ {
+		part := make([]byte, u.PartSize)
+		n, readErr := io.ReadFull(file, part)
+		if n > 0 {
+			group.Go(func() error {
+				query := url.Values{"partNumber": {strconv.Itoa(number)}, "uploadId": {uploadId}}
+				etag, err := u.doPart(groupCtx, objectUrl, query, part[:n])
+				if err != nil {
+					return fmt.Errorf("part %d: %w", number, err)
+				}
+				mu.Lock()
+				parts = append(parts, completedPart{ETag: etag, PartNumber: number})
+				mu.Unlock()
+				return nil
+			})
+		}
+		if errors.Is(readErr, io.EOF) || errors.Is(readErr, io.ErrUnexpectedEOF) {
+			break
+		}
+		if readErr != nil {
+			group.Wait()
+			return u.abort(objectUrl, uploadId, readErr)
+		}
+		if groupCtx.Err() != nil {
+			break
+		}
+	}
+	if err := group.Wait(); err != nil {
+		return u.abort(objectUrl, uploadId, err)
+	}
+
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	resp, err = u.do(ctx, http.MethodPost, objectUrl, url.Values{"uploadId": {uploadId}}, body)
+	if err != nil {
+		return u.abort(objectUrl, uploadId, err)
+	}
+	// Completing can fail after S3 answered 200 OK, with an error document as the body
+	var failure s3Error
+	if xml.Unmarshal(resp, &failure) == nil && failure.Code != "" {
+		return u.abort(objectUrl, uploadId, fmt.Errorf("S3 error %s: %s", failure.Code, failure.Message))
+	}
+	return nil
+}
+
+// doPart uploads a part of a multipart upload, returning the ETag S3 identifies it by.
+func (u *S3Uploader) doPart(ctx context.Context, objectUrl string, query url.Values, data []byte) (string, error) {
+	req, err := u.newRequest(ctx, http.MethodPut, objectUrl, query, data)
+	if err != nil {
+		return "", err
+	}
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err := s3ResponseError(resp); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("ETag"), nil
+}
+
+// abort aborts the multipart upload so that S3 doesn't keep its parts, and returns err.
+func (u *S3Uploader) abort(objectUrl, uploadId string, err error) error {
+	// The upload is aborted even when the context of the run is done
+	u.do(context.Background(), http.MethodDelete, objectUrl, url.Values{"uploadId": {uploadId}}, nil)
+	return err
+}
+
+// do sends a signed request to S3 and returns the body of its response. Returns an
+// error if the request fails or S3 doesn't answer with a 2xx status.
+func (u *S3Uploader) do(ctx context.Context, method, objectUrl string, query url.Values, body []byte) ([]byte, error) {
+	req, err := u.newRequest(ctx, method, objectUrl, query, body)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if err := s3ResponseError(resp); err != nil {
+		return nil, err
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// s3ResponseError returns the error of a response S3 didn't answer with a 2xx status,
+// read from its error document when it has one.
+func s3ResponseError(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var failure s3Error
+	if xml.Unmarshal(data, &failure) == nil && failure.Code != "" {
+		return fmt.Errorf("S3 returned status %d: %s: %s", resp.StatusCode, failure.Code, failure.Message)
+	}
+	return fmt.Errorf("S3 returned status %d", resp.StatusCode)
+}
+
+// objectUrl returns the path-style URL of the object of key below the prefix.
+func (u *S3Uploader) objectUrl(key string) string {
+	objectKey := strings.TrimLeft(u.Prefix+"/"+key, "/")
+	return u.Endpoint + "/" + s3Escape(u.Bucket) + "/" + s3Escape(objectKey)
+}
+
+// newRequest returns the request of method to objectUrl with the query and body,
+// signed with AWS Signature Version 4.
+func (u *S3Uploader) newRequest(ctx context.Context, method, objectUrl string, query url.Values, body []byte) (*http.Request, error) {
+	target := objectUrl
+	if len(query) > 0 {
+		target += "?" + s3Query(query)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	u.sign(req, body)
+	return req, nil
+}
+
+// sign adds the AWS Signature Version 4 authorization of the request with body to its
+// headers.
+func (u *S3Uploader) sign(req *http.Request, body []byte) {
+	now := time.Now
+	if u.now != nil {
+		now = u.now
+	}
+	at := now().UTC()
+	amzDate := at.Format("20060102T150405Z")
+	day := at.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	req.Header.Set("X-Amz-Date", amzDate)
+	if u.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", u.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		if lower := strings.ToLower(name); strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(req.Header.Get(name))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	scope := day + "/" + u.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSha256([]byte("AWS4"+u.SecretKey), day)
+	key = hmacSha256(key, u.Region)
+	key = hmacSha256(key, "s3")
+	key = hmacSha256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSha256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", u.AccessKey, scope, signedHeaders, signature))
+}
+
+// s3Escape escapes an object key as AWS Signature Version 4 expects: every byte but
+// the unreserved characters and slashes is percent-encoded.
+func s3Escape(key string) string {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || strings.IndexByte("-_.~/", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// s3Query returns the canonical query string of query: its parameters sorted by name,
+// escaped like object keys, slashes included.
+func s3Query(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, strings.ReplaceAll(s3Escape(name), "/", "%2F")+"="+strings.ReplaceAll(s3Escape(value), "/", "%2F"))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// sha256Hex returns the hex encoded SHA-256 checksum of data.
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSha256 returns the HMAC-SHA256 of data with key.
+func hmacSha256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
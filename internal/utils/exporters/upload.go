@@ -0,0 +1,202 @@
+package exporters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// defaultPartSize is the size of the parts files larger than it are uploaded in, the
+// parts being uploaded concurrently.
+const defaultPartSize = 8 << 20
+
+// Uploader pushes saved files to remote storage.
+type Uploader interface {
+	// Upload uploads the file at path as key, a slash separated path relative to the
+	// destination.
+	Upload(ctx context.Context, key, path string) error
+}
+
+// UploadOptions holds the settings of the uploads to remote storage. User and Password
+// are the access key ID and secret access key of S3, falling back to the
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY environment variables, or the user name
+// and password of WebDAV. Endpoint and Region are those of S3, the endpoint defaulting
+// to the AWS one of the region, and the region to AWS_REGION or us-east-1.
+type UploadOptions struct {
+	Concurrency int
+	Endpoint    string
+	Password    string
+	Region      string
+	User        string
+}
+
+// ValidateUploadDestination checks that destination is an s3://bucket/prefix,
+// webdav://host/path or webdavs://host/path URL.
+func ValidateUploadDestination(destination string) error {
+	_, err := parseUploadDestination(destination)
+	return err
+}
+
+// parseUploadDestination parses the destination URL of the uploads, returning an error
+// if it isn't one of the supported schemes or has no bucket or host.
+func parseUploadDestination(destination string) (*url.URL, error) {
+	target, err := url.Parse(destination)
+	if err != nil {
+		return nil, fmt.Errorf("invalid upload destination %q: %w", destination, err)
+	}
+	switch target.Scheme {
+	case "s3", "webdav", "webdavs":
+	case "sftp":
+		return nil, fmt.Errorf("unsupported upload destination %q: sftp isn't supported, use s3:// or webdav(s)://", destination)
+	default:
+		return nil, fmt.Errorf("unsupported upload destination %q, expected s3://bucket/prefix or webdav(s)://host/path", destination)
+	}
+	if target.Host == "" {
+		return nil, fmt.Errorf("invalid upload destination %q: no bucket or host", destination)
+	}
+	if target.User != nil {
+		return nil, fmt.Errorf("invalid upload destination %q: give the credentials with --upload-user and --upload-password rather than in the URL", destination)
+	}
+	return target, nil
+}
+
+// NewUploader returns the uploader of destination, an s3://bucket/prefix or
+// webdav(s)://host/path URL, sending its requests with client. Returns an error if the
+// destination isn't supported or S3 has no credentials.
+func NewUploader(destination string, options UploadOptions, client *http.Client) (Uploader, error) {
+	target, err := parseUploadDestination(destination)
+	if err != nil {
+		return nil, err
+	}
+	concurrency := max(options.Concurrency, 1)
+
+	if target.Scheme != "s3" {
+		scheme := "https"
+		if target.Scheme == "webdav" {
+			scheme = "http"
+		}
+		return &WebDAVUploader{
+			BaseUrl:  scheme + "://" + target.Host + strings.TrimRight(target.Path, "/"),
+			Client:   client,
+			Password: options.Password,
+			User:     options.User,
+		}, nil
+	}
+
+	accessKey, secretKey := options.User, options.Password
+	if accessKey == "" && secretKey == "" {
+		accessKey, secretKey = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+	if accessKey == "" || secretKey == "" {
+		return nil, fmt.Errorf("no S3 credentials: give them with --upload-user and --upload-password, or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	region := options.Region
+	if region == "" {
+		region = os.Getenv("AWS_REGION")
+	}
+	if region == "" {
+		region = "us-east-1"
+	}
+	endpoint := options.Endpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", region)
+	}
+
+	return &S3Uploader{
+		AccessKey:    accessKey,
+		Bucket:       target.Host,
+		Client:       client,
+		Concurrency:  concurrency,
+		Endpoint:     strings.TrimRight(endpoint, "/"),
+		PartSize:     defaultPartSize,
+		Prefix:       strings.Trim(target.Path, "/"),
+		Region:       region,
+		SecretKey:    secretKey,
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}, nil
+}
+
+// UploadFiles uploads the files at paths, saved within baseDir, keyed by their path
+// relative to it, up to concurrency files at a time. Every file is attempted, the
+// error of the first one failing being returned.
+func UploadFiles(ctx context.Context, uploader Uploader, baseDir string, paths []string, concurrency int) error {
+	group := errgroup.Group{}
+	group.SetLimit(max(concurrency, 1))
+
+	for _, filePath := range paths {
+		key, err := filepath.Rel(baseDir, filePath)
+		if err != nil || key == ".." || strings.HasPrefix(key, ".."+string(filepath.Separator)) {
+			return fmt.Errorf("can't upload %s: it isn't within %s", filePath, baseDir)
+		}
+		key = path.Clean(filepath.ToSlash(key))
+
+		group.Go(func() error {
+			if err := uploader.Upload(ctx, key, filePath); err != nil {
+				return fmt.Errorf("error uploading %s: %w", filePath, err)
+			}
+			return nil
+		})
+	}
+	return group.Wait()
+}
+
+// SavedFiles collects the paths of the files saved during a run, to upload them once
+// it is done. It is safe for concurrent use, and a nil SavedFiles ignores the files.
+type SavedFiles struct {
+	mu    sync.Mutex
+	paths map[string]bool
+}
+
+// savedFilesKey is the context key of the SavedFiles of a run.
+type savedFilesKey struct{}
+
+// WithSavedFiles returns a copy of ctx carrying files, collecting the files saved by
+// the scrape it is given to.
+func WithSavedFiles(ctx context.Context, files *SavedFiles) context.Context {
+	return context.WithValue(ctx, savedFilesKey{}, files)
+}
+
+// SavedFilesFrom returns the SavedFiles carried by ctx, or nil when there is none.
+func SavedFilesFrom(ctx context.Context) *SavedFiles {
+	files, _ := ctx.Value(savedFilesKey{}).(*SavedFiles)
+	return files
+}
+
+// Add records the paths of saved files. Files saved several times are recorded once.
+func (s *SavedFiles) Add(paths ...string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.paths == nil {
+		s.paths = map[string]bool{}
+	}
+	for _, path := range paths {
+		s.paths[path] = true
+	}
+}
+
+// Paths returns the paths of the saved files, sorted.
+func (s *SavedFiles) Paths() []string {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.paths))
+	for path := range s.paths {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
@@ -0,0 +1,119 @@
+package exporters
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+// WebDAVUploader uploads files to a WebDAV server below BaseUrl, creating the
+// collections of their keys as needed, and authenticating with basic authentication
+// when User is set.
+type WebDAVUploader struct {
+	BaseUrl  string
+	Client   *http.Client
+	Password string
+	User     string
+
+	mu      sync.Mutex
+	created map[string]bool
+}
+
+// Upload uploads the file at path as key below the base URL, creating the collections
+// of the key first. Returns an error if the file can't be read or the server rejects a
+// request.
+func (u *WebDAVUploader) Upload(ctx context.Context, key, filePath string) error {
+	if err := u.makeCollections(ctx, path.Dir(key)); err != nil {
+		return err
+	}
+
+	file, err := fsys.Default.OpenFile(filePath, os.O_RDONLY, 0)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.url(key), file)
+	if err != nil {
+		return err
+	}
+	if info, err := fsys.Default.Stat(filePath); err == nil {
+		req.ContentLength = info.Size()
+	}
+	resp, err := u.send(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return fmt.Errorf("WebDAV server returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// makeCollections creates the collection dir and its parents, relative to the base URL,
+// skipping those created before. A collection that already exists is answered with 405
+// Method Not Allowed, which isn't an error.
+func (u *WebDAVUploader) makeCollections(ctx context.Context, dir string) error {
+	if dir == "." || dir == "/" {
+		return nil
+	}
+	u.mu.Lock()
+	done := u.created[dir]
+	u.mu.Unlock()
+	if done {
+		return nil
+	}
+	if err := u.makeCollections(ctx, path.Dir(dir)); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "MKCOL", u.url(dir)+"/", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := u.send(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+		return fmt.Errorf("error creating the collection %s: WebDAV server returned status %d", dir, resp.StatusCode)
+	}
+
+	u.mu.Lock()
+	if u.created == nil {
+		u.created = map[string]bool{}
+	}
+	u.created[dir] = true
+	u.mu.Unlock()
+	return nil
+}
+
+// send sends the request, with basic authentication when a user is set.
+func (u *WebDAVUploader) send(req *http.Request) (*http.Response, error) {
+	if u.User != "" {
+		req.SetBasicAuth(u.User, u.Password)
+	}
+	return u.Client.Do(req)
+}
+
+// url returns the URL of key below the base URL, with each of its segments escaped.
+func (u *WebDAVUploader) url(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = pathEscape(segment)
+	}
+	return u.BaseUrl + "/" + strings.Join(segments, "/")
+}
+
+// pathEscape escapes a segment of a URL path, spaces included.
+func pathEscape(segment string) string {
+	return strings.ReplaceAll(s3Escape(segment), "/", "%2F")
+}
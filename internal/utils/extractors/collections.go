@@ -0,0 +1,61 @@
+package extractors
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// ExtractCollectionInfo parses a collection page to extract its name, author, revision
+// and the mods it contains. Each mod is read from a link to its mod page, together with
+// the version and optional badge found in the same row; a mod linked more than once is
+// only listed once.
+func ExtractCollectionInfo(doc *goquery.Document) types.Collection {
+	return types.Collection{
+		Name:     extractElementText(doc, "h1"),
+		Author:   formatters.CleanTextSelect(doc.Find("a[href*='/users/']").First()),
+		Revision: formatters.CleanTextSelect(doc.Find("[data-e2eid='collection-revision'], .collection-revision").First()),
+		Mods:     extractCollectionMods(doc),
+	}
+}
+
+// extractCollectionMods returns the mods linked from the collection's mod list, in page
+// order and without duplicates.
+func extractCollectionMods(doc *goquery.Document) []types.CollectionMod {
+	links := doc.Find("a[href*='/mods/']")
+	mods := make([]types.CollectionMod, 0, links.Length())
+	seen := map[string]int{}
+
+	links.Each(func(i int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		game, modID, err := formatters.ParseModUrl(href)
+		if err != nil {
+			return
+		}
+
+		// Tiles often link the same mod from both its image and its name
+		key := fmt.Sprintf("%s/%d", strings.ToLower(game), modID)
+		if index, ok := seen[key]; ok {
+			if mods[index].Name == "" {
+				mods[index].Name = formatters.CleanTextSelect(link)
+			}
+			return
+		}
+		seen[key] = len(mods)
+
+		row := link.Closest("tr, li, [data-e2eid='mod-tile']")
+		mods = append(mods, types.CollectionMod{
+			Game:     game,
+			ModID:    modID,
+			Name:     formatters.CleanTextSelect(link),
+			Optional: row.Find("[data-e2eid='mod-optional'], .optional").Length() > 0,
+			Url:      strings.TrimSpace(href),
+			Version:  formatters.CleanTextSelect(row.Find("[data-e2eid='mod-version'], .version").First()),
+		})
+	})
+
+	return mods
+}
@@ -0,0 +1,46 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCollectionInfo(t *testing.T) {
+	// Arrange
+	html := `
+		<h1>Skyrim Essentials</h1>
+		<a href="https://next.nexusmods.com/profile/curator">Profile</a>
+		<a href="https://www.nexusmods.com/users/1234">Curator</a>
+		<span data-e2eid="collection-revision">Revision 7</span>
+		<table>
+			<tbody>
+				<tr>
+					<td><a href="https://www.nexusmods.com/skyrimspecialedition/mods/266"><img src="x.png"></a></td>
+					<td><a href="https://www.nexusmods.com/skyrimspecialedition/mods/266">Unofficial Patch</a></td>
+					<td data-e2eid="mod-version">4.3.2</td>
+				</tr>
+				<tr>
+					<td><a href="https://www.nexusmods.com/skyrimspecialedition/mods/12604">SKSE Address Library</a></td>
+					<td data-e2eid="mod-version">11</td>
+					<td><span data-e2eid="mod-optional">Optional</span></td>
+				</tr>
+			</tbody>
+		</table>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	collection := ExtractCollectionInfo(doc)
+
+	// Assert
+	assert.Equal(t, "Skyrim Essentials", collection.Name)
+	assert.Equal(t, "Curator", collection.Author)
+	assert.Equal(t, "Revision 7", collection.Revision)
+	assert.Equal(t, []types.CollectionMod{
+		{Game: "skyrimspecialedition", ModID: 266, Name: "Unofficial Patch", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/266", Version: "4.3.2"},
+		{Game: "skyrimspecialedition", ModID: 12604, Name: "SKSE Address Library", Optional: true, Url: "https://www.nexusmods.com/skyrimspecialedition/mods/12604", Version: "11"},
+	}, collection.Mods)
+}
@@ -0,0 +1,91 @@
+package extractors
+
+import (
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+
+	// Registers the pure Go "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+)
+
+// chromeEpochOffset is the number of microseconds between the start of the timestamps
+// of Chromium cookie databases, January 1, 1601, and the Unix epoch.
+const chromeEpochOffset = 11644473600000000
+
+// cookieWriteColumns are the columns of the browser cookie databases recording when
+// each cookie was last written or used, tried in order: the last update and last
+// access of Chromium-based browsers, older versions only having the latter, and the
+// last access of Firefox.
+var cookieWriteColumns = []struct {
+	table  string
+	host   string
+	column string
+	toTime func(value int64) time.Time
+}{
+	{table: "cookies", host: "host_key", column: "last_update_utc", toTime: chromeTime},
+	{table: "cookies", host: "host_key", column: "last_access_utc", toTime: chromeTime},
+	{table: "moz_cookies", host: "host", column: "lastAccessed", toTime: time.UnixMicro},
+}
+
+// lastWriteTime returns the last time the browser wrote or used any of the named
+// cookies of the domain, as recorded in the cookie database at path, or failing that,
+// e.g. for stores that aren't SQLite databases, the modification time of the store
+// file. It returns the zero time if neither can be read.
+func lastWriteTime(path, domain string, names []string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	if written, ok := cookieWriteTime(path, domain, names); ok {
+		return written
+	}
+
+	info, err := fsys.Default.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// cookieWriteTime reads the most recent write of the named cookies of the domain from
+// the cookie database at path, trying each of cookieWriteColumns. The database is
+// opened read only and as immutable, so that a running browser holding it is left
+// alone; it lives on disk rather than in fsys.Default as SQLite does its own file
+// handling. Reports false if the database records no write of the cookies.
+func cookieWriteTime(path, domain string, names []string) (time.Time, bool) {
+	if len(names) == 0 {
+		return time.Time{}, false
+	}
+
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path)+"?mode=ro&immutable=1")
+	if err != nil {
+		return time.Time{}, false
+	}
+	defer db.Close()
+
+	args := []any{domain}
+	for _, name := range names {
+		args = append(args, name)
+	}
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", ")
+
+	for _, column := range cookieWriteColumns {
+		query := fmt.Sprintf("SELECT MAX(%s) FROM %s WHERE instr(%s, ?) > 0 AND name IN (%s)", column.column, column.table, column.host, placeholders)
+		var written sql.NullInt64
+		if err := db.QueryRow(query, args...).Scan(&written); err != nil || !written.Valid || written.Int64 <= 0 {
+			continue
+		}
+		return column.toTime(written.Int64).UTC(), true
+	}
+	return time.Time{}, false
+}
+
+// chromeTime returns the time of a timestamp of a Chromium cookie database, counted in
+// microseconds since January 1, 1601.
+func chromeTime(value int64) time.Time {
+	return time.UnixMicro(value - chromeEpochOffset)
+}
@@ -0,0 +1,93 @@
+package extractors
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeCookieDatabase creates a SQLite cookie database at path with the schema and
+// rows given as statements.
+func writeCookieDatabase(t *testing.T, path string, statements ...string) {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+filepath.ToSlash(path))
+	require.NoError(t, err)
+	defer db.Close()
+	for _, statement := range statements {
+		_, err := db.Exec(statement)
+		require.NoError(t, err)
+	}
+}
+
+func TestLastWriteTime(t *testing.T) {
+	written := time.Date(2024, time.June, 3, 8, 0, 0, 0, time.UTC)
+	older := written.Add(-48 * time.Hour)
+
+	tests := []struct {
+		name       string
+		statements []string
+	}{
+		{
+			name: "chrome",
+			statements: []string{
+				"CREATE TABLE cookies (host_key TEXT, name TEXT, last_access_utc INTEGER, last_update_utc INTEGER)",
+				"INSERT INTO cookies VALUES ('.nexusmods.com', 'session', 0, " + strconv.FormatInt(written.UnixMicro()+chromeEpochOffset, 10) + ")",
+				"INSERT INTO cookies VALUES ('.nexusmods.com', 'refresh', 0, " + strconv.FormatInt(older.UnixMicro()+chromeEpochOffset, 10) + ")",
+				"INSERT INTO cookies VALUES ('.example.com', 'session', 0, " + strconv.FormatInt(time.Now().UnixMicro()+chromeEpochOffset, 10) + ")",
+			},
+		},
+		{
+			name: "older chrome",
+			statements: []string{
+				"CREATE TABLE cookies (host_key TEXT, name TEXT, last_access_utc INTEGER)",
+				"INSERT INTO cookies VALUES ('.nexusmods.com', 'session', " + strconv.FormatInt(written.UnixMicro()+chromeEpochOffset, 10) + ")",
+			},
+		},
+		{
+			name: "firefox",
+			statements: []string{
+				"CREATE TABLE moz_cookies (host TEXT, name TEXT, lastAccessed INTEGER)",
+				"INSERT INTO moz_cookies VALUES ('.nexusmods.com', 'session', " + strconv.FormatInt(written.UnixMicro(), 10) + ")",
+				"INSERT INTO moz_cookies VALUES ('.nexusmods.com', 'tracking', " + strconv.FormatInt(time.Now().UnixMicro(), 10) + ")",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange: the store file was written after the cookies, e.g. for other sites
+			path := filepath.Join(t.TempDir(), "Cookies")
+			writeCookieDatabase(t, path, tt.statements...)
+			require.NoError(t, os.Chtimes(path, time.Now(), time.Now()))
+
+			// Act
+			lastWrite := lastWriteTime(path, "nexusmods.com", []string{"session", "refresh"})
+
+			// Assert
+			assert.True(t, written.Equal(lastWrite), "got %s", lastWrite)
+		})
+	}
+}
+
+func TestLastWriteTime_Unknown(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "Cookies")
+	writeCookieDatabase(t, path, "CREATE TABLE cookies (host_key TEXT, name TEXT, last_access_utc INTEGER)")
+	modified := time.Date(2024, time.June, 3, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(path, modified, modified))
+
+	// Act
+	noCookies := lastWriteTime(path, "nexusmods.com", []string{"session"})
+	missing := lastWriteTime(filepath.Join(t.TempDir(), "missing"), "nexusmods.com", []string{"session"})
+
+	// Assert
+	assert.True(t, modified.Equal(noCookies), "the store file's modification time is used when the database records no write")
+	assert.True(t, missing.IsZero())
+	assert.True(t, lastWriteTime("", "nexusmods.com", []string{"session"}).IsZero())
+}
@@ -4,8 +4,13 @@ import (
 	"errors"
 
 	"fmt"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
 
@@ -34,24 +39,57 @@ func IsAdultContent(doc *goquery.Document, modId int64) bool {
 	return false
 }
 
+// ModStatus returns the status of the mod identified by modId when the goquery document
+// is one of the pages Nexus Mods shows in place of a mod that is no longer available,
+// found like adult content from the h3 tag with the corresponding modId: "hidden" for
+// a hidden mod, "removed" for a mod removed or deleted by its author, and "not_found"
+// for a mod that doesn't exist. Returns an empty string for any other page.
+func ModStatus(doc *goquery.Document, modId int64) string {
+	title := strings.ToLower(strings.TrimSpace(doc.Find(fmt.Sprintf("#%d-title", modId)).Text()))
+
+	switch {
+	case title == "":
+		return ""
+	case strings.Contains(title, "hidden"):
+		return types.ModStatusHidden
+	case strings.Contains(title, "removed"), strings.Contains(title, "deleted"):
+		return types.ModStatusRemoved
+	case strings.Contains(title, "not found"):
+		return types.ModStatusNotFound
+	}
+	return ""
+}
+
 // CookieExtractor extracts valid cookies for a specified domain from available cookie stores.
 // It takes a domain, a list of valid cookie names, and a store provider function that returns
-// cookie stores. Returns a map of cookie names and values, or an error if no cookies are found
-// or if an error occurs while reading the stores.
+// cookie stores. Cookies found in every store are merged together. Returns a map of cookie
+// names and values, or an error if no cookies are found or if an error occurs while reading
+// the stores.
 func CookieExtractor(domain string, validCookies []string, storeProvider func() []kooky.CookieStore) (map[string]string, error) {
-	// Declare a map to store cookies
-	cookies := make(map[string]string)
+	candidates, err := CookieCandidates(domain, validCookies, storeProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeCandidateCookies(candidates), nil
+}
 
+// CookieCandidates reads the valid cookies for a specified domain from each available
+// cookie store separately, returning one candidate per store that holds at least one of
+// the valid cookies. Each candidate records when its cookies were created, expire and
+// were last written, as stored in the browser database. Returns an error if no cookie
+// stores exist or no store holds any of the valid cookies.
+func CookieCandidates(domain string, validCookies []string, storeProvider func() []kooky.CookieStore) ([]types.CookieCandidate, error) {
 	// Find all available cookie stores (for all browsers)
 	cookieStores := storeProvider()
 	if len(cookieStores) == 0 {
 		return nil, errors.New("no cookie stores found")
 	}
 
+	var candidates []types.CookieCandidate
+
 	// Iterate over each cookie store
 	for _, store := range cookieStores {
-		defer store.Close()
-
 		// Define filters for valid cookies and specific domain
 		var filters = []kooky.Filter{
 			kooky.Valid,
@@ -60,46 +98,126 @@ func CookieExtractor(domain string, validCookies []string, storeProvider func()
 
 		// Read cookies based on the filters
 		storeCookies, err := store.ReadCookies(filters...)
+
+		// Close the store explicitly after reading its cookies
+		store.Close()
 		if err != nil {
 			continue
 		}
 
 		// Filter and store valid cookies in the map
+		cookies := make(map[string]string)
+		var createdAt, expiresAt time.Time
 		for _, cookie := range storeCookies {
 			for _, valid := range validCookies {
-				if cookie.Name == valid {
-					cookies[cookie.Name] = cookie.Value
+				if cookie.Name != valid {
+					continue
+				}
+				cookies[cookie.Name] = cookie.Value
+				if cookie.Creation.After(createdAt) {
+					createdAt = cookie.Creation
+				}
+				if !cookie.Expires.IsZero() && (expiresAt.IsZero() || cookie.Expires.Before(expiresAt)) {
+					expiresAt = cookie.Expires
 				}
 			}
 		}
 
-		// Close the store explicitly after reading its cookies
-		store.Close()
+		if len(cookies) == 0 {
+			continue
+		}
+
+		candidates = append(candidates, types.CookieCandidate{
+			Browser:     store.Browser(),
+			Profile:     store.Profile(),
+			FilePath:    store.FilePath(),
+			Cookies:     cookies,
+			CreatedAt:   createdAt,
+			ExpiresAt:   expiresAt,
+			LastWriteAt: lastWriteTime(store.FilePath(), domain, validCookies),
+		})
 	}
 
 	// Check if any cookies were found
-	if len(cookies) == 0 {
+	if len(candidates) == 0 {
 		return nil, errors.New("no matching cookies found")
 	}
 
-	// Return the map of cookies
-	return cookies, nil
+	return candidates, nil
+}
+
+// SelectCookieCandidate picks the cookies to use from the candidates returned by
+// CookieCandidates. When several candidates hold the complete set of valid cookies,
+// each of them is checked concurrently with the validate function and the first one
+// (in store order) that actually authenticates is preferred; if none authenticate the
+// first complete candidate is used. A single complete candidate is used as is, and when
+// no candidate is complete the cookies from every candidate are merged together.
+func SelectCookieCandidate(candidates []types.CookieCandidate, validCookies []string, validate func(cookies map[string]string) (bool, error)) types.CookieCandidate {
+	var complete []types.CookieCandidate
+	for _, candidate := range candidates {
+		if candidate.HasAll(validCookies) {
+			complete = append(complete, candidate)
+		}
+	}
+
+	switch {
+	case len(complete) == 0:
+		return types.CookieCandidate{Browser: "merged", Cookies: mergeCandidateCookies(candidates)}
+	case len(complete) == 1 || validate == nil:
+		return complete[0]
+	}
+
+	// Validate every complete candidate concurrently
+	valid := make([]bool, len(complete))
+	var wg sync.WaitGroup
+	for i, candidate := range complete {
+		wg.Add(1)
+		go func(i int, cookies map[string]string) {
+			defer wg.Done()
+			ok, err := validate(cookies)
+			valid[i] = ok && err == nil
+		}(i, candidate.Cookies)
+	}
+	wg.Wait()
+
+	for i, candidate := range complete {
+		if valid[i] {
+			candidate.Validated = true
+			return candidate
+		}
+	}
+
+	return complete[0]
+}
+
+// mergeCandidateCookies combines the cookies of every candidate into a single map,
+// with later candidates overriding earlier ones for the same cookie name.
+func mergeCandidateCookies(candidates []types.CookieCandidate) map[string]string {
+	cookies := make(map[string]string)
+	for _, candidate := range candidates {
+		for name, value := range candidate.Cookies {
+			cookies[name] = value
+		}
+	}
+	return cookies
 }
 
 // extractChangeLogs parses a goquery document to extract versioned change logs.
 // It looks for specific elements containing version and log notes, and returns
-// a slice of ChangeLog objects with the version and corresponding notes.
+// a slice of ChangeLog objects with the version and corresponding notes, empty if the
+// mod has no changelog.
 func extractChangeLogs(doc *goquery.Document) []types.ChangeLog {
-	var changeLogs []types.ChangeLog
+	changeLogs := make([]types.ChangeLog, 0)
+	sel := selectors.Current.ModPage.ChangeLog
 
 	// Find each list item (li) containing a version and its change log notes
-	doc.Find("div.accordionitems > dl > dd > div > ul > li").Each(func(i int, s *goquery.Selection) {
+	doc.Find(sel.Item).Each(func(i int, s *goquery.Selection) {
 		// Extract the version from the h3 tag within this li element
-		version := strings.TrimSpace(s.Find("h3").Text())
+		version := strings.TrimSpace(s.Find(sel.Version).Text())
 
 		var notes []string
 		// Extract the notes from the log-change div inside this li element
-		s.Find("div.log-change > ul > li").Each(func(j int, li *goquery.Selection) {
+		s.Find(sel.Notes).Each(func(j int, li *goquery.Selection) {
 			note := strings.TrimSpace(li.Text())
 			if note != "" {
 				notes = append(notes, note)
@@ -124,6 +242,29 @@ func extractElementText(doc *goquery.Document, selector string) string {
 	return formatters.CleanAndFormatText(doc.Find(selector).Text())
 }
 
+// extractTimestamp parses the time element matching the provided CSS selector into a
+// Timestamp, preferring its machine readable datetime attribute and falling back to the
+// displayed text. It returns nil if the element is missing or the date can't be parsed.
+func extractTimestamp(doc *goquery.Document, selector string) *types.Timestamp {
+	selection := doc.Find(selector).First()
+	if selection.Length() == 0 {
+		return nil
+	}
+
+	if datetime, ok := selection.Attr("datetime"); ok {
+		if parsed, err := formatters.ParseNexusDate(datetime); err == nil {
+			return types.NewTimestamp(parsed)
+		}
+	}
+
+	parsed, err := formatters.ParseNexusDate(formatters.CleanAndFormatText(selection.Text()))
+	if err != nil {
+		return nil
+	}
+
+	return types.NewTimestamp(parsed)
+}
+
 // extractCleanTextExcludingElementText retrieves the text content of the first
 // element matching the selector, removes any text from the excluded sub-elements
 // matching elem, and returns the cleaned and formatted text.
@@ -146,83 +287,360 @@ func extractCleanTextExcludingElementText(doc *goquery.Document, selector, elem
 
 // ExtractFileInfo parses a goquery document to extract file information, such as
 // name, version, upload date, file size, unique downloads, total downloads, and
-// description. Returns a slice of File objects with the extracted details.
-func ExtractFileInfo(doc *goquery.Document) []types.File {
-	fileElements := doc.Find(".file-expander-header")
+// description, along with the size in bytes and the download counts when they can be
+// parsed. The file ID is read from the expander element and, when modUrl is
+// provided, combined with it to build the file's download page link. Pages of the
+// redesigned frontend (see DetectLayout) are read by its own extractor. Returns a
+// slice of File objects with the extracted details.
+func ExtractFileInfo(doc *goquery.Document, modUrl string) []types.File {
+	if DetectLayout(doc) == LayoutNext {
+		return extractNextFileInfo(doc, modUrl)
+	}
+
+	sel := selectors.Current.FilesTab
+	fileElements := doc.Find(sel.File)
 	files := make([]types.File, 0, fileElements.Length())
 
 	fileElements.Each(func(i int, s *goquery.Selection) {
 		file := types.File{
-			Name:        formatters.CleanTextSelect(s.Find("p")),
-			Version:     formatters.CleanTextSelect(s.Find(".stat-version .stat")),
-			UploadDate:  formatters.CleanTextSelect(s.Find(".stat-uploaddate .stat")),
-			FileSize:    formatters.CleanTextSelect(s.Find(".stat-filesize .stat")),
-			UniqueDLs:   formatters.CleanTextSelect(s.Find(".stat-uniquedls .stat")),
-			TotalDLs:    formatters.CleanTextSelect(s.Find(".stat-totaldls .stat")),
-			Description: formatters.CleanTextSelect(s.Next().Find(".tabbed-block.files-description")),
-		}
-		files = append(files, file)
+			Category:    extractFileCategory(s),
+			FileID:      extractFileID(s),
+			Name:        formatters.CleanTextSelect(s.Find(sel.Name)),
+			Version:     formatters.CleanTextSelect(s.Find(sel.Version)),
+			UploadDate:  formatters.CleanTextSelect(s.Find(sel.UploadDate)),
+			FileSize:    formatters.CleanTextSelect(s.Find(sel.FileSize)),
+			UniqueDLs:   formatters.CleanTextSelect(s.Find(sel.UniqueDLs)),
+			TotalDLs:    formatters.CleanTextSelect(s.Find(sel.TotalDLs)),
+			Description: formatters.CleanTextSelect(s.Next().Find(sel.Description)),
+		}
+		files = append(files, completeFile(file, s.Find(sel.MD5).AddSelection(s.Next().Find(sel.MD5)), modUrl))
 	})
 
 	return files
 }
 
-// ExtractModInfo parses a goquery document to extract detailed mod information,
-// including name, last updated date, original upload date, creator, changelogs,
-// uploader, virus status, short description, full description, tags, dependencies,
-// and mods requiring this file. Returns a ModInfo object with the extracted details.
+// completeFile fills in the details of a file derived from those read from the files
+// tab: its MD5 checksum, read from md5 or failing that its description, its size and
+// download counts as numbers, and its download URL on the mod page at modUrl.
+func completeFile(file types.File, md5 *goquery.Selection, modUrl string) types.File {
+	file.MD5 = extractMD5(md5, file.Description)
+	file.FileSizeBytes, _ = formatters.ParseSize(file.FileSize)
+	file.TotalDLCount, _ = formatters.ParseCount(file.TotalDLs)
+	file.UniqueDLCount, _ = formatters.ParseCount(file.UniqueDLs)
+	if file.FileID != 0 && modUrl != "" {
+		file.DownloadUrl = fmt.Sprintf("%s?tab=files&file_id=%d", modUrl, file.FileID)
+	}
+	return file
+}
+
+// extractFileCategory returns the Files tab section a file expander element belongs
+// to, e.g. "main", "optional", "old" or "miscellaneous". The section is read from the
+// enclosing "file-container-<category>-files" element or, failing that, from the
+// heading of the enclosing files section. It returns "" if no section can be found.
+func extractFileCategory(s *goquery.Selection) string {
+	if containerID, ok := s.Closest("[id^='file-container-']").Attr("id"); ok {
+		category := strings.TrimSuffix(strings.TrimPrefix(containerID, "file-container-"), "-files")
+		if category != "" {
+			return strings.ToLower(category)
+		}
+	}
+
+	heading := formatters.CleanTextSelect(s.Closest(".files-tabs").Find(".file-category-header h2").First())
+	return normalizeFileCategory(heading)
+}
+
+// normalizeFileCategory turns a section heading or user supplied category such as
+// "Optional files" into its short lowercase form ("optional").
+func normalizeFileCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	category = strings.TrimSuffix(category, " files")
+	category = strings.TrimSuffix(category, " file")
+	return strings.TrimSpace(category)
+}
+
+// FilterFilesByCategory returns only the files whose category is one of the given
+// categories, compared case-insensitively ("optional" and "Optional files" are the
+// same). It returns the files unchanged when no categories are given.
+func FilterFilesByCategory(files []types.File, categories []string) []types.File {
+	if len(categories) == 0 {
+		return files
+	}
+
+	wanted := make(map[string]bool, len(categories))
+	for _, category := range categories {
+		wanted[normalizeFileCategory(category)] = true
+	}
+
+	filtered := make([]types.File, 0, len(files))
+	for _, file := range files {
+		if wanted[normalizeFileCategory(file.Category)] {
+			filtered = append(filtered, file)
+		}
+	}
+	return filtered
+}
+
+// extractFileID returns the Nexus file ID of a file expander element, read from its
+// data-id attribute or, failing that, from its "file-expander-header-<id>" element ID.
+// It returns 0 if no ID can be found.
+func extractFileID(s *goquery.Selection) int64 {
+	if dataID, ok := s.Attr("data-id"); ok {
+		if id, err := formatters.StrToInt(strings.TrimSpace(dataID)); err == nil {
+			return id
+		}
+	}
+
+	if elemID, ok := s.Attr("id"); ok {
+		if id, err := formatters.StrToInt(strings.TrimPrefix(elemID, "file-expander-header-")); err == nil {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// md5Pattern matches an MD5 checksum, and labelledMD5Pattern one labelled as such in a
+// file description.
+var (
+	md5Pattern         = regexp.MustCompile(`(?i)\b[0-9a-f]{32}\b`)
+	labelledMD5Pattern = regexp.MustCompile(`(?i)\bmd5\b[^0-9a-f]{0,10}([0-9a-f]{32})\b`)
+)
+
+// extractMD5 returns the MD5 checksum of a file, lowercased, as the download popup
+// shows it for some games: read from the checksum element's data-md5 attribute or text
+// or, failing that, from an "MD5: ..." line of the file description. It returns "" if
+// the file has no checksum.
+func extractMD5(s *goquery.Selection, description string) string {
+	dataMD5, _ := s.Attr("data-md5")
+	for _, candidate := range []string{dataMD5, formatters.CleanTextSelect(s.First())} {
+		if checksum := md5Pattern.FindString(candidate); checksum != "" {
+			return strings.ToLower(checksum)
+		}
+	}
+
+	if match := labelledMD5Pattern.FindStringSubmatch(description); match != nil {
+		return strings.ToLower(match[1])
+	}
+	return ""
+}
+
+// ExtractModInfo parses a goquery document to extract detailed mod information, such
+// as its name, dates, creator, description, requirements and statistics, with the
+// current selectors. Pages of the redesigned frontend (see DetectLayout) are read by
+// its own extractor. Returns a ModInfo object with the extracted details.
 func ExtractModInfo(doc *goquery.Document) types.ModInfo {
+	if DetectLayout(doc) == LayoutNext {
+		return extractNextModInfo(doc)
+	}
+
+	sel := selectors.Current.ModPage
 	return types.ModInfo{
-		Name:             extractElementText(doc, "#pagetitle > h1"),
-		LastUpdated:      extractElementText(doc, "#fileinfo > div:nth-child(2) > time"),
-		OriginalUpload:   extractElementText(doc, "#fileinfo > div:nth-child(3) > time"),
-		Creator:          extractCleanTextExcludingElementText(doc, "#fileinfo > div:nth-child(4)", "h3"),
-		ChangeLogs:       extractChangeLogs(doc),
-		Uploader:         extractElementText(doc, "#fileinfo > div:nth-child(5) > a"),
-		VirusStatus:      extractElementText(doc, "#fileinfo > div:nth-child(6) > div > span"),
-		ShortDescription: extractElementText(doc, "#section > div > div.wrap.flex > div:nth-child(2) > div > div.tabcontent.tabcontent-mod-page > div.container.tab-description > p"),
-		Description:      extractElementText(doc, "#section > div > div.wrap.flex > div:nth-child(2) > div > div.tabcontent.tabcontent-mod-page > div.container.mod_description_container.condensed"),
-		Tags:             extractTags(doc),
-		Dependencies:     extractRequirements(doc, "Nexus requirements"),
-		ModsUsing:        extractRequirements(doc, "Mods requiring this file"),
+		Name:                extractElementText(doc, sel.Name),
+		LastUpdated:         extractElementText(doc, sel.LastUpdated),
+		LastUpdatedAt:       extractTimestamp(doc, sel.LastUpdated),
+		OriginalUpload:      extractElementText(doc, sel.OriginalUpload),
+		OriginalUploadAt:    extractTimestamp(doc, sel.OriginalUpload),
+		Permissions:         extractPermissions(doc),
+		Creator:             extractCleanTextExcludingElementText(doc, sel.Creator, "h3"),
+		ChangeLogs:          extractChangeLogs(doc),
+		Uploader:            extractElementText(doc, sel.Uploader),
+		VirusStatus:         extractElementText(doc, sel.VirusStatus),
+		ShortDescription:    extractElementText(doc, sel.ShortDescription),
+		Description:         extractElementText(doc, sel.Description),
+		Tags:                extractTags(doc),
+		Category:            extractCategory(doc),
+		Translations:        extractTranslations(doc),
+		Dependencies:        extractRequirements(doc, blockTitles(func(t selectors.BlockTitles) string { return t.NexusRequirements })),
+		OffSiteRequirements: extractRequirements(doc, blockTitles(func(t selectors.BlockTitles) string { return t.OffSiteRequirements })),
+		DLCRequirements:     extractRequirements(doc, blockTitles(func(t selectors.BlockTitles) string { return t.DLCRequirements })),
+		ModsUsing:           extractRequirements(doc, blockTitles(func(t selectors.BlockTitles) string { return t.ModsRequiring })),
+		Endorsements:        extractModStat(doc, sel.Stats.Endorsements, "Endorsements"),
+		UniqueDLs:           extractModStat(doc, sel.Stats.UniqueDLs, "Unique DLs"),
+		TotalDLs:            extractModStat(doc, sel.Stats.TotalDLs, "Total DLs"),
+		TotalViews:          extractModStat(doc, sel.Stats.TotalViews, "Total views"),
+		Version:             extractModStat(doc, sel.Stats.Version, "Version"),
+	}
+}
+
+// blockTitles returns the titles a block of the description tab may have, the one
+// title picks from the block titles of the language the pages are read in (see
+// --locale), followed by the English one, so that pages Nexus Mods didn't translate
+// are still read. Languages without a title for the block fall back to English.
+func blockTitles(title func(selectors.BlockTitles) string) []string {
+	titles := selectors.Current.ModPage.Blocks.Titles
+	matched := make([]string, 0, 2)
+	for _, lang := range []string{formatters.Language(), formatters.DefaultLocale} {
+		if t := title(titles[lang]); t != "" && !slices.Contains(matched, t) {
+			matched = append(matched, t)
+		}
 	}
+	return matched
+}
+
+// findBlock returns the first block of the description tab whose title matches one of
+// titles (case-insensitive), or only starts with it when prefix is set. The selection
+// is empty if no block matches.
+func findBlock(doc *goquery.Document, titles []string, prefix bool) *goquery.Selection {
+	sel := selectors.Current.ModPage.Blocks
+	return doc.Find(sel.Block).FilterFunction(func(i int, s *goquery.Selection) bool {
+		blockTitle := strings.ToLower(formatters.CleanTextStr(s.Find(sel.Title).Text()))
+		for _, title := range titles {
+			title = strings.ToLower(title)
+			if blockTitle == title || prefix && strings.HasPrefix(blockTitle, title) {
+				return true
+			}
+		}
+		return false
+	}).First()
 }
 
 // extractRequirements parses a goquery document to extract a list of requirements
-// from a table with the specified title. It returns a slice of Requirement objects
-// containing the name and notes for each requirement. If the table is not found,
-// it returns an empty slice.
-func extractRequirements(doc *goquery.Document, tableTitle string) []types.Requirement {
-	var requirements []types.Requirement
+// from a table with one of the specified titles. It returns a slice of Requirement objects
+// containing the name, notes and link for each requirement; names that aren't links,
+// as in the DLC requirements table, are read from the cell text, and the links within
+// the notes are kept in NotesLinks. If the table is not found, it returns an empty
+// slice.
+func extractRequirements(doc *goquery.Document, tableTitles []string) []types.Requirement {
+	requirements := make([]types.Requirement, 0)
+	sel := selectors.Current.ModPage
 
 	// Find the correct div.tabbed-block
-	block := doc.Find("div.tabbed-block").FilterFunction(func(i int, s *goquery.Selection) bool {
-		return s.Find("h3").Text() == tableTitle
-	}).First()
+	block := findBlock(doc, tableTitles, false)
 
 	if block.Length() == 0 {
 		return requirements // Return empty slice if the table is not found
 	}
 
 	// Preallocate the slice based on the number of rows
-	rowCount := block.Find("table.table.desc-table tbody tr").Length()
+	rowCount := block.Find(sel.Requirements.Rows).Length()
 	requirements = make([]types.Requirement, 0, rowCount)
 
 	// Extract requirements
-	block.Find("table.table.desc-table tbody tr").Each(func(i int, row *goquery.Selection) {
-		name := formatters.CleanTextStr(row.Find("td.table-require-name a").Text())
-		notes := formatters.CleanTextStr(row.Find("td.table-require-notes").Text())
-		requirements = append(requirements, types.Requirement{Name: name, Notes: notes})
+	block.Find(sel.Requirements.Rows).Each(func(i int, row *goquery.Selection) {
+		nameCell := row.Find(sel.Requirements.Name)
+		link := nameCell.Find("a").First()
+
+		// DLC requirements are plain text rather than links
+		name := formatters.CleanTextStr(link.Text())
+		if link.Length() == 0 {
+			name = formatters.CleanTextStr(nameCell.Text())
+		}
+		notesCell := row.Find(sel.Requirements.Notes)
+		url, _ := link.Attr("href")
+		requirements = append(requirements, types.Requirement{
+			Name:       name,
+			Notes:      formatters.CleanTextStr(notesCell.Text()),
+			NotesLinks: extractLinks(notesCell),
+			Url:        strings.TrimSpace(url),
+		})
 	})
 
 	return requirements
 }
 
+// extractLinks returns the text and URL of every link within the selection, in page
+// order, skipping links without an href. It returns an empty slice if there are none.
+func extractLinks(s *goquery.Selection) []types.Link {
+	links := make([]types.Link, 0)
+	s.Find("a[href]").Each(func(i int, a *goquery.Selection) {
+		url, _ := a.Attr("href")
+		url = strings.TrimSpace(url)
+		if url == "" {
+			return
+		}
+		links = append(links, types.Link{Text: formatters.CleanTextStr(a.Text()), Url: url})
+	})
+	return links
+}
+
+// extractTranslations parses the "Translations available on the Nexus" table of the
+// description tab and returns the localized versions of the mod it lists, with the
+// language read from the first cell and the name and link from the link of the row.
+// The mod ID is parsed from the link and left at zero when it isn't a mod page. It
+// returns an empty slice if the mod has no translations.
+func extractTranslations(doc *goquery.Document) []types.Translation {
+	sel := selectors.Current.ModPage
+	block := findBlock(doc, blockTitles(func(t selectors.BlockTitles) string { return t.Translations }), true)
+
+	rows := block.Find(sel.Translations.Rows)
+	translations := make([]types.Translation, 0, rows.Length())
+	rows.Each(func(i int, row *goquery.Selection) {
+		link := row.Find("a").First()
+		url, _ := link.Attr("href")
+		url = strings.TrimSpace(url)
+		if url == "" {
+			return
+		}
+
+		translation := types.Translation{
+			Language: formatters.CleanTextStr(row.Find("td").First().Text()),
+			Name:     formatters.CleanTextStr(link.Text()),
+			Url:      url,
+		}
+		if _, modID, err := formatters.ParseModUrl(url); err == nil {
+			translation.ModID = modID
+		}
+		translations = append(translations, translation)
+	})
+
+	return translations
+}
+
+// extractModStat returns the value of the statistic of a mod matching selector, such
+// as ".stat-endorsements", whatever the language of the page. Pages whose statistics
+// don't match it are read by title with extractStat instead.
+func extractModStat(doc *goquery.Document, selector, title string) string {
+	sel := selectors.Current.ModPage.Stats
+	if item := doc.Find(sel.Item).Filter(selector).First(); item.Length() > 0 {
+		return formatters.CleanTextSelect(item.Find(sel.Value))
+	}
+	return extractStat(doc, title)
+}
+
+// extractStat parses the statistics block under the page title and returns the value
+// of the statistic whose title matches the provided title (case-insensitive), such as
+// "Endorsements" or "Unique DLs". It returns an empty string if the statistic is not found.
+func extractStat(doc *goquery.Document, title string) string {
+	var value string
+	sel := selectors.Current.ModPage.Stats
+
+	doc.Find(sel.Item).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		if strings.EqualFold(formatters.CleanTextSelect(s.Find(sel.Title)), title) {
+			value = formatters.CleanTextSelect(s.Find(sel.Value))
+			return false
+		}
+		return true
+	})
+
+	return value
+}
+
+// extractCategory returns the category of the mod, the last breadcrumb link leading to
+// a category listing, e.g. "Gameplay" in "Skyrim > Mods > Gameplay". It returns "" if
+// the breadcrumb has no category.
+func extractCategory(doc *goquery.Document) string {
+	return categoryFromLinks(doc.Find(selectors.Current.ModPage.Breadcrumb))
+}
+
+// categoryFromLinks returns the name of the last of the breadcrumb links leading to a
+// category listing, or "" if none does.
+func categoryFromLinks(links *goquery.Selection) string {
+	category := ""
+	links.Each(func(i int, s *goquery.Selection) {
+		if href, _ := s.Attr("href"); strings.Contains(href, "/categories/") {
+			if name := formatters.CleanTextSelect(s); name != "" {
+				category = name
+			}
+		}
+	})
+	return category
+}
+
 // extractTags parses a goquery document to extract all tag labels from the tag
 // elements on the page. It returns a slice of strings representing the tags.
 func extractTags(doc *goquery.Document) []string {
 	// Find all tag elements
-	elements := doc.Find(".sideitems.side-tags .tags li a span.flex-label")
+	elements := doc.Find(selectors.Current.ModPage.Tags)
 
 	// Preallocate the slice
 	tags := make([]string, 0, elements.Length())
@@ -1,19 +1,26 @@
 package extractors
 
 import (
+	"encoding/json"
+	"errors"
 	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
 
 	"github.com/browserutils/kooky"
 	_ "github.com/browserutils/kooky/browser/all"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 type MockCookieStore struct {
@@ -80,6 +87,30 @@ func TestIsAdultContent(t *testing.T) {
 	assert.True(t, result, "Expected true for adult content")
 }
 
+func TestModStatus(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected string
+	}{
+		{"available", `<div id="pagetitle"><h1>SkyUI</h1></div>`, ""},
+		{"adult content", `<h3 id="12345-title">Adult content</h3>`, ""},
+		{"hidden", `<h3 id="12345-title">Hidden mod</h3>`, types.ModStatusHidden},
+		{"removed", `<h3 id="12345-title"> This mod has been removed by its author </h3>`, types.ModStatusRemoved},
+		{"deleted", `<h3 id="12345-title">Mod deleted</h3>`, types.ModStatusRemoved},
+		{"not found", `<h3 id="12345-title">Not found</h3>`, types.ModStatusNotFound},
+		{"other mod", `<h3 id="1-title">Hidden mod</h3>`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+
+			assert.Equal(t, tt.expected, ModStatus(doc, 12345))
+		})
+	}
+}
+
 func TestCookieExtractor_Success(t *testing.T) {
 	// Arrange: Create a mock cookie store
 	mockStore := new(MockCookieStore)
@@ -98,6 +129,9 @@ func TestCookieExtractor_Success(t *testing.T) {
 	// Mock methods that are actually called by CookieExtractor
 	mockStore.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{cookie}, nil)
 	mockStore.On("Close").Return(nil)
+	mockStore.On("Browser").Return("MockBrowser").Maybe()
+	mockStore.On("Profile").Return("Default").Maybe()
+	mockStore.On("FilePath").Return("/mock/cookies").Maybe()
 
 	// Create a mock function that returns the mock store
 	mockStoreProvider := func() []kooky.CookieStore {
@@ -135,6 +169,9 @@ func TestCookieExtractor_NoMatchingCookies(t *testing.T) {
 	// No matching cookies
 	mockStore.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{}, nil)
 	mockStore.On("Close").Return(nil)
+	mockStore.On("Browser").Return("MockBrowser").Maybe()
+	mockStore.On("Profile").Return("Default").Maybe()
+	mockStore.On("FilePath").Return("/mock/cookies").Maybe()
 
 	// Mock function that returns the mock store
 	mockStoreProvider := func() []kooky.CookieStore {
@@ -150,6 +187,113 @@ func TestCookieExtractor_NoMatchingCookies(t *testing.T) {
 	assert.Equal(t, "no matching cookies found", err.Error())
 }
 
+func TestExtractFileInfo_Categories(t *testing.T) {
+	// Arrange
+	html := `<div class="files-tabs" id="file-container-main-files">
+				<div class="file-category-header"><h2>Main files</h2></div>
+				<dl><dt class="file-expander-header"><p>Main</p></dt><dd></dd></dl>
+			</div>
+			<div class="files-tabs" id="file-container-optional-files">
+				<div class="file-category-header"><h2>Optional files</h2></div>
+				<dl><dt class="file-expander-header"><p>Patch</p></dt><dd></dd></dl>
+			</div>
+			<div class="files-tabs">
+				<div class="file-category-header"><h2>Old files</h2></div>
+				<dl><dt class="file-expander-header"><p>Legacy</p></dt><dd></dd></dl>
+			</div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	files := ExtractFileInfo(doc, "")
+
+	// Assert
+	assert.Len(t, files, 3)
+	assert.Equal(t, "main", files[0].Category)
+	assert.Equal(t, "optional", files[1].Category)
+	assert.Equal(t, "old", files[2].Category)
+}
+
+func TestFilterFilesByCategory(t *testing.T) {
+	files := []types.File{
+		{Name: "Main", Category: "main"},
+		{Name: "Patch", Category: "optional"},
+		{Name: "Legacy", Category: "old"},
+	}
+
+	assert.Equal(t, files, FilterFilesByCategory(files, nil))
+	assert.Equal(t, []types.File{files[0], files[1]}, FilterFilesByCategory(files, []string{"Main", "optional files"}))
+	assert.Empty(t, FilterFilesByCategory(files, []string{"miscellaneous"}))
+}
+
+func TestCookieCandidates_PerStore(t *testing.T) {
+	// Arrange: two stores, each holding a different session
+	newStore := func(browser, value string) *MockCookieStore {
+		store := new(MockCookieStore)
+		store.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{
+			{Cookie: http.Cookie{Name: "session", Value: value}},
+			{Cookie: http.Cookie{Name: "ignored", Value: "x"}},
+		}, nil)
+		store.On("Close").Return(nil)
+		store.On("Browser").Return(browser)
+		store.On("Profile").Return("Default")
+		store.On("FilePath").Return("/" + browser)
+		return store
+	}
+	chrome, firefox := newStore("chrome", "1"), newStore("firefox", "2")
+
+	// Act
+	candidates, err := CookieCandidates("example.com", []string{"session"}, func() []kooky.CookieStore {
+		return []kooky.CookieStore{chrome, firefox}
+	})
+
+	// Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []types.CookieCandidate{
+		{Browser: "chrome", Profile: "Default", FilePath: "/chrome", Cookies: map[string]string{"session": "1"}},
+		{Browser: "firefox", Profile: "Default", FilePath: "/firefox", Cookies: map[string]string{"session": "2"}},
+	}, candidates)
+}
+
+func TestSelectCookieCandidate(t *testing.T) {
+	names := []string{"session", "refresh"}
+	loggedOut := types.CookieCandidate{Browser: "chrome", Cookies: map[string]string{"session": "old", "refresh": "old"}}
+	loggedIn := types.CookieCandidate{Browser: "firefox", Cookies: map[string]string{"session": "new", "refresh": "new"}}
+	partial := types.CookieCandidate{Browser: "edge", Cookies: map[string]string{"session": "partial"}}
+
+	validate := func(cookies map[string]string) (bool, error) {
+		return cookies["session"] == "new", nil
+	}
+
+	t.Run("Prefers the candidate that authenticates", func(t *testing.T) {
+		selected := SelectCookieCandidate([]types.CookieCandidate{loggedOut, partial, loggedIn}, names, validate)
+		assert.Equal(t, "firefox", selected.Browser)
+		assert.True(t, selected.Validated)
+	})
+
+	t.Run("Falls back to the first complete candidate", func(t *testing.T) {
+		never := func(cookies map[string]string) (bool, error) { return false, errors.New("offline") }
+		selected := SelectCookieCandidate([]types.CookieCandidate{partial, loggedOut, loggedIn}, names, never)
+		assert.Equal(t, "chrome", selected.Browser)
+		assert.False(t, selected.Validated)
+	})
+
+	t.Run("Single complete candidate is not validated", func(t *testing.T) {
+		called := false
+		selected := SelectCookieCandidate([]types.CookieCandidate{partial, loggedOut}, names, func(map[string]string) (bool, error) {
+			called = true
+			return false, nil
+		})
+		assert.Equal(t, "chrome", selected.Browser)
+		assert.False(t, called)
+	})
+
+	t.Run("Merges when no candidate is complete", func(t *testing.T) {
+		other := types.CookieCandidate{Browser: "brave", Cookies: map[string]string{"refresh": "r"}}
+		selected := SelectCookieCandidate([]types.CookieCandidate{partial, other}, names, validate)
+		assert.Equal(t, map[string]string{"session": "partial", "refresh": "r"}, selected.Cookies)
+	})
+}
+
 func TestExtractChangeLogs(t *testing.T) {
 	html := `
 		<div id="section">
@@ -232,11 +376,30 @@ func TestExtractFileInfo(t *testing.T) {
 	html := `<div class="file-expander-header"><p>File1</p><div class="stat-version"><div class="stat">v1.0</div></div><div class="stat-uploaddate"><div class="stat">2024-01-01</div></div></div>`
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
 
-	result := ExtractFileInfo(doc)
+	result := ExtractFileInfo(doc, "")
 	assert.Len(t, result, 1)
 	assert.Equal(t, "File1", result[0].Name)
 	assert.Equal(t, "v1.0", result[0].Version)
 	assert.Equal(t, "2024-01-01", result[0].UploadDate)
+	assert.Equal(t, int64(0), result[0].FileID)
+	assert.Equal(t, "", result[0].DownloadUrl)
+}
+
+func TestExtractFileInfo_FileIDAndDownloadUrl(t *testing.T) {
+	html := `<dl>
+				<dt id="file-expander-header-111" class="file-expander-header" data-id="111"><p>Main</p></dt>
+				<dd></dd>
+				<dt id="file-expander-header-222" class="file-expander-header"><p>Patch</p></dt>
+				<dd></dd>
+			</dl>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	result := ExtractFileInfo(doc, "https://www.nexusmods.com/skyrim/mods/3863")
+	assert.Len(t, result, 2)
+	assert.Equal(t, int64(111), result[0].FileID)
+	assert.Equal(t, "https://www.nexusmods.com/skyrim/mods/3863?tab=files&file_id=111", result[0].DownloadUrl)
+	assert.Equal(t, int64(222), result[1].FileID)
+	assert.Equal(t, "https://www.nexusmods.com/skyrim/mods/3863?tab=files&file_id=222", result[1].DownloadUrl)
 }
 
 func TestExtractModInfo(t *testing.T) {
@@ -298,18 +461,92 @@ func TestExtractModInfo(t *testing.T) {
 
 	// Assert
 	expectedModInfo := types.ModInfo{
-		Name:           "Mod Name",
-		LastUpdated:    "13 October 2024, 10:44AM",
-		OriginalUpload: "13 October 2024, 10:44AM",
-		Creator:        "Mod Creator",
-		Uploader:       "Uploader Name",
-		VirusStatus:    "Some files not scanned",
-		Tags:           []string{},
+		Name:                "Mod Name",
+		LastUpdated:         "13 October 2024, 10:44AM",
+		LastUpdatedAt:       types.NewTimestamp(time.Date(2024, time.October, 13, 10, 44, 0, 0, time.UTC)),
+		OriginalUpload:      "13 October 2024, 10:44AM",
+		OriginalUploadAt:    types.NewTimestamp(time.Date(2024, time.October, 13, 10, 44, 0, 0, time.UTC)),
+		Creator:             "Mod Creator",
+		Uploader:            "Uploader Name",
+		VirusStatus:         "Some files not scanned",
+		ChangeLogs:          []types.ChangeLog{},
+		Tags:                []string{},
+		Translations:        []types.Translation{},
+		Dependencies:        []types.Requirement{},
+		OffSiteRequirements: []types.Requirement{},
+		DLCRequirements:     []types.Requirement{},
+		ModsUsing:           []types.Requirement{},
 	}
 
 	assert.Equal(t, expectedModInfo, result)
 }
 
+func TestExtractModInfo_MissingSections(t *testing.T) {
+	// Arrange: a page with a title only, no changelog, tags, tables or files
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<div id="pagetitle"><h1>Bare Mod</h1></div>`))
+
+	// Act
+	result := ExtractModInfo(doc)
+	files := ExtractFileInfo(doc, "")
+	data, err := json.Marshal(result)
+
+	// Assert
+	assert.Equal(t, "Bare Mod", result.Name)
+	for name, list := range map[string]any{
+		"ChangeLogs":          result.ChangeLogs,
+		"DLCRequirements":     result.DLCRequirements,
+		"Dependencies":        result.Dependencies,
+		"Files":               files,
+		"ModsUsing":           result.ModsUsing,
+		"OffSiteRequirements": result.OffSiteRequirements,
+		"Tags":                result.Tags,
+		"Translations":        result.Translations,
+	} {
+		assert.NotNil(t, list, name)
+		assert.Empty(t, list, name)
+	}
+	assert.Nil(t, result.Permissions)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "null")
+}
+
+func TestExtractModInfo_SelectorOverride(t *testing.T) {
+	// Arrange: the page title moved to an h2 and the stats to a new list
+	overridden := selectors.Default()
+	overridden.ModPage.Name = "#pagetitle > h2"
+	overridden.ModPage.Stats.Item = "#pagetitle ol.statistics li"
+	defer selectors.Use(overridden)()
+	html := `<div id="pagetitle">
+				<h2>Redesigned Mod</h2>
+				<ol class="statistics">
+					<li><div class="titlestat">Version</div><div class="stat">2.0</div></li>
+				</ol>
+			</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	// Act
+	modInfo := ExtractModInfo(doc)
+
+	// Assert
+	assert.Equal(t, "Redesigned Mod", modInfo.Name)
+	assert.Equal(t, "2.0", modInfo.Version)
+}
+
+func TestExtractTimestamp(t *testing.T) {
+	html := `<div>
+				<time class="attr" datetime="2024-10-13 10:44"><span>ignored</span></time>
+				<time class="text"><span class="date">1 June 2024</span>, <span class="time">9:05PM</span></time>
+				<time class="bad">sometime</time>
+			</div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	assert.Equal(t, time.Date(2024, time.October, 13, 10, 44, 0, 0, time.UTC), extractTimestamp(doc, "time.attr").Time)
+	assert.Equal(t, time.Date(2024, time.June, 1, 21, 5, 0, 0, time.UTC), extractTimestamp(doc, "time.text").Time)
+	assert.Nil(t, extractTimestamp(doc, "time.bad"))
+	assert.Nil(t, extractTimestamp(doc, "time.missing"))
+}
+
 func TestExtractRequirements(t *testing.T) {
 	html := `
 		<div class="tabbed-block">
@@ -335,7 +572,7 @@ func TestExtractRequirements(t *testing.T) {
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
 
 	// Act
-	result := extractRequirements(doc, "Nexus requirements")
+	result := extractRequirements(doc, []string{"Nexus requirements"})
 
 	// Assert
 	assert.Len(t, result, 1, "Expected 1 requirement")
@@ -343,6 +580,232 @@ func TestExtractRequirements(t *testing.T) {
 	assert.Equal(t, "Note1", result[0].Notes)
 }
 
+func TestExtractRequirements_NotesLinks(t *testing.T) {
+	html := `
+		<div class="tabbed-block">
+			<h3>Nexus requirements</h3>
+			<table class="table desc-table">
+				<tbody>
+					<tr>
+						<td class="table-require-name"><a href="https://www.site.com/mod/1234">Requirement1</a></td>
+						<td class="table-require-notes">Use <a href="https://www.site.com/mod/1234?tab=files&amp;file_id=42">version 1.2</a> or the <a href="/mod/5678">legacy port</a> (<a>no link</a>)</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	result := extractRequirements(doc, []string{"Nexus requirements"})
+
+	// Assert
+	assert.Len(t, result, 1)
+	assert.Equal(t, "Use version 1.2 or the legacy port (no link)", result[0].Notes)
+	assert.Equal(t, []types.Link{
+		{Text: "version 1.2", Url: "https://www.site.com/mod/1234?tab=files&file_id=42"},
+		{Text: "legacy port", Url: "/mod/5678"},
+	}, result[0].NotesLinks)
+}
+
+func TestExtractTranslations(t *testing.T) {
+	html := `
+		<div class="tabbed-block">
+			<h3>Translations available on the Nexus</h3>
+			<table class="table desc-table">
+				<thead>
+					<tr>
+						<th class="table-translation-language"><span class="table-header">Language</span></th>
+						<th class="table-translation-name"><span class="table-header">Name</span></th>
+					</tr>
+				</thead>
+				<tbody>
+					<tr>
+						<td class="table-translation-language"><span class="flag flag-German"></span> German</td>
+						<td class="table-translation-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/1234">SkyUI - Deutsch</a></td>
+					</tr>
+					<tr>
+						<td class="table-translation-language">Polish</td>
+						<td class="table-translation-name"><a href="https://example.com/skyui-pl">SkyUI PL</a></td>
+					</tr>
+					<tr>
+						<td class="table-translation-language">French</td>
+						<td class="table-translation-name">Removed translation</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>`
+
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	result := extractTranslations(doc)
+
+	// Assert
+	assert.Equal(t, []types.Translation{
+		{Language: "German", ModID: 1234, Name: "SkyUI - Deutsch", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/1234"},
+		{Language: "Polish", Name: "SkyUI PL", Url: "https://example.com/skyui-pl"},
+	}, result)
+}
+
+func TestExtractTranslations_None(t *testing.T) {
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<div class="tabbed-block"><h3>Nexus requirements</h3></div>`))
+
+	result := extractTranslations(doc)
+
+	assert.NotNil(t, result)
+	assert.Empty(t, result)
+}
+
+func TestExtractStat(t *testing.T) {
+	html := `<div id="pagetitle">
+				<h1>Mod Name</h1>
+				<ul class="stats clearfix">
+					<li class="stat-endorsements"><div class="titlestat">Endorsements</div><div class="stat">1,234</div></li>
+					<li class="stat-uniquedp"><div class="titlestat">Unique DLs</div><div class="stat">5,678</div></li>
+					<li class="stat-totaldl"><div class="titlestat">Total DLs</div><div class="stat">9,012</div></li>
+					<li class="stat-totalviews"><div class="titlestat">Total views</div><div class="stat">34,567</div></li>
+					<li class="stat-version"><div class="titlestat">Version</div><div class="stat"> 1.2.3 </div></li>
+				</ul>
+			</div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	assert.Equal(t, "1,234", extractStat(doc, "Endorsements"))
+	assert.Equal(t, "5,678", extractStat(doc, "Unique DLs"))
+	assert.Equal(t, "9,012", extractStat(doc, "Total DLs"))
+	assert.Equal(t, "34,567", extractStat(doc, "total views"))
+	assert.Equal(t, "1.2.3", extractStat(doc, "Version"))
+	assert.Equal(t, "", extractStat(doc, "Missing"))
+}
+
+func TestExtractModInfo_Localized(t *testing.T) {
+	// Arrange
+	defer func(previous string) { formatters.Locale = previous }(formatters.Locale)
+	formatters.Locale = "de-DE"
+	html := `<div id="pagetitle">
+				<h1>SkyUI</h1>
+				<ul class="stats clearfix">
+					<li class="stat-endorsements"><div class="titlestat">Empfehlungen</div><div class="stat">1.234</div></li>
+					<li class="stat-uniquedp"><div class="titlestat">Einzigartige DLs</div><div class="stat">5.678</div></li>
+					<li class="stat-version"><div class="titlestat">Version</div><div class="stat">5.2</div></li>
+				</ul>
+			</div>
+			<div class="tabbed-block">
+				<h3>Nexus-Voraussetzungen</h3>
+				<table class="table desc-table"><tbody><tr>
+					<td class="table-require-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/30379">SKSE64</a></td>
+					<td class="table-require-notes"></td>
+				</tr></tbody></table>
+			</div>
+			<div class="tabbed-block">
+				<h3>Off-site requirements</h3>
+				<table class="table desc-table"><tbody><tr>
+					<td class="table-require-name">Creation Kit</td>
+					<td class="table-require-notes"></td>
+				</tr></tbody></table>
+			</div>
+			<div class="tabbed-block">
+				<h3>Übersetzungen auf Nexus</h3>
+				<table class="table desc-table"><tbody><tr>
+					<td>Polnisch</td>
+					<td><a href="https://www.nexusmods.com/skyrimspecialedition/mods/1234">SkyUI PL</a></td>
+				</tr></tbody></table>
+			</div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	result := ExtractModInfo(doc)
+
+	// Assert
+	assert.Equal(t, "1.234", result.Endorsements)
+	assert.Equal(t, "5.678", result.UniqueDLs)
+	assert.Equal(t, "5.2", result.Version)
+	require.Len(t, result.Dependencies, 1)
+	assert.Equal(t, "SKSE64", result.Dependencies[0].Name)
+	require.Len(t, result.OffSiteRequirements, 1, "blocks left in English are still read")
+	assert.Equal(t, "Creation Kit", result.OffSiteRequirements[0].Name)
+	require.Len(t, result.Translations, 1)
+	assert.Equal(t, "Polnisch", result.Translations[0].Language)
+}
+
+func TestExtractModInfo_LocalizedFrench(t *testing.T) {
+	// Arrange
+	defer func(previous string) { formatters.Locale = previous }(formatters.Locale)
+	formatters.Locale = "fr"
+	block := func(title, row string) string {
+		return `<div class="tabbed-block"><h3>` + title + `</h3><table class="table desc-table"><tbody><tr>` + row + `</tr></tbody></table></div>`
+	}
+	requirement := func(name string) string {
+		return `<td class="table-require-name">` + name + `</td><td class="table-require-notes"></td>`
+	}
+	html := `<div id="pagetitle"><h1>SkyUI</h1></div>` +
+		block("Prérequis Nexus", requirement("SKSE64")) +
+		block("Prérequis externes", requirement("Creation Kit")) +
+		block("Prérequis DLC", requirement("Dawnguard")) +
+		block("Mods nécessitant ce fichier", requirement("SkyUI Patch")) +
+		block("Traductions disponibles", `<td>Allemand</td><td><a href="https://www.nexusmods.com/skyrimspecialedition/mods/1234">SkyUI DE</a></td>`)
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	result := ExtractModInfo(doc)
+
+	// Assert
+	require.Len(t, result.Dependencies, 1)
+	assert.Equal(t, "SKSE64", result.Dependencies[0].Name)
+	require.Len(t, result.OffSiteRequirements, 1)
+	assert.Equal(t, "Creation Kit", result.OffSiteRequirements[0].Name)
+	require.Len(t, result.DLCRequirements, 1)
+	assert.Equal(t, "Dawnguard", result.DLCRequirements[0].Name)
+	require.Len(t, result.ModsUsing, 1)
+	assert.Equal(t, "SkyUI Patch", result.ModsUsing[0].Name)
+	require.Len(t, result.Translations, 1)
+	assert.Equal(t, "Allemand", result.Translations[0].Language)
+}
+
+func TestExtractRequirements_OffSiteAndDLC(t *testing.T) {
+	// Arrange
+	html := `
+		<div class="tabbed-block">
+			<h3>Off-site requirements</h3>
+			<table class="table desc-table">
+				<tbody>
+					<tr>
+						<td class="table-require-name"><a href="https://skse.silverlock.org/">SKSE64</a></td>
+						<td class="table-require-notes">Script extender</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>
+		<div class="tabbed-block">
+			<h3>DLC requirements</h3>
+			<table class="table desc-table">
+				<tbody>
+					<tr>
+						<td class="table-require-name"> Dawnguard </td>
+						<td class="table-require-notes"></td>
+					</tr>
+					<tr>
+						<td class="table-require-name">Dragonborn</td>
+						<td class="table-require-notes">Solstheim patch only</td>
+					</tr>
+				</tbody>
+			</table>
+		</div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	offSite := extractRequirements(doc, []string{"Off-site requirements"})
+	dlc := extractRequirements(doc, []string{"DLC requirements"})
+
+	// Assert
+	assert.Equal(t, []types.Requirement{
+		{Name: "SKSE64", Notes: "Script extender", NotesLinks: []types.Link{}, Url: "https://skse.silverlock.org/"},
+	}, offSite)
+	assert.Equal(t, []types.Requirement{
+		{Name: "Dawnguard", NotesLinks: []types.Link{}},
+		{Name: "Dragonborn", Notes: "Solstheim patch only", NotesLinks: []types.Link{}},
+	}, dlc)
+}
+
 func TestExtractTags(t *testing.T) {
 	html := `<div class="sideitems side-tags"><ul class="tags"><li><a><span class="flex-label">Tag1</span></a></li></ul></div>`
 	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
@@ -351,3 +814,88 @@ func TestExtractTags(t *testing.T) {
 	assert.Len(t, result, 1)
 	assert.Equal(t, "Tag1", result[0])
 }
+
+func TestExtractCategory(t *testing.T) {
+	html := `<ul id="breadcrumb">
+		<li><a href="/">Home</a></li>
+		<li><a href="/skyrimspecialedition">Skyrim Special Edition</a></li>
+		<li><a href="/skyrimspecialedition/mods/">Mods</a></li>
+		<li><a href="/skyrimspecialedition/mods/categories/42/">User Interface</a></li>
+	</ul>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+	noCategory, _ := goquery.NewDocumentFromReader(strings.NewReader(`<ul id="breadcrumb"><li><a href="/">Home</a></li></ul>`))
+
+	assert.Equal(t, "User Interface", extractCategory(doc))
+	assert.Equal(t, "", extractCategory(noCategory))
+}
+
+func TestCookieCandidates_Freshness(t *testing.T) {
+	// Arrange: the store file isn't a cookie database, its modification time is the last write
+	storePath := filepath.Join(t.TempDir(), "Cookies")
+	require.NoError(t, os.WriteFile(storePath, []byte{}, 0644))
+	lastWrite := time.Date(2024, time.June, 3, 8, 0, 0, 0, time.UTC)
+	require.NoError(t, os.Chtimes(storePath, lastWrite, lastWrite))
+
+	created := time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC)
+	store := new(MockCookieStore)
+	store.On("ReadCookies", mock.Anything).Return([]*kooky.Cookie{
+		{Cookie: http.Cookie{Name: "session", Value: "1", Expires: created.AddDate(0, 1, 0)}, Creation: created},
+		{Cookie: http.Cookie{Name: "refresh", Value: "2", Expires: created.AddDate(1, 0, 0)}, Creation: created.Add(-time.Hour)},
+	}, nil)
+	store.On("Close").Return(nil)
+	store.On("Browser").Return("chrome")
+	store.On("Profile").Return("Default")
+	store.On("FilePath").Return(storePath)
+
+	// Act
+	candidates, err := CookieCandidates("example.com", []string{"session", "refresh"}, func() []kooky.CookieStore {
+		return []kooky.CookieStore{store}
+	})
+
+	// Assert
+	require.NoError(t, err)
+	require.Len(t, candidates, 1)
+	assert.Equal(t, created, candidates[0].CreatedAt)
+	assert.Equal(t, created.AddDate(0, 1, 0), candidates[0].ExpiresAt)
+	assert.True(t, lastWrite.Equal(candidates[0].LastWriteAt))
+	assert.True(t, lastWrite.Equal(candidates[0].RefreshedAt()))
+}
+
+func TestExtractFileInfo_ParsesSizeAndDownloads(t *testing.T) {
+	html := `<div class="file-expander-header"><p>File1</p>` +
+		`<div class="stat-filesize"><div class="stat">1.5MB</div></div>` +
+		`<div class="stat-uniquedls"><div class="stat">1,234</div></div>` +
+		`<div class="stat-totaldls"><div class="stat">5,678</div></div></div>` +
+		`<div class="file-expander-header"><p>File2</p><div class="stat-filesize"><div class="stat">unknown</div></div></div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	result := ExtractFileInfo(doc, "")
+
+	assert.Len(t, result, 2)
+	assert.Equal(t, "1.5MB", result[0].FileSize)
+	assert.Equal(t, int64(1572864), result[0].FileSizeBytes)
+	assert.Equal(t, int64(1234), result[0].UniqueDLCount)
+	assert.Equal(t, int64(5678), result[0].TotalDLCount)
+	assert.Equal(t, "unknown", result[1].FileSize)
+	assert.Zero(t, result[1].FileSizeBytes)
+	assert.Zero(t, result[1].TotalDLCount)
+}
+
+func TestExtractFileInfo_MD5(t *testing.T) {
+	html := `<dl>
+				<dt class="file-expander-header"><p>File1</p><div class="stat-md5"><div class="stat">0123456789ABCDEF0123456789ABCDEF</div></div></dt>
+				<dd><div class="tabbed-block files-description">Main file</div></dd>
+				<dt class="file-expander-header"><p>File2</p></dt>
+				<dd><div class="tabbed-block files-description">Patch. MD5: fedcba9876543210fedcba9876543210</div></dd>
+				<dt class="file-expander-header"><p>File3</p></dt>
+				<dd><div class="tabbed-block files-description">No checksum, just 0123456789abcdef0123456789abcdef</div></dd>
+			</dl>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	result := ExtractFileInfo(doc, "")
+
+	assert.Len(t, result, 3)
+	assert.Equal(t, "0123456789abcdef0123456789abcdef", result[0].MD5)
+	assert.Equal(t, "fedcba9876543210fedcba9876543210", result[1].MD5)
+	assert.Empty(t, result[2].MD5)
+}
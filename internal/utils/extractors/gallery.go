@@ -0,0 +1,56 @@
+package extractors
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
+)
+
+// ExtractGalleryImages returns the URLs of the full size images of the gallery of a
+// mod page, in page order and without duplicates. The legacy frontend gives the URL in
+// the data-src attribute of each thumbnail, falling back to the link or image within
+// it; the redesigned one tags the gallery images with data-e2eid attributes. Relative
+// URLs are resolved against the URL of the page. It returns an empty slice if the mod
+// has no gallery.
+func ExtractGalleryImages(doc *goquery.Document) []string {
+	items := doc.Find(selectors.Current.ModPage.Gallery)
+	if DetectLayout(doc) == LayoutNext {
+		items = doc.Find("[data-e2eid='mod-gallery-image']")
+	}
+
+	images := make([]string, 0, items.Length())
+	seen := map[string]bool{}
+	items.Each(func(i int, s *goquery.Selection) {
+		imageUrl := galleryImageUrl(s)
+		if imageUrl == "" {
+			return
+		}
+		if doc.Url != nil {
+			if ref, err := url.Parse(imageUrl); err == nil {
+				imageUrl = doc.Url.ResolveReference(ref).String()
+			}
+		}
+		if !seen[imageUrl] {
+			seen[imageUrl] = true
+			images = append(images, imageUrl)
+		}
+	})
+	return images
+}
+
+// galleryImageUrl returns the URL of the full size image of a gallery item: its own
+// data-src, href or src attribute, or the href of the link or src of the image within
+// it, or "" if it has none.
+func galleryImageUrl(s *goquery.Selection) string {
+	for _, attr := range []string{"data-src", "href", "src"} {
+		if value := strings.TrimSpace(s.AttrOr(attr, "")); value != "" {
+			return value
+		}
+	}
+	if href := strings.TrimSpace(s.Find("a[href]").First().AttrOr("href", "")); href != "" {
+		return href
+	}
+	return strings.TrimSpace(s.Find("img[src]").First().AttrOr("src", ""))
+}
@@ -0,0 +1,64 @@
+package extractors
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtractGalleryImages(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		expected []string
+	}{
+		{
+			name: "legacy thumbnails",
+			html: `
+				<div id="sidebargallery"><ul class="thumbgallery">
+					<li class="thumb" data-src="https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-1.png"><a href="#"><img src="thumb-1.png"></a></li>
+					<li class="thumb"><a href="/mods/1704/images/3863/3863-2.jpg"><img src="thumb-2.jpg"></a></li>
+					<li class="thumb"><img src="https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-3.jpg"></li>
+					<li class="thumb" data-src="https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-1.png"></li>
+					<li class="thumb"></li>
+				</ul></div>`,
+			expected: []string{
+				"https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-1.png",
+				"https://www.nexusmods.com/mods/1704/images/3863/3863-2.jpg",
+				"https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-3.jpg",
+			},
+		},
+		{
+			name: "redesigned gallery",
+			html: `
+				<div id="__next"><div data-e2eid="mod-gallery">
+					<a data-e2eid="mod-gallery-image" href="https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-1.png"><img src="thumb-1.png"></a>
+					<img data-e2eid="mod-gallery-image" src="https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-2.png">
+				</div></div>`,
+			expected: []string{
+				"https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-1.png",
+				"https://staticdelivery.nexusmods.com/mods/1704/images/3863/3863-2.png",
+			},
+		},
+		{name: "no gallery", html: `<div id="pagetitle"><h1>SkyUI</h1></div>`, expected: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			require.NoError(t, err)
+			doc.Url, _ = url.Parse("https://www.nexusmods.com/skyrimspecialedition/mods/3863")
+
+			// Act
+			images := ExtractGalleryImages(doc)
+
+			// Assert
+			assert.Equal(t, tt.expected, images)
+		})
+	}
+}
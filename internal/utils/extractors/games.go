@@ -0,0 +1,62 @@
+package extractors
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// ExtractGameInfo parses a game's landing page to extract its name, the number of
+// mods, collections and downloads from the statistics block, the categories it lists,
+// and the mods listed in the "Most endorsed" section. Counts that can't be parsed are
+// left at zero.
+func ExtractGameInfo(doc *goquery.Document) types.GameInfo {
+	return types.GameInfo{
+		Name:                  extractElementText(doc, "#pagetitle > h1"),
+		ModCount:              extractCount(doc, "Mods"),
+		CollectionCount:       extractCount(doc, "Collections"),
+		DownloadCount:         extractCount(doc, "Downloads"),
+		Categories:            ExtractCategories(doc),
+		MostEndorsedThisMonth: extractGameMods(doc, "most endorsed"),
+	}
+}
+
+// extractCount returns the statistic with the given title parsed as a count, or zero
+// if the statistic is missing or isn't a number.
+func extractCount(doc *goquery.Document, title string) int64 {
+	count, err := formatters.ParseCount(extractStat(doc, title))
+	if err != nil {
+		return 0
+	}
+	return count
+}
+
+// extractGameMods returns the mod tiles of the first landing page section whose heading
+// contains the given text (case-insensitive), such as "most endorsed". It returns an
+// empty slice if no such section is found.
+func extractGameMods(doc *goquery.Document, heading string) []types.GameMod {
+	section := doc.Find("h2, h3").FilterFunction(func(i int, s *goquery.Selection) bool {
+		return strings.Contains(strings.ToLower(formatters.CleanTextSelect(s)), heading)
+	}).First().Parent()
+
+	tiles := section.Find(".mod-tile")
+	mods := make([]types.GameMod, 0, tiles.Length())
+
+	tiles.Each(func(i int, tile *goquery.Selection) {
+		link := tile.Find(".tile-name a").First()
+		href, _ := link.Attr("href")
+
+		mod := types.GameMod{Name: formatters.CleanTextSelect(link), Url: strings.TrimSpace(href)}
+		if _, id, err := formatters.ParseModUrl(mod.Url); err == nil {
+			mod.ModID = id
+		}
+		if endorsements, err := formatters.ParseCount(formatters.CleanTextSelect(tile.Find(".endorsecount").First())); err == nil {
+			mod.Endorsements = endorsements
+		}
+		mods = append(mods, mod)
+	})
+
+	return mods
+}
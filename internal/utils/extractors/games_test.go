@@ -0,0 +1,75 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractGameInfo(t *testing.T) {
+	// Arrange
+	html := `
+		<div id="pagetitle">
+			<h1>Skyrim Special Edition</h1>
+			<ul class="stats">
+				<li><div class="titlestat">Mods</div><div class="stat">104,512</div></li>
+				<li><div class="titlestat">Collections</div><div class="stat">3.2k</div></li>
+				<li><div class="titlestat">Downloads</div><div class="stat">1.2B</div></li>
+			</ul>
+		</div>
+		<ul class="categories">
+			<li><a href="/skyrimspecialedition/mods/categories/2">Armour (12,345)</a></li>
+			<li><a href="/skyrimspecialedition/mods/categories/3">Audio</a></li>
+		</ul>
+		<section>
+			<h2>Most endorsed this month</h2>
+			<ul>
+				<li class="mod-tile">
+					<p class="tile-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/266">Unofficial Patch</a></p>
+					<span class="endorsecount">1.5k</span>
+				</li>
+				<li class="mod-tile">
+					<p class="tile-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/30379">SkyUI</a></p>
+					<span class="endorsecount">987</span>
+				</li>
+			</ul>
+		</section>
+		<section>
+			<h2>Trending</h2>
+			<ul><li class="mod-tile"><p class="tile-name"><a href="/x">Other</a></p></li></ul>
+		</section>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	info := ExtractGameInfo(doc)
+
+	// Assert
+	assert.Equal(t, types.GameInfo{
+		Name:            "Skyrim Special Edition",
+		ModCount:        104512,
+		CollectionCount: 3200,
+		DownloadCount:   1200000000,
+		Categories: []types.TaxonomyEntry{
+			{Count: 12345, Name: "Armour", Url: "/skyrimspecialedition/mods/categories/2"},
+			{Name: "Audio", Url: "/skyrimspecialedition/mods/categories/3"},
+		},
+		MostEndorsedThisMonth: []types.GameMod{
+			{Endorsements: 1500, ModID: 266, Name: "Unofficial Patch", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/266"},
+			{Endorsements: 987, ModID: 30379, Name: "SkyUI", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/30379"},
+		},
+	}, info)
+}
+
+func TestExtractGameInfo_MissingSections(t *testing.T) {
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<html></html>`))
+
+	info := ExtractGameInfo(doc)
+
+	assert.Zero(t, info.ModCount)
+	assert.Zero(t, info.DownloadCount)
+	assert.Empty(t, info.Categories)
+	assert.Empty(t, info.MostEndorsedThisMonth)
+}
@@ -0,0 +1,61 @@
+package extractors
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// ExtractUpdatedMods parses a page of a game's updated mods listing and returns the
+// mods listed on it, in order. The game and mod ID are read from each mod link and the
+// update time from the tile's time element, preferring its datetime attribute. Tiles
+// without a link to a mod page are ignored, and the update time is left nil when it
+// can't be parsed.
+func ExtractUpdatedMods(doc *goquery.Document) []types.ListedMod {
+	tiles := doc.Find(".mod-tile")
+	mods := make([]types.ListedMod, 0, tiles.Length())
+
+	tiles.Each(func(i int, tile *goquery.Selection) {
+		link := tile.Find(".tile-name a").First()
+		href, _ := link.Attr("href")
+
+		game, id, err := formatters.ParseModUrl(strings.TrimSpace(href))
+		if err != nil {
+			return
+		}
+
+		mods = append(mods, types.ListedMod{
+			Game:      game,
+			ModID:     id,
+			Name:      formatters.CleanTextSelect(link),
+			UpdatedAt: tileTimestamp(tile),
+			Url:       strings.TrimSpace(href),
+		})
+	})
+
+	return mods
+}
+
+// tileTimestamp parses the time element of a mod tile, preferring its datetime
+// attribute and falling back to the displayed text. It returns nil if the tile has no
+// time element or the date can't be parsed.
+func tileTimestamp(tile *goquery.Selection) *types.Timestamp {
+	selection := tile.Find("time").First()
+	if selection.Length() == 0 {
+		return nil
+	}
+
+	if datetime, ok := selection.Attr("datetime"); ok {
+		if parsed, err := formatters.ParseNexusDate(datetime); err == nil {
+			return types.NewTimestamp(parsed)
+		}
+	}
+
+	parsed, err := formatters.ParseNexusDate(formatters.CleanTextSelect(selection))
+	if err != nil {
+		return nil
+	}
+	return types.NewTimestamp(parsed)
+}
@@ -0,0 +1,43 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractUpdatedMods(t *testing.T) {
+	// Arrange
+	html := `
+		<ul>
+			<li class="mod-tile">
+				<p class="tile-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/266">Unofficial Patch</a></p>
+				<time datetime="2024-06-20 14:30">20 Jun 2024</time>
+			</li>
+			<li class="mod-tile">
+				<p class="tile-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/42">Armor Pack</a></p>
+				<time>01 Jun 2024</time>
+			</li>
+			<li class="mod-tile">
+				<p class="tile-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/7">Undated</a></p>
+			</li>
+			<li class="mod-tile">
+				<p class="tile-name"><a href="https://www.nexusmods.com/images/1">Image</a></p>
+			</li>
+		</ul>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	mods := ExtractUpdatedMods(doc)
+
+	// Assert
+	assert.Equal(t, []types.ListedMod{
+		{Game: "skyrimspecialedition", ModID: 266, Name: "Unofficial Patch", UpdatedAt: types.NewTimestamp(time.Date(2024, 6, 20, 14, 30, 0, 0, time.UTC)), Url: "https://www.nexusmods.com/skyrimspecialedition/mods/266"},
+		{Game: "skyrimspecialedition", ModID: 42, Name: "Armor Pack", UpdatedAt: types.NewTimestamp(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)), Url: "https://www.nexusmods.com/skyrimspecialedition/mods/42"},
+		{Game: "skyrimspecialedition", ModID: 7, Name: "Undated", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/7"},
+	}, mods)
+}
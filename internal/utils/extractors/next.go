@@ -0,0 +1,167 @@
+package extractors
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// Layout is the frontend a Nexus Mods page was rendered by.
+type Layout string
+
+const (
+	// LayoutLegacy is the classic www.nexusmods.com frontend, read with the selectors.
+	LayoutLegacy Layout = "legacy"
+	// LayoutNext is the redesigned next.nexusmods.com frontend, read with the selectors
+	// under next.
+	LayoutNext Layout = "next"
+)
+
+// DetectLayout returns the frontend the page was rendered by. Pages of the redesigned
+// frontend are served from next.nexusmods.com or hold the elements its selectors
+// detect by, e.g. the __NEXT_DATA__ script Next.js leaves behind or the data-e2eid
+// attributes of the mod content; pages showing none of these are legacy ones.
+func DetectLayout(doc *goquery.Document) Layout {
+	if doc.Url != nil && strings.HasPrefix(doc.Url.Hostname(), "next.") {
+		return LayoutNext
+	}
+	if doc.Find(selectors.Current.Next.Detect).Length() > 0 {
+		return LayoutNext
+	}
+	return LayoutLegacy
+}
+
+// extractNextModInfo is the ExtractModInfo of the redesigned frontend, reading the same
+// details with the selectors of its elements. The redesigned pages show no
+// translations table or permissions, so Translations is left empty and Permissions
+// nil.
+func extractNextModInfo(doc *goquery.Document) types.ModInfo {
+	sel := selectors.Current.Next.ModPage
+	return types.ModInfo{
+		Name:                extractElementText(doc, sel.Name),
+		LastUpdated:         extractElementText(doc, sel.LastUpdated),
+		LastUpdatedAt:       extractTimestamp(doc, sel.LastUpdated),
+		OriginalUpload:      extractElementText(doc, sel.OriginalUpload),
+		OriginalUploadAt:    extractTimestamp(doc, sel.OriginalUpload),
+		Creator:             extractElementText(doc, sel.Creator),
+		ChangeLogs:          extractNextChangeLogs(doc),
+		Uploader:            extractElementText(doc, sel.Uploader),
+		VirusStatus:         extractElementText(doc, sel.VirusStatus),
+		ShortDescription:    extractElementText(doc, sel.ShortDescription),
+		Description:         extractElementText(doc, sel.Description),
+		Tags:                extractNextTags(doc),
+		Category:            categoryFromLinks(doc.Find(sel.Breadcrumb)),
+		Translations:        make([]types.Translation, 0),
+		Dependencies:        extractNextRequirements(doc, sel.Requirements.Nexus),
+		OffSiteRequirements: extractNextRequirements(doc, sel.Requirements.OffSite),
+		DLCRequirements:     extractNextRequirements(doc, sel.Requirements.DLC),
+		ModsUsing:           extractNextRequirements(doc, sel.Requirements.ModsRequiring),
+		Endorsements:        extractNextStat(doc, sel.Stats.Endorsements),
+		UniqueDLs:           extractNextStat(doc, sel.Stats.UniqueDLs),
+		TotalDLs:            extractNextStat(doc, sel.Stats.TotalDLs),
+		TotalViews:          extractNextStat(doc, sel.Stats.TotalViews),
+		Version:             extractNextStat(doc, sel.Stats.Version),
+	}
+}
+
+// extractNextStat returns the value of the statistic of the redesigned page matching
+// the selector, or "" if it is missing.
+func extractNextStat(doc *goquery.Document, selector string) string {
+	stat := doc.Find(selector).First()
+	if value := stat.Find(selectors.Current.Next.ModPage.Stats.Value); value.Length() > 0 {
+		return formatters.CleanTextSelect(value.First())
+	}
+	return formatters.CleanTextSelect(stat)
+}
+
+// extractNextTags returns the labels of the tags of the redesigned page.
+func extractNextTags(doc *goquery.Document) []string {
+	elements := doc.Find(selectors.Current.Next.ModPage.Tags)
+	tags := make([]string, 0, elements.Length())
+	elements.Each(func(i int, s *goquery.Selection) {
+		if label := formatters.CleanTextSelect(s); label != "" {
+			tags = append(tags, label)
+		}
+	})
+	return tags
+}
+
+// extractNextChangeLogs returns the changelog entries of the redesigned page, skipping
+// those without a version or notes like the legacy extractor does.
+func extractNextChangeLogs(doc *goquery.Document) []types.ChangeLog {
+	sel := selectors.Current.Next.ModPage.ChangeLog
+	changeLogs := make([]types.ChangeLog, 0)
+	doc.Find(sel.Item).Each(func(i int, s *goquery.Selection) {
+		version := formatters.CleanTextSelect(s.Find(sel.Version).First())
+
+		var notes []string
+		s.Find(sel.Notes).Each(func(j int, note *goquery.Selection) {
+			if text := formatters.CleanTextSelect(note); text != "" {
+				notes = append(notes, text)
+			}
+		})
+
+		if version != "" && len(notes) > 0 {
+			changeLogs = append(changeLogs, types.ChangeLog{Version: version, Notes: notes})
+		}
+	})
+	return changeLogs
+}
+
+// extractNextRequirements returns the requirements listed in the requirement section
+// of the redesigned page matching the selector, with the name read from the link of
+// each requirement or, for DLCs, from its text. It returns an empty slice if the
+// section is missing.
+func extractNextRequirements(doc *goquery.Document, section string) []types.Requirement {
+	sel := selectors.Current.Next.ModPage.Requirements
+	items := doc.Find(section).Find(sel.Item)
+	requirements := make([]types.Requirement, 0, items.Length())
+	items.Each(func(i int, item *goquery.Selection) {
+		nameElement := item.Find(sel.Name).First()
+		link := nameElement.Find("a").AddSelection(nameElement.Filter("a")).First()
+
+		name := formatters.CleanTextStr(link.Text())
+		if link.Length() == 0 {
+			name = formatters.CleanTextStr(nameElement.Text())
+		}
+		notes := item.Find(sel.Notes).First()
+		url, _ := link.Attr("href")
+		requirements = append(requirements, types.Requirement{
+			Name:       name,
+			Notes:      formatters.CleanTextStr(notes.Text()),
+			NotesLinks: extractLinks(notes),
+			Url:        strings.TrimSpace(url),
+		})
+	})
+	return requirements
+}
+
+// extractNextFileInfo is the ExtractFileInfo of the redesigned frontend, reading each
+// file from its file tile. The file ID is read from the tile's data-id attribute and
+// the category from the data-category attribute of the enclosing file section.
+func extractNextFileInfo(doc *goquery.Document, modUrl string) []types.File {
+	sel := selectors.Current.Next.FilesTab
+	tiles := doc.Find(sel.File)
+	files := make([]types.File, 0, tiles.Length())
+
+	tiles.Each(func(i int, s *goquery.Selection) {
+		category, _ := s.Closest(sel.Section).Attr("data-category")
+		file := types.File{
+			Category:    normalizeFileCategory(category),
+			FileID:      extractFileID(s),
+			Name:        formatters.CleanTextSelect(s.Find(sel.Name).First()),
+			Version:     formatters.CleanTextSelect(s.Find(sel.Version).First()),
+			UploadDate:  formatters.CleanTextSelect(s.Find(sel.UploadDate).First()),
+			FileSize:    formatters.CleanTextSelect(s.Find(sel.FileSize).First()),
+			UniqueDLs:   formatters.CleanTextSelect(s.Find(sel.UniqueDLs).First()),
+			TotalDLs:    formatters.CleanTextSelect(s.Find(sel.TotalDLs).First()),
+			Description: formatters.CleanTextSelect(s.Find(sel.Description).First()),
+		}
+		files = append(files, completeFile(file, s.Find(sel.MD5), modUrl))
+	})
+
+	return files
+}
@@ -0,0 +1,177 @@
+package extractors
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/selectors"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLayout(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		url      string
+		expected Layout
+	}{
+		{name: "legacy page", html: `<div id="pagetitle"><h1>SkyUI</h1></div>`, expected: LayoutLegacy},
+		{name: "next data script", html: `<script id="__NEXT_DATA__" type="application/json">{}</script>`, expected: LayoutNext},
+		{name: "tagged mod content", html: `<h1 data-e2eid="mod-title">SkyUI</h1>`, expected: LayoutNext},
+		{name: "next host", html: `<h1>SkyUI</h1>`, url: "https://next.nexusmods.com/skyrim/mods/3863", expected: LayoutNext},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			require.NoError(t, err)
+			if tt.url != "" {
+				doc.Url, _ = url.Parse(tt.url)
+			}
+
+			assert.Equal(t, tt.expected, DetectLayout(doc))
+		})
+	}
+}
+
+func TestExtractModInfo_NextLayout(t *testing.T) {
+	// Arrange
+	html := `
+		<div id="__next">
+			<nav data-e2eid="breadcrumbs">
+				<a href="/skyrimspecialedition">Skyrim Special Edition</a>
+				<a href="/skyrimspecialedition/mods/categories/42">User Interface</a>
+			</nav>
+			<h1 data-e2eid="mod-title">SkyUI</h1>
+			<div data-e2eid="mod-last-updated"><time datetime="2024-06-01T12:00:00Z">01 Jun 2024</time></div>
+			<div data-e2eid="mod-original-upload"><time datetime="2016-11-01T10:00:00Z">01 Nov 2016</time></div>
+			<a data-e2eid="mod-creator">schlangster</a>
+			<a data-e2eid="mod-uploader">EssArrBee</a>
+			<span data-e2eid="mod-virus-status">Safe to use</span>
+			<p data-e2eid="mod-summary">Elegant, PC-friendly interface mod</p>
+			<div data-e2eid="mod-description">SkyUI is a mod that makes the interface easier to use.</div>
+			<ul>
+				<li data-e2eid="mod-stat-endorsements"><span>Endorsements</span><span data-e2eid="stat-value">1.2M</span></li>
+				<li data-e2eid="mod-stat-total-dls"><span>Total DLs</span><span data-e2eid="stat-value">10M</span></li>
+				<li data-e2eid="mod-stat-version"><span data-e2eid="stat-value">5.2SE</span></li>
+			</ul>
+			<a data-e2eid="mod-tag">Interface</a>
+			<a data-e2eid="mod-tag">SKSE</a>
+			<section data-e2eid="requirements-nexus">
+				<div data-e2eid="requirement">
+					<span data-e2eid="requirement-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/30379">SKSE64</a></span>
+					<span data-e2eid="requirement-notes">Needed for the MCM</span>
+				</div>
+			</section>
+			<section data-e2eid="requirements-dlc">
+				<div data-e2eid="requirement"><span data-e2eid="requirement-name">Dawnguard</span></div>
+			</section>
+			<div data-e2eid="changelog-entry">
+				<h3 data-e2eid="changelog-version">5.2SE</h3>
+				<li data-e2eid="changelog-note">Ported to Special Edition</li>
+			</div>
+		</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	// Act
+	mod := ExtractModInfo(doc)
+
+	// Assert
+	assert.Equal(t, "SkyUI", mod.Name)
+	assert.Equal(t, "User Interface", mod.Category)
+	assert.Equal(t, "schlangster", mod.Creator)
+	assert.Equal(t, "EssArrBee", mod.Uploader)
+	assert.Equal(t, "Safe to use", mod.VirusStatus)
+	assert.Equal(t, "Elegant, PC-friendly interface mod", mod.ShortDescription)
+	assert.Equal(t, "SkyUI is a mod that makes the interface easier to use.", mod.Description)
+	require.NotNil(t, mod.LastUpdatedAt)
+	assert.Equal(t, 2024, mod.LastUpdatedAt.Year())
+	require.NotNil(t, mod.OriginalUploadAt)
+	assert.Equal(t, 2016, mod.OriginalUploadAt.Year())
+	assert.Equal(t, "1.2M", mod.Endorsements)
+	assert.Equal(t, "10M", mod.TotalDLs)
+	assert.Equal(t, "", mod.UniqueDLs)
+	assert.Equal(t, "5.2SE", mod.Version)
+	assert.Equal(t, []string{"Interface", "SKSE"}, mod.Tags)
+	assert.Equal(t, []types.Requirement{{
+		Name:       "SKSE64",
+		Notes:      "Needed for the MCM",
+		NotesLinks: []types.Link{},
+		Url:        "https://www.nexusmods.com/skyrimspecialedition/mods/30379",
+	}}, mod.Dependencies)
+	assert.Equal(t, []types.Requirement{{Name: "Dawnguard", NotesLinks: []types.Link{}}}, mod.DLCRequirements)
+	assert.Empty(t, mod.OffSiteRequirements)
+	assert.Equal(t, []types.ChangeLog{{Version: "5.2SE", Notes: []string{"Ported to Special Edition"}}}, mod.ChangeLogs)
+	assert.NotNil(t, mod.Translations)
+	assert.Empty(t, mod.Translations)
+	assert.Nil(t, mod.Permissions, "the redesigned pages show no permissions")
+}
+
+func TestExtractModInfo_NextLayoutSelectorOverride(t *testing.T) {
+	// Arrange: the redesigned page tags its title and statistics anew
+	overridden := selectors.Default()
+	overridden.Next.ModPage.Name = "[data-e2eid='mod-name']"
+	overridden.Next.ModPage.Stats.Version = "[data-e2eid='mod-version']"
+	defer selectors.Use(overridden)()
+	html := `<div id="__next">
+				<h1 data-e2eid="mod-name">SkyUI</h1>
+				<span data-e2eid="mod-version">5.2SE</span>
+			</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	// Act
+	mod := ExtractModInfo(doc)
+
+	// Assert
+	assert.Equal(t, "SkyUI", mod.Name)
+	assert.Equal(t, "5.2SE", mod.Version)
+}
+
+func TestExtractFileInfo_NextLayout(t *testing.T) {
+	// Arrange
+	html := `
+		<div id="__next">
+			<section data-e2eid="file-section" data-category="Optional files">
+				<div data-e2eid="file-tile" data-id="1001">
+					<p data-e2eid="file-name">SkyUI Patch</p>
+					<span data-e2eid="file-version">1.1</span>
+					<span data-e2eid="file-upload-date">01 Jun 2024</span>
+					<span data-e2eid="file-size">2MB</span>
+					<span data-e2eid="file-unique-dls">1,000</span>
+					<span data-e2eid="file-total-dls">1,500</span>
+					<div data-e2eid="file-description">Compatibility patch</div>
+					<span data-e2eid="file-md5" data-md5="9E107D9D372BB6826BD81D3542A419D6">9e107d9d…</span>
+				</div>
+			</section>
+		</div>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	require.NoError(t, err)
+
+	// Act
+	files := ExtractFileInfo(doc, "https://next.nexusmods.com/skyrim/mods/3863")
+
+	// Assert
+	require.Len(t, files, 1)
+	assert.Equal(t, types.File{
+		Category:      "optional",
+		Description:   "Compatibility patch",
+		DownloadUrl:   "https://next.nexusmods.com/skyrim/mods/3863?tab=files&file_id=1001",
+		FileID:        1001,
+		FileSize:      "2MB",
+		FileSizeBytes: 2 * 1024 * 1024,
+		MD5:           "9e107d9d372bb6826bd81d3542a419d6",
+		Name:          "SkyUI Patch",
+		TotalDLCount:  1500,
+		TotalDLs:      "1,500",
+		UniqueDLCount: 1000,
+		UniqueDLs:     "1,000",
+		UploadDate:    "01 Jun 2024",
+		Version:       "1.1",
+	}, files[0])
+}
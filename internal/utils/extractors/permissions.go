@@ -0,0 +1,79 @@
+package extractors
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// permissionStatuses maps the class of a permission row to the status it stands for.
+var permissionStatuses = map[string]string{
+	"permission-yes":   "yes",
+	"permission-no":    "no",
+	"permission-maybe": "maybe",
+}
+
+// extractPermissions parses the "Permissions and credits" accordion of the description
+// tab. Each permission row is read with its title, its status (yes, no or maybe, from
+// the row's class) and its description; the author notes, credits and donation points
+// blocks are read as text. It returns nil if the mod page has no such accordion.
+func extractPermissions(doc *goquery.Document) *types.Permissions {
+	header := doc.Find("dt").FilterFunction(func(i int, s *goquery.Selection) bool {
+		return strings.Contains(strings.ToLower(s.Text()), "permissions and credits")
+	}).First()
+	if header.Length() == 0 {
+		return nil
+	}
+	content := header.NextFiltered("dd")
+
+	permissions := &types.Permissions{Rules: make([]types.Permission, 0)}
+	content.Find("li").Each(func(i int, row *goquery.Selection) {
+		title := formatters.CleanTextSelect(row.Find("h3").First())
+		if title == "" {
+			return
+		}
+
+		permission := types.Permission{
+			Description: formatters.CleanTextSelect(row.Find("p").First()),
+			Title:       title,
+		}
+		for class, status := range permissionStatuses {
+			if row.HasClass(class) {
+				permission.Status = status
+			}
+		}
+		permissions.Rules = append(permissions.Rules, permission)
+	})
+
+	content.Find("div.tabbed-block").Each(func(i int, block *goquery.Selection) {
+		title := strings.ToLower(formatters.CleanTextSelect(block.Find("h3").First()))
+		switch {
+		case strings.Contains(title, "author notes"):
+			permissions.AuthorNotes = blockText(block)
+		case strings.Contains(title, "credits"):
+			permissions.Credits = blockText(block)
+		case strings.Contains(title, "donation"):
+			permissions.DonationPoints = blockText(block)
+		}
+	})
+
+	return permissions
+}
+
+// blockText returns the text of a description block without its heading, with the
+// whitespace of each line condensed.
+func blockText(block *goquery.Selection) string {
+	block = block.Clone()
+	block.Find("h3").Remove()
+
+	lines := strings.Split(block.Text(), "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
@@ -0,0 +1,79 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractPermissions(t *testing.T) {
+	// Arrange
+	html := `
+		<dl class="accordion">
+			<dt>Permissions and credits <div class="acc-status"></div></dt>
+			<dd>
+				<div class="tabbed-block">
+					<h3>Author's instructions</h3>
+					<p>Ask me first.</p>
+				</div>
+				<div class="tabbed-block">
+					<h3>File credits</h3>
+					<p>Thanks to
+						everyone who tested.</p>
+					<p>SKSE team</p>
+				</div>
+				<div class="tabbed-block">
+					<h3>Donation Points system</h3>
+					<p>This mod is opted-in to receive Donation Points</p>
+				</div>
+				<div class="tabbed-block">
+					<h3>Author notes</h3>
+					<p>Please don't reupload.</p>
+				</div>
+				<div class="tabbed-block">
+					<ul class="permissions">
+						<li class="permission-yes"><h3>Modification permission</h3><p>You are allowed to modify my files.</p></li>
+						<li class="permission-no"><h3>Upload permission</h3><p>You are not allowed to upload this file.</p></li>
+						<li class="permission-maybe"><h3>Asset use permission</h3><p>You must get permission.</p></li>
+					</ul>
+				</div>
+			</dd>
+			<dt>Changelogs</dt>
+			<dd><ul><li><h3>Version 1.0</h3></li></ul></dd>
+		</dl>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	result := extractPermissions(doc)
+
+	// Assert
+	assert.Equal(t, &types.Permissions{
+		AuthorNotes:    "Please don't reupload.",
+		Credits:        "Thanks to\neveryone who tested.\nSKSE team",
+		DonationPoints: "This mod is opted-in to receive Donation Points",
+		Rules: []types.Permission{
+			{Description: "You are allowed to modify my files.", Status: "yes", Title: "Modification permission"},
+			{Description: "You are not allowed to upload this file.", Status: "no", Title: "Upload permission"},
+			{Description: "You must get permission.", Status: "maybe", Title: "Asset use permission"},
+		},
+	}, result)
+}
+
+func TestExtractPermissions_None(t *testing.T) {
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<dl class="accordion"><dt>Changelogs</dt><dd></dd></dl>`))
+
+	assert.Nil(t, extractPermissions(doc))
+}
+
+func TestExtractPermissions_NoRules(t *testing.T) {
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(`<dl class="accordion"><dt>Permissions and credits</dt><dd></dd></dl>`))
+
+	result := extractPermissions(doc)
+
+	assert.NotNil(t, result)
+	assert.NotNil(t, result.Rules)
+	assert.Empty(t, result.Rules)
+}
@@ -0,0 +1,69 @@
+package extractors
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+const (
+	// categorySelector matches the entries of a game's category listing.
+	categorySelector = ".category-list li, ul.categories li"
+	// tagSelector matches the entries of the tag filter of a game's mod search.
+	tagSelector = ".tag-list li, ul.tags li, .tag-filter li"
+)
+
+// trailingCount matches a count shown in parentheses after a name, e.g. "Armour (1,234)".
+var trailingCount = regexp.MustCompile(`\s*\(([\d.,]+[kKmMbB]?)\)$`)
+
+// ExtractCategories parses a game's category listing and returns its categories in
+// order, each with the number of mods it holds when shown.
+func ExtractCategories(doc *goquery.Document) []types.TaxonomyEntry {
+	return extractTaxonomy(doc, categorySelector)
+}
+
+// ExtractTagFilter parses the tag filter of a game's mod search and returns its tags in
+// order, each with the number of mods tagged with it when shown.
+func ExtractTagFilter(doc *goquery.Document) []types.TaxonomyEntry {
+	return extractTaxonomy(doc, tagSelector)
+}
+
+// extractTaxonomy returns the entries matched by selector, listed once each by name.
+// The count is read from a ".count" element of the entry or, failing that, from a
+// count in parentheses ending its text. Entries without a name are ignored.
+func extractTaxonomy(doc *goquery.Document, selector string) []types.TaxonomyEntry {
+	items := doc.Find(selector)
+	tags := make([]types.TaxonomyEntry, 0, items.Length())
+	seen := map[string]bool{}
+
+	items.Each(func(i int, item *goquery.Selection) {
+		countText := formatters.CleanTextSelect(item.Find(".count").First())
+		name := formatters.CleanTextSelect(item.Clone().Find(".count").Remove().End())
+		if countText == "" {
+			if match := trailingCount.FindStringSubmatch(name); match != nil {
+				countText = match[1]
+				name = strings.TrimSpace(strings.TrimSuffix(name, match[0]))
+			}
+		}
+
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			return
+		}
+		seen[key] = true
+
+		tag := types.TaxonomyEntry{Name: name}
+		if count, err := formatters.ParseCount(strings.Trim(countText, "()")); err == nil {
+			tag.Count = count
+		}
+		if href, ok := item.Find("a").First().Attr("href"); ok {
+			tag.Url = strings.TrimSpace(href)
+		}
+		tags = append(tags, tag)
+	})
+
+	return tags
+}
@@ -0,0 +1,45 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractCategories(t *testing.T) {
+	// Arrange
+	html := `
+		<ul class="categories">
+			<li><a href="/skyrimspecialedition/mods/categories/54">Armour (12.5k)</a></li>
+			<li><a href="/skyrimspecialedition/mods/categories/42">Animation</a> <span class="count">1,500</span></li>
+			<li><a href="/skyrimspecialedition/mods/categories/54">Armour (12.5k)</a></li>
+			<li><a href="/skyrimspecialedition/mods/categories/1">Miscellaneous</a></li>
+			<li></li>
+		</ul>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	categories := ExtractCategories(doc)
+
+	// Assert
+	assert.Equal(t, []types.TaxonomyEntry{
+		{Count: 12500, Name: "Armour", Url: "/skyrimspecialedition/mods/categories/54"},
+		{Count: 1500, Name: "Animation", Url: "/skyrimspecialedition/mods/categories/42"},
+		{Name: "Miscellaneous", Url: "/skyrimspecialedition/mods/categories/1"},
+	}, categories)
+}
+
+func TestExtractTagFilter(t *testing.T) {
+	// Arrange
+	html := `<div class="tag-filter"><ul><li><label>Lore Friendly <span class="count">(321)</span></label></li><li><label>NSFW</label></li></ul></div>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	tags := ExtractTagFilter(doc)
+
+	// Assert
+	assert.Equal(t, []types.TaxonomyEntry{{Count: 321, Name: "Lore Friendly"}, {Name: "NSFW"}}, tags)
+}
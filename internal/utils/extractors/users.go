@@ -0,0 +1,48 @@
+package extractors
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
+)
+
+// ExtractUserMods parses a page of an author's profile "Mods" tab and returns the mods
+// listed on it. The game and mod ID are read from each mod link; tiles without a link
+// to a mod page are ignored, and counts that can't be parsed are left at zero.
+func ExtractUserMods(doc *goquery.Document) []types.UserMod {
+	tiles := doc.Find(".mod-tile")
+	mods := make([]types.UserMod, 0, tiles.Length())
+
+	tiles.Each(func(i int, tile *goquery.Selection) {
+		link := tile.Find(".tile-name a").First()
+		href, _ := link.Attr("href")
+
+		game, id, err := formatters.ParseModUrl(strings.TrimSpace(href))
+		if err != nil {
+			return
+		}
+
+		mod := types.UserMod{
+			Game:  game,
+			ModID: id,
+			Name:  formatters.CleanTextSelect(link),
+			Url:   strings.TrimSpace(href),
+		}
+		if downloads, err := formatters.ParseCount(formatters.CleanTextSelect(tile.Find(".downloadcount").First())); err == nil {
+			mod.Downloads = downloads
+		}
+		if endorsements, err := formatters.ParseCount(formatters.CleanTextSelect(tile.Find(".endorsecount").First())); err == nil {
+			mod.Endorsements = endorsements
+		}
+		mods = append(mods, mod)
+	})
+
+	return mods
+}
+
+// HasNextPage reports whether a paginated listing links to a following page.
+func HasNextPage(doc *goquery.Document) bool {
+	return doc.Find(".pagination a.next, .pagination a[rel='next'], a[rel='next']").Length() > 0
+}
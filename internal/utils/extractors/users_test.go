@@ -0,0 +1,59 @@
+package extractors
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractUserMods(t *testing.T) {
+	// Arrange
+	html := `
+		<ul>
+			<li class="mod-tile">
+				<p class="tile-name"><a href="https://www.nexusmods.com/skyrimspecialedition/mods/266">Unofficial Patch</a></p>
+				<span class="downloadcount">12.5k</span>
+				<span class="endorsecount">1,500</span>
+			</li>
+			<li class="mod-tile">
+				<p class="tile-name"><a href="https://www.nexusmods.com/fallout4/mods/42">Armor Pack</a></p>
+				<span class="downloadcount">n/a</span>
+			</li>
+			<li class="mod-tile">
+				<p class="tile-name"><a href="https://www.nexusmods.com/images/1">Image</a></p>
+			</li>
+		</ul>`
+	doc, _ := goquery.NewDocumentFromReader(strings.NewReader(html))
+
+	// Act
+	mods := ExtractUserMods(doc)
+
+	// Assert
+	assert.Equal(t, []types.UserMod{
+		{Downloads: 12500, Endorsements: 1500, Game: "skyrimspecialedition", ModID: 266, Name: "Unofficial Patch", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/266"},
+		{Game: "fallout4", ModID: 42, Name: "Armor Pack", Url: "https://www.nexusmods.com/fallout4/mods/42"},
+	}, mods)
+}
+
+func TestHasNextPage(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want bool
+	}{
+		{name: "next link", html: `<div class="pagination"><a class="next" href="?page=2">Next</a></div>`, want: true},
+		{name: "rel next", html: `<a rel="next" href="?page=2">2</a>`, want: true},
+		{name: "last page", html: `<div class="pagination"><a href="?page=1">1</a></div>`, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, _ := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+
+			assert.Equal(t, tt.want, HasNextPage(doc))
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package extractors
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+)
+
+// Confidence levels of a version guessed from the text of a mod page.
+const (
+	// VersionGuessHigh is a version marked as such in the mod name, e.g. "MyMod v2.3".
+	VersionGuessHigh = "high"
+	// VersionGuessMedium is a dotted number in the mod name, e.g. "MyMod 2.3".
+	VersionGuessMedium = "medium"
+	// VersionGuessLow is a version marked as such in the mod description.
+	VersionGuessLow = "low"
+)
+
+var (
+	// markedVersionPattern matches a version preceded by "v", "ver" or "version",
+	// e.g. "v2.3", "Ver. 1.0" or "version 4".
+	markedVersionPattern = regexp.MustCompile(`(?i)(?:^|[\s(\[_-])(?:v|ver\.?|version)\s?(\d+(?:\.\d+){0,3}[a-z]?)\b`)
+	// dottedVersionPattern matches a standalone dotted number, e.g. "2.3" or "1.0.2b".
+	dottedVersionPattern = regexp.MustCompile(`(?i)(?:^|[\s(\[_-])(\d+(?:\.\d+){1,3}[a-z]?)(?:$|[\s)\],])`)
+)
+
+// GuessVersion looks for the version of a mod in its name and descriptions, for mods
+// that don't set file versions. A version marked with "v" or "version" in the name is
+// guessed with high confidence, a dotted number in the name with medium confidence,
+// and a marked version in the short or full description with low confidence. The
+// version is returned without its marker, e.g. "2.3" for "MyMod v2.3", along with its
+// confidence; both are empty when no version is found.
+func GuessVersion(mod types.ModInfo) (string, string) {
+	if version := findVersion(markedVersionPattern, mod.Name); version != "" {
+		return version, VersionGuessHigh
+	}
+	if version := findVersion(dottedVersionPattern, mod.Name); version != "" {
+		return version, VersionGuessMedium
+	}
+	for _, description := range []string{mod.ShortDescription, mod.Description} {
+		if version := findVersion(markedVersionPattern, description); version != "" {
+			return version, VersionGuessLow
+		}
+	}
+	return "", ""
+}
+
+// findVersion returns the last version matched by pattern in text, lowercased, or an
+// empty string when there is none. The last match is used as names tend to end with
+// the version, e.g. "Skyrim 2.0 Fixes v1.4".
+func findVersion(pattern *regexp.Regexp, text string) string {
+	matches := pattern.FindAllStringSubmatch(text, -1)
+	if len(matches) == 0 {
+		return ""
+	}
+	return strings.ToLower(matches[len(matches)-1][1])
+}
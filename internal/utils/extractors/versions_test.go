@@ -0,0 +1,36 @@
+package extractors
+
+import (
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGuessVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		mod        types.ModInfo
+		version    string
+		confidence string
+	}{
+		{"marked in name", types.ModInfo{Name: "MyMod v2.3"}, "2.3", VersionGuessHigh},
+		{"version word in name", types.ModInfo{Name: "MyMod (Version 1.0.2b)"}, "1.0.2b", VersionGuessHigh},
+		{"last marked version in name", types.ModInfo{Name: "Skyrim 2.0 Fixes V1.4"}, "1.4", VersionGuessHigh},
+		{"dotted number in name", types.ModInfo{Name: "MyMod 2.3"}, "2.3", VersionGuessMedium},
+		{"marked in description", types.ModInfo{Name: "MyMod", Description: "Updated to ver. 3 with fixes"}, "3", VersionGuessLow},
+		{"number without dot in name", types.ModInfo{Name: "Skyrim 2020 Patch"}, "", ""},
+		{"word starting with v", types.ModInfo{Name: "Vivid Weathers", Description: "Very vibrant"}, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			version, confidence := GuessVersion(tt.mod)
+
+			// Assert
+			assert.Equal(t, tt.version, version)
+			assert.Equal(t, tt.confidence, confidence)
+		})
+	}
+}
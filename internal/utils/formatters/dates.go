@@ -0,0 +1,131 @@
+package formatters
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// nexusDateLayouts lists the layouts Nexus Mods uses when rendering dates, covering
+// the visible "13 October 2024, 10:44AM" text, its 24-hour variants rendered in other
+// languages once their month names are translated, as well as the machine readable
+// "2024-10-13 10:44" datetime attribute and date-only variants.
+var nexusDateLayouts = []string{
+	"2 January 2006, 3:04PM",
+	"2 January 2006, 3:04 PM",
+	"2 Jan 2006, 3:04PM",
+	"2 Jan 2006, 3:04 PM",
+	"2 January 2006, 15:04",
+	"2 January 2006 15:04",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+	"2 January 2006",
+	"2 Jan 2006",
+	"2006-01-02",
+}
+
+// localizedMonths maps the month names of the languages Nexus Mods pages can be read
+// in, in their nominative and genitive forms, to their English names.
+var localizedMonths = map[string]string{
+	// German
+	"januar": "January", "jänner": "January", "februar": "February", "märz": "March", "mai": "May", "juni": "June", "juli": "July", "oktober": "October", "dezember": "December",
+	// Spanish
+	"enero": "January", "febrero": "February", "marzo": "March", "abril": "April", "mayo": "May", "junio": "June", "julio": "July", "agosto": "August", "septiembre": "September", "setiembre": "September", "octubre": "October", "noviembre": "November", "diciembre": "December",
+	// French
+	"janvier": "January", "février": "February", "mars": "March", "avril": "April", "juin": "June", "juillet": "July", "août": "August", "septembre": "September", "octobre": "October", "novembre": "November", "décembre": "December",
+	// Italian
+	"gennaio": "January", "febbraio": "February", "aprile": "April", "maggio": "May", "giugno": "June", "luglio": "July", "settembre": "September", "ottobre": "October", "dicembre": "December",
+	// Dutch
+	"januari": "January", "februari": "February", "maart": "March", "mei": "May", "augustus": "August",
+	// Polish
+	"styczeń": "January", "stycznia": "January", "luty": "February", "lutego": "February", "marzec": "March", "marca": "March", "kwiecień": "April", "kwietnia": "April", "maj": "May", "maja": "May", "czerwiec": "June", "czerwca": "June", "lipiec": "July", "lipca": "July", "sierpień": "August", "sierpnia": "August", "wrzesień": "September", "września": "September", "październik": "October", "października": "October", "listopad": "November", "listopada": "November", "grudzień": "December", "grudnia": "December",
+	// Portuguese
+	"janeiro": "January", "fevereiro": "February", "março": "March", "maio": "May", "junho": "June", "julho": "July", "setembro": "September", "outubro": "October", "novembro": "November", "dezembro": "December",
+	// Russian
+	"январь": "January", "января": "January", "февраль": "February", "февраля": "February", "март": "March", "марта": "March", "апрель": "April", "апреля": "April", "май": "May", "мая": "May", "июнь": "June", "июня": "June", "июль": "July", "июля": "July", "август": "August", "августа": "August", "сентябрь": "September", "сентября": "September", "октябрь": "October", "октября": "October", "ноябрь": "November", "ноября": "November", "декабрь": "December", "декабря": "December",
+}
+
+// dateFillers are the words other languages put between the parts of a date, e.g.
+// "13 de octubre de 2024" or "13. Oktober 2024 um 10:44", dropped before parsing.
+var dateFillers = map[string]bool{"de": true, "del": true, "à": true, "a": true, "las": true, "alle": true, "um": true, "om": true, "o": true, "às": true, "в": true, "г": true}
+
+// ParseNexusDate parses a date as rendered on Nexus Mods pages (e.g. "13 October 2024, 10:44AM")
+// into a time.Time in UTC, in English or in one of the other languages the pages can be
+// read in (e.g. "13. Oktober 2024, 10:44"). It tries each known layout in turn and
+// returns an error if none match.
+func ParseNexusDate(input string) (time.Time, error) {
+	value := translateDate(input)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("empty date")
+	}
+
+	for _, layout := range nexusDateLayouts {
+		if parsed, err := time.ParseInLocation(layout, value, time.UTC); err == nil {
+			return parsed, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognised date format: %q", input)
+}
+
+// translateDate rewrites a date rendered in another language with the English month
+// names and without the words and day dots the English layouts don't have, e.g.
+// "13 October 2024 10:44" for "13 de octubre de 2024 a las 10:44".
+func translateDate(input string) string {
+	fields := strings.Fields(input)
+	words := make([]string, 0, len(fields))
+	for i, field := range fields {
+		lower := strings.ToLower(field)
+		trimmed := strings.TrimRight(lower, ".,")
+		suffix := lower[len(trimmed):]
+		if dateFillers[trimmed] {
+			continue
+		}
+		if month, ok := localizedMonths[trimmed]; ok {
+			words = append(words, month+strings.TrimSuffix(suffix, "."))
+			continue
+		}
+		// The German day is followed by a dot, e.g. "13."
+		if i == 0 && suffix == "." && isDigits(trimmed) {
+			words = append(words, trimmed)
+			continue
+		}
+		words = append(words, field)
+	}
+	return strings.Join(words, " ")
+}
+
+// isDigits reports whether s is a non-empty run of ASCII digits.
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// DateFormats are the named formats DateLayout accepts for the --date-format option,
+// suggested when completing it.
+var DateFormats = []string{"rfc3339", "date", "datetime", "unix"}
+
+// DateLayout resolves the value of the --date-format option into the layout used to
+// serialize timestamps. It accepts the named formats "rfc3339", "date", "datetime" and
+// "unix" (case-insensitive); any other value is treated as a custom Go time layout.
+func DateLayout(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "rfc3339":
+		return time.RFC3339
+	case "date":
+		return time.DateOnly
+	case "datetime":
+		return time.DateTime
+	case "unix":
+		return "unix"
+	default:
+		return name
+	}
+}
@@ -0,0 +1,53 @@
+package formatters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseNexusDate(t *testing.T) {
+	expected := time.Date(2024, time.October, 13, 10, 44, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected time.Time
+		hasError bool
+	}{
+		{"Page text", "13 October 2024, 10:44AM", expected, false},
+		{"Extra whitespace", "  13 October 2024,\n 10:44AM ", expected, false},
+		{"Datetime attribute", "2024-10-13 10:44", expected, false},
+		{"Date only", "13 October 2024", time.Date(2024, time.October, 13, 0, 0, 0, 0, time.UTC), false},
+		{"German", "13. Oktober 2024, 10:44", expected, false},
+		{"German with um", "13. Oktober 2024 um 10:44", expected, false},
+		{"Spanish", "13 de octubre de 2024, 10:44", expected, false},
+		{"French", "13 octobre 2024 à 10:44", expected, false},
+		{"Polish", "13 października 2024, 10:44", expected, false},
+		{"Russian", "13 октября 2024 г., 10:44", expected, false},
+		{"Empty", "", time.Time{}, true},
+		{"Garbage", "yesterday", time.Time{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ParseNexusDate(tt.input)
+			if tt.hasError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, tt.expected.Equal(result), "expected %v, got %v", tt.expected, result)
+		})
+	}
+}
+
+func TestDateLayout(t *testing.T) {
+	assert.Equal(t, time.RFC3339, DateLayout(""))
+	assert.Equal(t, time.RFC3339, DateLayout("RFC3339"))
+	assert.Equal(t, time.DateOnly, DateLayout("date"))
+	assert.Equal(t, time.DateTime, DateLayout("datetime"))
+	assert.Equal(t, "unix", DateLayout("unix"))
+	assert.Equal(t, "02/01/2006", DateLayout("02/01/2006"))
+}
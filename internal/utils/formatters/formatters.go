@@ -1,17 +1,25 @@
 package formatters
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/url"
+	"os"
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/TylerBrock/colorjson"
 	"github.com/fatih/color"
+	"github.com/savioxavier/termlink"
 )
 
 // CleanAndFormatText processes the input string by removing escape characters,
@@ -83,9 +91,33 @@ func FormatResultsAsJson(mods types.ModInfo) (string, error) {
 	return string(jsonData), nil
 }
 
-// PrintJson prints a given JSON-formatted string to the standard output.
+// ResultsOutput is where the JSON results are printed, the standard output when nil.
+var ResultsOutput io.Writer
+
+// StatusOutput is where the status lines and spinners are written, the standard output
+// when nil. In quiet mode it is the standard error, so that the standard output only
+// carries the results.
+var StatusOutput io.Writer
+
+// Status returns the writer the status lines and spinners are written to.
+func Status() io.Writer {
+	if StatusOutput == nil {
+		return os.Stdout
+	}
+	return StatusOutput
+}
+
+// resultsOutput returns the writer the JSON results are printed to.
+func resultsOutput() io.Writer {
+	if ResultsOutput == nil {
+		return os.Stdout
+	}
+	return ResultsOutput
+}
+
+// PrintJson prints a given JSON-formatted string to the results output.
 func PrintJson(data string) {
-	fmt.Println(data)
+	fmt.Fprintln(resultsOutput(), data)
 }
 
 // PrintPrettyJson takes a JSON string, unmarshals it into an object, and prints
@@ -112,10 +144,19 @@ func PrintPrettyJson(data string, useAltColors ...bool) error {
 		return fmt.Errorf("failed to marshal formatted JSON: %w", err)
 	}
 
-	fmt.Println(string(s))
+	fmt.Fprintln(resultsOutput(), string(s))
 	return nil
 }
 
+// PathLink returns path as a green link to itself for terminals that support it, or
+// as is when colors are disabled, e.g. with --no-color.
+func PathLink(path string) string {
+	if color.NoColor {
+		return path
+	}
+	return termlink.ColorLink(path, path, "green")
+}
+
 // RemoveHTTPPrefix removes the http or https prefix from a given URL and returns
 // the modified string.
 func RemoveHTTPPrefix(url string) string {
@@ -133,3 +174,273 @@ func StrToInt(input string) (int64, error) {
 
 	return result, nil
 }
+
+// ParseModUrl extracts the game domain and mod ID from a Nexus mod page URL such as
+// "https://www.nexusmods.com/skyrimspecialedition/mods/12604?tab=files". Returns an
+// error if the URL doesn't point at a mod page.
+func ParseModUrl(modUrl string) (string, int64, error) {
+	u, err := url.Parse(strings.TrimSpace(modUrl))
+	if err != nil {
+		return "", 0, err
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 1; i+1 < len(segments); i++ {
+		if segments[i] != "mods" {
+			continue
+		}
+		if id, err := strconv.ParseInt(segments[i+1], 10, 64); err == nil {
+			return segments[i-1], id, nil
+		}
+	}
+
+	return "", 0, fmt.Errorf("not a mod url: %q", modUrl)
+}
+
+// StrToInt64Slice converts a comma separated string of numbers (e.g. "123,456") into
+// a slice of int64. Entries of the form @path are expanded to the IDs of that file, as
+// read by ReadModIDs, in place, so that "123,@ids.txt,456" mixes both. Surrounding
+// whitespace and empty entries are ignored. It returns an error if any entry fails to
+// parse, a file can't be read, or if no entries are found.
+func StrToInt64Slice(input string) ([]int64, error) {
+	var results []int64
+
+	for _, part := range strings.Split(input, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		if path, ok := strings.CutPrefix(part, "@"); ok {
+			data, err := fsys.Default.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("error reading ids file: %w", err)
+			}
+			values, err := ReadModIDs(bytes.NewReader(data))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", path, err)
+			}
+			results = append(results, values...)
+			continue
+		}
+
+		value, err := StrToInt(part)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, value)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no mod ids found in %q", input)
+	}
+
+	return results, nil
+}
+
+// ReadModIDs reads mod IDs from r, one per line, such as a wishlist file or the output
+// of another command. A line may also hold several comma separated IDs, and anything
+// after a "#" is a comment. Blank lines are ignored. It returns an error naming the
+// line of an entry that fails to parse, or if no IDs are found.
+func ReadModIDs(r io.Reader) ([]int64, error) {
+	var results []int64
+
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		for _, part := range strings.Split(text, ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+
+			value, err := StrToInt(part)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: invalid mod id %q", line, part)
+			}
+			results = append(results, value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading mod ids: %w", err)
+	}
+
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no mod ids found")
+	}
+
+	return results, nil
+}
+
+// PlanModIDs returns the mod IDs to scrape out of ids, as pasted or read from files:
+// each ID once, in the order it first appears, along with the number of duplicates
+// dropped, so that no mod is requested twice. Returns an error listing the IDs that
+// aren't positive, Nexus Mods numbering mods from 1.
+func PlanModIDs(ids []int64) ([]int64, int, error) {
+	var invalid []string
+	planned := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		switch {
+		case id <= 0:
+			invalid = append(invalid, strconv.FormatInt(id, 10))
+		case !seen[id]:
+			seen[id] = true
+			planned = append(planned, id)
+		}
+	}
+
+	if len(invalid) > 0 {
+		return nil, 0, fmt.Errorf("invalid mod ids %s: mod ids must be positive numbers", strings.Join(invalid, ", "))
+	}
+	return planned, len(ids) - len(planned), nil
+}
+
+// ParseCount converts a count as displayed on Nexus Mods (e.g. "1,234", "12.5k" or
+// "3M") into an int64, reading the separators of the configured locale, e.g. "1.234"
+// or "12,5k" in German. It returns an error if the value can't be parsed.
+func ParseCount(input string) (int64, error) {
+	value := strings.ToLower(strings.TrimSpace(input))
+	if value == "" {
+		return 0, fmt.Errorf("empty count")
+	}
+
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(value, "k"):
+		multiplier, value = 1e3, strings.TrimSuffix(value, "k")
+	case strings.HasSuffix(value, "m"):
+		multiplier, value = 1e6, strings.TrimSuffix(value, "m")
+	case strings.HasSuffix(value, "b"):
+		multiplier, value = 1e9, strings.TrimSuffix(value, "b")
+	}
+
+	// Abbreviated counts have decimals, the others are whole numbers
+	number, err := strconv.ParseFloat(normalizeNumber(value, multiplier == 1), 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid count %q: %w", input, err)
+	}
+
+	return int64(number*multiplier + 0.5), nil
+}
+
+// FormatCount formats a count with thousands separators as displayed on Nexus Mods,
+// e.g. 12,345 for 12345, which ParseCount reads back.
+func FormatCount(count int64) string {
+	digits := strconv.FormatInt(count, 10)
+	if count < 0 {
+		return digits
+	}
+
+	var formatted strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			formatted.WriteByte(',')
+		}
+		formatted.WriteRune(digit)
+	}
+	return formatted.String()
+}
+
+// ParseSize converts a file size as displayed on Nexus Mods (e.g. "512KB", "10MB" or
+// "1.5 GB") into a number of bytes, reading the separators of the configured locale,
+// e.g. "1,5 GB" in German, and the French units, e.g. "10 Mo". Units are binary, a KB
+// being 1024 bytes, and a value without a unit is a number of bytes. It returns an
+// error if the value can't be parsed.
+func ParseSize(input string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(input))
+	if value == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+
+	multiplier := 1.0
+	for _, unit := range []struct {
+		suffix     string
+		multiplier float64
+	}{
+		{"TB", 1 << 40},
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+		{"BYTES", 1},
+		{"B", 1},
+		{"TO", 1 << 40},
+		{"GO", 1 << 30},
+		{"MO", 1 << 20},
+		{"KO", 1 << 10},
+		{"OCTETS", 1},
+		{"O", 1},
+	} {
+		if strings.HasSuffix(value, unit.suffix) {
+			multiplier, value = unit.multiplier, strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			break
+		}
+	}
+
+	// Sizes have decimals, "1.234 GB" is read with the decimal separator of the locale
+	number, err := strconv.ParseFloat(normalizeNumber(value, false), 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", input)
+	}
+
+	return int64(number*multiplier + 0.5), nil
+}
+
+// FormatSize formats a number of bytes as a file size displayed on Nexus Mods, in the
+// largest binary unit it reaches with at most one decimal, e.g. 10MB or 1.5GB, which
+// ParseSize reads back. Sizes under a KB are formatted in bytes, e.g. 100B.
+func FormatSize(size int64) string {
+	for _, unit := range []struct {
+		suffix string
+		size   int64
+	}{
+		{"TB", 1 << 40},
+		{"GB", 1 << 30},
+		{"MB", 1 << 20},
+		{"KB", 1 << 10},
+	} {
+		if size >= unit.size {
+			value := strconv.FormatFloat(float64(size)/float64(unit.size), 'f', 1, 64)
+			return strings.TrimSuffix(value, ".0") + unit.suffix
+		}
+	}
+	return strconv.FormatInt(size, 10) + "B"
+}
+
+// FormatPrometheusMetrics renders the outcome of a scrape run in the Prometheus text
+// exposition format, suitable for the node_exporter textfile collector. Every metric
+// is labelled with the game that was scraped.
+func FormatPrometheusMetrics(game string, summary types.ScrapeSummary, duration time.Duration, finished time.Time) string {
+	succeeded, skipped, unchanged, failed := len(summary.Succeeded), len(summary.Skipped)+len(summary.Ignored), len(summary.Unchanged), len(summary.Failed)
+	unavailable := len(summary.Unavailable)
+	lastRunSuccess := 0
+	if failed == 0 {
+		lastRunSuccess = 1
+	}
+
+	metrics := []struct {
+		name, help, kind string
+		value            string
+	}{
+		{"nexus_mods_scraper_mods_total", "Number of mods requested in the last run.", "gauge", strconv.Itoa(succeeded + skipped + unchanged + unavailable + failed)},
+		{"nexus_mods_scraper_mods_succeeded", "Number of mods scraped successfully in the last run.", "gauge", strconv.Itoa(succeeded)},
+		{"nexus_mods_scraper_mods_skipped", "Number of mods skipped by filters or the ignore list in the last run.", "gauge", strconv.Itoa(skipped)},
+		{"nexus_mods_scraper_mods_unchanged", "Number of mods skipped as unchanged since they were last saved in the last run.", "gauge", strconv.Itoa(unchanged)},
+		{"nexus_mods_scraper_mods_unavailable", "Number of mods found hidden, removed or not found in the last run.", "gauge", strconv.Itoa(unavailable)},
+		{"nexus_mods_scraper_mods_failed", "Number of mods that failed to scrape in the last run.", "gauge", strconv.Itoa(failed)},
+		{"nexus_mods_scraper_run_duration_seconds", "Duration of the last run in seconds.", "gauge", strconv.FormatFloat(duration.Seconds(), 'f', 3, 64)},
+		{"nexus_mods_scraper_last_run_timestamp_seconds", "Unix time the last run finished.", "gauge", strconv.FormatInt(finished.Unix(), 10)},
+		{"nexus_mods_scraper_last_run_success", "Whether the last run finished without failed mods (1) or not (0).", "gauge", strconv.Itoa(lastRunSuccess)},
+	}
+
+	label := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(game)
+
+	var sb strings.Builder
+	for _, metric := range metrics {
+		fmt.Fprintf(&sb, "# HELP %s %s\n", metric.name, metric.help)
+		fmt.Fprintf(&sb, "# TYPE %s %s\n", metric.name, metric.kind)
+		fmt.Fprintf(&sb, "%s{game=\"%s\"} %s\n", metric.name, label, metric.value)
+	}
+
+	return sb.String()
+}
@@ -1,11 +1,16 @@
 package formatters
 
 import (
+	"bytes"
+	"os"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/fatih/color"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
 	"github.com/ondrovic/nexus-mods-scraper/internal/types"
 )
 
@@ -149,6 +154,43 @@ func TestPrintPrettyJson(t *testing.T) {
 	}
 }
 
+// Test for PrintPrettyJson writing plain JSON to ResultsOutput without colors
+func TestPrintPrettyJson_ResultsOutputNoColor(t *testing.T) {
+	var out bytes.Buffer
+	ResultsOutput = &out
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() {
+		ResultsOutput = nil
+		color.NoColor = noColor
+	}()
+
+	if err := PrintPrettyJson(`{"Name":"Test Mod"}`); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := "{\n    \"Name\": \"Test Mod\"\n}\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+	if link := PathLink("/tmp/mod.json"); link != "/tmp/mod.json" {
+		t.Errorf("expected the plain path, got %q", link)
+	}
+}
+
+// Test for Status falling back to stdout
+func TestStatus(t *testing.T) {
+	defer func() { StatusOutput = nil }()
+
+	if Status() != os.Stdout {
+		t.Errorf("expected stdout by default")
+	}
+	StatusOutput = os.Stderr
+	if Status() != os.Stderr {
+		t.Errorf("expected the configured status output")
+	}
+}
+
 // Test for RemoveHTTPPrefix
 func TestRemoveHTTPPrefix(t *testing.T) {
 	tests := []struct {
@@ -207,3 +249,278 @@ func TestStrToInt(t *testing.T) {
 		})
 	}
 }
+
+// Test for StrToInt64Slice
+func TestParseModUrl(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		wantGame string
+		wantID   int64
+		wantErr  bool
+	}{
+		{name: "Mod page", input: "https://www.nexusmods.com/skyrimspecialedition/mods/12604", wantGame: "skyrimspecialedition", wantID: 12604},
+		{name: "Files tab with trailing slash", input: "https://www.nexusmods.com/fallout4/mods/42/?tab=files", wantGame: "fallout4", wantID: 42},
+		{name: "External link", input: "https://skse.silverlock.org/", wantErr: true},
+		{name: "Not a mod id", input: "https://www.nexusmods.com/skyrim/mods/categories", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, id, err := ParseModUrl(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expected error: %v, got: %v", tt.wantErr, err)
+			}
+			if game != tt.wantGame || id != tt.wantID {
+				t.Errorf("expected %s/%d, got %s/%d", tt.wantGame, tt.wantID, game, id)
+			}
+		})
+	}
+}
+
+func TestStrToInt64Slice(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []int64
+		hasError bool
+	}{
+		{"Single value", "123", []int64{123}, false},
+		{"Multiple values", "123,456, 789", []int64{123, 456, 789}, false},
+		{"Trailing comma", "123,", []int64{123}, false},
+		{"Invalid value", "123,abc", nil, true},
+		{"Empty input", " , ", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := StrToInt64Slice(tt.input)
+			if (err != nil) != tt.hasError {
+				t.Errorf("expected error: %v, got: %v", tt.hasError, err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestStrToInt64Slice_IdsFile(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+	if err := fsys.Default.WriteFile("ids.txt", []byte("# wishlist\n200\n300, 400\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := StrToInt64Slice("123,@ids.txt, 456")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []int64{123, 200, 300, 400, 456}; !reflect.DeepEqual(result, expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	if _, err := StrToInt64Slice("123,@missing.txt"); err == nil || !strings.HasPrefix(err.Error(), "error reading ids file:") {
+		t.Errorf("expected an error reading the ids file, got: %v", err)
+	}
+	if _, err := StrToInt64Slice("@ids.txt,abc"); err == nil {
+		t.Errorf("expected an error for the invalid entry")
+	}
+}
+
+func TestReadModIDs(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []int64
+		err      string
+	}{
+		{name: "one per line", input: "123\n456\n", expected: []int64{123, 456}},
+		{name: "comments and blank lines", input: "# wishlist\n\n123 # SkyUI\n 456\r\n", expected: []int64{123, 456}},
+		{name: "comma separated", input: "123,456\n789", expected: []int64{123, 456, 789}},
+		{name: "invalid entry", input: "123\nskyui\n", err: `line 2: invalid mod id "skyui"`},
+		{name: "empty input", input: "# nothing\n", err: "no mod ids found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ReadModIDs(strings.NewReader(tt.input))
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestPlanModIDs(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      []int64
+		expected   []int64
+		duplicates int
+		err        string
+	}{
+		{name: "unique ids", input: []int64{3, 1, 2}, expected: []int64{3, 1, 2}},
+		{name: "duplicates dropped in order", input: []int64{3, 1, 3, 2, 1, 3}, expected: []int64{3, 1, 2}, duplicates: 3},
+		{name: "non-positive ids", input: []int64{1, 0, 2, -5}, err: "invalid mod ids 0, -5: mod ids must be positive numbers"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, duplicates, err := PlanModIDs(tt.input)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Errorf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(result, tt.expected) || duplicates != tt.duplicates {
+				t.Errorf("expected %v with %d duplicates, got %v with %d", tt.expected, tt.duplicates, result, duplicates)
+			}
+		})
+	}
+}
+
+// Test for ParseCount
+func TestParseCount(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		hasError bool
+	}{
+		{"1,234", 1234, false},
+		{" 987 ", 987, false},
+		{"12.5k", 12500, false},
+		{"3M", 3000000, false},
+		{"", 0, true},
+		{"lots", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseCount(tt.input)
+			if (err != nil) != tt.hasError {
+				t.Errorf("expected error: %v, got: %v", tt.hasError, err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Test for ParseSize
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+		hasError bool
+	}{
+		{"10MB", 10485760, false},
+		{"1.5 GB", 1610612736, false},
+		{"512KB", 524288, false},
+		{"1,024kb", 1048576, false},
+		{"1.234GB", 1324997411, false},
+		{"12.345 MB", 12944671, false},
+		{"1,234 MB", 1293942784, false},
+		{"100 bytes", 100, false},
+		{"42", 42, false},
+		{"", 0, true},
+		{"huge", 0, true},
+		{"-1MB", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseSize(tt.input)
+			if (err != nil) != tt.hasError {
+				t.Errorf("expected error: %v, got: %v", tt.hasError, err)
+			}
+			if result != tt.expected {
+				t.Errorf("expected %d, got %d", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{10485760, "10MB"},
+		{1610612736, "1.5GB"},
+		{524288, "512KB"},
+		{100, "100B"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := FormatSize(tt.input); result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestFormatCount(t *testing.T) {
+	tests := []struct {
+		input    int64
+		expected string
+	}{
+		{0, "0"},
+		{999, "999"},
+		{1000, "1,000"},
+		{1234567, "1,234,567"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			if result := FormatCount(tt.input); result != tt.expected {
+				t.Errorf("expected %s, got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+// Test for FormatPrometheusMetrics
+func TestFormatPrometheusMetrics(t *testing.T) {
+	summary := types.ScrapeSummary{
+		Succeeded:   []int64{1, 2},
+		Skipped:     []int64{3},
+		Unchanged:   []int64{5},
+		Unavailable: []types.UnavailableMod{{ModID: 6, Status: types.ModStatusHidden}},
+		Failed:      []types.FailedMod{{ModID: 4, Error: "boom"}},
+	}
+	finished := time.Unix(1717243200, 0)
+
+	result := FormatPrometheusMetrics(`sky"rim`, summary, 1500*time.Millisecond, finished)
+
+	for _, line := range []string{
+		"# TYPE nexus_mods_scraper_mods_total gauge\n",
+		`nexus_mods_scraper_mods_total{game="sky\"rim"} 6` + "\n",
+		`nexus_mods_scraper_mods_succeeded{game="sky\"rim"} 2` + "\n",
+		`nexus_mods_scraper_mods_skipped{game="sky\"rim"} 1` + "\n",
+		`nexus_mods_scraper_mods_unchanged{game="sky\"rim"} 1` + "\n",
+		`nexus_mods_scraper_mods_unavailable{game="sky\"rim"} 1` + "\n",
+		`nexus_mods_scraper_mods_failed{game="sky\"rim"} 1` + "\n",
+		`nexus_mods_scraper_run_duration_seconds{game="sky\"rim"} 1.500` + "\n",
+		`nexus_mods_scraper_last_run_timestamp_seconds{game="sky\"rim"} 1717243200` + "\n",
+		`nexus_mods_scraper_last_run_success{game="sky\"rim"} 0` + "\n",
+	} {
+		if !strings.Contains(result, line) {
+			t.Errorf("expected metrics to contain %q, got:\n%s", line, result)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package formatters
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale is the language Nexus Mods pages are read in unless configured
+// otherwise.
+const DefaultLocale = "en"
+
+// Locale is the language Nexus Mods renders the pages in, as set with the --locale
+// flag, e.g. "de" or "pt-BR". It tells the decimal separator of the counts and sizes.
+var Locale = DefaultLocale
+
+// decimalSeparators maps the supported languages to the decimal separator of their
+// numbers, the other one of "." and "," separating the thousands.
+var decimalSeparators = map[string]string{
+	"de": ",",
+	"en": ".",
+	"es": ",",
+	"fr": ",",
+	"it": ",",
+	"nl": ",",
+	"pl": ",",
+	"pt": ",",
+	"ru": ",",
+}
+
+// groupedPattern matches an integer written with thousands separators, e.g. "1,234"
+// or "1.234.567", whatever the locale.
+var groupedPattern = regexp.MustCompile(`^\d{1,3}([.,])\d{3}(?:[.,]\d{3})*$`)
+
+// ValidateLocale checks that the language of locale, e.g. "de" for "de-DE", is one the
+// pages can be read in. Returns an error listing the supported languages otherwise.
+func ValidateLocale(locale string) error {
+	if _, ok := decimalSeparators[language(locale)]; ok {
+		return nil
+	}
+	return fmt.Errorf("unsupported locale %q, expected one of %s", locale, strings.Join(Locales(), ", "))
+}
+
+// Locales returns the languages the pages can be read in, sorted, e.g. to suggest them
+// when completing the --locale flag.
+func Locales() []string {
+	supported := make([]string, 0, len(decimalSeparators))
+	for lang := range decimalSeparators {
+		supported = append(supported, lang)
+	}
+	sort.Strings(supported)
+	return supported
+}
+
+// AcceptLanguage returns the Accept-Language header asking for pages in locale, falling
+// back to English, e.g. "de-DE,de;q=0.9,en;q=0.8" for "de-DE".
+func AcceptLanguage(locale string) string {
+	lang := language(locale)
+	if lang == DefaultLocale {
+		return "en-US,en;q=0.9"
+	}
+	if tag := strings.TrimSpace(locale); !strings.EqualFold(tag, lang) {
+		return fmt.Sprintf("%s,%s;q=0.9,en;q=0.8", tag, lang)
+	}
+	return fmt.Sprintf("%s,en;q=0.8", lang)
+}
+
+// Language returns the language of the configured Locale, e.g. "pt" for "pt-BR".
+func Language() string {
+	return language(Locale)
+}
+
+// language returns the lowercased language of a locale, e.g. "pt" for "pt_BR".
+func language(locale string) string {
+	lang, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(locale)), "-")
+	lang, _, _ = strings.Cut(lang, "_")
+	return lang
+}
+
+// normalizeNumber rewrites a number rendered in the configured locale, e.g. "1.234" or
+// "12,5" in German, with a "." decimal separator and no thousands separators, spaces
+// included. When grouped is set, for counts which have no decimals, integers grouped
+// by thousands are read as such whatever the locale, so that values saved in English
+// stay readable. Sizes aren't grouped, their separators follow the locale.
+func normalizeNumber(value string, grouped bool) string {
+	value = strings.NewReplacer(" ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(value)
+	if grouped && groupedPattern.MatchString(value) {
+		return strings.NewReplacer(".", "", ",", "").Replace(value)
+	}
+	if strings.Count(value, ".")+strings.Count(value, ",") == 1 {
+		// Thousands are separated by three digits, a lone separator followed by any
+		// other number of them is the decimal one whatever the locale
+		if i := strings.IndexAny(value, ".,"); len(value)-i-1 != 3 {
+			return value[:i] + "." + value[i+1:]
+		}
+	}
+
+	decimal, ok := decimalSeparators[language(Locale)]
+	if !ok {
+		decimal = "."
+	}
+	group := ","
+	if decimal == "," {
+		group = "."
+	}
+	return strings.Replace(strings.ReplaceAll(value, group, ""), decimal, ".", 1)
+}
@@ -0,0 +1,68 @@
+package formatters
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCount_Locale(t *testing.T) {
+	defer func(previous string) { Locale = previous }(Locale)
+	Locale = "de-DE"
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"1.234", 1234},
+		{"1.234.567", 1234567},
+		{"12,5k", 12500},
+		{"1 234", 1234},
+		{"1,234", 1234},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseCount(tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestParseSize_Locale(t *testing.T) {
+	defer func(previous string) { Locale = previous }(Locale)
+	Locale = "fr"
+
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"1,5 Go", 1610612736},
+		{"10 Mo", 10485760},
+		{"1.5GB", 1610612736},
+		{"1 024 Ko", 1048576},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := ParseSize(tt.input)
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, result)
+		})
+	}
+}
+
+func TestValidateLocale(t *testing.T) {
+	assert.NoError(t, ValidateLocale("pt_BR"))
+	assert.NoError(t, ValidateLocale("EN"))
+	assert.EqualError(t, ValidateLocale("xx"), `unsupported locale "xx", expected one of de, en, es, fr, it, nl, pl, pt, ru`)
+}
+
+func TestAcceptLanguage(t *testing.T) {
+	assert.Equal(t, "en-US,en;q=0.9", AcceptLanguage("en"))
+	assert.Equal(t, "de-DE,de;q=0.9,en;q=0.8", AcceptLanguage("de-DE"))
+	assert.Equal(t, "fr,en;q=0.8", AcceptLanguage("fr"))
+}
@@ -8,8 +8,16 @@ import (
 	"time"
 
 	"github.com/theckman/yacspin"
+	"golang.org/x/term"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/formatters"
 )
 
+// Animated tells whether the spinners are animated. When false, as set with the
+// --no-spinner flag, each step is printed on its own line when it starts and stops,
+// without frames that would clutter logs.
+var Animated = true
+
 // CreateSpinner initializes and returns a yacspin spinner with the provided
 // start and stop messages, characters, and failure configurations.
 func CreateSpinner(startMessage, stopCharacter, stopMessage, stopFailCharacter, stopFailMessage string) *yacspin.Spinner {
@@ -27,10 +35,20 @@ func CreateSpinner(startMessage, stopCharacter, stopMessage, stopFailCharacter,
 		StopFailColors:    []string{"fgHiRed"},
 		StopFailMessage:   stopFailMessage,
 	}
+	if !Animated {
+		cfg.TerminalMode = yacspin.ForceNoTTYMode | yacspin.ForceDumbTerminalMode
+	}
+	if output := formatters.StatusOutput; output != nil {
+		// Animated only when the output is a terminal, the spinner checks stdout itself
+		cfg.Writer = output
+		if file, ok := output.(*os.File); !ok || !term.IsTerminal(int(file.Fd())) {
+			cfg.TerminalMode = yacspin.ForceNoTTYMode | yacspin.ForceDumbTerminalMode
+		}
+	}
 
 	s, err := yacspin.New(cfg)
 	if err != nil {
-		fmt.Printf("failed to create spinner: %v\n", err)
+		fmt.Fprintf(formatters.Status(), "failed to create spinner: %v\n", err)
 		os.Exit(1)
 	}
 
@@ -1,7 +1,9 @@
 package spinners
 
 import (
+	"io"
 	"os"
+	"strings"
 
 	"os/signal"
 	"time"
@@ -38,6 +40,39 @@ func TestCreateSpinner_StartAndStop(t *testing.T) {
 	}
 }
 
+func TestCreateSpinner_NotAnimated(t *testing.T) {
+	// Arrange: the spinner writes to stdout as it is when created
+	Animated = false
+	defer func() { Animated = true }()
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Expected pipe to be created, but got error: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = writer
+	spinner := CreateSpinner("Starting...", "✔", "Completed", "✘", "Failed")
+	os.Stdout = stdout
+
+	// Act
+	if err := spinner.Start(); err != nil {
+		t.Fatalf("Expected spinner to start successfully, but got error: %v", err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if err := spinner.Stop(); err != nil {
+		t.Fatalf("Expected spinner to stop successfully, but got error: %v", err)
+	}
+	writer.Close()
+	output, _ := io.ReadAll(reader)
+
+	// Assert: each step is a plain line, without escape sequences or carriage returns
+	if !strings.Contains(string(output), "Starting...\n") || !strings.HasSuffix(string(output), "Completed\n") {
+		t.Errorf("Expected start and stop lines, but got %q", output)
+	}
+	if strings.ContainsAny(string(output), "\r\x1b") {
+		t.Errorf("Expected no animation, but got %q", output)
+	}
+}
+
 func TestStopOnSignal_Interrupt(t *testing.T) {
 	// Arrange
 	spinner := CreateSpinner("Starting...", "✔", "Completed", "✘", "Failed")
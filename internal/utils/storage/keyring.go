@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/zalando/go-keyring"
+)
+
+// The stores the session cookies can be kept in: a JSON file on disk, or the OS
+// keyring (Windows Credential Manager, macOS Keychain or the Secret Service on Linux).
+const (
+	CookieStoreFile    = "file"
+	CookieStoreKeyring = "keyring"
+)
+
+// keyringService is the service the session cookies are filed under in the OS keyring.
+const keyringService = "nexus-mods-scraper"
+
+// ErrNoKeyringCookies is returned when the OS keyring holds no session cookies for the
+// requested location.
+var ErrNoKeyringCookies = errors.New("no session cookies in the OS keyring, save them with extract --cookie-store keyring")
+
+// ValidateCookieStore returns an error if store isn't one of the cookie stores.
+func ValidateCookieStore(store string) error {
+	if store != CookieStoreFile && store != CookieStoreKeyring {
+		return fmt.Errorf("unsupported cookie store %q, expected file or keyring", store)
+	}
+	return nil
+}
+
+// SaveCookiesToKeyring stores the cookies in the OS keyring as JSON, under the path
+// the cookie file would have been saved to, so that cookie files of different
+// directories, names or profiles keep apart. Returns an error if the keyring is
+// unavailable or refuses the cookies, e.g. because they are too large.
+func SaveCookiesToKeyring(path string, cookies map[string]string) error {
+	data, err := json.Marshal(cookies)
+	if err != nil {
+		return fmt.Errorf("error formatting cookies: %w", err)
+	}
+	if err := keyring.Set(keyringService, path, string(data)); err != nil {
+		return fmt.Errorf("error saving cookies to the OS keyring: %w", err)
+	}
+	return nil
+}
+
+// LoadCookiesFromKeyring returns the cookies stored in the OS keyring under path by
+// SaveCookiesToKeyring. Returns ErrNoKeyringCookies if there are none, or an error if
+// the keyring is unavailable or the entry isn't valid JSON.
+func LoadCookiesFromKeyring(path string) (map[string]string, error) {
+	data, err := keyring.Get(keyringService, path)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, fmt.Errorf("%w (%s)", ErrNoKeyringCookies, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading cookies from the OS keyring: %w", err)
+	}
+
+	var cookies map[string]string
+	if err := json.Unmarshal([]byte(data), &cookies); err != nil {
+		return nil, fmt.Errorf("error decoding cookies from the OS keyring: %w", err)
+	}
+	return cookies, nil
+}
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+)
+
+func TestKeyringCookies(t *testing.T) {
+	// Arrange
+	keyring.MockInit()
+	cookies := map[string]string{"nexusmods_session": "abc", "nexusmods_session_refresh": "def"}
+
+	// Act
+	saveErr := SaveCookiesToKeyring("/home/me/.nexus-mods-scraper/data/session-cookies.json", cookies)
+	loaded, loadErr := LoadCookiesFromKeyring("/home/me/.nexus-mods-scraper/data/session-cookies.json")
+	_, missingErr := LoadCookiesFromKeyring("/home/me/.nexus-mods-scraper/profiles/archival/session-cookies.json")
+
+	// Assert
+	require.NoError(t, saveErr)
+	require.NoError(t, loadErr)
+	assert.Equal(t, cookies, loaded)
+	assert.ErrorIs(t, missingErr, ErrNoKeyringCookies)
+}
+
+func TestKeyringCookies_Unavailable(t *testing.T) {
+	keyring.MockInitWithError(errors.New("no secret service"))
+
+	saveErr := SaveCookiesToKeyring("session-cookies.json", map[string]string{"a": "b"})
+	_, loadErr := LoadCookiesFromKeyring("session-cookies.json")
+
+	assert.EqualError(t, saveErr, "error saving cookies to the OS keyring: no secret service")
+	assert.EqualError(t, loadErr, "error reading cookies from the OS keyring: no secret service")
+}
+
+func TestValidateCookieStore(t *testing.T) {
+	assert.NoError(t, ValidateCookieStore(CookieStoreFile))
+	assert.NoError(t, ValidateCookieStore(CookieStoreKeyring))
+	assert.EqualError(t, ValidateCookieStore("vault"), `unsupported cookie store "vault", expected file or keyring`)
+}
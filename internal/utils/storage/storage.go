@@ -0,0 +1,44 @@
+package storage
+
+import "path/filepath"
+
+// profile is the name of the active profile, empty for the default one.
+var profile string
+
+// SetProfile makes name the active profile, whose directory GetDataStoragePath returns
+// from then on. An empty name switches back to the default profile.
+func SetProfile(name string) {
+	profile = name
+}
+
+// ActiveProfile returns the name of the active profile, or "" for the default one.
+func ActiveProfile() string {
+	return profile
+}
+
+// GetDataStoragePath returns the data storage path of the active profile: the one in
+// the user's home directory for the default profile, or the profile's own directory
+// under ProfilesPath.
+func GetDataStoragePath() string {
+	if profile != "" {
+		return ProfilePath(profile)
+	}
+	return defaultDataStoragePath()
+}
+
+// ProfilesPath returns the directory holding the directories of the named profiles,
+// next to the data storage path of the default profile.
+func ProfilesPath() string {
+	return filepath.Join(filepath.Dir(defaultDataStoragePath()), "profiles")
+}
+
+// ProfilePath returns the data storage path of the named profile.
+func ProfilePath(name string) string {
+	return filepath.Join(ProfilesPath(), name)
+}
+
+// DefaultProfilePath returns the data storage path of the default profile, whichever
+// profile is active.
+func DefaultProfilePath() string {
+	return defaultDataStoragePath()
+}
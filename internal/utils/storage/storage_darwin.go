@@ -8,9 +8,10 @@ import (
 	"path/filepath"
 )
 
-// GetDataStoragePath returns the data storage path in the user's HOME directory,
-// specifically for the nexus-mods-scraper application on macos systems.
-func GetDataStoragePath() string {
+// defaultDataStoragePath returns the data storage path of the default profile in the
+// user's HOME directory, specifically for the nexus-mods-scraper application on macos
+// systems.
+func defaultDataStoragePath() string {
 	homeDir := os.Getenv("HOME")
 	return filepath.Join(homeDir, ".nexus-mods-scraper", "data")
 }
@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDataStoragePath_Profile(t *testing.T) {
+	defer SetProfile("")
+
+	SetProfile("archival")
+	assert.Equal(t, "archival", ActiveProfile())
+	assert.Equal(t, filepath.Join(filepath.Dir(defaultDataStoragePath()), "profiles", "archival"), GetDataStoragePath())
+
+	SetProfile("")
+	assert.Equal(t, "", ActiveProfile())
+	assert.Equal(t, defaultDataStoragePath(), GetDataStoragePath())
+}
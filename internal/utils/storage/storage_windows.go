@@ -8,9 +8,9 @@ import (
 	"path/filepath"
 )
 
-// GetDataStoragePath returns the data storage path in the user's home directory,
-// specifically for the nexus-mods-scraper application.
-func GetDataStoragePath() string {
+// defaultDataStoragePath returns the data storage path of the default profile in the
+// user's home directory, specifically for the nexus-mods-scraper application.
+func defaultDataStoragePath() string {
 	userProfileDir := os.Getenv("USERPROFILE")
 	return filepath.Join(userProfileDir, ".nexus-mods-scraper", "data")
 }
@@ -1,47 +1,39 @@
 package utils
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"os"
-	"sync"
-)
+	"time"
 
-// ConcurrentFetch runs multiple tasks concurrently, returning the first error
-// encountered. If all tasks succeed, it returns nil.
-func ConcurrentFetch(tasks ...func() error) error {
-	var wg sync.WaitGroup
-	errChan := make(chan error, len(tasks))
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"golang.org/x/sync/errgroup"
+)
 
+// ConcurrentFetch runs multiple tasks concurrently, handing each a context derived
+// from ctx that is cancelled as soon as one of them fails, so that the others stop
+// early instead of running to completion. Returns the first error encountered, as
+// returned by its task so that callers can match it with errors.Is, or nil if all
+// tasks succeed.
+func ConcurrentFetch(ctx context.Context, tasks ...func(ctx context.Context) error) error {
+	group, groupCtx := errgroup.WithContext(ctx)
 	for _, task := range tasks {
-		wg.Add(1)
-		go func(t func() error) {
-			defer wg.Done()
-			if err := t(); err != nil {
-				errChan <- err
-			}
-		}(task)
+		group.Go(func() error {
+			return task(groupCtx)
+		})
 	}
-
-	wg.Wait()
-	close(errChan)
-
-	// Return the first error encountered, if any
-	for err := range errChan {
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return group.Wait()
 }
 
 // EnsureDirExists checks if a directory exists at the given path and creates it
 // if it does not. Returns an error if the directory cannot be created or accessed.
 func EnsureDirExists(path string) error {
 	// Check if the directory exists
-	_, err := os.Stat(path)
+	_, err := fsys.Default.Stat(path)
 	if os.IsNotExist(err) {
 		// Create the directory if it doesn't exist
-		err := os.MkdirAll(path, os.ModePerm) // os.ModePerm ensures the directory is created with the correct permissions
+		err := fsys.Default.MkdirAll(path, os.ModePerm) // os.ModePerm ensures the directory is created with the correct permissions
 		if err != nil {
 			return err
 		}
@@ -51,3 +43,15 @@ func EnsureDirExists(path string) error {
 	}
 	return nil
 }
+
+// NewRunID returns a unique identifier for a run, made of the UTC start time and a
+// random suffix, e.g. "20240601T120000Z-1a2b3c4d". The time prefix keeps IDs sortable
+// while the suffix tells apart overlapping runs started on different machines.
+func NewRunID() string {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		// Fall back to the sub-second part of the clock if no randomness is available
+		return time.Now().UTC().Format("20060102T150405.000000000Z")
+	}
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(suffix)
+}
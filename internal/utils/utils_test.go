@@ -1,19 +1,21 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"os"
+	"regexp"
 	"testing"
 )
 
 func TestConcurrentFetch_FirstTaskFails(t *testing.T) {
 	// Arrange
 	expectedErr := errors.New("task1 failed")
-	task1 := func() error { return expectedErr }
-	task2 := func() error { return nil }
+	task1 := func(context.Context) error { return expectedErr }
+	task2 := func(context.Context) error { return nil }
 
 	// Act
-	err := ConcurrentFetch(task1, task2)
+	err := ConcurrentFetch(context.Background(), task1, task2)
 
 	// Assert
 	if err != expectedErr {
@@ -24,11 +26,11 @@ func TestConcurrentFetch_FirstTaskFails(t *testing.T) {
 func TestConcurrentFetch_SecondTaskFails(t *testing.T) {
 	// Arrange
 	expectedErr := errors.New("task2 failed")
-	task1 := func() error { return nil }
-	task2 := func() error { return expectedErr }
+	task1 := func(context.Context) error { return nil }
+	task2 := func(context.Context) error { return expectedErr }
 
 	// Act
-	err := ConcurrentFetch(task1, task2)
+	err := ConcurrentFetch(context.Background(), task1, task2)
 
 	// Assert
 	if err != expectedErr {
@@ -40,11 +42,11 @@ func TestConcurrentFetch_MultipleTasksFail(t *testing.T) {
 	// Arrange
 	task1Err := errors.New("task1 failed")
 	task2Err := errors.New("task2 failed")
-	task1 := func() error { return task1Err }
-	task2 := func() error { return task2Err }
+	task1 := func(context.Context) error { return task1Err }
+	task2 := func(context.Context) error { return task2Err }
 
 	// Act
-	err := ConcurrentFetch(task1, task2)
+	err := ConcurrentFetch(context.Background(), task1, task2)
 
 	// Assert
 	if err != task1Err && err != task2Err {
@@ -52,6 +54,25 @@ func TestConcurrentFetch_MultipleTasksFail(t *testing.T) {
 	}
 }
 
+func TestConcurrentFetch_CancelsSiblingsOnError(t *testing.T) {
+	// Arrange
+	expectedErr := errors.New("task1 failed")
+	task1 := func(context.Context) error { return expectedErr }
+	task2 := func(ctx context.Context) error {
+		// Block until the failure of task1 cancels the shared context
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	// Act
+	err := ConcurrentFetch(context.Background(), task1, task2)
+
+	// Assert
+	if err != expectedErr {
+		t.Errorf("Expected error %v, got %v", expectedErr, err)
+	}
+}
+
 func TestEnsureDirExists_DirAlreadyExists(t *testing.T) {
 	// Arrange
 	existingDir := "existingDir"
@@ -99,3 +120,17 @@ func TestEnsureDirExists_CannotCreateDir(t *testing.T) {
 		t.Errorf("Expected an error, but got nil")
 	}
 }
+
+func TestNewRunID(t *testing.T) {
+	// Act
+	first := NewRunID()
+	second := NewRunID()
+
+	// Assert
+	if !regexp.MustCompile(`^\d{8}T\d{6}Z-[0-9a-f]{8}$`).MatchString(first) {
+		t.Errorf("Unexpected run ID format: %s", first)
+	}
+	if first == second {
+		t.Errorf("Expected unique run IDs, got %s twice", first)
+	}
+}
@@ -0,0 +1,294 @@
+package wabbajack
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/compat"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+)
+
+// modlistEntry is the name of the modlist JSON inside a .wabbajack file.
+const modlistEntry = "modlist"
+
+// gameDomains maps the Wabbajack names of the games whose Nexus Mods domain isn't
+// their lowercased name to that domain.
+var gameDomains = map[string]string{
+	"dragonageorigins": "dragonage",
+	"fallout4vr":       "fallout4",
+	"falloutnewvegas":  "newvegas",
+	"skyrimvr":         "skyrimspecialedition",
+}
+
+// Mod is a Nexus Mods archive a modlist installs, with the version it pins.
+type Mod struct {
+	FileID  int64  `json:"fileId,omitempty"`
+	Game    string `json:"game"`
+	ModID   int64  `json:"modId"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version,omitempty"`
+}
+
+// ModList is a Wabbajack modlist, reduced to the Nexus Mods archives it installs in
+// the order it lists them, each mod once.
+type ModList struct {
+	Game    string
+	Mods    []Mod
+	Name    string
+	Version string
+}
+
+// modlistFile is the part of the modlist JSON read.
+type modlistFile struct {
+	Archives []struct {
+		Name  string `json:"Name"`
+		State struct {
+			FileID   flexInt `json:"FileID"`
+			Game     string  `json:"Game"`
+			GameName string  `json:"GameName"`
+			ModID    flexInt `json:"ModID"`
+			Name     string  `json:"Name"`
+			Type     string  `json:"$type"`
+			Version  string  `json:"Version"`
+		} `json:"State"`
+	} `json:"Archives"`
+	GameType string `json:"GameType"`
+	Name     string `json:"Name"`
+	Version  string `json:"Version"`
+}
+
+// flexInt is a number that older modlists write as a string.
+type flexInt int64
+
+// UnmarshalJSON reads the number either as a JSON number or a string holding one.
+func (n *flexInt) UnmarshalJSON(data []byte) error {
+	value := strings.Trim(string(data), `"`)
+	if value == "" || value == "null" {
+		*n = 0
+		return nil
+	}
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid number %s", data)
+	}
+	*n = flexInt(parsed)
+	return nil
+}
+
+// Read reads the modlist at path, either a .wabbajack file, the zip archive holding
+// the modlist JSON, or that JSON extracted from it. Returns an error if the file can't
+// be read or isn't a modlist.
+func Read(path string) (ModList, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return ModList{}, fmt.Errorf("error reading modlist: %w", err)
+	}
+
+	if bytes.HasPrefix(data, []byte("PK")) {
+		if data, err = unzipModList(data); err != nil {
+			return ModList{}, fmt.Errorf("error reading modlist %s: %w", path, err)
+		}
+	}
+
+	list, err := Parse(data)
+	if err != nil {
+		return ModList{}, fmt.Errorf("error reading modlist %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// unzipModList returns the modlist JSON held by a .wabbajack archive.
+func unzipModList(data []byte) ([]byte, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range archive.File {
+		if !strings.EqualFold(file.Name, modlistEntry) {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return io.ReadAll(reader)
+	}
+	return nil, fmt.Errorf("no %s entry in the archive", modlistEntry)
+}
+
+// Parse parses the modlist JSON, keeping the archives downloaded from Nexus Mods with
+// their game domain, e.g. skyrimspecialedition for SkyrimSpecialEdition. An archive
+// listed twice, e.g. the main file of a mod and one of its optional files, only
+// counts once. Returns an error if the JSON can't be decoded.
+func Parse(data []byte) (ModList, error) {
+	var file modlistFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return ModList{}, fmt.Errorf("not a modlist: %w", err)
+	}
+
+	list := ModList{Game: GameDomain(file.GameType), Name: file.Name, Version: file.Version}
+	seen := map[string]bool{}
+	for _, archive := range file.Archives {
+		state := archive.State
+		if !strings.Contains(strings.ToLower(state.Type), "nexus") || state.ModID <= 0 {
+			continue
+		}
+
+		game := state.Game
+		if game == "" {
+			game = state.GameName
+		}
+		mod := Mod{
+			FileID:  int64(state.FileID),
+			Game:    GameDomain(game),
+			ModID:   int64(state.ModID),
+			Name:    state.Name,
+			Version: state.Version,
+		}
+		if mod.Game == "" {
+			mod.Game = list.Game
+		}
+		if mod.Name == "" {
+			mod.Name = archive.Name
+		}
+
+		key := Key(mod.Game, mod.ModID)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		list.Mods = append(list.Mods, mod)
+	}
+	return list, nil
+}
+
+// GameDomain returns the Nexus Mods domain of a game as Wabbajack names it, e.g.
+// skyrimspecialedition for SkyrimSpecialEdition and newvegas for FalloutNewVegas.
+func GameDomain(game string) string {
+	name := strings.ToLower(strings.TrimSpace(game))
+	if domain, ok := gameDomains[name]; ok {
+		return domain
+	}
+	return name
+}
+
+// Key identifies a mod across games, e.g. "skyrimspecialedition/12604".
+func Key(game string, modID int64) string {
+	return fmt.Sprintf("%s/%d", game, modID)
+}
+
+// Games returns the games of the modlist's mods in the order they first appear, along
+// with the mod IDs of each.
+func (l ModList) Games() ([]string, map[string][]int64) {
+	var games []string
+	modIDs := map[string][]int64{}
+	for _, mod := range l.Mods {
+		if _, ok := modIDs[mod.Game]; !ok {
+			games = append(games, mod.Game)
+		}
+		modIDs[mod.Game] = append(modIDs[mod.Game], mod.ModID)
+	}
+	return games, modIDs
+}
+
+// The statuses of the mods in a refresh report. Mods no longer available have the
+// status they were scraped with instead, e.g. removed.
+const (
+	// StatusCurrent is a mod whose pinned version is its latest one.
+	StatusCurrent = "current"
+	// StatusNotScraped is a mod that wasn't scraped, e.g. it failed or was ignored.
+	StatusNotScraped = "not_scraped"
+	// StatusOutdated is a mod whose pinned version is older than its latest one.
+	StatusOutdated = "outdated"
+)
+
+// RefreshMod is a mod of a refresh report, with the version the modlist pins and the
+// latest one scraped.
+type RefreshMod struct {
+	Game          string `json:"game"`
+	LatestVersion string `json:"latestVersion,omitempty"`
+	ModID         int64  `json:"modId"`
+	Name          string `json:"name,omitempty"`
+	PinnedVersion string `json:"pinnedVersion,omitempty"`
+	Status        string `json:"status"`
+	Url           string `json:"url,omitempty"`
+}
+
+// Refresh is the report telling modlist maintainers which of the mods it pins have
+// been updated or are no longer available since.
+type Refresh struct {
+	Counts  map[string]int `json:"counts"`
+	Mods    []RefreshMod   `json:"mods"`
+	Name    string         `json:"name,omitempty"`
+	Version string         `json:"version,omitempty"`
+}
+
+// NewRefresh compares the version each mod of the modlist pins with the latest one
+// scraped, keyed by Key. A mod is outdated when its pinned version is older, see
+// compat.CompareVersions; mods without a version to compare count as current. Mods
+// are listed in the modlist's order.
+func NewRefresh(list ModList, scraped map[string]types.ModInfo) Refresh {
+	refresh := Refresh{Counts: map[string]int{}, Mods: []RefreshMod{}, Name: list.Name, Version: list.Version}
+	for _, mod := range list.Mods {
+		entry := RefreshMod{Game: mod.Game, ModID: mod.ModID, Name: mod.Name, PinnedVersion: mod.Version, Status: StatusNotScraped}
+		if info, ok := scraped[Key(mod.Game, mod.ModID)]; ok {
+			if info.Name != "" {
+				entry.Name = info.Name
+			}
+			entry.Url = info.Url
+			entry.LatestVersion = info.LatestVersion
+			if entry.LatestVersion == "" {
+				entry.LatestVersion = info.Version
+			}
+
+			switch {
+			case info.Status != "":
+				entry.Status = info.Status
+			case entry.PinnedVersion != "" && entry.LatestVersion != "" && compat.CompareVersions(entry.PinnedVersion, entry.LatestVersion) < 0:
+				entry.Status = StatusOutdated
+			default:
+				entry.Status = StatusCurrent
+			}
+		}
+		refresh.Counts[entry.Status]++
+		refresh.Mods = append(refresh.Mods, entry)
+	}
+	return refresh
+}
+
+// Statuses returns the statuses counted in the report, sorted.
+func (r Refresh) Statuses() []string {
+	statuses := make([]string, 0, len(r.Counts))
+	for status := range r.Counts {
+		statuses = append(statuses, status)
+	}
+	sort.Strings(statuses)
+	return statuses
+}
+
+// SaveRefresh writes the refresh report to path as indented JSON, creating its
+// directory if needed.
+func SaveRefresh(path string, refresh Refresh) error {
+	if err := utils.EnsureDirExists(filepath.Dir(path)); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(refresh, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting refresh report: %w", err)
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
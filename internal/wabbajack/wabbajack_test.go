@@ -0,0 +1,105 @@
+package wabbajack
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testModList is a modlist holding two Nexus archives of the same mod, one with its
+// mod ID as a string as older modlists write it, a Fallout New Vegas mod and an
+// archive downloaded from elsewhere.
+const testModList = `{
+  "Name": "Test List",
+  "Version": "1.2.0",
+  "GameType": "SkyrimSpecialEdition",
+  "Archives": [
+    {"Name": "SkyUI_5_2_SE-12604-5-2SE.7z", "State": {"$type": "NexusDownloader, Wabbajack.Lib", "Game": "SkyrimSpecialEdition", "ModID": 12604, "FileID": 35407, "Name": "SkyUI", "Version": "5.2SE"}},
+    {"Name": "SkyUI_Optional.7z", "State": {"$type": "NexusDownloader, Wabbajack.Lib", "GameName": "SkyrimSpecialEdition", "ModID": "12604", "FileID": "35408", "Version": "5.2SE"}},
+    {"Name": "JIP.7z", "State": {"$type": "NexusDownloader, Wabbajack.Lib", "GameName": "FalloutNewVegas", "ModID": 58277, "Version": "57.30"}},
+    {"Name": "ENB.zip", "State": {"$type": "HttpDownloader, Wabbajack.Lib", "Url": "https://enbdev.com/enb.zip"}}
+  ]
+}`
+
+func TestParse(t *testing.T) {
+	// Act
+	list, err := Parse([]byte(testModList))
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Test List", list.Name)
+	assert.Equal(t, "1.2.0", list.Version)
+	assert.Equal(t, "skyrimspecialedition", list.Game)
+	assert.Equal(t, []Mod{
+		{FileID: 35407, Game: "skyrimspecialedition", ModID: 12604, Name: "SkyUI", Version: "5.2SE"},
+		{Game: "newvegas", ModID: 58277, Name: "JIP.7z", Version: "57.30"},
+	}, list.Mods)
+}
+
+func TestParse_NotAModList(t *testing.T) {
+	_, err := Parse([]byte("not json"))
+
+	assert.ErrorContains(t, err, "not a modlist")
+}
+
+func TestRead_WabbajackArchive(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "test.wabbajack")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+	archive := zip.NewWriter(file)
+	entry, err := archive.Create("modlist")
+	require.NoError(t, err)
+	_, err = entry.Write([]byte(testModList))
+	require.NoError(t, err)
+	require.NoError(t, archive.Close())
+	require.NoError(t, file.Close())
+
+	// Act
+	list, err := Read(path)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Len(t, list.Mods, 2)
+}
+
+func TestGames(t *testing.T) {
+	// Arrange
+	list := ModList{Mods: []Mod{{Game: "skyrimspecialedition", ModID: 1}, {Game: "skyrim", ModID: 2}, {Game: "skyrimspecialedition", ModID: 3}}}
+
+	// Act
+	games, modIDs := list.Games()
+
+	// Assert
+	assert.Equal(t, []string{"skyrimspecialedition", "skyrim"}, games)
+	assert.Equal(t, map[string][]int64{"skyrimspecialedition": {1, 3}, "skyrim": {2}}, modIDs)
+}
+
+func TestNewRefresh(t *testing.T) {
+	// Arrange
+	list := ModList{Name: "Test List", Mods: []Mod{
+		{Game: "skyrimspecialedition", ModID: 1, Name: "Current", Version: "1.0"},
+		{Game: "skyrimspecialedition", ModID: 2, Name: "Outdated", Version: "1.0"},
+		{Game: "skyrimspecialedition", ModID: 3, Name: "Removed", Version: "1.0"},
+		{Game: "skyrimspecialedition", ModID: 4, Name: "Failed", Version: "1.0"},
+	}}
+	scraped := map[string]types.ModInfo{
+		"skyrimspecialedition/1": {Name: "Current Mod", Version: "1.0"},
+		"skyrimspecialedition/2": {Name: "Outdated Mod", LatestVersion: "1.1", Url: "https://www.nexusmods.com/skyrimspecialedition/mods/2"},
+		"skyrimspecialedition/3": {Name: "Removed Mod", Status: types.ModStatusRemoved},
+	}
+
+	// Act
+	refresh := NewRefresh(list, scraped)
+
+	// Assert
+	assert.Equal(t, map[string]int{StatusCurrent: 1, StatusOutdated: 1, types.ModStatusRemoved: 1, StatusNotScraped: 1}, refresh.Counts)
+	assert.Equal(t, RefreshMod{Game: "skyrimspecialedition", LatestVersion: "1.1", ModID: 2, Name: "Outdated Mod", PinnedVersion: "1.0", Status: StatusOutdated, Url: "https://www.nexusmods.com/skyrimspecialedition/mods/2"}, refresh.Mods[1])
+	assert.Equal(t, StatusNotScraped, refresh.Mods[3].Status)
+	assert.Equal(t, []string{StatusCurrent, StatusNotScraped, StatusOutdated, types.ModStatusRemoved}, refresh.Statuses())
+}
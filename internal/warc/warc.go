@@ -0,0 +1,199 @@
+package warc
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+)
+
+const (
+	// Version is the version of the WARC format the records are written in.
+	Version = "WARC/1.1"
+	// conformsTo is the specification the warcinfo record declares the file follows.
+	conformsTo = "http://iipc.github.io/warc-specifications/specifications/warc-format/warc-1.1/"
+)
+
+// The types of the records written.
+const (
+	TypeInfo     = "warcinfo"
+	TypeRequest  = "request"
+	TypeResponse = "response"
+)
+
+// recordedRequestHeaders are left out of the recorded requests, so that the session
+// cookies don't end up in an archive that may be shared.
+var recordedRequestHeaders = map[string]bool{"Authorization": true, "Cookie": true}
+
+// Field is a named field of the header of a record, kept in the order it is written.
+type Field struct {
+	Name  string
+	Value string
+}
+
+// Writer writes WARC records, such as the HTTP exchanges of a crawl, to a file that
+// replay tools like pywb can read. When compressed, each record is its own gzip member,
+// as in .warc.gz files, so that a record can be read without the ones before it.
+type Writer struct {
+	compress bool
+	records  int
+	w        io.Writer
+
+	// now returns the time the records are dated with, time.Now unless replaced in tests
+	now func() time.Time
+}
+
+// NewWriter returns a Writer writing records to w, each compressed with gzip when
+// compress is set.
+func NewWriter(w io.Writer, compress bool) *Writer {
+	return &Writer{compress: compress, w: w}
+}
+
+// Records returns the number of records written.
+func (w *Writer) Records() int {
+	return w.records
+}
+
+// WriteInfo writes the warcinfo record describing the file named filename, written by
+// software, e.g. "nexus-mods-scraper/v1.2.0". It is expected to be the first record.
+func (w *Writer) WriteInfo(filename, software string) error {
+	block := fmt.Sprintf("software: %s\r\nformat: WARC File Format 1.1\r\nconformsTo: %s\r\n", software, conformsTo)
+	return w.WriteRecord([]Field{
+		{"WARC-Type", TypeInfo},
+		{"WARC-Record-ID", newRecordID()},
+		{"WARC-Date", w.date()},
+		{"WARC-Filename", filename},
+		{"Content-Type", "application/warc-fields"},
+	}, []byte(block))
+}
+
+// Fetch sends req with client and writes the exchange, the response being fully read.
+// Returns the response, whose body has been read and closed, along with the body, or
+// an error if the request fails or the records can't be written.
+func (w *Writer) Fetch(client httpclient.HTTPClient, req *http.Request) (*http.Response, []byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := w.WriteExchange(req, resp, body); err != nil {
+		return nil, nil, err
+	}
+	return resp, body, nil
+}
+
+// WriteExchange writes the response to req, with its body, followed by the request
+// concurrent to it. The messages are recorded as HTTP/1.1, as WARC expects, whatever
+// the protocol they were exchanged over: the body is recorded as decoded by the client,
+// with its length, and the cookies and credentials of the request are left out.
+func (w *Writer) WriteExchange(req *http.Request, resp *http.Response, body []byte) error {
+	date := w.date()
+	target := req.URL.String()
+
+	header := resp.Header.Clone()
+	header.Del("Transfer-Encoding")
+	if resp.Uncompressed {
+		header.Del("Content-Encoding")
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	var response bytes.Buffer
+	fmt.Fprintf(&response, "HTTP/1.1 %03d %s\r\n", resp.StatusCode, http.StatusText(resp.StatusCode))
+	header.Write(&response)
+	response.WriteString("\r\n")
+	response.Write(body)
+
+	responseID := newRecordID()
+	if err := w.WriteRecord([]Field{
+		{"WARC-Type", TypeResponse},
+		{"WARC-Record-ID", responseID},
+		{"WARC-Date", date},
+		{"WARC-Target-URI", target},
+		{"WARC-Block-Digest", digest(response.Bytes())},
+		{"WARC-Payload-Digest", digest(body)},
+		{"Content-Type", "application/http; msgtype=response"},
+	}, response.Bytes()); err != nil {
+		return err
+	}
+
+	var request bytes.Buffer
+	fmt.Fprintf(&request, "%s %s HTTP/1.1\r\nHost: %s\r\n", req.Method, req.URL.RequestURI(), req.URL.Host)
+	req.Header.WriteSubset(&request, recordedRequestHeaders)
+	request.WriteString("\r\n")
+
+	return w.WriteRecord([]Field{
+		{"WARC-Type", TypeRequest},
+		{"WARC-Record-ID", newRecordID()},
+		{"WARC-Date", date},
+		{"WARC-Target-URI", target},
+		{"WARC-Concurrent-To", responseID},
+		{"WARC-Block-Digest", digest(request.Bytes())},
+		{"Content-Type", "application/http; msgtype=request"},
+	}, request.Bytes())
+}
+
+// WriteRecord writes a record made of the fields of its header, to which the
+// Content-Length of block is added, and block.
+func (w *Writer) WriteRecord(fields []Field, block []byte) error {
+	var record bytes.Buffer
+	record.WriteString(Version + "\r\n")
+	for _, field := range fields {
+		fmt.Fprintf(&record, "%s: %s\r\n", field.Name, field.Value)
+	}
+	fmt.Fprintf(&record, "Content-Length: %d\r\n\r\n", len(block))
+	record.Write(block)
+	record.WriteString("\r\n\r\n")
+
+	if !w.compress {
+		if _, err := w.w.Write(record.Bytes()); err != nil {
+			return err
+		}
+		w.records++
+		return nil
+	}
+
+	member := gzip.NewWriter(w.w)
+	if _, err := member.Write(record.Bytes()); err != nil {
+		return err
+	}
+	if err := member.Close(); err != nil {
+		return err
+	}
+	w.records++
+	return nil
+}
+
+// date returns the WARC-Date of a record written now.
+func (w *Writer) date() string {
+	now := time.Now
+	if w.now != nil {
+		now = w.now
+	}
+	return now().UTC().Format(time.RFC3339)
+}
+
+// digest returns the SHA-1 digest of data, in the base32 form of WARC digests.
+func digest(data []byte) string {
+	sum := sha1.Sum(data)
+	return "sha1:" + base32.StdEncoding.EncodeToString(sum[:])
+}
+
+// newRecordID returns a new record ID, a random (version 4) UUID URN.
+func newRecordID() string {
+	var id [16]byte
+	rand.Read(id[:])
+	id[6] = id[6]&0x0f | 0x40
+	id[8] = id[8]&0x3f | 0x80
+	return fmt.Sprintf("<urn:uuid:%x-%x-%x-%x-%x>", id[0:4], id[4:6], id[6:8], id[8:10], id[10:16])
+}
@@ -0,0 +1,149 @@
+package warc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// record is a WARC record read back from a file.
+type record struct {
+	header textproto.MIMEHeader
+	block  []byte
+}
+
+// readRecords reads back the records of a WARC file, decompressing its gzip members
+// when compressed, and checks that each is framed as the format requires.
+func readRecords(t *testing.T, data []byte, compressed bool) []record {
+	t.Helper()
+	var r io.Reader = bytes.NewReader(data)
+	if compressed {
+		gz, err := gzip.NewReader(r)
+		require.NoError(t, err)
+		r = gz
+	}
+	reader := bufio.NewReader(r)
+
+	var records []record
+	for {
+		version, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return records
+		}
+		require.NoError(t, err)
+		require.Equal(t, Version+"\r\n", version)
+		header, err := textproto.NewReader(reader).ReadMIMEHeader()
+		require.NoError(t, err)
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		require.NoError(t, err)
+		block := make([]byte, length+4)
+		_, err = io.ReadFull(reader, block)
+		require.NoError(t, err)
+		require.Equal(t, "\r\n\r\n", string(block[length:]))
+		records = append(records, record{header: header, block: block[:length]})
+	}
+}
+
+func TestWriter_WriteInfo(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	writer := NewWriter(&buf, false)
+	writer.now = func() time.Time { return time.Date(2024, time.June, 1, 12, 0, 0, 0, time.UTC) }
+
+	// Act
+	err := writer.WriteInfo("3863 2024-06-01T12-00.warc", "nexus-mods-scraper/v1.2.0")
+
+	// Assert
+	require.NoError(t, err)
+	records := readRecords(t, buf.Bytes(), false)
+	require.Len(t, records, 1)
+	assert.Equal(t, TypeInfo, records[0].header.Get("WARC-Type"))
+	assert.Equal(t, "2024-06-01T12:00:00Z", records[0].header.Get("WARC-Date"))
+	assert.Equal(t, "3863 2024-06-01T12-00.warc", records[0].header.Get("WARC-Filename"))
+	assert.Regexp(t, regexp.MustCompile(`^<urn:uuid:[0-9a-f]{8}-[0-9a-f]{4}-4[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}>$`), records[0].header.Get("WARC-Record-ID"))
+	assert.Contains(t, string(records[0].block), "software: nexus-mods-scraper/v1.2.0\r\n")
+	assert.Equal(t, 1, writer.Records())
+}
+
+func TestWriter_Fetch(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Header().Set("X-Test", "yes")
+		// Flushing first makes the server answer chunked
+		w.(http.Flusher).Flush()
+		io.WriteString(w, "<h1>SkyUI</h1>")
+	}))
+	defer server.Close()
+	var buf bytes.Buffer
+	writer := NewWriter(&buf, true)
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/skyrim/mods/3863?tab=files", nil)
+	require.NoError(t, err)
+	req.Header.Set("Cookie", "nexusmods_session=secret")
+	req.Header.Set("User-Agent", "Mozilla/5.0")
+
+	// Act
+	resp, body, err := writer.Fetch(server.Client(), req)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "<h1>SkyUI</h1>", string(body))
+	records := readRecords(t, buf.Bytes(), true)
+	require.Len(t, records, 2)
+
+	response, request := records[0], records[1]
+	assert.Equal(t, TypeResponse, response.header.Get("WARC-Type"))
+	assert.Equal(t, server.URL+"/skyrim/mods/3863?tab=files", response.header.Get("WARC-Target-URI"))
+	assert.Equal(t, "application/http; msgtype=response", response.header.Get("Content-Type"))
+	assert.Equal(t, digest(body), response.header.Get("WARC-Payload-Digest"))
+	assert.Equal(t, digest(response.block), response.header.Get("WARC-Block-Digest"))
+	recorded, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(response.block)), nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, recorded.StatusCode)
+	assert.Equal(t, "yes", recorded.Header.Get("X-Test"))
+	assert.Empty(t, recorded.TransferEncoding)
+	assert.Equal(t, int64(len(body)), recorded.ContentLength)
+	recordedBody, err := io.ReadAll(recorded.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, recordedBody)
+
+	assert.Equal(t, TypeRequest, request.header.Get("WARC-Type"))
+	assert.Equal(t, response.header.Get("WARC-Record-ID"), request.header.Get("WARC-Concurrent-To"))
+	assert.True(t, strings.HasPrefix(string(request.block), "GET /skyrim/mods/3863?tab=files HTTP/1.1\r\nHost: "+strings.TrimPrefix(server.URL, "http://")+"\r\n"))
+	assert.Contains(t, string(request.block), "User-Agent: Mozilla/5.0\r\n")
+	assert.NotContains(t, string(request.block), "secret")
+}
+
+func TestWriter_WriteExchange_DecodedBody(t *testing.T) {
+	// Arrange
+	var buf bytes.Buffer
+	writer := NewWriter(&buf, false)
+	req := httptest.NewRequest(http.MethodGet, "https://staticdelivery.nexusmods.com/3863-1.png", nil)
+	resp := &http.Response{
+		StatusCode:   http.StatusNotFound,
+		Header:       http.Header{"Content-Encoding": {"gzip"}, "Content-Length": {"120"}},
+		Uncompressed: true,
+	}
+
+	// Act
+	err := writer.WriteExchange(req, resp, []byte("missing"))
+
+	// Assert
+	require.NoError(t, err)
+	records := readRecords(t, buf.Bytes(), false)
+	require.Len(t, records, 2)
+	assert.Equal(t, "HTTP/1.1 404 Not Found\r\nContent-Length: 7\r\n\r\nmissing", string(records[0].block))
+}
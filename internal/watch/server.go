@@ -0,0 +1,106 @@
+package watch
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// Handler returns the HTTP control API of the daemon:
+//
+//	GET  /list     the tracked targets
+//	POST /add      track the target given as {"game": ..., "modId": ...}
+//	POST /remove   stop tracking the target given as {"game": ..., "modId": ...}
+//	POST /run-now  request a pass as soon as possible
+//	GET  /status   the state of the daemon
+//
+// Responses are JSON. Invalid targets are answered with 400 Bad Request and failures
+// to save the watch list with 500 Internal Server Error.
+func Handler(d *Daemon) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /list", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.List())
+	})
+
+	mux.HandleFunc("POST /add", func(w http.ResponseWriter, r *http.Request) {
+		changeTargets(w, r, d.Add, "added", "already tracked")
+	})
+
+	mux.HandleFunc("POST /remove", func(w http.ResponseWriter, r *http.Request) {
+		changeTargets(w, r, d.Remove, "removed", "not tracked")
+	})
+
+	mux.HandleFunc("POST /run-now", func(w http.ResponseWriter, r *http.Request) {
+		result := "queued"
+		if !d.RunNow() {
+			result = "already queued"
+		}
+		writeJSON(w, http.StatusAccepted, map[string]string{"result": result})
+	})
+
+	mux.HandleFunc("GET /status", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, d.Status())
+	})
+
+	return mux
+}
+
+// ProfilesHandler returns the HTTP control API of several daemons, one per watch
+// profile, each serving the API of Handler under /profiles/{name}, e.g.
+// /profiles/skyrim/status. GET /profiles answers with the status of every daemon by
+// profile name, and requests for an unknown profile with 404 Not Found.
+func ProfilesHandler(daemons map[string]*Daemon) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /profiles", func(w http.ResponseWriter, r *http.Request) {
+		statuses := make(map[string]Status, len(daemons))
+		for name, daemon := range daemons {
+			statuses[name] = daemon.Status()
+		}
+		writeJSON(w, http.StatusOK, statuses)
+	})
+
+	for name, daemon := range daemons {
+		prefix := "/profiles/" + name
+		mux.Handle(prefix+"/", http.StripPrefix(prefix, Handler(daemon)))
+	}
+
+	mux.HandleFunc("/profiles/", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown profile"})
+	})
+
+	return mux
+}
+
+// changeTargets decodes the target of the request and applies change to it, answering
+// with changed or unchanged depending on whether the watch list was modified.
+func changeTargets(w http.ResponseWriter, r *http.Request, change func(Target) (bool, error), changed, unchanged string) {
+	var target Target
+	if err := json.NewDecoder(r.Body).Decode(&target); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid target: " + err.Error()})
+		return
+	}
+	if _, err := Normalize(target); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+
+	ok, err := change(target)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	result := changed
+	if !ok {
+		result = unchanged
+	}
+	writeJSON(w, http.StatusOK, map[string]string{"result": result})
+}
+
+// writeJSON answers the request with the status and value encoded as JSON.
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
@@ -0,0 +1,287 @@
+package watch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+)
+
+const (
+	// DefaultFilename is the name of the watch list file in the data directory.
+	DefaultFilename = "watchlist.json"
+	// DefaultListen is the address the control API listens on, only reachable locally.
+	DefaultListen = "127.0.0.1:8765"
+)
+
+// Target is a mod tracked by the watch command.
+type Target struct {
+	Game  string `json:"game"`
+	ModID int64  `json:"modId"`
+}
+
+// Status describes the state of a watch daemon.
+type Status struct {
+	LastError      string    `json:"lastError,omitempty"`
+	LastFinishedAt time.Time `json:"lastFinishedAt"`
+	LastStartedAt  time.Time `json:"lastStartedAt"`
+	NextRunAt      time.Time `json:"nextRunAt"`
+	Passes         int       `json:"passes"`
+	Running        bool      `json:"running"`
+	Targets        int       `json:"targets"`
+}
+
+// RunFunc scrapes the targets once, in a single pass.
+type RunFunc func(ctx context.Context, targets []Target) error
+
+// Store persists the watch list, e.g. in a JSON file or a database.
+type Store interface {
+	Load() ([]Target, error)
+	Save(targets []Target) error
+}
+
+// FileStore is a Store keeping the watch list in a JSON file at Path.
+type FileStore struct {
+	Path string
+}
+
+// Load reads the watch list from the file, see Load.
+func (f FileStore) Load() ([]Target, error) {
+	return Load(f.Path)
+}
+
+// Save writes the watch list to the file, see Save.
+func (f FileStore) Save(targets []Target) error {
+	return Save(f.Path, targets)
+}
+
+// Load reads the watch list at path. A missing file is not an error and yields an
+// empty list.
+func Load(path string) ([]Target, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading watch list: %w", err)
+	}
+
+	var targets []Target
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("error decoding watch list %s: %w", path, err)
+	}
+	return targets, nil
+}
+
+// Save writes the watch list to path as indented JSON, creating its directory if
+// needed.
+func Save(path string, targets []Target) error {
+	if err := fsys.Default.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return err
+	}
+	if targets == nil {
+		targets = []Target{}
+	}
+
+	data, err := json.MarshalIndent(targets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error formatting watch list: %w", err)
+	}
+	if err := fsys.Default.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("error saving file: %s - %v", path, err)
+	}
+	return nil
+}
+
+// Normalize lowercases the game of the target and checks that it names a mod. Returns
+// an error if the game or mod ID is missing.
+func Normalize(target Target) (Target, error) {
+	target.Game = strings.ToLower(strings.TrimSpace(target.Game))
+	if target.Game == "" {
+		return target, fmt.Errorf("game is required")
+	}
+	if target.ModID <= 0 {
+		return target, fmt.Errorf("modId must be a positive number")
+	}
+	return target, nil
+}
+
+// Daemon scrapes a watch list every interval until stopped. The list can be changed
+// and passes triggered while it runs, and changes are saved to the store so they
+// survive restarts. A Daemon is safe for concurrent use.
+type Daemon struct {
+	interval time.Duration
+	run      RunFunc
+	runNow   chan struct{}
+	store    Store
+
+	mu      sync.Mutex
+	status  Status
+	targets []Target
+}
+
+// NewDaemon returns a Daemon scraping the watch list kept in store with run every
+// interval. Returns an error if the interval isn't positive or the list can't be read.
+func NewDaemon(store Store, interval time.Duration, run RunFunc) (*Daemon, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("interval must be greater than zero")
+	}
+
+	targets, err := store.Load()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Daemon{
+		interval: interval,
+		run:      run,
+		runNow:   make(chan struct{}, 1),
+		store:    store,
+		targets:  targets,
+	}, nil
+}
+
+// Add tracks the target and saves the watch list. Reports false when the target was
+// already tracked. Returns an error if the target is invalid or the list can't be
+// saved.
+func (d *Daemon) Add(target Target) (bool, error) {
+	target, err := Normalize(target)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, tracked := range d.targets {
+		if tracked == target {
+			return false, nil
+		}
+	}
+
+	targets := append(append([]Target(nil), d.targets...), target)
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].Game != targets[j].Game {
+			return targets[i].Game < targets[j].Game
+		}
+		return targets[i].ModID < targets[j].ModID
+	})
+	if err := d.store.Save(targets); err != nil {
+		return false, err
+	}
+	d.targets = targets
+	return true, nil
+}
+
+// Remove stops tracking the target and saves the watch list. Reports false when the
+// target wasn't tracked. Returns an error if the target is invalid or the list can't
+// be saved.
+func (d *Daemon) Remove(target Target) (bool, error) {
+	target, err := Normalize(target)
+	if err != nil {
+		return false, err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	targets := make([]Target, 0, len(d.targets))
+	for _, tracked := range d.targets {
+		if tracked != target {
+			targets = append(targets, tracked)
+		}
+	}
+	if len(targets) == len(d.targets) {
+		return false, nil
+	}
+
+	if err := d.store.Save(targets); err != nil {
+		return false, err
+	}
+	d.targets = targets
+	return true, nil
+}
+
+// List returns the tracked targets.
+func (d *Daemon) List() []Target {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Target{}, d.targets...)
+}
+
+// Status returns the state of the daemon.
+func (d *Daemon) Status() Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	status := d.status
+	status.Targets = len(d.targets)
+	return status
+}
+
+// RunNow requests a pass as soon as the current one, if any, is done. Reports false
+// when a pass was already requested.
+func (d *Daemon) RunNow() bool {
+	select {
+	case d.runNow <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+// Run scrapes the watch list right away and then every interval, or sooner when a pass
+// is requested with RunNow, until ctx is done. Passes never overlap; a failing pass is
+// recorded in the status and doesn't stop the daemon.
+func (d *Daemon) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-d.runNow:
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+
+		d.pass(ctx)
+		timer.Reset(d.interval)
+	}
+}
+
+// pass scrapes the tracked targets once and records the outcome in the status.
+func (d *Daemon) pass(ctx context.Context) {
+	d.mu.Lock()
+	targets := append([]Target(nil), d.targets...)
+	d.status.Running = true
+	d.status.LastStartedAt = time.Now()
+	d.mu.Unlock()
+
+	var err error
+	if len(targets) > 0 {
+		err = d.run(ctx, targets)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.status.Running = false
+	d.status.Passes++
+	d.status.LastFinishedAt = time.Now()
+	d.status.NextRunAt = d.status.LastFinishedAt.Add(d.interval)
+	d.status.LastError = ""
+	if err != nil {
+		d.status.LastError = err.Error()
+	}
+}
@@ -0,0 +1,179 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// noRun is a RunFunc that doesn't scrape anything.
+func noRun(context.Context, []Target) error { return nil }
+
+func TestDaemon_AddRemove(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	path := filepath.Join("data", DefaultFilename)
+	d, err := NewDaemon(FileStore{Path: path}, time.Hour, noRun)
+	require.NoError(t, err)
+
+	// Act
+	added, addErr := d.Add(Target{Game: "Skyrim", ModID: 3863})
+	addedAgain, _ := d.Add(Target{Game: "skyrim", ModID: 3863})
+	_, _ = d.Add(Target{Game: "fallout4", ModID: 1})
+	removed, removeErr := d.Remove(Target{Game: "fallout4", ModID: 1})
+	removedAgain, _ := d.Remove(Target{Game: "fallout4", ModID: 1})
+
+	// Assert
+	require.NoError(t, addErr)
+	require.NoError(t, removeErr)
+	assert.True(t, added)
+	assert.False(t, addedAgain)
+	assert.True(t, removed)
+	assert.False(t, removedAgain)
+	assert.Equal(t, []Target{{Game: "skyrim", ModID: 3863}}, d.List())
+
+	saved, err := Load(path)
+	require.NoError(t, err)
+	assert.Equal(t, []Target{{Game: "skyrim", ModID: 3863}}, saved)
+}
+
+func TestDaemon_AddInvalid(t *testing.T) {
+	defer fsys.Use(fsys.NewMemFS())()
+	d, err := NewDaemon(FileStore{Path: "watchlist.json"}, time.Hour, noRun)
+	require.NoError(t, err)
+
+	_, err = d.Add(Target{Game: "skyrim"})
+
+	assert.EqualError(t, err, "modId must be a positive number")
+}
+
+func TestNewDaemon_InvalidInterval(t *testing.T) {
+	_, err := NewDaemon(FileStore{Path: "watchlist.json"}, 0, noRun)
+
+	assert.EqualError(t, err, "interval must be greater than zero")
+}
+
+func TestDaemon_Run(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, Save("watchlist.json", []Target{{Game: "skyrim", ModID: 1}}))
+
+	passes := make(chan []Target, 2)
+	run := func(ctx context.Context, targets []Target) error {
+		passes <- targets
+		return errors.New("boom")
+	}
+	d, err := NewDaemon(FileStore{Path: "watchlist.json"}, time.Hour, run)
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	// Act
+	go func() {
+		d.Run(ctx)
+		close(done)
+	}()
+	first := <-passes
+	require.Eventually(t, func() bool { return d.Status().Passes == 1 }, time.Second, time.Millisecond)
+	assert.True(t, d.RunNow())
+	second := <-passes
+	cancel()
+	<-done
+
+	// Assert
+	assert.Equal(t, []Target{{Game: "skyrim", ModID: 1}}, first)
+	assert.Equal(t, first, second)
+	status := d.Status()
+	assert.Equal(t, "boom", status.LastError)
+	assert.Equal(t, 1, status.Targets)
+	assert.False(t, status.Running)
+}
+
+func TestHandler(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	d, err := NewDaemon(FileStore{Path: "watchlist.json"}, time.Hour, noRun)
+	require.NoError(t, err)
+	server := httptest.NewServer(Handler(d))
+	defer server.Close()
+
+	post := func(path, body string) (int, string) {
+		resp, err := http.Post(server.URL+path, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	// Act
+	addStatus, addBody := post("/add", `{"game":"skyrim","modId":3863}`)
+	invalidStatus, _ := post("/add", `{"game":"skyrim"}`)
+	runStatus, runBody := post("/run-now", "")
+	listResp, err := http.Get(server.URL + "/list")
+	require.NoError(t, err)
+	defer listResp.Body.Close()
+	methodResp, err := http.Get(server.URL + "/add")
+	require.NoError(t, err)
+	defer methodResp.Body.Close()
+
+	// Assert
+	assert.Equal(t, http.StatusOK, addStatus)
+	assert.JSONEq(t, `{"result":"added"}`, addBody)
+	assert.Equal(t, http.StatusBadRequest, invalidStatus)
+	assert.Equal(t, http.StatusAccepted, runStatus)
+	assert.JSONEq(t, `{"result":"queued"}`, runBody)
+	list, err := io.ReadAll(listResp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[{"game":"skyrim","modId":3863}]`, string(list))
+	assert.Equal(t, http.StatusMethodNotAllowed, methodResp.StatusCode)
+}
+
+func TestProfilesHandler(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, Save("fallout.json", []Target{{Game: "fallout4", ModID: 1}}))
+	skyrim, err := NewDaemon(FileStore{Path: "skyrim.json"}, time.Hour, noRun)
+	require.NoError(t, err)
+	fallout, err := NewDaemon(FileStore{Path: "fallout.json"}, time.Hour, noRun)
+	require.NoError(t, err)
+	server := httptest.NewServer(ProfilesHandler(map[string]*Daemon{"skyrim": skyrim, "fallout": fallout}))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	// Act
+	resp, err := http.Post(server.URL+"/profiles/skyrim/add", "application/json", strings.NewReader(`{"game":"skyrim","modId":3863}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	skyrimStatus, skyrimList := get("/profiles/skyrim/list")
+	_, falloutList := get("/profiles/fallout/list")
+	unknownStatus, _ := get("/profiles/oblivion/list")
+	profilesStatus, profiles := get("/profiles")
+
+	// Assert
+	assert.Equal(t, http.StatusOK, skyrimStatus)
+	assert.JSONEq(t, `[{"game":"skyrim","modId":3863}]`, skyrimList)
+	assert.JSONEq(t, `[{"game":"fallout4","modId":1}]`, falloutList)
+	assert.Equal(t, http.StatusNotFound, unknownStatus)
+	assert.Equal(t, http.StatusOK, profilesStatus)
+	assert.Contains(t, profiles, `"fallout":{`)
+	assert.Contains(t, profiles, `"skyrim":{`)
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"fmt"
+	"os"
 	"runtime"
 
 	sCli "github.com/ondrovic/common/utils/cli"
 	"github.com/ondrovic/nexus-mods-scraper/cmd/cli"
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
 )
 
 type clearScreenFunc func(interface{}) error
@@ -22,12 +24,17 @@ func run(clearScreen clearScreenFunc, executeFunc func() error) error {
 	return nil
 }
 
-func executeMain(clearScreen clearScreenFunc, executeFunc func() error) {
-	if err := run(clearScreen, executeFunc); err != nil {
-		return
-	}
+// executeMain runs the command and returns the exit code matching its outcome, telling
+// authentication, network and parse failures and partially successful runs apart.
+func executeMain(clearScreen clearScreenFunc, executeFunc func() error) int {
+	return failures.ExitCode(run(clearScreen, executeFunc))
 }
 
 func main() {
-	executeMain(sCli.ClearTerminalScreen, cli.RootCmd.Execute)
+	clearScreen := clearScreenFunc(sCli.ClearTerminalScreen)
+	// The shell reads the completion output, it mustn't start with escape codes
+	if cli.CompletionRequested(os.Args[1:]) {
+		clearScreen = func(interface{}) error { return nil }
+	}
+	os.Exit(executeMain(clearScreen, cli.Execute))
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ondrovic/nexus-mods-scraper/internal/failures"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -17,10 +18,10 @@ func TestExecuteMain_Success(t *testing.T) {
 	}
 
 	// Act: Call `executeMain` and verify it succeeds
-	executeMain(mockClearTerminal, mockExecute)
+	code := executeMain(mockClearTerminal, mockExecute)
 
-	// Since `executeMain` doesn't return anything, you would assert no panics/errors occurred
-	assert.True(t, true, "executeMain should complete without errors")
+	// Assert that the command exits successfully
+	assert.Equal(t, 0, code, "executeMain should complete without errors")
 }
 
 func TestExecuteMain_FailureOnClearTerminal(t *testing.T) {
@@ -36,10 +37,10 @@ func TestExecuteMain_FailureOnClearTerminal(t *testing.T) {
 	}
 
 	// Act: Call `executeMain` and verify it handles the error
-	executeMain(mockClearTerminal, mockExecute)
+	code := executeMain(mockClearTerminal, mockExecute)
 
-	// Again, since `executeMain` doesn't return anything, you assert that no panic occurred
-	assert.True(t, true, "executeMain should handle the terminal clearing error gracefully")
+	// Assert that the generic failure exit code is returned
+	assert.Equal(t, 1, code, "executeMain should handle the terminal clearing error gracefully")
 }
 
 func TestExecuteMain_FailureOnExecute(t *testing.T) {
@@ -54,8 +55,26 @@ func TestExecuteMain_FailureOnExecute(t *testing.T) {
 	}
 
 	// Act: Call `executeMain` and verify it handles the error
-	executeMain(mockClearTerminal, mockExecute)
+	code := executeMain(mockClearTerminal, mockExecute)
 
-	// No panics/errors should occur, and the execution error should be gracefully handled
-	assert.True(t, true, "executeMain should handle the execution error gracefully")
+	// Assert that the generic failure exit code is returned
+	assert.Equal(t, 1, code, "executeMain should handle the execution error gracefully")
+}
+
+func TestExecuteMain_FailureExitCode(t *testing.T) {
+	// Mock `ClearTerminalScreen` to succeed
+	mockClearTerminal := func(_ interface{}) error {
+		return nil
+	}
+
+	// Mock `executeFunc` to return a partially successful run
+	mockExecute := func() error {
+		return &failures.RunError{Failed: 1, First: errors.New("boom"), Succeeded: 1, Total: 2}
+	}
+
+	// Act: Call `executeMain` and verify the exit code reflects the failure
+	code := executeMain(mockClearTerminal, mockExecute)
+
+	// Assert that the partial success exit code is returned
+	assert.Equal(t, failures.ExitPartial, code)
 }
@@ -0,0 +1,190 @@
+// Package scraper is the public Go API of nexus-mods-scraper. It lets other Go
+// programs scrape mods from Nexus Mods with the same extractors as the CLI, without
+// shelling out to it.
+//
+// A Client holds its own session cookies, so that several clients, e.g. for
+// different accounts, can be used side by side:
+//
+//	cookies, err := scraper.LoadCookies(scraper.DefaultCookiePath())
+//	if err != nil {
+//		return err
+//	}
+//	client, err := scraper.NewClient(scraper.Options{Cookies: cookies})
+//	if err != nil {
+//		return err
+//	}
+//	mod, err := client.ScrapeMod(ctx, "skyrimspecialedition", 12604)
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fetchers"
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/ondrovic/nexus-mods-scraper/internal/httpclient"
+	"github.com/ondrovic/nexus-mods-scraper/internal/types"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils"
+	"github.com/ondrovic/nexus-mods-scraper/internal/utils/storage"
+)
+
+const (
+	// DefaultBaseURL is the site scraped when Options doesn't set one.
+	DefaultBaseURL = "https://nexusmods.com"
+	// DefaultCookieFilename is the file the CLI saves the session cookies to.
+	DefaultCookieFilename = "session-cookies.json"
+)
+
+// Statuses ScrapeMod sets in ModInfo.Status for a mod that is no longer available.
+const (
+	// ModStatusHidden is a mod hidden by its author or by the moderators.
+	ModStatusHidden = types.ModStatusHidden
+	// ModStatusNotFound is a mod whose page doesn't exist.
+	ModStatusNotFound = types.ModStatusNotFound
+	// ModStatusRemoved is a mod removed by its author.
+	ModStatusRemoved = types.ModStatusRemoved
+)
+
+// ErrAdultContent is returned by ScrapeMod when the mod is hidden behind the adult
+// content filter, which only a logged in session with the filter disabled can see.
+var ErrAdultContent = fetchers.ErrAdultContent
+
+// ModInfo is a scraped mod: its details, statistics, requirements, changelogs and
+// files.
+type ModInfo = types.ModInfo
+
+// File is a file listed on the files tab of a mod.
+type File = types.File
+
+// ChangeLog is the changelog of a version of a mod.
+type ChangeLog = types.ChangeLog
+
+// Requirement is a mod, DLC or off-site resource a mod requires, or a mod requiring
+// it.
+type Requirement = types.Requirement
+
+// Link is a link found in the notes of a requirement.
+type Link = types.Link
+
+// Translation is a translation of a mod published as a separate mod.
+type Translation = types.Translation
+
+// Permissions is the "Permissions and credits" section of a mod page.
+type Permissions = types.Permissions
+
+// Permission is a permission rule set by the author of a mod.
+type Permission = types.Permission
+
+// Timestamp is a date read from a mod page, e.g. ModInfo.LastUpdatedAt.
+type Timestamp = types.Timestamp
+
+// Options configures a Client.
+type Options struct {
+	// BaseURL is the site scraped; empty means DefaultBaseURL.
+	BaseURL string
+	// Cookies are the session cookies sent with every request, by name, e.g. as read
+	// by LoadCookies. Without them, mods are scraped as a logged out visitor.
+	Cookies map[string]string
+}
+
+// Client scrapes mods from Nexus Mods with its own session cookies. It is safe for
+// concurrent use.
+type Client struct {
+	baseURL       string
+	fetchDocument func(ctx context.Context, targetURL string) (*goquery.Document, error)
+}
+
+// NewClient returns a Client configured with options. Returns an error if the base
+// URL or the cookies are invalid.
+func NewClient(options Options) (*Client, error) {
+	baseURL := strings.TrimRight(options.BaseURL, "/")
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+
+	client, err := httpclient.NewClient(baseURL, "", "", CookieHeader(options.Cookies))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{baseURL: baseURL, fetchDocument: fetchers.WithClient(client, fetchers.FetchDocument)}, nil
+}
+
+// ScrapeMod scrapes the mod of the game, e.g. "skyrimspecialedition", with the given
+// ID: its main page and files tab, fetched concurrently. The context bounds both
+// requests. A mod that is no longer available, hidden, removed or not found, isn't an
+// error: it is returned with its Status set to one of the ModStatus constants. Returns
+// ErrAdultContent if the mod is hidden by the adult content filter, or an error if the
+// game or ID is invalid or a page can't be fetched.
+func (c *Client) ScrapeMod(ctx context.Context, game string, id int64) (ModInfo, error) {
+	slug, err := types.ParseGameSlug(game)
+	if err != nil {
+		return ModInfo{}, err
+	}
+	modID, err := types.NewModID(id)
+	if err != nil {
+		return ModInfo{}, err
+	}
+	results, err := fetchers.FetchModInfoConcurrent(ctx, c.baseURL, slug, modID, nil, utils.ConcurrentFetch, c.fetchDocument)
+	if err != nil {
+		return ModInfo{}, err
+	}
+	return results.Mods, nil
+}
+
+// DefaultCookiePath returns the path of the cookie file the CLI's extract command
+// saves the session cookies to.
+func DefaultCookiePath() string {
+	return filepath.Join(storage.GetDataStoragePath(), DefaultCookieFilename)
+}
+
+// LoadCookies reads the session cookies from the JSON file at path, an object mapping
+// each cookie name to its value as saved by the CLI. Returns an error if the file
+// can't be read or decoded.
+func LoadCookies(path string) (map[string]string, error) {
+	data, err := fsys.Default.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading cookie file: %w", err)
+	}
+
+	var cookies map[string]string
+	if err := json.Unmarshal(data, &cookies); err != nil {
+		return nil, fmt.Errorf("error decoding cookie file %s: %w", path, err)
+	}
+	return cookies, nil
+}
+
+// ParseCookieHeader parses the value of a Cookie header, e.g. as copied from the
+// browser's developer tools, into cookies by name. Returns an error if it holds no
+// valid cookie.
+func ParseCookieHeader(header string) (map[string]string, error) {
+	parsed, err := http.ParseCookie(header)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing cookie header: %w", err)
+	}
+
+	cookies := make(map[string]string, len(parsed))
+	for _, cookie := range parsed {
+		cookies[cookie.Name] = cookie.Value
+	}
+	return cookies, nil
+}
+
+// CookieHeader formats cookies as the value of a Cookie header, sorted by name.
+func CookieHeader(cookies map[string]string) string {
+	return httpclient.CookieHeader(cookies)
+}
+
+// ValidateCookies reports whether the cookies authenticate against the site at
+// baseURL, empty meaning DefaultBaseURL. Returns an error if the check can't be made.
+func ValidateCookies(baseURL string, cookies map[string]string) (bool, error) {
+	if baseURL == "" {
+		baseURL = DefaultBaseURL
+	}
+	return fetchers.ValidateCookies(baseURL, cookies)
+}
@@ -0,0 +1,120 @@
+package scraper
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ondrovic/nexus-mods-scraper/internal/fsys"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_ScrapeMod(t *testing.T) {
+	// Arrange
+	var cookies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookies = append(cookies, r.Header.Get("Cookie"))
+		if r.URL.Query().Get("tab") == "files" {
+			w.Write([]byte(`<html><body></body></html>`))
+			return
+		}
+		w.Write([]byte(`<html><body><div id="pagetitle"><h1>SkyUI</h1></div></body></html>`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(Options{BaseURL: server.URL, Cookies: map[string]string{"nexusmods_session": "abc"}})
+	require.NoError(t, err)
+
+	// Act
+	mod, err := client.ScrapeMod(context.Background(), "SkyrimSpecialEdition", 12604)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, "SkyUI", mod.Name)
+	assert.Equal(t, int64(12604), mod.ModID)
+	require.Len(t, cookies, 2)
+	for _, cookie := range cookies {
+		assert.Contains(t, cookie, "nexusmods_session=abc")
+	}
+}
+
+func TestClient_ScrapeModNotFound(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+	client, err := NewClient(Options{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	// Act
+	mod, err := client.ScrapeMod(context.Background(), "skyrim", 1)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, ModStatusNotFound, mod.Status)
+	assert.Equal(t, int64(1), mod.ModID)
+}
+
+func TestClient_ScrapeModError(t *testing.T) {
+	// Arrange
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+	client, err := NewClient(Options{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	// Act
+	_, err = client.ScrapeMod(context.Background(), "skyrim", 1)
+
+	// Assert
+	assert.Error(t, err)
+}
+
+func TestLoadCookies(t *testing.T) {
+	// Arrange
+	defer fsys.Use(fsys.NewMemFS())()
+	require.NoError(t, fsys.Default.WriteFile("cookies.json", []byte(`{"nexusmods_session":"abc"}`), 0644))
+	require.NoError(t, fsys.Default.WriteFile("broken.json", []byte(`[`), 0644))
+
+	// Act
+	cookies, err := LoadCookies("cookies.json")
+	_, brokenErr := LoadCookies("broken.json")
+	_, missingErr := LoadCookies("missing.json")
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"nexusmods_session": "abc"}, cookies)
+	assert.ErrorContains(t, brokenErr, "error decoding cookie file broken.json")
+	assert.ErrorContains(t, missingErr, "error reading cookie file")
+}
+
+func TestCookieHeader(t *testing.T) {
+	// Act
+	header := CookieHeader(map[string]string{"b": "2", "a": "1"})
+	cookies, err := ParseCookieHeader(header)
+
+	// Assert
+	assert.Equal(t, "a=1; b=2", header)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "1", "b": "2"}, cookies)
+	assert.Empty(t, CookieHeader(nil))
+}
+
+func TestModInfo_NestedTypes(t *testing.T) {
+	// Arrange
+	data := []byte(`{"Dependencies":[{"Name":"SKSE","NotesLinks":[{"Text":"2.2.6","Url":"https://skse.silverlock.org"}]}],"LastUpdatedAt":"2024-10-13T10:44:00Z","Translations":[{"Language":"German","ModID":2}]}`)
+
+	// Act
+	var mod ModInfo
+	err := json.Unmarshal(data, &mod)
+
+	// Assert
+	require.NoError(t, err)
+	assert.Equal(t, []Requirement{{Name: "SKSE", NotesLinks: []Link{{Text: "2.2.6", Url: "https://skse.silverlock.org"}}}}, mod.Dependencies)
+	assert.Equal(t, []Translation{{Language: "German", ModID: 2}}, mod.Translations)
+	assert.Equal(t, &Timestamp{Time: time.Date(2024, 10, 13, 10, 44, 0, 0, time.UTC)}, mod.LastUpdatedAt)
+}